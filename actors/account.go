@@ -21,7 +21,7 @@ func (p *ActorParser) ParseAccount(txType string, msg *parser.LotusMessage, msgR
 	case parser.MethodAuthenticateMessage:
 		return p.authenticateMessage(msg.Params, msgRct.Return)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
@@ -1,6 +1,9 @@
 package actors
 
 import (
+	"context"
+	"errors"
+
 	"github.com/filecoin-project/go-state-types/manifest"
 	filTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/ipfs/go-cid"
@@ -8,6 +11,7 @@ import (
 	"github.com/zondax/fil-parser/parser"
 	"github.com/zondax/fil-parser/parser/helper"
 	"github.com/zondax/fil-parser/types"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -23,19 +27,36 @@ func NewActorParser(helper *helper.Helper, logger *zap.Logger) *ActorParser {
 	}
 }
 
-func (p *ActorParser) GetMetadata(txType string, msg *parser.LotusMessage, mainMsgCid cid.Cid, msgRct *parser.LotusMessageReceipt,
+func (p *ActorParser) GetMetadata(ctx context.Context, txType string, msg *parser.LotusMessage, mainMsgCid cid.Cid, msgRct *parser.LotusMessageReceipt,
 	height int64, key filTypes.TipSetKey) (map[string]interface{}, *types.AddressInfo, error) {
 	metadata := make(map[string]interface{})
 	if msg == nil {
 		return metadata, nil, nil
 	}
 
-	actor, err := p.helper.GetActorNameFromAddress(msg.To, height, key)
+	actor, err := p.helper.GetActorNameFromAddressWithContext(ctx, msg.To, height, key)
 	if err != nil {
 		return metadata, nil, err
 	}
 
-	var addressInfo *types.AddressInfo
+	_, span := p.helper.GetTracer().Start(ctx, parser.SpanDecodeActor, trace.WithAttributes(
+		parser.AttributeActor.String(actor),
+		parser.AttributeTxType.String(txType),
+		parser.AttributeHeight.Int64(height),
+	))
+	defer span.End()
+
+	metadata, addressInfo, err := p.parseActorMetadata(ctx, actor, txType, msg, mainMsgCid, msgRct, height, key)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return metadata, addressInfo, err
+}
+
+// parseActorMetadata decodes the metadata of a message sent to an actor whose name is already known
+func (p *ActorParser) parseActorMetadata(ctx context.Context, actor, txType string, msg *parser.LotusMessage, mainMsgCid cid.Cid, msgRct *parser.LotusMessageReceipt,
+	height int64, key filTypes.TipSetKey) (metadata map[string]interface{}, addressInfo *types.AddressInfo, err error) {
+	metadata = make(map[string]interface{})
 	switch actor {
 	case manifest.InitKey:
 		metadata, addressInfo, err = p.ParseInit(txType, msg, msgRct)
@@ -46,13 +67,13 @@ func (p *ActorParser) GetMetadata(txType string, msg *parser.LotusMessage, mainM
 	case manifest.PowerKey:
 		metadata, addressInfo, err = p.ParseStoragepower(txType, msg, msgRct)
 	case manifest.MinerKey:
-		metadata, err = p.ParseStorageminer(txType, msg, msgRct)
+		metadata, err = p.ParseStorageminer(ctx, txType, msg, msgRct, key)
 	case manifest.MarketKey:
 		metadata, err = p.ParseStoragemarket(txType, msg, msgRct)
 	case manifest.PaychKey:
 		metadata, err = p.ParsePaymentchannel(txType, msg, msgRct)
 	case manifest.MultisigKey:
-		metadata, err = p.ParseMultisig(txType, msg, msgRct, height, key)
+		metadata, err = p.ParseMultisig(ctx, txType, msg, msgRct, height, key)
 	case manifest.RewardKey:
 		metadata, err = p.ParseReward(txType, msg, msgRct)
 	case manifest.VerifregKey:
@@ -70,5 +91,21 @@ func (p *ActorParser) GetMetadata(txType string, msg *parser.LotusMessage, mainM
 	default:
 		err = parser.ErrNotValidActor
 	}
+
+	// Methods known by go-state-types without an explicit parser are decoded into their declared types
+	if errors.Is(err, parser.ErrUnknownMethod) && p.helper.GetConfig().IsFeatureEnabled(parser.FeatureGenericMethodDecoder) {
+		if decoded, dErr := p.helper.DecodeMethodMetadata(actor, msg.Method, msg.Params, msgRct.Return); dErr == nil {
+			metadata, err = decoded, nil
+		}
+	}
+
+	if err == nil && p.helper.GetConfig().IsFeatureEnabled(parser.FeatureLotusJSON) {
+		metadata = p.lotusJSONMetadata(actor, metadata, msg, msgRct)
+	}
+
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureParamsCodecs) {
+		metadata = p.appendCodecs(metadata, msg, msgRct)
+	}
+
 	return metadata, addressInfo, err
 }
@@ -15,7 +15,10 @@ import (
 	"github.com/zondax/fil-parser/actors/cache/impl"
 	"github.com/zondax/fil-parser/actors/cache/impl/common"
 	logger2 "github.com/zondax/fil-parser/logger"
+	"github.com/zondax/fil-parser/parser"
 	"github.com/zondax/fil-parser/types"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -32,7 +35,20 @@ var SystemActorsId = map[string]bool{
 	"f099": true,
 }
 
+// setupHealthCheckTimeout bounds the check of the remote cache done while setting up the actors cache
+const setupHealthCheckTimeout = 5 * time.Second
+
+var ErrRemoteCacheUnavailable = errors.New("remote cache unavailable")
+
 func SetupActorsCache(dataSource common.DataSource, logger *zap.Logger) (*ActorsCache, error) {
+	actorsCache, _, err := SetupActorsCacheWithStatus(dataSource, logger)
+	return actorsCache, err
+}
+
+// SetupActorsCacheWithStatus sets up the actors cache like SetupActorsCache, also returning the backend it selected.
+// If the remote cache is not configured or cannot be reached, the cache falls back to its local store and the
+// status reports why, unless RequireRemoteCache is set, in which case ErrRemoteCacheUnavailable is returned.
+func SetupActorsCacheWithStatus(dataSource common.DataSource, logger *zap.Logger) (*ActorsCache, CacheStatus, error) {
 	var offChainCache IActorsCache
 	var onChainCache impl.OnChain
 
@@ -40,26 +56,154 @@ func SetupActorsCache(dataSource common.DataSource, logger *zap.Logger) (*Actors
 
 	err := onChainCache.NewImpl(dataSource, logger)
 	if err != nil {
-		return nil, err
+		return nil, CacheStatus{}, err
 	}
 
 	var combinedCache impl.ZCache
 	if err = combinedCache.NewImpl(dataSource, logger); err != nil {
 		logger.Sugar().Errorf("[ActorsCache] - Unable to initialize combined cache: %s", err.Error())
-		return nil, err
+		return nil, CacheStatus{}, err
 	}
 
 	offChainCache = &combinedCache
 
+	status := cacheStatus(dataSource, offChainCache)
+	if status.Fallback {
+		if dataSource.Config.RequireRemoteCache {
+			return nil, status, fmt.Errorf("%w: %s", ErrRemoteCacheUnavailable, status.FallbackReason)
+		}
+		logger.Sugar().Warnf("[ActorsCache] - Falling back to the local cache: %s", status.FallbackReason)
+	}
+
 	logger.Sugar().Infof("[ActorsCache] - Actors cache initialized. Off chain cache implementation: %s", offChainCache.ImplementationType())
 
+	return newActorsCache(offChainCache, &onChainCache, status, logger), status, nil
+}
+
+// NewActorsCacheWithOffChain sets up the actors cache on top of an already initialized off-chain cache, e.g. one
+// shared with another service or a custom distributed implementation. The on-chain lookups use the node of the
+// data source. The off-chain cache is health checked if it implements HealthChecker, but never replaced.
+func NewActorsCacheWithOffChain(offChainCache IActorsCache, dataSource common.DataSource, logger *zap.Logger) (*ActorsCache, error) {
+	if offChainCache == nil {
+		return nil, errors.New("off chain cache is nil")
+	}
+	logger = logger2.GetSafeLogger(logger)
+
+	var onChainCache impl.OnChain
+	if err := onChainCache.NewImpl(dataSource, logger); err != nil {
+		return nil, err
+	}
+
+	status := CacheStatus{Backend: offChainCache.ImplementationType()}
+	if checker, ok := offChainCache.(HealthChecker); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), setupHealthCheckTimeout)
+		defer cancel()
+		if err := checker.HealthCheck(ctx); err != nil {
+			logger.Sugar().Warnf("[ActorsCache] - Off chain cache %s is unhealthy: %s", status.Backend, err)
+		}
+	}
+
+	logger.Sugar().Infof("[ActorsCache] - Actors cache initialized. Off chain cache implementation: %s", status.Backend)
+	return newActorsCache(offChainCache, &onChainCache, status, logger), nil
+}
+
+func newActorsCache(offChainCache, onChainCache IActorsCache, status CacheStatus, logger *zap.Logger) *ActorsCache {
 	return &ActorsCache{
 		offChainCache: offChainCache,
-		onChainCache:  &onChainCache,
+		onChainCache:  onChainCache,
 		badAddress:    cmap.New(),
+		epochs:        newEpochIndex(),
 		logger:        logger,
 		httpClient:    resty.New().SetTimeout(30 * time.Second),
-	}, nil
+		status:        status,
+	}
+}
+
+// cacheStatus checks whether the off-chain cache is backed by the remote store
+func cacheStatus(dataSource common.DataSource, offChainCache IActorsCache) CacheStatus {
+	status := CacheStatus{Backend: offChainCache.ImplementationType()}
+	if dataSource.Config.Cache == nil {
+		status.Fallback = true
+		status.FallbackReason = "no remote cache configured"
+		return status
+	}
+
+	checker, ok := offChainCache.(HealthChecker)
+	if !ok {
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupHealthCheckTimeout)
+	defer cancel()
+	if err := checker.HealthCheck(ctx); err != nil {
+		status.Fallback = true
+		status.FallbackReason = fmt.Sprintf("remote cache unreachable: %s", err)
+	}
+	return status
+}
+
+// Status returns the off-chain cache backend selected when the cache was set up
+func (a *ActorsCache) Status() CacheStatus {
+	return a.status
+}
+
+// HealthCheck checks the off-chain cache store, if its implementation depends on one
+func (a *ActorsCache) HealthCheck(ctx context.Context) error {
+	if checker, ok := a.offChainCache.(HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// SetHeadEpoch sets the epoch being parsed near the chain head. Entries fetched from the node from then on
+// are tagged with it, so they can be evicted with InvalidateAbove if the epoch is reorged.
+func (a *ActorsCache) SetHeadEpoch(epoch int64) {
+	a.epochs.setHead(epoch)
+}
+
+// InvalidateAbove evicts the entries stored while parsing epochs greater than the given one, as they may
+// belong to an orphaned fork (e.g. an actor created on it). It returns the amount of evicted entries.
+func (a *ActorsCache) InvalidateAbove(epoch int64) int {
+	removed := a.epochs.removeAbove(epoch)
+	for _, info := range removed {
+		// evictions are not cancelled, so no stale entry is left behind
+		if err := a.deleteAddressInfo(context.Background(), info); err != nil {
+			a.logger.Sugar().Errorf("[ActorsCache] - Unable to evict address info: %s", err.Error())
+		}
+	}
+
+	// Actors not found on an orphaned fork may exist on the canonical chain
+	a.badAddress.Clear()
+
+	a.logger.Sugar().Infof("[ActorsCache] - Invalidated %d entries above epoch %d", len(removed), epoch)
+	return len(removed)
+}
+
+// InvalidateActorCode evicts the cached actor code of the address, e.g. once the actor is deleted or promoted to
+// another actor type, so later lookups get it from the node. The short and robust addresses of the actor are kept,
+// they never change, not even after the actor is deleted. Nothing is evicted if the off-chain cache does not
+// implement ActorCodeDeleter.
+func (a *ActorsCache) InvalidateActorCode(add address.Address) {
+	deleter, ok := a.offChainCache.(ActorCodeDeleter)
+	if !ok {
+		return
+	}
+
+	// evictions are not cancelled, so no stale entry is left behind
+	ctx := context.Background()
+	short := add.String()
+	if add.Protocol() != address.ID {
+		var err error
+		if short, err = lookupShortAddress(ctx, a.offChainCache, add); err != nil {
+			// the actor is not cached
+			return
+		}
+	}
+
+	start := time.Now()
+	deleter.DeleteActorCode(ctx, short)
+	a.observeKvOp(KvOpDeleteActorCode, short, start, nil)
+	a.logger.Sugar().Debugf("[ActorsCache] - Invalidated actor code of %s (short: %s)", add.String(), short)
 }
 
 func (a *ActorsCache) ClearBadAddressCache() {
@@ -67,22 +211,39 @@ func (a *ActorsCache) ClearBadAddressCache() {
 }
 
 func (a *ActorsCache) GetActorCode(add address.Address, key filTypes.TipSetKey, onChainOnly bool) (string, error) {
+	return a.GetActorCodeWithContext(context.Background(), add, key, onChainOnly)
+}
+
+// GetActorCodeWithContext is GetActorCode, failing with the context error instead of looking the actor up
+// on-chain once the context is done
+func (a *ActorsCache) GetActorCodeWithContext(ctx context.Context, add address.Address, key filTypes.TipSetKey, onChainOnly bool) (_ string, err error) {
+	tier := parser.CacheTierOnChain
+	span := a.startLookupSpan(ctx, "GetActorCode")
+	defer func() { a.endLookupSpan(span, tier, err) }()
+
 	// Check if this address is flagged as bad
 	if a.isBadAddress(add) {
+		tier = parser.CacheTierBadAddress
 		return "", fmt.Errorf("address %s is flagged as bad", add.String())
 	}
 
 	if !onChainOnly {
-		actorCode, err := a.offChainCache.GetActorCode(add, key)
+		start := time.Now()
+		actorCode, err := lookupActorCode(ctx, a.offChainCache, add, key)
+		a.observeKvOp(KvOpGetActorCode, add.String(), start, err)
 		if err == nil {
+			tier = parser.CacheTierOffChain
 			return actorCode, nil
 		}
 	}
 
 	a.logger.Sugar().Debugf("[ActorsCache] - Unable to retrieve actor code from offchain cache for address %s. Trying on-chain cache", add.String())
 	// Try on-chain cache
-	actorCode, err := a.onChainCache.GetActorCode(add, key)
+	actorCode, err := lookupActorCode(ctx, a.onChainCache, add, key)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
 		a.logger.Sugar().Error("[ActorsCache] - Unable to retrieve actor code from node: %s", err.Error())
 		if strings.Contains(err.Error(), "actor not found") {
 			a.badAddress.Set(add.String(), true)
@@ -92,7 +253,7 @@ func (a *ActorsCache) GetActorCode(add address.Address, key filTypes.TipSetKey,
 	}
 
 	// Code is not cached, store it
-	err = a.storeActorCode(add, types.AddressInfo{
+	err = a.storeActorCode(ctx, add, types.AddressInfo{
 		ActorCid: actorCode,
 	})
 
@@ -105,32 +266,47 @@ func (a *ActorsCache) GetActorCode(add address.Address, key filTypes.TipSetKey,
 }
 
 func (a *ActorsCache) GetRobustAddress(add address.Address) (string, error) {
+	return a.GetRobustAddressWithContext(context.Background(), add)
+}
+
+// GetRobustAddressWithContext is GetRobustAddress, failing with the context error instead of looking the address
+// up on-chain once the context is done
+func (a *ActorsCache) GetRobustAddressWithContext(ctx context.Context, add address.Address) (_ string, err error) {
+	tier := parser.CacheTierOnChain
+	span := a.startLookupSpan(ctx, "GetRobustAddress")
+	defer func() { a.endLookupSpan(span, tier, err) }()
+
 	if _, ok := SystemActorsId[add.String()]; ok {
+		tier = parser.CacheTierSystem
 		return add.String(), nil
 	}
 
 	// Try offline store cache
-	robust, err := a.offChainCache.GetRobustAddress(add)
+	start := time.Now()
+	robust, err := lookupRobustAddress(ctx, a.offChainCache, add)
+	a.observeKvOp(KvOpGetRobustAddress, add.String(), start, err)
 	if err == nil {
+		tier = parser.CacheTierOffChain
 		return robust, nil
 	}
 
 	// Check if this is a flagged address
 	if a.isBadAddress(add) {
+		tier = parser.CacheTierBadAddress
 		return "", fmt.Errorf("address %s is flagged as bad", add.String())
 	}
 
 	a.logger.Sugar().Debugf("[ActorsCache] - Unable to retrieve robust address from offchain cache for address %s. Trying on-chain cache", add.String())
 
 	// Try on-chain cache
-	robust, err = a.onChainCache.GetRobustAddress(add)
+	robust, err = lookupRobustAddress(ctx, a.onChainCache, add)
 	if err != nil {
 		a.logger.Sugar().Errorf("[ActorsCache] - Unable to retrieve actor code from node: %s", err.Error())
 		return "", err
 	}
 
 	// Robust address is not cached, store it
-	err = a.storeRobustAddress(add, types.AddressInfo{
+	err = a.storeRobustAddress(ctx, add, types.AddressInfo{
 		Robust: robust,
 	})
 
@@ -143,28 +319,42 @@ func (a *ActorsCache) GetRobustAddress(add address.Address) (string, error) {
 }
 
 func (a *ActorsCache) GetShortAddress(add address.Address) (string, error) {
+	return a.GetShortAddressWithContext(context.Background(), add)
+}
+
+// GetShortAddressWithContext is GetShortAddress, failing with the context error instead of looking the address
+// up on-chain once the context is done
+func (a *ActorsCache) GetShortAddressWithContext(ctx context.Context, add address.Address) (_ string, err error) {
+	tier := parser.CacheTierOnChain
+	span := a.startLookupSpan(ctx, "GetShortAddress")
+	defer func() { a.endLookupSpan(span, tier, err) }()
+
 	// Try kv store cache
-	short, err := a.offChainCache.GetShortAddress(add)
+	start := time.Now()
+	short, err := lookupShortAddress(ctx, a.offChainCache, add)
+	a.observeKvOp(KvOpGetShortAddress, add.String(), start, err)
 	if err == nil {
+		tier = parser.CacheTierOffChain
 		return short, nil
 	}
 
 	// Check if this is a flagged address
 	if a.isBadAddress(add) {
+		tier = parser.CacheTierBadAddress
 		return "", fmt.Errorf("address %s is flagged as bad", add.String())
 	}
 
 	a.logger.Sugar().Debugf("[ActorsCache] - Unable to retrieve short address from offchain cache for address %s. Trying on-chain cache", add.String())
 
 	// Try on-chain cache
-	short, err = a.onChainCache.GetShortAddress(add)
+	short, err = lookupShortAddress(ctx, a.onChainCache, add)
 	if err != nil {
 		a.logger.Sugar().Error("[ActorsCache] - Unable to retrieve actor code from node: %s", err.Error())
 		return "", err
 	}
 
 	// Robust address is not cached, store it
-	err = a.storeShortAddress(add, types.AddressInfo{
+	err = a.storeShortAddress(ctx, add, types.AddressInfo{
 		Short: short,
 	})
 
@@ -176,8 +366,42 @@ func (a *ActorsCache) GetShortAddress(add address.Address) (string, error) {
 	return short, nil
 }
 
+// lookupActorCode looks the actor code up in the given cache, cancelling the lookup with the context if the cache
+// supports it, see ContextLookup
+func lookupActorCode(ctx context.Context, cache IActorsCache, add address.Address, key filTypes.TipSetKey) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if lookup, ok := cache.(ContextLookup); ok {
+		return lookup.GetActorCodeWithContext(ctx, add, key)
+	}
+	return cache.GetActorCode(add, key)
+}
+
+func lookupRobustAddress(ctx context.Context, cache IActorsCache, add address.Address) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if lookup, ok := cache.(ContextLookup); ok {
+		return lookup.GetRobustAddressWithContext(ctx, add)
+	}
+	return cache.GetRobustAddress(add)
+}
+
+func lookupShortAddress(ctx context.Context, cache IActorsCache, add address.Address) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if lookup, ok := cache.(ContextLookup); ok {
+		return lookup.GetShortAddressWithContext(ctx, add)
+	}
+	return cache.GetShortAddress(add)
+}
+
 func (a *ActorsCache) GetEVMSelectorSig(ctx context.Context, selectorID string) (string, error) {
+	start := time.Now()
 	selectorSig, err := a.offChainCache.GetEVMSelectorSig(ctx, selectorID)
+	a.observeKvOp(KvOpGetEVMSelectorSig, selectorID, start, err)
 	if err != nil {
 		return "", err
 	}
@@ -186,6 +410,10 @@ func (a *ActorsCache) GetEVMSelectorSig(ctx context.Context, selectorID string)
 		return selectorSig, nil
 	}
 
+	if a.offline {
+		return "", fmt.Errorf("signature not found: %s", selectorID)
+	}
+
 	// not found in cache
 	resp, err := a.httpClient.NewRequest().
 		SetQueryParam("hex_signature", selectorID).
@@ -211,54 +439,135 @@ func (a *ActorsCache) GetEVMSelectorSig(ctx context.Context, selectorID string)
 
 	sig := signatureData.Results[0].TextSignature
 
-	if err := a.offChainCache.StoreEVMSelectorSig(ctx, selectorID, sig); err != nil {
+	if err := a.StoreEVMSelectorSig(ctx, selectorID, sig); err != nil {
 		return selectorSig, fmt.Errorf("error adding selector_sig to cache: %w", err)
 	}
 	return sig, nil
 }
 
-func (a *ActorsCache) storeActorCode(add address.Address, info types.AddressInfo) error {
-	shortAddress, err := a.GetShortAddress(add)
+// StoreEVMSelectorSig stores the signature of the selector in the off-chain cache
+func (a *ActorsCache) StoreEVMSelectorSig(ctx context.Context, selectorID, selectorSig string) error {
+	start := time.Now()
+	err := a.offChainCache.StoreEVMSelectorSig(ctx, selectorID, selectorSig)
+	a.observeKvOp(KvOpStoreEVMSelectorSig, selectorID, start, err)
+	return err
+}
+
+// DisableSignatureLookup stops querying SignatureDBURL for the selectors missing in the cache,
+// so no request leaves the process. Missing selectors are reported as not found.
+func (a *ActorsCache) DisableSignatureLookup() {
+	a.offline = true
+}
+
+func (a *ActorsCache) storeActorCode(ctx context.Context, add address.Address, info types.AddressInfo) error {
+	shortAddress, err := a.GetShortAddressWithContext(ctx, add)
 	if err != nil {
 		return err
 	}
 
-	a.offChainCache.StoreAddressInfo(types.AddressInfo{
+	// the info was looked up already, so a failing write is only reported
+	a.logStoreError(a.storeAddressInfo(ctx, types.AddressInfo{
 		Short:    shortAddress,
 		ActorCid: info.ActorCid,
-	})
+	}))
 
 	return nil
 }
 
-func (a *ActorsCache) storeShortAddress(add address.Address, info types.AddressInfo) error {
-	robustAddress, err := a.GetRobustAddress(add)
+func (a *ActorsCache) storeShortAddress(ctx context.Context, add address.Address, info types.AddressInfo) error {
+	robustAddress, err := a.GetRobustAddressWithContext(ctx, add)
 	if err != nil {
 		return err
 	}
 
-	a.offChainCache.StoreAddressInfo(types.AddressInfo{
+	// the info was looked up already, so a failing write is only reported
+	a.logStoreError(a.storeAddressInfo(ctx, types.AddressInfo{
 		Short:  info.Short,
 		Robust: robustAddress,
-	})
+	}))
 
 	return nil
 }
 
-func (a *ActorsCache) storeRobustAddress(add address.Address, info types.AddressInfo) error {
-	shortAddress, err := a.GetShortAddress(add)
+func (a *ActorsCache) storeRobustAddress(ctx context.Context, add address.Address, info types.AddressInfo) error {
+	shortAddress, err := a.GetShortAddressWithContext(ctx, add)
 	if err != nil {
 		return err
 	}
 
-	a.offChainCache.StoreAddressInfo(types.AddressInfo{
+	// the info was looked up already, so a failing write is only reported
+	a.logStoreError(a.storeAddressInfo(ctx, types.AddressInfo{
 		Short:  shortAddress,
 		Robust: info.Robust,
-	})
+	}))
 
 	return nil
 }
 
+// storeAddressInfo stores the info in the off-chain cache, tagged with the head epoch. The write is cancelled
+// with the context if the cache supports it, see ContextStore. The errors of the caches not implementing
+// ContextStore are not known.
+func (a *ActorsCache) storeAddressInfo(ctx context.Context, info types.AddressInfo) error {
+	start := time.Now()
+	var err error
+	if store, ok := a.offChainCache.(ContextStore); ok {
+		err = store.StoreAddressInfoWithContext(ctx, info)
+	} else {
+		a.offChainCache.StoreAddressInfo(info)
+	}
+	a.observeKvOp(KvOpStoreAddressInfo, addressInfoKey(info), start, err)
+	a.epochs.tag(info)
+	return err
+}
+
+// deleteAddressInfo removes the info from the off-chain cache, see storeAddressInfo
+func (a *ActorsCache) deleteAddressInfo(ctx context.Context, info types.AddressInfo) error {
+	start := time.Now()
+	var err error
+	switch store := a.offChainCache.(type) {
+	case ContextStore:
+		err = store.DeleteAddressInfoWithContext(ctx, info)
+	case addressInfoDeleter:
+		store.DeleteAddressInfo(info)
+	default:
+		return nil
+	}
+	a.observeKvOp(KvOpDeleteAddressInfo, addressInfoKey(info), start, err)
+	return err
+}
+
+func (a *ActorsCache) logStoreError(err error) {
+	if err != nil {
+		a.logger.Sugar().Errorf("[ActorsCache] - Unable to store address info: %s", err.Error())
+	}
+}
+
+// SetTracer sets the tracer used to record a span for every address lookup, with the tier that served it
+func (a *ActorsCache) SetTracer(tracer trace.Tracer) {
+	a.tracer = tracer
+}
+
+// startLookupSpan starts the span of a lookup, as a child of the span carried by the context if any
+func (a *ActorsCache) startLookupSpan(ctx context.Context, method string) trace.Span {
+	if a.tracer == nil {
+		return nil
+	}
+	_, span := a.tracer.Start(ctx, parser.SpanCacheLookup, trace.WithAttributes(parser.AttributeCacheMethod.String(method)))
+	return span
+}
+
+func (a *ActorsCache) endLookupSpan(span trace.Span, tier string, err error) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(parser.AttributeCacheTier.String(tier))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 func (a *ActorsCache) isBadAddress(add address.Address) bool {
 	_, bad := a.badAddress.Get(add.String())
 	return bad
@@ -1,7 +1,295 @@
 package cache
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/types"
+	"github.com/zondax/golem/pkg/zcache"
+)
+
+// lightNode only implements the node methods used by the on-chain cache
+type lightNode struct {
+	robust map[address.Address]address.Address
+	codes  map[address.Address]cid.Cid
+}
+
+func (n *lightNode) StateGetActor(_ context.Context, addr address.Address, _ filTypes.TipSetKey) (*filTypes.Actor, error) {
+	code, ok := n.codes[addr]
+	if !ok {
+		return nil, common.ErrKeyNotFound
+	}
+	return &filTypes.Actor{Code: code}, nil
+}
+
+func (n *lightNode) StateLookupID(_ context.Context, addr address.Address, _ filTypes.TipSetKey) (address.Address, error) {
+	for short, robust := range n.robust {
+		if robust == addr {
+			return short, nil
+		}
+	}
+	return address.Undef, common.ErrKeyNotFound
+}
+
+func (n *lightNode) StateAccountKey(_ context.Context, addr address.Address, _ filTypes.TipSetKey) (address.Address, error) {
+	robust, ok := n.robust[addr]
+	if !ok {
+		return address.Undef, common.ErrKeyNotFound
+	}
+	return robust, nil
+}
+
+func TestNewActorsCacheWithOffChain(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+
+	// the off-chain cache is shared by both actors caches, so the address resolved by the first one
+	// is served to the second one without a node
+	dataSource := common.DataSource{CacheNode: &lightNode{robust: map[address.Address]address.Address{short: robust}}}
+	var offChainCache impl.ZCache
+	require.NoError(t, offChainCache.NewImpl(dataSource, nil))
+
+	first, err := NewActorsCacheWithOffChain(&offChainCache, dataSource, nil)
+	require.NoError(t, err)
+	require.Equal(t, offChainCache.ImplementationType(), first.Status().Backend)
+	got, err := first.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robust.String(), got)
+
+	second, err := NewActorsCacheWithOffChain(&offChainCache, common.DataSource{CacheNode: &lightNode{}}, nil)
+	require.NoError(t, err)
+	got, err = second.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robust.String(), got)
+
+	_, err = NewActorsCacheWithOffChain(nil, dataSource, nil)
+	require.Error(t, err)
+}
 
 func TestSetupActorsCache(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+
+	actorsCache, err := SetupActorsCache(common.DataSource{
+		CacheNode: &lightNode{robust: map[address.Address]address.Address{short: robust}},
+	}, nil)
+	require.NoError(t, err)
+
+	got, err := actorsCache.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robust.String(), got)
+
+	got, err = actorsCache.GetShortAddress(robust)
+	require.NoError(t, err)
+	require.Equal(t, short.String(), got)
+}
+
+func TestActorsCache_InvalidateAbove(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+
+	node := &lightNode{robust: map[address.Address]address.Address{short: robust}}
+	actorsCache, err := SetupActorsCache(common.DataSource{CacheNode: node}, nil)
+	require.NoError(t, err)
+
+	actorsCache.SetHeadEpoch(100)
+	_, err = actorsCache.GetRobustAddress(short)
+	require.NoError(t, err)
+
+	// entries stored at the head are kept if the reorg is above it
+	require.Zero(t, actorsCache.InvalidateAbove(100))
+
+	// the actor only existed on the orphaned fork
+	delete(node.robust, short)
+	require.Equal(t, 1, actorsCache.InvalidateAbove(99))
+	_, err = actorsCache.GetRobustAddress(short)
+	require.Error(t, err)
+}
+
+func TestEpochIndex_SetHead(t *testing.T) {
+	index := newEpochIndex()
+	index.tag(types.AddressInfo{Short: "f01000"})
+	require.Empty(t, index.entries, "nothing is tracked without a head")
+
+	index.setHead(10)
+	index.tag(types.AddressInfo{Short: "f01000", Robust: "f1robust"})
+	index.setHead(10 + ReorgEpochWindow)
+	require.Empty(t, index.entries, "final entries are forgotten")
+
+	require.Empty(t, index.buckets)
+
+	// older epochs do not move the head back
+	index.setHead(5)
+	require.Equal(t, int64(10+ReorgEpochWindow), index.head)
+
+	index.tag(types.AddressInfo{Short: "f01001", ActorCid: "bafkcode"})
+	require.Equal(t, []types.AddressInfo{{Short: "f01001", ActorCid: "bafkcode"}}, index.removeAbove(10))
+	require.Equal(t, int64(10), index.head)
+	require.Empty(t, index.buckets)
+
+	// entries tagged again are kept until their latest epoch is final
+	index.setHead(20)
+	index.tag(types.AddressInfo{Short: "f01002"})
+	index.setHead(30)
+	index.tag(types.AddressInfo{Short: "f01002", Robust: "f1robust"})
+	index.setHead(20 + ReorgEpochWindow)
+	require.Len(t, index.entries, 1)
+	require.Equal(t, []types.AddressInfo{{Short: "f01002", Robust: "f1robust"}}, index.removeAbove(29))
+	require.Empty(t, index.entries)
+}
+
+func TestSetupActorsCache_OnChainRateLimit(t *testing.T) {
+	actorsCache, err := SetupActorsCache(common.DataSource{
+		CacheNode: &lightNode{},
+		Config:    common.DataSourceConfig{OnChainRateLimit: &common.RateLimitConfig{RequestsPerSecond: 20}},
+	}, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := uint64(0); i < 3; i++ {
+		short, err := address.NewIDAddress(2000 + i)
+		require.NoError(t, err)
+		_, err = actorsCache.GetRobustAddress(short)
+		require.Error(t, err)
+	}
+	// the first request uses the burst, the next two wait 50ms each
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+// slowNode blocks every request until it is cancelled
+type slowNode struct {
+	lightNode
+}
+
+func (n *slowNode) StateAccountKey(ctx context.Context, _ address.Address, _ filTypes.TipSetKey) (address.Address, error) {
+	<-ctx.Done()
+	return address.Undef, ctx.Err()
+}
+
+func TestSetupActorsCache_OnChainTimeout(t *testing.T) {
+	actorsCache, err := SetupActorsCache(common.DataSource{
+		CacheNode: &slowNode{},
+		Config:    common.DataSourceConfig{OnChainTimeout: 50 * time.Millisecond},
+	}, nil)
+	require.NoError(t, err)
+
+	short, err := address.NewIDAddress(3000)
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = actorsCache.GetRobustAddress(short)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestActorsCache_LookupsWithContext(t *testing.T) {
+	actorsCache, err := SetupActorsCache(common.DataSource{CacheNode: &slowNode{}}, nil)
+	require.NoError(t, err)
+
+	short, err := address.NewIDAddress(3000)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the stuck lookup is aborted with the context, without the on-chain timeout
+	start := time.Now()
+	_, err = actorsCache.GetRobustAddressWithContext(ctx, short)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	// lookups are not started once the context is done, and the address is not flagged as bad
+	_, err = actorsCache.GetActorCodeWithContext(ctx, short, filTypes.EmptyTSK, false)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, actorsCache.isBadAddress(short))
+}
+
+func TestSetupActorsCacheWithStatus(t *testing.T) {
+	unreachable := &zcache.CombinedConfig{
+		IsRemoteBestEffort: true,
+		Remote:             &zcache.RemoteConfig{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond},
+	}
+
+	tests := []struct {
+		name         string
+		config       common.DataSourceConfig
+		wantErr      error
+		wantFallback bool
+	}{
+		{name: "no remote cache", wantFallback: true},
+		{name: "no remote cache required", config: common.DataSourceConfig{RequireRemoteCache: true}, wantErr: ErrRemoteCacheUnavailable},
+		{name: "unreachable remote cache", config: common.DataSourceConfig{Cache: unreachable}, wantFallback: true},
+		{name: "unreachable remote cache required", config: common.DataSourceConfig{Cache: unreachable, RequireRemoteCache: true}, wantErr: ErrRemoteCacheUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actorsCache, status, err := SetupActorsCacheWithStatus(common.DataSource{CacheNode: &lightNode{}, Config: tt.config}, nil)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFallback, status.Fallback)
+			require.NotEmpty(t, status.FallbackReason)
+			require.Equal(t, status, actorsCache.Status())
+		})
+	}
+}
+
+func TestActorsCache_InvalidateActorCode(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+	paychCode, err := cid.Decode("bafk2bzacebalad3f72wyk7qyilvfjijcwubdspytnyzlrhvn73254gqis44rq")
+	require.NoError(t, err)
+	evmCode, err := cid.Decode("bafk2bzacebmvlbfzmzxtnmb7ku2xfnzhuvf6rlaxsqwe7xqemrmtt46b5zzqy")
+	require.NoError(t, err)
+
+	node := &lightNode{
+		robust: map[address.Address]address.Address{short: robust},
+		codes:  map[address.Address]cid.Cid{short: paychCode},
+	}
+	actorsCache, err := SetupActorsCache(common.DataSource{CacheNode: node}, nil)
+	require.NoError(t, err)
+
+	got, err := actorsCache.GetShortAddress(robust)
+	require.NoError(t, err)
+	require.Equal(t, short.String(), got)
+	got, err = actorsCache.GetActorCode(short, filTypes.EmptyTSK, false)
+	require.NoError(t, err)
+	require.Equal(t, paychCode.String(), got)
+
+	// the code of the actor changes on chain, the cached one is served until it is invalidated
+	node.codes[short] = evmCode
+	got, err = actorsCache.GetActorCode(short, filTypes.EmptyTSK, false)
+	require.NoError(t, err)
+	require.Equal(t, paychCode.String(), got)
+
+	actorsCache.InvalidateActorCode(robust)
+	got, err = actorsCache.GetActorCode(short, filTypes.EmptyTSK, false)
+	require.NoError(t, err)
+	require.Equal(t, evmCode.String(), got)
 
+	// the addresses are kept, they are still resolved once the actor is gone from the node
+	delete(node.robust, short)
+	delete(node.codes, short)
+	actorsCache.InvalidateActorCode(short)
+	got, err = actorsCache.GetShortAddress(robust)
+	require.NoError(t, err)
+	require.Equal(t, short.String(), got)
+	got, err = actorsCache.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robust.String(), got)
 }
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// ReorgEpochWindow is the amount of epochs behind the head that can still be reorged (chain finality).
+// Entries stored before that window are considered final and are no longer tracked.
+const ReorgEpochWindow = 900
+
+// epochIndex tracks the entries stored in the off-chain cache while following the chain head, tagged with
+// the head epoch at the moment they were stored, so they can be evicted if those epochs are reorged.
+type epochIndex struct {
+	mu      sync.Mutex
+	head    int64
+	entries map[string]taggedEntry
+	// buckets are the keys tagged at each epoch, in ascending epoch order, so the final and the reorged entries
+	// are found without scanning every entry. The keys of the entries tagged again later are left behind in their
+	// older buckets, and skipped as their epoch does not match.
+	buckets []epochBucket
+}
+
+type taggedEntry struct {
+	info  types.AddressInfo
+	epoch int64
+}
+
+type epochBucket struct {
+	epoch int64
+	keys  []string
+}
+
+func newEpochIndex() *epochIndex {
+	return &epochIndex{entries: make(map[string]taggedEntry)}
+}
+
+// setHead moves the head forward, forgetting the entries that are final. Epochs behind the head are ignored.
+func (e *epochIndex) setHead(epoch int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if epoch <= e.head {
+		return
+	}
+	e.head = epoch
+
+	final := 0
+	for ; final < len(e.buckets) && e.buckets[final].epoch <= epoch-ReorgEpochWindow; final++ {
+		e.forget(e.buckets[final])
+		e.buckets[final] = epochBucket{}
+	}
+	e.buckets = e.buckets[final:]
+}
+
+// tag records the entry with the current head epoch. Nothing is tracked until a head is set.
+func (e *epochIndex) tag(info types.AddressInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.head <= 0 || info.Short == "" {
+		return
+	}
+
+	entry, ok := e.entries[info.Short]
+	if !ok || entry.epoch != e.head {
+		if last := len(e.buckets) - 1; last >= 0 && e.buckets[last].epoch == e.head {
+			e.buckets[last].keys = append(e.buckets[last].keys, info.Short)
+		} else {
+			e.buckets = append(e.buckets, epochBucket{epoch: e.head, keys: []string{info.Short}})
+		}
+	}
+	// the head only moves back with removeAbove, which forgets the entries above it
+	entry.epoch = e.head
+	if info.Robust != "" {
+		entry.info.Robust = info.Robust
+	}
+	if info.ActorCid != "" {
+		entry.info.ActorCid = info.ActorCid
+	}
+	entry.info.Short = info.Short
+	e.entries[info.Short] = entry
+}
+
+// removeAbove forgets and returns the entries tagged with an epoch greater than the given one, and moves the head back
+func (e *epochIndex) removeAbove(epoch int64) []types.AddressInfo {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var removed []types.AddressInfo
+	for len(e.buckets) > 0 && e.buckets[len(e.buckets)-1].epoch > epoch {
+		last := len(e.buckets) - 1
+		removed = append(removed, e.forget(e.buckets[last])...)
+		e.buckets = e.buckets[:last]
+	}
+
+	e.head = min(e.head, epoch)
+	return removed
+}
+
+// forget removes the entries still tagged with the epoch of the bucket, and returns them
+func (e *epochIndex) forget(bucket epochBucket) []types.AddressInfo {
+	var forgotten []types.AddressInfo
+	for _, key := range bucket.keys {
+		if entry, ok := e.entries[key]; ok && entry.epoch == bucket.epoch {
+			forgotten = append(forgotten, entry.info)
+			delete(e.entries, key)
+		}
+	}
+	return forgotten
+}
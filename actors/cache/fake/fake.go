@@ -0,0 +1,150 @@
+// Package fake provides a DataSource answering from canned state, so the parser can run without a node.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/actors/cache"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"go.uber.org/zap"
+)
+
+// Actor is the canned state of an actor. Robust is empty for actors without a robust address.
+type Actor struct {
+	Short  string `json:"short"`
+	Robust string `json:"robust,omitempty"`
+	Code   string `json:"code"`
+}
+
+// State is the canned chain state served by the fake node
+type State struct {
+	Actors []Actor `json:"actors"`
+	// Signatures maps evm selectors to their text signature
+	Signatures map[string]string `json:"signatures,omitempty"`
+}
+
+// LoadState reads the canned state from a json file
+func LoadState(path string) (State, error) {
+	var state State
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, fmt.Errorf("could not read state file %s: %w", path, err)
+	}
+
+	if err = json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("could not decode state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+type actorEntry struct {
+	short  address.Address
+	robust address.Address
+	code   cid.Cid
+}
+
+// Node implements common.NodeAPI on top of the canned state. Its answers only depend on the state,
+// never on the tipset key, so the same input always resolves to the same output.
+type Node struct {
+	actors map[string]*actorEntry
+}
+
+// NewNode indexes the actors of the state by their short and robust addresses
+func NewNode(state State) (*Node, error) {
+	node := &Node{actors: make(map[string]*actorEntry, 2*len(state.Actors))}
+	for _, actor := range state.Actors {
+		entry := &actorEntry{robust: address.Undef}
+
+		var err error
+		if entry.short, err = address.NewFromString(actor.Short); err != nil {
+			return nil, fmt.Errorf("invalid short address %s: %w", actor.Short, err)
+		}
+		if entry.short.Protocol() != address.ID {
+			return nil, fmt.Errorf("short address %s is not an id address", actor.Short)
+		}
+		if entry.code, err = cid.Decode(actor.Code); err != nil {
+			return nil, fmt.Errorf("invalid code %s of actor %s: %w", actor.Code, actor.Short, err)
+		}
+		if actor.Robust != "" {
+			if entry.robust, err = address.NewFromString(actor.Robust); err != nil {
+				return nil, fmt.Errorf("invalid robust address %s: %w", actor.Robust, err)
+			}
+			node.actors[entry.robust.String()] = entry
+		}
+		node.actors[entry.short.String()] = entry
+	}
+	return node, nil
+}
+
+func (n *Node) lookup(addr address.Address) (*actorEntry, error) {
+	entry, ok := n.actors[addr.String()]
+	if !ok {
+		// the actors cache flags the address as bad when it finds this message
+		return nil, fmt.Errorf("actor not found: %s", addr.String())
+	}
+	return entry, nil
+}
+
+func (n *Node) StateGetActor(_ context.Context, addr address.Address, _ filTypes.TipSetKey) (*filTypes.Actor, error) {
+	entry, err := n.lookup(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &filTypes.Actor{Code: entry.code}, nil
+}
+
+func (n *Node) StateLookupID(_ context.Context, addr address.Address, _ filTypes.TipSetKey) (address.Address, error) {
+	entry, err := n.lookup(addr)
+	if err != nil {
+		return address.Undef, err
+	}
+	return entry.short, nil
+}
+
+func (n *Node) StateAccountKey(_ context.Context, addr address.Address, _ filTypes.TipSetKey) (address.Address, error) {
+	entry, err := n.lookup(addr)
+	if err != nil {
+		return address.Undef, err
+	}
+	if entry.robust == address.Undef {
+		return address.Undef, fmt.Errorf("actor %s has no robust address", addr.String())
+	}
+	return entry.robust, nil
+}
+
+// NewDataSource returns a DataSource backed by the fake node and the in-memory cache
+func NewDataSource(state State) (common.DataSource, error) {
+	node, err := NewNode(state)
+	if err != nil {
+		return common.DataSource{}, err
+	}
+	return common.DataSource{CacheNode: node}, nil
+}
+
+// NewActorsCache sets up an actors cache over the fake DataSource, with the signatures of the state
+// preloaded and the remote signature lookup disabled, so it never reaches the network.
+func NewActorsCache(state State, logger *zap.Logger) (*cache.ActorsCache, error) {
+	dataSource, err := NewDataSource(state)
+	if err != nil {
+		return nil, err
+	}
+
+	actorsCache, err := cache.SetupActorsCache(dataSource, logger)
+	if err != nil {
+		return nil, err
+	}
+	actorsCache.DisableSignatureLookup()
+
+	for selectorID, selectorSig := range state.Signatures {
+		if err = actorsCache.StoreEVMSelectorSig(context.Background(), selectorID, selectorSig); err != nil {
+			return nil, err
+		}
+	}
+	return actorsCache, nil
+}
@@ -0,0 +1,94 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	accountCode = "bafk2bzacedudbf7fc5va57t3tmo63snmt3en4iaidv4vo3qlyacbxaa6hlx6y"
+	robustAddr  = "f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea"
+)
+
+var testState = State{
+	Actors: []Actor{
+		{Short: "f01000", Robust: robustAddr, Code: accountCode},
+		{Short: "f01001", Code: accountCode},
+	},
+	Signatures: map[string]string{"0xddf252ad": "Transfer(address,address,uint256)"},
+}
+
+func TestNode(t *testing.T) {
+	node, err := NewNode(testState)
+	require.NoError(t, err)
+
+	short, err := address.NewFromString("f01000")
+	require.NoError(t, err)
+	robust, err := address.NewFromString(robustAddr)
+	require.NoError(t, err)
+	unknown, err := address.NewFromString("f09999")
+	require.NoError(t, err)
+
+	actor, err := node.StateGetActor(context.Background(), robust, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, accountCode, actor.Code.String())
+
+	id, err := node.StateLookupID(context.Background(), robust, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, short, id)
+
+	key, err := node.StateAccountKey(context.Background(), short, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, robust, key)
+
+	_, err = node.StateGetActor(context.Background(), unknown, filTypes.EmptyTSK)
+	require.ErrorContains(t, err, "actor not found")
+}
+
+func TestNewNode_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor Actor
+	}{
+		{name: "invalid short", actor: Actor{Short: "x", Code: accountCode}},
+		{name: "robust as short", actor: Actor{Short: robustAddr, Code: accountCode}},
+		{name: "invalid code", actor: Actor{Short: "f01000", Code: "x"}},
+		{name: "invalid robust", actor: Actor{Short: "f01000", Robust: "x", Code: accountCode}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNode(State{Actors: []Actor{tt.actor}})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNewActorsCache(t *testing.T) {
+	actorsCache, err := NewActorsCache(testState, nil)
+	require.NoError(t, err)
+
+	short, err := address.NewFromString("f01000")
+	require.NoError(t, err)
+	noRobust, err := address.NewFromString("f01001")
+	require.NoError(t, err)
+
+	robust, err := actorsCache.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robustAddr, robust)
+
+	code, err := actorsCache.GetActorCode(noRobust, filTypes.EmptyTSK, false)
+	require.NoError(t, err)
+	require.Equal(t, accountCode, code)
+
+	sig, err := actorsCache.GetEVMSelectorSig(context.Background(), "0xddf252ad")
+	require.NoError(t, err)
+	require.Equal(t, "Transfer(address,address,uint256)", sig)
+
+	// unknown selectors are not looked up remotely
+	_, err = actorsCache.GetEVMSelectorSig(context.Background(), "0x12345678")
+	require.ErrorContains(t, err, "signature not found")
+}
@@ -1,7 +1,12 @@
 package common
 
 import (
-	"github.com/filecoin-project/lotus/api"
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/types"
 	"github.com/zondax/golem/pkg/zcache"
 	"github.com/zondax/znats/znats"
 	"gorm.io/gorm"
@@ -12,10 +17,47 @@ type DataSourceConfig struct {
 	Cache          *zcache.CombinedConfig
 	InputTableName string
 	NetworkName    string
+	// OnChainRateLimit is optional. If set, the requests of the on-chain cache to the node are rate limited
+	OnChainRateLimit *RateLimitConfig
+	// OnChainTimeout is optional. If set, every lookup of the on-chain cache is cancelled after it, so a slow
+	// node can not consume the whole time budget of a height
+	OnChainTimeout time.Duration
+	// RequireRemoteCache makes the actors cache setup fail instead of falling back to an in-memory
+	// cache when no remote cache is configured or it cannot be reached
+	RequireRemoteCache bool
+}
+
+// RateLimitConfig configures a token bucket rate limit
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of requests allowed
+	RequestsPerSecond float64
+	// Burst is the amount of requests allowed at once. It defaults to 1.
+	Burst int
+}
+
+// NodeAPI is the subset of the node api used by the on-chain cache. It allows backing the cache with a
+// lightweight client (e.g. a caching proxy) instead of a full node.
+type NodeAPI interface {
+	StateGetActor(ctx context.Context, actor address.Address, tsk filTypes.TipSetKey) (*filTypes.Actor, error)
+	StateLookupID(ctx context.Context, addr address.Address, tsk filTypes.TipSetKey) (address.Address, error)
+	StateAccountKey(ctx context.Context, addr address.Address, tsk filTypes.TipSetKey) (address.Address, error)
 }
 
 type DataSource struct {
-	Node   api.FullNode
-	Db     *gorm.DB
-	Config DataSourceConfig
+	Node types.FullNode
+	// CacheNode is optional. If set, the on-chain cache uses it instead of Node
+	CacheNode NodeAPI
+	Db        *gorm.DB
+	Config    DataSourceConfig
+}
+
+// GetCacheNode returns the node api used by the on-chain cache
+func (d DataSource) GetCacheNode() NodeAPI {
+	if d.CacheNode != nil {
+		return d.CacheNode
+	}
+	if d.Node != nil {
+		return d.Node
+	}
+	return nil
 }
@@ -3,23 +3,26 @@ package impl
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/filecoin-project/go-address"
-	"github.com/filecoin-project/lotus/api"
 	filTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/ipfs/go-cid"
 	"github.com/zondax/fil-parser/actors/cache/impl/common"
 	logger2 "github.com/zondax/fil-parser/logger"
 	"github.com/zondax/fil-parser/types"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 const OnChainImpl = "on-chain"
 
 // OnChain implementation
 type OnChain struct {
-	Node   api.FullNode
-	logger *zap.Logger
+	Node    common.NodeAPI
+	limiter *rate.Limiter
+	timeout time.Duration
+	logger  *zap.Logger
 }
 
 func (m *OnChain) StoreAddressInfo(info types.AddressInfo) {
@@ -34,20 +37,49 @@ func (m *OnChain) BackFill() error {
 func (m *OnChain) NewImpl(source common.DataSource, logger *zap.Logger) error {
 	// Node datastore is required
 	m.logger = logger2.GetSafeLogger(logger)
-	if source.Node == nil {
+	node := source.GetCacheNode()
+	if node == nil {
 		m.logger.Sugar().Panic("[ActorsCache] - Node ptr is nil")
 	}
 
-	m.Node = source.Node
+	m.Node = node
+	m.timeout = source.Config.OnChainTimeout
+
+	if limit := source.Config.OnChainRateLimit; limit != nil && limit.RequestsPerSecond > 0 {
+		m.limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), max(limit.Burst, 1))
+		m.logger.Sugar().Infof("[ActorsCache] - On-chain requests limited to %.2f/s (burst %d)", limit.RequestsPerSecond, m.limiter.Burst())
+	}
 	return nil
 }
 
+// lookupContext returns the context of a lookup, cancelled with the parent context or after the OnChainTimeout
+// if it is set
+func (m *OnChain) lookupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, m.timeout)
+}
+
+// wait blocks until the rate limit allows a new request to the node
+func (m *OnChain) wait(ctx context.Context) error {
+	if m.limiter == nil {
+		return nil
+	}
+	return m.limiter.Wait(ctx)
+}
+
 func (m *OnChain) ImplementationType() string {
 	return OnChainImpl
 }
 
 func (m *OnChain) GetActorCode(address address.Address, key filTypes.TipSetKey) (string, error) {
-	actorCid, err := m.retrieveActorFromLotus(address, key)
+	return m.GetActorCodeWithContext(context.Background(), address, key)
+}
+
+// GetActorCodeWithContext is GetActorCode, cancelling the node requests once the context is done
+func (m *OnChain) GetActorCodeWithContext(ctx context.Context, address address.Address, key filTypes.TipSetKey) (string, error) {
+	actorCid, err := m.retrieveActorFromLotus(ctx, address, key)
 	if err != nil {
 		return cid.Undef.String(), err
 	}
@@ -56,6 +88,11 @@ func (m *OnChain) GetActorCode(address address.Address, key filTypes.TipSetKey)
 }
 
 func (m *OnChain) GetRobustAddress(address address.Address) (string, error) {
+	return m.GetRobustAddressWithContext(context.Background(), address)
+}
+
+// GetRobustAddressWithContext is GetRobustAddress, cancelling the node requests once the context is done
+func (m *OnChain) GetRobustAddressWithContext(ctx context.Context, address address.Address) (string, error) {
 	isRobustAddress, err := common.IsRobustAddress(address)
 	if err != nil {
 		return "", err
@@ -67,7 +104,7 @@ func (m *OnChain) GetRobustAddress(address address.Address) (string, error) {
 	}
 
 	// Address is not in cache, get robust address from lotus
-	robustAdd, err := m.retrieveActorPubKeyFromLotus(address, false)
+	robustAdd, err := m.retrieveActorPubKeyFromLotus(ctx, address, false)
 	if err != nil {
 		return "", err
 	}
@@ -76,6 +113,11 @@ func (m *OnChain) GetRobustAddress(address address.Address) (string, error) {
 }
 
 func (m *OnChain) GetShortAddress(address address.Address) (string, error) {
+	return m.GetShortAddressWithContext(context.Background(), address)
+}
+
+// GetShortAddressWithContext is GetShortAddress, cancelling the node requests once the context is done
+func (m *OnChain) GetShortAddressWithContext(ctx context.Context, address address.Address) (string, error) {
 	isRobustAddress, err := common.IsRobustAddress(address)
 	if err != nil {
 		return "", err
@@ -86,19 +128,30 @@ func (m *OnChain) GetShortAddress(address address.Address) (string, error) {
 		return address.String(), nil
 	}
 
-	shortAdd, err := m.retrieveActorPubKeyFromLotus(address, true)
+	shortAdd, err := m.retrieveActorPubKeyFromLotus(ctx, address, true)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
 		return "", common.ErrKeyNotFound
 	}
 
 	return shortAdd, nil
 }
 
-func (m *OnChain) retrieveActorFromLotus(add address.Address, key filTypes.TipSetKey) (cid.Cid, error) {
-	actor, err := m.Node.StateGetActor(context.Background(), add, filTypes.EmptyTSK)
+func (m *OnChain) retrieveActorFromLotus(ctx context.Context, add address.Address, key filTypes.TipSetKey) (cid.Cid, error) {
+	ctx, cancel := m.lookupContext(ctx)
+	defer cancel()
+	if err := m.wait(ctx); err != nil {
+		return cid.Cid{}, err
+	}
+	actor, err := m.Node.StateGetActor(ctx, add, filTypes.EmptyTSK)
 	if err != nil {
 		// Try again but using the corresponding tipset Key
-		actor, err = m.Node.StateGetActor(context.Background(), add, key)
+		if err = m.wait(ctx); err != nil {
+			return cid.Cid{}, err
+		}
+		actor, err = m.Node.StateGetActor(ctx, add, key)
 		if err != nil {
 			m.logger.Sugar().Errorf("[ActorsCache] - retrieveActorFromLotus: %s", err.Error())
 			return cid.Cid{}, err
@@ -108,17 +161,27 @@ func (m *OnChain) retrieveActorFromLotus(add address.Address, key filTypes.TipSe
 	return actor.Code, nil
 }
 
-func (m *OnChain) retrieveActorPubKeyFromLotus(add address.Address, reverse bool) (string, error) {
+func (m *OnChain) retrieveActorPubKeyFromLotus(parent context.Context, add address.Address, reverse bool) (string, error) {
+	ctx, cancel := m.lookupContext(parent)
+	defer cancel()
+	if err := m.wait(ctx); err != nil {
+		return "", err
+	}
+
 	var key address.Address
 	var err error
 	if reverse {
-		key, err = m.Node.StateLookupID(context.Background(), add, filTypes.EmptyTSK)
+		key, err = m.Node.StateLookupID(ctx, add, filTypes.EmptyTSK)
 	} else {
-		key, err = m.Node.StateAccountKey(context.Background(), add, filTypes.EmptyTSK)
+		key, err = m.Node.StateAccountKey(ctx, add, filTypes.EmptyTSK)
 	}
 
 	if err != nil {
 		m.logger.Sugar().Errorf("[ActorsCache] - retrieveActorPubKeyFromLotus: %s", err.Error())
+		// a cancelled lookup says nothing about the address
+		if parentErr := parent.Err(); parentErr != nil {
+			return "", parentErr
+		}
 		return "", common.ErrKeyNotFound
 	}
 
@@ -2,6 +2,7 @@ package impl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/filecoin-project/go-address"
@@ -21,6 +22,8 @@ const (
 	NoTtl           = -1
 	DummyTtl        = -1
 	PrefixSplitter  = "/"
+
+	healthCheckKey = "health"
 )
 
 // ZCache In-Memory database
@@ -138,16 +141,23 @@ func (m *ZCache) BackFill() error {
 }
 
 func (m *ZCache) GetActorCode(address address.Address, key filTypes.TipSetKey) (string, error) {
-	shortAddress, err := m.GetShortAddress(address)
+	return m.GetActorCodeWithContext(context.Background(), address, key)
+}
+
+// GetActorCodeWithContext is GetActorCode, cancelling the lookup of the store with the context
+func (m *ZCache) GetActorCodeWithContext(ctx context.Context, address address.Address, key filTypes.TipSetKey) (string, error) {
+	shortAddress, err := m.GetShortAddressWithContext(ctx, address)
 	if err != nil {
 		m.logger.Sugar().Debugf("[ActorsCache] - short address [%s] not found, err: %s\n", address.String(), err.Error())
-		return cid.Undef.String(), common.ErrKeyNotFound
+		if errors.Is(err, common.ErrKeyNotFound) || errors.Is(err, common.ErrEmptyValue) || errors.Is(err, common.ErrUnkownAddressType) {
+			return cid.Undef.String(), common.ErrKeyNotFound
+		}
+		return cid.Undef.String(), err
 	}
 
 	var code string
-	ctx := context.Background()
 	if err = m.shortCidMap.Get(ctx, shortAddress, &code); err != nil {
-		return cid.Undef.String(), common.ErrKeyNotFound
+		return cid.Undef.String(), m.lookupError(m.shortCidMap, err)
 	}
 
 	if code == "" {
@@ -157,7 +167,21 @@ func (m *ZCache) GetActorCode(address address.Address, key filTypes.TipSetKey) (
 	return code, nil
 }
 
+// HealthCheck checks that the cache store can be reached. A missing key is a healthy response.
+func (m *ZCache) HealthCheck(ctx context.Context) error {
+	var value string
+	if err := m.shortRobustMap.Get(ctx, healthCheckKey, &value); err != nil && !m.shortRobustMap.IsNotFoundError(err) {
+		return err
+	}
+	return nil
+}
+
 func (m *ZCache) GetRobustAddress(address address.Address) (string, error) {
+	return m.GetRobustAddressWithContext(context.Background(), address)
+}
+
+// GetRobustAddressWithContext is GetRobustAddress, cancelling the lookup of the store with the context
+func (m *ZCache) GetRobustAddressWithContext(ctx context.Context, address address.Address) (string, error) {
 	isRobustAddress, err := common.IsRobustAddress(address)
 	if err != nil {
 		return "", err
@@ -170,9 +194,8 @@ func (m *ZCache) GetRobustAddress(address address.Address) (string, error) {
 
 	// This is a short address, get the robust one
 	var robustAdd string
-	ctx := context.Background()
 	if err = m.shortRobustMap.Get(ctx, address.String(), &robustAdd); err != nil {
-		return "", common.ErrKeyNotFound
+		return "", m.lookupError(m.shortRobustMap, err)
 	}
 
 	if robustAdd == "" {
@@ -183,6 +206,11 @@ func (m *ZCache) GetRobustAddress(address address.Address) (string, error) {
 }
 
 func (m *ZCache) GetShortAddress(address address.Address) (string, error) {
+	return m.GetShortAddressWithContext(context.Background(), address)
+}
+
+// GetShortAddressWithContext is GetShortAddress, cancelling the lookup of the store with the context
+func (m *ZCache) GetShortAddressWithContext(ctx context.Context, address address.Address) (string, error) {
 	isRobustAddress, err := common.IsRobustAddress(address)
 	if err != nil {
 		return "", err
@@ -195,10 +223,8 @@ func (m *ZCache) GetShortAddress(address address.Address) (string, error) {
 
 	// This is a robust address, get the short one
 	var shortAdd string
-	ctx := context.Background()
-
 	if err = m.robustShortMap.Get(ctx, address.String(), &shortAdd); err != nil {
-		return "", common.ErrKeyNotFound
+		return "", m.lookupError(m.robustShortMap, err)
 	}
 
 	if shortAdd == "" {
@@ -208,6 +234,15 @@ func (m *ZCache) GetShortAddress(address address.Address) (string, error) {
 	return shortAdd, nil
 }
 
+// lookupError returns ErrKeyNotFound for the missing keys and the error of the store otherwise, so a failing
+// store can be told apart from a cache miss
+func (m *ZCache) lookupError(store zcache.ZCache, err error) error {
+	if store.IsNotFoundError(err) {
+		return common.ErrKeyNotFound
+	}
+	return err
+}
+
 func (m *ZCache) GetEVMSelectorSig(ctx context.Context, selectorHash string) (string, error) {
 	var selectorSig string
 	if err := m.selectorHashSigMap.Get(ctx, selectorHash, &selectorSig); err != nil {
@@ -225,44 +260,76 @@ func (m *ZCache) StoreEVMSelectorSig(ctx context.Context, selectorHash, selector
 	return nil
 }
 
-func (m *ZCache) storeRobustShort(robust string, short string) {
+func (m *ZCache) storeRobustShort(ctx context.Context, robust string, short string) error {
 	if robust == "" || short == "" {
 		m.logger.Sugar().Debugf("[ActorsCache] - Trying to store empty robust or short address")
-		return
+		return nil
 	}
 
 	// Possible ZCache types can be Local or Combined. Both types set the TTL at instantiation time
 	// The ttl here is pointless
-	ctx := context.Background()
-	_ = m.robustShortMap.Set(ctx, robust, short, DummyTtl)
+	return m.robustShortMap.Set(ctx, robust, short, DummyTtl)
 }
 
-func (m *ZCache) storeShortRobust(short string, robust string) {
+func (m *ZCache) storeShortRobust(ctx context.Context, short string, robust string) error {
 	if robust == "" || short == "" {
 		m.logger.Sugar().Debugf("[ActorsCache] - Trying to store empty robust or short address")
-		return
+		return nil
 	}
 
 	// Possible ZCache types can be Local or Combined. Both types set the TTL at instantiation time
 	// The ttl here is pointless
-	ctx := context.Background()
-	_ = m.shortRobustMap.Set(ctx, short, robust, DummyTtl)
+	return m.shortRobustMap.Set(ctx, short, robust, DummyTtl)
 }
 
 func (m *ZCache) StoreAddressInfo(info types.AddressInfo) {
-	m.storeRobustShort(info.Robust, info.Short)
-	m.storeShortRobust(info.Short, info.Robust)
-	m.storeActorCode(info.Short, info.ActorCid)
+	if err := m.StoreAddressInfoWithContext(context.Background(), info); err != nil {
+		m.logger.Sugar().Errorf("[ActorsCache] - Unable to store address info: %s", err.Error())
+	}
+}
+
+// StoreAddressInfoWithContext is StoreAddressInfo, cancelling the writes of the store with the context. The errors
+// of the writes are returned.
+func (m *ZCache) StoreAddressInfoWithContext(ctx context.Context, info types.AddressInfo) error {
+	return errors.Join(
+		m.storeRobustShort(ctx, info.Robust, info.Short),
+		m.storeShortRobust(ctx, info.Short, info.Robust),
+		m.storeActorCode(ctx, info.Short, info.ActorCid),
+	)
+}
+
+// DeleteAddressInfo removes the mappings of the given addresses and the actor code of the short address
+func (m *ZCache) DeleteAddressInfo(info types.AddressInfo) {
+	if err := m.DeleteAddressInfoWithContext(context.Background(), info); err != nil {
+		m.logger.Sugar().Errorf("[ActorsCache] - Unable to delete address info: %s", err.Error())
+	}
+}
+
+// DeleteAddressInfoWithContext is DeleteAddressInfo, cancelling the deletes of the store with the context. The
+// errors of the deletes are returned.
+func (m *ZCache) DeleteAddressInfoWithContext(ctx context.Context, info types.AddressInfo) error {
+	var errs []error
+	if info.Robust != "" {
+		errs = append(errs, m.robustShortMap.Delete(ctx, info.Robust))
+	}
+	if info.Short != "" {
+		errs = append(errs, m.shortRobustMap.Delete(ctx, info.Short), m.shortCidMap.Delete(ctx, info.Short))
+	}
+	return errors.Join(errs...)
+}
+
+// DeleteActorCode removes the actor code of the short address, keeping its robust address
+func (m *ZCache) DeleteActorCode(ctx context.Context, short string) {
+	_ = m.shortCidMap.Delete(ctx, short)
 }
 
-func (m *ZCache) storeActorCode(shortAddress string, cid string) {
+func (m *ZCache) storeActorCode(ctx context.Context, shortAddress string, cid string) error {
 	if shortAddress == "" || cid == "" {
 		m.logger.Sugar().Debugf("[ActorsCache] - Trying to store empty cid or short address")
-		return
+		return nil
 	}
 
 	// Possible ZCache types can be Local or Combined. Both types set the TTL at instantiation time
 	// The ttl here is pointless
-	ctx := context.Background()
-	_ = m.shortCidMap.Set(ctx, shortAddress, cid, DummyTtl)
+	return m.shortCidMap.Set(ctx, shortAddress, cid, DummyTtl)
 }
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/types"
+)
+
+// Operations on the off-chain cache reported to the KvOpHook
+const (
+	KvOpGetActorCode        = "GetActorCode"
+	KvOpGetRobustAddress    = "GetRobustAddress"
+	KvOpGetShortAddress     = "GetShortAddress"
+	KvOpStoreAddressInfo    = "StoreAddressInfo"
+	KvOpDeleteAddressInfo   = "DeleteAddressInfo"
+	KvOpDeleteActorCode     = "DeleteActorCode"
+	KvOpGetEVMSelectorSig   = "GetEVMSelectorSig"
+	KvOpStoreEVMSelectorSig = "StoreEVMSelectorSig"
+)
+
+// KvOp is an operation on the off-chain cache (the kv store) that was slow or failed
+type KvOp struct {
+	Operation string
+	// Key is the address, or the selector hash, the operation was done on
+	Key      string
+	Duration time.Duration
+	// Slow is set when the operation took longer than the threshold of the hook
+	Slow bool
+	// Err is the error of the failed operation. Missing keys are not failures.
+	Err error
+}
+
+// KvOpHook receives the slow and failed operations of the off-chain cache, see SetKvOpHook
+type KvOpHook func(op KvOp)
+
+// kvObserver reports the off-chain cache operations to the hook
+type kvObserver struct {
+	threshold time.Duration
+	hook      KvOpHook
+}
+
+// SetKvOpHook sets a hook called for every off-chain cache operation that takes longer than the threshold or
+// fails, e.g. to spot hot keys or a degraded Redis before the parse throughput collapses. A zero threshold only
+// reports the failures. It must be set before the cache is used, as the hook is read without locking.
+func (a *ActorsCache) SetKvOpHook(threshold time.Duration, hook KvOpHook) {
+	if hook == nil {
+		a.kvObserver = nil
+		return
+	}
+	a.kvObserver = &kvObserver{threshold: threshold, hook: hook}
+}
+
+// observeKvOp reports the operation started at start to the hook, if it was slow or failed
+func (a *ActorsCache) observeKvOp(operation, key string, start time.Time, err error) {
+	if a.kvObserver == nil {
+		return
+	}
+
+	duration := time.Since(start)
+	if isKvMiss(err) {
+		err = nil
+	}
+	slow := a.kvObserver.threshold > 0 && duration > a.kvObserver.threshold
+	if !slow && err == nil {
+		return
+	}
+	a.kvObserver.hook(KvOp{Operation: operation, Key: key, Duration: duration, Slow: slow, Err: err})
+}
+
+// isKvMiss tells whether the error only means that the key is not cached, or cannot be, rather than a store failure
+func isKvMiss(err error) bool {
+	return errors.Is(err, common.ErrKeyNotFound) || errors.Is(err, common.ErrEmptyValue) || errors.Is(err, common.ErrUnkownAddressType)
+}
+
+// addressInfoKey returns the address the info is stored by
+func addressInfoKey(info types.AddressInfo) string {
+	if info.Short != "" {
+		return info.Short
+	}
+	return info.Robust
+}
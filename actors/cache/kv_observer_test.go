@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/types"
+)
+
+var errStoreDown = errors.New("store down")
+
+// degradedCache is an off-chain cache whose robust lookups are slow, whose short lookups of robust addresses fail
+// and whose writes fail
+type degradedCache struct {
+	*impl.ZCache
+	delay time.Duration
+}
+
+func (d *degradedCache) GetRobustAddressWithContext(ctx context.Context, add address.Address) (string, error) {
+	time.Sleep(d.delay)
+	return d.ZCache.GetRobustAddressWithContext(ctx, add)
+}
+
+func (d *degradedCache) GetShortAddressWithContext(ctx context.Context, add address.Address) (string, error) {
+	if add.Protocol() == address.ID {
+		return d.ZCache.GetShortAddressWithContext(ctx, add)
+	}
+	return "", errStoreDown
+}
+
+func (d *degradedCache) StoreAddressInfoWithContext(ctx context.Context, info types.AddressInfo) error {
+	return errStoreDown
+}
+
+func TestActorsCache_KvOpHook(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+
+	dataSource := common.DataSource{CacheNode: &lightNode{robust: map[address.Address]address.Address{short: robust}}}
+	zCache := &impl.ZCache{}
+	require.NoError(t, zCache.NewImpl(dataSource, nil))
+	actorsCache, err := NewActorsCacheWithOffChain(&degradedCache{ZCache: zCache, delay: 20 * time.Millisecond}, dataSource, nil)
+	require.NoError(t, err)
+
+	var ops []KvOp
+	actorsCache.SetKvOpHook(10*time.Millisecond, func(op KvOp) { ops = append(ops, op) })
+
+	// a slow miss of the robust address, resolved by the node
+	_, err = actorsCache.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.NotEmpty(t, ops)
+	require.Equal(t, KvOpGetRobustAddress, ops[0].Operation)
+	require.Equal(t, short.String(), ops[0].Key)
+	require.True(t, ops[0].Slow)
+	require.NoError(t, ops[0].Err, "a miss is not a failure")
+
+	// the failing store is reported, while the lookup falls back to the node
+	ops = nil
+	_, err = actorsCache.GetShortAddress(robust)
+	require.NoError(t, err)
+	require.Equal(t, KvOpGetShortAddress, ops[0].Operation)
+	require.False(t, ops[0].Slow)
+	require.ErrorIs(t, ops[0].Err, errStoreDown)
+
+	// the failing write of the info resolved by the node is reported too
+	require.Equal(t, KvOpStoreAddressInfo, ops[len(ops)-1].Operation)
+	require.ErrorIs(t, ops[len(ops)-1].Err, errStoreDown)
+
+	// fast hits are not reported
+	ops = nil
+	actorsCache.SetKvOpHook(time.Second, func(op KvOp) { ops = append(ops, op) })
+	_, err = actorsCache.GetRobustAddress(robust)
+	require.NoError(t, err)
+	require.Empty(t, ops)
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	logger2 "github.com/zondax/fil-parser/logger"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+const (
+	NoopImpl   = "noop"
+	StaticImpl = "static"
+)
+
+// NoopActorsCache is an IActorsCache that knows no actors and stores nothing. Every lookup fails as if the
+// actor did not exist, so the addresses are flagged as bad instead of being retried.
+type NoopActorsCache struct{}
+
+// errActorNotFound is the error of the lookups of unknown actors. It wraps common.ErrKeyNotFound, so the callers
+// tell a missing actor apart from a failing cache, as with the other implementations.
+func errActorNotFound(add address.Address) error {
+	return fmt.Errorf("actor not found: %s: %w", add.String(), common.ErrKeyNotFound)
+}
+
+func (m *NoopActorsCache) NewImpl(_ common.DataSource, _ *zap.Logger) error {
+	return nil
+}
+
+func (m *NoopActorsCache) GetActorCode(add address.Address, _ filTypes.TipSetKey) (string, error) {
+	return "", errActorNotFound(add)
+}
+
+func (m *NoopActorsCache) GetRobustAddress(add address.Address) (string, error) {
+	return "", errActorNotFound(add)
+}
+
+func (m *NoopActorsCache) GetShortAddress(add address.Address) (string, error) {
+	return "", errActorNotFound(add)
+}
+
+func (m *NoopActorsCache) StoreAddressInfo(_ types.AddressInfo) {
+	// Nothing to store
+}
+
+func (m *NoopActorsCache) GetEVMSelectorSig(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+
+func (m *NoopActorsCache) StoreEVMSelectorSig(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m *NoopActorsCache) BackFill() error {
+	return nil
+}
+
+func (m *NoopActorsCache) ImplementationType() string {
+	return NoopImpl
+}
+
+// StaticActorsCache is an in-memory IActorsCache pre-seeded with the given actors, e.g. the fixtures of a test.
+// Actors stored while parsing are added to it. Unknown actors fail as if they did not exist.
+type StaticActorsCache struct {
+	mu          sync.RWMutex
+	shortRobust map[string]string
+	robustShort map[string]string
+	shortCode   map[string]string
+	signatures  map[string]string
+}
+
+// NewStaticActorsCache returns a StaticActorsCache seeded with the short and robust addresses and the actor
+// cid of the given actors
+func NewStaticActorsCache(actors ...types.AddressInfo) *StaticActorsCache {
+	m := &StaticActorsCache{
+		shortRobust: make(map[string]string),
+		robustShort: make(map[string]string),
+		shortCode:   make(map[string]string),
+		signatures:  make(map[string]string),
+	}
+	for _, actor := range actors {
+		m.StoreAddressInfo(actor)
+	}
+	return m
+}
+
+func (m *StaticActorsCache) NewImpl(_ common.DataSource, _ *zap.Logger) error {
+	return nil
+}
+
+func (m *StaticActorsCache) GetActorCode(add address.Address, _ filTypes.TipSetKey) (string, error) {
+	short, err := m.GetShortAddress(add)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	code, ok := m.shortCode[short]
+	if !ok {
+		return "", errActorNotFound(add)
+	}
+	return code, nil
+}
+
+func (m *StaticActorsCache) GetRobustAddress(add address.Address) (string, error) {
+	isRobustAddress, err := common.IsRobustAddress(add)
+	if err != nil {
+		return "", err
+	}
+	if isRobustAddress {
+		return add.String(), nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	robust, ok := m.shortRobust[add.String()]
+	if !ok {
+		return "", errActorNotFound(add)
+	}
+	return robust, nil
+}
+
+func (m *StaticActorsCache) GetShortAddress(add address.Address) (string, error) {
+	isRobustAddress, err := common.IsRobustAddress(add)
+	if err != nil {
+		return "", err
+	}
+	if !isRobustAddress {
+		return add.String(), nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	short, ok := m.robustShort[add.String()]
+	if !ok {
+		return "", errActorNotFound(add)
+	}
+	return short, nil
+}
+
+func (m *StaticActorsCache) StoreAddressInfo(info types.AddressInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if info.Short != "" && info.Robust != "" {
+		m.shortRobust[info.Short] = info.Robust
+		m.robustShort[info.Robust] = info.Short
+	}
+	if info.Short != "" && info.ActorCid != "" {
+		m.shortCode[info.Short] = info.ActorCid
+	}
+}
+
+// DeleteAddressInfo removes the mappings of the given addresses and the actor code of the short address
+func (m *StaticActorsCache) DeleteAddressInfo(info types.AddressInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.robustShort, info.Robust)
+	delete(m.shortRobust, info.Short)
+	delete(m.shortCode, info.Short)
+}
+
+// DeleteActorCode removes the actor code of the short address, keeping its robust address
+func (m *StaticActorsCache) DeleteActorCode(_ context.Context, short string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.shortCode, short)
+}
+
+func (m *StaticActorsCache) GetEVMSelectorSig(_ context.Context, selectorHash string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.signatures[selectorHash], nil
+}
+
+func (m *StaticActorsCache) StoreEVMSelectorSig(_ context.Context, selectorHash, selectorSig string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signatures[selectorHash] = selectorSig
+	return nil
+}
+
+func (m *StaticActorsCache) BackFill() error {
+	return nil
+}
+
+func (m *StaticActorsCache) ImplementationType() string {
+	return StaticImpl
+}
+
+// NewOfflineActorsCache sets up an actors cache that never reaches a node nor the network: the actors are only
+// resolved by the off-chain cache (e.g. a StaticActorsCache) and the on-chain lookups always miss.
+func NewOfflineActorsCache(offChainCache IActorsCache, logger *zap.Logger) *ActorsCache {
+	if offChainCache == nil {
+		offChainCache = &NoopActorsCache{}
+	}
+	actorsCache := newActorsCache(offChainCache, &NoopActorsCache{}, CacheStatus{Backend: offChainCache.ImplementationType()},
+		logger2.GetSafeLogger(logger))
+	actorsCache.DisableSignatureLookup()
+	return actorsCache
+}
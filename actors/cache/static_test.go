@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/types"
+)
+
+const accountCode = "bafk2bzacedudbf7fc5va57t3tmo63ujwcxzuhovmimqgpfhmzxcbwfgrlduk"
+
+func TestNewOfflineActorsCache(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+	unknown, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	robust2, err := address.NewSecp256k1Address([]byte("unknown"))
+	require.NoError(t, err)
+
+	static := NewStaticActorsCache(types.AddressInfo{Short: short.String(), Robust: robust.String(), ActorCid: accountCode})
+	actorsCache := NewOfflineActorsCache(static, nil)
+	require.Equal(t, StaticImpl, actorsCache.Status().Backend)
+
+	got, err := actorsCache.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robust.String(), got)
+	got, err = actorsCache.GetShortAddress(robust)
+	require.NoError(t, err)
+	require.Equal(t, short.String(), got)
+	got, err = actorsCache.GetActorCode(robust, filTypes.EmptyTSK, false)
+	require.NoError(t, err)
+	require.Equal(t, accountCode, got)
+
+	// unknown actors are flagged as bad, there is no node to retry them against
+	_, err = actorsCache.GetActorCode(unknown, filTypes.EmptyTSK, false)
+	require.ErrorContains(t, err, "actor not found")
+	require.True(t, actorsCache.isBadAddress(unknown))
+
+	// stored actors are served afterwards
+	actorsCache.ClearBadAddressCache()
+	static.StoreAddressInfo(types.AddressInfo{Short: unknown.String(), ActorCid: accountCode})
+	got, err = actorsCache.GetActorCode(unknown, filTypes.EmptyTSK, false)
+	require.NoError(t, err)
+	require.Equal(t, accountCode, got)
+
+	_, err = static.GetShortAddress(robust2)
+	require.ErrorIs(t, err, common.ErrKeyNotFound)
+
+	actorsCache.InvalidateActorCode(short)
+	_, err = static.GetActorCode(short, filTypes.EmptyTSK)
+	require.ErrorIs(t, err, common.ErrKeyNotFound)
+	got, err = static.GetRobustAddress(short)
+	require.NoError(t, err)
+	require.Equal(t, robust.String(), got)
+}
+
+func TestNoopActorsCache(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	actorsCache := NewOfflineActorsCache(nil, nil)
+	require.Equal(t, NoopImpl, actorsCache.Status().Backend)
+	_, err = actorsCache.GetRobustAddress(short)
+	require.Error(t, err)
+
+	noop := &NoopActorsCache{}
+	_, err = noop.GetActorCode(short, filTypes.EmptyTSK)
+	require.ErrorIs(t, err, common.ErrKeyNotFound)
+	_, err = noop.GetShortAddress(short)
+	require.ErrorIs(t, err, common.ErrKeyNotFound)
+	require.NoError(t, noop.StoreEVMSelectorSig(context.Background(), "0xa9059cbb", "transfer(address,uint256)"))
+	sig, err := noop.GetEVMSelectorSig(context.Background(), "0xa9059cbb")
+	require.NoError(t, err)
+	require.Empty(t, sig)
+}
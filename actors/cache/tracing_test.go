@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/parser"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type recordedSpan struct {
+	noop.Span
+	name       string
+	parent     context.Context
+	attributes map[attribute.Key]attribute.Value
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+type recordingTracer struct {
+	noop.Tracer
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordedSpan{name: name, parent: ctx, attributes: make(map[attribute.Key]attribute.Value)}
+	config := trace.NewSpanStartConfig(opts...)
+	span.SetAttributes(config.Attributes()...)
+	r.spans = append(r.spans, span)
+	return ctx, span
+}
+
+func TestActorsCache_Tracing(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+	system, err := address.NewIDAddress(5)
+	require.NoError(t, err)
+
+	actorsCache, err := SetupActorsCache(common.DataSource{
+		CacheNode: &lightNode{robust: map[address.Address]address.Address{short: robust}},
+	}, nil)
+	require.NoError(t, err)
+
+	tracer := &recordingTracer{}
+	actorsCache.SetTracer(tracer)
+
+	for _, addr := range []address.Address{short, short, system} {
+		_, err = actorsCache.GetRobustAddress(addr)
+		require.NoError(t, err)
+	}
+
+	// storing the robust address looks up the short one too, so only the robust lookups are checked
+	var spans []*recordedSpan
+	for _, span := range tracer.spans {
+		require.True(t, span.ended)
+		require.Equal(t, parser.SpanCacheLookup, span.name)
+		if span.attributes[parser.AttributeCacheMethod].AsString() == "GetRobustAddress" {
+			spans = append(spans, span)
+		}
+	}
+
+	require.Len(t, spans, 3)
+	for i, tier := range []string{parser.CacheTierOnChain, parser.CacheTierOffChain, parser.CacheTierSystem} {
+		require.Equal(t, tier, spans[i].attributes[parser.AttributeCacheTier].AsString())
+	}
+}
+
+type tracingCtxKey struct{}
+
+func TestActorsCache_TracingWithContext(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+
+	actorsCache, err := SetupActorsCache(common.DataSource{
+		CacheNode: &lightNode{robust: map[address.Address]address.Address{short: robust}},
+	}, nil)
+	require.NoError(t, err)
+
+	tracer := &recordingTracer{}
+	actorsCache.SetTracer(tracer)
+
+	ctx := context.WithValue(context.Background(), tracingCtxKey{}, "parse")
+	_, err = actorsCache.GetRobustAddressWithContext(ctx, short)
+	require.NoError(t, err)
+
+	// the lookup spans start from the caller's context, so they join the trace of the parse
+	require.NotEmpty(t, tracer.spans)
+	for _, span := range tracer.spans {
+		require.Equal(t, "parse", span.parent.Value(tracingCtxKey{}))
+	}
+}
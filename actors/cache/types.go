@@ -9,6 +9,7 @@ import (
 	cmap "github.com/orcaman/concurrent-map"
 	"github.com/zondax/fil-parser/actors/cache/impl/common"
 	"github.com/zondax/fil-parser/types"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -26,12 +27,62 @@ type IActorsCache interface {
 	ImplementationType() string
 }
 
+// CacheStatus describes the off-chain cache backend selected by SetupActorsCache
+type CacheStatus struct {
+	// Backend is the implementation type of the off-chain cache
+	Backend string
+	// Fallback is set when the cache runs degraded to its local store
+	Fallback bool
+	// FallbackReason explains why the cache fell back to its local store
+	FallbackReason string
+}
+
+// HealthChecker is implemented by the caches that depend on an external store
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// ContextLookup is implemented by the caches whose lookups can be cancelled, like the on-chain one and zcache. The
+// lookups of the caches not implementing it are not cancelled once started.
+type ContextLookup interface {
+	GetActorCodeWithContext(ctx context.Context, add address.Address, key filTypes.TipSetKey) (string, error)
+	GetRobustAddressWithContext(ctx context.Context, add address.Address) (string, error)
+	GetShortAddressWithContext(ctx context.Context, add address.Address) (string, error)
+}
+
+// ContextStore is implemented by the caches whose writes can be cancelled, see ContextLookup. The errors of the
+// writes are returned, so they are reported by the kv observer.
+type ContextStore interface {
+	StoreAddressInfoWithContext(ctx context.Context, info types.AddressInfo) error
+	DeleteAddressInfoWithContext(ctx context.Context, info types.AddressInfo) error
+}
+
+// addressInfoDeleter is implemented by the caches that can evict the entries invalidated by a reorg, see
+// ActorsCache.InvalidateAbove. It is internal, as the entries are only evicted by the ActorsCache.
+type addressInfoDeleter interface {
+	DeleteAddressInfo(info types.AddressInfo)
+}
+
+// ActorCodeDeleter is implemented by the caches that can evict the actor code of an address, see
+// ActorsCache.InvalidateActorCode
+type ActorCodeDeleter interface {
+	DeleteActorCode(ctx context.Context, short string)
+}
+
 type ActorsCache struct {
 	offChainCache IActorsCache
 	onChainCache  IActorsCache
 	badAddress    cmap.ConcurrentMap
+	epochs        *epochIndex
 	logger        *zap.Logger
 	httpClient    *resty.Client
+	// tracer is optional, see SetTracer
+	tracer trace.Tracer
+	// kvObserver is optional, see SetKvOpHook
+	kvObserver *kvObserver
+	status     CacheStatus
+	// offline disables the lookup of unknown selectors on SignatureDBURL
+	offline bool
 }
 
 // FourBytesSignatureResult represents the response from SignatureDBURL
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+)
+
+// WarmStats is the outcome of warming the actors cache up with a set of addresses
+type WarmStats struct {
+	// Addresses is the amount of addresses looked up
+	Addresses int `json:"addresses"`
+	// Resolved is the amount of addresses whose actor code is cached
+	Resolved int `json:"resolved"`
+	// Failed is the amount of addresses whose actor code could not be resolved, e.g. actors created later
+	Failed int `json:"failed"`
+}
+
+// Warm resolves the addresses into the off-chain cache in bulk, so a later backfill finds them cached: the actor
+// code at the tipset and the short or robust counterpart of every address. Addresses already cached are not
+// looked up on-chain again. It stops once the context is done, returning the stats so far.
+func (a *ActorsCache) Warm(ctx context.Context, addresses []address.Address, key filTypes.TipSetKey) (WarmStats, error) {
+	var stats WarmStats
+	for _, add := range addresses {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		stats.Addresses++
+
+		// only accounts have a robust address, so its lookup is not required for the address to be resolved
+		if add.Protocol() == address.ID {
+			_, _ = a.GetRobustAddressWithContext(ctx, add)
+		} else {
+			_, _ = a.GetShortAddressWithContext(ctx, add)
+		}
+
+		if _, err := a.GetActorCodeWithContext(ctx, add, key, false); err != nil {
+			// the lookup was cancelled, so the address is neither resolved nor failed
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				stats.Addresses--
+				return stats, ctxErr
+			}
+			a.logger.Sugar().Debugf("[ActorsCache] - could not warm up address %s: %v", add.String(), err)
+			stats.Failed++
+			continue
+		}
+		stats.Resolved++
+	}
+	return stats, nil
+}
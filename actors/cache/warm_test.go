@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+func TestActorsCache_Warm(t *testing.T) {
+	short, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	robust, err := address.NewFromString("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea")
+	require.NoError(t, err)
+	unknown, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	offChain := NewStaticActorsCache()
+	onChain := NewStaticActorsCache(types.AddressInfo{Short: short.String(), Robust: robust.String(), ActorCid: accountCode})
+	actorsCache := newActorsCache(offChain, onChain, CacheStatus{Backend: StaticImpl}, zap.NewNop())
+
+	stats, err := actorsCache.Warm(context.Background(), []address.Address{robust, unknown}, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, WarmStats{Addresses: 2, Resolved: 1, Failed: 1}, stats)
+
+	// the resolved actor is served by the off-chain cache afterwards
+	got, err := offChain.GetActorCode(short, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, accountCode, got)
+	got, err = offChain.GetShortAddress(robust)
+	require.NoError(t, err)
+	require.Equal(t, short.String(), got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stats, err = actorsCache.Warm(ctx, []address.Address{short}, filTypes.EmptyTSK)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, WarmStats{}, stats)
+}
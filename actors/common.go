@@ -26,17 +26,61 @@ func (p *ActorParser) parseConstructor(raw []byte) (map[string]interface{}, erro
 	return metadata, nil
 }
 
-func (p *ActorParser) unknownMetadata(msgParams, msgReturn []byte) (map[string]interface{}, error) {
+func (p *ActorParser) unknownMetadata(msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
-	if len(msgParams) > 0 {
-		metadata[parser.ParamsKey] = hex.EncodeToString(msgParams)
+	renderCodecs := p.helper.GetConfig().IsFeatureEnabled(parser.FeatureParamsCodecs)
+	if len(msg.Params) > 0 {
+		metadata[parser.ParamsKey] = hex.EncodeToString(msg.Params)
+		if renderCodecs {
+			metadata[parser.ParamsKey] = parser.RenderIpldData(msg.ParamsCodec, msg.Params)
+		}
 	}
-	if len(msgReturn) > 0 {
-		metadata[parser.ReturnKey] = hex.EncodeToString(msgReturn)
+	if len(msgRct.Return) > 0 {
+		metadata[parser.ReturnKey] = hex.EncodeToString(msgRct.Return)
+		if renderCodecs {
+			metadata[parser.ReturnKey] = parser.RenderIpldData(msgRct.ReturnCodec, msgRct.Return)
+		}
 	}
 	return metadata, nil
 }
 
+// appendCodecs records the codecs of the params and return values, when the traces record them
+func (p *ActorParser) appendCodecs(metadata map[string]interface{}, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt) map[string]interface{} {
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	if len(msg.Params) > 0 && msg.ParamsCodec != parser.CodecUnknown {
+		metadata[parser.ParamsCodecKey] = parser.CodecName(msg.ParamsCodec)
+	}
+	if len(msgRct.Return) > 0 && msgRct.ReturnCodec != parser.CodecUnknown {
+		metadata[parser.ReturnCodecKey] = parser.CodecName(msgRct.ReturnCodec)
+	}
+	return metadata
+}
+
+// lotusJSONMetadata replaces the params and return values with the go-state-types values they are decoded into by
+// lotus StateDecodeParams, so they are rendered with the same JSON conventions: byte arrays as base64, CIDs as
+// {"/": ...} and addresses as strings. Values of methods unknown to go-state-types, or that can not be decoded
+// into their declared types, are kept as rendered by the actor parser.
+func (p *ActorParser) lotusJSONMetadata(actor string, metadata map[string]interface{}, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt) map[string]interface{} {
+	if !parser.IsCBORCodec(msg.ParamsCodec) || !parser.IsCBORCodec(msgRct.ReturnCodec) {
+		return metadata
+	}
+	decoded, err := p.helper.DecodeMethodMetadata(actor, msg.Method, msg.Params, msgRct.Return)
+	if err != nil {
+		return metadata
+	}
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	for _, key := range []string{parser.ParamsKey, parser.ReturnKey} {
+		if value, ok := decoded[key]; ok {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
 func (p *ActorParser) emptyParamsAndReturn() (map[string]interface{}, error) {
 	return make(map[string]interface{}), nil
 }
@@ -34,7 +34,7 @@ func TestActorParser_parseUnknown(t *testing.T) {
 	rawParams, err := loadFile(manifest.AccountKey, parser.UnknownStr, parser.ParamsKey)
 	require.NoError(t, err)
 	require.NotNil(t, rawParams)
-	got, err := p.unknownMetadata(rawParams, nil)
+	got, err := p.unknownMetadata(&parser.LotusMessage{Params: rawParams}, &parser.LotusMessageReceipt{})
 	require.NoError(t, err)
 	require.NotNil(t, got)
 	require.Contains(t, got, parser.ParamsKey, fmt.Sprintf("%s could no be found in metadata", parser.ParamsKey))
@@ -14,7 +14,7 @@ func (p *ActorParser) ParseCron(txType string, msg *parser.LotusMessage, msgRct
 	case parser.MethodEpochTick:
 		return p.emptyParamsAndReturn()
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
@@ -44,7 +44,7 @@ func (p *ActorParser) ParseDatacap(txType string, msg *parser.LotusMessage, msgR
 	case parser.MethodGranularityExported:
 		return p.granularityExported(msgRct.Return)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
@@ -29,7 +29,7 @@ func (p *ActorParser) ParseEam(txType string, msg *parser.LotusMessage, msgRct *
 	case parser.MethodCreateExternal:
 		return p.parseCreateExternal(msg.Params, msgRct.Return, msgCid)
 	case parser.UnknownStr:
-		metadata, err = p.unknownMetadata(msg.Params, msgRct.Return)
+		metadata, err = p.unknownMetadata(msg, msgRct)
 	default:
 		err = parser.ErrUnknownMethod
 	}
@@ -70,7 +70,7 @@ func (p *ActorParser) newEamCreate(r eam.CreateReturn) parser.EamCreateReturn {
 	return parser.EamCreateReturn{
 		ActorId:       r.ActorID,
 		RobustAddress: r.RobustAddress,
-		EthAddress:    parser.EthPrefix + hex.EncodeToString(r.EthAddress[:]),
+		EthAddress:    p.helper.GetConfig().FormatEthAddress(ethtypes.EthAddress(r.EthAddress)),
 	}
 }
 
@@ -101,7 +101,7 @@ func (p *ActorParser) parseCreate(rawParams, rawReturn []byte, msgCid cid.Cid) (
 	createdEvmActor := &types.AddressInfo{
 		Short:         parser.FilPrefix + strconv.FormatUint(r.ActorID, 10),
 		Robust:        r.RobustAddress.String(),
-		EthAddress:    parser.EthPrefix + hex.EncodeToString(r.EthAddress[:]),
+		EthAddress:    p.helper.GetConfig().FormatEthAddress(ethtypes.EthAddress(r.EthAddress)),
 		ActorType:     "evm",
 		CreationTxCid: msgCid.String(),
 	}
@@ -134,7 +134,7 @@ func (p *ActorParser) parseCreate2(rawParams, rawReturn []byte, msgCid cid.Cid)
 	createdEvmActor := &types.AddressInfo{
 		Short:         parser.FilPrefix + strconv.FormatUint(r.ActorID, 10),
 		Robust:        r.RobustAddress.String(),
-		EthAddress:    parser.EthPrefix + hex.EncodeToString(r.EthAddress[:]),
+		EthAddress:    p.helper.GetConfig().FormatEthAddress(ethtypes.EthAddress(r.EthAddress)),
 		ActorType:     "evm",
 		CreationTxCid: msgCid.String(),
 	}
@@ -170,7 +170,7 @@ func (p *ActorParser) parseCreateExternal(rawParams, rawReturn []byte, msgCid ci
 	createdEvmActor := &types.AddressInfo{
 		Short:         parser.FilPrefix + strconv.FormatUint(r.ActorID, 10),
 		Robust:        r.RobustAddress.String(),
-		EthAddress:    parser.EthPrefix + hex.EncodeToString(r.EthAddress[:]),
+		EthAddress:    p.helper.GetConfig().FormatEthAddress(ethtypes.EthAddress(r.EthAddress)),
 		ActorType:     "evm",
 		CreationTxCid: msgCid.String(),
 	}
@@ -19,7 +19,7 @@ func (p *ActorParser) ParseEvm(txType string, msg *parser.LotusMessage, msgRct *
 	case parser.MethodResurrect: // TODO: not tested
 		return p.resurrect(msg.Params)
 	case parser.MethodInvokeContract, parser.MethodInvokeContractReadOnly:
-		return p.invokeContract(msg.Params, msgRct.Return)
+		return p.invokeContract(msg, msgRct)
 	case parser.MethodInvokeContractDelegate:
 		return p.invokeContractDelegate(msg.Params, msgRct.Return)
 	case parser.MethodGetBytecode:
@@ -29,7 +29,7 @@ func (p *ActorParser) ParseEvm(txType string, msg *parser.LotusMessage, msgRct *
 	case parser.MethodGetStorageAt: // TODO: not tested
 		return p.getStorageAt(msg.Params, msgRct.Return)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return metadata, nil
 }
@@ -46,29 +46,37 @@ func (p *ActorParser) resurrect(raw []byte) (map[string]interface{}, error) {
 	return metadata, nil
 }
 
-func (p *ActorParser) invokeContract(rawParams, rawReturn []byte) (map[string]interface{}, error) {
+func (p *ActorParser) invokeContract(msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
-	reader := bytes.NewReader(rawParams)
+	rawParams, rawReturn := msg.Params, msgRct.Return
 	metadata[parser.ParamsKey] = parser.EthPrefix + hex.EncodeToString(rawParams)
 	metadata[parser.ReturnKey] = parser.EthPrefix + hex.EncodeToString(rawReturn)
 
-	var params abi.CborBytes
-	if err := params.UnmarshalCBOR(reader); err != nil {
-		p.logger.Sugar().Warn(fmt.Sprintf("error deserializing rawParams: %s - hex data: %s", err.Error(), hex.EncodeToString(rawParams)))
-	}
+	// Values with a non CBOR codec (e.g. raw) are the calldata itself, so they are not unwrapped
+	renderCodecs := p.helper.GetConfig().IsFeatureEnabled(parser.FeatureParamsCodecs)
 
-	if reader.Len() == 0 { // This means that the reader has processed all the bytes
-		metadata[parser.ParamsKey] = parser.EthPrefix + hex.EncodeToString(params)
-	}
+	if !renderCodecs || parser.IsCBORCodec(msg.ParamsCodec) {
+		reader := bytes.NewReader(rawParams)
+		var params abi.CborBytes
+		if err := params.UnmarshalCBOR(reader); err != nil {
+			p.logger.Sugar().Warn(fmt.Sprintf("error deserializing rawParams: %s - hex data: %s", err.Error(), hex.EncodeToString(rawParams)))
+		}
 
-	reader = bytes.NewReader(rawReturn)
-	var returnValue abi.CborBytes
-	if err := returnValue.UnmarshalCBOR(reader); err != nil {
-		p.logger.Sugar().Warn(fmt.Sprintf("Error deserializing rawReturn: %s - hex data: %s", err.Error(), hex.EncodeToString(rawReturn)))
+		if reader.Len() == 0 { // This means that the reader has processed all the bytes
+			metadata[parser.ParamsKey] = parser.EthPrefix + hex.EncodeToString(params)
+		}
 	}
 
-	if reader.Len() == 0 { // This means that the reader has processed all the bytes
-		metadata[parser.ReturnKey] = parser.EthPrefix + hex.EncodeToString(returnValue)
+	if !renderCodecs || parser.IsCBORCodec(msgRct.ReturnCodec) {
+		reader := bytes.NewReader(rawReturn)
+		var returnValue abi.CborBytes
+		if err := returnValue.UnmarshalCBOR(reader); err != nil {
+			p.logger.Sugar().Warn(fmt.Sprintf("Error deserializing rawReturn: %s - hex data: %s", err.Error(), hex.EncodeToString(rawReturn)))
+		}
+
+		if reader.Len() == 0 { // This means that the reader has processed all the bytes
+			metadata[parser.ReturnKey] = parser.EthPrefix + hex.EncodeToString(returnValue)
+		}
 	}
 
 	return metadata, nil
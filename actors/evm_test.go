@@ -1,11 +1,17 @@
 package actors
 
 import (
+	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
 	"github.com/filecoin-project/go-state-types/manifest"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
 	"github.com/zondax/fil-parser/parser"
 )
@@ -109,13 +115,33 @@ func TestActorParser_invokeContract(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, ethLogs)
 
-	got, err := p.invokeContract(rawParams, rawReturn)
+	got, err := p.invokeContract(&parser.LotusMessage{Params: rawParams}, &parser.LotusMessageReceipt{Return: rawReturn})
 	require.NoError(t, err)
 	require.NotNil(t, got)
 	require.Equal(t, got["Params"], "0x8381e182ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff0000000000000000000000008b21c7d96a349834dcfaddf871accda700b843e1")
 	require.Equal(t, got["Return"], "0x00000000000000000000000000000000000000000000000698b81208dfe49012")
 }
 
+func TestActorParser_invokeContract_lotusJSON(t *testing.T) {
+	rawParams, rawReturn, err := getParamsAndReturn(manifest.EvmKey, parser.MethodInvokeContract)
+	require.NoError(t, err)
+	msg := &parser.LotusMessage{Method: builtin.MethodsEVM.InvokeContract, Params: rawParams}
+	msgRct := &parser.LotusMessageReceipt{Return: rawReturn}
+
+	p := getActorParserWithConfig(parser.FilecoinParserConfig{ExperimentalFeatures: []string{string(parser.FeatureLotusJSON)}})
+	got, _, err := p.parseActorMetadata(context.Background(), manifest.EvmKey, parser.MethodInvokeContract, msg, cid.Undef, msgRct, 0, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	// lotus renders the evm calldata as base64 bytes instead of hex
+	require.IsType(t, abi.CborBytes{}, got[parser.ParamsKey])
+	params, err := json.Marshal(got[parser.ParamsKey])
+	require.NoError(t, err)
+	require.Equal(t, `"g4Hhgv//////////////////////////////////////////AAAAAAAAAAAAAAAAiyHH2Wo0mDTc+t34cazNpwC4Q+E="`, string(params))
+
+	got, _, err = getActorParser().parseActorMetadata(context.Background(), manifest.EvmKey, parser.MethodInvokeContract, msg, cid.Undef, msgRct, 0, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.IsType(t, "", got[parser.ParamsKey])
+}
+
 func TestActorParser_invokeContractReadOnly(t *testing.T) {
 	p := getActorParser()
 	rawParams, rawReturn, err := getParamsAndReturn(manifest.EvmKey, parser.MethodInvokeContractReadOnly)
@@ -131,7 +157,7 @@ func TestActorParser_invokeContractReadOnly(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, ethLogs)
 
-	got, err := p.invokeContract(rawParams, rawReturn)
+	got, err := p.invokeContract(&parser.LotusMessage{Params: rawParams}, &parser.LotusMessageReceipt{Return: rawReturn})
 	require.NoError(t, err)
 	require.NotNil(t, got)
 }
@@ -152,7 +178,7 @@ func TestActorParser_invokeContract_whenCborUnmarshalFail(t *testing.T) {
 
 	hexParamsString := "70a082310000000000000000000000001a5ef7ef64e3fb12be3b43edd77819dc7f034b1f"
 	rawParams, _ := hex.DecodeString(hexParamsString)
-	got, err := p.invokeContract(rawParams, rawReturn)
+	got, err := p.invokeContract(&parser.LotusMessage{Params: rawParams}, &parser.LotusMessageReceipt{Return: rawReturn})
 	require.NoError(t, err)
 	require.NotNil(t, got)
 	require.Equal(t, got["Params"], "0x70a082310000000000000000000000001a5ef7ef64e3fb12be3b43edd77819dc7f034b1f")
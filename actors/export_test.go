@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sync"
 
 	filTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/zondax/fil-parser/actors/cache"
@@ -15,6 +16,7 @@ import (
 	helper2 "github.com/zondax/fil-parser/parser/helper"
 	"github.com/zondax/fil-parser/types"
 
+	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/api/client"
 	rosettaFilecoinLib "github.com/zondax/rosetta-filecoin-lib"
 )
@@ -33,6 +35,17 @@ func loadFile(actor, txType, name string) ([]byte, error) {
 }
 
 func getActorParser() *ActorParser {
+	return getActorParserWithConfig(parser.FilecoinParserConfig{})
+}
+
+type testDependencies struct {
+	node        api.FullNode
+	lib         *rosettaFilecoinLib.RosettaConstructionFilecoin
+	actorsCache *cache.ActorsCache
+}
+
+// getTestDependencies is shared by the parsers of the tests, as every actors cache keeps its local caches alive
+var getTestDependencies = sync.OnceValue(func() *testDependencies {
 	lotusClient, _, err := client.NewFullNodeRPCV1(context.Background(), testUrl, http.Header{})
 	if err != nil {
 		return nil
@@ -40,13 +53,24 @@ func getActorParser() *ActorParser {
 	actorsCache, err := cache.SetupActorsCache(common.DataSource{
 		Node: lotusClient,
 	}, nil)
-
 	if err != nil {
 		return nil
 	}
 
 	lib := rosettaFilecoinLib.NewRosettaConstructionFilecoin(lotusClient)
-	helper := helper2.NewHelper(lib, actorsCache, lotusClient, nil)
+	if lib == nil {
+		// the node is not reachable, the methods that do not need it can still be decoded
+		lib = rosettaFilecoinLib.NewRosettaConstructionFilecoin(nil)
+	}
+	return &testDependencies{node: lotusClient, lib: lib, actorsCache: actorsCache}
+})
+
+func getActorParserWithConfig(config parser.FilecoinParserConfig) *ActorParser {
+	deps := getTestDependencies()
+	if deps == nil {
+		return nil
+	}
+	helper := helper2.NewHelper(deps.lib, deps.actorsCache, deps.node, nil, config)
 
 	return NewActorParser(helper, nil)
 }
@@ -0,0 +1,84 @@
+package actors
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+)
+
+// fixturesPath contains the per-method fixtures. Every file holds a list of actorFixture, so the coverage of a
+// single method can be added without downloading a whole height.
+const fixturesPath = dataPath + "/fixtures"
+
+// actorFixture is a single trace snippet along with the metadata it must be decoded into
+type actorFixture struct {
+	Name    string `json:"name"`
+	Actor   string `json:"actor"`
+	TxType  string `json:"txType"`
+	Height  int64  `json:"height"`
+	Message struct {
+		From   address.Address `json:"from"`
+		To     address.Address `json:"to"`
+		Method abi.MethodNum   `json:"method"`
+		// Params and Return are the raw cbor, base64 encoded
+		Params []byte `json:"params"`
+	} `json:"message"`
+	Receipt struct {
+		ExitCode exitcode.ExitCode `json:"exitCode"`
+		Return   []byte            `json:"return"`
+	} `json:"receipt"`
+	// Metadata has the expected json of every listed key. Keys not listed are not checked.
+	Metadata map[string]json.RawMessage `json:"metadata"`
+}
+
+func loadActorFixtures(t *testing.T) []actorFixture {
+	files, err := filepath.Glob(filepath.Join(fixturesPath, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	var fixtures []actorFixture
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		var fileFixtures []actorFixture
+		require.NoError(t, json.Unmarshal(data, &fileFixtures), file)
+		fixtures = append(fixtures, fileFixtures...)
+	}
+	return fixtures
+}
+
+func TestActorParser_Fixtures(t *testing.T) {
+	p := getActorParser()
+	for _, tt := range loadActorFixtures(t) {
+		t.Run(tt.Actor+"/"+tt.TxType+"/"+tt.Name, func(t *testing.T) {
+			msg := &parser.LotusMessage{
+				To:     tt.Message.To,
+				From:   tt.Message.From,
+				Method: tt.Message.Method,
+				Params: tt.Message.Params,
+			}
+			msgRct := &parser.LotusMessageReceipt{ExitCode: tt.Receipt.ExitCode, Return: tt.Receipt.Return}
+
+			metadata, _, err := p.parseActorMetadata(context.Background(), tt.Actor, tt.TxType, msg, cid.Undef, msgRct, tt.Height, filTypes.EmptyTSK)
+			require.NoError(t, err)
+
+			for key, want := range tt.Metadata {
+				require.Contains(t, metadata, key)
+				got, err := json.Marshal(metadata[key])
+				require.NoError(t, err)
+				require.JSONEq(t, string(want), string(got), key)
+			}
+		})
+	}
+}
@@ -27,7 +27,7 @@ func (p *ActorParser) ParseInit(txType string, msg *parser.LotusMessage, msgRct
 	case parser.MethodExec4:
 		return p.parseExec4(msg, msgRct.Return)
 	case parser.UnknownStr:
-		metadata, err = p.unknownMetadata(msg.Params, msgRct.Return)
+		metadata, err = p.unknownMetadata(msg, msgRct)
 	default:
 		err = parser.ErrUnknownMethod
 	}
@@ -54,7 +54,7 @@ func (p *ActorParser) ParseStoragemarket(txType string, msg *parser.LotusMessage
 	case parser.MethodGetDealActivation:
 		return p.getDealActivation(msg.Params, msgRct.Return)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
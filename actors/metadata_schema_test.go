@@ -0,0 +1,196 @@
+package actors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/parser/helper"
+)
+
+// TestMetadataSchemas_Embedded checks that the embedded schemas are up to date.
+// If this test fails, run go generate in the parser package.
+func TestMetadataSchemas_Embedded(t *testing.T) {
+	schemas, err := helper.GenerateMetadataSchemas(MetadataValueTypes)
+	require.NoError(t, err)
+
+	embedded := parser.MetadataSchemas()
+	require.Len(t, embedded, len(schemas))
+	for i := range schemas {
+		require.Equal(t, schemas[i].Actor, embedded[i].Actor)
+		require.Equal(t, schemas[i].TxType, embedded[i].TxType)
+		require.JSONEq(t, string(schemas[i].Schema), string(embedded[i].Schema), "schema of %s.%s is outdated", schemas[i].Actor, schemas[i].TxType)
+	}
+}
+
+// TestMetadataSchemas_Fixtures validates the metadata decoded from the actors fixtures against the embedded schemas
+func TestMetadataSchemas_Fixtures(t *testing.T) {
+	p := getActorParser()
+	validated := 0
+	validate := func(t *testing.T, actor, txType string, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt, height int64) {
+		if txType == parser.UnknownStr {
+			t.Skip("the metadata of unknown methods has no schema")
+		}
+		metadata, _, err := p.parseActorMetadata(context.Background(), actor, txType, msg, cid.Undef, msgRct, height, filTypes.EmptyTSK)
+		if err != nil {
+			// some fixtures need a node to be decoded
+			t.Skipf("could not decode the fixture: %s", err)
+		}
+
+		raw, ok := parser.GetMetadataSchema(actor, txType)
+		require.True(t, ok, "missing schema")
+		var schema map[string]interface{}
+		require.NoError(t, json.Unmarshal(raw, &schema))
+
+		encoded, err := json.Marshal(metadata)
+		require.NoError(t, err)
+		var value interface{}
+		require.NoError(t, json.Unmarshal(encoded, &value))
+
+		require.NoError(t, validateJSONSchema(schema, value, "$"), string(encoded))
+		validated++
+	}
+
+	for _, tt := range loadActorFixtures(t) {
+		t.Run(tt.Actor+"/"+tt.TxType+"/"+tt.Name, func(t *testing.T) {
+			msg := &parser.LotusMessage{
+				To:     tt.Message.To,
+				From:   tt.Message.From,
+				Method: tt.Message.Method,
+				Params: tt.Message.Params,
+			}
+			validate(t, tt.Actor, tt.TxType, msg, &parser.LotusMessageReceipt{ExitCode: tt.Receipt.ExitCode, Return: tt.Receipt.Return}, tt.Height)
+		})
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(dataPath, "*", "*"))
+	require.NoError(t, err)
+	for _, dir := range dirs {
+		actor, txType := filepath.Base(filepath.Dir(dir)), filepath.Base(dir)
+		if actor == filepath.Base(fixturesPath) {
+			continue
+		}
+		t.Run(actor+"/"+txType, func(t *testing.T) {
+			msg := &parser.LotusMessage{}
+			if _, err := os.Stat(filepath.Join(dir, "Message")); err == nil {
+				msg, err = deserializeMessage(actor, txType)
+				require.NoError(t, err)
+			} else if rawParams, err := loadFile(actor, txType, parser.ParamsKey); err == nil {
+				msg.Params = rawParams
+			}
+			msgRct := &parser.LotusMessageReceipt{}
+			if rawReturn, err := loadFile(actor, txType, parser.ReturnKey); err == nil {
+				msgRct.Return = rawReturn
+			}
+			validate(t, actor, txType, msg, msgRct, 0)
+		})
+	}
+
+	require.NotZero(t, validated)
+}
+
+// validateJSONSchema checks the value against the subset of JSON Schema used by the metadata schemas
+func validateJSONSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var errs []error
+		for _, alternative := range anyOf {
+			err := validateJSONSchema(alternative.(map[string]interface{}), value, path)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return fmt.Errorf("%s: no alternative of anyOf matches: %w", path, errors.Join(errs...))
+	}
+
+	switch schema["type"] {
+	case nil:
+		// any value
+	case "null":
+		if value != nil {
+			return fmt.Errorf("%s: expected null, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", path, value)
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s: expected a number, got %T", path, value)
+		}
+		if schema["type"] == "integer" && number != math.Trunc(number) {
+			return fmt.Errorf("%s: expected an integer, got %v", path, number)
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			return fmt.Errorf("%s: %v is lower than %v", path, number, minimum)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string, got %T", path, value)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(str) {
+			return fmt.Errorf("%s: %q does not match %s", path, str, pattern)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", path, value)
+		}
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(items)) < minItems {
+			return fmt.Errorf("%s: expected at least %v items", path, minItems)
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(items)) > maxItems {
+			return fmt.Errorf("%s: expected at most %v items", path, maxItems)
+		}
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range items {
+				if err := validateJSONSchema(itemSchema, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", path, value)
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := object[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required property %s", path, name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range object {
+			propertySchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				propertySchema, ok = schema["additionalProperties"].(map[string]interface{})
+			}
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %s", path, name)
+				}
+				continue
+			}
+			if err := validateJSONSchema(propertySchema, property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %v", path, schema["type"])
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package actors
+
+import (
+	"reflect"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v11/verifreg"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+)
+
+var (
+	bytesType  = reflect.TypeOf([]byte(nil))
+	stringType = reflect.TypeOf("")
+	anyType    = reflect.TypeOf((*interface{})(nil)).Elem()
+
+	sendValueTypes = helper.MethodValueTypes{Params: bytesType}
+)
+
+// MetadataValueTypes are the types of the Params and Return that the parsers of this package write to the
+// metadata when they differ from the types declared by go-state-types (e.g. addresses rendered as strings or
+// values kept base64 encoded). They are used to generate the schemas returned by parser.MetadataSchemas.
+var MetadataValueTypes = map[string]map[string]helper.MethodValueTypes{
+	manifest.AccountKey: {
+		parser.MethodSend:          sendValueTypes,
+		parser.MethodPubkeyAddress: {Params: stringType},
+	},
+	manifest.InitKey: {
+		parser.MethodSend:  sendValueTypes,
+		parser.MethodExec:  {Params: reflect.TypeOf(parser.ExecParams{}), Return: reflect.TypeOf(&types.AddressInfo{})},
+		parser.MethodExec4: {Params: reflect.TypeOf(parser.Exec4Params{}), Return: reflect.TypeOf(&types.AddressInfo{})},
+	},
+	manifest.PowerKey: {
+		parser.MethodSend:                sendValueTypes,
+		parser.MethodCreateMiner:         {Return: reflect.TypeOf(&types.AddressInfo{})},
+		parser.MethodCreateMinerExported: {Return: reflect.TypeOf(&types.AddressInfo{})},
+	},
+	manifest.MinerKey: {
+		parser.MethodSend:             sendValueTypes,
+		parser.MethodControlAddresses: {Params: stringType, Return: reflect.TypeOf(parser.ControlAddress{})},
+		parser.MethodGetBeneficiary:   {Params: stringType, Return: reflect.TypeOf(parser.GetBeneficiaryReturn{})},
+	},
+	manifest.MarketKey: {
+		parser.MethodSend:                    sendValueTypes,
+		parser.MethodWithdrawBalance:         {Return: stringType},
+		parser.MethodWithdrawBalanceExported: {Return: stringType},
+	},
+	manifest.PaychKey: {
+		parser.MethodSend: sendValueTypes,
+	},
+	manifest.MultisigKey: {
+		parser.MethodSend:                 sendValueTypes,
+		parser.MethodPropose:              {Params: reflect.TypeOf(parser.Propose{})},
+		parser.MethodProposeExported:      {Params: reflect.TypeOf(parser.Propose{})},
+		parser.MethodApprove:              {Params: stringType},
+		parser.MethodApproveExported:      {Params: stringType},
+		parser.MethodCancel:               {Params: stringType},
+		parser.MethodCancelExported:       {Params: stringType},
+		parser.MethodRemoveSigner:         {Params: stringType},
+		parser.MethodRemoveSignerExported: {Params: stringType},
+		// the metadata is the json returned by rosetta-filecoin-lib
+		parser.MethodAddSigner:          {Params: anyType, Return: anyType},
+		parser.MethodAddSignerExported:  {Params: anyType, Return: anyType},
+		parser.MethodSwapSigner:         {Params: anyType, Return: anyType},
+		parser.MethodSwapSignerExported: {Params: anyType, Return: anyType},
+	},
+	manifest.RewardKey: {
+		parser.MethodSend: sendValueTypes,
+	},
+	manifest.VerifregKey: {
+		parser.MethodSend:                        sendValueTypes,
+		parser.MethodRemoveVerifiedClientDataCap: {Params: reflect.TypeOf(abi.StoragePower{})},
+		// the deprecated UseBytes and RestoreBytes methods of the actors v8
+		parser.MethodVerifiedDeprecated1: {Params: reflect.TypeOf(verifreg.RestoreBytesParams{}), MethodNum: 5},
+		parser.MethodVerifiedDeprecated2: {Params: reflect.TypeOf(verifreg.UseBytesParams{}), MethodNum: 6},
+	},
+	manifest.EvmKey: {
+		parser.MethodInvokeContract: {Params: stringType, Return: stringType},
+		// read only calls found in the eth traces
+		parser.MethodInvokeContractReadOnly: {Params: stringType, Return: stringType, MethodNum: builtin.MethodsEVM.InvokeContract},
+	},
+	manifest.EamKey: {
+		parser.MethodCreate:         {Return: reflect.TypeOf(parser.EamCreateReturn{})},
+		parser.MethodCreate2:        {Return: reflect.TypeOf(parser.EamCreateReturn{})},
+		parser.MethodCreateExternal: {Params: stringType, Return: reflect.TypeOf(parser.EamCreateReturn{})},
+	},
+	// every method of these actors keeps the raw params and return
+	manifest.PlaceholderKey: {
+		helper.AnyMethod:  {Params: bytesType, Return: bytesType},
+		parser.MethodSend: {Params: bytesType, Return: bytesType},
+	},
+	manifest.EthAccountKey: {
+		helper.AnyMethod:  {Params: bytesType, Return: bytesType},
+		parser.MethodSend: {Params: bytesType, Return: bytesType},
+	},
+}
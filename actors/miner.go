@@ -2,16 +2,32 @@ package actors
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"slices"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/builtin/v11/miner"
 	miner14 "github.com/filecoin-project/go-state-types/builtin/v14/miner"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/actors/minernet"
 	"github.com/zondax/fil-parser/parser"
 )
 
-func (p *ActorParser) ParseStorageminer(txType string, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt) (map[string]interface{}, error) {
+func (p *ActorParser) ParseStorageminer(ctx context.Context, txType string, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt, key filTypes.TipSetKey) (map[string]interface{}, error) {
+	metadata, err := p.parseStorageminer(txType, msg, msgRct)
+	if err == nil && p.helper.GetConfig().EnrichSectorInfo {
+		p.appendSectorsInfo(ctx, metadata, msg.To, key)
+	}
+	if err == nil && p.helper.GetConfig().EnrichMinerInfo {
+		p.appendMinerInfo(ctx, metadata, msg.To, key)
+	}
+	return metadata, err
+}
+
+func (p *ActorParser) parseStorageminer(txType string, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt) (map[string]interface{}, error) {
 	switch txType {
 	case parser.MethodSend:
 		return p.parseSend(msg), nil
@@ -97,11 +113,57 @@ func (p *ActorParser) ParseStorageminer(txType string, msg *parser.LotusMessage,
 	case parser.MethodGetMultiaddrs:
 		return p.getMultiaddrs(msgRct.Return)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
 
+// appendSectorsInfo adds the on-chain info of the sectors affected by terminate, extend and fault txs
+func (p *ActorParser) appendSectorsInfo(ctx context.Context, metadata map[string]interface{}, minerAddr address.Address, key filTypes.TipSetKey) {
+	var sectors []bitfield.BitField
+	switch params := metadata[parser.ParamsKey].(type) {
+	case miner.TerminateSectorsParams:
+		for _, termination := range params.Terminations {
+			sectors = append(sectors, termination.Sectors)
+		}
+	case miner.ExtendSectorExpirationParams:
+		for _, extension := range params.Extensions {
+			sectors = append(sectors, extension.Sectors)
+		}
+	case miner.ExtendSectorExpiration2Params:
+		for _, extension := range params.Extensions {
+			sectors = append(sectors, extension.Sectors)
+			claimedSectors := make([]uint64, 0, len(extension.SectorsWithClaims))
+			for _, claim := range extension.SectorsWithClaims {
+				claimedSectors = append(claimedSectors, uint64(claim.SectorNumber))
+			}
+			sectors = append(sectors, bitfield.NewFromSet(claimedSectors))
+		}
+	case miner.DeclareFaultsParams:
+		for _, fault := range params.Faults {
+			sectors = append(sectors, fault.Sectors)
+		}
+	case miner.DeclareFaultsRecoveredParams:
+		for _, recovery := range params.Recoveries {
+			sectors = append(sectors, recovery.Sectors)
+		}
+	default:
+		return
+	}
+
+	metadata[parser.SectorsInfoKey] = p.helper.GetSectorsInfo(ctx, minerAddr, sectors, key)
+}
+
+// appendMinerInfo adds the addresses controlling the miner at the tipset
+func (p *ActorParser) appendMinerInfo(ctx context.Context, metadata map[string]interface{}, minerAddr address.Address, key filTypes.TipSetKey) {
+	info, err := p.helper.GetMinerInfo(ctx, minerAddr, key)
+	if err != nil {
+		p.logger.Sugar().Debugf("could not get miner info of %s: %s", minerAddr.String(), err)
+		return
+	}
+	metadata[parser.MinerInfoKey] = info
+}
+
 func (p *ActorParser) terminateSectors(rawParams, rawReturn []byte) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
 	reader := bytes.NewReader(rawParams)
@@ -289,6 +351,9 @@ func (p *ActorParser) changeMultiaddrs(raw []byte) (map[string]interface{}, erro
 		return metadata, err
 	}
 	metadata[parser.ParamsKey] = params
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureMinerNetworkInfo) {
+		metadata[parser.MinerNetworkInfoKey] = parser.MinerNetworkInfo{Multiaddrs: minernet.DecodeMultiaddrs(params.NewMultiaddrs)}
+	}
 	return metadata, nil
 }
 
@@ -313,6 +378,13 @@ func (p *ActorParser) extendSectorExpiration(raw []byte) (map[string]interface{}
 		return metadata, err
 	}
 	metadata[parser.ParamsKey] = params
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureSectorExtensions) {
+		extensions := make([]parser.SectorExtension, 0, len(params.Extensions))
+		for _, extension := range params.Extensions {
+			extensions = append(extensions, newSectorExtension(extension.Deadline, extension.Partition, extension.Sectors, extension.NewExpiration))
+		}
+		metadata[parser.SectorExtensionsKey] = extensions
+	}
 	return metadata, nil
 }
 
@@ -325,6 +397,9 @@ func (p *ActorParser) changePeerID(raw []byte) (map[string]interface{}, error) {
 		return metadata, err
 	}
 	metadata[parser.ParamsKey] = params
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureMinerNetworkInfo) {
+		metadata[parser.MinerNetworkInfoKey] = parser.MinerNetworkInfo{PeerID: minernet.DecodePeerID(params.NewID)}
+	}
 	return metadata, nil
 }
 
@@ -541,6 +616,21 @@ func (p *ActorParser) extendSectorExpiration2(raw []byte) (map[string]interface{
 		return metadata, err
 	}
 	metadata[parser.ParamsKey] = params
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureSectorExtensions) {
+		extensions := make([]parser.SectorExtension, 0, len(params.Extensions))
+		for _, extension := range params.Extensions {
+			sectorExtension := newSectorExtension(extension.Deadline, extension.Partition, extension.Sectors, extension.NewExpiration)
+			for _, claim := range extension.SectorsWithClaims {
+				sectorExtension.Sectors = append(sectorExtension.Sectors, uint64(claim.SectorNumber))
+				for _, claimID := range claim.DropClaims {
+					sectorExtension.ClaimsDropped = append(sectorExtension.ClaimsDropped, uint64(claimID))
+				}
+			}
+			slices.Sort(sectorExtension.Sectors)
+			extensions = append(extensions, sectorExtension)
+		}
+		metadata[parser.SectorExtensionsKey] = extensions
+	}
 	return metadata, nil
 }
 
@@ -602,6 +692,9 @@ func (p *ActorParser) getPeerID(rawReturn []byte) (map[string]interface{}, error
 		return metadata, err
 	}
 	metadata[parser.ReturnKey] = params
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureMinerNetworkInfo) {
+		metadata[parser.MinerNetworkInfoKey] = parser.MinerNetworkInfo{PeerID: minernet.DecodePeerID(params.PeerId)}
+	}
 	return metadata, nil
 }
 
@@ -614,9 +707,27 @@ func (p *ActorParser) getMultiaddrs(rawReturn []byte) (map[string]interface{}, e
 		return metadata, err
 	}
 	metadata[parser.ReturnKey] = params
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureMinerNetworkInfo) {
+		metadata[parser.MinerNetworkInfoKey] = parser.MinerNetworkInfo{Multiaddrs: minernet.DecodeMultiaddrs([]abi.Multiaddrs{params.MultiAddrs})}
+	}
 	return metadata, nil
 }
 
+// newSectorExtension returns the extension of the sectors set in the bitfield
+func newSectorExtension(deadline, partition uint64, sectors bitfield.BitField, newExpiration abi.ChainEpoch) parser.SectorExtension {
+	extension := parser.SectorExtension{
+		Deadline:      deadline,
+		Partition:     partition,
+		Sectors:       make([]uint64, 0),
+		NewExpiration: int64(newExpiration),
+	}
+	_ = sectors.ForEach(func(sectorNumber uint64) error {
+		extension.Sectors = append(extension.Sectors, sectorNumber)
+		return nil
+	})
+	return extension
+}
+
 func getControlAddrs(addrs []address.Address) []string {
 	r := make([]string, len(addrs))
 	for i, addr := range addrs {
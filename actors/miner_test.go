@@ -1,9 +1,13 @@
 package actors
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/builtin/v11/miner"
+	"github.com/filecoin-project/go-state-types/builtin/v11/verifreg"
 	"github.com/filecoin-project/go-state-types/manifest"
 	"github.com/stretchr/testify/require"
 	"github.com/zondax/fil-parser/parser"
@@ -315,3 +319,61 @@ func TestActorParser_minerWithParamsAndReturn(t *testing.T) {
 		})
 	}
 }
+
+func TestActorParser_minerNetworkInfo(t *testing.T) {
+	stable := getActorParser()
+	p := getActorParserWithConfig(parser.FilecoinParserConfig{ExperimentalFeatures: []string{string(parser.FeatureMinerNetworkInfo)}})
+	for _, txType := range []string{parser.MethodChangePeerID, parser.MethodChangeMultiaddrs} {
+		t.Run(txType, func(t *testing.T) {
+			rawParams, err := loadFile(manifest.MinerKey, txType, parser.ParamsKey)
+			require.NoError(t, err)
+
+			got, err := p.parseStorageminer(txType, &parser.LotusMessage{Params: rawParams}, &parser.LotusMessageReceipt{})
+			require.NoError(t, err)
+			require.IsType(t, parser.MinerNetworkInfo{}, got[parser.MinerNetworkInfoKey])
+
+			// the network info is experimental, it is not added unless enabled
+			got, err = stable.parseStorageminer(txType, &parser.LotusMessage{Params: rawParams}, &parser.LotusMessageReceipt{})
+			require.NoError(t, err)
+			require.NotContains(t, got, parser.MinerNetworkInfoKey)
+		})
+	}
+}
+
+func TestActorParser_sectorExtensions(t *testing.T) {
+	legacy := miner.ExtendSectorExpirationParams{Extensions: []miner.ExpirationExtension{
+		{Deadline: 1, Partition: 2, Sectors: bitfield.NewFromSet([]uint64{10, 11}), NewExpiration: 5000},
+	}}
+	unified := miner.ExtendSectorExpiration2Params{Extensions: []miner.ExpirationExtension2{
+		{
+			Deadline:      1,
+			Partition:     2,
+			Sectors:       bitfield.NewFromSet([]uint64{11}),
+			NewExpiration: 5000,
+			SectorsWithClaims: []miner.SectorClaim{
+				{SectorNumber: 10, MaintainClaims: []verifreg.ClaimId{7}, DropClaims: []verifreg.ClaimId{8, 9}},
+			},
+		},
+	}}
+	rawLegacy := new(bytes.Buffer)
+	require.NoError(t, legacy.MarshalCBOR(rawLegacy))
+	rawUnified := new(bytes.Buffer)
+	require.NoError(t, unified.MarshalCBOR(rawUnified))
+
+	expected := parser.SectorExtension{Deadline: 1, Partition: 2, Sectors: []uint64{10, 11}, NewExpiration: 5000}
+	p := getActorParserWithConfig(parser.FilecoinParserConfig{ExperimentalFeatures: []string{string(parser.FeatureSectorExtensions)}})
+
+	got, err := p.extendSectorExpiration(rawLegacy.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []parser.SectorExtension{expected}, got[parser.SectorExtensionsKey])
+
+	expected.ClaimsDropped = []uint64{8, 9}
+	got, err = p.extendSectorExpiration2(rawUnified.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []parser.SectorExtension{expected}, got[parser.SectorExtensionsKey])
+
+	// the unified view is experimental, it is not added unless enabled
+	got, err = getActorParser().extendSectorExpiration2(rawUnified.Bytes())
+	require.NoError(t, err)
+	require.NotContains(t, got, parser.SectorExtensionsKey)
+}
@@ -0,0 +1,39 @@
+// Package minernet decodes the peer ids and multiaddrs announced by the miners, see parser.MinerNetworkInfo.
+// It holds the libp2p and multiaddr dependencies of the miner_network_info feature, so the actors parsers do
+// not depend on them directly.
+package minernet
+
+import (
+	"encoding/base64"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// DecodePeerID returns the text form of the peer id, or the base64 of the raw bytes if they are not a valid
+// peer id. It is empty if there is no peer id.
+func DecodePeerID(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	id, err := peer.IDFromBytes(raw)
+	if err != nil {
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+	return id.String()
+}
+
+// DecodeMultiaddrs returns the text form of the multiaddrs, keeping the base64 of the raw bytes of the invalid ones
+func DecodeMultiaddrs(raw []abi.Multiaddrs) []string {
+	addrs := make([]string, 0, len(raw))
+	for _, rawAddr := range raw {
+		addr, err := multiaddr.NewMultiaddrBytes(rawAddr)
+		if err != nil {
+			addrs = append(addrs, base64.StdEncoding.EncodeToString(rawAddr))
+			continue
+		}
+		addrs = append(addrs, addr.String())
+	}
+	return addrs
+}
@@ -0,0 +1,27 @@
+package minernet
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePeerID(t *testing.T) {
+	id, err := peer.Decode("12D3KooWGzxzKZYveHXtpG6AsrUJBcWxHBFS2HsEoGTxrMLvKXtf")
+	require.NoError(t, err)
+
+	require.Equal(t, id.String(), DecodePeerID([]byte(id)))
+	require.Empty(t, DecodePeerID(nil))
+	require.Equal(t, "AQI=", DecodePeerID([]byte{1, 2}))
+}
+
+func TestDecodeMultiaddrs(t *testing.T) {
+	addr, err := multiaddr.NewMultiaddr("/ip4/1.2.3.4/tcp/24001")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/ip4/1.2.3.4/tcp/24001", "AQI="}, DecodeMultiaddrs([]abi.Multiaddrs{addr.Bytes(), {1, 2}}))
+	require.Empty(t, DecodeMultiaddrs(nil))
+}
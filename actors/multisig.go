@@ -2,6 +2,7 @@ package actors
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -23,7 +24,7 @@ Still needs to parse:
 
 	Receive
 */
-func (p *ActorParser) ParseMultisig(txType string, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
+func (p *ActorParser) ParseMultisig(ctx context.Context, txType string, msg *parser.LotusMessage, msgRct *parser.LotusMessageReceipt, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
 	switch txType {
 	case parser.MethodConstructor: // TODO: not tested
 		return p.msigConstructor(msg.Params)
@@ -32,13 +33,13 @@ func (p *ActorParser) ParseMultisig(txType string, msg *parser.LotusMessage, msg
 	case parser.MethodPropose, parser.MethodProposeExported:
 		return p.propose(msg.Params, msgRct.Return)
 	case parser.MethodApprove, parser.MethodApproveExported:
-		return p.approve(msg, msgRct.Return, height, key)
+		return p.approve(ctx, msg, msgRct.Return, height, key)
 	case parser.MethodCancel, parser.MethodCancelExported:
-		return p.cancel(msg, height, key)
+		return p.cancel(ctx, msg, height, key)
 	case parser.MethodAddSigner, parser.MethodAddSignerExported, parser.MethodSwapSigner, parser.MethodSwapSignerExported:
-		return p.msigParams(msg, height, key)
+		return p.msigParams(ctx, msg, height, key)
 	case parser.MethodRemoveSigner, parser.MethodRemoveSignerExported:
-		return p.removeSigner(msg, height, key)
+		return p.removeSigner(ctx, msg, height, key)
 	case parser.MethodChangeNumApprovalsThreshold, parser.MethodChangeNumApprovalsThresholdExported:
 		return p.changeNumApprovalsThreshold(msg.Params)
 	case parser.MethodLockBalance, parser.MethodLockBalanceExported:
@@ -46,7 +47,7 @@ func (p *ActorParser) ParseMultisig(txType string, msg *parser.LotusMessage, msg
 	case parser.MethodMsigUniversalReceiverHook: // TODO: not tested
 		return p.universalReceiverHook(msg.Params)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
@@ -63,8 +64,8 @@ func (p *ActorParser) msigConstructor(raw []byte) (map[string]interface{}, error
 	return metadata, nil
 }
 
-func (p *ActorParser) msigParams(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
-	params, err := p.parseMsigParams(msg, height, key)
+func (p *ActorParser) msigParams(ctx context.Context, msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
+	params, err := p.parseMsigParams(ctx, msg, height, key)
 	if err != nil {
 		return map[string]interface{}{}, err
 	}
@@ -104,9 +105,9 @@ func (p *ActorParser) propose(rawParams, rawReturn []byte) (map[string]interface
 	return metadata, nil
 }
 
-func (p *ActorParser) approve(msg *parser.LotusMessage, rawReturn []byte, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
+func (p *ActorParser) approve(ctx context.Context, msg *parser.LotusMessage, rawReturn []byte, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
-	params, err := p.parseMsigParams(msg, height, key)
+	params, err := p.parseMsigParams(ctx, msg, height, key)
 	if err != nil {
 		return map[string]interface{}{}, err
 	}
@@ -121,9 +122,9 @@ func (p *ActorParser) approve(msg *parser.LotusMessage, rawReturn []byte, height
 	return metadata, nil
 }
 
-func (p *ActorParser) cancel(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
+func (p *ActorParser) cancel(ctx context.Context, msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
-	params, err := p.parseMsigParams(msg, height, key)
+	params, err := p.parseMsigParams(ctx, msg, height, key)
 	if err != nil {
 		return map[string]interface{}{}, err
 	}
@@ -131,9 +132,9 @@ func (p *ActorParser) cancel(msg *parser.LotusMessage, height int64, key filType
 	return metadata, nil
 }
 
-func (p *ActorParser) removeSigner(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
+func (p *ActorParser) removeSigner(ctx context.Context, msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
-	params, err := p.parseMsigParams(msg, height, key)
+	params, err := p.parseMsigParams(ctx, msg, height, key)
 	if err != nil {
 		return map[string]interface{}{}, err
 	}
@@ -165,14 +166,14 @@ func (p *ActorParser) lockBalance(raw []byte) (map[string]interface{}, error) {
 	return metadata, nil
 }
 
-func (p *ActorParser) parseMsigParams(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (string, error) {
+func (p *ActorParser) parseMsigParams(ctx context.Context, msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (string, error) {
 	msgSerial, err := msg.MarshalJSON() // TODO: this may not work properly
 	if err != nil {
 		p.logger.Sugar().Errorf("Could not parse params. Cannot serialize lotus message: %v", err)
 		return "", err
 	}
 
-	actorCode, err := p.helper.GetActorsCache().GetActorCode(msg.To, key, false)
+	actorCode, err := p.helper.GetActorsCache().GetActorCodeWithContext(ctx, msg.To, key, false)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package actors
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
@@ -47,7 +48,7 @@ func TestActorParser_approve(t *testing.T) {
 			tipSet, err := deserializeTipset(manifest.MultisigKey, tt.method)
 			require.NoError(t, err)
 
-			got, err := p.approve(msg, rawReturn, int64(tipSet.Height()), tipSet.Key())
+			got, err := p.approve(context.Background(), msg, rawReturn, int64(tipSet.Height()), tipSet.Key())
 			require.NoError(t, err)
 			require.NotNil(t, got)
 		})
@@ -95,7 +96,7 @@ func TestActorParser_multiSigParams(t *testing.T) {
 	tests := []struct {
 		name   string
 		txType string
-		f      func(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error)
+		f      func(ctx context.Context, msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (map[string]interface{}, error)
 	}{
 		{
 			name:   "Add Signer",
@@ -149,7 +150,7 @@ func TestActorParser_multiSigParams(t *testing.T) {
 			tipset, err := deserializeTipset(manifest.MultisigKey, tt.txType)
 			require.NoError(t, err)
 
-			got, err := tt.f(msg, int64(tipset.Height()), tipset.Key())
+			got, err := tt.f(context.Background(), msg, int64(tipset.Height()), tipset.Key())
 			require.NoError(t, err)
 			require.NotNil(t, got)
 		})
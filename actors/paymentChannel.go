@@ -24,7 +24,7 @@ func (p *ActorParser) ParsePaymentchannel(txType string, msg *parser.LotusMessag
 	case parser.MethodSettle, parser.MethodCollect:
 		return p.emptyParamsAndReturn()
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
@@ -43,7 +43,7 @@ func (p *ActorParser) ParseStoragepower(txType string, msg *parser.LotusMessage,
 	case parser.MethodMinerConsensusCountExported:
 		metadata, err = p.minerConsensusCount(msgRct.Return)
 	case parser.UnknownStr:
-		metadata, err = p.unknownMetadata(msg.Params, msgRct.Return)
+		metadata, err = p.unknownMetadata(msg, msgRct)
 	default:
 		err = parser.ErrUnknownMethod
 	}
@@ -21,7 +21,7 @@ func (p *ActorParser) ParseReward(txType string, msg *parser.LotusMessage, msgRc
 	case parser.MethodThisEpochReward:
 		return p.thisEpochReward(msgRct.Return)
 	case parser.UnknownStr:
-		return p.unknownMetadata(msg.Params, msgRct.Return)
+		return p.unknownMetadata(msg, msgRct)
 	}
 	return map[string]interface{}{}, parser.ErrUnknownMethod
 }
@@ -0,0 +1,129 @@
+package fil_parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/zondax/fil-parser/types"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// CapabilityTraces is the support of the node traces by the parser, see NodeCapabilities.ParserVersion
+	CapabilityTraces = "traces"
+	// CapabilityEthAPI is the eth json-rpc api, e.g. eth_chainId
+	CapabilityEthAPI = "eth_api"
+	// CapabilityEthTraces is trace_block, see ParseEthTraces
+	CapabilityEthTraces = "eth_traces"
+	// CapabilityEthReceipts is eth_getBlockReceipts, see TxsData.EthReceipts
+	CapabilityEthReceipts = "eth_receipts"
+	// CapabilityEvents is the actor events api, see ParseNativeEvents
+	CapabilityEvents = "events"
+)
+
+// NodeCapability is whether the node supports one of the apis used to fetch the parser inputs
+type NodeCapability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NodeCapabilities is the version of the node and the apis it supports, so the fetch strategies can be chosen
+// from it instead of being configured by hand for every node
+type NodeCapabilities struct {
+	NodeFullVersion       string `json:"node_full_version"`
+	NodeMajorMinorVersion string `json:"node_major_minor_version"`
+	APIVersion            string `json:"api_version"`
+	// ParserVersion is the parser version matching the traces of the node, empty if the node version is not supported
+	ParserVersion string           `json:"parser_version,omitempty"`
+	Capabilities  []NodeCapability `json:"capabilities"`
+}
+
+// Supports returns whether the capability is available in the node
+func (c *NodeCapabilities) Supports(name string) bool {
+	for _, capability := range c.Capabilities {
+		if capability.Name == name {
+			return capability.Available
+		}
+	}
+	return false
+}
+
+// ProbeNodeCapabilities asks the node for its version and probes the apis used to fetch the parser inputs. An api
+// is only reported as available if the probe succeeds; the error of the failed probes is kept in the report.
+// Probes run against the head of the chain, so the node must be synced for them to succeed.
+func (p *FilecoinParser) ProbeNodeCapabilities(ctx context.Context) (*NodeCapabilities, error) {
+	node := p.Helper.GetFilecoinNodeClient()
+	if node == nil {
+		return nil, errors.New("node client is nil")
+	}
+
+	version, err := node.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get node version: %w", err)
+	}
+	capabilities := &NodeCapabilities{
+		NodeFullVersion:       version.Version,
+		NodeMajorMinorVersion: nodeMajorMinorVersion(version.Version),
+		APIVersion:            version.APIVersion.String(),
+	}
+
+	traces := NodeCapability{Name: CapabilityTraces}
+	metadata := types.BlockMetadata{NodeInfo: types.NodeInfo{NodeFullVersion: capabilities.NodeFullVersion,
+		NodeMajorMinorVersion: capabilities.NodeMajorMinorVersion}}
+	// An empty version is taken as v1 for backwards compatibility of old traces, but it is unknown for a node
+	parserVersion, err := p.translateParserVersionFromMetadata(metadata)
+	if err != nil || (capabilities.NodeMajorMinorVersion == "" && !metadata.IsForest()) {
+		traces.Error = fmt.Sprintf("node version not supported %s", capabilities.NodeFullVersion)
+	} else {
+		capabilities.ParserVersion = parserVersion
+		traces.Available = true
+	}
+	capabilities.Capabilities = append(capabilities.Capabilities, traces)
+
+	probes := []struct {
+		name  string
+		probe func(ctx context.Context, node types.FullNode) error
+	}{
+		{name: CapabilityEthAPI, probe: func(ctx context.Context, node types.FullNode) error {
+			_, err := node.EthChainId(ctx)
+			return err
+		}},
+		{name: CapabilityEthTraces, probe: func(ctx context.Context, node types.FullNode) error {
+			_, err := node.EthTraceBlock(ctx, "latest")
+			return err
+		}},
+		{name: CapabilityEthReceipts, probe: func(ctx context.Context, node types.FullNode) error {
+			_, err := node.EthGetBlockReceipts(ctx, ethtypes.NewEthBlockNumberOrHashFromPredefined("latest"))
+			return err
+		}},
+		{name: CapabilityEvents, probe: func(ctx context.Context, node types.FullNode) error {
+			_, err := node.GetActorEventsRaw(ctx, nil)
+			return err
+		}},
+	}
+	for _, probe := range probes {
+		capability := NodeCapability{Name: probe.name, Available: true}
+		if err := probe.probe(ctx, node); err != nil {
+			p.logger.Sugar().Warnf("node capability %s is not available: %v", probe.name, err)
+			capability.Available = false
+			capability.Error = err.Error()
+		}
+		capabilities.Capabilities = append(capabilities.Capabilities, capability)
+	}
+
+	return capabilities, nil
+}
+
+// nodeMajorMinorVersion returns the major.minor version of a lotus version, e.g. v1.31 for 1.31.0+mainnet+git.abc.
+// It is empty if the version can not be parsed.
+func nodeMajorMinorVersion(fullVersion string) string {
+	version := strings.Split(fullVersion, "+")[0]
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return semver.MajorMinor(version)
+}
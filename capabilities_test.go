@@ -0,0 +1,49 @@
+package fil_parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/mocks"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	v2 "github.com/zondax/fil-parser/parser/v2"
+)
+
+func TestFilecoinParser_ProbeNodeCapabilities(t *testing.T) {
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	p, err := NewFilecoinParser(nil, common.DataSource{Node: node}, nil)
+	require.NoError(t, err)
+
+	node.EXPECT().Version(gomock.Any()).Return(api.APIVersion{Version: "1.31.0+mainnet+git.3f7c1ea", APIVersion: api.FullAPIVersion1}, nil)
+	node.EXPECT().EthChainId(gomock.Any()).Return(ethtypes.EthUint64(314), nil)
+	node.EXPECT().EthTraceBlock(gomock.Any(), "latest").Return(nil, nil)
+	node.EXPECT().EthGetBlockReceipts(gomock.Any(), gomock.Any()).Return(nil, errors.New("method 'Filecoin.EthGetBlockReceipts' not found"))
+	node.EXPECT().GetActorEventsRaw(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	capabilities, err := p.ProbeNodeCapabilities(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "v1.31", capabilities.NodeMajorMinorVersion)
+	require.Equal(t, v2.Version, capabilities.ParserVersion)
+	require.True(t, capabilities.Supports(CapabilityTraces))
+	require.True(t, capabilities.Supports(CapabilityEthAPI))
+	require.True(t, capabilities.Supports(CapabilityEthTraces))
+	require.False(t, capabilities.Supports(CapabilityEthReceipts))
+	require.True(t, capabilities.Supports(CapabilityEvents))
+	require.False(t, capabilities.Supports("unknown"))
+
+	// the report can not be built without the node version
+	node.EXPECT().Version(gomock.Any()).Return(api.APIVersion{}, errors.New("connection refused"))
+	_, err = p.ProbeNodeCapabilities(context.Background())
+	require.Error(t, err)
+}
+
+func TestNodeMajorMinorVersion(t *testing.T) {
+	require.Equal(t, "v1.31", nodeMajorMinorVersion("1.31.0+mainnet+git.3f7c1ea"))
+	require.Equal(t, "v1.25", nodeMajorMinorVersion("v1.25.2"))
+	require.Equal(t, "", nodeMajorMinorVersion("forest"))
+}
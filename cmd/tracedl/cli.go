@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
@@ -10,9 +11,14 @@ import (
 	"path/filepath"
 
 	"github.com/bytedance/sonic"
+	"github.com/filecoin-project/go-state-types/abi"
 	lotusChainTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/klauspost/compress/s2"
 	"github.com/spf13/cobra"
+	"github.com/zondax/fil-parser/actors/cache"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/tools/jobs"
 	"github.com/zondax/golem/pkg/cli"
 	"go.uber.org/zap"
 )
@@ -67,7 +73,235 @@ func get(c *cli.CLI, cmd *cobra.Command, _ []string) {
 		return
 	}
 
+	if err = downloadHeight(rpcClient, logType, height, outPath, format); err != nil {
+		zap.S().Error(err)
+		return
+	}
+}
+
+func GetRangeCommand(c *cli.CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "range",
+		Short: "Download a range of heights, resuming from the last checkpoint",
+		Run: func(cmd *cobra.Command, args []string) {
+			getRange(c, cmd, args)
+		},
+	}
+	cmd.Flags().StringSlice("types", []string{"traces", "tipset", "ethlog", "nativelog"}, "--types traces,tipset")
+	cmd.Flags().String("outPath", ".", "--outPath ../")
+	cmd.Flags().String("compress", "gz", "--compress s2")
+	cmd.Flags().Uint64("from", 0, "--from 387926")
+	cmd.Flags().Uint64("to", 0, "--to 387930")
+	cmd.Flags().String("checkpoint", "tracedl_checkpoint.json", "--checkpoint ./checkpoint.json")
+	cmd.Flags().Int("retries", jobs.DefaultMaxRetries, "--retries 3")
+	cmd.Flags().String("quarantine", "", "--quarantine ./quarantine.json")
+	cmd.Flags().Bool("retryQuarantined", false, "--retryQuarantined")
+	return cmd
+}
+
+func getRange(c *cli.CLI, cmd *cobra.Command, _ []string) {
+	zap.S().Infof(c.GetVersionString())
+
+	config, err := cli.LoadConfig[Config]()
+	if err != nil {
+		zap.S().Errorf("Error loading config: %s", err)
+		return
+	}
+	logTypes, err := cmd.Flags().GetStringSlice("types")
+	if err != nil {
+		zap.S().Errorf("Error loading types: %s", err)
+		return
+	}
+	outPath, err := cmd.Flags().GetString("outPath")
+	if err != nil {
+		zap.S().Errorf("Error loading outPath: %s", err)
+		return
+	}
+	format, err := cmd.Flags().GetString("compress")
+	if err != nil {
+		zap.S().Errorf("Error loading compress: %s", err)
+		return
+	}
+	from, err := cmd.Flags().GetUint64("from")
+	if err != nil {
+		zap.S().Errorf("Error loading from: %s", err)
+		return
+	}
+	to, err := cmd.Flags().GetUint64("to")
+	if err != nil {
+		zap.S().Errorf("Error loading to: %s", err)
+		return
+	}
+	checkpointPath, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		zap.S().Errorf("Error loading checkpoint: %s", err)
+		return
+	}
+	retries, err := cmd.Flags().GetInt("retries")
+	if err != nil {
+		zap.S().Errorf("Error loading retries: %s", err)
+		return
+	}
+	quarantinePath, err := cmd.Flags().GetString("quarantine")
+	if err != nil {
+		zap.S().Errorf("Error loading quarantine: %s", err)
+		return
+	}
+	retryQuarantined, err := cmd.Flags().GetBool("retryQuarantined")
+	if err != nil {
+		zap.S().Errorf("Error loading retryQuarantined: %s", err)
+		return
+	}
+
+	// heights quarantined by another build are retried, as it may download them fine
+	jobConfig := jobs.Config{From: from, To: to, MaxRetries: retries, RetryQuarantined: retryQuarantined,
+		ParserVersion: parser.GetBuildInfo().String()}
+	if quarantinePath != "" {
+		quarantine, err := jobs.NewFileQuarantine(quarantinePath)
+		if err != nil {
+			zap.S().Error(err)
+			return
+		}
+		jobConfig.Quarantine = quarantine
+	}
+
+	rpcClient, err := newFilecoinRPCClient(config.NodeURL, config.NodeToken)
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+
+	job, err := jobs.NewRangeJob(jobConfig, func(_ context.Context, height uint64) error {
+		for _, logType := range logTypes {
+			if err := downloadHeight(rpcClient, logType, height, outPath, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, jobs.NewFileCheckpointer(checkpointPath), zap.L())
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+
+	if retryQuarantined {
+		if err = job.RunQuarantined(cmd.Context()); err != nil {
+			zap.S().Error(err)
+			return
+		}
+	}
+
+	if err = job.Run(cmd.Context()); err != nil {
+		zap.S().Error(err)
+		return
+	}
+}
+
+func GetWarmCommand(c *cli.CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Resolve the addresses found in the traces of a range of heights into the actors cache",
+		Run: func(cmd *cobra.Command, args []string) {
+			warmRange(c, cmd, args)
+		},
+	}
+	cmd.Flags().Uint64("from", 0, "--from 387926")
+	cmd.Flags().Uint64("to", 0, "--to 387930")
+	cmd.Flags().String("checkpoint", "tracedl_warm_checkpoint.json", "--checkpoint ./checkpoint.json")
+	cmd.Flags().Int("retries", jobs.DefaultMaxRetries, "--retries 3")
+	return cmd
+}
+
+func warmRange(c *cli.CLI, cmd *cobra.Command, _ []string) {
+	zap.S().Infof(c.GetVersionString())
+
+	config, err := cli.LoadConfig[Config]()
+	if err != nil {
+		zap.S().Errorf("Error loading config: %s", err)
+		return
+	}
+	from, err := cmd.Flags().GetUint64("from")
+	if err != nil {
+		zap.S().Errorf("Error loading from: %s", err)
+		return
+	}
+	to, err := cmd.Flags().GetUint64("to")
+	if err != nil {
+		zap.S().Errorf("Error loading to: %s", err)
+		return
+	}
+	checkpointPath, err := cmd.Flags().GetString("checkpoint")
+	if err != nil {
+		zap.S().Errorf("Error loading checkpoint: %s", err)
+		return
+	}
+	retries, err := cmd.Flags().GetInt("retries")
+	if err != nil {
+		zap.S().Errorf("Error loading retries: %s", err)
+		return
+	}
+
+	rpcClient, err := newFilecoinRPCClient(config.NodeURL, config.NodeToken)
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+
+	// warming an in-memory cache is useless, as it is dropped on exit
+	actorsCache, err := cache.SetupActorsCache(common.DataSource{
+		Node:   rpcClient.client,
+		Config: common.DataSourceConfig{Cache: config.Cache, NetworkName: config.NetworkName, RequireRemoteCache: true},
+	}, zap.L())
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+
+	var total cache.WarmStats
+	job, err := jobs.NewRangeJob(jobs.Config{From: from, To: to, MaxRetries: retries}, func(ctx context.Context, height uint64) error {
+		stats, err := warmHeight(ctx, rpcClient, actorsCache, height)
+		if err != nil {
+			return err
+		}
+		zap.S().Infof("warmed height %d: %d addresses, %d resolved, %d failed", height, stats.Addresses, stats.Resolved, stats.Failed)
+		total.Addresses += stats.Addresses
+		total.Resolved += stats.Resolved
+		total.Failed += stats.Failed
+		return nil
+	}, jobs.NewFileCheckpointer(checkpointPath), zap.L())
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+
+	if err = job.Run(cmd.Context()); err != nil {
+		zap.S().Error(err)
+		return
+	}
+	zap.S().Infof("warmed heights %d to %d: %d addresses, %d resolved, %d failed", from, to, total.Addresses, total.Resolved, total.Failed)
+}
+
+// warmHeight resolves the addresses found in the traces of the height into the actors cache, without parsing them
+func warmHeight(ctx context.Context, rpcClient *RPCClient, actorsCache *cache.ActorsCache, height uint64) (cache.WarmStats, error) {
+	traces, err := getTraceFileByHeight(height, rpcClient.client)
+	if err != nil || traces == nil {
+		return cache.WarmStats{}, err
+	}
+	tipset, err := rpcClient.client.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(height), lotusChainTypes.EmptyTSK)
+	if err != nil {
+		return cache.WarmStats{}, err
+	}
+
+	tracesJson, err := sonic.Marshal(traces)
+	if err != nil {
+		return cache.WarmStats{}, err
+	}
+	return actorsCache.Warm(ctx, parser.ExtractAddresses(tracesJson), tipset.Key())
+}
+
+func downloadHeight(rpcClient *RPCClient, logType string, height uint64, outPath, format string) error {
 	var data any
+	var err error
 	switch logType {
 	case "traces":
 		data, err = getTraceFileByHeight(height, rpcClient.client)
@@ -79,17 +313,17 @@ func get(c *cli.CLI, cmd *cobra.Command, _ []string) {
 		data, err = getNativeLogsByHeight(height, rpcClient.client)
 	case "metadata":
 		data, err = getMetadata(rpcClient)
+	default:
+		return fmt.Errorf("unknown type %s", logType)
 	}
 
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return err
 	}
 
 	dataJson, err := sonic.Marshal(data)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return err
 	}
 
 	out := dataJson
@@ -97,17 +331,12 @@ func get(c *cli.CLI, cmd *cobra.Command, _ []string) {
 	if format != "" {
 		out, err = compress(format, dataJson)
 		if err != nil {
-			zap.S().Error(err)
-			return
+			return err
 		}
 		fname = fmt.Sprintf("%s_%d.json.%s", logType, height, format)
 	}
 
-	if err := writeToFile(outPath, fname, out); err != nil {
-		zap.S().Error(err)
-		return
-	}
-
+	return writeToFile(outPath, fname, out)
 }
 
 func writeToFile(path, filename string, data []byte) error {
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+
+	"github.com/zondax/golem/pkg/zcache"
 )
 
 type Config struct {
@@ -12,6 +14,8 @@ type Config struct {
 	// NetworkSymbol is the token symbol for this network
 	NetworkSymbol string `mapstructure:"network_name"`
 	NodeToken     string `mapstructure:"node_token"`
+	// Cache is the actors cache warmed up by the warm command, an in-memory cache is used if not set
+	Cache *zcache.CombinedConfig `mapstructure:"cache"`
 }
 
 func (c Config) SetDefaults() {
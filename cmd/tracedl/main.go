@@ -16,6 +16,8 @@ func main() {
 	defer cli.Close()
 
 	cli.GetRoot().AddCommand(GetStartCommand(cli))
+	cli.GetRoot().AddCommand(GetRangeCommand(cli))
+	cli.GetRoot().AddCommand(GetWarmCommand(cli))
 
 	cli.Run()
 }
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/manifest"
 	types2 "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/google/uuid"
 	"github.com/zondax/fil-parser/actors/cache"
 	"github.com/zondax/fil-parser/actors/cache/impl/common"
@@ -21,8 +23,10 @@ import (
 	v2 "github.com/zondax/fil-parser/parser/v2"
 	"github.com/zondax/fil-parser/tools"
 	multisigTools "github.com/zondax/fil-parser/tools/multisig"
+	verifregTools "github.com/zondax/fil-parser/tools/verifreg"
 	"github.com/zondax/fil-parser/types"
-	rosettaFilecoinLib "github.com/zondax/rosetta-filecoin-lib"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -35,7 +39,14 @@ type FilecoinParser struct {
 	parserV1 Parser
 	parserV2 Parser
 	Helper   *helper2.Helper
-	logger   *zap.Logger
+	tagger   parser.AddressTagger
+	// contracts is optional, see WithContractMetadataProvider
+	contracts parser.ContractMetadataProvider
+	// resultsCache is optional, see WithResultsCache
+	resultsCache ResultsCache
+	// skippedTraceHook is optional, see WithSkippedTraceHook
+	skippedTraceHook SkippedTraceHook
+	logger           *zap.Logger
 }
 
 type Parser interface {
@@ -45,64 +56,674 @@ type Parser interface {
 	ParseNativeEvents(ctx context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error)
 	ParseMultisigEvents(ctx context.Context, multisigTxs []*types.Transaction, tipsetCid string, tipsetKey types2.TipSetKey) (*types.MultisigEvents, error)
 	ParseEthLogs(ctx context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error)
+	// Deprecated: the base fee may not fit in an uint64, implement BaseFeeParser too
 	GetBaseFee(traces []byte, tipset *types.ExtendedTipSet) (uint64, error)
 	IsNodeVersionSupported(ver string) bool
 }
 
-func NewFilecoinParser(lib *rosettaFilecoinLib.RosettaConstructionFilecoin, cacheSource common.DataSource, logger *zap.Logger) (*FilecoinParser, error) {
+// BaseFeeParser is implemented by the parsers that return the whole base fee along with how it was derived. It is
+// optional, the base fee of the parsers without it is read with GetBaseFee.
+type BaseFeeParser interface {
+	GetBaseFeeWithSource(traces []byte, tipset *types.ExtendedTipSet) (types.BaseFee, error)
+}
+
+// MessagesParser is implemented by the parsers that can parse the messages of a tipset with their receipts, for
+// nodes that can not serve execution traces. It is optional, see FilecoinParser.ParseMessages.
+type MessagesParser interface {
+	ParseMessages(ctx context.Context, messagesData types.MessagesData) (*types.TxsParsedResult, error)
+}
+
+// FeesParser is implemented by the parsers that can re-extract only the fee txs of a tipset from its traces. It is
+// optional, see FilecoinParser.ParseFees.
+type FeesParser interface {
+	ParseFees(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error)
+}
+
+// EthTracesParser is implemented by the parsers that can parse the eth traces of a tipset, in the layout of the
+// trace_block eth api. It is optional, see FilecoinParser.ParseEthTraces.
+type EthTracesParser interface {
+	ParseEthTraces(ctx context.Context, ethTracesData types.EthTracesData) (*types.TxsParsedResult, error)
+}
+
+// streamParser is implemented by the parsers that can pass the txs of every trace to fn as soon as it is parsed,
+// see FilecoinParser.ParseTransactionsStream
+type streamParser interface {
+	ParseTransactionsStream(ctx context.Context, txsData types.TxsData, fn func(txs []*types.Transaction) error) (*types.TxsParsedResult, error)
+}
+
+func NewFilecoinParser(lib *helper2.FilecoinLib, cacheSource common.DataSource, logger *zap.Logger, opts ...Option) (*FilecoinParser, error) {
 	logger = logger2.GetSafeLogger(logger)
-	actorsCache, err := cache.SetupActorsCache(cacheSource, logger)
+	options := newOptions(opts...)
+
+	var actorsCache *cache.ActorsCache
+	var err error
+	if options.offChainCache != nil {
+		actorsCache, err = cache.NewActorsCacheWithOffChain(options.offChainCache, cacheSource, logger)
+	} else {
+		actorsCache, err = cache.SetupActorsCache(cacheSource, logger)
+	}
 	if err != nil {
 		logger.Sugar().Errorf("could not setup actors cache: %v", err)
 		return nil, err
 	}
+	if options.kvOpHook != nil {
+		actorsCache.SetKvOpHook(options.kvOpThreshold, options.kvOpHook)
+	}
+
+	return newFilecoinParser(lib, actorsCache, cacheSource.Node, logger, options)
+}
+
+// NewFilecoinParserWithActorsCache creates a parser using an already set up actors cache, e.g. the one of the
+// fake package to parse without network access. The node is optional.
+func NewFilecoinParserWithActorsCache(lib *helper2.FilecoinLib, actorsCache *cache.ActorsCache, node types.FullNode, logger *zap.Logger, opts ...Option) (*FilecoinParser, error) {
+	return newFilecoinParser(lib, actorsCache, node, logger2.GetSafeLogger(logger), newOptions(opts...))
+}
+
+func newFilecoinParser(lib *helper2.FilecoinLib, actorsCache *cache.ActorsCache, node types.FullNode, logger *zap.Logger,
+	options *FilecoinParserOptions) (*FilecoinParser, error) {
+	if err := options.config.Validate(); err != nil {
+		logger.Sugar().Errorf("invalid parser config: %v", err)
+		return nil, err
+	}
 
-	helper := helper2.NewHelper(lib, actorsCache, cacheSource.Node, logger)
+	helper := helper2.NewHelper(lib, actorsCache, node, logger, options.config)
+	helper.SetEventSchemas(options.eventSchemas)
+	helper.SetTracer(parser.NewTracer(options.tracerProvider))
 	parserV1 := v1.NewParser(helper, logger)
 	parserV2 := v2.NewParser(helper, logger)
 
 	return &FilecoinParser{
-		parserV1: parserV1,
-		parserV2: parserV2,
-		Helper:   helper,
-		logger:   logger,
+		parserV1:         parserV1,
+		parserV2:         parserV2,
+		Helper:           helper,
+		tagger:           options.tagger,
+		contracts:        options.contracts,
+		resultsCache:     options.resultsCache,
+		skippedTraceHook: options.skippedTraceHook,
+		logger:           logger,
 	}, nil
 }
 
+// ParseTransactions parses the txs of the tipset running the steps of DefaultPipeline. If a results cache is
+// set, the stored output is returned when the same inputs were parsed before, see WithResultsCache.
 func (p *FilecoinParser) ParseTransactions(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
-	parserVersion, err := p.translateParserVersionFromMetadata(txsData.Metadata)
+	if p.resultsCache == nil {
+		return p.ParseTransactionsWithPipeline(ctx, p.DefaultPipeline(), txsData)
+	}
+
+	parserVersion, err := p.tracesParserVersion(txsData.Metadata, txsData.Traces)
 	if err != nil {
-		return nil, errUnknownVersion
+		return nil, err
+	}
+	// the inputs are hashed once, for the key and for the report
+	state := &PipelineState{TxsData: txsData}
+	key, err := p.resultsCacheKey(state.InputHashes(), txsData, parserVersion)
+	if err != nil {
+		p.logger.Sugar().Warnf("could not build results cache key: %v", err)
+		return p.runPipeline(ctx, p.DefaultPipeline(), state)
+	}
+	if cached, ok := p.resultsCache.Get(ctx, key); ok {
+		p.logger.Sugar().Debugf("[parser] - returning cached result %s", key)
+		return cached, nil
 	}
 
-	var parsedResult *types.TxsParsedResult
+	result, err := p.runPipeline(ctx, p.DefaultPipeline(), state)
+	if err != nil {
+		return nil, err
+	}
+	p.resultsCache.Set(ctx, key, result)
+	return result, nil
+}
 
-	p.logger.Sugar().Debugf("trace files node version: [%s] - parser to use: [%s]", txsData.Metadata.NodeMajorMinorVersion, parserVersion)
+// ParseFees re-extracts only the fee txs (and gas refunds, if enabled) of the tipset from its traces, skipping
+// params decoding and address consolidation. It is meant to recompute the fees of large ranges after a
+// fee-calculation fix; the ids of the fee txs and their parents match the ones built by ParseTransactions.
+func (p *FilecoinParser) ParseFees(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
+	parserVersion, err := p.tracesParserVersion(txsData.Metadata, txsData.Traces)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := p.startSpan(ctx, parser.SpanParseFees, txsData.Tipset, parserVersion)
+	defer span.End()
+
+	var impl Parser
 	switch parserVersion {
 	case v1.Version:
-		parsedResult, err = p.parserV1.ParseTransactions(ctx, txsData)
+		impl = p.parserV1
 	case v2.Version:
-		parsedResult, err = p.parserV2.ParseTransactions(ctx, txsData)
-	default:
+		impl = p.parserV2
+	}
+	feesParser, ok := impl.(FeesParser)
+	if !ok {
 		p.logger.Sugar().Errorf("[parser] implementation not supported: %s", parserVersion)
 		return nil, errUnknownImpl
 	}
 
+	parsedResult, err := feesParser.ParseFees(ctx, txsData)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	parsedResult.Txs, parsedResult.Report = p.filterDuplicated(parsedResult.Txs)
+	p.linkTxHierarchy(parsedResult.Txs)
+	parser.FormatAmounts(parsedResult.Txs, p.Helper.GetConfig().AmountFormat)
+	p.setBuildInfo(parsedResult)
+
+	return parsedResult, nil
+}
+
+// ParseTransactionsStream parses the txs of the tipset, passing the txs of every trace to fn as soon as the trace
+// is parsed, so tipsets with a huge amount of sub-calls can be indexed without holding all their txs in memory.
+// Parsing stops at the first error returned by fn. The returned result holds the addresses, tx cids and report
+// but no txs.
+//
+// Only the steps that work on the txs of a single trace are run: the duplicates are dropped and reported, see
+// filterStreamDuplicated, the tx hierarchy is linked (a trace holds every tx of its message, fees included), the
+// metadata is rendered and the build info stamped. The steps needing the txs of the whole tipset (eth logs and
+// receipts reconciliation, anomalies, invariants...) are skipped; use ParseTransactions if they are needed.
+func (p *FilecoinParser) ParseTransactionsStream(ctx context.Context, txsData types.TxsData, fn func(txs []*types.Transaction) error) (*types.TxsParsedResult, error) {
+	parserVersion, err := p.tracesParserVersion(txsData.Metadata, txsData.Traces)
+	if err != nil {
+		return nil, err
+	}
+	p.setHeadEpoch(txsData.Tipset)
+
+	ctx, span := p.startSpan(ctx, parser.SpanParseTransactions, txsData.Tipset, parserVersion)
+	defer span.End()
+
+	config := p.Helper.GetConfig()
+	decodeCtx, cancel := parser.WithStageTimeout(ctx, config.DecodeTimeout)
+	defer cancel()
+
+	// addresses of protocols newer than the parser are replaced, so the traces can still be decoded
+	var unknownAddresses *parser.UnknownAddresses
+	txsData.Traces, unknownAddresses = parser.SanitizeUnknownAddresses(txsData.Traces)
+
+	var impl Parser
+	switch parserVersion {
+	case v1.Version:
+		impl = p.parserV1
+	case v2.Version:
+		impl = p.parserV2
+	}
+	stream, ok := impl.(streamParser)
+	if !ok {
+		p.logger.Sugar().Errorf("[parser] implementation not supported: %s", parserVersion)
+		return nil, errUnknownImpl
+	}
+
+	build := parser.GetBuildInfo()
+	emitted := make(map[string]struct{})
+	var duplicated types.ParseReport
+	emit := func(txs []*types.Transaction) error {
+		txs = p.filterStreamDuplicated(txs, emitted, &duplicated)
+		if len(txs) == 0 {
+			return nil
+		}
+		p.linkTxHierarchy(txs)
+		if err := unknownAddresses.Restore(txs, nil); err != nil {
+			p.logger.Sugar().Errorf("[parser] - could not mark the txs with unknown addresses: %v", err)
+		}
+		p.compressMetadata(txs)
+		parser.FormatAmounts(txs, config.AmountFormat)
+		if config.StampBuildInfo {
+			for _, tx := range txs {
+				tx.ParserBuild = build.String()
+			}
+		}
+		return fn(txs)
+	}
+
+	parsedResult, err := stream.ParseTransactionsStream(decodeCtx, txsData, emit)
+	if err != nil && errors.Is(decodeCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %s exceeded %s: %w", ErrStageTimeout, StepDecodeTraces, config.DecodeTimeout, err)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// the placeholders of the unknown addresses were restored in the txs already
+	if err = unknownAddresses.Restore(nil, parsedResult.Addresses); err != nil {
+		p.logger.Sugar().Errorf("[parser] - could not mark the txs with unknown addresses: %v", err)
+	}
+	if err = p.checkAddressLookups(parsedResult, StepDecodeParams); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err = p.validateAddresses(ctx, parsedResult, txsData.Tipset); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	parsedResult.Report.DuplicatedTxs += duplicated.DuplicatedTxs
+	parsedResult.Report.DuplicatedFees += duplicated.DuplicatedFees
+	parsedResult.Report.SkippedTraces = append(parsedResult.Report.SkippedTraces, duplicated.SkippedTraces...)
+	parsedResult.Report.Build = build
+	return parsedResult, nil
+}
+
+// UnknownMethods returns the (actor, method) pairs that could not be decoded since the parser was created,
+// the most called first. They point to the decoders missing after a network upgrade.
+func (p *FilecoinParser) UnknownMethods() []parser.UnknownMethod {
+	return p.Helper.GetUnknownMethods().Snapshot()
+}
+
+// ParseMessages parses the messages of a tipset with their receipts, for nodes that can not serve execution
+// traces. Only top-level transactions are returned, without internal calls nor fees. The messages layout does
+// not depend on the node version, so the latest parser is always used.
+func (p *FilecoinParser) ParseMessages(ctx context.Context, messagesData types.MessagesData) (*types.TxsParsedResult, error) {
+	p.setHeadEpoch(messagesData.Tipset)
+
+	ctx, span := p.startSpan(ctx, parser.SpanParseMessages, messagesData.Tipset, v2.Version)
+	defer span.End()
+
+	messagesParser, ok := p.parserV2.(MessagesParser)
+	if !ok {
+		p.logger.Sugar().Errorf("[parser] implementation not supported: %s", v2.Version)
+		return nil, errUnknownImpl
+	}
+
+	parsedResult, err := messagesParser.ParseMessages(ctx, messagesData)
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
-	parsedResult.Txs = p.FilterDuplicated(parsedResult.Txs)
+	skippedTraces := parsedResult.Report.SkippedTraces
+	parsedResult.Txs, parsedResult.Report = p.filterDuplicated(parsedResult.Txs)
+	p.reportSkippedTraces(parsedResult, skippedTraces, messagesData.Tipset)
+	if err = p.checkAddressLookups(parsedResult, StepDecodeParams); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	p.linkTxHierarchy(parsedResult.Txs)
+	p.setInputHashes(parsedResult, types.HashMessagesData(messagesData))
+	p.detectAnomalies(parsedResult, messagesData.Tipset)
+	p.checkInvariants(parsedResult, messagesData.Tipset)
+	if err = p.validateAddresses(ctx, parsedResult, messagesData.Tipset); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	p.invalidateDeletedActors(parsedResult.Txs)
+	p.setEscrowChanges(parsedResult)
+	p.setAccountPromotions(ctx, parsedResult, messagesData.Tipset)
+	p.setBlockInclusions(parsedResult, messagesData.Tipset)
+	p.tagAddresses(ctx, parsedResult)
+	p.setVerifiedContracts(ctx, parsedResult)
+	p.compressMetadata(parsedResult.Txs)
+	parser.FormatAmounts(parsedResult.Txs, p.Helper.GetConfig().AmountFormat)
+	p.setBuildInfo(parsedResult)
 
 	return parsedResult, nil
 }
 
+// startSpan starts the span of the parse of a tipset
+func (p *FilecoinParser) startSpan(ctx context.Context, name string, tipset *types.ExtendedTipSet, parserVersion string) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{parser.AttributeParserVersion.String(parserVersion)}
+	if tipset != nil {
+		attributes = append(attributes, parser.AttributeHeight.Int64(int64(tipset.Height())))
+	}
+	return p.Helper.GetTracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// setHeadEpoch tags the entries the actors cache stores while parsing the tipset, so they can be invalidated
+// on reorgs with InvalidateAbove. The head only advances: backfills of older tipsets do not move it.
+func (p *FilecoinParser) setHeadEpoch(tipset *types.ExtendedTipSet) {
+	if tipset == nil {
+		return
+	}
+	p.Helper.GetActorsCache().SetHeadEpoch(int64(tipset.Height()))
+}
+
+// InvalidateAbove evicts the cached actors data stored while parsing epochs greater than the given one.
+// Chain followers must call it when a reorg is detected, before parsing the new tipsets.
+func (p *FilecoinParser) InvalidateAbove(epoch int64) int {
+	return p.Helper.GetActorsCache().InvalidateAbove(epoch)
+}
+
+// WarmActorsCache resolves the addresses found in the traces of a tipset into the actors cache, without parsing
+// them, so a later backfill of the tipset can run from the cache
+func (p *FilecoinParser) WarmActorsCache(ctx context.Context, traces []byte, tipset *types.ExtendedTipSet) (cache.WarmStats, error) {
+	return p.Helper.GetActorsCache().Warm(ctx, parser.ExtractAddresses(traces), tipset.Key())
+}
+
+// tagAddresses labels the from and to addresses of the txs, if an address tagger is configured
+func (p *FilecoinParser) tagAddresses(ctx context.Context, parsedResult *types.TxsParsedResult) {
+	if p.tagger == nil {
+		return
+	}
+
+	formats := addressFormats(parsedResult.Addresses)
+	for _, tx := range parsedResult.Txs {
+		tx.TxFromTags = p.addressTags(ctx, tx.TxFrom, formats)
+		tx.TxToTags = p.addressTags(ctx, tx.TxTo, formats)
+	}
+}
+
+// setVerifiedContracts adds the verified contract called by the evm txs to their metadata, if a contract
+// metadata provider is configured
+func (p *FilecoinParser) setVerifiedContracts(ctx context.Context, parsedResult *types.TxsParsedResult) {
+	if p.contracts == nil {
+		return
+	}
+
+	formats := addressFormats(parsedResult.Addresses)
+	for _, tx := range parsedResult.Txs {
+		if !parser.IsContractCall(tx.TxType) {
+			continue
+		}
+		contract, ok := p.verifiedContract(ctx, tx.TxTo, formats)
+		if !ok {
+			continue
+		}
+		if err := parser.AddVerifiedContractMetadata(tx, *contract); err != nil {
+			p.logger.Sugar().Warnf("could not add verified contract %s to tx %s: %v", tx.TxTo, tx.Id, err)
+		}
+	}
+}
+
+// verifiedContract returns the verified contract of the address, trying its other known formats if it has none
+func (p *FilecoinParser) verifiedContract(ctx context.Context, addr string, formats map[string]*types.AddressInfo) (*parser.VerifiedContract, bool) {
+	if contract, ok := p.contracts.VerifiedContract(ctx, addr); ok {
+		return contract, true
+	}
+
+	info, ok := formats[addr]
+	if !ok {
+		return nil, false
+	}
+	for _, other := range []string{info.Short, info.Robust, info.EthAddress} {
+		if other == "" || other == addr {
+			continue
+		}
+		if contract, ok := p.contracts.VerifiedContract(ctx, other); ok {
+			return contract, true
+		}
+	}
+	return nil, false
+}
+
+// addressFormats indexes the parsed addresses by all their formats, so they can be found whatever format an
+// address is given in
+func addressFormats(addresses *types.AddressInfoMap) map[string]*types.AddressInfo {
+	formats := make(map[string]*types.AddressInfo)
+	if addresses == nil {
+		return formats
+	}
+	addresses.Range(func(_ string, info *types.AddressInfo) bool {
+		for _, addr := range []string{info.Short, info.Robust, info.EthAddress} {
+			if addr != "" {
+				formats[addr] = info
+			}
+		}
+		return true
+	})
+	return formats
+}
+
+// addressTags returns the tags of the address, trying its other known formats if it has none
+func (p *FilecoinParser) addressTags(ctx context.Context, addr string, formats map[string]*types.AddressInfo) []string {
+	if tags := p.tagger.TagAddress(ctx, addr); len(tags) > 0 {
+		return tags
+	}
+
+	info, ok := formats[addr]
+	if !ok {
+		return nil
+	}
+	for _, other := range []string{info.Short, info.Robust, info.EthAddress} {
+		if other == "" || other == addr {
+			continue
+		}
+		if tags := p.tagger.TagAddress(ctx, other); len(tags) > 0 {
+			return tags
+		}
+	}
+	return nil
+}
+
+// setInputHashes reports the hashes of the inputs and, if TxInputProvenance is enabled, sets them on every tx
+func (p *FilecoinParser) setInputHashes(parsedResult *types.TxsParsedResult, hashes types.InputHashes) {
+	parsedResult.Report.InputHashes = hashes
+	if !p.Helper.GetConfig().TxInputProvenance {
+		return
+	}
+
+	combined := hashes.Combined()
+	for _, tx := range parsedResult.Txs {
+		tx.InputHash = combined
+	}
+}
+
+// invalidateDeletedActors evicts from the actors cache the code of the actors deleted by the txs, see
+// IsActorDeletion. Their addresses are kept, so the txs of other heights still resolve them.
+func (p *FilecoinParser) invalidateDeletedActors(txs []*types.Transaction) {
+	okStatus := parser.GetExitCodeStatus(0)
+	for _, tx := range txs {
+		if tx.Status != okStatus || !parser.IsActorDeletion(tx.TxType) {
+			continue
+		}
+
+		addr, err := address.NewFromString(tx.TxTo)
+		if err != nil {
+			p.logger.Sugar().Errorf("could not parse address of deleted actor %s: %s", tx.TxTo, err)
+			continue
+		}
+		p.Helper.GetActorsCache().InvalidateActorCode(addr)
+	}
+}
+
+// setEscrowChanges reports the changes of the storage market escrow made by the txs, if enabled
+func (p *FilecoinParser) setEscrowChanges(parsedResult *types.TxsParsedResult) {
+	if !p.Helper.GetConfig().IsFeatureEnabled(parser.FeatureMarketEscrow) {
+		return
+	}
+	parsedResult.EscrowChanges = parser.ExtractEscrowChanges(parsedResult.Txs)
+}
+
+// setBlockInclusions reports the blocks that included each message, if enabled
+func (p *FilecoinParser) setBlockInclusions(parsedResult *types.TxsParsedResult, tipset *types.ExtendedTipSet) {
+	if !p.Helper.GetConfig().IsFeatureEnabled(parser.FeatureBlockInclusions) {
+		return
+	}
+	parsedResult.BlockInclusions = parser.BlockInclusions(parsedResult.Txs, tipset)
+}
+
+// setAccountPromotions reports the placeholders promoted to EthAccounts by the txs, if enabled. Their cached
+// actor code is evicted, so the following tipsets see them as accounts.
+func (p *FilecoinParser) setAccountPromotions(ctx context.Context, parsedResult *types.TxsParsedResult, tipset *types.ExtendedTipSet) {
+	if !p.Helper.GetConfig().IsFeatureEnabled(parser.FeatureAccountPromotions) || tipset == nil {
+		return
+	}
+	node := p.Helper.GetFilecoinNodeClient()
+	if node == nil {
+		return
+	}
+
+	var ethAccountCode string
+	for _, tx := range parser.AccountPromotionCandidates(parsedResult.Txs) {
+		addr, err := address.NewFromString(tx.TxFrom)
+		if err != nil {
+			continue
+		}
+		// the state of the tipset is the one before applying its messages
+		actor, err := node.StateGetActor(ctx, addr, tipset.Key())
+		if err != nil {
+			p.logger.Sugar().Errorf("could not get actor %s to check its promotion: %s", tx.TxFrom, err)
+			continue
+		}
+		actorName, err := p.Helper.GetFilecoinLib().BuiltinActors.GetActorNameFromCid(actor.Code)
+		if err != nil || actorName != manifest.PlaceholderKey {
+			continue
+		}
+
+		if ethAccountCode == "" {
+			ethAccountCode = p.ethAccountCode(ctx, tipset.Key())
+		}
+		promotion := &types.AccountPromotion{
+			Height:    uint64(tipset.Height()),
+			TipsetCid: tipset.GetCidString(),
+			Address:   tx.TxFrom,
+			ActorCode: ethAccountCode,
+			TxCid:     tx.TxCid,
+		}
+		if ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(addr); err == nil {
+			promotion.EthAddress = p.Helper.GetConfig().FormatEthAddress(ethAddr)
+		}
+		if id, err := node.StateLookupID(ctx, addr, tipset.Key()); err == nil {
+			promotion.ActorId = id.String()
+		}
+		parsedResult.AccountPromotions = append(parsedResult.AccountPromotions, promotion)
+		p.Helper.GetActorsCache().InvalidateActorCode(addr)
+	}
+}
+
+// ethAccountCode returns the code cid of the EthAccount actor in the network version of the tipset
+func (p *FilecoinParser) ethAccountCode(ctx context.Context, key types2.TipSetKey) string {
+	node := p.Helper.GetFilecoinNodeClient()
+	version, err := node.StateNetworkVersion(ctx, key)
+	if err != nil {
+		p.logger.Sugar().Errorf("could not get network version: %s", err)
+		return ""
+	}
+	codes, err := node.StateActorCodeCIDs(ctx, version)
+	if err != nil {
+		p.logger.Sugar().Errorf("could not get actor codes of network version %d: %s", version, err)
+		return ""
+	}
+	code, ok := codes[manifest.EthAccountKey]
+	if !ok {
+		return ""
+	}
+	return code.String()
+}
+
+// setBuildInfo stamps the report, and the txs if StampBuildInfo is enabled, with the fil-parser build
+func (p *FilecoinParser) setBuildInfo(parsedResult *types.TxsParsedResult) {
+	build := parser.GetBuildInfo()
+	parsedResult.Report.Build = build
+	if !p.Helper.GetConfig().StampBuildInfo {
+		return
+	}
+
+	for _, tx := range parsedResult.Txs {
+		tx.ParserBuild = build.String()
+	}
+}
+
+func (p *FilecoinParser) detectAnomalies(parsedResult *types.TxsParsedResult, tipset *types.ExtendedTipSet) {
+	if !p.Helper.GetConfig().DetectAnomalies {
+		return
+	}
+
+	blocks, height := 0, int64(0)
+	if tipset != nil {
+		blocks, height = len(tipset.Blocks()), int64(tipset.Height())
+	}
+	parsedResult.Report.Anomalies = parser.DetectAnomalies(parsedResult.Txs, blocks)
+	if len(parsedResult.Report.Anomalies) > 0 {
+		p.logger.Sugar().Warnf("[parser] - %d anomalies found in height %d", len(parsedResult.Report.Anomalies), height)
+	}
+}
+
+// checkInvariants reports the chain economics invariants that do not hold for the tipset, if enabled
+func (p *FilecoinParser) checkInvariants(parsedResult *types.TxsParsedResult, tipset *types.ExtendedTipSet) {
+	if !p.Helper.GetConfig().CheckInvariants {
+		return
+	}
+
+	height := int64(0)
+	if tipset != nil {
+		height = int64(tipset.Height())
+	}
+	// without a tipset the base fee burn is not checked
+	parentBaseFee, _ := parser.GetParentBaseFee(tipset, zap.NewNop())
+	parsedResult.Report.InvariantViolations = parser.CheckInvariants(parsedResult.Txs, parsedResult.Addresses, parentBaseFee)
+	if len(parsedResult.Report.InvariantViolations) > 0 {
+		p.logger.Sugar().Warnf("[parser] - %d invariant violations found in height %d", len(parsedResult.Report.InvariantViolations), height)
+	}
+}
+
+// validateAddresses reports the inconsistent address info entries against the state of the parsed tipset, if enabled.
+// The entries are kept, as a lookup failing at the node is not a proof of the entry being wrong. The validation runs
+// within the AddressValidationTimeout budget; in best effort mode, running out of it leaves the remaining entries
+// not validated instead of failing.
+func (p *FilecoinParser) validateAddresses(ctx context.Context, parsedResult *types.TxsParsedResult, tipset *types.ExtendedTipSet) error {
+	config := p.Helper.GetConfig()
+	if !config.ValidateAddresses || parsedResult.Addresses == nil {
+		return nil
+	}
+
+	ctx, cancel := parser.WithStageTimeout(ctx, config.AddressValidationTimeout)
+	defer cancel()
+
+	key := types2.EmptyTSK
+	if tipset != nil {
+		key = tipset.Key()
+	}
+	violations, err := p.Helper.ValidateAddresses(ctx, parsedResult.Addresses, key)
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		if config.StageTimeoutHandling != parser.StageTimeoutBestEffort {
+			return fmt.Errorf("%w: %s exceeded %s: %w", ErrStageTimeout, StepConsolidateAddresses, config.AddressValidationTimeout, err)
+		}
+		p.logger.Sugar().Warnf("[parser] - %s exceeded %s, the remaining addresses are not validated", StepConsolidateAddresses,
+			config.AddressValidationTimeout)
+		parsedResult.Report.TimedOutSteps = append(parsedResult.Report.TimedOutSteps, StepConsolidateAddresses)
+	}
+
+	parsedResult.Report.AddressViolations = violations
+
+	if len(violations) > 0 && tipset != nil {
+		p.logger.Sugar().Warnf("[parser] - %d address violations found in height %d", len(violations), tipset.Height())
+	}
+	return nil
+}
+
+// checkAddressLookups handles the AddressLookupTimeout budget running out while the txs were parsed by the step: the
+// height fails or, in best effort mode, the step is reported as timed out and the addresses not looked up are missing
+func (p *FilecoinParser) checkAddressLookups(parsedResult *types.TxsParsedResult, step string) error {
+	if !p.Helper.AddressLookupsExceeded() {
+		return nil
+	}
+	config := p.Helper.GetConfig()
+	if config.StageTimeoutHandling != parser.StageTimeoutBestEffort {
+		return fmt.Errorf("%w: the address lookups of %s exceeded %s", ErrStageTimeout, step, config.AddressLookupTimeout)
+	}
+	p.logger.Sugar().Warnf("[parser] - the address lookups of %s exceeded %s, the remaining addresses are not looked up", step,
+		config.AddressLookupTimeout)
+	parsedResult.Report.TimedOutSteps = append(parsedResult.Report.TimedOutSteps, step)
+	return nil
+}
+
+// compressMetadata compresses the metadata of the txs over the configured threshold, if enabled
+func (p *FilecoinParser) compressMetadata(txs []*types.Transaction) {
+	config := p.Helper.GetConfig()
+	if !config.CompressMetadata {
+		return
+	}
+
+	threshold := config.GetMetadataCompressionThreshold()
+	for _, tx := range txs {
+		if len(tx.TxMetadata) > threshold {
+			tx.CompressMetadata()
+		}
+	}
+}
+
 func (p *FilecoinParser) ParseNativeEvents(ctx context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error) {
 	parserVersion, err := p.translateParserVersionFromMetadata(eventsData.Metadata)
 	if err != nil {
 		return nil, errUnknownVersion
 	}
 
+	ctx, span := p.startSpan(ctx, parser.SpanParseNativeEvents, eventsData.Tipset, parserVersion)
+	defer span.End()
+
 	var parsedResult *types.EventsParsedResult
 
 	p.logger.Sugar().Debugf("trace files node version: [%s] - parser to use: [%s]", eventsData.Metadata.NodeMajorMinorVersion, parserVersion)
@@ -115,6 +736,7 @@ func (p *FilecoinParser) ParseNativeEvents(ctx context.Context, eventsData types
 	}
 
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
@@ -127,6 +749,9 @@ func (p *FilecoinParser) ParseEthLogs(ctx context.Context, eventsData types.Even
 		return nil, errUnknownVersion
 	}
 
+	ctx, span := p.startSpan(ctx, parser.SpanParseEthLogs, eventsData.Tipset, parserVersion)
+	defer span.End()
+
 	var parsedResult *types.EventsParsedResult
 
 	p.logger.Sugar().Debugf("trace files node version: [%s] - parser to use: [%s]", eventsData.Metadata.NodeMajorMinorVersion, parserVersion)
@@ -139,12 +764,52 @@ func (p *FilecoinParser) ParseEthLogs(ctx context.Context, eventsData types.Even
 	}
 
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
 	return parsedResult, nil
 }
 
+// ParseEthTraces parses the eth traces of a tipset, in the layout of the trace_block eth api, into txs. It is
+// meant for EVM-only parsing of archived FEVM data: only the EVM calls are returned, without fees nor native calls.
+func (p *FilecoinParser) ParseEthTraces(ctx context.Context, ethTracesData types.EthTracesData) (*types.TxsParsedResult, error) {
+	parserVersion, err := p.translateParserVersionFromMetadata(ethTracesData.Metadata)
+	if err != nil {
+		return nil, errUnknownVersion
+	}
+
+	ctx, span := p.startSpan(ctx, parser.SpanParseEthTraces, ethTracesData.Tipset, parserVersion)
+	defer span.End()
+
+	// the eth traces layout does not depend on the node version, so the latest parser is used
+	var impl Parser
+	switch parserVersion {
+	case v1.Version, v2.Version:
+		impl = p.parserV2
+	}
+	ethTracesParser, ok := impl.(EthTracesParser)
+	if !ok {
+		p.logger.Sugar().Errorf("[parser] implementation not supported: %s", parserVersion)
+		return nil, errUnknownImpl
+	}
+
+	parsedResult, err := ethTracesParser.ParseEthTraces(ctx, ethTracesData)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	skippedTraces := parsedResult.Report.SkippedTraces
+	parsedResult.Txs, parsedResult.Report = p.filterDuplicated(parsedResult.Txs)
+	p.reportSkippedTraces(parsedResult, skippedTraces, ethTracesData.Tipset)
+	p.linkTxHierarchy(parsedResult.Txs)
+	parser.FormatAmounts(parsedResult.Txs, p.Helper.GetConfig().AmountFormat)
+	p.setBuildInfo(parsedResult)
+
+	return parsedResult, nil
+}
+
 func (p *FilecoinParser) ParseMultisigEvents(ctx context.Context, txs []*types.Transaction, tipsetCid string, tipsetKey types2.TipSetKey) (*types.MultisigEvents, error) {
 	multisigTxs, err := p.Helper.FilterTxsByActorType(ctx, txs, manifest.MultisigKey, tipsetKey)
 	if err != nil {
@@ -155,6 +820,9 @@ func (p *FilecoinParser) ParseMultisigEvents(ctx context.Context, txs []*types.T
 
 func (p *FilecoinParser) translateParserVersionFromMetadata(metadata types.BlockMetadata) (string, error) {
 	switch {
+	// Forest nodes use their own versioning scheme, but their traces follow the v2 format
+	case metadata.IsForest():
+		return v2.Version, nil
 	// The empty string is for backwards compatibility with older traces versions
 	case p.parserV1.IsNodeVersionSupported(metadata.NodeMajorMinorVersion), metadata.NodeMajorMinorVersion == "":
 		return v1.Version, nil
@@ -166,59 +834,256 @@ func (p *FilecoinParser) translateParserVersionFromMetadata(metadata types.Block
 	}
 }
 
+// tracesParserVersion returns the parser version for the traces. By default, traces whose layout does not match
+// the node version of their metadata are parsed with the parser matching their layout; see TraceVersionMismatch.
+func (p *FilecoinParser) tracesParserVersion(metadata types.BlockMetadata, traces []byte) (string, error) {
+	parserVersion, err := p.translateParserVersionFromMetadata(metadata)
+	if err != nil {
+		return "", errUnknownVersion
+	}
+
+	handling := p.Helper.GetConfig().TraceVersionMismatch
+	if metadata.IsForest() || handling == parser.TraceVersionMismatchIgnore {
+		return parserVersion, nil
+	}
+
+	detected := parser.DetectTraceFormat(traces)
+	if detected == "" || detected == parserVersion {
+		return parserVersion, nil
+	}
+
+	mismatch := &parser.VersionMismatchError{NodeVersion: metadata.NodeMajorMinorVersion, ExpectedFormat: parserVersion, DetectedFormat: detected}
+	if handling == parser.TraceVersionMismatchError {
+		return "", mismatch
+	}
+
+	p.logger.Sugar().Warnf("[parser] %s, parsing them with parser %s", mismatch, detected)
+	return detected, nil
+}
+
 func (p *FilecoinParser) FilterDuplicated(txs []*types.Transaction) []*types.Transaction {
-	idsFound := make(map[string]bool)
+	filteredTxs, _ := p.filterDuplicated(txs)
+	return filteredTxs
+}
+
+// reconcileEthLogs reports how the eth logs of the tipset match the parsed messages, if any eth log was provided
+func (p *FilecoinParser) reconcileEthLogs(parsedResult *types.TxsParsedResult, ethLogs []types.EthLog, tipset *types.ExtendedTipSet) {
+	if len(ethLogs) == 0 {
+		return
+	}
+
+	report := parser.ReconcileEthLogs(parsedResult.Txs, parsedResult.TxCids, ethLogs)
+	parsedResult.Report.EthLogs = report
+	if len(report.Unmatched) == 0 && len(report.Duplicates) == 0 {
+		return
+	}
+
+	var height int64
+	if tipset != nil {
+		height = int64(tipset.Height())
+	}
+	p.logger.Sugar().Warnf("[parser] - eth logs of height %d do not reconcile: %d logs, %d matched, %d unmatched, %d duplicated",
+		height, report.Logs, report.Matched, len(report.Unmatched), len(report.Duplicates))
+}
+
+// reconcileEthReceipts sets the effective gas price of the eth receipts of the tipset on their messages and reports
+// how the receipts match the parsed messages, if any eth receipt was provided
+func (p *FilecoinParser) reconcileEthReceipts(parsedResult *types.TxsParsedResult, ethReceipts []types.EthReceipt, tipset *types.ExtendedTipSet) {
+	if len(ethReceipts) == 0 {
+		return
+	}
+
+	report := parser.ReconcileEthReceipts(parsedResult.Txs, parsedResult.TxCids, ethReceipts)
+	parsedResult.Report.EthReceipts = report
+	if len(report.Mismatches) == 0 && len(report.Duplicates) == 0 {
+		return
+	}
+
+	var height int64
+	if tipset != nil {
+		height = int64(tipset.Height())
+	}
+	p.logger.Sugar().Warnf("[parser] - eth receipts of height %d do not reconcile: %d receipts, %d matched, %d mismatches, %d duplicated",
+		height, report.Receipts, report.Matched, len(report.Mismatches), len(report.Duplicates))
+	for _, mismatch := range report.Mismatches {
+		if mismatch.Reason == types.EthReceiptMismatchStatus {
+			p.logger.Sugar().Warnf("[parser] - status of tx %s (%s) at height %d diverges from the node: %s",
+				mismatch.TxId, mismatch.TransactionCid, height, mismatch.Detail)
+		}
+	}
+}
+
+// reportSkippedTraces adds the traces skipped by the parser to the ones dropped as duplicated, and passes
+// them to the skipped trace hook, if any
+func (p *FilecoinParser) reportSkippedTraces(parsedResult *types.TxsParsedResult, skippedTraces []types.SkippedTrace, tipset *types.ExtendedTipSet) {
+	parsedResult.Report.SkippedTraces = append(skippedTraces, parsedResult.Report.SkippedTraces...)
+	if len(parsedResult.Report.SkippedTraces) == 0 {
+		return
+	}
+
+	var height uint64
+	if tipset != nil {
+		height = uint64(tipset.Height())
+	}
+	p.logger.Sugar().Debugf("[parser] - %d traces skipped in height %d", len(parsedResult.Report.SkippedTraces), height)
+	if p.skippedTraceHook != nil {
+		p.skippedTraceHook(height, parsedResult.Report.SkippedTraces)
+	}
+}
+
+// filterDuplicated drops the txs with a repeated id, flagging the kept copy and reporting the dropped ones.
+// Duplicated fees at level 0 are a known corner case of v1 traces (e.g. height 845259).
+func (p *FilecoinParser) filterDuplicated(txs []*types.Transaction) ([]*types.Transaction, types.ParseReport) {
+	var report types.ParseReport
+	idsFound := make(map[string]*types.Transaction)
 	filteredTxs := make([]*types.Transaction, 0)
 
 	for _, tx := range txs {
-		if _, found := idsFound[tx.Id]; !found {
-			idsFound[tx.Id] = true
+		kept, found := idsFound[tx.Id]
+		if !found {
+			idsFound[tx.Id] = tx
 			filteredTxs = append(filteredTxs, tx)
+			continue
+		}
+
+		diagnostic := reportDuplicated(&report, tx)
+		if !slices.Contains(kept.Diagnostics, diagnostic) {
+			kept.Diagnostics = append(kept.Diagnostics, diagnostic)
 		}
 	}
 
+	return filteredTxs, report
+}
+
+// filterStreamDuplicated filters the duplicates of the txs of a trace like filterDuplicated, and also drops the txs
+// with the id of a tx emitted by a previous trace. Only the ids of the emitted txs are kept, so the emitted copy is
+// not flagged. The dropped txs are added to the report.
+func (p *FilecoinParser) filterStreamDuplicated(txs []*types.Transaction, emitted map[string]struct{}, report *types.ParseReport) []*types.Transaction {
+	txs, traceReport := p.filterDuplicated(txs)
+	report.DuplicatedTxs += traceReport.DuplicatedTxs
+	report.DuplicatedFees += traceReport.DuplicatedFees
+	report.SkippedTraces = append(report.SkippedTraces, traceReport.SkippedTraces...)
+
+	filteredTxs := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if _, found := emitted[tx.Id]; found {
+			reportDuplicated(report, tx)
+			continue
+		}
+		emitted[tx.Id] = struct{}{}
+		filteredTxs = append(filteredTxs, tx)
+	}
 	return filteredTxs
 }
 
+// linkTxHierarchy sets IsInternal and RootId on the txs if FeatureTxHierarchy is enabled
+func (p *FilecoinParser) linkTxHierarchy(txs []*types.Transaction) {
+	if !p.Helper.GetConfig().IsFeatureEnabled(parser.FeatureTxHierarchy) {
+		return
+	}
+	parser.LinkTxHierarchy(txs)
+}
+
+// reportDuplicated adds the dropped duplicated tx to the report and returns the diagnostic of the kept copy
+func reportDuplicated(report *types.ParseReport, tx *types.Transaction) string {
+	report.DuplicatedTxs++
+	report.SkippedTraces = append(report.SkippedTraces, types.SkippedTrace{
+		ExecutionIndex: tx.ExecutionIndex,
+		TxCid:          tx.TxCid,
+		Path:           strings.TrimPrefix(tx.InternalTxId, tx.TxCid+":"),
+		Reason:         types.SkipReasonDuplicated,
+		Detail:         fmt.Sprintf("%s tx %s", tx.TxType, tx.Id),
+	})
+	if tx.Level == 0 && tx.TxType == parser.TotalFeeOp {
+		report.DuplicatedFees++
+		return types.DiagnosticDuplicatedFee
+	}
+	return types.DiagnosticDuplicatedTx
+}
+
+// GetMultisigState reads the signers, threshold and pending transactions of a multisig at the tipset
+func (p *FilecoinParser) GetMultisigState(ctx context.Context, addr address.Address, tipset *types.ExtendedTipSet) (*types.MultisigState, error) {
+	return multisigTools.GetMultisigState(ctx, p.Helper, addr, tipset)
+}
+
+// GetVerifregAllocations reads the pending DataCap allocations made by a client at the tipset
+func (p *FilecoinParser) GetVerifregAllocations(ctx context.Context, client address.Address, tipset *types.ExtendedTipSet) (*types.VerifregAllocations, error) {
+	return verifregTools.GetAllocations(ctx, p.Helper, client, tipset)
+}
+
+// GetVerifregClaims reads the DataCap claims of a provider at the tipset
+func (p *FilecoinParser) GetVerifregClaims(ctx context.Context, provider address.Address, tipset *types.ExtendedTipSet) (*types.VerifregClaims, error) {
+	return verifregTools.GetClaims(ctx, p.Helper, provider, tipset)
+}
+
+// GetBaseFee returns the base fee of the tipset, or types.ErrBaseFeeOverflow if it does not fit in an uint64.
+//
+// Deprecated: use GetBaseFeeWithSource, which returns the whole base fee
 func (p *FilecoinParser) GetBaseFee(traces []byte, metadata types.BlockMetadata, tipset *types.ExtendedTipSet) (uint64, error) {
-	parserVersion, err := p.translateParserVersionFromMetadata(metadata)
+	baseFee, err := p.GetBaseFeeWithSource(traces, metadata, tipset)
 	if err != nil {
-		return 0, errUnknownVersion
+		return baseFee.Value, err
+	}
+	return baseFee.Uint64()
+}
+
+// GetBaseFeeWithSource returns the base fee of the tipset along with how it was derived, so fallback
+// values can be told apart from the ones derived from the traces. The source is empty if the parser of the
+// traces does not implement BaseFeeParser.
+func (p *FilecoinParser) GetBaseFeeWithSource(traces []byte, metadata types.BlockMetadata, tipset *types.ExtendedTipSet) (types.BaseFee, error) {
+	parserVersion, err := p.tracesParserVersion(metadata, traces)
+	if err != nil {
+		return types.BaseFee{}, err
 	}
 
 	p.logger.Sugar().Debugf("trace files node version: [%s] - parser to use: [%s]", metadata.NodeMajorMinorVersion, parserVersion)
+	var impl Parser
 	switch parserVersion {
 	case v1.Version:
-		return p.parserV1.GetBaseFee(traces, tipset)
+		impl = p.parserV1
 	case v2.Version:
-		return p.parserV2.GetBaseFee(traces, tipset)
+		impl = p.parserV2
+	default:
+		return types.BaseFee{}, errUnknownImpl
 	}
 
-	return 0, errUnknownImpl
+	if baseFeeParser, ok := impl.(BaseFeeParser); ok {
+		return baseFeeParser.GetBaseFeeWithSource(traces, tipset)
+	}
+	value, err := impl.GetBaseFee(traces, tipset)
+	return types.NewBaseFee(new(big.Int).SetUint64(value), ""), err
 }
 
+// ParseGenesis returns a Genesis tx for every actor funded at genesis, along with the address info of all the
+// genesis actors. Their addresses are resolved at the genesis tipset. The address info is keyed by the address of
+// the genesis balance, as in previous versions.
 func (p *FilecoinParser) ParseGenesis(genesis *types.GenesisBalances, genesisTipset *types.ExtendedTipSet) ([]*types.Transaction, *types.AddressInfoMap) {
+	genesisTxs, addresses, _ := p.ParseGenesisWithContext(context.Background(), genesis, genesisTipset)
+	return genesisTxs, addresses
+}
+
+// ParseGenesisWithContext is ParseGenesis, failing with the context error once the context is done instead of
+// resolving the rest of the genesis actors
+func (p *FilecoinParser) ParseGenesisWithContext(ctx context.Context, genesis *types.GenesisBalances, genesisTipset *types.ExtendedTipSet) ([]*types.Transaction, *types.AddressInfoMap, error) {
 	genesisTxs := make([]*types.Transaction, 0)
 	addresses := types.NewAddressInfoMap()
 	genesisTimestamp := parser.GetTimestamp(genesisTipset.MinTimestamp())
+	ids := tools.NewIdBuilder(p.Helper.GetConfig().IdHashScheme)
 
 	for _, balance := range genesis.Actors.All {
-		if balance.Value.Balance == "0" {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		filAdd, err := address.NewFromString(balance.Key)
+		if err != nil {
+			p.logger.Sugar().Errorf("could not parse genesis address %s: %v", balance.Key, err)
 			continue
 		}
+		addresses.Set(balance.Key, p.genesisAddressInfo(ctx, filAdd, genesisTipset))
 
-		filAdd, _ := address.NewFromString(balance.Key)
-		shortAdd, _ := p.Helper.GetActorsCache().GetShortAddress(filAdd)
-		robustAdd, _ := p.Helper.GetActorsCache().GetRobustAddress(filAdd)
-		actorCode, _ := p.Helper.GetActorsCache().GetActorCode(filAdd, types2.EmptyTSK, false)
-		actorName, _ := p.Helper.GetActorNameFromAddress(filAdd, 0, types2.EmptyTSK)
-
-		addresses.Set(balance.Key, &types.AddressInfo{
-			Short:     shortAdd,
-			Robust:    robustAdd,
-			ActorCid:  actorCode,
-			ActorType: actorName,
-		})
+		if balance.Value.Balance == "0" {
+			continue
+		}
 		amount, _ := big.FromString(balance.Value.Balance)
 
 		tipsetCid := genesisTipset.GetCidString()
@@ -234,7 +1099,7 @@ func (p *FilecoinParser) ParseGenesis(genesis *types.GenesisBalances, genesisTip
 				},
 				BlockCid: blockCid,
 			},
-			Id:          tools.BuildId(genesisTipset.Key().String(), balance.Key, balance.Value.Balance),
+			Id:          ids.BuildId(genesisTipset.Key().String(), balance.Key, balance.Value.Balance),
 			ParentId:    uuid.Nil.String(),
 			Level:       0,
 			TxTimestamp: genesisTimestamp,
@@ -246,11 +1111,26 @@ func (p *FilecoinParser) ParseGenesis(genesis *types.GenesisBalances, genesisTip
 		})
 	}
 
-	return genesisTxs, addresses
+	return genesisTxs, addresses, nil
+}
+
+// genesisAddressInfo resolves the address info of a genesis actor at the genesis tipset. Actors without a
+// robust address (miners, multisigs and system actors) are only known by their short address. The short address
+// of a robust address that can not be resolved is left empty.
+func (p *FilecoinParser) genesisAddressInfo(ctx context.Context, add address.Address, genesisTipset *types.ExtendedTipSet) *types.AddressInfo {
+	info := p.Helper.GetActorAddressInfoWithContext(ctx, add, genesisTipset.Key())
+	if info.Short == "" && add.Protocol() == address.ID {
+		info.Short = add.String()
+	}
+	if info.Robust == "" && add.Protocol() != address.ID {
+		info.Robust = add.String()
+	}
+	return info
 }
 
 func (p *FilecoinParser) ParseGenesisMultisig(ctx context.Context, genesis *types.GenesisBalances, genesisTipset *types.ExtendedTipSet) ([]*types.MultisigInfo, error) {
 	var multisigInfos []*types.MultisigInfo
+	ids := tools.NewIdBuilder(p.Helper.GetConfig().IdHashScheme)
 	for _, actor := range genesis.Actors.All {
 		addrStr := actor.Key
 		// parse address
@@ -261,7 +1141,10 @@ func (p *FilecoinParser) ParseGenesisMultisig(ctx context.Context, genesis *type
 		}
 
 		// get actor name from address
-		actorName, err := p.Helper.GetActorNameFromAddress(addr, int64(parser.GenesisHeight), genesisTipset.Key())
+		actorName, err := p.Helper.GetActorNameFromAddressWithContext(ctx, addr, int64(parser.GenesisHeight), genesisTipset.Key())
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
 			p.logger.Sugar().Errorf("could not get actor name from address: %s. err: %s", addrStr, err)
 			continue
@@ -284,7 +1167,7 @@ func (p *FilecoinParser) ParseGenesisMultisig(ctx context.Context, genesis *type
 		}
 
 		multisigInfo := &types.MultisigInfo{
-			ID:              tools.BuildId(genesisTipset.GetCidString(), addrStr, fmt.Sprint(parser.GenesisHeight), "", parser.TxTypeGenesis),
+			ID:              ids.BuildId(genesisTipset.GetCidString(), addrStr, fmt.Sprint(parser.GenesisHeight), "", parser.TxTypeGenesis),
 			MultisigAddress: addrStr,
 			Height:          parser.GenesisHeight,
 			ActionType:      parser.MultisigConstructorMethod,
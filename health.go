@@ -0,0 +1,76 @@
+package fil_parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/builtin"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+)
+
+const (
+	HealthComponentNode     = "node"
+	HealthComponentCache    = "cache"
+	HealthComponentResolver = "address_resolver"
+
+	HealthStatusOk    = "ok"
+	HealthStatusError = "error"
+)
+
+// ComponentHealth is the status of one of the dependencies of the parser
+type ComponentHealth struct {
+	Name    string        `json:"name"`
+	Status  string        `json:"status"`
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+// HealthStatus is the status of all the dependencies of the parser
+type HealthStatus struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentHealth `json:"components"`
+	// Capabilities are the apis supported by the node, see ProbeNodeCapabilities. They are only set if the node is
+	// reachable; an unavailable api does not make the parser unhealthy.
+	Capabilities *NodeCapabilities `json:"capabilities,omitempty"`
+}
+
+// Health checks the node, probing its capabilities, the cache store and that a known address (the init actor)
+// can be resolved. It can be used as a readiness probe of the services embedding the parser.
+func (p *FilecoinParser) Health(ctx context.Context) HealthStatus {
+	status := HealthStatus{Healthy: true}
+	checks := []struct {
+		name  string
+		check func(ctx context.Context) error
+	}{
+		{name: HealthComponentNode, check: func(ctx context.Context) error {
+			capabilities, err := p.ProbeNodeCapabilities(ctx)
+			status.Capabilities = capabilities
+			return err
+		}},
+		{name: HealthComponentCache, check: p.Helper.GetActorsCache().HealthCheck},
+		{name: HealthComponentResolver, check: p.checkAddressResolver},
+	}
+
+	for _, c := range checks {
+		start := time.Now()
+		err := c.check(ctx)
+		component := ComponentHealth{Name: c.name, Status: HealthStatusOk, Latency: time.Since(start)}
+		if err != nil {
+			p.logger.Sugar().Errorf("health check of %s failed: %v", c.name, err)
+			component.Status = HealthStatusError
+			component.Error = err.Error()
+			status.Healthy = false
+		}
+		status.Components = append(status.Components, component)
+	}
+
+	return status
+}
+
+func (p *FilecoinParser) checkAddressResolver(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := p.Helper.GetActorsCache().GetActorCodeWithContext(ctx, builtin.InitActorAddr, filTypes.EmptyTSK, false)
+	return err
+}
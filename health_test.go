@@ -0,0 +1,56 @@
+package fil_parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/mocks"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/golang/mock/gomock"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+)
+
+func TestFilecoinParser_Health(t *testing.T) {
+	initActorCode, err := cid.Decode("bafk2bzaceaadogg765rwdh5bhs4gjpspnongfrcjkvzy6ta2rtokrdh4hfdqm")
+	require.NoError(t, err)
+
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	p, err := NewFilecoinParser(nil, common.DataSource{Node: node}, nil)
+	require.NoError(t, err)
+
+	node.EXPECT().Version(gomock.Any()).Return(api.APIVersion{Version: "1.31.0+mainnet+git.3f7c1ea", APIVersion: api.FullAPIVersion1}, nil)
+	node.EXPECT().EthChainId(gomock.Any()).Return(ethtypes.EthUint64(314), nil)
+	node.EXPECT().EthTraceBlock(gomock.Any(), "latest").Return(nil, nil)
+	node.EXPECT().EthGetBlockReceipts(gomock.Any(), gomock.Any()).Return(nil, errors.New("method 'Filecoin.EthGetBlockReceipts' not found"))
+	node.EXPECT().GetActorEventsRaw(gomock.Any(), gomock.Any()).Return(nil, nil)
+	node.EXPECT().StateGetActor(gomock.Any(), builtin.InitActorAddr, gomock.Any()).Return(&filTypes.Actor{Code: initActorCode}, nil)
+
+	status := p.Health(context.Background())
+	require.True(t, status.Healthy)
+	require.Len(t, status.Components, 3)
+	for _, component := range status.Components {
+		require.Equal(t, HealthStatusOk, component.Status, component.Name)
+	}
+	// a missing api is reported, but the parser is still healthy
+	require.NotNil(t, status.Capabilities)
+	require.Equal(t, "v1.31", status.Capabilities.NodeMajorMinorVersion)
+	require.True(t, status.Capabilities.Supports(CapabilityEthTraces))
+	require.False(t, status.Capabilities.Supports(CapabilityEthReceipts))
+
+	// the actor code is cached now, only the node is down
+	node.EXPECT().Version(gomock.Any()).Return(api.APIVersion{}, errors.New("connection refused"))
+	status = p.Health(context.Background())
+	require.False(t, status.Healthy)
+	require.Equal(t, HealthComponentNode, status.Components[0].Name)
+	require.Equal(t, HealthStatusError, status.Components[0].Status)
+	require.Contains(t, status.Components[0].Error, "connection refused")
+	require.Nil(t, status.Capabilities)
+	require.Equal(t, HealthStatusOk, status.Components[1].Status)
+	require.Equal(t, HealthStatusOk, status.Components[2].Status)
+}
@@ -0,0 +1,113 @@
+package fil_parser
+
+import (
+	"time"
+
+	"github.com/zondax/fil-parser/actors/cache"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type FilecoinParserOptions struct {
+	config           parser.FilecoinParserConfig
+	tagger           parser.AddressTagger
+	contracts        parser.ContractMetadataProvider
+	resultsCache     ResultsCache
+	eventSchemas     *parser.EventSchemaRegistry
+	tracerProvider   trace.TracerProvider
+	skippedTraceHook SkippedTraceHook
+	offChainCache    cache.IActorsCache
+	kvOpThreshold    time.Duration
+	kvOpHook         cache.KvOpHook
+}
+
+type Option func(*FilecoinParserOptions)
+
+func newOptions(opts ...Option) *FilecoinParserOptions {
+	options := &FilecoinParserOptions{config: parser.DefaultConfig()}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithConfig sets the config used to enable the optional features of the parser
+func WithConfig(config parser.FilecoinParserConfig) Option {
+	return func(o *FilecoinParserOptions) {
+		o.config = config
+	}
+}
+
+// WithAddressTagger sets the tagger used to label the from and to addresses of the parsed txs
+func WithAddressTagger(tagger parser.AddressTagger) Option {
+	return func(o *FilecoinParserOptions) {
+		o.tagger = tagger
+	}
+}
+
+// WithContractMetadataProvider sets the provider of the verified contracts, whose metadata is added to the
+// metadata of the txs calling them
+func WithContractMetadataProvider(provider parser.ContractMetadataProvider) Option {
+	return func(o *FilecoinParserOptions) {
+		o.contracts = provider
+	}
+}
+
+// WithResultsCache sets the cache of the results of ParseTransactions, keyed by the hash of the traces, tipset,
+// eth logs and receipts, node metadata, parser version and config. Tipsets parsed again with the same inputs
+// return the stored output. Results are not cached when parsing with a custom pipeline. The cache must not be
+// shared with parsers using a different tagger, contract metadata provider or event schemas.
+func WithResultsCache(cache ResultsCache) Option {
+	return func(o *FilecoinParserOptions) {
+		o.resultsCache = cache
+	}
+}
+
+// WithEventSchemas sets the registry used to decode the native events of user actors into named fields.
+// Schemas can still be registered after the parser is created.
+func WithEventSchemas(schemas *parser.EventSchemaRegistry) Option {
+	return func(o *FilecoinParserOptions) {
+		o.eventSchemas = schemas
+	}
+}
+
+// WithTracerProvider enables the OpenTelemetry spans of the parser: one per parsed tipset, one per actor
+// decoding and one per actors cache lookup, tagged with the actor family and the cache tier that served it.
+// Tracing is disabled by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *FilecoinParserOptions) {
+		o.tracerProvider = provider
+	}
+}
+
+// WithActorsCache sets an already initialized off-chain actors cache, e.g. one shared with another service or a
+// custom distributed implementation, instead of the one NewFilecoinParser sets up from the cache source.
+// The node of the cache source is still used for the on-chain lookups. It is ignored by the parsers of a
+// ParserPool, which share the actors cache of the pool.
+func WithActorsCache(offChainCache cache.IActorsCache) Option {
+	return func(o *FilecoinParserOptions) {
+		o.offChainCache = offChainCache
+	}
+}
+
+// WithCacheKvOpHook sets a hook called for the operations of the off-chain actors cache that take longer than the
+// threshold or fail, with the operation and the address, see cache.ActorsCache.SetKvOpHook. It is ignored by the
+// parsers created with an already set up actors cache, e.g. the ones of a ParserPool: set it on that cache instead.
+func WithCacheKvOpHook(threshold time.Duration, hook cache.KvOpHook) Option {
+	return func(o *FilecoinParserOptions) {
+		o.kvOpThreshold = threshold
+		o.kvOpHook = hook
+	}
+}
+
+// SkippedTraceHook receives the traces of a height that did not produce any tx, see types.SkippedTrace
+type SkippedTraceHook func(height uint64, skipped []types.SkippedTrace)
+
+// WithSkippedTraceHook sets a hook called with the skipped traces of every parsed height that has any. The
+// skipped traces are always available in the parse report; the hook eases auditing them as they are found.
+func WithSkippedTraceHook(hook SkippedTraceHook) Option {
+	return func(o *FilecoinParserOptions) {
+		o.skippedTraceHook = hook
+	}
+}
@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/zondax/fil-parser/types"
+)
+
+// AccountPromotionCandidates returns the first message (level 0 tx) sent by each f410 address of the eth address
+// manager. The sender of these messages is promoted from placeholder to EthAccount if it was still a placeholder
+// before the tipset. The promotion happens before the message is applied, so failed messages are included.
+func AccountPromotionCandidates(txs []*types.Transaction) []*types.Transaction {
+	seen := make(map[string]bool)
+	candidates := make([]*types.Transaction, 0)
+	for _, tx := range txs {
+		if tx.Level != 0 || IsFee(tx.TxType) || tx.TxType == GasRefundOp || seen[tx.TxFrom] {
+			continue
+		}
+		if !IsEthAccountAddress(tx.TxFrom) {
+			continue
+		}
+		seen[tx.TxFrom] = true
+		candidates = append(candidates, tx)
+	}
+	return candidates
+}
+
+// IsEthAccountAddress returns whether the address is a f410 address of the eth address manager
+func IsEthAccountAddress(addr string) bool {
+	parsed, err := address.NewFromString(addr)
+	if err != nil || parsed.Protocol() != address.Delegated {
+		return false
+	}
+	// only the addresses of the eth address manager namespace convert to eth addresses
+	_, err = ethtypes.EthAddressFromFilecoinAddress(parsed)
+	return err == nil
+}
@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestAccountPromotionCandidates(t *testing.T) {
+	const (
+		ethSender   = "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"
+		otherSender = "f410fuiwj6a3yxajbohrl5vu6ns6o2e2jriul52lvzci"
+	)
+	txs := []*types.Transaction{
+		{Id: "a", TxFrom: ethSender, TxType: MethodInvokeContract},
+		{Id: "fee", TxFrom: ethSender, TxType: TotalFeeOp},
+		{Id: "sub", TxFrom: otherSender, TxType: MethodSend, Level: 1},
+		{Id: "b", TxFrom: ethSender, TxType: MethodSend},
+		{Id: "c", TxFrom: "f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea", TxType: MethodSend},
+		{Id: "d", TxFrom: otherSender, TxType: MethodInvokeContract, Status: "ErrForbidden"},
+	}
+
+	var ids []string
+	for _, tx := range AccountPromotionCandidates(txs) {
+		ids = append(ids, tx.Id)
+	}
+	require.Equal(t, []string{"a", "d"}, ids)
+}
+
+func TestIsEthAccountAddress(t *testing.T) {
+	require.True(t, IsEthAccountAddress("f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"))
+	require.False(t, IsEthAccountAddress("f01234"))
+	require.False(t, IsEthAccountAddress("f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea"))
+	require.False(t, IsEthAccountAddress("invalid"))
+}
@@ -0,0 +1,15 @@
+package parser
+
+import "slices"
+
+// ActorDeletionTxTypes are the tx types that delete the receiver actor when they succeed, e.g. a payment
+// channel is deleted once collected. EVM contracts calling SELFDESTRUCT are not included: the FVM only marks
+// them as dead, they keep their actor and their EVM code.
+var ActorDeletionTxTypes = []string{
+	MethodCollect,
+}
+
+// IsActorDeletion returns whether a successful tx of this type deletes the receiver actor
+func IsActorDeletion(txType string) bool {
+	return slices.Contains(ActorDeletionTxTypes, txType)
+}
@@ -0,0 +1,13 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsActorDeletion(t *testing.T) {
+	require.True(t, IsActorDeletion(MethodCollect))
+	require.False(t, IsActorDeletion(MethodSettle))
+	require.False(t, IsActorDeletion(MethodSend))
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/zondax/fil-parser/types"
+)
+
+// AddressProtocolUnknown is the Kind of the addresses of a protocol the parser does not know, see UnknownProtocolAddress
+const AddressProtocolUnknown = "unknown_protocol"
+
+// addressCandidate matches the json strings that may be addresses, so the ones of unknown protocols can be replaced
+var addressCandidate = regexp.MustCompile(`"[ft][0-9][a-z0-9]+"`)
+
+// UnknownProtocolAddress is an address of a protocol newer than the ones known by the parser (f0 to f4), kept as
+// found in the traces so it can be decoded once the protocol is supported
+type UnknownProtocolAddress struct {
+	Kind     string `json:"kind"`
+	Address  string `json:"address"`
+	Protocol uint8  `json:"protocol"`
+	// Payload is the hex encoded payload of the address, empty if it is not base32 encoded like the known protocols
+	Payload string `json:"payload,omitempty"`
+}
+
+// ParseUnknownProtocolAddress returns the address if its protocol is unknown. Addresses of known protocols and
+// strings that are not addresses are not returned.
+func ParseUnknownProtocolAddress(s string) (UnknownProtocolAddress, bool) {
+	if _, err := address.NewFromString(s); !errors.Is(err, address.ErrUnknownProtocol) || len(s) < 3 {
+		return UnknownProtocolAddress{}, false
+	}
+	unknown := UnknownProtocolAddress{Kind: AddressProtocolUnknown, Address: s, Protocol: s[1] - '0'}
+	if payload, err := address.AddressEncoding.WithPadding(-1).DecodeString(s[2:]); err == nil {
+		unknown.Payload = hex.EncodeToString(payload)
+	}
+	return unknown, true
+}
+
+// UnknownAddresses are the addresses of unknown protocols found in the traces of a tipset, by the placeholder
+// they were replaced with, see SanitizeUnknownAddresses
+type UnknownAddresses struct {
+	byPlaceholder map[string]UnknownProtocolAddress
+}
+
+// Len returns the amount of unknown addresses
+func (u *UnknownAddresses) Len() int {
+	if u == nil {
+		return 0
+	}
+	return len(u.byPlaceholder)
+}
+
+// SanitizeUnknownAddresses replaces the addresses of unknown protocols in the traces with placeholder ID addresses,
+// counting down from the max ID, so the traces can be decoded instead of failing. The placeholders are replaced
+// back by Restore once the txs are parsed. The traces are returned as they are, with nil UnknownAddresses, if
+// there are none.
+func SanitizeUnknownAddresses(traces []byte) ([]byte, *UnknownAddresses) {
+	if !hasUnknownProtocolPrefix(traces) {
+		return traces, nil
+	}
+
+	unknown := &UnknownAddresses{byPlaceholder: make(map[string]UnknownProtocolAddress)}
+	replacements := make(map[string]string)
+	for _, match := range addressCandidate.FindAll(traces, -1) {
+		candidate := string(match[1 : len(match)-1])
+		if _, ok := replacements[candidate]; ok {
+			continue
+		}
+		parsed, ok := ParseUnknownProtocolAddress(candidate)
+		if !ok {
+			continue
+		}
+		placeholder, err := address.NewIDAddress(uint64(math.MaxInt64 - len(replacements)))
+		if err != nil {
+			continue
+		}
+		replacements[candidate] = placeholder.String()
+		unknown.byPlaceholder[placeholder.String()] = parsed
+	}
+	if len(replacements) == 0 {
+		return traces, nil
+	}
+
+	sanitized := addressCandidate.ReplaceAllFunc(traces, func(match []byte) []byte {
+		if placeholder, ok := replacements[string(match[1:len(match)-1])]; ok {
+			return []byte(`"` + placeholder + `"`)
+		}
+		return match
+	})
+	return sanitized, unknown
+}
+
+// hasUnknownProtocolPrefix is a quick check of whether the traces may have an address of an unknown protocol, so
+// the traces are only scanned with a regexp when needed
+func hasUnknownProtocolPrefix(traces []byte) bool {
+	for _, network := range []byte{'f', 't'} {
+		for protocol := byte('5'); protocol <= '9'; protocol++ {
+			if bytes.Contains(traces, []byte{'"', network, protocol}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Restore replaces the placeholders in the parsed txs with the addresses of unknown protocols they stand for. The
+// txs with an unknown address are marked with their UnknownProtocolAddress under UnknownAddressesKey in the
+// metadata. The address info entries of the placeholders are dropped, as they can not be resolved.
+func (u *UnknownAddresses) Restore(txs []*types.Transaction, addresses *types.AddressInfoMap) error {
+	if u.Len() == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, tx := range txs {
+		var found []UnknownProtocolAddress
+		for placeholder, unknown := range u.byPlaceholder {
+			replaced := false
+			if tx.TxFrom == placeholder {
+				tx.TxFrom, replaced = unknown.Address, true
+			}
+			if tx.TxTo == placeholder {
+				tx.TxTo, replaced = unknown.Address, true
+			}
+			if strings.Contains(tx.TxMetadata, placeholder) {
+				tx.TxMetadata, replaced = strings.ReplaceAll(tx.TxMetadata, placeholder, unknown.Address), true
+			}
+			if replaced {
+				found = append(found, unknown)
+			}
+		}
+		if len(found) > 0 {
+			sort.Slice(found, func(i, j int) bool { return found[i].Address < found[j].Address })
+			if err := addUnknownAddressesMetadata(tx, found); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if addresses != nil {
+		for placeholder := range u.byPlaceholder {
+			addresses.Delete(placeholder)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func addUnknownAddressesMetadata(tx *types.Transaction, unknown []UnknownProtocolAddress) error {
+	return mergeTxMetadata(tx, UnknownAddressesKey, unknown)
+}
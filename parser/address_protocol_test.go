@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+// newUnknownProtocolAddress returns a synthetic address of a protocol that does not exist yet
+func newUnknownProtocolAddress(protocol byte, payload []byte) string {
+	return "f" + string('0'+protocol) + address.AddressEncoding.WithPadding(-1).EncodeToString(payload)
+}
+
+func TestParseUnknownProtocolAddress(t *testing.T) {
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	unknown, ok := ParseUnknownProtocolAddress(newUnknownProtocolAddress(5, payload))
+	require.True(t, ok)
+	require.Equal(t, AddressProtocolUnknown, unknown.Kind)
+	require.Equal(t, uint8(5), unknown.Protocol)
+	require.Equal(t, hex.EncodeToString(payload), unknown.Payload)
+
+	// the raw address is kept even if the payload is not base32
+	unknown, ok = ParseUnknownProtocolAddress("f9abc1")
+	require.True(t, ok)
+	require.Equal(t, "f9abc1", unknown.Address)
+	require.Empty(t, unknown.Payload)
+
+	for _, s := range []string{"f01234", "f410fabc", "x5abc", "", "f5"} {
+		_, ok = ParseUnknownProtocolAddress(s)
+		require.False(t, ok, s)
+	}
+}
+
+func TestSanitizeUnknownAddresses(t *testing.T) {
+	traces := []byte(`{"Trace":[{"From":"f01000","To":"f01001"}]}`)
+	sanitized, unknown := SanitizeUnknownAddresses(traces)
+	require.Equal(t, traces, sanitized)
+	require.Nil(t, unknown)
+
+	from := newUnknownProtocolAddress(5, []byte{1, 2, 3})
+	to := newUnknownProtocolAddress(7, []byte{4, 5, 6})
+	traces = []byte(`{"Trace":[{"From":"` + from + `","To":"f01001"},{"From":"` + from + `","To":"` + to + `"}]}`)
+	sanitized, unknown = SanitizeUnknownAddresses(traces)
+	require.Equal(t, 2, unknown.Len())
+
+	// the sanitized traces can be decoded
+	var decoded struct {
+		Trace []struct {
+			From address.Address
+			To   address.Address
+		}
+	}
+	require.NoError(t, json.Unmarshal(sanitized, &decoded))
+	require.Equal(t, decoded.Trace[0].From, decoded.Trace[1].From)
+	require.Equal(t, address.ID, decoded.Trace[1].To.Protocol())
+
+	txs := []*types.Transaction{
+		{Id: "a", TxFrom: decoded.Trace[0].From.String(), TxTo: "f01001", TxMetadata: `{"Params":{"To":"` + decoded.Trace[1].To.String() + `"}}`},
+		{Id: "b", TxFrom: "f01001", TxTo: "f01002"},
+	}
+	addresses := types.NewAddressInfoMap()
+	addresses.Set(decoded.Trace[0].From.String(), &types.AddressInfo{Short: decoded.Trace[0].From.String()})
+	addresses.Set("f01001", &types.AddressInfo{Short: "f01001"})
+	require.NoError(t, unknown.Restore(txs, addresses))
+
+	require.Equal(t, from, txs[0].TxFrom)
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(txs[0].TxMetadata), &metadata))
+	require.Equal(t, to, metadata["Params"].(map[string]interface{})["To"])
+	require.Len(t, metadata[UnknownAddressesKey], 2)
+	require.Empty(t, txs[1].TxMetadata, "txs without unknown addresses are not marked")
+	require.Equal(t, 1, addresses.Len())
+}
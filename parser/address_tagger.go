@@ -0,0 +1,32 @@
+package parser
+
+import "context"
+
+// Well known address tags. Taggers are free to return any other tag.
+const (
+	AddressTagExchange     = "exchange"
+	AddressTagBridge       = "bridge"
+	AddressTagBurn         = "burn"
+	AddressTagTeamMultisig = "team_multisig"
+)
+
+// AddressTagger labels the addresses found while parsing (e.g. exchanges or bridges), so the labels are
+// part of the parser output. It is called for every tx, so implementations must be fast and safe for concurrent use.
+type AddressTagger interface {
+	// TagAddress returns the tags of the address, if any. The address may be in any format (short, robust...).
+	TagAddress(ctx context.Context, address string) []string
+}
+
+// StaticAddressTagger tags the addresses from a fixed list
+type StaticAddressTagger struct {
+	tags map[string][]string
+}
+
+// NewStaticAddressTagger creates a tagger from a map of addresses to their tags
+func NewStaticAddressTagger(tags map[string][]string) *StaticAddressTagger {
+	return &StaticAddressTagger{tags: tags}
+}
+
+func (s *StaticAddressTagger) TagAddress(_ context.Context, address string) []string {
+	return s.tags[address]
+}
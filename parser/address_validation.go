@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	// AddressViolationInvalidShort flags entries whose short address is missing or is not an ID address
+	AddressViolationInvalidShort = "invalid_short"
+	// AddressViolationInvalidRobust flags entries whose robust address can not be parsed or is an ID address
+	// other than the short one (only system actors use their ID as robust address)
+	AddressViolationInvalidRobust = "invalid_robust"
+	// AddressViolationShortMismatch flags entries whose robust address resolves to another short address
+	AddressViolationShortMismatch = "short_mismatch"
+	// AddressViolationUnknownActorCode flags entries whose actor cid is not part of any known actors bundle
+	AddressViolationUnknownActorCode = "unknown_actor_code"
+	// AddressViolationActorTypeMismatch flags entries whose actor type is not the one of their actor cid
+	AddressViolationActorTypeMismatch = "actor_type_mismatch"
+	// AddressViolationWrongNetwork flags entries with addresses of another network (f vs t prefix)
+	AddressViolationWrongNetwork = "wrong_network"
+)
+
+// AddressResolvers are the lookups used to validate the address info entries against the chain
+type AddressResolvers struct {
+	// ShortAddress returns the ID address of the given address. Optional, the short addresses are not
+	// checked against the chain if it is nil.
+	ShortAddress func(addr address.Address) (string, error)
+	// ActorName returns the name of the actor of the given code, failing if it is not part of a known
+	// actors bundle. Optional, the actor cids are not checked if it is nil.
+	ActorName func(code cid.Cid) (string, error)
+}
+
+// ValidateAddressInfo checks that the entry is internally consistent and follows the rules of the network
+func ValidateAddressInfo(info *types.AddressInfo, network address.Network, resolvers AddressResolvers) []types.AddressViolation {
+	violations := make([]types.AddressViolation, 0)
+	addViolation := func(kind, detail string) {
+		violations = append(violations, types.AddressViolation{Kind: kind, Short: info.Short, Robust: info.Robust, Detail: detail})
+	}
+
+	prefix := address.MainnetPrefix
+	if network == address.Testnet {
+		prefix = address.TestnetPrefix
+	}
+	for _, addr := range []string{info.Short, info.Robust} {
+		if addr != "" && addr[:1] != prefix {
+			addViolation(AddressViolationWrongNetwork, fmt.Sprintf("address %s does not have the network prefix %s", addr, prefix))
+		}
+	}
+
+	short, err := address.NewFromString(info.Short)
+	switch {
+	case err != nil:
+		addViolation(AddressViolationInvalidShort, fmt.Sprintf("could not parse short address: %s", err))
+	case short.Protocol() != address.ID:
+		addViolation(AddressViolationInvalidShort, fmt.Sprintf("short address %s is not an ID address", info.Short))
+	}
+
+	if info.Robust != "" {
+		robust, err := address.NewFromString(info.Robust)
+		switch {
+		case err != nil:
+			addViolation(AddressViolationInvalidRobust, fmt.Sprintf("could not parse robust address: %s", err))
+		case robust.Protocol() == address.ID && info.Robust != info.Short:
+			addViolation(AddressViolationInvalidRobust, fmt.Sprintf("robust address %s is an ID address", info.Robust))
+		case resolvers.ShortAddress != nil && robust.Protocol() != address.ID:
+			resolved, err := resolvers.ShortAddress(robust)
+			if isContextError(err) {
+				// the lookup was cancelled or timed out, so the entry is not validated rather than invalid
+				break
+			}
+			if err != nil {
+				addViolation(AddressViolationShortMismatch, fmt.Sprintf("could not resolve robust address: %s", err))
+			} else if resolved != info.Short {
+				addViolation(AddressViolationShortMismatch, fmt.Sprintf("robust address resolves to %s", resolved))
+			}
+		}
+	}
+
+	if info.ActorCid != "" && info.ActorCid != UnknownStr && resolvers.ActorName != nil {
+		code, err := cid.Parse(info.ActorCid)
+		if err != nil {
+			addViolation(AddressViolationUnknownActorCode, fmt.Sprintf("could not parse actor cid %s: %s", info.ActorCid, err))
+			return violations
+		}
+
+		actorName, err := resolvers.ActorName(code)
+		switch {
+		case isContextError(err):
+		case err != nil:
+			addViolation(AddressViolationUnknownActorCode, fmt.Sprintf("actor cid %s is not part of a known bundle: %s", info.ActorCid, err))
+		case info.ActorType != "" && info.ActorType != actorName:
+			addViolation(AddressViolationActorTypeMismatch, fmt.Sprintf("actor type is %s, the actor cid is of %s", info.ActorType, actorName))
+		}
+	}
+
+	return violations
+}
+
+// ValidateAddresses validates every entry of the map, see ValidateAddressInfo. The violations are sorted by
+// short address so the report is stable.
+func ValidateAddresses(addresses *types.AddressInfoMap, network address.Network, resolvers AddressResolvers) []types.AddressViolation {
+	violations, _ := ValidateAddressesWithContext(context.Background(), addresses, network, resolvers)
+	return violations
+}
+
+// ValidateAddressesWithContext validates the entries of the map like ValidateAddresses until the context is done.
+// In that case, the violations found so far are returned along with the error of the context.
+func ValidateAddressesWithContext(ctx context.Context, addresses *types.AddressInfoMap, network address.Network,
+	resolvers AddressResolvers) ([]types.AddressViolation, error) {
+	violations := make([]types.AddressViolation, 0)
+	if addresses == nil {
+		return violations, nil
+	}
+
+	var err error
+	addresses.Range(func(_ string, info *types.AddressInfo) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		entryViolations := ValidateAddressInfo(info, network, resolvers)
+		// lookups cancelled midway are not violations, the entry is left as not validated
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		violations = append(violations, entryViolations...)
+		return true
+	})
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Short != violations[j].Short {
+			return violations[i].Short < violations[j].Short
+		}
+		return violations[i].Kind < violations[j].Kind
+	})
+	return violations, err
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	testRobust    = "f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea"
+	testActorCode = "bafk2bzacecy7dyaoulnfncp33az2vbsspu3t2ja7fktsmm6tg6nefcxrczsus"
+)
+
+func TestValidateAddressInfo(t *testing.T) {
+	resolvers := AddressResolvers{
+		ShortAddress: func(addr address.Address) (string, error) {
+			if addr.String() == testRobust {
+				return "f01234", nil
+			}
+			return "", errors.New("not found")
+		},
+		ActorName: func(code cid.Cid) (string, error) {
+			if code.String() == testActorCode {
+				return manifest.AccountKey, nil
+			}
+			return "", errors.New("unknown code")
+		},
+	}
+
+	tests := []struct {
+		name      string
+		info      types.AddressInfo
+		network   address.Network
+		wantKinds []string
+	}{
+		{
+			name:    "valid",
+			info:    types.AddressInfo{Short: "f01234", Robust: testRobust, ActorCid: testActorCode, ActorType: manifest.AccountKey},
+			network: address.Mainnet,
+		},
+		{
+			name:    "system actor",
+			info:    types.AddressInfo{Short: "f05", Robust: "f05"},
+			network: address.Mainnet,
+		},
+		{
+			name:      "short mismatch",
+			info:      types.AddressInfo{Short: "f01235", Robust: testRobust},
+			network:   address.Mainnet,
+			wantKinds: []string{AddressViolationShortMismatch},
+		},
+		{
+			name:      "short is not an id",
+			info:      types.AddressInfo{Short: testRobust},
+			network:   address.Mainnet,
+			wantKinds: []string{AddressViolationInvalidShort},
+		},
+		{
+			name:      "robust is another id",
+			info:      types.AddressInfo{Short: "f01234", Robust: "f01235"},
+			network:   address.Mainnet,
+			wantKinds: []string{AddressViolationInvalidRobust},
+		},
+		{
+			name:      "unknown actor code",
+			info:      types.AddressInfo{Short: "f01234", ActorCid: "bafkqadlgnfwc6mjpnb2hazlbnf2gc4tjn5xwoyrsgm"},
+			network:   address.Mainnet,
+			wantKinds: []string{AddressViolationUnknownActorCode},
+		},
+		{
+			name:      "actor type mismatch",
+			info:      types.AddressInfo{Short: "f01234", ActorCid: testActorCode, ActorType: manifest.MinerKey},
+			network:   address.Mainnet,
+			wantKinds: []string{AddressViolationActorTypeMismatch},
+		},
+		{
+			name:      "wrong network",
+			info:      types.AddressInfo{Short: "f01234"},
+			network:   address.Testnet,
+			wantKinds: []string{AddressViolationWrongNetwork},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := ValidateAddressInfo(&tt.info, tt.network, resolvers)
+			kinds := make([]string, 0, len(violations))
+			for _, violation := range violations {
+				require.Equal(t, tt.info.Short, violation.Short)
+				kinds = append(kinds, violation.Kind)
+			}
+			require.ElementsMatch(t, tt.wantKinds, kinds)
+		})
+	}
+}
+
+func TestValidateAddresses(t *testing.T) {
+	addresses := types.NewAddressInfoMap()
+	addresses.Set("f01234", &types.AddressInfo{Short: "f01234", Robust: testRobust})
+	addresses.Set("f01235", &types.AddressInfo{Short: "f01235", Robust: "f01236"})
+
+	violations := ValidateAddresses(addresses, address.Mainnet, AddressResolvers{})
+	require.Len(t, violations, 1)
+	require.Equal(t, "f01235", violations[0].Short)
+
+	require.Empty(t, ValidateAddresses(nil, address.Mainnet, AddressResolvers{}))
+
+	// the validation stops once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	violations, err := ValidateAddressesWithContext(ctx, addresses, address.Mainnet, AddressResolvers{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, violations)
+
+	// lookups cancelled midway are not reported as violations
+	ctx, cancel = context.WithCancel(context.Background())
+	cancelling := AddressResolvers{ShortAddress: func(address.Address) (string, error) {
+		cancel()
+		return "", context.Canceled
+	}}
+	resolved := types.NewAddressInfoMap()
+	resolved.Set("f01234", &types.AddressInfo{Short: "f01234", Robust: testRobust})
+	violations, err = ValidateAddressesWithContext(ctx, resolved, address.Mainnet, cancelling)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, violations)
+
+	// lookups timing out on their own (e.g. OnChainTimeout) leave the entry not validated rather than invalid
+	timingOut := AddressResolvers{ShortAddress: func(address.Address) (string, error) {
+		return "", fmt.Errorf("lookup failed: %w", context.DeadlineExceeded)
+	}}
+	violations, err = ValidateAddressesWithContext(context.Background(), resolved, address.Mainnet, timingOut)
+	require.NoError(t, err)
+	require.Empty(t, violations)
+}
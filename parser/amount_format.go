@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	// AmountFormatAttoFil keeps the amounts in attoFIL, in the Amount field of the txs
+	AmountFormatAttoFil = "attofil"
+	// AmountFormatFil renders the amounts as FIL decimal strings, in the AmountFil field of the txs. Amount is cleared.
+	AmountFormatFil = "fil"
+	// AmountFormatBoth sets both the Amount and AmountFil fields of the txs
+	AmountFormatBoth = "both"
+
+	filDecimals = 18
+)
+
+var amountFormats = []string{AmountFormatAttoFil, AmountFormatFil, AmountFormatBoth}
+
+// FormatFil renders an amount of attoFIL as a FIL decimal string without losing precision, e.g. 1500000000000000000
+// is rendered as 1.5. Nil amounts are rendered as an empty string.
+func FormatFil(attoFil *big.Int) string {
+	if attoFil == nil {
+		return ""
+	}
+
+	digits := new(big.Int).Abs(attoFil).String()
+	if len(digits) <= filDecimals {
+		digits = strings.Repeat("0", filDecimals-len(digits)+1) + digits
+	}
+
+	integer, fraction := digits[:len(digits)-filDecimals], strings.TrimRight(digits[len(digits)-filDecimals:], "0")
+	result := integer
+	if fraction != "" {
+		result += "." + fraction
+	}
+	if attoFil.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+// FormatAmounts sets the amounts of the txs in the given format, see the AmountFormat constants
+func FormatAmounts(txs []*types.Transaction, format string) {
+	if format == "" || format == AmountFormatAttoFil {
+		return
+	}
+
+	for _, tx := range txs {
+		tx.AmountFil = FormatFil(tx.Amount)
+		if format == AmountFormatFil {
+			tx.Amount = nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestFormatFil(t *testing.T) {
+	tests := []struct {
+		name    string
+		attoFil string
+		want    string
+	}{
+		{name: "zero", attoFil: "0", want: "0"},
+		{name: "one attofil", attoFil: "1", want: "0.000000000000000001"},
+		{name: "one fil", attoFil: "1000000000000000000", want: "1"},
+		{name: "decimals", attoFil: "1500000000000000000", want: "1.5"},
+		{name: "big amount", attoFil: "123456789000000000000000001", want: "123456789.000000000000000001"},
+		{name: "negative", attoFil: "-250000000000000000", want: "-0.25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, ok := new(big.Int).SetString(tt.attoFil, 10)
+			require.True(t, ok)
+			require.Equal(t, tt.want, FormatFil(amount))
+		})
+	}
+
+	require.Empty(t, FormatFil(nil))
+}
+
+func TestFormatAmounts(t *testing.T) {
+	newTxs := func() []*types.Transaction {
+		return []*types.Transaction{{Amount: big.NewInt(2_000_000_000_000_000_000)}}
+	}
+
+	txs := newTxs()
+	FormatAmounts(txs, AmountFormatAttoFil)
+	require.Equal(t, big.NewInt(2_000_000_000_000_000_000), txs[0].Amount)
+	require.Empty(t, txs[0].AmountFil)
+
+	txs = newTxs()
+	FormatAmounts(txs, AmountFormatBoth)
+	require.Equal(t, big.NewInt(2_000_000_000_000_000_000), txs[0].Amount)
+	require.Equal(t, "2", txs[0].AmountFil)
+
+	txs = newTxs()
+	FormatAmounts(txs, AmountFormatFil)
+	require.Nil(t, txs[0].Amount)
+	require.Equal(t, "2", txs[0].AmountFil)
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// BlockGasLimit is the max amount of gas the messages of a single block can use
+const BlockGasLimit = 10_000_000_000
+
+const (
+	// AnomalyGasExceedsBlockLimit flags messages, or tipsets, using more gas than their blocks allow
+	AnomalyGasExceedsBlockLimit = "gas_exceeds_block_limit"
+	// AnomalyNegativeAmount flags txs with a negative amount, which would imply negative balances
+	AnomalyNegativeAmount = "negative_amount"
+	// AnomalyOrphanValue flags txs moving value whose parent tx is not part of the tipset
+	AnomalyOrphanValue = "orphan_value"
+	// AnomalyValueUnderFailedParent flags successful txs moving value under a failed or reverted parent,
+	// as the value should have been reverted with the parent
+	AnomalyValueUnderFailedParent = "value_under_failed_parent"
+)
+
+// DetectAnomalies runs the heuristics on the txs of a tipset with the given amount of blocks. Anomalies point
+// either to chain weirdness or to parser bugs, so they are reported but the txs are never modified.
+func DetectAnomalies(txs []*types.Transaction, blocks int) []types.Anomaly {
+	anomalies := make([]types.Anomaly, 0)
+	byId := make(map[string]*types.Transaction, len(txs))
+	for _, tx := range txs {
+		byId[tx.Id] = tx
+	}
+
+	var tipsetGasUsed uint64
+	for _, tx := range txs {
+		if tx.Level == 0 && tx.TxType != TotalFeeOp {
+			tipsetGasUsed += tx.GasUsed
+			if tx.GasUsed > BlockGasLimit {
+				anomalies = append(anomalies, newAnomaly(AnomalyGasExceedsBlockLimit, tx,
+					fmt.Sprintf("message used %d gas, the block limit is %d", tx.GasUsed, uint64(BlockGasLimit))))
+			}
+		}
+
+		if tx.Amount == nil {
+			continue
+		}
+		if tx.Amount.Sign() < 0 {
+			anomalies = append(anomalies, newAnomaly(AnomalyNegativeAmount, tx, fmt.Sprintf("amount %s", tx.Amount.String())))
+			continue
+		}
+		if tx.Amount.Sign() == 0 || tx.Level == 0 {
+			continue
+		}
+
+		parent, ok := byId[tx.ParentId]
+		switch {
+		case !ok:
+			anomalies = append(anomalies, newAnomaly(AnomalyOrphanValue, tx, fmt.Sprintf("parent tx %s not found", tx.ParentId)))
+		case parent.Status != tx.Status && tx.Status == GetExitCodeStatus(0):
+			anomalies = append(anomalies, newAnomaly(AnomalyValueUnderFailedParent, tx, fmt.Sprintf("parent tx %s status is %s", parent.Id, parent.Status)))
+		}
+	}
+
+	if blocks > 0 && tipsetGasUsed > uint64(blocks)*BlockGasLimit {
+		anomalies = append(anomalies, types.Anomaly{
+			Kind:   AnomalyGasExceedsBlockLimit,
+			Detail: fmt.Sprintf("tipset used %d gas, the limit of its %d blocks is %d", tipsetGasUsed, blocks, uint64(blocks)*BlockGasLimit),
+		})
+	}
+	return anomalies
+}
+
+func newAnomaly(kind string, tx *types.Transaction, detail string) types.Anomaly {
+	return types.Anomaly{Kind: kind, TxId: tx.Id, TxCid: tx.TxCid, Detail: detail}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func anomalyKinds(anomalies []types.Anomaly) []string {
+	kinds := make([]string, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		kinds = append(kinds, anomaly.Kind+":"+anomaly.TxId)
+	}
+	return kinds
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	tests := []struct {
+		name   string
+		txs    []*types.Transaction
+		blocks int
+		want   []string
+	}{
+		{
+			name: "no anomalies",
+			txs: []*types.Transaction{
+				{Id: "main", Status: "Ok", GasUsed: 100, Amount: big.NewInt(10)},
+				{Id: "sub", ParentId: "main", Level: 1, Status: "Ok", Amount: big.NewInt(5)},
+				{Id: "fee", ParentId: "main", TxType: TotalFeeOp, Status: "Ok", Amount: big.NewInt(1)},
+			},
+			blocks: 1,
+			want:   []string{},
+		},
+		{
+			name: "message over the block gas limit",
+			txs: []*types.Transaction{
+				{Id: "main", Status: "Ok", GasUsed: BlockGasLimit + 1},
+			},
+			blocks: 2,
+			want:   []string{AnomalyGasExceedsBlockLimit + ":main"},
+		},
+		{
+			name: "tipset over the gas limit of its blocks",
+			txs: []*types.Transaction{
+				{Id: "a", Status: "Ok", GasUsed: BlockGasLimit},
+				{Id: "b", Status: "Ok", GasUsed: 1},
+			},
+			blocks: 1,
+			want:   []string{AnomalyGasExceedsBlockLimit + ":"},
+		},
+		{
+			name: "negative amount",
+			txs: []*types.Transaction{
+				{Id: "main", Status: "Ok", Amount: big.NewInt(-1)},
+			},
+			want: []string{AnomalyNegativeAmount + ":main"},
+		},
+		{
+			name: "orphan value",
+			txs: []*types.Transaction{
+				{Id: "sub", ParentId: "missing", Level: 1, Status: "Ok", Amount: big.NewInt(5)},
+			},
+			want: []string{AnomalyOrphanValue + ":sub"},
+		},
+		{
+			name: "value under failed parent",
+			txs: []*types.Transaction{
+				{Id: "main", Status: "ErrForbidden", Amount: big.NewInt(10)},
+				{Id: "sub", ParentId: "main", Level: 1, Status: "Ok", Amount: big.NewInt(5)},
+				{Id: "reverted", ParentId: "main", Level: 1, Status: StatusReverted, Amount: big.NewInt(5)},
+			},
+			want: []string{AnomalyValueUnderFailedParent + ":sub"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, anomalyKinds(DetectAnomalies(tt.txs, tt.blocks)))
+		})
+	}
+}
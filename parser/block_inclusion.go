@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"github.com/zondax/fil-parser/types"
+)
+
+// BlockInclusions attributes each message (level 0 tx) to every block of the tipset that included it. Txs that
+// are not included in any block, e.g. the implicit messages of cron and rewards, are skipped.
+func BlockInclusions(txs []*types.Transaction, tipset *types.ExtendedTipSet) []*types.BlockInclusion {
+	inclusions := make([]*types.BlockInclusion, 0)
+	if tipset == nil {
+		return inclusions
+	}
+
+	height := uint64(tipset.Height())
+	tipsetCid := tipset.GetCidString()
+	for _, tx := range txs {
+		if tx.Level != 0 || IsFee(tx.TxType) || tx.TxType == GasRefundOp {
+			continue
+		}
+		blocks := tipset.BlockMessages[tx.TxCid]
+		for _, block := range blocks {
+			inclusions = append(inclusions, &types.BlockInclusion{
+				Height:     height,
+				TipsetCid:  tipsetCid,
+				BlockCid:   block.Cid,
+				BlockMiner: block.BlockMiner,
+				TxCid:      tx.TxCid,
+				TxId:       tx.Id,
+				Canonical:  block.Cid == tx.BlockCid,
+				Inclusions: len(blocks),
+			})
+		}
+	}
+	return inclusions
+}
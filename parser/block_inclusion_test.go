@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestBlockInclusions(t *testing.T) {
+	tipset := &types.ExtendedTipSet{BlockMessages: types.BlockMessages{
+		"msgA": {{Cid: "block1", BlockMiner: "f01000"}, {Cid: "block2", BlockMiner: "f01001"}},
+		"msgB": {{Cid: "block2", BlockMiner: "f01001"}},
+	}}
+	withBlock := func(tx *types.Transaction, blockCid string) *types.Transaction {
+		tx.BlockCid = blockCid
+		return tx
+	}
+	txs := []*types.Transaction{
+		withBlock(&types.Transaction{Id: "a", TxCid: "msgA", TxType: MethodSend}, "block1"),
+		withBlock(&types.Transaction{Id: "subA", TxCid: "msgA", TxType: MethodSend, Level: 1}, "block1"),
+		withBlock(&types.Transaction{Id: "feeA", TxCid: "msgA", TxType: TotalFeeOp}, "block1"),
+		withBlock(&types.Transaction{Id: "b", TxCid: "msgB", TxType: MethodSend}, "block2"),
+		// implicit messages are not included in any block
+		{Id: "cron", TxCid: "msgCron", TxType: MethodEpochTick},
+	}
+
+	got := BlockInclusions(txs, tipset)
+	require.Len(t, got, 3)
+
+	tipsetCid := tipset.GetCidString()
+	require.Equal(t, []*types.BlockInclusion{
+		{TipsetCid: tipsetCid, BlockCid: "block1", BlockMiner: "f01000", TxCid: "msgA", TxId: "a", Canonical: true, Inclusions: 2},
+		{TipsetCid: tipsetCid, BlockCid: "block2", BlockMiner: "f01001", TxCid: "msgA", TxId: "a", Inclusions: 2},
+		{TipsetCid: tipsetCid, BlockCid: "block2", BlockMiner: "f01001", TxCid: "msgB", TxId: "b", Canonical: true, Inclusions: 1},
+	}, got)
+
+	require.Empty(t, BlockInclusions(txs, nil))
+}
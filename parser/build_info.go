@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"regexp"
+	"runtime/debug"
+	"sync"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	// ModulePath is the path of this module, used to find its version in the build info of the binary
+	ModulePath = "github.com/zondax/fil-parser"
+	// DevelVersion is the version reported when the module version is not known, e.g. when running its tests
+	DevelVersion = "(devel)"
+)
+
+// pseudoVersionRev matches the revision at the end of a pseudo-version, e.g. v0.0.0-20240101000000-abcdef123456
+var pseudoVersionRev = regexp.MustCompile(`[-.][0-9]{14}-([0-9a-f]{12})(?:\+incompatible)?$`)
+
+var buildInfo = sync.OnceValue(readBuildInfo)
+
+// GetBuildInfo returns the version and git revision of the fil-parser module the binary was built with
+func GetBuildInfo() types.BuildInfo {
+	return buildInfo()
+}
+
+func readBuildInfo() types.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return types.BuildInfo{Version: DevelVersion}
+	}
+	return buildInfoFrom(info)
+}
+
+func buildInfoFrom(info *debug.BuildInfo) types.BuildInfo {
+	// fil-parser is the main module when running its own binaries (e.g. cmd/tracedl) or tests
+	if info.Main.Path == ModulePath {
+		build := types.BuildInfo{Version: info.Main.Version}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				build.Revision = setting.Value
+			}
+		}
+		if build.Version == "" {
+			build.Version = DevelVersion
+		}
+		return build
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != ModulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+
+		build := types.BuildInfo{Version: dep.Version}
+		if match := pseudoVersionRev.FindStringSubmatch(dep.Version); match != nil {
+			build.Revision = match[1]
+		}
+		if build.Version == "" {
+			build.Version = DevelVersion
+		}
+		return build
+	}
+
+	return types.BuildInfo{Version: DevelVersion}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestBuildInfoFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		info *debug.BuildInfo
+		want types.BuildInfo
+	}{
+		{
+			name: "main module",
+			info: &debug.BuildInfo{
+				Main:     debug.Module{Path: ModulePath, Version: "v1.2.0"},
+				Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "abcdef1234567890"}},
+			},
+			want: types.BuildInfo{Version: "v1.2.0", Revision: "abcdef1234567890"},
+		},
+		{
+			name: "main module without version",
+			info: &debug.BuildInfo{Main: debug.Module{Path: ModulePath}},
+			want: types.BuildInfo{Version: DevelVersion},
+		},
+		{
+			name: "tagged dependency",
+			info: &debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}, Deps: []*debug.Module{{Path: ModulePath, Version: "v1.3.1"}}},
+			want: types.BuildInfo{Version: "v1.3.1"},
+		},
+		{
+			name: "pseudo-version dependency",
+			info: &debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}, Deps: []*debug.Module{{Path: ModulePath, Version: "v1.3.2-0.20240101000000-abcdef123456"}}},
+			want: types.BuildInfo{Version: "v1.3.2-0.20240101000000-abcdef123456", Revision: "abcdef123456"},
+		},
+		{
+			name: "replaced dependency",
+			info: &debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}, Deps: []*debug.Module{{Path: ModulePath, Version: "v1.0.0", Replace: &debug.Module{Path: "../fil-parser"}}}},
+			want: types.BuildInfo{Version: DevelVersion},
+		},
+		{
+			name: "not a dependency",
+			info: &debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}},
+			want: types.BuildInfo{Version: DevelVersion},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, buildInfoFrom(tt.info))
+		})
+	}
+}
+
+func TestBuildInfo_String(t *testing.T) {
+	require.Equal(t, "v1.0.0", types.BuildInfo{Version: "v1.0.0"}.String())
+	require.Equal(t, "v1.0.0+abc", types.BuildInfo{Version: "v1.0.0", Revision: "abc"}.String())
+}
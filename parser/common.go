@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
 	"time"
 
+	filBig "github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/exitcode"
 	"github.com/filecoin-project/go-state-types/manifest"
-	"github.com/filecoin-project/lotus/api"
 	"github.com/ipfs/go-cid"
 	"github.com/zondax/fil-parser/types"
 	"go.uber.org/zap"
@@ -24,6 +27,29 @@ func GetExitCodeStatus(exitCode exitcode.ExitCode) string {
 	return CheckExitCodeCommonError(code)
 }
 
+// GetTxStatus returns the status of a sub tx. Successful sub txs of a failed parent are reverted. The parsers only
+// report it if FeatureRevertedStatus is enabled.
+func GetTxStatus(exitCode exitcode.ExitCode, parentReverted bool) string {
+	if parentReverted && exitCode.IsSuccess() {
+		return StatusReverted
+	}
+	return GetExitCodeStatus(exitCode)
+}
+
+// IsPositiveAmount checks the sign of the amount. Uint64() must not be used for this, as it overflows for
+// amounts over ~18 FIL and is undefined for negative amounts.
+func IsPositiveAmount(amount filBig.Int) bool {
+	return amount.Int != nil && amount.Sign() > 0
+}
+
+// GetGasUsed returns the gas used as uint64, or zero if the value is negative or does not fit
+func GetGasUsed(gasUsed filBig.Int) uint64 {
+	if gasUsed.Int == nil || gasUsed.Sign() < 0 || !gasUsed.IsUint64() {
+		return 0
+	}
+	return gasUsed.Uint64()
+}
+
 func parseMetadata(key string, metadata map[string]interface{}) string {
 	params, ok := metadata[key].(string)
 	if ok && params != "" {
@@ -74,24 +100,55 @@ func AppendToAddressesMap(addressMap *types.AddressInfoMap, info ...*types.Addre
 	}
 }
 
+// SetCreationHeight sets the height of the actor created by the tx of the given height (Exec, Exec4, EAM creations...)
+func SetCreationHeight(info *types.AddressInfo, height uint64) {
+	if info.CreationTxCid != "" {
+		info.CreationHeight = height
+	}
+}
+
+// GetParentBaseFeeByHeight returns the ParentBaseFee of the first block of the tipset, or
+// types.ErrBaseFeeOverflow if it does not fit in an uint64.
+//
+// Deprecated: use GetParentBaseFee, which returns the whole base fee
 func GetParentBaseFeeByHeight(tipset *types.ExtendedTipSet, logger *zap.Logger) (uint64, error) {
+	parentBaseFee, err := GetParentBaseFee(tipset, logger)
+	if err != nil {
+		return 0, err
+	}
+	return types.NewBaseFee(parentBaseFee, types.BaseFeeSourceParentBaseFee).Uint64()
+}
+
+// GetParentBaseFee returns the ParentBaseFee of the first block of the tipset
+func GetParentBaseFee(tipset *types.ExtendedTipSet, logger *zap.Logger) (*big.Int, error) {
 	defaultError := errors.New("could not find base fee")
 	if tipset == nil {
 		logger.Sugar().Error("get-parent-base-fee: tipset is nil")
-		return 0, defaultError
+		return nil, defaultError
 	}
 
 	if len(tipset.TipSet.Blocks()) == 0 {
 		logger.Sugar().Error("get-parent-base-fee: no blocks found in the Tipset")
-		return 0, defaultError
+		return nil, defaultError
 	}
 
 	parentBaseFee := tipset.TipSet.Blocks()[0].ParentBaseFee
-	return parentBaseFee.Uint64(), nil
+	if parentBaseFee.Int == nil {
+		return new(big.Int), nil
+	}
+	return new(big.Int).Set(parentBaseFee.Int), nil
+}
+
+func TranslateTxCidToTxHash(nodeClient types.FullNode, mainMsgCid cid.Cid) (string, error) {
+	return TranslateTxCidToTxHashWithContext(context.Background(), nodeClient, mainMsgCid)
 }
 
-func TranslateTxCidToTxHash(nodeClient api.FullNode, mainMsgCid cid.Cid) (string, error) {
-	ctx := context.Background()
+// TranslateTxCidToTxHashWithContext is TranslateTxCidToTxHash, cancelling the lookup with the context
+func TranslateTxCidToTxHashWithContext(ctx context.Context, nodeClient types.FullNode, mainMsgCid cid.Cid) (string, error) {
+	// parsers without node, e.g. over a fake actors cache, have no eth tx hashes
+	if nodeClient == nil {
+		return "", nil
+	}
 	ethHash, err := nodeClient.EthGetTransactionHashByCid(ctx, mainMsgCid)
 	if err != nil || ethHash == nil {
 		return "", nil
@@ -99,3 +156,54 @@ func TranslateTxCidToTxHash(nodeClient api.FullNode, mainMsgCid cid.Cid) (string
 
 	return ethHash.String(), nil
 }
+
+// SetExecutionIndex sets the execution index of the message on all the txs generated from it
+func SetExecutionIndex(txs []*types.Transaction, index int) {
+	for _, tx := range txs {
+		tx.ExecutionIndex = uint64(index)
+	}
+}
+
+// SetSkippedTracesExecutionIndex sets the position of the message in the tipset on its skipped traces
+func SetSkippedTracesExecutionIndex(skipped []types.SkippedTrace, index int) {
+	for i := range skipped {
+		skipped[i].ExecutionIndex = uint64(index)
+	}
+}
+
+// BuildInternalTxPath returns the path of the i-th sub-call of the call at the parent path, e.g. 0.2
+func BuildInternalTxPath(parentPath string, i int) string {
+	if parentPath == "" {
+		return strconv.Itoa(i)
+	}
+	return parentPath + "." + strconv.Itoa(i)
+}
+
+// BuildInternalTxId returns the synthetic identifier of an internal tx. Internal txs are not messages of the
+// chain, so they have no cid of their own: the identifier is the cid of the on-chain message that triggered
+// them followed by the path of sub-call indexes in its execution trace, e.g. bafy2...:0.2 for the third
+// sub-call of the first sub-call. It can not be resolved by a node.
+func BuildInternalTxId(mainMsgCid, path string) string {
+	return mainMsgCid + ":" + path
+}
+
+// mergeTxMetadata sets the value under the key of the json metadata of the tx, keeping the other keys. Numbers are
+// decoded as json.Number, so the integers of the metadata above 2^53 are not rounded when encoded again.
+func mergeTxMetadata(tx *types.Transaction, key string, value interface{}) error {
+	metadata := make(map[string]interface{})
+	if tx.TxMetadata != "" {
+		decoder := json.NewDecoder(strings.NewReader(tx.TxMetadata))
+		decoder.UseNumber()
+		if err := decoder.Decode(&metadata); err != nil {
+			return fmt.Errorf("could not decode metadata of tx %s: %w", tx.Id, err)
+		}
+	}
+
+	metadata[key] = value
+	jsonMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	tx.TxMetadata = string(jsonMetadata)
+	return nil
+}
@@ -1,10 +1,18 @@
 package parser
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/filecoin-project/go-address"
+	filBig "github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/builtin/v11/datacap"
 	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
 )
 
 func TestGetExitcodeStatus(t *testing.T) {
@@ -143,6 +151,21 @@ func TestGetExitcodeStatus(t *testing.T) {
 			exitCode: exitcode.ErrReadOnly,
 			want:     "ErrReadOnly",
 		},
+		{
+			name:     "ErrNotPayable",
+			exitCode: exitcode.ErrNotPayable,
+			want:     "ErrNotPayable",
+		},
+		{
+			name:     "actor specific exit code",
+			exitCode: exitcode.FirstActorSpecificExitCode + 1,
+			want:     "33",
+		},
+		{
+			name:     "negative exit code",
+			exitCode: exitcode.ExitCode(-1),
+			want:     "-1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -153,6 +176,56 @@ func TestGetExitcodeStatus(t *testing.T) {
 	}
 }
 
+func TestGetTxStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		exitCode       exitcode.ExitCode
+		parentReverted bool
+		want           string
+	}{
+		{name: "successful tx", exitCode: exitcode.Ok, want: "Ok"},
+		{name: "failed tx", exitCode: exitcode.ErrInsufficientFunds, want: "ErrInsufficientFunds"},
+		{name: "successful send reverted by its parent", exitCode: exitcode.Ok, parentReverted: true, want: StatusReverted},
+		{name: "failed tx with reverted parent", exitCode: exitcode.ErrForbidden, parentReverted: true, want: "ErrForbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, GetTxStatus(tt.exitCode, tt.parentReverted))
+		})
+	}
+}
+
+func TestIsPositiveAmount(t *testing.T) {
+	overflow, err := filBig.FromString("20000000000000000000")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		amount filBig.Int
+		want   bool
+	}{
+		{name: "positive", amount: filBig.NewInt(10), want: true},
+		{name: "zero", amount: filBig.Zero()},
+		{name: "negative refund", amount: filBig.NewInt(-10)},
+		{name: "nil", amount: filBig.Int{}},
+		// 2^64 is zero as uint64
+		{name: "over uint64", amount: filBig.Lsh(filBig.NewInt(1), 64), want: true},
+		{name: "over 18 FIL", amount: overflow, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsPositiveAmount(tt.amount))
+		})
+	}
+}
+
+func TestGetGasUsed(t *testing.T) {
+	require.Equal(t, uint64(100), GetGasUsed(filBig.NewInt(100)))
+	require.Equal(t, uint64(0), GetGasUsed(filBig.NewInt(-100)))
+	require.Equal(t, uint64(0), GetGasUsed(filBig.Int{}))
+	require.Equal(t, uint64(0), GetGasUsed(filBig.Lsh(filBig.NewInt(1), 64)))
+}
+
 func TestParseParams(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -260,3 +333,84 @@ func TestParseReturn(t *testing.T) {
 		})
 	}
 }
+
+func TestSetExecutionIndex(t *testing.T) {
+	txs := []*types.Transaction{{}, {}}
+	SetExecutionIndex(txs, 3)
+	for _, tx := range txs {
+		require.Equal(t, uint64(3), tx.ExecutionIndex)
+	}
+}
+
+func TestSetSkippedTracesExecutionIndex(t *testing.T) {
+	skipped := []types.SkippedTrace{{}, {}}
+	SetSkippedTracesExecutionIndex(skipped[1:], 3)
+	require.Equal(t, uint64(0), skipped[0].ExecutionIndex)
+	require.Equal(t, uint64(3), skipped[1].ExecutionIndex)
+}
+
+func TestSetCreationHeight(t *testing.T) {
+	created := &types.AddressInfo{Short: "f01000", CreationTxCid: "bafy"}
+	SetCreationHeight(created, 100)
+	require.Equal(t, uint64(100), created.CreationHeight)
+
+	// the info of an existing actor is not a creation
+	existing := &types.AddressInfo{Short: "f01001"}
+	SetCreationHeight(existing, 100)
+	require.Zero(t, existing.CreationHeight)
+}
+
+func TestBuildInternalTxId(t *testing.T) {
+	path := BuildInternalTxPath("", 0)
+	require.Equal(t, "0", path)
+	path = BuildInternalTxPath(path, 2)
+	require.Equal(t, "0.2", path)
+	require.Equal(t, "bafy2bzacea:0.2", BuildInternalTxId("bafy2bzacea", path))
+}
+
+func TestMergeTxMetadata(t *testing.T) {
+	// integers above 2^53 are kept as they are
+	tx := &types.Transaction{Id: "a", TxMetadata: `{"Params":{"Amount":9007199254740993,"Ratio":0.5}}`}
+	require.NoError(t, mergeTxMetadata(tx, SignatureKey, "value"))
+	require.Equal(t, `{"Params":{"Amount":9007199254740993,"Ratio":0.5},"signature":"value"}`, tx.TxMetadata)
+
+	tx = &types.Transaction{Id: "b"}
+	require.NoError(t, mergeTxMetadata(tx, SignatureKey, "value"))
+	require.Equal(t, `{"signature":"value"}`, tx.TxMetadata)
+
+	tx = &types.Transaction{Id: "c", TxMetadata: "not json"}
+	require.Error(t, mergeTxMetadata(tx, SignatureKey, "value"))
+}
+
+func TestGetParentBaseFeeByHeight(t *testing.T) {
+	tipsetWithBaseFee := func(baseFee filBig.Int) *types.ExtendedTipSet {
+		c := cid.MustParse("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+		miner, err := address.NewIDAddress(1000)
+		require.NoError(t, err)
+		tipset, err := filTypes.NewTipSet([]*filTypes.BlockHeader{{
+			Miner:                 miner,
+			Parents:               []cid.Cid{c},
+			ParentWeight:          filTypes.NewInt(10),
+			ParentStateRoot:       c,
+			ParentMessageReceipts: c,
+			Messages:              c,
+			ParentBaseFee:         baseFee,
+		}})
+		require.NoError(t, err)
+		return &types.ExtendedTipSet{TipSet: *tipset}
+	}
+
+	baseFee, err := GetParentBaseFeeByHeight(tipsetWithBaseFee(filBig.NewInt(100)), zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), baseFee)
+
+	overflow := filBig.NewFromGo(new(big.Int).Lsh(big.NewInt(1), 64))
+	_, err = GetParentBaseFeeByHeight(tipsetWithBaseFee(overflow), zap.NewNop())
+	require.ErrorIs(t, err, types.ErrBaseFeeOverflow)
+	amount, err := GetParentBaseFee(tipsetWithBaseFee(overflow), zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, overflow.Int, amount)
+
+	_, err = GetParentBaseFeeByHeight(nil, zap.NewNop())
+	require.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	DefaultMaxSectorInfoLookups = 100
+	// DefaultMetadataCompressionThreshold is the metadata size in bytes from which it is compressed
+	DefaultMetadataCompressionThreshold = 64 * 1024
+	// DefaultLowMemoryTraceThreshold is the traces size in bytes from which they are decoded one at a time
+	DefaultLowMemoryTraceThreshold = 256 * 1024 * 1024
+
+	// ConfigEnvPrefix is the prefix of the env vars that override the config values, e.g. FIL_PARSER_ENRICH_SECTOR_INFO
+	ConfigEnvPrefix = "FIL_PARSER"
+)
+
+var ErrInvalidConfig = errors.New("invalid parser config")
+
+// FilecoinParserConfig contains the optional features of the parser
+type FilecoinParserConfig struct {
+	// EnrichSectorInfo attaches the on-chain sector info (activation, expiration, deal ids) to terminate,
+	// extend and fault miner txs. Every sector requires an extra call to the node, so it is disabled by default.
+	EnrichSectorInfo bool `mapstructure:"enrich_sector_info" yaml:"enrich_sector_info"`
+	// MaxSectorInfoLookups caps the amount of sectors enriched per tx. Zero means DefaultMaxSectorInfoLookups
+	MaxSectorInfoLookups int `mapstructure:"max_sector_info_lookups" yaml:"max_sector_info_lookups"`
+	// EnrichMinerInfo attaches the owner, worker and control addresses of the miner at the tipset to every tx
+	// sent to a miner actor. Every miner requires an extra call to the node per tipset, so it is disabled by default.
+	EnrichMinerInfo bool `mapstructure:"enrich_miner_info" yaml:"enrich_miner_info"`
+	// CompressMetadata zstd compresses the metadata of the txs bigger than MetadataCompressionThreshold,
+	// e.g. PublishStorageDeals or ProveCommitAggregate. Compressed txs are flagged with MetadataCompressed.
+	CompressMetadata bool `mapstructure:"compress_metadata" yaml:"compress_metadata"`
+	// MetadataCompressionThreshold is the size in bytes from which the metadata is compressed.
+	// Zero means DefaultMetadataCompressionThreshold
+	MetadataCompressionThreshold int `mapstructure:"metadata_compression_threshold" yaml:"metadata_compression_threshold"`
+	// TxInputProvenance sets on every tx the combined hash of the inputs it was parsed from. The hashes of
+	// the inputs are always reported in the ParseReport.
+	TxInputProvenance bool `mapstructure:"tx_input_provenance" yaml:"tx_input_provenance"`
+	// DetectAnomalies runs the anomalies heuristics on every parsed tipset (gas over the block limits,
+	// negative amounts, value moved out of nowhere...) and reports them in the ParseReport
+	DetectAnomalies bool `mapstructure:"detect_anomalies" yaml:"detect_anomalies"`
+	// CheckInvariants checks on every parsed tipset that fees, burns and rewards reconcile with the tipset level
+	// expectations (e.g. the miner tips are the gas rewards of the blocks) and reports the violations in the ParseReport
+	CheckInvariants bool `mapstructure:"check_invariants" yaml:"check_invariants"`
+	// ValidateAddresses checks the address info entries of every parsed tipset (short matching the robust address
+	// at the tipset, known actor cid, network prefix...). Inconsistent entries are kept and reported in the ParseReport.
+	ValidateAddresses bool `mapstructure:"validate_addresses" yaml:"validate_addresses"`
+	// ExperimentalFeatures are the experimental decoders to enable, see Feature. None by default, so upgrading
+	// the library does not change the output shape until the features are explicitly enabled.
+	ExperimentalFeatures []string `mapstructure:"experimental_features" yaml:"experimental_features"`
+	// AmountFormat is how the amounts of the txs are rendered: attoFIL (default), FIL decimal strings or both,
+	// see the AmountFormat constants
+	AmountFormat string `mapstructure:"amount_format" yaml:"amount_format"`
+	// UnknownSignatures is how the messages signed with an unknown signature type are handled: kept and
+	// flagged (default) or dropped, see the UnknownSignatures constants
+	UnknownSignatures string `mapstructure:"unknown_signatures" yaml:"unknown_signatures"`
+	// StampBuildInfo sets on every tx the fil-parser build that parsed it. The build is always reported in the ParseReport.
+	StampBuildInfo bool `mapstructure:"stamp_build_info" yaml:"stamp_build_info"`
+	// LinkReceipts sets on every message tx the index of its receipt and the receipts root of the tipset, when
+	// provided, so indexed receipts can later be verified against the chain with a proof
+	LinkReceipts bool `mapstructure:"link_receipts" yaml:"link_receipts"`
+	// TraceVersionMismatch is how traces whose layout does not match the node version of their metadata are
+	// handled: parsed with the parser matching their layout (default), rejected with an ErrVersionMismatch or
+	// parsed as the metadata says, see the TraceVersionMismatch constants
+	TraceVersionMismatch string `mapstructure:"trace_version_mismatch" yaml:"trace_version_mismatch"`
+	// IdHashScheme is the hash the tx ids are built from, see the IdHashScheme constants. Indexers using the
+	// same scheme generate the same ids for the same traces.
+	IdHashScheme string `mapstructure:"id_hash_scheme" yaml:"id_hash_scheme"`
+	// JSONCodec is the json implementation the traces are decoded with, see the JSONCodec constants. Both
+	// codecs produce the same output.
+	JSONCodec string `mapstructure:"json_codec" yaml:"json_codec"`
+	// LowMemoryTraceThreshold is the size in bytes of the traces from which they are decoded and parsed one at a
+	// time instead of all at once, so pathological heights (e.g. huge aggregated prove-commits) do not run out
+	// of memory. The output is the same with both strategies. Zero means DefaultLowMemoryTraceThreshold.
+	// Only the v2 traces parsed by ParseTransactions and ParseTransactionsStream are decoded one at a time; v1
+	// traces and ParseFees still decode them all at once. ParseTransactions still holds all the parsed txs, use
+	// ParseTransactionsStream to not accumulate them.
+	LowMemoryTraceThreshold int `mapstructure:"low_memory_trace_threshold" yaml:"low_memory_trace_threshold"`
+	// DecodeTimeout is the time budget per height to decode the traces, e.g. 30s. Zero means no budget
+	DecodeTimeout time.Duration `mapstructure:"decode_timeout" yaml:"decode_timeout"`
+	// AddressValidationTimeout is the time budget per height to validate the addresses against the chain, e.g. 5s.
+	// It only applies when ValidateAddresses is enabled; the lookups done while parsing the traces are bounded by
+	// AddressLookupTimeout instead. Zero means no budget
+	AddressValidationTimeout time.Duration `mapstructure:"address_validation_timeout" yaml:"address_validation_timeout"`
+	// AddressLookupTimeout is the time budget per height of the address lookups made while parsing the traces to
+	// fill the addresses of the result, e.g. 5s. Once it runs out the remaining addresses are not looked up: the
+	// height fails or, in best effort mode, the result only holds the addresses found so far. Each lookup is also
+	// bounded by DataSourceConfig.OnChainTimeout. Zero means no budget
+	AddressLookupTimeout time.Duration `mapstructure:"address_lookup_timeout" yaml:"address_lookup_timeout"`
+	// StageTimeoutHandling is what happens when a stage runs out of its time budget: the height fails (default) or
+	// the stage is skipped and reported, see the StageTimeout constants. The on-chain lookups of the actors cache
+	// have their own budget, see DataSourceConfig.OnChainTimeout.
+	StageTimeoutHandling string `mapstructure:"stage_timeout_handling" yaml:"stage_timeout_handling"`
+}
+
+// DefaultConfig returns the config used when none is provided
+func DefaultConfig() FilecoinParserConfig {
+	return FilecoinParserConfig{
+		EnrichSectorInfo:             false,
+		MaxSectorInfoLookups:         DefaultMaxSectorInfoLookups,
+		EnrichMinerInfo:              false,
+		CompressMetadata:             false,
+		MetadataCompressionThreshold: DefaultMetadataCompressionThreshold,
+		TxInputProvenance:            false,
+		DetectAnomalies:              false,
+		CheckInvariants:              false,
+		ValidateAddresses:            false,
+		ExperimentalFeatures:         []string{},
+		AmountFormat:                 AmountFormatAttoFil,
+		UnknownSignatures:            UnknownSignaturesPassThrough,
+		StampBuildInfo:               false,
+		LinkReceipts:                 false,
+		TraceVersionMismatch:         TraceVersionMismatchAutoCorrect,
+		IdHashScheme:                 IdHashSchemeSha256,
+		JSONCodec:                    JSONCodecSonic,
+		LowMemoryTraceThreshold:      DefaultLowMemoryTraceThreshold,
+		DecodeTimeout:                0,
+		AddressValidationTimeout:     0,
+		AddressLookupTimeout:         0,
+		StageTimeoutHandling:         StageTimeoutFail,
+	}
+}
+
+// GetMetadataCompressionThreshold returns the threshold, using the default one if it is not set
+func (c FilecoinParserConfig) GetMetadataCompressionThreshold() int {
+	if c.MetadataCompressionThreshold <= 0 {
+		return DefaultMetadataCompressionThreshold
+	}
+	return c.MetadataCompressionThreshold
+}
+
+// GetLowMemoryTraceThreshold returns the threshold, using the default one if it is not set
+func (c FilecoinParserConfig) GetLowMemoryTraceThreshold() int {
+	if c.LowMemoryTraceThreshold <= 0 {
+		return DefaultLowMemoryTraceThreshold
+	}
+	return c.LowMemoryTraceThreshold
+}
+
+// Validate returns an error describing every invalid value of the config
+func (c FilecoinParserConfig) Validate() error {
+	var errs []error
+	if c.MaxSectorInfoLookups < 0 {
+		errs = append(errs, fmt.Errorf("max_sector_info_lookups must be zero or positive, got %d", c.MaxSectorInfoLookups))
+	}
+	if c.MetadataCompressionThreshold < 0 {
+		errs = append(errs, fmt.Errorf("metadata_compression_threshold must be zero or positive, got %d", c.MetadataCompressionThreshold))
+	}
+	if c.LowMemoryTraceThreshold < 0 {
+		errs = append(errs, fmt.Errorf("low_memory_trace_threshold must be zero or positive, got %d", c.LowMemoryTraceThreshold))
+	}
+	if c.DecodeTimeout < 0 {
+		errs = append(errs, fmt.Errorf("decode_timeout must be zero or positive, got %s", c.DecodeTimeout))
+	}
+	if c.AddressValidationTimeout < 0 {
+		errs = append(errs, fmt.Errorf("address_validation_timeout must be zero or positive, got %s", c.AddressValidationTimeout))
+	}
+	if c.AddressLookupTimeout < 0 {
+		errs = append(errs, fmt.Errorf("address_lookup_timeout must be zero or positive, got %s", c.AddressLookupTimeout))
+	}
+	errs = append(errs, validateFeatures(c.ExperimentalFeatures)...)
+	if c.AmountFormat != "" && !slices.Contains(amountFormats, c.AmountFormat) {
+		errs = append(errs, fmt.Errorf("amount_format must be one of %s, got %s", strings.Join(amountFormats, ", "), c.AmountFormat))
+	}
+	if c.UnknownSignatures != "" && !slices.Contains(unknownSignatureHandlings, c.UnknownSignatures) {
+		errs = append(errs, fmt.Errorf("unknown_signatures must be one of %s, got %s", strings.Join(unknownSignatureHandlings, ", "), c.UnknownSignatures))
+	}
+	if c.TraceVersionMismatch != "" && !slices.Contains(traceVersionMismatchHandlings, c.TraceVersionMismatch) {
+		errs = append(errs, fmt.Errorf("trace_version_mismatch must be one of %s, got %s", strings.Join(traceVersionMismatchHandlings, ", "),
+			c.TraceVersionMismatch))
+	}
+	if c.IdHashScheme != "" && !slices.Contains(idHashSchemes, c.IdHashScheme) {
+		errs = append(errs, fmt.Errorf("id_hash_scheme must be one of %s, got %s", strings.Join(idHashSchemes, ", "), c.IdHashScheme))
+	}
+	if c.JSONCodec != "" && !slices.Contains(jsonCodecs, c.JSONCodec) {
+		errs = append(errs, fmt.Errorf("json_codec must be one of %s, got %s", strings.Join(jsonCodecs, ", "), c.JSONCodec))
+	}
+	if c.StageTimeoutHandling != "" && !slices.Contains(stageTimeoutHandlings, c.StageTimeoutHandling) {
+		errs = append(errs, fmt.Errorf("stage_timeout_handling must be one of %s, got %s", strings.Join(stageTimeoutHandlings, ", "),
+			c.StageTimeoutHandling))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return nil
+}
+
+// LoadConfig reads the config from a yaml file, starting from the default values. Env vars prefixed
+// with ConfigEnvPrefix take precedence over the file. If path is empty, only the env vars are read.
+func LoadConfig(path string) (FilecoinParserConfig, error) {
+	v := viper.New()
+	v.SetEnvPrefix(ConfigEnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// defaults must be set for every key, so viper knows which env vars to look for
+	defaults := DefaultConfig()
+	v.SetDefault("enrich_sector_info", defaults.EnrichSectorInfo)
+	v.SetDefault("max_sector_info_lookups", defaults.MaxSectorInfoLookups)
+	v.SetDefault("enrich_miner_info", defaults.EnrichMinerInfo)
+	v.SetDefault("compress_metadata", defaults.CompressMetadata)
+	v.SetDefault("metadata_compression_threshold", defaults.MetadataCompressionThreshold)
+	v.SetDefault("tx_input_provenance", defaults.TxInputProvenance)
+	v.SetDefault("detect_anomalies", defaults.DetectAnomalies)
+	v.SetDefault("check_invariants", defaults.CheckInvariants)
+	v.SetDefault("validate_addresses", defaults.ValidateAddresses)
+	v.SetDefault("experimental_features", defaults.ExperimentalFeatures)
+	v.SetDefault("amount_format", defaults.AmountFormat)
+	v.SetDefault("unknown_signatures", defaults.UnknownSignatures)
+	v.SetDefault("stamp_build_info", defaults.StampBuildInfo)
+	v.SetDefault("link_receipts", defaults.LinkReceipts)
+	v.SetDefault("trace_version_mismatch", defaults.TraceVersionMismatch)
+	v.SetDefault("id_hash_scheme", defaults.IdHashScheme)
+	v.SetDefault("json_codec", defaults.JSONCodec)
+	v.SetDefault("low_memory_trace_threshold", defaults.LowMemoryTraceThreshold)
+	v.SetDefault("decode_timeout", defaults.DecodeTimeout)
+	v.SetDefault("address_validation_timeout", defaults.AddressValidationTimeout)
+	v.SetDefault("address_lookup_timeout", defaults.AddressLookupTimeout)
+	v.SetDefault("stage_timeout_handling", defaults.StageTimeoutHandling)
+
+	if path != "" {
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			return FilecoinParserConfig{}, fmt.Errorf("could not read config file %s: %w", path, err)
+		}
+	}
+
+	var config FilecoinParserConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return FilecoinParserConfig{}, fmt.Errorf("could not decode config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return FilecoinParserConfig{}, err
+	}
+	return config, nil
+}
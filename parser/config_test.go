@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilecoinParserConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  FilecoinParserConfig
+		wantErr bool
+	}{
+		{name: "default config", config: DefaultConfig()},
+		{name: "zero value", config: FilecoinParserConfig{}},
+		{name: "negative sector info lookups", config: FilecoinParserConfig{MaxSectorInfoLookups: -1}, wantErr: true},
+		{name: "experimental features", config: FilecoinParserConfig{ExperimentalFeatures: []string{"miner_network_info", "all"}}},
+		{name: "unknown experimental feature", config: FilecoinParserConfig{ExperimentalFeatures: []string{"unknown"}}, wantErr: true},
+		{name: "fil amount format", config: FilecoinParserConfig{AmountFormat: AmountFormatFil}},
+		{name: "unknown amount format", config: FilecoinParserConfig{AmountFormat: "nanofil"}, wantErr: true},
+		{name: "drop unknown signatures", config: FilecoinParserConfig{UnknownSignatures: UnknownSignaturesDrop}},
+		{name: "invalid unknown signatures handling", config: FilecoinParserConfig{UnknownSignatures: "fail"}, wantErr: true},
+		{name: "std json codec", config: FilecoinParserConfig{JSONCodec: JSONCodecStd}},
+		{name: "unknown json codec", config: FilecoinParserConfig{JSONCodec: "jsoniter"}, wantErr: true},
+		{name: "negative low memory trace threshold", config: FilecoinParserConfig{LowMemoryTraceThreshold: -1}, wantErr: true},
+		{name: "best effort stage timeouts", config: FilecoinParserConfig{AddressValidationTimeout: 5 * time.Second, StageTimeoutHandling: StageTimeoutBestEffort}},
+		{name: "negative decode timeout", config: FilecoinParserConfig{DecodeTimeout: -time.Second}, wantErr: true},
+		{name: "negative address lookup timeout", config: FilecoinParserConfig{AddressLookupTimeout: -time.Second}, wantErr: true},
+		{name: "unknown stage timeout handling", config: FilecoinParserConfig{StageTimeoutHandling: "retry"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidConfig)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("enrich_sector_info: true\nmax_sector_info_lookups: 20\naddress_validation_timeout: 5s\n"), 0o600))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	want := DefaultConfig()
+	want.EnrichSectorInfo = true
+	want.MaxSectorInfoLookups = 20
+	want.AddressValidationTimeout = 5 * time.Second
+	require.Equal(t, want, config)
+
+	// env vars take precedence over the file
+	t.Setenv("FIL_PARSER_MAX_SECTOR_INFO_LOOKUPS", "50")
+	config, err = LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, 50, config.MaxSectorInfoLookups)
+
+	// defaults are used when there is no file
+	config, err = LoadConfig("")
+	require.NoError(t, err)
+	want = DefaultConfig()
+	want.MaxSectorInfoLookups = 50
+	require.Equal(t, want, config)
+
+	t.Setenv("FIL_PARSER_EXPERIMENTAL_FEATURES", "miner_network_info,frc46_token_transfers")
+	config, err = LoadConfig("")
+	require.NoError(t, err)
+	require.True(t, config.IsFeatureEnabled(FeatureMinerNetworkInfo))
+	require.True(t, config.IsFeatureEnabled(FeatureFRC46TokenTransfers))
+	require.False(t, config.IsFeatureEnabled(FeatureGenericMethodDecoder))
+	t.Setenv("FIL_PARSER_EXPERIMENTAL_FEATURES", "")
+
+	t.Setenv("FIL_PARSER_MAX_SECTOR_INFO_LOOKUPS", "-1")
+	_, err = LoadConfig(path)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestFilecoinParserConfig_IsFeatureEnabled(t *testing.T) {
+	require.False(t, DefaultConfig().IsFeatureEnabled(FeatureGenericMethodDecoder))
+
+	config := FilecoinParserConfig{ExperimentalFeatures: []string{string(FeatureGenericMethodDecoder)}}
+	require.True(t, config.IsFeatureEnabled(FeatureGenericMethodDecoder))
+	require.False(t, config.IsFeatureEnabled(FeatureMinerNetworkInfo))
+
+	config = FilecoinParserConfig{ExperimentalFeatures: []string{string(FeatureAll)}}
+	for _, feature := range ExperimentalFeatures {
+		require.True(t, config.IsFeatureEnabled(feature))
+	}
+}
@@ -7,6 +7,8 @@ const (
 	OverEstimationBurnOp = "OverEstimationBurn"
 	MinerFeeOp           = "MinerFee"
 	BurnFeeOp            = "BurnFee"
+	// GasRefundOp is the unused gas returned to the sender, see NewGasRefundTx
+	GasRefundOp = "GasRefund"
 
 	BurnAddress = "f099"
 	EthPrefix   = "0x"
@@ -18,12 +20,33 @@ const (
 	EthHashKey = "ethHash"
 	AddressKey = "address"
 	EthLogsKey = "ethLogs"
+	// SignatureKey holds the SignatureInfo of the signed messages
+	SignatureKey = "signature"
+	// VerifiedContractKey holds the VerifiedContract called by the evm txs, if a contract metadata provider is set
+	VerifiedContractKey = "verifiedContract"
+	// UnknownAddressesKey holds the UnknownProtocolAddress entries of the txs with an address of an unknown protocol
+	UnknownAddressesKey = "unknownAddresses"
+
+	SectorsInfoKey      = "SectorsInfo"
+	MinerInfoKey        = "MinerInfo"
+	MinerNetworkInfoKey = "MinerNetworkInfo"
+	SectorExtensionsKey = "SectorExtensions"
 
 	UnknownStr = "unknown"
 
+	// StatusReverted is the status of sub txs that succeeded but were reverted by the failure of a parent tx
+	StatusReverted = "Reverted"
+
 	TxTypeGenesis = "Genesis"
 	GenesisHeight = 0
 
+	// TxTypeCronProvingDeadlinePenalty is the burn of a miner at the end of a proving deadline. The actor burns the
+	// continued fault fees and the deposits of the expired pre-commits together, in a single send.
+	TxTypeCronProvingDeadlinePenalty = "CronProvingDeadlinePenalty"
+	// TxTypeCronEarlyTerminationPenalty is the burn of the termination fees of the sectors terminated early,
+	// processed by the cron of the miner
+	TxTypeCronEarlyTerminationPenalty = "CronEarlyTerminationPenalty"
+
 	MultisigConstructorMethod = "Constructor"
 
 	// Methods
@@ -65,6 +88,7 @@ const (
 	MethodCurrentTotalPower                   = "CurrentTotalPower"                   // MethodsPower
 	MethodUpdatePledgeTotal                   = "UpdatePledgeTotal"                   // MethodsPower
 	MethodPowerDeprecated1                    = "Deprecated1"                         // MethodsPower - OnConsensusFault
+	MethodOnConsensusFault                    = "OnConsensusFault"                    // MethodsPower
 	MethodNetworkRawPowerExported             = "NetworkRawPowerExported"             // MethodsPower
 	MethodMinerRawPowerExported               = "MinerRawPowerExported"               // MethodsPower
 	MethodMinerCountExported                  = "MinerCountExported"                  // MethodsPower
@@ -22,6 +22,7 @@ var commonErrors = errorsMap{
 	"23": "ErrUnspecified",
 	"24": "ErrAssertionFailed",
 	"25": "ErrReadOnly",
+	"26": "ErrNotPayable",
 }
 
 // CheckExitCodeCommonError given an ExitCode.String() checks if is a common error
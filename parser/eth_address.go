@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"golang.org/x/crypto/sha3"
+)
+
+// ChecksumEthAddress returns the EIP-55 checksummed representation of the eth address.
+// With FeatureChecksumEthAddresses every eth address emitted by the parser uses this format, see FormatEthAddress.
+func ChecksumEthAddress(ethAddr ethtypes.EthAddress) string {
+	lower := hex.EncodeToString(ethAddr[:])
+
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(lower))
+	hash := hasher.Sum(nil)
+
+	checksummed := []byte(lower)
+	for i, c := range checksummed {
+		if c < 'a' || c > 'f' {
+			continue
+		}
+		// uppercase the letter if the matching nibble of the hash is >= 8
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+
+	return EthPrefix + string(checksummed)
+}
+
+// FormatEthAddress returns the representation of the eth address emitted by the parser: checksummed with
+// FeatureChecksumEthAddresses, lowercase otherwise
+func (c FilecoinParserConfig) FormatEthAddress(ethAddr ethtypes.EthAddress) string {
+	if c.IsFeatureEnabled(FeatureChecksumEthAddresses) {
+		return ChecksumEthAddress(ethAddr)
+	}
+	return ethAddr.String()
+}
+
+// NormalizeEthAddress parses an eth address in any case and returns its checksummed representation
+func NormalizeEthAddress(ethAddr string) (string, error) {
+	parsed, err := ethtypes.ParseEthAddress(strings.ToLower(ethAddr))
+	if err != nil {
+		return "", fmt.Errorf("invalid eth address %s: %w", ethAddr, err)
+	}
+	return ChecksumEthAddress(parsed), nil
+}
+
+// EthAddressFromFilAddress converts a f410 (or id) address to its checksummed eth address
+func EthAddressFromFilAddress(addr address.Address) (string, error) {
+	ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	return ChecksumEthAddress(ethAddr), nil
+}
+
+// FilAddressFromEthAddress converts an eth address in any case to its f410 (or id, for masked id addresses) address
+func FilAddressFromEthAddress(ethAddr string) (address.Address, error) {
+	parsed, err := ethtypes.ParseEthAddress(strings.ToLower(ethAddr))
+	if err != nil {
+		return address.Undef, fmt.Errorf("invalid eth address %s: %w", ethAddr, err)
+	}
+	return parsed.ToFilecoinAddress()
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEthAddress(t *testing.T) {
+	// EIP-55 test vectors
+	tests := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+	for _, want := range tests {
+		t.Run(want, func(t *testing.T) {
+			got, err := NormalizeEthAddress(want)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			got, err = NormalizeEthAddress("0x" + strings.ToLower(want[2:]))
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	_, err := NormalizeEthAddress("0x1234")
+	require.Error(t, err)
+}
+
+func TestEthAddressFilAddressConversion(t *testing.T) {
+	ethAddr := "0xd4c5fb16488Aa48081296299d54b0c648C9333dA"
+
+	filAddr, err := FilAddressFromEthAddress("0xD4C5FB16488AA48081296299D54B0C648C9333DA")
+	require.NoError(t, err)
+	require.Equal(t, address.Delegated, filAddr.Protocol())
+
+	got, err := EthAddressFromFilAddress(filAddr)
+	require.NoError(t, err)
+	require.Equal(t, ethAddr, got)
+
+	// id addresses are converted to masked id eth addresses
+	idAddr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	got, err = EthAddressFromFilAddress(idAddr)
+	require.NoError(t, err)
+	require.Equal(t, "0xFf000000000000000000000000000000000003E8", got)
+
+	filAddr, err = FilAddressFromEthAddress(got)
+	require.NoError(t, err)
+	require.Equal(t, idAddr, filAddr)
+}
+
+func TestFilecoinParserConfig_FormatEthAddress(t *testing.T) {
+	ethAddr, err := ethtypes.ParseEthAddress("0xd4c5fb16488aa48081296299d54b0c648c9333da")
+	require.NoError(t, err)
+
+	require.Equal(t, "0xd4c5fb16488aa48081296299d54b0c648c9333da", FilecoinParserConfig{}.FormatEthAddress(ethAddr))
+
+	config := FilecoinParserConfig{ExperimentalFeatures: []string{string(FeatureChecksumEthAddresses)}}
+	require.Equal(t, "0xd4c5fb16488Aa48081296299d54b0c648C9333dA", config.FormatEthAddress(ethAddr))
+}
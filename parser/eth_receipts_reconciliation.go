@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/zondax/fil-parser/tools/fees"
+	"github.com/zondax/fil-parser/types"
+)
+
+// ReconcileEthReceipts matches the eth receipts of a tipset with its parsed messages (level 0 txs) using the tx
+// cid of the receipts, or their tx hash translated with txCids when the cid is not set. The effective gas price
+// of the receipts is set on their messages, and their status, gas used and effective gas price are cross-checked
+// with the values derived from the traces.
+// The status of the messages is not overwritten with the status of the receipts: it is derived from the exit code
+// of the traces, which the binary status of a receipt cannot represent, and the traces are the source of every
+// other field of the tx. A divergent status is reported per tx instead, with the id of the message.
+func ReconcileEthReceipts(txs []*types.Transaction, txCids []types.TxCidTranslation, receipts []types.EthReceipt) *types.EthReceiptsReconciliation {
+	okStatus := GetExitCodeStatus(0)
+	messages := make(map[string]*types.Transaction)
+	feeTxs := make(map[string]*types.Transaction)
+	for _, tx := range txs {
+		switch {
+		case tx.TxType == TotalFeeOp:
+			feeTxs[tx.ParentId] = tx
+		case tx.Level == 0 && tx.TxType != GasRefundOp:
+			messages[tx.TxCid] = tx
+		}
+	}
+	hashToCid := make(map[string]string, len(txCids))
+	for _, translation := range txCids {
+		hashToCid[translation.TxHash] = translation.TxCid
+	}
+
+	report := &types.EthReceiptsReconciliation{Receipts: len(receipts)}
+	seen := make(map[string]bool, len(receipts))
+	for _, receipt := range receipts {
+		mismatch := types.EthReceiptMismatch{
+			TransactionHash: receipt.TransactionHash.String(),
+			TransactionCid:  receipt.TransactionCid,
+		}
+
+		if seen[mismatch.TransactionHash] {
+			report.Duplicates = append(report.Duplicates, mismatch)
+			continue
+		}
+		seen[mismatch.TransactionHash] = true
+
+		if mismatch.TransactionCid == "" {
+			mismatch.TransactionCid = hashToCid[mismatch.TransactionHash]
+		}
+		tx, ok := messages[mismatch.TransactionCid]
+		if !ok {
+			mismatch.Reason = types.EthReceiptMismatchMessageNotFound
+			report.Mismatches = append(report.Mismatches, mismatch)
+			continue
+		}
+		mismatch.TxId = tx.Id
+
+		if receipt.EffectiveGasPrice.Int != nil {
+			tx.EffectiveGasPrice = new(big.Int).Set(receipt.EffectiveGasPrice.Int)
+		}
+
+		var mismatches []types.EthReceiptMismatch
+		if succeeded := tx.Status == okStatus; succeeded != (receipt.Status == 1) {
+			mismatches = append(mismatches, withReason(mismatch, types.EthReceiptMismatchStatus,
+				fmt.Sprintf("receipt status is %d, message status is %s", uint64(receipt.Status), tx.Status)))
+		}
+		if uint64(receipt.GasUsed) != tx.GasUsed {
+			mismatches = append(mismatches, withReason(mismatch, types.EthReceiptMismatchGasUsed,
+				fmt.Sprintf("receipt gas used is %d, message gas used is %d", uint64(receipt.GasUsed), tx.GasUsed)))
+		}
+		if derived := derivedEffectiveGasPrice(feeTxs[tx.Id], tx.GasUsed); derived != nil && tx.EffectiveGasPrice != nil &&
+			derived.Cmp(tx.EffectiveGasPrice) != 0 {
+			mismatches = append(mismatches, withReason(mismatch, types.EthReceiptMismatchEffectiveGasPrice,
+				fmt.Sprintf("receipt effective gas price is %s, derived from the traces is %s", tx.EffectiveGasPrice, derived)))
+		}
+
+		if len(mismatches) > 0 {
+			report.Mismatches = append(report.Mismatches, mismatches...)
+			continue
+		}
+		report.Matched++
+	}
+
+	return report
+}
+
+func withReason(mismatch types.EthReceiptMismatch, reason, detail string) types.EthReceiptMismatch {
+	mismatch.Reason = reason
+	mismatch.Detail = detail
+	return mismatch
+}
+
+// derivedEffectiveGasPrice computes the effective gas price from the fees of the message the same way the node
+// does, see fees.GasOutputs. It returns nil if the fee tx is not available.
+func derivedEffectiveGasPrice(feeTx *types.Transaction, gasUsed uint64) *big.Int {
+	if feeTx == nil {
+		return nil
+	}
+	rawMetadata, err := feeTx.GetMetadata()
+	if err != nil {
+		return nil
+	}
+	var metadata FeesMetadata
+	if err = json.Unmarshal([]byte(rawMetadata), &metadata); err != nil {
+		return nil
+	}
+
+	var amounts [3]filBig.Int
+	for i, amount := range []string{metadata.BurnFee.Amount, metadata.MinerFee.Amount, metadata.OverEstimationBurnFee.Amount} {
+		if amounts[i], err = filBig.FromString(amount); err != nil {
+			return nil
+		}
+	}
+	outputs := fees.GasOutputs{BaseFeeBurn: amounts[0], MinerTip: amounts[1], OverEstimationBurn: amounts[2]}
+	return outputs.EffectiveGasPrice(int64(gasUsed)).Int
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func newReconciliationEthReceipt(t *testing.T, txHash, txCid string, status, gasUsed, effectiveGasPrice uint64) types.EthReceipt {
+	hash, err := ethtypes.ParseEthHash(txHash)
+	require.NoError(t, err)
+	return types.EthReceipt{
+		EthTxReceipt: types.EthTxReceipt{
+			TransactionHash:   hash,
+			Status:            ethtypes.EthUint64(status),
+			GasUsed:           ethtypes.EthUint64(gasUsed),
+			EffectiveGasPrice: ethtypes.EthBigInt(filBig.NewIntUnsigned(effectiveGasPrice)),
+		},
+		TransactionCid: txCid,
+	}
+}
+
+func TestReconcileEthReceipts(t *testing.T) {
+	const (
+		hashA = "0x0000000000000000000000000000000000000000000000000000000000000001"
+		hashB = "0x0000000000000000000000000000000000000000000000000000000000000002"
+		hashC = "0x0000000000000000000000000000000000000000000000000000000000000003"
+		hashD = "0x0000000000000000000000000000000000000000000000000000000000000004"
+	)
+	// 200 attoFil spent for 10 gas units
+	feesMetadata, err := json.Marshal(FeesMetadata{
+		BurnFee:               BurnFee{Amount: "100"},
+		MinerFee:              MinerFee{Amount: "50"},
+		OverEstimationBurnFee: OverEstimationBurnFee{Amount: "50"},
+		RefundFee:             &RefundFee{Amount: "1000"},
+	})
+	require.NoError(t, err)
+
+	ok := GetExitCodeStatus(0)
+	txs := []*types.Transaction{
+		{Id: "a", TxCid: "cidA", TxType: MethodInvokeContract, Status: ok, GasUsed: 10},
+		{Id: "feeA", ParentId: "a", TxCid: "cidA", TxType: TotalFeeOp, Status: ok, TxMetadata: string(feesMetadata)},
+		{Id: "b", TxCid: "cidB", TxType: MethodInvokeContract, Status: "Error", GasUsed: 10},
+		{Id: "c", TxCid: "cidC", TxType: MethodInvokeContract, Status: ok, GasUsed: 10},
+		{Id: "feeC", ParentId: "c", TxCid: "cidC", TxType: TotalFeeOp, Status: ok, TxMetadata: string(feesMetadata)},
+	}
+	txCids := []types.TxCidTranslation{
+		{TxCid: "cidA", TxHash: hashA},
+		{TxCid: "cidB", TxHash: hashB},
+		{TxCid: "cidC", TxHash: hashC},
+	}
+	receipts := []types.EthReceipt{
+		// the tx cid is resolved from the tx hash
+		newReconciliationEthReceipt(t, hashA, "", 1, 10, 20),
+		newReconciliationEthReceipt(t, hashA, "cidA", 1, 10, 20),
+		newReconciliationEthReceipt(t, hashB, "cidB", 1, 12, 7),
+		newReconciliationEthReceipt(t, hashC, "cidC", 1, 10, 21),
+		newReconciliationEthReceipt(t, hashD, "", 1, 10, 20),
+	}
+
+	report := ReconcileEthReceipts(txs, txCids, receipts)
+	require.Equal(t, 5, report.Receipts)
+	require.Equal(t, 1, report.Matched)
+	require.Len(t, report.Duplicates, 1)
+
+	reasons := make(map[string][]string)
+	txIds := make(map[string]string)
+	for _, mismatch := range report.Mismatches {
+		reasons[mismatch.TransactionHash] = append(reasons[mismatch.TransactionHash], mismatch.Reason)
+		txIds[mismatch.TransactionHash] = mismatch.TxId
+	}
+	require.Equal(t, map[string][]string{
+		hashB: {types.EthReceiptMismatchStatus, types.EthReceiptMismatchGasUsed},
+		hashC: {types.EthReceiptMismatchEffectiveGasPrice},
+		hashD: {types.EthReceiptMismatchMessageNotFound},
+	}, reasons)
+	require.Equal(t, map[string]string{hashB: "b", hashC: "c", hashD: ""}, txIds)
+
+	// the status of the messages comes from the traces
+	require.Equal(t, "Error", txs[2].Status)
+
+	// the effective gas price comes from the node, even if it does not match the traces
+	require.Equal(t, big.NewInt(20), txs[0].EffectiveGasPrice)
+	require.Equal(t, big.NewInt(7), txs[2].EffectiveGasPrice)
+	require.Equal(t, big.NewInt(21), txs[3].EffectiveGasPrice)
+	require.Nil(t, txs[1].EffectiveGasPrice)
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/zondax/fil-parser/types"
+)
+
+type ethLogKey struct {
+	txHash   string
+	logIndex uint64
+}
+
+// ReconcileEthLogs matches the eth logs of a tipset with its parsed messages (level 0 txs) using the tx cid of
+// the logs, or their tx hash translated with txCids when the cid is not set. The txs are never modified.
+func ReconcileEthLogs(txs []*types.Transaction, txCids []types.TxCidTranslation, ethLogs []types.EthLog) *types.EthLogsReconciliation {
+	okStatus := GetExitCodeStatus(0)
+	messages := make(map[string]*types.Transaction)
+	for _, tx := range txs {
+		if tx.Level == 0 && tx.TxType != TotalFeeOp && tx.TxType != GasRefundOp {
+			messages[tx.TxCid] = tx
+		}
+	}
+	receiptIndexes := messagesReceiptIndexes(messages)
+	hashToCid := make(map[string]string, len(txCids))
+	for _, translation := range txCids {
+		hashToCid[translation.TxHash] = translation.TxCid
+	}
+
+	report := &types.EthLogsReconciliation{Logs: len(ethLogs)}
+	seen := make(map[ethLogKey]bool, len(ethLogs))
+	withLogs := make(map[string]bool)
+	for _, ethLog := range ethLogs {
+		mismatch := types.EthLogMismatch{
+			TransactionHash: ethLog.TransactionHash.String(),
+			TransactionCid:  ethLog.TransactionCid,
+			LogIndex:        uint64(ethLog.LogIndex),
+		}
+
+		key := ethLogKey{txHash: mismatch.TransactionHash, logIndex: mismatch.LogIndex}
+		if seen[key] {
+			report.Duplicates = append(report.Duplicates, mismatch)
+			continue
+		}
+		seen[key] = true
+
+		txCid, translated := hashToCid[mismatch.TransactionHash]
+		if mismatch.TransactionCid == "" {
+			mismatch.TransactionCid = txCid
+		} else if translated && txCid != mismatch.TransactionCid {
+			mismatch.Reason = types.EthLogMismatchTxHash
+			mismatch.Detail = fmt.Sprintf("tx hash translates to %s", txCid)
+			report.Unmatched = append(report.Unmatched, mismatch)
+			continue
+		}
+
+		tx, ok := messages[mismatch.TransactionCid]
+		receiptIndex, explicit := receiptIndexes[mismatch.TransactionCid]
+		switch {
+		case !ok:
+			mismatch.Reason = types.EthLogMismatchMessageNotFound
+		case tx.Status != okStatus:
+			mismatch.Reason = types.EthLogMismatchFailedMessage
+			mismatch.Detail = fmt.Sprintf("message status is %s", tx.Status)
+		case !explicit:
+			mismatch.Reason = types.EthLogMismatchTransactionIndex
+			mismatch.Detail = "message is implicit and has no receipt"
+		case receiptIndex != uint64(ethLog.TransactionIndex):
+			mismatch.Reason = types.EthLogMismatchTransactionIndex
+			mismatch.Detail = fmt.Sprintf("transaction index is %d, message receipt index is %d", uint64(ethLog.TransactionIndex), receiptIndex)
+		}
+		if mismatch.Reason != "" {
+			report.Unmatched = append(report.Unmatched, mismatch)
+			continue
+		}
+
+		report.Matched++
+		withLogs[tx.TxCid] = true
+	}
+
+	report.Messages = len(withLogs)
+	return report
+}
+
+// messagesReceiptIndexes returns the receipt index of the messages, which is what the transaction index of the eth
+// logs counts. Unlike the execution index, it skips the implicit messages (rewards, cron). The ReceiptIndex of the
+// messages is used if linked, otherwise the explicit messages are counted in execution order.
+func messagesReceiptIndexes(messages map[string]*types.Transaction) map[string]uint64 {
+	ordered := make([]*types.Transaction, 0, len(messages))
+	for _, tx := range messages {
+		ordered = append(ordered, tx)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].ExecutionIndex < ordered[j].ExecutionIndex
+	})
+
+	indexes := make(map[string]uint64, len(ordered))
+	var explicitMessages uint64
+	for _, tx := range ordered {
+		if tx.ReceiptIndex != nil {
+			indexes[tx.TxCid] = *tx.ReceiptIndex
+			explicitMessages = *tx.ReceiptIndex + 1
+			continue
+		}
+		if from, err := address.NewFromString(tx.TxFrom); err == nil && IsImplicitMessage(from) {
+			continue
+		}
+		indexes[tx.TxCid] = explicitMessages
+		explicitMessages++
+	}
+	return indexes
+}
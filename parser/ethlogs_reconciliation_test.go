@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func newReconciliationEthLog(t *testing.T, txHash, txCid string, logIndex, txIndex uint64) types.EthLog {
+	hash, err := ethtypes.ParseEthHash(txHash)
+	require.NoError(t, err)
+	return types.EthLog{
+		EthLog:         ethtypes.EthLog{TransactionHash: hash, LogIndex: ethtypes.EthUint64(logIndex), TransactionIndex: ethtypes.EthUint64(txIndex)},
+		TransactionCid: txCid,
+	}
+}
+
+func TestReconcileEthLogs(t *testing.T) {
+	const (
+		hashA = "0x0000000000000000000000000000000000000000000000000000000000000001"
+		hashB = "0x0000000000000000000000000000000000000000000000000000000000000002"
+		hashC = "0x0000000000000000000000000000000000000000000000000000000000000003"
+		hashD = "0x0000000000000000000000000000000000000000000000000000000000000004"
+	)
+	ok := GetExitCodeStatus(0)
+	txs := []*types.Transaction{
+		{TxCid: "cidA", TxType: MethodInvokeContract, Status: ok, ExecutionIndex: 0},
+		{TxCid: "cidA", TxType: TotalFeeOp, Status: ok, ExecutionIndex: 0},
+		{TxCid: "cidB", TxType: MethodInvokeContract, Status: "Error", ExecutionIndex: 1},
+		{TxCid: "cidC", TxType: MethodInvokeContract, Status: ok, ExecutionIndex: 2},
+	}
+	txCids := []types.TxCidTranslation{
+		{TxCid: "cidA", TxHash: hashA},
+		{TxCid: "cidB", TxHash: hashB},
+		{TxCid: "cidC", TxHash: hashC},
+	}
+	ethLogs := []types.EthLog{
+		newReconciliationEthLog(t, hashA, "cidA", 0, 0),
+		// the tx cid is resolved from the tx hash
+		newReconciliationEthLog(t, hashA, "", 1, 0),
+		newReconciliationEthLog(t, hashA, "cidA", 1, 0),
+		newReconciliationEthLog(t, hashB, "cidB", 0, 1),
+		newReconciliationEthLog(t, hashC, "cidC", 0, 5),
+		newReconciliationEthLog(t, hashC, "cidA", 1, 2),
+		newReconciliationEthLog(t, hashD, "cidD", 0, 3),
+	}
+
+	report := ReconcileEthLogs(txs, txCids, ethLogs)
+	require.Equal(t, 7, report.Logs)
+	require.Equal(t, 2, report.Matched)
+	require.Equal(t, 1, report.Messages)
+	require.Len(t, report.Duplicates, 1)
+	require.Equal(t, uint64(1), report.Duplicates[0].LogIndex)
+
+	reasons := make([]string, 0, len(report.Unmatched))
+	for _, mismatch := range report.Unmatched {
+		reasons = append(reasons, mismatch.Reason)
+	}
+	require.Equal(t, []string{types.EthLogMismatchFailedMessage, types.EthLogMismatchTransactionIndex, types.EthLogMismatchTxHash,
+		types.EthLogMismatchMessageNotFound}, reasons)
+}
+
+func TestReconcileEthLogs_ImplicitMessages(t *testing.T) {
+	const (
+		hashA = "0x0000000000000000000000000000000000000000000000000000000000000001"
+		hashB = "0x0000000000000000000000000000000000000000000000000000000000000002"
+	)
+	ok := GetExitCodeStatus(0)
+	// the block reward is applied ahead of the evm messages, but it has no receipt so the eth logs do not count it
+	txs := []*types.Transaction{
+		{TxCid: "cidReward", TxFrom: "f00", TxType: MethodAwardBlockReward, Status: ok, ExecutionIndex: 0},
+		{TxCid: "cidA", TxFrom: "f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea", TxType: MethodInvokeContract, Status: ok, ExecutionIndex: 1},
+		{TxCid: "cidB", TxFrom: "f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea", TxType: MethodInvokeContract, Status: ok, ExecutionIndex: 2},
+	}
+	ethLogs := []types.EthLog{
+		newReconciliationEthLog(t, hashA, "cidA", 0, 0),
+		newReconciliationEthLog(t, hashB, "cidB", 1, 1),
+	}
+
+	report := ReconcileEthLogs(txs, nil, ethLogs)
+	require.Empty(t, report.Unmatched)
+	require.Equal(t, 2, report.Matched)
+	require.Equal(t, 2, report.Messages)
+
+	// the linked receipt index is used as is
+	receiptIndex := uint64(4)
+	txs[2].ReceiptIndex = &receiptIndex
+	report = ReconcileEthLogs(txs, nil, ethLogs)
+	require.Equal(t, 1, report.Matched)
+	require.Len(t, report.Unmatched, 1)
+	require.Equal(t, types.EthLogMismatchTransactionIndex, report.Unmatched[0].Reason)
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// EventFieldCodec is how the value of an event entry is decoded
+type EventFieldCodec string
+
+const (
+	// EventFieldRaw keeps the value hex encoded
+	EventFieldRaw EventFieldCodec = "raw"
+	// EventFieldCBOR decodes the value as generic dag-cbor
+	EventFieldCBOR    EventFieldCodec = "cbor"
+	EventFieldString  EventFieldCodec = "string"
+	EventFieldUint    EventFieldCodec = "uint"
+	EventFieldInt     EventFieldCodec = "int"
+	EventFieldBigInt  EventFieldCodec = "bigint"
+	EventFieldAddress EventFieldCodec = "address"
+	EventFieldCid     EventFieldCodec = "cid"
+	EventFieldBoolean EventFieldCodec = "bool"
+)
+
+var ErrInvalidEventSchema = errors.New("invalid event schema")
+
+// EventField maps the entry with the given key to a named field
+type EventField struct {
+	// Key is the key of the entry emitted by the actor
+	Key string `json:"key" yaml:"key"`
+	// Name is the name of the field in the decoded event. Empty means Key
+	Name  string          `json:"name" yaml:"name"`
+	Codec EventFieldCodec `json:"codec" yaml:"codec"`
+}
+
+// EventSchema describes the native events emitted by a user actor
+type EventSchema struct {
+	// Emitter is the address of the actor, as it shows up in the events (usually the id address)
+	Emitter string `json:"emitter" yaml:"emitter"`
+	// Type is optional and matches the $type entry of the event. Empty matches every event of the emitter
+	Type string `json:"type" yaml:"type"`
+	// Name is the name of the event, set as the selector signature of the decoded events
+	Name   string       `json:"name" yaml:"name"`
+	Fields []EventField `json:"fields" yaml:"fields"`
+}
+
+// Field returns the field of the entry key with its name set, and false if the entry is not in the schema
+func (s *EventSchema) Field(key string) (EventField, bool) {
+	for _, field := range s.Fields {
+		if field.Key == key {
+			if field.Name == "" {
+				field.Name = field.Key
+			}
+			return field, true
+		}
+	}
+	return EventField{}, false
+}
+
+func (s *EventSchema) validate() error {
+	if s.Emitter == "" {
+		return fmt.Errorf("%w: emitter is required", ErrInvalidEventSchema)
+	}
+	for _, field := range s.Fields {
+		if field.Key == "" {
+			return fmt.Errorf("%w: field key is required", ErrInvalidEventSchema)
+		}
+		switch field.Codec {
+		case EventFieldRaw, EventFieldCBOR, EventFieldString, EventFieldUint, EventFieldInt, EventFieldBigInt,
+			EventFieldAddress, EventFieldCid, EventFieldBoolean:
+		case "":
+			return fmt.Errorf("%w: codec of field %s is required", ErrInvalidEventSchema, field.Key)
+		default:
+			return fmt.Errorf("%w: unknown codec %s of field %s", ErrInvalidEventSchema, field.Codec, field.Key)
+		}
+	}
+	return nil
+}
+
+// EventSchemaRegistry holds the event schemas registered for user actors. It is safe for concurrent use.
+type EventSchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string][]EventSchema
+}
+
+func NewEventSchemaRegistry() *EventSchemaRegistry {
+	return &EventSchemaRegistry{schemas: make(map[string][]EventSchema)}
+}
+
+// Register adds the schema, replacing the one registered for the same emitter and type
+func (r *EventSchemaRegistry) Register(schema EventSchema) error {
+	if err := schema.validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schemas := r.schemas[schema.Emitter]
+	for i := range schemas {
+		if schemas[i].Type == schema.Type {
+			schemas[i] = schema
+			return nil
+		}
+	}
+	r.schemas[schema.Emitter] = append(schemas, schema)
+	return nil
+}
+
+// Lookup returns the schema of the event, preferring the one registered for the event type
+// over the one registered for every event of the emitter
+func (r *EventSchemaRegistry) Lookup(emitter, eventType string) (*EventSchema, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var fallback *EventSchema
+	for i := range r.schemas[emitter] {
+		schema := r.schemas[emitter][i]
+		if eventType != "" && schema.Type == eventType {
+			return &schema, true
+		}
+		if schema.Type == "" {
+			fallback = &schema
+		}
+	}
+	return fallback, fallback != nil
+}
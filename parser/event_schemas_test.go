@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSchemaRegistry(t *testing.T) {
+	registry := NewEventSchemaRegistry()
+
+	require.ErrorIs(t, registry.Register(EventSchema{}), ErrInvalidEventSchema)
+	require.ErrorIs(t, registry.Register(EventSchema{Emitter: "f01001", Fields: []EventField{{Key: "a"}}}), ErrInvalidEventSchema)
+	require.ErrorIs(t, registry.Register(EventSchema{Emitter: "f01001", Fields: []EventField{{Key: "a", Codec: "float"}}}), ErrInvalidEventSchema)
+
+	require.NoError(t, registry.Register(EventSchema{Emitter: "f01001", Name: "any"}))
+	require.NoError(t, registry.Register(EventSchema{Emitter: "f01001", Type: "deposit", Name: "deposit"}))
+	// registering the same emitter and type again replaces the schema
+	require.NoError(t, registry.Register(EventSchema{Emitter: "f01001", Type: "deposit", Name: "deposit-v2",
+		Fields: []EventField{{Key: "a", Codec: EventFieldUint}}}))
+
+	schema, ok := registry.Lookup("f01001", "deposit")
+	require.True(t, ok)
+	require.Equal(t, "deposit-v2", schema.Name)
+
+	field, ok := schema.Field("a")
+	require.True(t, ok)
+	require.Equal(t, "a", field.Name)
+	_, ok = schema.Field("b")
+	require.False(t, ok)
+
+	// events without a type specific schema use the schema of the emitter
+	schema, ok = registry.Lookup("f01001", "withdraw")
+	require.True(t, ok)
+	require.Equal(t, "any", schema.Name)
+
+	_, ok = registry.Lookup("f01002", "")
+	require.False(t, ok)
+
+	var nilRegistry *EventSchemaRegistry
+	_, ok = nilRegistry.Lookup("f01001", "")
+	require.False(t, ok)
+}
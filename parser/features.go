@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Feature is an experimental decoder. Experimental decoders change the output shape of the txs, so they are
+// disabled by default and must be enabled one by one with FilecoinParserConfig.ExperimentalFeatures. Once a
+// decoder is considered stable, its flag is removed and it is always enabled.
+// Not every new output is a feature: the output opted in by its own config field (e.g. CompressMetadata, whose
+// MetadataCompressed flag is omitted otherwise), fixes of wrong values (e.g. the overflowing amounts handled by
+// IsPositiveAmount) and the ExecutionIndex, which the parser itself needs to order the txs and report the skipped
+// traces, are always enabled.
+type Feature string
+
+const (
+	// FeatureAll enables every experimental feature
+	FeatureAll Feature = "all"
+	// FeatureGenericMethodDecoder decodes the methods without an explicit parser into the param and return
+	// types declared for them by go-state-types
+	FeatureGenericMethodDecoder Feature = "generic_method_decoder"
+	// FeatureMinerNetworkInfo adds the decoded peer ids and multiaddrs to the miner network txs, see MinerNetworkInfo
+	FeatureMinerNetworkInfo Feature = "miner_network_info"
+	// FeatureFRC46TokenTransfers reports the FRC-46 token transfers found in the txs, see TokenTransfer
+	FeatureFRC46TokenTransfers Feature = "frc46_token_transfers"
+	// FeatureMinerCronPenalties types the burns made by the miner deadline cron, see ClassifyMinerCronTxs
+	FeatureMinerCronPenalties Feature = "miner_cron_penalties"
+	// FeatureGasRefunds adds a tx with the gas refunded to the sender of every message, see NewGasRefundTx, and the
+	// refund to the fee metadata
+	FeatureGasRefunds Feature = "gas_refunds"
+	// FeatureSectorExtensions adds the same view of the extended sectors to both versions of
+	// ExtendSectorExpiration, see SectorExtension
+	FeatureSectorExtensions Feature = "sector_extensions"
+	// FeatureMarketEscrow reports the deposits and withdrawals of the storage market escrow, see ExtractEscrowChanges
+	FeatureMarketEscrow Feature = "market_escrow"
+	// FeatureParamsCodecs records the IPLD codec of the params and return values, and renders them according to
+	// it instead of assuming CBOR, see RenderIpldData
+	FeatureParamsCodecs Feature = "params_codecs"
+	// FeatureAccountPromotions reports the placeholder actors promoted to EthAccounts, see AccountPromotionCandidates
+	FeatureAccountPromotions Feature = "account_promotions"
+	// FeatureLotusJSON renders the decoded params and return values with the JSON conventions of lotus
+	// StateDecodeParams, so the output can be diffed against the node
+	FeatureLotusJSON Feature = "lotus_json"
+	// FeatureBlockInclusions reports every block that included each message, not only the canonical one, see
+	// BlockInclusions
+	FeatureBlockInclusions Feature = "block_inclusions"
+	// FeatureRevertedStatus sets the StatusReverted status on the successful sub txs of a failed call, see GetTxStatus
+	FeatureRevertedStatus Feature = "reverted_status"
+	// FeatureChecksumEthAddresses renders the eth addresses in their EIP-55 checksummed form and fills the eth
+	// address of the delegated actors returned by GetActorAddressInfo, see FormatEthAddress
+	FeatureChecksumEthAddresses Feature = "checksum_eth_addresses"
+	// FeatureFeeMarket adds the placement of the message gas premium within its tipset to the fee metadata, see
+	// FeeMarketPlacement
+	FeatureFeeMarket Feature = "fee_market"
+	// FeatureTxHierarchy sets IsInternal and RootId on the txs, see LinkTxHierarchy
+	FeatureTxHierarchy Feature = "tx_hierarchy"
+	// FeatureCreationHeight sets the CreationHeight of the actors created by the parsed txs, see SetCreationHeight
+	FeatureCreationHeight Feature = "creation_height"
+)
+
+// ExperimentalFeatures are the features that can be enabled in the config
+var ExperimentalFeatures = []Feature{
+	FeatureGenericMethodDecoder,
+	FeatureMinerNetworkInfo,
+	FeatureFRC46TokenTransfers,
+	FeatureMinerCronPenalties,
+	FeatureGasRefunds,
+	FeatureSectorExtensions,
+	FeatureMarketEscrow,
+	FeatureParamsCodecs,
+	FeatureAccountPromotions,
+	FeatureLotusJSON,
+	FeatureBlockInclusions,
+	FeatureRevertedStatus,
+	FeatureChecksumEthAddresses,
+	FeatureFeeMarket,
+	FeatureTxHierarchy,
+	FeatureCreationHeight,
+}
+
+// IsFeatureEnabled returns whether the experimental feature is enabled in the config
+func (c FilecoinParserConfig) IsFeatureEnabled(feature Feature) bool {
+	for _, enabled := range c.ExperimentalFeatures {
+		if Feature(enabled) == feature || Feature(enabled) == FeatureAll {
+			return true
+		}
+	}
+	return false
+}
+
+func validateFeatures(features []string) []error {
+	var errs []error
+	for _, feature := range features {
+		if Feature(feature) != FeatureAll && !slices.Contains(ExperimentalFeatures, Feature(feature)) {
+			errs = append(errs, fmt.Errorf("unknown experimental feature %s", feature))
+		}
+	}
+	return errs
+}
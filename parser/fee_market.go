@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+// FeeMarketPlacement is the position of a message gas premium within the messages of its tipset
+type FeeMarketPlacement struct {
+	GasPremium string
+	// GasPremiumPercentile is the percentage of messages of the tipset with a gas premium lower or equal to this one
+	GasPremiumPercentile float64
+}
+
+// GasPremiumDistribution holds the gas premiums of the messages paying fees in a tipset
+type GasPremiumDistribution struct {
+	premiums []big.Int
+}
+
+func NewGasPremiumDistribution(premiums []big.Int) *GasPremiumDistribution {
+	sorted := make([]big.Int, 0, len(premiums))
+	for _, premium := range premiums {
+		if premium.Int == nil {
+			premium = big.Zero()
+		}
+		sorted = append(sorted, premium)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LessThan(sorted[j])
+	})
+	return &GasPremiumDistribution{premiums: sorted}
+}
+
+// Placement returns the position of the gas premium within the distribution
+func (d *GasPremiumDistribution) Placement(premium big.Int) FeeMarketPlacement {
+	if premium.Int == nil {
+		premium = big.Zero()
+	}
+
+	placement := FeeMarketPlacement{GasPremium: premium.String()}
+	if len(d.premiums) == 0 {
+		return placement
+	}
+
+	// index of the first premium greater than the given one
+	lowerOrEqual := sort.Search(len(d.premiums), func(i int) bool {
+		return d.premiums[i].GreaterThan(premium)
+	})
+	placement.GasPremiumPercentile = float64(lowerOrEqual) * 100 / float64(len(d.premiums))
+	return placement
+}
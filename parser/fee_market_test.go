@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasPremiumDistribution_Placement(t *testing.T) {
+	distribution := NewGasPremiumDistribution([]big.Int{big.NewInt(30), big.NewInt(10), big.NewInt(20), big.NewInt(20)})
+
+	tests := []struct {
+		name    string
+		premium big.Int
+		want    float64
+	}{
+		{name: "lowest premium", premium: big.NewInt(10), want: 25},
+		{name: "repeated premium", premium: big.NewInt(20), want: 75},
+		{name: "highest premium", premium: big.NewInt(30), want: 100},
+		{name: "premium below the distribution", premium: big.NewInt(1), want: 0},
+		{name: "nil premium", premium: big.Int{}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement := distribution.Placement(tt.premium)
+			require.Equal(t, tt.want, placement.GasPremiumPercentile)
+		})
+	}
+
+	empty := NewGasPremiumDistribution(nil)
+	require.Equal(t, FeeMarketPlacement{GasPremium: "10"}, empty.Placement(big.NewInt(10)))
+}
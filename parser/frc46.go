@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v15/verifreg"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"github.com/zondax/fil-parser/types"
+)
+
+var (
+	// UniversalReceiverHookMethodNum is the FRC-42 method number of the hook every token recipient implements
+	UniversalReceiverHookMethodNum = builtin.MustGenerateFRCMethodNum("Receive")
+	// FRC46TokenType is the receiver type the token actors use when calling the hook on a transfer
+	FRC46TokenType = verifreg.ReceiverType(builtin.MustGenerateFRCMethodNum("FRC46"))
+)
+
+// FRC46TokenReceived is the payload of the receiver hook of a FRC-46 transfer
+type FRC46TokenReceived struct {
+	Operator     abi.ActorID
+	From         abi.ActorID
+	To           abi.ActorID
+	Amount       abi.TokenAmount
+	OperatorData []byte
+	TokenData    []byte
+}
+
+func (t *FRC46TokenReceived) UnmarshalCBOR(r io.Reader) error {
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+	if extra != 6 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	for _, id := range []*abi.ActorID{&t.Operator, &t.From, &t.To} {
+		maj, extra, err = cr.ReadHeader()
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for actor id field")
+		}
+		*id = abi.ActorID(extra)
+	}
+
+	if err = t.Amount.UnmarshalCBOR(cr); err != nil {
+		return fmt.Errorf("unmarshaling t.Amount: %w", err)
+	}
+
+	for _, data := range []*[]byte{&t.OperatorData, &t.TokenData} {
+		if *data, err = cbg.ReadByteArray(cr, cbg.ByteArrayMaxLen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseFRC46TokenReceived decodes the FRC-46 payload of a receiver hook call. It returns false if the message
+// is not a receiver hook or the hook was not called because of a FRC-46 transfer.
+func ParseFRC46TokenReceived(msg *LotusMessage) (*FRC46TokenReceived, bool) {
+	if msg == nil || msg.Method != UniversalReceiverHookMethodNum || len(msg.Params) == 0 {
+		return nil, false
+	}
+
+	var params verifreg.UniversalReceiverParams
+	if err := params.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil || params.Type_ != FRC46TokenType {
+		return nil, false
+	}
+
+	var received FRC46TokenReceived
+	if err := received.UnmarshalCBOR(bytes.NewReader(params.Payload)); err != nil {
+		return nil, false
+	}
+	return &received, true
+}
+
+// NewTokenTransfer builds the normalized transfer of a FRC-46 receiver hook tx. The hook is called by the
+// token actor on the recipient, so the token is the sender of the hook. Failed hooks, and hooks reverted by
+// a failed parent call, revert the transfer and return nil, as any non FRC-46 message does.
+func NewTokenTransfer(msg *LotusMessage, rct *LotusMessageReceipt, tx *types.Transaction, reverted bool) *types.TokenTransfer {
+	if rct == nil || rct.ExitCode.IsError() || reverted || tx == nil {
+		return nil
+	}
+
+	received, ok := ParseFRC46TokenReceived(msg)
+	if !ok {
+		return nil
+	}
+
+	amount := received.Amount.Int
+	if amount == nil {
+		amount = abi.NewTokenAmount(0).Int
+	}
+
+	return &types.TokenTransfer{
+		TxId:       tx.Id,
+		TxCid:      tx.TxCid,
+		Height:     tx.Height,
+		TokenActor: msg.From.String(),
+		From:       idAddress(received.From),
+		To:         idAddress(received.To),
+		Operator:   idAddress(received.Operator),
+		Amount:     amount,
+	}
+}
+
+func idAddress(id abi.ActorID) string {
+	addr, err := address.NewIDAddress(uint64(id))
+	if err != nil {
+		return ""
+	}
+	return addr.String()
+}
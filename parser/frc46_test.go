@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin/v15/verifreg"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"github.com/zondax/fil-parser/types"
+)
+
+func encodeTokenReceived(t *testing.T, operator, from, to uint64, amount int64) []byte {
+	buf := new(bytes.Buffer)
+	cw := cbg.NewCborWriter(buf)
+	require.NoError(t, cw.WriteMajorTypeHeader(cbg.MajArray, 6))
+	for _, id := range []uint64{operator, from, to} {
+		require.NoError(t, cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, id))
+	}
+	tokenAmount := abi.NewTokenAmount(amount)
+	require.NoError(t, tokenAmount.MarshalCBOR(cw))
+	require.NoError(t, cbg.WriteByteArray(cw, []byte{}))
+	require.NoError(t, cbg.WriteByteArray(cw, []byte{}))
+	return buf.Bytes()
+}
+
+func receiverHookParams(t *testing.T, receiverType verifreg.ReceiverType, payload []byte) []byte {
+	buf := new(bytes.Buffer)
+	params := verifreg.UniversalReceiverParams{Type_: receiverType, Payload: payload}
+	require.NoError(t, params.MarshalCBOR(buf))
+	return buf.Bytes()
+}
+
+func TestNewTokenTransfer(t *testing.T) {
+	datacap, _ := address.NewIDAddress(7)
+	recipient, _ := address.NewIDAddress(1002)
+	tx := &types.Transaction{Id: "tx-id", TxCid: "tx-cid", TxBasicBlockData: types.TxBasicBlockData{BasicBlockData: types.BasicBlockData{Height: 10}}}
+	frc46Params := receiverHookParams(t, FRC46TokenType, encodeTokenReceived(t, 1001, 1000, 1002, 500))
+
+	tests := []struct {
+		name     string
+		msg      *LotusMessage
+		exitCode exitcode.ExitCode
+		reverted bool
+		want     *types.TokenTransfer
+	}{
+		{
+			name: "frc46 transfer",
+			msg:  &LotusMessage{From: datacap, To: recipient, Method: UniversalReceiverHookMethodNum, Params: frc46Params},
+			want: &types.TokenTransfer{
+				TxId:       "tx-id",
+				TxCid:      "tx-cid",
+				Height:     10,
+				TokenActor: "f07",
+				From:       "f01000",
+				To:         "f01002",
+				Operator:   "f01001",
+				Amount:     abi.NewTokenAmount(500).Int,
+			},
+		},
+		{
+			name:     "failed hook",
+			msg:      &LotusMessage{From: datacap, To: recipient, Method: UniversalReceiverHookMethodNum, Params: frc46Params},
+			exitCode: exitcode.ErrForbidden,
+		},
+		{
+			// the hook succeeded, but the call of the token actor that made it failed
+			name:     "failed parent call",
+			msg:      &LotusMessage{From: datacap, To: recipient, Method: UniversalReceiverHookMethodNum, Params: frc46Params},
+			reverted: true,
+		},
+		{
+			name: "other receiver type",
+			msg: &LotusMessage{From: datacap, To: recipient, Method: UniversalReceiverHookMethodNum,
+				Params: receiverHookParams(t, 1, encodeTokenReceived(t, 1001, 1000, 1002, 500))},
+		},
+		{
+			name: "other method",
+			msg:  &LotusMessage{From: datacap, To: recipient, Method: 2, Params: frc46Params},
+		},
+		{
+			name: "invalid payload",
+			msg: &LotusMessage{From: datacap, To: recipient, Method: UniversalReceiverHookMethodNum,
+				Params: receiverHookParams(t, FRC46TokenType, []byte{0x01})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewTokenTransfer(tt.msg, &LotusMessageReceipt{ExitCode: tt.exitCode}, tx, tt.reverted)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	filBig "github.com/filecoin-project/go-state-types/big"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/tools/fees"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	// GasOutputsSourceTrace means the fees are the gas outputs exposed by the trace, exactly as accounted by the node
+	GasOutputsSourceTrace = "trace"
+	// GasOutputsSourceComputed means the trace does not expose the gas outputs, so the fees are recomputed from the
+	// message, its receipt and the parent base fee of the tipset, see fees.ComputeFees
+	GasOutputsSourceComputed = "computed"
+)
+
+// HasGasOutputs returns whether the gas cost of a trace exposes all the gas outputs of the message
+func HasGasOutputs(gasCost types.MsgGasCost) bool {
+	for _, amount := range []filBig.Int{gasCost.BaseFeeBurn, gasCost.OverEstimationBurn, gasCost.MinerPenalty, gasCost.MinerTip,
+		gasCost.Refund, gasCost.TotalCost} {
+		if amount.Int == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveGasOutputs returns where the gas outputs of a trace come from. The outputs exposed by the trace are
+// preferred; otherwise they are recomputed and set on the gas cost. It returns an empty source if they can not
+// be recomputed either, e.g. for implicit messages, which pay no gas, or if the parent base fee is unknown.
+func ResolveGasOutputs(gasCost *types.MsgGasCost, msg *filTypes.Message, receipt *filTypes.MessageReceipt, parentBaseFee abi.TokenAmount) string {
+	if HasGasOutputs(*gasCost) {
+		return GasOutputsSourceTrace
+	}
+	if msg == nil || receipt == nil || parentBaseFee.Int == nil || IsImplicitMessage(msg.From) {
+		return ""
+	}
+
+	outputs := fees.ComputeFees(msg, receipt, parentBaseFee)
+	gasCost.GasUsed = filBig.NewInt(receipt.GasUsed)
+	gasCost.BaseFeeBurn = outputs.BaseFeeBurn
+	gasCost.OverEstimationBurn = outputs.OverEstimationBurn
+	gasCost.MinerPenalty = outputs.MinerPenalty
+	gasCost.MinerTip = outputs.MinerTip
+	gasCost.Refund = outputs.Refund
+	gasCost.TotalCost = outputs.TotalCost()
+	return GasOutputsSourceComputed
+}
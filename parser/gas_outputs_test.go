@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestResolveGasOutputs(t *testing.T) {
+	sender, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	msg := &filTypes.Message{From: sender, GasLimit: 100, GasFeeCap: big.NewInt(8), GasPremium: big.NewInt(5)}
+	receipt := &filTypes.MessageReceipt{GasUsed: 100}
+	baseFee := big.NewInt(10)
+
+	// the outputs of the trace are kept, even if they do not match the recomputed ones
+	exposed := types.MsgGasCost{BaseFeeBurn: big.NewInt(1), OverEstimationBurn: big.Zero(), MinerPenalty: big.Zero(),
+		MinerTip: big.Zero(), Refund: big.Zero(), TotalCost: big.NewInt(1)}
+	gasCost := exposed
+	require.True(t, HasGasOutputs(gasCost))
+	require.Equal(t, GasOutputsSourceTrace, ResolveGasOutputs(&gasCost, msg, receipt, baseFee))
+	require.Equal(t, exposed, gasCost)
+
+	// the fee cap is below the base fee, so the miner is penalized
+	gasCost = types.MsgGasCost{}
+	require.False(t, HasGasOutputs(gasCost))
+	require.Equal(t, GasOutputsSourceComputed, ResolveGasOutputs(&gasCost, msg, receipt, baseFee))
+	require.Equal(t, big.NewInt(800), gasCost.BaseFeeBurn)
+	require.Equal(t, big.NewInt(200), gasCost.MinerPenalty)
+	require.Equal(t, big.NewInt(800), gasCost.TotalCost)
+	require.Equal(t, big.NewInt(100), gasCost.GasUsed)
+
+	// implicit messages and unknown base fees can not be recomputed
+	gasCost = types.MsgGasCost{}
+	implicit := &filTypes.Message{From: builtin.SystemActorAddr}
+	require.Empty(t, ResolveGasOutputs(&gasCost, implicit, receipt, baseFee))
+	require.Empty(t, ResolveGasOutputs(&gasCost, msg, receipt, big.Int{}))
+	require.False(t, HasGasOutputs(gasCost))
+}
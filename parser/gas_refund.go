@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"encoding/json"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/zondax/fil-parser/types"
+)
+
+// GasRefundMetadata explains the refund of a message. Before execution the sender locks GasLimit * GasFeeCap,
+// after it the unused part is returned, so Reserved - Amount is always the Charged amount of the fee tx.
+type GasRefundMetadata struct {
+	RefundAddress string
+	Reserved      string
+	Charged       string
+	Amount        string
+}
+
+// NewGasRefundTx builds the tx returning the unused gas to the sender of the message of the fee tx. The refund
+// is not part of the fee amount, so it must not be credited to the sender on top of the fee tx.
+func NewGasRefundTx(feeTx *types.Transaction, id string, gasLimit int64, gasFeeCap, refund filBig.Int) *types.Transaction {
+	reserved := filBig.Mul(filBig.NewInt(gasLimit), gasFeeCap)
+	charged := filBig.Zero()
+	if feeTx.Amount != nil {
+		charged = filBig.NewFromGo(feeTx.Amount)
+	}
+
+	metadata, _ := json.Marshal(GasRefundMetadata{
+		RefundAddress: feeTx.TxFrom,
+		Reserved:      reserved.String(),
+		Charged:       charged.String(),
+		Amount:        refund.String(),
+	})
+
+	return &types.Transaction{
+		TxBasicBlockData: feeTx.TxBasicBlockData,
+		Id:               id,
+		ParentId:         feeTx.ParentId,
+		TxTimestamp:      feeTx.TxTimestamp,
+		TxCid:            feeTx.TxCid,
+		TxTo:             feeTx.TxFrom,
+		Amount:           refund.Int,
+		Status:           "Ok",
+		TxType:           GasRefundOp,
+		TxMetadata:       string(metadata),
+	}
+}
@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestNewGasRefundTx(t *testing.T) {
+	feeTx := &types.Transaction{
+		TxBasicBlockData: types.TxBasicBlockData{BasicBlockData: types.BasicBlockData{Height: 10, TipsetCid: "tipset"}, BlockCid: "block"},
+		Id:               "fee",
+		ParentId:         "main",
+		TxCid:            "cid1",
+		TxFrom:           "f01001",
+		Amount:           big.NewInt(600),
+		TxType:           TotalFeeOp,
+	}
+
+	refundTx := NewGasRefundTx(feeTx, "refund", 100, filBig.NewInt(10), filBig.NewInt(400))
+	require.Equal(t, "refund", refundTx.Id)
+	require.Equal(t, "main", refundTx.ParentId)
+	require.Equal(t, feeTx.TxBasicBlockData, refundTx.TxBasicBlockData)
+	require.Equal(t, "f01001", refundTx.TxTo)
+	require.Empty(t, refundTx.TxFrom)
+	require.Equal(t, GasRefundOp, refundTx.TxType)
+	require.Equal(t, int64(400), refundTx.Amount.Int64())
+
+	var metadata GasRefundMetadata
+	require.NoError(t, json.Unmarshal([]byte(refundTx.TxMetadata), &metadata))
+	require.Equal(t, GasRefundMetadata{RefundAddress: "f01001", Reserved: "1000", Charged: "600", Amount: "400"}, metadata)
+}
@@ -22,14 +22,15 @@ import (
 	"github.com/filecoin-project/go-state-types/builtin/v12/reward"
 	"github.com/filecoin-project/go-state-types/builtin/v12/verifreg"
 	"github.com/filecoin-project/go-state-types/manifest"
-	"github.com/filecoin-project/lotus/api"
 	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/ipfs/go-cid"
 	"github.com/zondax/fil-parser/actors/cache"
 	logger2 "github.com/zondax/fil-parser/logger"
 	"github.com/zondax/fil-parser/parser"
-	rosettaFilecoinLib "github.com/zondax/rosetta-filecoin-lib"
+	"github.com/zondax/golem/pkg/zcache"
 	"github.com/zondax/rosetta-filecoin-lib/actors"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	// The following import is necessary to ensure that the init() function
@@ -66,33 +67,145 @@ var allMethods = map[string]map[abi.MethodNum]builtin.MethodMeta{
 }
 
 type Helper struct {
-	lib        *rosettaFilecoinLib.RosettaConstructionFilecoin
-	node       api.FullNode
-	actorCache *cache.ActorsCache
-	logger     *zap.Logger
+	lib             *FilecoinLib
+	node            types.FullNode
+	actorCache      *cache.ActorsCache
+	sectorInfoCache zcache.ZCache
+	minerInfoCache  zcache.ZCache
+	config          parser.FilecoinParserConfig
+	unknownMethods  *parser.UnknownMethodsTracker
+	eventSchemas    *parser.EventSchemaRegistry
+	tracer          trace.Tracer
+	logger          *zap.Logger
+	// addressLookups is the AddressLookupTimeout budget of the height being parsed
+	addressLookups *parser.StageBudget
 }
 
-func NewHelper(lib *rosettaFilecoinLib.RosettaConstructionFilecoin, actorsCache *cache.ActorsCache, node api.FullNode, logger *zap.Logger) *Helper {
-	return &Helper{lib: lib, actorCache: actorsCache, node: node, logger: logger2.GetSafeLogger(logger)}
+func NewHelper(lib *FilecoinLib, actorsCache *cache.ActorsCache, node types.FullNode, logger *zap.Logger,
+	config parser.FilecoinParserConfig) *Helper {
+	logger = logger2.GetSafeLogger(logger)
+	h := &Helper{lib: lib, actorCache: actorsCache, node: node, config: config, unknownMethods: parser.NewUnknownMethodsTracker(),
+		tracer: parser.NewTracer(nil), logger: logger}
+
+	if config.EnrichSectorInfo {
+		var err error
+		if h.sectorInfoCache, err = zcache.NewLocalCache(&zcache.LocalConfig{Prefix: sectorInfoCachePrefix, Logger: logger}); err != nil {
+			logger.Sugar().Errorf("could not create sector info cache, sector info will not be cached: %s", err)
+		}
+	}
+	if config.EnrichMinerInfo {
+		var err error
+		if h.minerInfoCache, err = zcache.NewLocalCache(&zcache.LocalConfig{Prefix: minerInfoCachePrefix, Logger: logger}); err != nil {
+			logger.Sugar().Errorf("could not create miner info cache, miner info will not be cached: %s", err)
+		}
+	}
+
+	return h
+}
+
+func (h *Helper) GetConfig() parser.FilecoinParserConfig {
+	return h.config
 }
 
 func (h *Helper) GetActorsCache() *cache.ActorsCache {
 	return h.actorCache
 }
 
-func (h *Helper) GetFilecoinLib() *rosettaFilecoinLib.RosettaConstructionFilecoin {
+func (h *Helper) GetFilecoinLib() *FilecoinLib {
 	return h.lib
 }
 
-func (h *Helper) GetFilecoinNodeClient() api.FullNode {
+func (h *Helper) GetFilecoinNodeClient() types.FullNode {
 	return h.node
 }
 
+func (h *Helper) GetUnknownMethods() *parser.UnknownMethodsTracker {
+	return h.unknownMethods
+}
+
+// SetEventSchemas sets the registry used to decode the native events of user actors
+func (h *Helper) SetEventSchemas(schemas *parser.EventSchemaRegistry) {
+	h.eventSchemas = schemas
+}
+
+// GetEventSchemas returns the registry of event schemas, or nil if none was set
+func (h *Helper) GetEventSchemas() *parser.EventSchemaRegistry {
+	return h.eventSchemas
+}
+
+// SetTracer sets the tracer used to record the spans of the parse, also on the actors cache lookups
+func (h *Helper) SetTracer(tracer trace.Tracer) {
+	h.tracer = tracer
+	if h.actorCache != nil {
+		h.actorCache.SetTracer(tracer)
+	}
+}
+
+// GetTracer returns the tracer of the parse, a no-op one if tracing is disabled
+func (h *Helper) GetTracer() trace.Tracer {
+	return h.tracer
+}
+
+// RecordUnknownMethod adds the call to the unknown methods telemetry, resolving the actor of the receiver
+func (h *Helper) RecordUnknownMethod(msg *parser.LotusMessage, reason string, txCid string, height int64, key filTypes.TipSetKey) {
+	h.RecordUnknownMethodWithContext(context.Background(), msg, reason, txCid, height, key)
+}
+
+// RecordUnknownMethodWithContext is RecordUnknownMethod, cancelling the on-chain lookups once the context is done
+func (h *Helper) RecordUnknownMethodWithContext(ctx context.Context, msg *parser.LotusMessage, reason string, txCid string, height int64,
+	key filTypes.TipSetKey) {
+	if msg == nil {
+		return
+	}
+
+	actorCid, err := h.actorCache.GetActorCodeWithContext(ctx, msg.To, key, false)
+	if err != nil {
+		actorCid = parser.UnknownStr
+	}
+	actorName, _ := h.GetActorNameFromAddressWithContext(ctx, msg.To, height, key)
+
+	h.unknownMethods.Record(actorName, actorCid, uint64(msg.Method), reason, txCid, uint64(height))
+}
+
+// StartAddressLookups starts the AddressLookupTimeout budget of the address lookups of a height, see
+// LookupAddressInfo
+func (h *Helper) StartAddressLookups() {
+	h.addressLookups = parser.NewStageBudget(h.config.AddressLookupTimeout)
+}
+
+// AddressLookupsExceeded returns true if the AddressLookupTimeout budget of the height ran out, so some addresses
+// were not looked up
+func (h *Helper) AddressLookupsExceeded() bool {
+	return h.addressLookups.Exceeded()
+}
+
+// LookupAddressInfo is GetActorAddressInfoWithContext within the AddressLookupTimeout budget of the height. Once
+// the budget runs out no lookup is made and false is returned.
+func (h *Helper) LookupAddressInfo(ctx context.Context, add address.Address, key filTypes.TipSetKey) (*types.AddressInfo, bool) {
+	if h.addressLookups.Exceeded() {
+		return nil, false
+	}
+	ctx, cancel := h.addressLookups.Context(ctx)
+	defer cancel()
+
+	info := h.GetActorAddressInfoWithContext(ctx, add, key)
+	if h.addressLookups.Exceeded() {
+		// the lookups may have been cut short, so the info is not complete
+		return nil, false
+	}
+	return info, true
+}
+
 func (h *Helper) GetActorAddressInfo(add address.Address, key filTypes.TipSetKey) *types.AddressInfo {
+	return h.GetActorAddressInfoWithContext(context.Background(), add, key)
+}
+
+// GetActorAddressInfoWithContext is GetActorAddressInfo, cancelling the on-chain lookups once the context is done
+func (h *Helper) GetActorAddressInfoWithContext(ctx context.Context, add address.Address, key filTypes.TipSetKey) *types.AddressInfo {
 	var err error
 	addInfo := &types.AddressInfo{}
 
-	addInfo.ActorCid, err = h.actorCache.GetActorCode(add, key, false)
+	addInfo.ActorCid, err = h.actorCache.GetActorCodeWithContext(ctx, add, key, false)
 	if err != nil {
 		h.logger.Sugar().Errorf("could not get actor code from address. Err: %s", err)
 	} else {
@@ -103,7 +216,7 @@ func (h *Helper) GetActorAddressInfo(add address.Address, key filTypes.TipSetKey
 		addInfo.ActorType, _ = h.lib.BuiltinActors.GetActorNameFromCid(c)
 	}
 
-	addInfo.Short, err = h.actorCache.GetShortAddress(add)
+	addInfo.Short, err = h.actorCache.GetShortAddressWithContext(ctx, add)
 	if err != nil {
 		h.logger.Sugar().Errorf("could not get short address for %s. Err: %v", add.String(), err)
 	}
@@ -113,19 +226,37 @@ func (h *Helper) GetActorAddressInfo(add address.Address, key filTypes.TipSetKey
 		return addInfo
 	}
 
-	addInfo.Robust, err = h.actorCache.GetRobustAddress(add)
+	addInfo.Robust, err = h.actorCache.GetRobustAddressWithContext(ctx, add)
 	if err != nil {
 		h.logger.Sugar().Errorf("could not get robust address for %s. Err: %v", add.String(), err)
 	}
 
+	// Delegated addresses have an eth address, use the same format as the eam returns
+	if h.config.IsFeatureEnabled(parser.FeatureChecksumEthAddresses) {
+		if robust, err := address.NewFromString(addInfo.Robust); err == nil && robust.Protocol() == address.Delegated {
+			ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(robust)
+			if err != nil {
+				h.logger.Sugar().Errorf("could not get eth address for %s. Err: %v", robust.String(), err)
+			} else {
+				addInfo.EthAddress = h.config.FormatEthAddress(ethAddr)
+			}
+		}
+	}
+
 	return addInfo
 }
 
 func (h *Helper) GetActorNameFromAddress(address address.Address, height int64, key filTypes.TipSetKey) (string, error) {
+	return h.GetActorNameFromAddressWithContext(context.Background(), address, height, key)
+}
+
+// GetActorNameFromAddressWithContext is GetActorNameFromAddress, cancelling the on-chain lookups once the context
+// is done
+func (h *Helper) GetActorNameFromAddressWithContext(ctx context.Context, address address.Address, height int64, key filTypes.TipSetKey) (string, error) {
 	onChainOnly := false
 	for {
 		// Search for actor in cache
-		actorCode, err := h.actorCache.GetActorCode(address, key, onChainOnly)
+		actorCode, err := h.actorCache.GetActorCodeWithContext(ctx, address, key, onChainOnly)
 		if err != nil {
 			return actors.UnknownStr, err
 		}
@@ -150,6 +281,11 @@ func (h *Helper) GetActorNameFromAddress(address address.Address, height int64,
 }
 
 func (h *Helper) GetMethodName(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (string, error) {
+	return h.GetMethodNameWithContext(context.Background(), msg, height, key)
+}
+
+// GetMethodNameWithContext is GetMethodName, cancelling the on-chain lookups once the context is done
+func (h *Helper) GetMethodNameWithContext(ctx context.Context, msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (string, error) {
 
 	if msg == nil {
 		return "", errors.New("malformed value")
@@ -165,7 +301,7 @@ func (h *Helper) GetMethodName(msg *parser.LotusMessage, height int64, key filTy
 		return parser.MethodConstructor, nil
 	}
 
-	actorName, _ := h.GetActorNameFromAddress(msg.To, height, key)
+	actorName, _ := h.GetActorNameFromAddressWithContext(ctx, msg.To, height, key)
 
 	actorMethods, ok := allMethods[actorName]
 	if !ok {
@@ -211,9 +347,9 @@ func (h *Helper) FilterTxsByActorType(ctx context.Context, txs []*types.Transact
 	return result, nil
 }
 
-func (h *Helper) isAnyAddressOfType(_ context.Context, addresses []address.Address, height int64, key filTypes.TipSetKey, actorType string) (bool, error) {
+func (h *Helper) isAnyAddressOfType(ctx context.Context, addresses []address.Address, height int64, key filTypes.TipSetKey, actorType string) (bool, error) {
 	for _, addr := range addresses {
-		actorName, err := h.GetActorNameFromAddress(addr, height, key)
+		actorName, err := h.GetActorNameFromAddressWithContext(ctx, addr, height, key)
 		if err != nil {
 			return false, err
 		}
@@ -223,3 +359,28 @@ func (h *Helper) isAnyAddressOfType(_ context.Context, addresses []address.Addre
 	}
 	return false, nil
 }
+
+// ValidateAddresses checks the address info entries against the chain state of the tipset and the known actors
+// bundles, see parser.ValidateAddressInfo. The robust addresses are resolved with the node at the tipset, falling
+// back to the actors cache without a node. It stops once the context is done, returning the violations found so far.
+func (h *Helper) ValidateAddresses(ctx context.Context, addresses *types.AddressInfoMap, key filTypes.TipSetKey) ([]types.AddressViolation, error) {
+	resolvers := parser.AddressResolvers{}
+	switch {
+	case h.node != nil:
+		resolvers.ShortAddress = func(addr address.Address) (string, error) {
+			short, err := h.node.StateLookupID(ctx, addr, key)
+			if err != nil {
+				return "", err
+			}
+			return short.String(), nil
+		}
+	case h.actorCache != nil:
+		resolvers.ShortAddress = func(addr address.Address) (string, error) {
+			return h.actorCache.GetShortAddressWithContext(ctx, addr)
+		}
+	}
+	if h.lib != nil {
+		resolvers.ActorName = h.lib.BuiltinActors.GetActorNameFromCid
+	}
+	return parser.ValidateAddressesWithContext(ctx, addresses, address.CurrentNetwork, resolvers)
+}
@@ -0,0 +1,70 @@
+package helper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api/mocks"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/golang/mock/gomock"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+// TestAllMethodsRegistered checks that every method name the parser can generate is part of the tx types registry
+func TestAllMethodsRegistered(t *testing.T) {
+	for actorName, methods := range allMethods {
+		txTypes, err := parser.GetTxTypesByActor(actorName)
+		require.NoError(t, err, "actor %s is not part of the tx types registry", actorName)
+
+		for _, method := range methods {
+			require.Contains(t, txTypes, method.Name, "method %s of actor %s is not part of the tx types registry", method.Name, actorName)
+		}
+	}
+}
+
+// TestHelper_ValidateAddresses checks that the robust addresses are resolved at the parsed tipset
+func TestHelper_ValidateAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	node := mocks.NewMockFullNode(ctrl)
+
+	robust, err := address.NewSecp256k1Address([]byte("robust"))
+	require.NoError(t, err)
+	short, err := address.NewIDAddress(1234)
+	require.NoError(t, err)
+	addresses := types.NewAddressInfoMap()
+	addresses.Set("f01234", &types.AddressInfo{Short: "f01234", Robust: robust.String()})
+	addresses.Set("f05678", &types.AddressInfo{Short: "f05678", Robust: robust.String()})
+
+	c, err := cid.Parse("bafy2bzacecnamqgqmifpluoeldx7zzglxcljo6oja4vrmtj7432rphldpdmm2")
+	require.NoError(t, err)
+	key := filTypes.NewTipSetKey(c)
+	node.EXPECT().StateLookupID(gomock.Any(), robust, key).Return(short, nil).Times(2)
+
+	h := NewHelper(nil, nil, node, zap.NewNop(), parser.FilecoinParserConfig{})
+	violations, err := h.ValidateAddresses(context.Background(), addresses, key)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	require.Equal(t, "f05678", violations[0].Short)
+	require.Equal(t, parser.AddressViolationShortMismatch, violations[0].Kind)
+}
+
+func TestHelper_LookupAddressInfo_Budget(t *testing.T) {
+	add, err := address.NewIDAddress(1234)
+	require.NoError(t, err)
+
+	// the helper has no actors cache, so any lookup would panic
+	h := NewHelper(nil, nil, nil, zap.NewNop(), parser.FilecoinParserConfig{AddressLookupTimeout: time.Nanosecond})
+	require.False(t, h.AddressLookupsExceeded(), "the budget is started per height")
+	h.StartAddressLookups()
+	time.Sleep(time.Millisecond)
+	require.True(t, h.AddressLookupsExceeded())
+	info, ok := h.LookupAddressInfo(context.Background(), add, filTypes.EmptyTSK)
+	require.False(t, ok)
+	require.Nil(t, info)
+}
@@ -0,0 +1,30 @@
+// schemagen writes the JSON Schemas of the tx metadata embedded in the parser package
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/zondax/fil-parser/actors"
+	"github.com/zondax/fil-parser/parser/helper"
+)
+
+func main() {
+	output := flag.String("o", "metadata_schemas.json", "output file")
+	flag.Parse()
+
+	schemas, err := helper.GenerateMetadataSchemas(actors.MetadataValueTypes)
+	if err != nil {
+		log.Fatalf("could not generate metadata schemas: %s", err)
+	}
+
+	data, err := helper.EncodeMetadataSchemas(schemas)
+	if err != nil {
+		log.Fatalf("could not encode metadata schemas: %s", err)
+	}
+
+	if err = os.WriteFile(*output, data, 0o644); err != nil {
+		log.Fatalf("could not write %s: %s", *output, err)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !nonode
+
+package helper
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/types"
+	rosettaFilecoinLib "github.com/zondax/rosetta-filecoin-lib"
+)
+
+// FilecoinLib is the rosetta filecoin lib. Building with the nonode tag replaces it with the part of it that
+// does not import the lotus api, see lotus_api_nonode.go.
+type FilecoinLib = rosettaFilecoinLib.RosettaConstructionFilecoin
+
+// lookupMinerInfo gets the addresses of the miner at the given tipset from the node
+func (h *Helper) lookupMinerInfo(ctx context.Context, minerAddr address.Address, key filTypes.TipSetKey) (*types.MinerInfo, error) {
+	onChainInfo, err := h.node.StateMinerInfo(ctx, minerAddr, key)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &types.MinerInfo{
+		Owner:            onChainInfo.Owner.String(),
+		Worker:           onChainInfo.Worker.String(),
+		ControlAddresses: make([]string, 0, len(onChainInfo.ControlAddresses)),
+	}
+	for _, addr := range onChainInfo.ControlAddresses {
+		info.ControlAddresses = append(info.ControlAddresses, addr.String())
+	}
+	if onChainInfo.Beneficiary != address.Undef {
+		info.Beneficiary = onChainInfo.Beneficiary.String()
+	}
+	return info, nil
+}
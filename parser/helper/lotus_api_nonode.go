@@ -0,0 +1,32 @@
+//go:build nonode
+
+package helper
+
+import (
+	"context"
+	"errors"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types"
+	"github.com/zondax/rosetta-filecoin-lib/actors"
+)
+
+// errNotInNonodeBuild is returned by the lookups that need the lotus api, which nonode builds do not import
+var errNotInNonodeBuild = errors.New("not available in nonode builds")
+
+// FilecoinLib is the part of the rosetta filecoin lib used by the parser that does not import the lotus api.
+// BuiltinActors must hold the actor cids of the network, e.g. from its actors bundle.
+type FilecoinLib struct {
+	BuiltinActors actors.BuiltinActors
+}
+
+// ParseParamsMultisigTx always fails, the params of the txs proposed to multisigs are not decoded in nonode builds
+func (l *FilecoinLib) ParseParamsMultisigTx(_ string, _ cid.Cid) (string, error) {
+	return "", errNotInNonodeBuild
+}
+
+func (h *Helper) lookupMinerInfo(_ context.Context, _ address.Address, _ filTypes.TipSetKey) (*types.MinerInfo, error) {
+	return nil, errNotInNonodeBuild
+}
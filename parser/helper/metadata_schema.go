@@ -0,0 +1,305 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/lotus/chain/actors"
+	lotusBuiltin "github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/parser"
+)
+
+// AnyMethod is the tx type of the MetadataValueTypes that apply to every method of an actor
+const AnyMethod = "*"
+
+// MethodValueTypes are the types of the Params and Return values that a parser writes to the metadata.
+// A nil type keeps the type declared by go-state-types.
+type MethodValueTypes struct {
+	Params reflect.Type
+	Return reflect.Type
+	// MethodNum is the method number of the tx types not declared by go-state-types (e.g. Send)
+	MethodNum abi.MethodNum
+}
+
+// GenerateMetadataSchemas builds the JSON Schemas of the metadata of every method known by go-state-types.
+// The schema of a method accepts the values of all the actors versions since v8, as older heights are decoded
+// with the types of their own version. The explicit parsers of the actors package do not always write the
+// declared types, so valueTypes overrides them by actor name and tx type (or AnyMethod).
+// The result is embedded in the parser package and returned by parser.MetadataSchemas.
+func GenerateMetadataSchemas(valueTypes map[string]map[string]MethodValueTypes) ([]parser.MetadataSchema, error) {
+	type methodSchema struct {
+		methodNum abi.MethodNum
+		params    parser.JSONSchema
+		ret       parser.JSONSchema
+	}
+
+	methods := make(map[string]map[string]*methodSchema)
+	for av := actorstypes.Version8; av <= actorstypes.Version(actors.LatestVersion); av++ {
+		versionMethods, err := versionedMethods(av)
+		if err != nil {
+			return nil, err
+		}
+		for actorName, actorMethods := range versionMethods {
+			if methods[actorName] == nil {
+				methods[actorName] = make(map[string]*methodSchema)
+			}
+			for methodNum, meta := range actorMethods {
+				if meta.Method == nil {
+					continue
+				}
+
+				methodType := reflect.TypeOf(meta.Method)
+				if methodType.Kind() != reflect.Func || methodType.NumIn() != 1 || methodType.NumOut() != 1 {
+					return nil, fmt.Errorf("unexpected signature for method %s of actor %s", meta.Name, actorName)
+				}
+
+				params, ret := methodValueSchema(methodType.In(0)), methodValueSchema(methodType.Out(0))
+				method, ok := methods[actorName][meta.Name]
+				if !ok {
+					methods[actorName][meta.Name] = &methodSchema{methodNum: methodNum, params: params, ret: ret}
+					continue
+				}
+				method.methodNum = methodNum
+				method.params = mergeJSONSchemas(method.params, params)
+				method.ret = mergeJSONSchemas(method.ret, ret)
+			}
+		}
+	}
+
+	for actorName, actorValueTypes := range valueTypes {
+		for txType, types := range actorValueTypes {
+			targets := make([]*methodSchema, 0)
+			method, ok := methods[actorName][txType]
+			switch {
+			case txType == AnyMethod:
+				for _, method := range methods[actorName] {
+					targets = append(targets, method)
+				}
+			case ok:
+				targets = append(targets, method)
+			default:
+				if methods[actorName] == nil {
+					methods[actorName] = make(map[string]*methodSchema)
+				}
+				method = &methodSchema{methodNum: types.MethodNum}
+				methods[actorName][txType] = method
+				targets = append(targets, method)
+			}
+
+			for _, method := range targets {
+				if types.Params != nil {
+					method.params = methodValueSchema(types.Params)
+				}
+				if types.Return != nil {
+					method.ret = methodValueSchema(types.Return)
+				}
+			}
+		}
+	}
+
+	schemas := make([]parser.MetadataSchema, 0)
+	for actorName, actorMethods := range methods {
+		for txType, method := range actorMethods {
+			schema := parser.NewMetadataSchema(fmt.Sprintf("%s.%s", actorName, txType), method.params, method.ret)
+			raw, err := json.Marshal(schema)
+			if err != nil {
+				return nil, fmt.Errorf("could not encode schema of method %s of actor %s: %w", txType, actorName, err)
+			}
+
+			schemas = append(schemas, parser.MetadataSchema{
+				Actor:     actorName,
+				TxType:    txType,
+				MethodNum: uint64(method.methodNum),
+				Schema:    raw,
+			})
+		}
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].Actor != schemas[j].Actor {
+			return schemas[i].Actor < schemas[j].Actor
+		}
+		if schemas[i].MethodNum != schemas[j].MethodNum {
+			return schemas[i].MethodNum < schemas[j].MethodNum
+		}
+		return schemas[i].TxType < schemas[j].TxType
+	})
+	return schemas, nil
+}
+
+// versionedMethods returns the methods of the actors of allMethods in the actors version
+func versionedMethods(av actorstypes.Version) (map[string]map[abi.MethodNum]builtin.MethodMeta, error) {
+	codeIDs, err := actors.GetActorCodeIDs(av)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the actor codes of actors version %d: %w", av, err)
+	}
+
+	byCode := make(map[cid.Cid]map[abi.MethodNum]builtin.MethodMeta)
+	for _, entry := range lotusBuiltin.MakeRegistry(av) {
+		byCode[entry.Code()] = entry.Exports()
+	}
+
+	result := make(map[string]map[abi.MethodNum]builtin.MethodMeta)
+	for actorName := range allMethods {
+		// see allMethods
+		codeActor := actorName
+		if actorName == manifest.PlaceholderKey || actorName == manifest.EthAccountKey {
+			codeActor = manifest.EvmKey
+		}
+		code, ok := codeIDs[codeActor]
+		if !ok {
+			// the actor does not exist in this version
+			continue
+		}
+		result[actorName] = byCode[code]
+	}
+	return result, nil
+}
+
+// methodValueSchema returns the schema of the decoded value of the type, as done by decodeMethodValue
+func methodValueSchema(valueType reflect.Type) parser.JSONSchema {
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	if valueType == emptyValueType {
+		return nil
+	}
+	return parser.NewJSONSchema(valueType)
+}
+
+// mergeJSONSchemas returns a schema accepting the values of both schemas. Object schemas are merged property
+// by property, keeping as required only the properties required by both; other schemas are combined with anyOf.
+// A nil schema (an empty value) accepts anything, as the key is then missing from the metadata.
+func mergeJSONSchemas(a, b parser.JSONSchema) parser.JSONSchema {
+	if a == nil {
+		return b
+	}
+	if b == nil || reflect.DeepEqual(a, b) {
+		return a
+	}
+
+	alternatives := schemaAlternatives(a)
+	for _, alternative := range schemaAlternatives(b) {
+		merged := false
+		for i, existing := range alternatives {
+			if reflect.DeepEqual(existing, alternative) {
+				merged = true
+				break
+			}
+			if isObjectSchema(existing) && isObjectSchema(alternative) {
+				alternatives[i] = mergeObjectSchemas(existing, alternative)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			alternatives = append(alternatives, alternative)
+		}
+	}
+
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	anyOf := make([]interface{}, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		anyOf = append(anyOf, alternative)
+	}
+	return parser.JSONSchema{"anyOf": anyOf}
+}
+
+func mergeObjectSchemas(a, b parser.JSONSchema) parser.JSONSchema {
+	aProperties, bProperties := schemaProperties(a), schemaProperties(b)
+	properties := make(map[string]interface{}, len(aProperties))
+	for name, property := range aProperties {
+		properties[name] = property
+	}
+	for name, property := range bProperties {
+		if existing, ok := properties[name]; ok {
+			properties[name] = mergeJSONSchemas(asJSONSchema(existing), asJSONSchema(property))
+			continue
+		}
+		properties[name] = property
+	}
+
+	bRequired := make(map[string]bool)
+	for _, name := range schemaRequired(b) {
+		bRequired[name] = true
+	}
+	required := make([]string, 0)
+	for _, name := range schemaRequired(a) {
+		if bRequired[name] {
+			required = append(required, name)
+		}
+	}
+
+	schema := parser.JSONSchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func schemaAlternatives(schema parser.JSONSchema) []parser.JSONSchema {
+	anyOf, ok := schema["anyOf"].([]interface{})
+	if !ok || len(schema) != 1 {
+		return []parser.JSONSchema{schema}
+	}
+	alternatives := make([]parser.JSONSchema, 0, len(anyOf))
+	for _, alternative := range anyOf {
+		alternatives = append(alternatives, asJSONSchema(alternative))
+	}
+	return alternatives
+}
+
+func isObjectSchema(schema parser.JSONSchema) bool {
+	_, ok := schema["properties"]
+	return schema["type"] == "object" && ok
+}
+
+func schemaProperties(schema parser.JSONSchema) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	return properties
+}
+
+func schemaRequired(schema parser.JSONSchema) []string {
+	required, _ := schema["required"].([]string)
+	return required
+}
+
+func asJSONSchema(value interface{}) parser.JSONSchema {
+	switch schema := value.(type) {
+	case parser.JSONSchema:
+		return schema
+	case map[string]interface{}:
+		return schema
+	}
+	return nil
+}
+
+// EncodeMetadataSchemas encodes the schemas as a json array with one schema per line, so changes
+// between versions are easy to review.
+func EncodeMetadataSchemas(schemas []parser.MetadataSchema) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("[\n")
+	for i, schema := range schemas {
+		raw, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(raw)
+		if i < len(schemas)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("]\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,88 @@
+package helper
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+)
+
+func TestGenerateMetadataSchemas(t *testing.T) {
+	raw, ok := parser.GetMetadataSchema(manifest.MinerKey, parser.MethodChangeWorkerAddress)
+	require.True(t, ok)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &schema))
+	require.Equal(t, parser.JSONSchemaDraft, schema["$schema"])
+	require.Equal(t, "storageminer.ChangeWorkerAddress", schema["title"])
+
+	properties := schema["properties"].(map[string]interface{})
+	// ChangeWorkerAddress returns an empty value, which is not part of the metadata
+	require.Contains(t, properties, parser.ParamsKey)
+	require.NotContains(t, properties, parser.ReturnKey)
+
+	params := properties[parser.ParamsKey].(map[string]interface{})
+	require.ElementsMatch(t, []interface{}{"NewWorker", "NewControlAddrs"}, params["required"])
+
+	_, ok = parser.GetMetadataSchema(manifest.MinerKey, "NotAMethod")
+	require.False(t, ok)
+}
+
+func TestGenerateMetadataSchemas_ValueTypes(t *testing.T) {
+	schemas, err := GenerateMetadataSchemas(map[string]map[string]MethodValueTypes{
+		manifest.MarketKey: {
+			parser.MethodSend:            {Params: reflect.TypeOf([]byte(nil))},
+			"OldMethod":                  {Params: reflect.TypeOf(""), MethodNum: 99},
+			parser.MethodWithdrawBalance: {Return: reflect.TypeOf("")},
+		},
+	})
+	require.NoError(t, err)
+
+	properties := func(txType string) map[string]interface{} {
+		for _, schema := range schemas {
+			if schema.Actor == manifest.MarketKey && schema.TxType == txType {
+				var decoded map[string]interface{}
+				require.NoError(t, json.Unmarshal(schema.Schema, &decoded))
+				return decoded["properties"].(map[string]interface{})
+			}
+		}
+		require.Failf(t, "missing schema", "tx type %s", txType)
+		return nil
+	}
+
+	// the params keep the type of go-state-types and the return is overridden
+	withdraw := properties(parser.MethodWithdrawBalance)
+	require.Equal(t, "object", withdraw[parser.ParamsKey].(map[string]interface{})["type"])
+	require.Equal(t, map[string]interface{}{"type": "string"}, withdraw[parser.ReturnKey])
+
+	// tx types not declared by go-state-types are added
+	require.Contains(t, properties(parser.MethodSend), parser.ParamsKey)
+	require.Equal(t, map[string]interface{}{"type": "string"}, properties("OldMethod")[parser.ParamsKey])
+}
+
+func TestMergeJSONSchemas(t *testing.T) {
+	type v1 struct {
+		A string
+		B int64
+	}
+	type v2 struct {
+		A string
+		C []int64
+	}
+
+	merged := mergeJSONSchemas(parser.NewJSONSchema(reflect.TypeOf(v1{})), parser.NewJSONSchema(reflect.TypeOf(v2{})))
+	require.Equal(t, "object", merged["type"])
+	require.Len(t, merged["properties"], 3)
+	// only the fields of every version are required
+	require.Equal(t, []string{"A"}, merged["required"])
+
+	// values of different types are accepted with anyOf
+	merged = mergeJSONSchemas(parser.JSONSchema{"type": "string"}, parser.JSONSchema{"type": "integer"})
+	require.Len(t, merged["anyOf"], 2)
+
+	// empty values are not part of the metadata
+	require.Equal(t, parser.JSONSchema{"type": "string"}, mergeJSONSchemas(nil, parser.JSONSchema{"type": "string"}))
+}
@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"github.com/zondax/fil-parser/parser"
+)
+
+var emptyValueType = reflect.TypeOf(abi.EmptyValue{})
+
+// DecodeMethodMetadata decodes the params and return of a method into the types declared for it by go-state-types.
+// It is the fallback for the methods known by go-state-types that have no explicit parser yet.
+func (h *Helper) DecodeMethodMetadata(actorName string, method abi.MethodNum, rawParams, rawReturn []byte) (map[string]interface{}, error) {
+	meta, ok := allMethods[actorName][method]
+	if !ok || meta.Method == nil {
+		return nil, parser.ErrUnknownMethod
+	}
+
+	methodType := reflect.TypeOf(meta.Method)
+	if methodType.Kind() != reflect.Func || methodType.NumIn() != 1 || methodType.NumOut() != 1 {
+		return nil, fmt.Errorf("unexpected signature for method %s of actor %s", meta.Name, actorName)
+	}
+
+	metadata := make(map[string]interface{})
+	params, err := decodeMethodValue(methodType.In(0), rawParams)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode params of method %s of actor %s: %w", meta.Name, actorName, err)
+	}
+	if params != nil {
+		metadata[parser.ParamsKey] = params
+	}
+
+	ret, err := decodeMethodValue(methodType.Out(0), rawReturn)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode return of method %s of actor %s: %w", meta.Name, actorName, err)
+	}
+	if ret != nil {
+		metadata[parser.ReturnKey] = ret
+	}
+
+	return metadata, nil
+}
+
+// decodeMethodValue unmarshals the raw cbor into a new value of the (pointer) type. Empty values are skipped.
+func decodeMethodValue(valueType reflect.Type, raw []byte) (interface{}, error) {
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	if valueType == emptyValueType || len(raw) == 0 {
+		return nil, nil
+	}
+
+	value := reflect.New(valueType)
+	unmarshaler, ok := value.Interface().(cbg.CBORUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("type %s can not be decoded from cbor", valueType)
+	}
+	if err := unmarshaler.UnmarshalCBOR(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin/v12/miner"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+)
+
+func TestHelper_DecodeMethodMetadata(t *testing.T) {
+	h := &Helper{}
+	worker, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	params := miner.ChangeWorkerAddressParams{NewWorker: worker, NewControlAddrs: []address.Address{worker}}
+	raw := new(bytes.Buffer)
+	require.NoError(t, params.MarshalCBOR(raw))
+
+	// ChangeWorkerAddress returns an empty value, which is not part of the metadata
+	metadata, err := h.DecodeMethodMetadata(manifest.MinerKey, 3, raw.Bytes(), nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{parser.ParamsKey: params}, metadata)
+
+	_, err = h.DecodeMethodMetadata(manifest.MinerKey, 3, []byte{0xff}, nil)
+	require.Error(t, err)
+
+	_, err = h.DecodeMethodMetadata(manifest.MinerKey, abi.MethodNum(999999), nil, nil)
+	require.ErrorIs(t, err, parser.ErrUnknownMethod)
+}
@@ -0,0 +1,44 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	minerInfoCachePrefix = "minerInfo"
+	// minerInfoTtl miner info is fetched at a given tipset, so cached values never expire
+	minerInfoTtl = -1
+)
+
+// GetMinerInfo returns the owner, worker and control addresses of the miner at the given tipset
+func (h *Helper) GetMinerInfo(ctx context.Context, minerAddr address.Address, key filTypes.TipSetKey) (*types.MinerInfo, error) {
+	cacheKey := fmt.Sprintf("%s/%s", key.String(), minerAddr.String())
+	if h.minerInfoCache != nil {
+		var cached types.MinerInfo
+		if err := h.minerInfoCache.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	if h.node == nil {
+		return nil, fmt.Errorf("node client is nil")
+	}
+
+	info, err := h.lookupMinerInfo(ctx, minerAddr, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.minerInfoCache != nil {
+		if err = h.minerInfoCache.Set(ctx, cacheKey, info, minerInfoTtl); err != nil {
+			h.logger.Sugar().Debugf("[miner-info] - could not cache miner %s: %s", minerAddr.String(), err)
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/mocks"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestHelper_GetMinerInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	node := mocks.NewMockFullNode(ctrl)
+
+	ids := make([]address.Address, 5)
+	for i := range ids {
+		addr, err := address.NewIDAddress(uint64(1000 + i))
+		require.NoError(t, err)
+		ids[i] = addr
+	}
+	minerAddr, unknownMiner := ids[0], ids[4]
+
+	node.EXPECT().StateMinerInfo(gomock.Any(), minerAddr, filTypes.EmptyTSK).
+		Return(api.MinerInfo{Owner: ids[1], Worker: ids[2], ControlAddresses: []address.Address{ids[3]}, Beneficiary: ids[1]}, nil).Times(1)
+	node.EXPECT().StateMinerInfo(gomock.Any(), unknownMiner, filTypes.EmptyTSK).
+		Return(api.MinerInfo{}, errors.New("actor not found")).Times(1)
+
+	h := NewHelper(nil, nil, node, nil, parser.FilecoinParserConfig{EnrichMinerInfo: true})
+	expected := &types.MinerInfo{Owner: "f01001", Worker: "f01002", ControlAddresses: []string{"f01003"}, Beneficiary: "f01001"}
+
+	got, err := h.GetMinerInfo(context.Background(), minerAddr, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+
+	// the miner is served from the cache
+	got, err = h.GetMinerInfo(context.Background(), minerAddr, filTypes.EmptyTSK)
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+
+	_, err = h.GetMinerInfo(context.Background(), unknownMiner, filTypes.EmptyTSK)
+	require.Error(t, err)
+}
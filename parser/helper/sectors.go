@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	sectorInfoCachePrefix = "sectorInfo"
+	// sectorInfoTtl sector info is fetched at a given tipset, so cached values never expire
+	sectorInfoTtl = -1
+)
+
+var errMaxSectorLookups = errors.New("max sector info lookups reached")
+
+// GetSectorsInfo returns the on-chain info at the given tipset of the sectors set in the bitfields.
+// Sectors that cannot be found (e.g. already terminated) are skipped.
+func (h *Helper) GetSectorsInfo(ctx context.Context, minerAddr address.Address, sectors []bitfield.BitField, key filTypes.TipSetKey) []types.SectorInfo {
+	maxLookups := h.config.MaxSectorInfoLookups
+	if maxLookups <= 0 {
+		maxLookups = parser.DefaultMaxSectorInfoLookups
+	}
+
+	sectorsInfo := make([]types.SectorInfo, 0)
+	lookups := 0
+	for _, sectorsBitfield := range sectors {
+		err := sectorsBitfield.ForEach(func(sectorNumber uint64) error {
+			if lookups >= maxLookups {
+				return errMaxSectorLookups
+			}
+			lookups++
+
+			info, err := h.getSectorInfo(ctx, minerAddr, sectorNumber, key)
+			if err != nil {
+				h.logger.Sugar().Debugf("[sector-info] - could not get sector %d of miner %s: %s", sectorNumber, minerAddr.String(), err)
+				return nil
+			}
+			sectorsInfo = append(sectorsInfo, *info)
+			return nil
+		})
+
+		if errors.Is(err, errMaxSectorLookups) {
+			h.logger.Sugar().Debugf("[sector-info] - miner %s: sector info lookups limited to %d", minerAddr.String(), maxLookups)
+			break
+		}
+		if err != nil {
+			h.logger.Sugar().Errorf("[sector-info] - could not iterate sectors of miner %s: %s", minerAddr.String(), err)
+		}
+	}
+
+	return sectorsInfo
+}
+
+func (h *Helper) getSectorInfo(ctx context.Context, minerAddr address.Address, sectorNumber uint64, key filTypes.TipSetKey) (*types.SectorInfo, error) {
+	cacheKey := fmt.Sprintf("%s/%s/%d", key.String(), minerAddr.String(), sectorNumber)
+	if h.sectorInfoCache != nil {
+		var cached types.SectorInfo
+		if err := h.sectorInfoCache.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	if h.node == nil {
+		return nil, fmt.Errorf("node client is nil")
+	}
+
+	onChainInfo, err := h.node.StateSectorGetInfo(ctx, minerAddr, abi.SectorNumber(sectorNumber), key)
+	if err != nil {
+		return nil, err
+	}
+	if onChainInfo == nil {
+		return nil, fmt.Errorf("sector not found")
+	}
+
+	info := &types.SectorInfo{
+		SectorNumber: uint64(onChainInfo.SectorNumber),
+		Activation:   int64(onChainInfo.Activation),
+		Expiration:   int64(onChainInfo.Expiration),
+		DealIDs:      make([]uint64, 0, len(onChainInfo.DealIDs)),
+	}
+	for _, dealID := range onChainInfo.DealIDs {
+		info.DealIDs = append(info.DealIDs, uint64(dealID))
+	}
+
+	if h.sectorInfoCache != nil {
+		if err = h.sectorInfoCache.Set(ctx, cacheKey, info, sectorInfoTtl); err != nil {
+			h.logger.Sugar().Debugf("[sector-info] - could not cache sector %d of miner %s: %s", sectorNumber, minerAddr.String(), err)
+		}
+	}
+
+	return info, nil
+}
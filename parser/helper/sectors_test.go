@@ -0,0 +1,44 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/api/mocks"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+)
+
+func TestHelper_GetSectorsInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	node := mocks.NewMockFullNode(ctrl)
+
+	minerAddr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	node.EXPECT().StateSectorGetInfo(gomock.Any(), minerAddr, abi.SectorNumber(1), filTypes.EmptyTSK).
+		Return(&miner.SectorOnChainInfo{SectorNumber: 1, Activation: 10, Expiration: 100, DealIDs: []abi.DealID{5}}, nil).Times(1)
+	node.EXPECT().StateSectorGetInfo(gomock.Any(), minerAddr, abi.SectorNumber(2), filTypes.EmptyTSK).
+		Return(nil, errors.New("not found")).Times(2)
+
+	h := NewHelper(nil, nil, node, nil, parser.FilecoinParserConfig{EnrichSectorInfo: true, MaxSectorInfoLookups: 2})
+	sectors := []bitfield.BitField{bitfield.NewFromSet([]uint64{1, 2, 3})}
+
+	got := h.GetSectorsInfo(context.Background(), minerAddr, sectors, filTypes.EmptyTSK)
+	require.Len(t, got, 1)
+	require.Equal(t, uint64(1), got[0].SectorNumber)
+	require.Equal(t, int64(10), got[0].Activation)
+	require.Equal(t, int64(100), got[0].Expiration)
+	require.Equal(t, []uint64{5}, got[0].DealIDs)
+
+	// sector 1 is served from the cache
+	got = h.GetSectorsInfo(context.Background(), minerAddr, sectors, filTypes.EmptyTSK)
+	require.Len(t, got, 1)
+}
@@ -0,0 +1,11 @@
+package parser
+
+const (
+	// IdHashSchemeSha256 builds the tx ids from the sha256 of their inputs. It is the default, so ids do not
+	// change for existing indexes.
+	IdHashSchemeSha256 = "sha256"
+	// IdHashSchemeBlake2b builds the tx ids from the blake2b-256 of their inputs, the hash used by Filecoin
+	IdHashSchemeBlake2b = "blake2b"
+)
+
+var idHashSchemes = []string{IdHashSchemeSha256, IdHashSchemeBlake2b}
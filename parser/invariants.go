@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	// InvariantFeeBreakdown flags fee txs whose total cost is not the sum of the base fee burn, the miner tip and
+	// the over estimation burn
+	InvariantFeeBreakdown = "fee_breakdown"
+	// InvariantBaseFeeBurn flags messages burning more than their gas used at the parent base fee of the tipset
+	InvariantBaseFeeBurn = "base_fee_burn"
+	// InvariantMinerTips flags tipsets where the miner tips of the messages are not the gas rewards awarded to
+	// the block miners
+	InvariantMinerTips = "miner_tips"
+	// InvariantValueFlow flags successful internal txs that are not sent by the receiver of their parent tx, so
+	// the value moved by a message does not flow along its call tree
+	InvariantValueFlow = "value_flow"
+	// InvariantUnparsableMetadata flags the fee and reward txs whose metadata can not be read, so they could not
+	// be checked
+	InvariantUnparsableMetadata = "unparsable_metadata"
+)
+
+// awardBlockRewardMetadata is the part of the AwardBlockReward metadata checked by the invariants
+type awardBlockRewardMetadata struct {
+	Params struct {
+		GasReward string
+	}
+}
+
+// CheckInvariants checks that the value moved by the txs of a tipset reconciles with the tipset level
+// expectations: value flows along the call trees, fees add up, the base fee burn is bounded by the parent base fee
+// and the miner tips are awarded to the block miners. The addresses are used to match the robust and short forms
+// of an actor. A nil parentBaseFee skips the base fee check. Like anomalies, violations point either to chain
+// weirdness or to parser regressions, so they are reported but the txs are never modified.
+func CheckInvariants(txs []*types.Transaction, addresses *types.AddressInfoMap, parentBaseFee *big.Int) []types.InvariantViolation {
+	violations := make([]types.InvariantViolation, 0)
+	byId := make(map[string]*types.Transaction, len(txs))
+	for _, tx := range txs {
+		byId[tx.Id] = tx
+	}
+	violations = append(violations, checkValueFlow(txs, byId, addresses)...)
+
+	minerTips := new(big.Int)
+	gasRewards := new(big.Int)
+	var rewardTxIds []string
+	for _, tx := range txs {
+		switch {
+		case tx.TxType == TotalFeeOp:
+			fees, err := txFeesMetadata(tx)
+			if err != nil {
+				violations = append(violations, unparsableMetadataViolation(tx, err))
+				continue
+			}
+			burn, overEstimationBurn, minerTip := fees[0], fees[1], fees[2]
+			minerTips.Add(minerTips, minerTip)
+
+			total := new(big.Int).Add(burn, overEstimationBurn)
+			total.Add(total, minerTip)
+			if tx.Amount != nil && tx.Amount.Cmp(total) != 0 {
+				violations = append(violations, types.InvariantViolation{Kind: InvariantFeeBreakdown, TxIds: []string{tx.Id},
+					Expected: total.String(), Actual: tx.Amount.String(),
+					Detail: "total fee is not base fee burn + over estimation burn + miner tip"})
+			}
+
+			message, ok := byId[tx.ParentId]
+			if parentBaseFee == nil || !ok {
+				continue
+			}
+			maxBurn := new(big.Int).Mul(parentBaseFee, new(big.Int).SetUint64(message.GasUsed))
+			if burn.Cmp(maxBurn) > 0 {
+				violations = append(violations, types.InvariantViolation{Kind: InvariantBaseFeeBurn, TxIds: []string{message.Id, tx.Id},
+					Expected: maxBurn.String(), Actual: burn.String(),
+					Detail: fmt.Sprintf("base fee burn is over %d gas used at a parent base fee of %s", message.GasUsed, parentBaseFee)})
+			}
+		case tx.TxType == MethodAwardBlockReward && tx.Status == GetExitCodeStatus(0):
+			gasReward, err := txGasReward(tx)
+			if err != nil {
+				violations = append(violations, unparsableMetadataViolation(tx, err))
+				continue
+			}
+			gasRewards.Add(gasRewards, gasReward)
+			rewardTxIds = append(rewardTxIds, tx.Id)
+		}
+	}
+
+	// the rewards are only part of the traces, so the tipset level check is skipped if there are none
+	if len(rewardTxIds) > 0 && minerTips.Cmp(gasRewards) != 0 {
+		violations = append(violations, types.InvariantViolation{Kind: InvariantMinerTips, TxIds: rewardTxIds,
+			Expected: minerTips.String(), Actual: gasRewards.String(),
+			Detail: "the gas rewards awarded to the block miners are not the miner tips of the messages"})
+	}
+	return violations
+}
+
+// checkValueFlow checks that the successful internal txs are sent by the receiver of their parent tx. The fee txs
+// are paid by the sender of the message, so they are not checked. When the short form of an address is unknown
+// the tx is not checked either.
+func checkValueFlow(txs []*types.Transaction, byId map[string]*types.Transaction, addresses *types.AddressInfoMap) []types.InvariantViolation {
+	shortAddresses := make(map[string]string)
+	if addresses != nil {
+		addresses.Range(func(short string, info *types.AddressInfo) bool {
+			if info.Robust != "" {
+				shortAddresses[info.Robust] = short
+			}
+			return true
+		})
+	}
+	shortAddress := func(addr string) (string, bool) {
+		if isIdAddress(addr) {
+			return addr, true
+		}
+		short, ok := shortAddresses[addr]
+		return short, ok
+	}
+
+	violations := make([]types.InvariantViolation, 0)
+	for _, tx := range txs {
+		parent, ok := byId[tx.ParentId]
+		if !ok || tx.Status != GetExitCodeStatus(0) || isFeeTxType(tx.TxType) || tx.TxFrom == parent.TxTo {
+			continue
+		}
+		from, fromOk := shortAddress(tx.TxFrom)
+		receiver, receiverOk := shortAddress(parent.TxTo)
+		if !fromOk || !receiverOk || from == receiver {
+			continue
+		}
+		violations = append(violations, types.InvariantViolation{Kind: InvariantValueFlow, TxIds: []string{parent.Id, tx.Id},
+			Expected: parent.TxTo, Actual: tx.TxFrom,
+			Detail: "internal tx is not sent by the receiver of its parent tx"})
+	}
+	return violations
+}
+
+func isIdAddress(addr string) bool {
+	return len(addr) > 2 && (addr[0] == 'f' || addr[0] == 't') && addr[1] == '0'
+}
+
+func isFeeTxType(txType string) bool {
+	return txType == TotalFeeOp || txType == MinerFeeOp || txType == BurnFeeOp || txType == OverEstimationBurnOp || txType == GasRefundOp
+}
+
+func unparsableMetadataViolation(tx *types.Transaction, err error) types.InvariantViolation {
+	return types.InvariantViolation{Kind: InvariantUnparsableMetadata, TxIds: []string{tx.Id},
+		Detail: fmt.Sprintf("could not read the metadata of the %s tx: %s", tx.TxType, err)}
+}
+
+// txFeesMetadata returns the base fee burn, over estimation burn and miner tip of a fee tx
+func txFeesMetadata(tx *types.Transaction) ([3]*big.Int, error) {
+	var amounts [3]*big.Int
+	rawMetadata, err := tx.GetMetadata()
+	if err != nil {
+		return amounts, err
+	}
+	var metadata FeesMetadata
+	if err = json.Unmarshal([]byte(rawMetadata), &metadata); err != nil {
+		return amounts, err
+	}
+	for i, amount := range []string{metadata.BurnFee.Amount, metadata.OverEstimationBurnFee.Amount, metadata.MinerFee.Amount} {
+		var ok bool
+		if amounts[i], ok = new(big.Int).SetString(amount, 10); !ok {
+			return amounts, fmt.Errorf("invalid amount %q", amount)
+		}
+	}
+	return amounts, nil
+}
+
+// txGasReward returns the gas reward awarded by an AwardBlockReward tx
+func txGasReward(tx *types.Transaction) (*big.Int, error) {
+	rawMetadata, err := tx.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+	var metadata awardBlockRewardMetadata
+	if err = json.Unmarshal([]byte(rawMetadata), &metadata); err != nil {
+		return nil, err
+	}
+	gasReward, ok := new(big.Int).SetString(metadata.Params.GasReward, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid gas reward %q", metadata.Params.GasReward)
+	}
+	return gasReward, nil
+}
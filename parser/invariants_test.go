@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func invariantFeeTx(t *testing.T, id, parentId string, amount int64, burn, overEstimationBurn, minerTip string) *types.Transaction {
+	metadata, err := json.Marshal(FeesMetadata{
+		BurnFee:               BurnFee{Amount: burn},
+		OverEstimationBurnFee: OverEstimationBurnFee{Amount: overEstimationBurn},
+		MinerFee:              MinerFee{Amount: minerTip},
+	})
+	require.NoError(t, err)
+	return &types.Transaction{Id: id, ParentId: parentId, TxType: TotalFeeOp, Amount: big.NewInt(amount), TxMetadata: string(metadata)}
+}
+
+func invariantRewardTx(id, gasReward string) *types.Transaction {
+	return &types.Transaction{Id: id, TxType: MethodAwardBlockReward, Status: GetExitCodeStatus(0),
+		TxMetadata: `{"Params":{"Miner":"f01000","Penalty":"0","GasReward":"` + gasReward + `","WinCount":1}}`}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	ok := GetExitCodeStatus(0)
+	tests := []struct {
+		name          string
+		txs           []*types.Transaction
+		parentBaseFee *big.Int
+		want          map[string][]string
+	}{
+		{
+			name: "fees and rewards reconcile",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, GasUsed: 10},
+				invariantFeeTx(t, "feeA", "a", 130, "100", "20", "10"),
+				{Id: "b", Status: ok, GasUsed: 10},
+				invariantFeeTx(t, "feeB", "b", 105, "100", "0", "5"),
+				invariantRewardTx("reward1", "10"),
+				invariantRewardTx("reward2", "5"),
+			},
+			parentBaseFee: big.NewInt(10),
+			want:          map[string][]string{},
+		},
+		{
+			name: "total fee is not the sum of its parts",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, GasUsed: 10},
+				invariantFeeTx(t, "feeA", "a", 131, "100", "20", "10"),
+			},
+			want: map[string][]string{InvariantFeeBreakdown: {"feeA"}},
+		},
+		{
+			name: "burn over the parent base fee",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, GasUsed: 10},
+				invariantFeeTx(t, "feeA", "a", 101, "101", "0", "0"),
+			},
+			parentBaseFee: big.NewInt(10),
+			want:          map[string][]string{InvariantBaseFeeBurn: {"a", "feeA"}},
+		},
+		{
+			name: "miner tips not awarded",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, GasUsed: 10},
+				invariantFeeTx(t, "feeA", "a", 110, "100", "0", "10"),
+				invariantRewardTx("reward1", "9"),
+			},
+			want: map[string][]string{InvariantMinerTips: {"reward1"}},
+		},
+		{
+			name: "miner tips are not checked without rewards",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, GasUsed: 10},
+				invariantFeeTx(t, "feeA", "a", 110, "100", "0", "10"),
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "value flows along the call tree",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, TxFrom: "f1sender", TxTo: "f410fcontract"},
+				{Id: "b", ParentId: "a", Status: ok, TxFrom: "f01001", TxTo: "f01002", Amount: big.NewInt(1)},
+				{Id: "c", ParentId: "b", Status: ok, TxFrom: "f01002", TxTo: "f01003", Amount: big.NewInt(1)},
+				// the robust address of the receiver is unknown
+				{Id: "d", Status: ok, TxFrom: "f1sender", TxTo: "f1unknown"},
+				{Id: "e", ParentId: "d", Status: ok, TxFrom: "f01004", TxTo: "f01002"},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "internal tx not sent by the receiver of its parent",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, TxFrom: "f1sender", TxTo: "f410fcontract"},
+				{Id: "b", ParentId: "a", Status: ok, TxFrom: "f01002", TxTo: "f01003", Amount: big.NewInt(1)},
+				// failed txs move no value
+				{Id: "c", ParentId: "a", Status: GetExitCodeStatus(1), TxFrom: "f01002", TxTo: "f01003"},
+			},
+			want: map[string][]string{InvariantValueFlow: {"a", "b"}},
+		},
+		{
+			name: "unparsable metadata",
+			txs: []*types.Transaction{
+				{Id: "a", Status: ok, GasUsed: 10},
+				{Id: "feeA", ParentId: "a", TxType: TotalFeeOp, Amount: big.NewInt(1), TxMetadata: `{"BurnFee":{"Amount":"x"}}`},
+				{Id: "reward1", TxType: MethodAwardBlockReward, Status: ok, TxMetadata: `{`},
+			},
+			want: map[string][]string{InvariantUnparsableMetadata: {"feeA", "reward1"}},
+		},
+	}
+	addresses := types.NewAddressInfoMap()
+	addresses.Set("f01001", &types.AddressInfo{Short: "f01001", Robust: "f410fcontract"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string][]string)
+			for _, violation := range CheckInvariants(tt.txs, addresses, tt.parentBaseFee) {
+				got[violation.Kind] = append(got[violation.Kind], violation.TxIds...)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
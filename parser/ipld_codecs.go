@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+)
+
+// IPLD codecs of the params and return values of the messages, as recorded in the traces by lotus.
+// Traces that do not record the codec (e.g. v1 traces) have a zero codec, and are taken as CBOR.
+const (
+	CodecUnknown uint64 = 0
+	CodecCBOR    uint64 = 0x51
+	CodecRaw     uint64 = 0x55
+	CodecDagCBOR uint64 = 0x71
+)
+
+const (
+	ParamsCodecKey = "ParamsCodec"
+	ReturnCodecKey = "ReturnCodec"
+)
+
+// CodecName returns the multicodec name of the codec
+func CodecName(codec uint64) string {
+	switch codec {
+	case CodecCBOR:
+		return "cbor"
+	case CodecRaw:
+		return "raw"
+	case CodecDagCBOR:
+		return "dag-cbor"
+	default:
+		return fmt.Sprintf("0x%x", codec)
+	}
+}
+
+// IsCBORCodec returns whether the data of the codec can be decoded as CBOR
+func IsCBORCodec(codec uint64) bool {
+	return codec == CodecUnknown || codec == CodecCBOR || codec == CodecDagCBOR
+}
+
+// RenderIpldData renders the data according to its codec: dag-cbor data is rendered as DAG-JSON, and the rest
+// is hex encoded. dag-cbor data that can not be decoded is hex encoded as well.
+func RenderIpldData(codec uint64, data []byte) interface{} {
+	if codec == CodecDagCBOR {
+		if rendered, err := dagCBORToJSON(data); err == nil {
+			return rendered
+		}
+	}
+	return hex.EncodeToString(data)
+}
+
+func dagCBORToJSON(data []byte) (json.RawMessage, error) {
+	node, err := ipld.Decode(data, dagcbor.Decode)
+	if err != nil {
+		return nil, fmt.Errorf("error ipld decode dag-cbor data: %w", err)
+	}
+	var buf bytes.Buffer
+	if err = dagjson.Encode(node, &buf); err != nil {
+		return nil, fmt.Errorf("error encoding dag-json: %w", err)
+	}
+	return buf.Bytes(), nil
+}
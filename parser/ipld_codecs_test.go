@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderIpldData(t *testing.T) {
+	// {"a": 1} encoded as cbor
+	data := []byte{0xa1, 0x61, 0x61, 0x01}
+
+	tests := []struct {
+		name  string
+		codec uint64
+		data  []byte
+		want  interface{}
+	}{
+		{name: "dag-cbor is rendered as dag-json", codec: CodecDagCBOR, data: data, want: json.RawMessage(`{"a":1}`)},
+		{name: "raw is hex encoded", codec: CodecRaw, data: data, want: "a1616101"},
+		{name: "cbor is hex encoded", codec: CodecCBOR, data: data, want: "a1616101"},
+		{name: "unknown codec is hex encoded", codec: CodecUnknown, data: data, want: "a1616101"},
+		{name: "invalid dag-cbor is hex encoded", codec: CodecDagCBOR, data: []byte{0xa1, 0x61}, want: "a161"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, RenderIpldData(tt.codec, tt.data))
+		})
+	}
+}
+
+func TestCodecName(t *testing.T) {
+	require.Equal(t, "raw", CodecName(CodecRaw))
+	require.Equal(t, "dag-cbor", CodecName(CodecDagCBOR))
+	require.Equal(t, "0x70", CodecName(0x70))
+	require.True(t, IsCBORCodec(CodecUnknown))
+	require.False(t, IsCBORCodec(CodecRaw))
+}
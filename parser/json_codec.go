@@ -0,0 +1,10 @@
+package parser
+
+const (
+	// JSONCodecSonic decodes the traces with sonic, configured to behave as encoding/json. It is the default.
+	JSONCodecSonic = "sonic"
+	// JSONCodecStd decodes the traces with encoding/json, e.g. on platforms sonic does not support
+	JSONCodecStd = "std"
+)
+
+var jsonCodecs = []string{JSONCodecSonic, JSONCodecStd}
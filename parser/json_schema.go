@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+)
+
+// JSONSchema is a JSON Schema (draft 2020-12) document or sub-schema
+type JSONSchema map[string]interface{}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// knownJSONSchemas are the types with a custom json encoding found in the metadata
+	knownJSONSchemas = map[reflect.Type]JSONSchema{
+		reflect.TypeOf(address.Address{}):   {"type": "string", "description": "filecoin address"},
+		reflect.TypeOf(filBig.Int{}):        {"type": "string", "pattern": "^-?[0-9]+$", "description": "big integer"},
+		reflect.TypeOf(cid.Cid{}):           {"type": "object", "properties": map[string]interface{}{"/": JSONSchema{"type": "string"}}, "required": []string{"/"}},
+		reflect.TypeOf(bitfield.BitField{}): {"type": "array", "items": JSONSchema{"type": "integer", "minimum": 0}, "description": "RLE+ run lengths"},
+	}
+)
+
+// NewJSONSchema builds the schema of the json encoding of the type, following the encoding/json rules
+// (field tags, omitempty, embedded structs, nil pointers and slices as null...).
+func NewJSONSchema(t reflect.Type) JSONSchema {
+	return newJSONSchema(t, map[reflect.Type]bool{})
+}
+
+func newJSONSchema(t reflect.Type, visiting map[reflect.Type]bool) JSONSchema {
+	if schema, ok := knownJSONSchemas[t]; ok {
+		return schema
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return nullable(newJSONSchema(t.Elem(), visiting))
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return JSONSchema{"description": "custom json encoding of " + t.String()}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return JSONSchema{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return JSONSchema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(JSONSchema{"type": "string", "contentEncoding": "base64"})
+		}
+		return nullable(JSONSchema{"type": "array", "items": newJSONSchema(t.Elem(), visiting)})
+	case reflect.Array:
+		return JSONSchema{"type": "array", "items": newJSONSchema(t.Elem(), visiting), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return nullable(JSONSchema{"type": "object", "additionalProperties": newJSONSchema(t.Elem(), visiting)})
+	case reflect.Struct:
+		if visiting[t] {
+			// recursive types are not expanded
+			return JSONSchema{"description": "recursive " + t.String()}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		return structJSONSchema(t, visiting)
+	default:
+		// interfaces can hold any value
+		return JSONSchema{}
+	}
+}
+
+func structJSONSchema(t reflect.Type, visiting map[reflect.Type]bool) JSONSchema {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	addStructFields(t, visiting, properties, &required)
+
+	schema := JSONSchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func addStructFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		// untagged embedded structs are flattened into the parent
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, visiting, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = newJSONSchema(field.Type, visiting)
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func nullable(schema JSONSchema) JSONSchema {
+	return JSONSchema{"anyOf": []interface{}{schema, JSONSchema{"type": "null"}}}
+}
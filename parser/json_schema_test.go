@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonSchemaEmbedded struct {
+	Height int64
+}
+
+type jsonSchemaNode struct {
+	Next *jsonSchemaNode
+}
+
+type jsonSchemaTest struct {
+	jsonSchemaEmbedded
+	Name     string `json:"name"`
+	Optional []byte `json:"optional,omitempty"`
+	Ignored  bool   `json:"-"`
+	Address  address.Address
+	Amount   abi.TokenAmount
+	Node     jsonSchemaNode
+	private  int
+}
+
+func TestNewJSONSchema(t *testing.T) {
+	schema := NewJSONSchema(reflect.TypeOf(jsonSchemaTest{}))
+	require.Equal(t, "object", schema["type"])
+	require.Equal(t, []string{"Height", "name", "Address", "Amount", "Node"}, schema["required"])
+
+	properties := schema["properties"].(map[string]interface{})
+	require.Len(t, properties, 6)
+	require.Equal(t, JSONSchema{"type": "integer"}, properties["Height"])
+	require.Equal(t, JSONSchema{"type": "string"}, properties["name"])
+	require.Equal(t, nullable(JSONSchema{"type": "string", "contentEncoding": "base64"}), properties["optional"])
+	require.Equal(t, "filecoin address", properties["Address"].(JSONSchema)["description"])
+	require.Equal(t, "big integer", properties["Amount"].(JSONSchema)["description"])
+
+	// recursive types are not expanded
+	next := properties["Node"].(JSONSchema)["properties"].(map[string]interface{})["Next"]
+	require.Equal(t, nullable(JSONSchema{"description": "recursive parser.jsonSchemaNode"}), next)
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/zondax/fil-parser/types"
+)
+
+// StorageMarketAddress is the address of the storage market actor
+var StorageMarketAddress = builtin.StorageMarketActorAddr.String()
+
+type withdrawBalanceMetadata struct {
+	Params struct {
+		ProviderOrClientAddress string
+		Amount                  abi.TokenAmount
+	}
+	Return string
+}
+
+// ExtractEscrowChanges builds the market escrow changes of the successful AddBalance and WithdrawBalance txs.
+// The recipient of a withdrawal is taken from the internal transfer sent by the market, if it is part of the txs.
+// The txs metadata must not be compressed yet.
+func ExtractEscrowChanges(txs []*types.Transaction) []*types.EscrowChange {
+	okStatus := GetExitCodeStatus(0)
+	transfers := make(map[string]*types.Transaction)
+	for _, tx := range txs {
+		if tx.TxType == MethodSend && tx.TxFrom == StorageMarketAddress && tx.Status == okStatus {
+			transfers[tx.ParentId] = tx
+		}
+	}
+
+	changes := make([]*types.EscrowChange, 0)
+	for _, tx := range txs {
+		if tx.TxTo != StorageMarketAddress || tx.Status != okStatus {
+			continue
+		}
+
+		var change *types.EscrowChange
+		switch tx.TxType {
+		case MethodAddBalance, MethodAddBalanceExported:
+			change = newDepositChange(tx)
+		case MethodWithdrawBalance, MethodWithdrawBalanceExported:
+			change = newWithdrawalChange(tx, transfers[tx.Id])
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func newDepositChange(tx *types.Transaction) *types.EscrowChange {
+	var metadata struct {
+		Params string
+	}
+	if err := json.Unmarshal([]byte(tx.TxMetadata), &metadata); err != nil || metadata.Params == "" {
+		return nil
+	}
+
+	amount := tx.Amount
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	return &types.EscrowChange{
+		TxId:    tx.Id,
+		TxCid:   tx.TxCid,
+		Height:  tx.Height,
+		Kind:    types.EscrowChangeDeposit,
+		Account: metadata.Params,
+		Sender:  tx.TxFrom,
+		Amount:  amount,
+	}
+}
+
+func newWithdrawalChange(tx, transfer *types.Transaction) *types.EscrowChange {
+	var metadata withdrawBalanceMetadata
+	if err := json.Unmarshal([]byte(tx.TxMetadata), &metadata); err != nil || metadata.Params.ProviderOrClientAddress == "" {
+		return nil
+	}
+
+	change := &types.EscrowChange{
+		TxId:    tx.Id,
+		TxCid:   tx.TxCid,
+		Height:  tx.Height,
+		Kind:    types.EscrowChangeWithdrawal,
+		Account: metadata.Params.ProviderOrClientAddress,
+		Sender:  tx.TxFrom,
+	}
+	if metadata.Params.Amount.Int != nil {
+		change.Requested = metadata.Params.Amount.Int
+	}
+
+	// the return holds the amount actually withdrawn, the internal transfer is the fallback for old versions
+	// of the actor that returned nothing
+	if withdrawn, ok := decodeWithdrawnAmount(metadata.Return); ok {
+		change.Amount = withdrawn
+	}
+	if transfer != nil {
+		change.Recipient = transfer.TxTo
+		if change.Amount == nil {
+			change.Amount = transfer.Amount
+		}
+	}
+	if change.Amount == nil {
+		change.Amount = big.NewInt(0)
+	}
+	return change
+}
+
+func decodeWithdrawnAmount(rawReturn string) (*big.Int, bool) {
+	if rawReturn == "" {
+		return nil, false
+	}
+	raw, err := base64.StdEncoding.DecodeString(rawReturn)
+	if err != nil {
+		return nil, false
+	}
+
+	var amount abi.TokenAmount
+	if err = amount.UnmarshalCBOR(bytes.NewReader(raw)); err != nil || amount.Int == nil {
+		return nil, false
+	}
+	return amount.Int, true
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin/v11/market"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestExtractEscrowChanges(t *testing.T) {
+	provider, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	withdrawn := new(bytes.Buffer)
+	amount := abi.NewTokenAmount(30)
+	require.NoError(t, amount.MarshalCBOR(withdrawn))
+	withdrawMetadata, err := json.Marshal(map[string]interface{}{
+		ParamsKey: market.WithdrawBalanceParams{ProviderOrClientAddress: provider, Amount: abi.NewTokenAmount(50)},
+		ReturnKey: base64.StdEncoding.EncodeToString(withdrawn.Bytes()),
+	})
+	require.NoError(t, err)
+
+	ok := GetExitCodeStatus(0)
+	txs := []*types.Transaction{
+		{Id: "deposit", TxType: MethodAddBalance, TxFrom: "f01001", TxTo: StorageMarketAddress, Amount: big.NewInt(100),
+			Status: ok, TxMetadata: `{"Params":"f01000"}`},
+		{Id: "withdraw", TxType: MethodWithdrawBalance, TxFrom: "f01002", TxTo: StorageMarketAddress, Amount: big.NewInt(0),
+			Status: ok, TxMetadata: string(withdrawMetadata)},
+		{Id: "transfer", ParentId: "withdraw", TxType: MethodSend, TxFrom: StorageMarketAddress, TxTo: "f01002",
+			Amount: big.NewInt(30), Status: ok},
+		// miner withdrawals share the tx type, but are not sent to the market
+		{Id: "miner", TxType: MethodWithdrawBalance, TxFrom: "f01002", TxTo: "f01000", Status: ok, TxMetadata: string(withdrawMetadata)},
+		// failed deposits do not change the escrow
+		{Id: "failed", TxType: MethodAddBalance, TxFrom: "f01001", TxTo: StorageMarketAddress, Amount: big.NewInt(100),
+			Status: "Error", TxMetadata: `{"Params":"f01000"}`},
+	}
+
+	changes := ExtractEscrowChanges(txs)
+	require.Equal(t, []*types.EscrowChange{
+		{TxId: "deposit", Kind: types.EscrowChangeDeposit, Account: "f01000", Sender: "f01001", Amount: big.NewInt(100)},
+		{TxId: "withdraw", Kind: types.EscrowChangeWithdrawal, Account: "f01000", Sender: "f01002", Recipient: "f01002",
+			Amount: big.NewInt(30), Requested: big.NewInt(50)},
+	}, changes)
+}
+
+func TestExtractEscrowChanges_WithoutReturn(t *testing.T) {
+	txs := []*types.Transaction{
+		{Id: "withdraw", TxType: MethodWithdrawBalanceExported, TxFrom: "f01002", TxTo: StorageMarketAddress, Status: GetExitCodeStatus(0),
+			TxMetadata: `{"Params":{"ProviderOrClientAddress":"f01000","Amount":"50"}}`},
+		{Id: "transfer", ParentId: "withdraw", TxType: MethodSend, TxFrom: StorageMarketAddress, TxTo: "f01003",
+			Amount: big.NewInt(20), Status: GetExitCodeStatus(0)},
+	}
+
+	changes := ExtractEscrowChanges(txs)
+	require.Len(t, changes, 1)
+	require.Equal(t, "f01003", changes[0].Recipient)
+	require.Equal(t, big.NewInt(20), changes[0].Amount)
+	require.Equal(t, big.NewInt(50), changes[0].Requested)
+}
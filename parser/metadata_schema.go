@@ -0,0 +1,73 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sync"
+)
+
+//go:generate go run ./helper/internal/schemagen -o metadata_schemas.json
+
+// JSONSchemaDraft is the JSON Schema dialect of the metadata schemas
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// MetadataSchema is the JSON Schema of the metadata of the txs of an actor method
+type MetadataSchema struct {
+	// Actor is the actor name (e.g. "storageminer")
+	Actor string `json:"actor"`
+	// TxType is the tx type of the method
+	TxType    string          `json:"tx_type"`
+	MethodNum uint64          `json:"method_num"`
+	Schema    json.RawMessage `json:"schema"`
+}
+
+//go:embed metadata_schemas.json
+var metadataSchemasData []byte
+
+var metadataSchemas = sync.OnceValue(func() []MetadataSchema {
+	var schemas []MetadataSchema
+	if err := json.Unmarshal(metadataSchemasData, &schemas); err != nil {
+		panic("invalid embedded metadata schemas: " + err.Error())
+	}
+	return schemas
+})
+
+// MetadataSchemas returns the JSON Schemas of the tx metadata, sorted by actor and method number.
+// The schemas describe the Params and Return written by the actor parsers with the default features, for
+// every actors version since v8. Experimental features may add other keys to the metadata, so every schema
+// allows additional properties.
+func MetadataSchemas() []MetadataSchema {
+	schemas := metadataSchemas()
+	result := make([]MetadataSchema, len(schemas))
+	copy(result, schemas)
+	return result
+}
+
+// GetMetadataSchema returns the JSON Schema of the metadata of the tx type of the actor
+func GetMetadataSchema(actor, txType string) (json.RawMessage, bool) {
+	for _, schema := range metadataSchemas() {
+		if schema.Actor == actor && schema.TxType == txType {
+			return schema.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// NewMetadataSchema builds the JSON Schema of the metadata of a method with the given params and return schemas.
+// Nil schemas are left out, as empty values are never added to the metadata.
+func NewMetadataSchema(title string, params, ret JSONSchema) JSONSchema {
+	properties := make(map[string]interface{})
+	if params != nil {
+		properties[ParamsKey] = params
+	}
+	if ret != nil {
+		properties[ReturnKey] = ret
+	}
+	return JSONSchema{
+		"$schema":              JSONSchemaDraft,
+		"title":                title,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": true,
+	}
+}
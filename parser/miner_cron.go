@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-state-types/builtin/v15/miner"
+	"github.com/zondax/fil-parser/types"
+)
+
+// ClassifyMinerCronTxs types the burns sent by a miner while processing its deferred cron event. Without it
+// they are plain sends to the burn address and the fees of the proving deadline can not be told apart from
+// the termination fees. The event is read from the params of the OnDeferredCronEvent call (parentParams);
+// only the direct sub-txs of parent are updated.
+func ClassifyMinerCronTxs(parent *types.Transaction, parentParams []byte, subTxs []*types.Transaction) {
+	if parent == nil || parent.TxType != MethodOnDeferredCronEvent {
+		return
+	}
+
+	txType := minerCronBurnTxType(parentParams)
+	if txType == "" {
+		return
+	}
+
+	for _, subTx := range subTxs {
+		if subTx.ParentId == parent.Id && subTx.TxType == MethodSend && subTx.TxFrom == parent.TxTo && subTx.TxTo == BurnAddress {
+			subTx.TxType = txType
+		}
+	}
+}
+
+// minerCronBurnTxType returns the tx type of the burns made while processing the cron event of the params
+func minerCronBurnTxType(rawParams []byte) string {
+	var params miner.DeferredCronEventParams
+	if err := params.UnmarshalCBOR(bytes.NewReader(rawParams)); err != nil {
+		return ""
+	}
+
+	var payload miner.CronEventPayload
+	if err := payload.UnmarshalCBOR(bytes.NewReader(params.EventPayload)); err != nil {
+		return ""
+	}
+
+	switch payload.EventType {
+	case miner.CronEventProvingDeadline:
+		return TxTypeCronProvingDeadlinePenalty
+	case miner.CronEventProcessEarlyTerminations:
+		return TxTypeCronEarlyTerminationPenalty
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v15/miner"
+	"github.com/filecoin-project/go-state-types/builtin/v15/util/smoothing"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func cronEventParams(t *testing.T, eventType miner.CronEventType) []byte {
+	payload := new(bytes.Buffer)
+	require.NoError(t, (&miner.CronEventPayload{EventType: eventType}).MarshalCBOR(payload))
+
+	params := new(bytes.Buffer)
+	require.NoError(t, (&miner.DeferredCronEventParams{
+		EventPayload:            payload.Bytes(),
+		RewardSmoothed:          smoothing.NewEstimate(big.NewInt(1), big.Zero()),
+		QualityAdjPowerSmoothed: smoothing.NewEstimate(big.NewInt(1), big.Zero()),
+	}).MarshalCBOR(params))
+	return params.Bytes()
+}
+
+func TestClassifyMinerCronTxs(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType miner.CronEventType
+		want      string
+	}{
+		{name: "proving deadline", eventType: miner.CronEventProvingDeadline, want: TxTypeCronProvingDeadlinePenalty},
+		{name: "early terminations", eventType: miner.CronEventProcessEarlyTerminations, want: TxTypeCronEarlyTerminationPenalty},
+		{name: "worker key change", eventType: miner.CronEventWorkerKeyChange, want: MethodSend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := &types.Transaction{Id: "parent", TxType: MethodOnDeferredCronEvent, TxFrom: "f04", TxTo: "f01000"}
+			burn := &types.Transaction{Id: "burn", ParentId: "parent", TxType: MethodSend, TxFrom: "f01000", TxTo: BurnAddress}
+			pledge := &types.Transaction{Id: "pledge", ParentId: "parent", TxType: MethodUpdatePledgeTotal, TxFrom: "f01000", TxTo: "f04"}
+			nested := &types.Transaction{Id: "nested", ParentId: "pledge", TxType: MethodSend, TxFrom: "f01000", TxTo: BurnAddress}
+
+			ClassifyMinerCronTxs(parent, cronEventParams(t, tt.eventType), []*types.Transaction{burn, pledge, nested})
+			require.Equal(t, tt.want, burn.TxType)
+			require.Equal(t, MethodUpdatePledgeTotal, pledge.TxType)
+			require.Equal(t, MethodSend, nested.TxType)
+		})
+	}
+
+	// other parents and invalid params are ignored
+	burn := &types.Transaction{ParentId: "parent", TxType: MethodSend, TxFrom: "f01000", TxTo: BurnAddress}
+	ClassifyMinerCronTxs(&types.Transaction{Id: "parent", TxType: MethodSend, TxTo: "f01000"}, cronEventParams(t, miner.CronEventProvingDeadline), []*types.Transaction{burn})
+	require.Equal(t, MethodSend, burn.TxType)
+	ClassifyMinerCronTxs(&types.Transaction{Id: "parent", TxType: MethodOnDeferredCronEvent, TxTo: "f01000"}, []byte{0xff}, []*types.Transaction{burn})
+	require.Equal(t, MethodSend, burn.TxType)
+}
+
+const heightsDataPath = "../data/heights"
+
+// fixtureTrace is the part of a trace of the fixtures needed to find the deferred cron events of the miners
+type fixtureTrace struct {
+	Msg struct {
+		From   string
+		To     string
+		Method uint64
+		Params []byte
+	}
+	Subcalls []fixtureTrace
+}
+
+// fixtureCronEventParams returns the params of the OnDeferredCronEvent calls of the traces of the height
+func fixtureCronEventParams(t *testing.T, height int64) [][]byte {
+	file, err := os.Open(filepath.Join(heightsDataPath, fmt.Sprintf("traces_%d.json.gz", height)))
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var computeState struct {
+		Trace []struct {
+			ExecutionTrace fixtureTrace
+		}
+	}
+	require.NoError(t, json.NewDecoder(reader).Decode(&computeState))
+
+	var params [][]byte
+	var walk func(trace fixtureTrace)
+	walk = func(trace fixtureTrace) {
+		if trace.Msg.Method == uint64(builtin.MethodsMiner.OnDeferredCronEvent) && (trace.Msg.From == "f04" || trace.Msg.From == "t04") {
+			params = append(params, trace.Msg.Params)
+		}
+		for _, subcall := range trace.Subcalls {
+			walk(subcall)
+		}
+	}
+	for _, trace := range computeState.Trace {
+		walk(trace.ExecutionTrace)
+	}
+	return params
+}
+
+// TestClassifyMinerCronTxs_Fixtures classifies the burns of the deferred cron events found in the real traces of
+// heights spanning several actors versions, as the params were encoded by the miner actor of each version
+func TestClassifyMinerCronTxs_Fixtures(t *testing.T) {
+	for _, height := range []int64{14107, 38940, 78689, 197673, 845259, 2907480, 3450305, 3573062} {
+		t.Run(fmt.Sprint(height), func(t *testing.T) {
+			events := fixtureCronEventParams(t, height)
+			require.NotEmpty(t, events)
+			for _, params := range events {
+				parent := &types.Transaction{Id: "parent", TxType: MethodOnDeferredCronEvent, TxFrom: "f04", TxTo: "f01000"}
+				burn := &types.Transaction{Id: "burn", ParentId: "parent", TxType: MethodSend, TxFrom: "f01000", TxTo: BurnAddress}
+				ClassifyMinerCronTxs(parent, params, []*types.Transaction{burn})
+				// the power actor only defers proving deadlines and early terminations
+				require.Contains(t, []string{TxTypeCronProvingDeadlinePenalty, TxTypeCronEarlyTerminationPenalty}, burn.TxType)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// DecodedTraces are the traces of a tipset decoded by the parser of their version. They can only be passed back to
+// the parser that decoded them.
+type DecodedTraces any
+
+// MetadataDecoder decodes the params and return values of a tx into its metadata
+type MetadataDecoder func(ctx context.Context) map[string]interface{}
+
+// PendingMetadata holds the txs built from the traces whose metadata is not rendered yet. Building the tx tree,
+// decoding the params and rendering the metadata are separate stages, so the txs are queued with their decoder
+// when they are built, decoded by Decode and encoded into their TxMetadata by Render.
+// The methods of a nil PendingMetadata are no-ops.
+type PendingMetadata struct {
+	entries []pendingMetadataEntry
+	// decoded is the amount of entries decoded so far
+	decoded int
+}
+
+type pendingMetadataEntry struct {
+	tx       *types.Transaction
+	decoder  MetadataDecoder
+	metadata map[string]interface{}
+	done     bool
+}
+
+func NewPendingMetadata() *PendingMetadata {
+	return &PendingMetadata{}
+}
+
+// Add queues the tx to be decoded with the decoder
+func (m *PendingMetadata) Add(tx *types.Transaction, decoder MetadataDecoder) {
+	m.entries = append(m.entries, pendingMetadataEntry{tx: tx, decoder: decoder})
+}
+
+// AddDecoded queues the tx whose metadata was already decoded, e.g. because building the tx needed it
+func (m *PendingMetadata) AddDecoded(tx *types.Transaction, metadata map[string]interface{}) {
+	m.entries = append(m.entries, pendingMetadataEntry{tx: tx, metadata: metadata, done: true})
+}
+
+// Len returns the amount of txs whose metadata is not rendered yet
+func (m *PendingMetadata) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.entries)
+}
+
+// Decode runs the decoders of the queued txs, in the order they were added. It stops once the context is done,
+// so it can be called again to decode the rest.
+func (m *PendingMetadata) Decode(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	for ; m.decoded < len(m.entries); m.decoded++ {
+		entry := &m.entries[m.decoded]
+		if entry.done {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry.metadata, entry.done = entry.decoder(ctx), true
+	}
+	return nil
+}
+
+// Render encodes the decoded metadata into the TxMetadata of the queued txs and empties the queue. The txs that
+// were not decoded keep an empty TxMetadata.
+func (m *PendingMetadata) Render() {
+	if m == nil {
+		return
+	}
+	for _, entry := range m.entries {
+		if !entry.done {
+			continue
+		}
+		jsonMetadata, _ := json.Marshal(entry.metadata)
+		entry.tx.TxMetadata = string(jsonMetadata)
+	}
+	*m = PendingMetadata{}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestPendingMetadata(t *testing.T) {
+	var order []string
+	decoder := func(id string) MetadataDecoder {
+		return func(context.Context) map[string]interface{} {
+			order = append(order, id)
+			return map[string]interface{}{"id": id}
+		}
+	}
+
+	a, b, c := &types.Transaction{Id: "a"}, &types.Transaction{Id: "b"}, &types.Transaction{Id: "c"}
+	pending := NewPendingMetadata()
+	pending.Add(a, decoder("a"))
+	pending.AddDecoded(b, map[string]interface{}{"id": "b"})
+	pending.Add(c, decoder("c"))
+	require.Equal(t, 3, pending.Len())
+
+	// the metadata is only set once rendered
+	require.NoError(t, pending.Decode(context.Background()))
+	require.Equal(t, []string{"a", "c"}, order)
+	require.Empty(t, a.TxMetadata)
+
+	pending.Render()
+	require.Equal(t, `{"id":"a"}`, a.TxMetadata)
+	require.Equal(t, `{"id":"b"}`, b.TxMetadata)
+	require.Equal(t, `{"id":"c"}`, c.TxMetadata)
+	require.Zero(t, pending.Len())
+
+	// decoding stops once the context is done, and the txs not decoded are not rendered
+	order = nil
+	d := &types.Transaction{Id: "d"}
+	pending.Add(d, decoder("d"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, pending.Decode(ctx), context.Canceled)
+	pending.Render()
+	require.Empty(t, order)
+	require.Empty(t, d.TxMetadata)
+
+	// a nil queue is a no-op
+	var none *PendingMetadata
+	require.NoError(t, none.Decode(context.Background()))
+	none.Render()
+	require.Zero(t, none.Len())
+}
@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types"
+)
+
+// IsImplicitMessage returns whether the message was sent by the system actor (block rewards, cron). Implicit
+// messages are applied by the VM but are not part of the blocks, so they have no receipt.
+func IsImplicitMessage(from address.Address) bool {
+	return from == builtin.SystemActorAddr
+}
+
+// LinkReceipt sets on the message tx the index of its receipt and, if known, the receipts root of the tipset,
+// so the receipt can be verified against the chain with a proof of the receipts AMT
+func LinkReceipt(tx *types.Transaction, receiptIndex uint64, receiptsRoot cid.Cid) {
+	tx.ReceiptIndex = &receiptIndex
+	if receiptsRoot.Defined() {
+		tx.ReceiptsRoot = receiptsRoot.String()
+	}
+}
+
+// JoinReceiptReturn returns the return of a message trace completed with the receipt of the message delivered
+// separately, see types.TxsData.Receipts. The receipt return is used if the trace return is missing or truncated,
+// i.e. a prefix of it. Receipts whose exit code does not match the trace are not joined, as they are not the
+// receipt of the execution traced. It returns whether the receipt return is used.
+func JoinReceiptReturn(traceReturn []byte, traceExitCode exitcode.ExitCode, receipt filTypes.MessageReceipt) ([]byte, bool) {
+	if receipt.ExitCode != traceExitCode || len(receipt.Return) <= len(traceReturn) || !bytes.HasPrefix(receipt.Return, traceReturn) {
+		return traceReturn, false
+	}
+	return receipt.Return, true
+}
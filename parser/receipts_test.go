@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestIsImplicitMessage(t *testing.T) {
+	sender, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	require.True(t, IsImplicitMessage(builtin.SystemActorAddr))
+	require.False(t, IsImplicitMessage(sender))
+}
+
+func TestLinkReceipt(t *testing.T) {
+	tx := &types.Transaction{}
+	LinkReceipt(tx, 0, cid.Undef)
+	require.NotNil(t, tx.ReceiptIndex)
+	require.Equal(t, uint64(0), *tx.ReceiptIndex)
+	require.Empty(t, tx.ReceiptsRoot)
+
+	root := cid.MustParse("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+	LinkReceipt(tx, 3, root)
+	require.Equal(t, uint64(3), *tx.ReceiptIndex)
+	require.Equal(t, root.String(), tx.ReceiptsRoot)
+}
+
+func TestJoinReceiptReturn(t *testing.T) {
+	receipt := filTypes.MessageReceipt{ExitCode: exitcode.Ok, Return: []byte{0x82, 0x01, 0x02}, GasUsed: 10}
+	tests := []struct {
+		name        string
+		traceReturn []byte
+		exitCode    exitcode.ExitCode
+		want        []byte
+		joined      bool
+	}{
+		{name: "missing return", exitCode: exitcode.Ok, want: receipt.Return, joined: true},
+		{name: "truncated return", traceReturn: []byte{0x82, 0x01}, exitCode: exitcode.Ok, want: receipt.Return, joined: true},
+		{name: "complete return", traceReturn: receipt.Return, exitCode: exitcode.Ok, want: receipt.Return},
+		{name: "different return", traceReturn: []byte{0x83}, exitCode: exitcode.Ok, want: []byte{0x83}},
+		{name: "different exit code", exitCode: exitcode.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, joined := JoinReceiptReturn(tt.traceReturn, tt.exitCode, receipt)
+			require.Equal(t, tt.joined, joined)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
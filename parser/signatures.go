@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	// SignatureTypeUnknown is the type of the signatures not supported by the parser, e.g. types added by a
+	// future network upgrade. Their raw bytes are kept in the metadata, see SignatureInfo.
+	SignatureTypeUnknown = "unknown"
+
+	// UnknownSignaturesPassThrough keeps the messages with an unknown signature type, flagged with
+	// DiagnosticUnknownSignature
+	UnknownSignaturesPassThrough = "pass_through"
+	// UnknownSignaturesDrop skips the messages with an unknown signature type
+	UnknownSignaturesDrop = "drop"
+)
+
+var unknownSignatureHandlings = []string{UnknownSignaturesPassThrough, UnknownSignaturesDrop}
+
+// SignatureInfo is the signature of a message, stored in its metadata under SignatureKey.
+// Data is only set for unknown signature types, so they can be decoded later.
+type SignatureInfo struct {
+	Type     string `json:"type"`
+	TypeCode byte   `json:"typeCode"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// NewSignatureInfo describes the signature. Types that are unknown or invalid for go-state-types are
+// reported as SignatureTypeUnknown.
+func NewSignatureInfo(signature crypto.Signature) SignatureInfo {
+	name, err := signature.Type.Name()
+	if err != nil || signature.Type == crypto.SigTypeUnknown {
+		return SignatureInfo{Type: SignatureTypeUnknown, TypeCode: byte(signature.Type), Data: signature.Data}
+	}
+	return SignatureInfo{Type: name, TypeCode: byte(signature.Type)}
+}
+
+// IsUnknown returns whether the signature type is not supported
+func (s SignatureInfo) IsUnknown() bool {
+	return s.Type == SignatureTypeUnknown
+}
+
+// AddSignatureMetadata adds the signature to the metadata of the tx. Unknown signatures also flag the
+// tx with DiagnosticUnknownSignature.
+func AddSignatureMetadata(tx *types.Transaction, signature SignatureInfo) error {
+	if err := mergeTxMetadata(tx, SignatureKey, signature); err != nil {
+		return err
+	}
+
+	if signature.IsUnknown() {
+		tx.Diagnostics = append(tx.Diagnostics, types.DiagnosticUnknownSignature)
+	}
+	return nil
+}
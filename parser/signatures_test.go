@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestNewSignatureInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature crypto.Signature
+		want      SignatureInfo
+	}{
+		{name: "secp256k1", signature: crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte{1}}, want: SignatureInfo{Type: "secp256k1", TypeCode: 1}},
+		{name: "bls", signature: crypto.Signature{Type: crypto.SigTypeBLS, Data: []byte{1}}, want: SignatureInfo{Type: "bls", TypeCode: 2}},
+		{name: "delegated", signature: crypto.Signature{Type: crypto.SigTypeDelegated, Data: []byte{1}}, want: SignatureInfo{Type: "delegated", TypeCode: 3}},
+		{name: "future type", signature: crypto.Signature{Type: 4, Data: []byte{1, 2}}, want: SignatureInfo{Type: SignatureTypeUnknown, TypeCode: 4, Data: []byte{1, 2}}},
+		{name: "unknown type", signature: crypto.Signature{Type: crypto.SigTypeUnknown, Data: []byte{3}}, want: SignatureInfo{Type: SignatureTypeUnknown, TypeCode: 255, Data: []byte{3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewSignatureInfo(tt.signature))
+		})
+	}
+}
+
+func TestAddSignatureMetadata(t *testing.T) {
+	tx := &types.Transaction{Id: "tx", TxMetadata: `{"Params":"1"}`}
+	require.NoError(t, AddSignatureMetadata(tx, NewSignatureInfo(crypto.Signature{Type: 4, Data: []byte{1, 2}})))
+	require.Equal(t, []string{types.DiagnosticUnknownSignature}, tx.Diagnostics)
+
+	var metadata map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(tx.TxMetadata), &metadata))
+	require.JSONEq(t, `"1"`, string(metadata[ParamsKey]))
+	require.JSONEq(t, `{"type":"unknown","typeCode":4,"data":"AQI="}`, string(metadata[SignatureKey]))
+
+	tx = &types.Transaction{Id: "tx"}
+	require.NoError(t, AddSignatureMetadata(tx, NewSignatureInfo(crypto.Signature{Type: crypto.SigTypeBLS})))
+	require.Empty(t, tx.Diagnostics)
+	require.JSONEq(t, `{"signature":{"type":"bls","typeCode":2}}`, tx.TxMetadata)
+}
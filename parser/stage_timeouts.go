@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// StageTimeoutFail fails the parsing of the height when a stage runs out of its timeout budget
+	StageTimeoutFail = "fail"
+	// StageTimeoutBestEffort keeps what the stage did before running out of its timeout budget and goes on with
+	// the next stages, reporting the stage in ParseReport.TimedOutSteps. Stages that can not be skipped (e.g. the
+	// decoding of the traces) fail anyway.
+	StageTimeoutBestEffort = "best_effort"
+)
+
+var stageTimeoutHandlings = []string{StageTimeoutFail, StageTimeoutBestEffort}
+
+// WithStageTimeout returns a context cancelled after the timeout budget of a stage. A zero timeout means no
+// budget, so the parent context is returned as is.
+func WithStageTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// StageBudget is the time budget of a stage spread over many calls, e.g. the address lookups of a height. The zero
+// value and a nil StageBudget have no budget.
+type StageBudget struct {
+	timeout  time.Duration
+	deadline time.Time
+}
+
+// NewStageBudget starts a budget of the timeout. A zero timeout means no budget.
+func NewStageBudget(timeout time.Duration) *StageBudget {
+	if timeout <= 0 {
+		return &StageBudget{}
+	}
+	return &StageBudget{timeout: timeout, deadline: time.Now().Add(timeout)}
+}
+
+// Context returns a context cancelled once the budget runs out
+func (b *StageBudget) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b == nil || b.deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, b.deadline)
+}
+
+// Exceeded returns true once the budget ran out
+func (b *StageBudget) Exceeded() bool {
+	return b != nil && !b.deadline.IsZero() && !time.Now().Before(b.deadline)
+}
+
+// Timeout returns the timeout the budget was started with
+func (b *StageBudget) Timeout() time.Duration {
+	if b == nil {
+		return 0
+	}
+	return b.timeout
+}
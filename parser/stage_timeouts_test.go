@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageBudget(t *testing.T) {
+	// no budget
+	var budget *StageBudget
+	require.False(t, budget.Exceeded())
+	ctx, cancel := budget.Context(context.Background())
+	defer cancel()
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+	require.False(t, NewStageBudget(0).Exceeded())
+
+	budget = NewStageBudget(time.Hour)
+	require.False(t, budget.Exceeded())
+	require.Equal(t, time.Hour, budget.Timeout())
+	ctx, cancel = budget.Context(context.Background())
+	defer cancel()
+	_, ok = ctx.Deadline()
+	require.True(t, ok)
+
+	budget = NewStageBudget(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	require.True(t, budget.Exceeded())
+	ctx, cancel = budget.Context(context.Background())
+	defer cancel()
+	require.Error(t, ctx.Err())
+}
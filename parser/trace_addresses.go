@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// traceAddress matches the json strings that may be addresses of the known protocols
+var traceAddress = regexp.MustCompile(`"[ft][0-4][a-z0-9]+"`)
+
+// ExtractAddresses returns the unique addresses found in the raw traces, sorted, without decoding them. It is
+// much cheaper than parsing the traces, e.g. to warm up the actors cache before a backfill; the json strings that
+// look like addresses but can not be decoded are ignored.
+// Only the addresses written as json strings are found, i.e. the senders and receivers of the messages and calls.
+// The addresses inside the CBOR encoded params and return values (e.g. the signers of a multisig, the control
+// addresses of a miner or the actors created by Exec) are not decoded, so they are resolved by the parser itself.
+func ExtractAddresses(traces []byte) []address.Address {
+	seen := make(map[string]bool)
+	addresses := make([]address.Address, 0)
+	for _, match := range traceAddress.FindAll(traces, -1) {
+		candidate := string(match[1 : len(match)-1])
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		addr, err := address.NewFromString(candidate)
+		if err != nil || addr == address.Undef {
+			continue
+		}
+		addresses = append(addresses, addr)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].String() < addresses[j].String() })
+	return addresses
+}
@@ -0,0 +1,19 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractAddresses(t *testing.T) {
+	traces := []byte(`{"Trace":[{"Msg":{"From":"f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea","To":"f01000","Params":"f0abc"}},` +
+		`{"Msg":{"From":"f01000","To":"f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"}},{"Msg":{"From":"f1invalidchecksum","To":"f9abc"}}]}`)
+
+	var got []string
+	for _, addr := range ExtractAddresses(traces) {
+		got = append(got, addr.String())
+	}
+	require.Equal(t, []string{"f01000", "f1ljefareoomkuplzvk5zkk3cjeq25fjdbs2gwzea", "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa"}, got)
+	require.Empty(t, ExtractAddresses([]byte(`{"Trace":[]}`)))
+}
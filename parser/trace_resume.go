@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+// TraceResume checkpoints the progress of the parse of a tipset, see types.TxsData.Checkpointer.
+// Without checkpointer every method is a no-op.
+type TraceResume struct {
+	checkpointer types.TraceCheckpointer
+	interval     int
+	key          types.TraceCheckpointKey
+	start        int
+	checkpoint   *types.TraceCheckpoint
+	saved        savedTraceState
+	logger       *zap.Logger
+}
+
+// savedTraceState is what was already checkpointed, so each checkpoint only holds what was parsed since the
+// previous one
+type savedTraceState struct {
+	txs            int
+	txCids         int
+	tokenTransfers int
+	skippedTraces  int
+	addresses      map[string]types.AddressInfo
+}
+
+// NewTraceResume loads the checkpoints the parser version saved for the tipset, if there are any
+func NewTraceResume(txsData types.TxsData, parserVersion string, logger *zap.Logger) (*TraceResume, error) {
+	resume := &TraceResume{
+		checkpointer: txsData.Checkpointer,
+		interval:     txsData.CheckpointInterval,
+		saved:        savedTraceState{addresses: make(map[string]types.AddressInfo)},
+		logger:       logger,
+	}
+	if resume.checkpointer == nil || txsData.Tipset == nil {
+		resume.checkpointer = nil
+		return resume, nil
+	}
+	if resume.interval <= 0 {
+		resume.interval = types.DefaultTraceCheckpointInterval
+	}
+	resume.key = types.TraceCheckpointKey{ParserVersion: parserVersion, TipsetCid: txsData.Tipset.GetCidString()}
+
+	checkpoints, err := resume.checkpointer.Load(resume.key)
+	if err != nil {
+		return nil, fmt.Errorf("could not load checkpoints of tipset %s: %w", resume.key.TipsetCid, err)
+	}
+	if checkpoint := resume.merge(checkpoints); checkpoint != nil {
+		logger.Sugar().Infof("[parser] - resuming tipset %s from trace %d", resume.key.TipsetCid, checkpoint.NextTrace)
+		resume.checkpoint = checkpoint
+		resume.start = checkpoint.NextTrace
+		resume.saved.mark(checkpoint)
+	}
+	return resume, nil
+}
+
+// merge merges the checkpoints of the tipset into a single one, or returns nil if there is none
+func (r *TraceResume) merge(checkpoints []*types.TraceCheckpoint) *types.TraceCheckpoint {
+	var merged *types.TraceCheckpoint
+	for _, checkpoint := range checkpoints {
+		if checkpoint == nil || checkpoint.Key() != r.key {
+			continue
+		}
+		if merged == nil {
+			merged = &types.TraceCheckpoint{ParserVersion: r.key.ParserVersion, TipsetCid: r.key.TipsetCid, Addresses: make(map[string]*types.AddressInfo)}
+		}
+		merged.NextTrace = checkpoint.NextTrace
+		for key, info := range checkpoint.Addresses {
+			merged.Addresses[key] = info
+		}
+		merged.Txs = append(merged.Txs, checkpoint.Txs...)
+		merged.TxCids = append(merged.TxCids, checkpoint.TxCids...)
+		merged.TokenTransfers = append(merged.TokenTransfers, checkpoint.TokenTransfers...)
+		merged.SkippedTraces = append(merged.SkippedTraces, checkpoint.SkippedTraces...)
+	}
+	return merged
+}
+
+// Enabled returns whether the progress is checkpointed
+func (r *TraceResume) Enabled() bool {
+	return r.checkpointer != nil
+}
+
+// Checkpoint returns the merged checkpoints, or nil if the tipset is parsed from the start
+func (r *TraceResume) Checkpoint() *types.TraceCheckpoint {
+	return r.checkpoint
+}
+
+// Skip returns true for the traces already parsed before the checkpoint
+func (r *TraceResume) Skip(trace int) bool {
+	return trace < r.start
+}
+
+// Save stores a checkpoint every interval traces. It must be called before parsing the trace, with the
+// state built from the previous ones; only what changed since the previous checkpoint is stored.
+// Errors are only logged: the parse is still valid without checkpoint.
+func (r *TraceResume) Save(trace int, state func() *types.TraceCheckpoint) {
+	if r.checkpointer == nil || trace == r.start || (trace-r.start)%r.interval != 0 {
+		return
+	}
+
+	checkpoint := r.saved.delta(state())
+	checkpoint.ParserVersion = r.key.ParserVersion
+	checkpoint.TipsetCid = r.key.TipsetCid
+	checkpoint.NextTrace = trace
+	if err := r.checkpointer.Save(checkpoint); err != nil {
+		// the next checkpoint holds this delta too
+		r.logger.Sugar().Warnf("[parser] - could not save checkpoint of tipset %s at trace %d: %s", r.key.TipsetCid, trace, err)
+		return
+	}
+	r.saved.mark(checkpoint)
+}
+
+// Done deletes the checkpoints of the fully parsed tipset
+func (r *TraceResume) Done() {
+	if r.checkpointer == nil {
+		return
+	}
+	if err := r.checkpointer.Delete(r.key); err != nil {
+		r.logger.Sugar().Warnf("[parser] - could not delete checkpoints of tipset %s: %s", r.key.TipsetCid, err)
+	}
+}
+
+// delta returns what the full state holds that was not saved yet
+func (s *savedTraceState) delta(state *types.TraceCheckpoint) *types.TraceCheckpoint {
+	delta := &types.TraceCheckpoint{
+		Addresses:      make(map[string]*types.AddressInfo),
+		Txs:            state.Txs[min(s.txs, len(state.Txs)):],
+		TxCids:         state.TxCids[min(s.txCids, len(state.TxCids)):],
+		TokenTransfers: state.TokenTransfers[min(s.tokenTransfers, len(state.TokenTransfers)):],
+		SkippedTraces:  state.SkippedTraces[min(s.skippedTraces, len(state.SkippedTraces)):],
+	}
+	for key, info := range state.Addresses {
+		if saved, ok := s.addresses[key]; info != nil && (!ok || saved != *info) {
+			delta.Addresses[key] = info
+		}
+	}
+	return delta
+}
+
+// mark records the checkpoint as saved
+func (s *savedTraceState) mark(checkpoint *types.TraceCheckpoint) {
+	s.txs += len(checkpoint.Txs)
+	s.txCids += len(checkpoint.TxCids)
+	s.tokenTransfers += len(checkpoint.TokenTransfers)
+	s.skippedTraces += len(checkpoint.SkippedTraces)
+	for key, info := range checkpoint.Addresses {
+		if info != nil {
+			s.addresses[key] = *info
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+type memoryTraceCheckpointer struct {
+	checkpoints map[types.TraceCheckpointKey][]*types.TraceCheckpoint
+	saved       []int
+}
+
+func (m *memoryTraceCheckpointer) Load(key types.TraceCheckpointKey) ([]*types.TraceCheckpoint, error) {
+	return m.checkpoints[key], nil
+}
+
+func (m *memoryTraceCheckpointer) Save(checkpoint *types.TraceCheckpoint) error {
+	m.checkpoints[checkpoint.Key()] = append(m.checkpoints[checkpoint.Key()], checkpoint)
+	m.saved = append(m.saved, checkpoint.NextTrace)
+	return nil
+}
+
+func (m *memoryTraceCheckpointer) Delete(key types.TraceCheckpointKey) error {
+	delete(m.checkpoints, key)
+	return nil
+}
+
+// parseTraces simulates a parse of the traces that crashes at the given trace
+func parseTraces(t *testing.T, txsData types.TxsData, parserVersion string, traces, crashAt int) []string {
+	resume, err := NewTraceResume(txsData, parserVersion, zap.NewNop())
+	require.NoError(t, err)
+
+	var parsed []string
+	if checkpoint := resume.Checkpoint(); checkpoint != nil {
+		for _, tx := range checkpoint.Txs {
+			parsed = append(parsed, tx.Id)
+		}
+	}
+
+	for i := 0; i < traces; i++ {
+		if resume.Skip(i) {
+			continue
+		}
+		resume.Save(i, func() *types.TraceCheckpoint {
+			checkpoint := &types.TraceCheckpoint{}
+			for _, id := range parsed {
+				checkpoint.Txs = append(checkpoint.Txs, &types.Transaction{Id: id})
+			}
+			return checkpoint
+		})
+		if i == crashAt {
+			return parsed
+		}
+		parsed = append(parsed, string(rune('a'+i)))
+	}
+	resume.Done()
+	return parsed
+}
+
+func TestTraceResume(t *testing.T) {
+	checkpointer := &memoryTraceCheckpointer{checkpoints: map[types.TraceCheckpointKey][]*types.TraceCheckpoint{}}
+	txsData := types.TxsData{Tipset: &types.ExtendedTipSet{}, Checkpointer: checkpointer, CheckpointInterval: 2}
+	key := types.TraceCheckpointKey{ParserVersion: "v1", TipsetCid: txsData.Tipset.GetCidString()}
+
+	// the process crashes after checkpointing trace 4
+	parseTraces(t, txsData, "v1", 6, 5)
+	require.Equal(t, []int{2, 4}, checkpointer.saved)
+
+	// each checkpoint only holds the txs parsed since the previous one
+	checkpoints := checkpointer.checkpoints[key]
+	require.Len(t, checkpoints, 2)
+	require.Equal(t, []*types.Transaction{{Id: "a"}, {Id: "b"}}, checkpoints[0].Txs)
+	require.Equal(t, []*types.Transaction{{Id: "c"}, {Id: "d"}}, checkpoints[1].Txs)
+
+	// other parser versions do not resume the checkpoints
+	checkpointer.saved = nil
+	parsed := parseTraces(t, txsData, "v2", 3, -1)
+	require.Equal(t, []string{"a", "b", "c"}, parsed)
+	require.Equal(t, []int{2}, checkpointer.saved)
+	require.Len(t, checkpointer.checkpoints[key], 2)
+
+	// the next run resumes from trace 4 and deletes the checkpoints once done
+	checkpointer.saved = nil
+	parsed = parseTraces(t, txsData, "v1", 6, -1)
+	require.Equal(t, []string{"a", "b", "c", "d", "e", "f"}, parsed)
+	require.Empty(t, checkpointer.saved)
+	require.Empty(t, checkpointer.checkpoints)
+}
+
+func TestTraceResume_Addresses(t *testing.T) {
+	checkpointer := &memoryTraceCheckpointer{checkpoints: map[types.TraceCheckpointKey][]*types.TraceCheckpoint{}}
+	txsData := types.TxsData{Tipset: &types.ExtendedTipSet{}, Checkpointer: checkpointer, CheckpointInterval: 1}
+	resume, err := NewTraceResume(txsData, "v1", zap.NewNop())
+	require.NoError(t, err)
+
+	addresses := map[string]*types.AddressInfo{"f01000": {Short: "f01000"}}
+	resume.Save(1, func() *types.TraceCheckpoint { return &types.TraceCheckpoint{Addresses: addresses} })
+	addresses["f01001"] = &types.AddressInfo{Short: "f01001"}
+	resume.Save(2, func() *types.TraceCheckpoint { return &types.TraceCheckpoint{Addresses: addresses} })
+	addresses["f01000"] = &types.AddressInfo{Short: "f01000", ActorType: "account"}
+	resume.Save(3, func() *types.TraceCheckpoint { return &types.TraceCheckpoint{Addresses: addresses} })
+
+	// only the new and updated addresses are saved, and merged on resume
+	checkpoints := checkpointer.checkpoints[types.TraceCheckpointKey{ParserVersion: "v1", TipsetCid: txsData.Tipset.GetCidString()}]
+	require.Len(t, checkpoints, 3)
+	require.Equal(t, []string{"f01001"}, mapKeys(checkpoints[1].Addresses))
+	require.Equal(t, []string{"f01000"}, mapKeys(checkpoints[2].Addresses))
+
+	resume, err = NewTraceResume(txsData, "v1", zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, 3, resume.Checkpoint().NextTrace)
+	require.Equal(t, addresses, resume.Checkpoint().Addresses)
+}
+
+func mapKeys(m map[string]*types.AddressInfo) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestTraceResume_NoCheckpointer(t *testing.T) {
+	parsed := parseTraces(t, types.TxsData{Tipset: &types.ExtendedTipSet{}}, "v1", 3, -1)
+	require.Equal(t, []string{"a", "b", "c"}, parsed)
+}
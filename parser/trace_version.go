@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+const (
+	// TraceFormatV1 is the layout of the traces of lotus nodes before v1.23, where execution traces embed the whole message
+	TraceFormatV1 = "v1"
+	// TraceFormatV2 is the layout of the traces of lotus v1.23+ (and forest) nodes, with message and return traces
+	TraceFormatV2 = "v2"
+
+	// TraceVersionMismatchAutoCorrect parses the traces with the parser matching their layout, logging a warning
+	TraceVersionMismatchAutoCorrect = "auto_correct"
+	// TraceVersionMismatchError fails the parsing of traces whose layout does not match the node version of their metadata
+	TraceVersionMismatchError = "error"
+	// TraceVersionMismatchIgnore always uses the parser of the node version of the metadata
+	TraceVersionMismatchIgnore = "ignore"
+)
+
+var traceVersionMismatchHandlings = []string{TraceVersionMismatchAutoCorrect, TraceVersionMismatchError, TraceVersionMismatchIgnore}
+
+// ErrVersionMismatch is returned, wrapped in a VersionMismatchError, when the layout of the traces does not match
+// the node version of their metadata
+var ErrVersionMismatch = errors.New("trace format does not match the node version")
+
+// VersionMismatchError has the details of an ErrVersionMismatch
+type VersionMismatchError struct {
+	// NodeVersion is the node version claimed by the metadata of the traces
+	NodeVersion string
+	// ExpectedFormat is the trace format of NodeVersion
+	ExpectedFormat string
+	// DetectedFormat is the trace format found in the traces
+	DetectedFormat string
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("%s: node version %s uses %s traces, but %s traces were found", ErrVersionMismatch, e.NodeVersion,
+		e.ExpectedFormat, e.DetectedFormat)
+}
+
+func (e *VersionMismatchError) Unwrap() error {
+	return ErrVersionMismatch
+}
+
+// traceFormatV2Markers are keys of the message and return traces that only the v2 layout has
+var traceFormatV2Markers = [][]byte{
+	[]byte(`"ParamsCodec"`),
+	[]byte(`"ReturnCodec"`),
+	[]byte(`"InvokedActor"`),
+}
+
+// DetectTraceFormat returns the layout of the raw traces (see the TraceFormat constants), or an empty string if
+// it can not be told, e.g. the tipset has no messages
+func DetectTraceFormat(rawTraces []byte) string {
+	for _, marker := range traceFormatV2Markers {
+		if bytes.Contains(rawTraces, marker) {
+			return TraceFormatV2
+		}
+	}
+	if bytes.Contains(rawTraces, []byte(`"ExecutionTrace"`)) {
+		return TraceFormatV1
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectTraceFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		traces string
+		want   string
+	}{
+		{
+			name:   "v1 traces embed the whole message",
+			traces: `{"Trace": [{"ExecutionTrace": {"Msg": {"Version": 0, "To": "f01", "From": "f02", "Nonce": 1}, "MsgRct": {"ExitCode": 0, "GasUsed": 10}}}]}`,
+			want:   TraceFormatV1,
+		},
+		{
+			name:   "v2 traces",
+			traces: `{"Trace": [{"ExecutionTrace": {"Msg": {"To": "f01", "From": "f02", "ParamsCodec": 0}, "MsgRct": {"ExitCode": 0, "ReturnCodec": 0}}}]}`,
+			want:   TraceFormatV2,
+		},
+		{
+			name:   "without messages",
+			traces: `{"Trace": []}`,
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DetectTraceFormat([]byte(tt.traces)))
+		})
+	}
+}
+
+func TestVersionMismatchError(t *testing.T) {
+	var err error = &VersionMismatchError{NodeVersion: "v1.23", ExpectedFormat: TraceFormatV2, DetectedFormat: TraceFormatV1}
+	require.True(t, errors.Is(err, ErrVersionMismatch))
+	require.Contains(t, err.Error(), "v1.23")
+
+	var mismatch *VersionMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, TraceFormatV1, mismatch.DetectedFormat)
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName is the instrumentation name of the spans of the parser
+const TracerName = "github.com/zondax/fil-parser"
+
+// Span names. The actor decoding and cache lookup spans are children of the span carried by the context, i.e.
+// of the span of the Parse method they run in; the lookups made through the methods without context (e.g.
+// GetActorCode) start the roots of their own traces.
+const (
+	SpanParseTransactions = "fil-parser.ParseTransactions"
+	SpanParseMessages     = "fil-parser.ParseMessages"
+	SpanParseFees         = "fil-parser.ParseFees"
+	SpanParseNativeEvents = "fil-parser.ParseNativeEvents"
+	SpanParseEthLogs      = "fil-parser.ParseEthLogs"
+	SpanParseEthTraces    = "fil-parser.ParseEthTraces"
+	SpanDecodeActor       = "fil-parser.actor.Decode"
+	SpanCacheLookup       = "fil-parser.cache.Lookup"
+)
+
+// Span attributes
+const (
+	AttributeHeight        = attribute.Key("fil.height")
+	AttributeParserVersion = attribute.Key("fil.parser_version")
+	AttributeActor         = attribute.Key("fil.actor")
+	AttributeTxType        = attribute.Key("fil.tx_type")
+	AttributeCacheMethod   = attribute.Key("fil.cache.method")
+	// AttributeCacheTier is the tier that served the lookup, see the CacheTier constants
+	AttributeCacheTier = attribute.Key("fil.cache.tier")
+)
+
+// Cache tiers
+const (
+	CacheTierOffChain   = "off_chain"
+	CacheTierOnChain    = "on_chain"
+	CacheTierSystem     = "system"
+	CacheTierBadAddress = "bad_address"
+)
+
+// NewTracer returns the tracer of the parser from the provider. Tracing is disabled if the provider is nil.
+func NewTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"encoding/json"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// NewTransactionsV2 builds the TransactionV2 view of the txs of a tipset. The call path and the gas breakdown
+// are taken from the other txs, so all the txs of the tipset must be provided. txCids are the translations
+// returned along the txs and are used to set the eth hash of the messages.
+func NewTransactionsV2(txs []*types.Transaction, txCids []types.TxCidTranslation) []*types.TransactionV2 {
+	ethHashes := make(map[string]string, len(txCids))
+	for _, translation := range txCids {
+		ethHashes[translation.TxCid] = translation.TxHash
+	}
+
+	byId := make(map[string]*types.Transaction, len(txs))
+	gasBreakdowns := make(map[string]*types.GasBreakdown)
+	for _, tx := range txs {
+		byId[tx.Id] = tx
+		if tx.TxType == TotalFeeOp {
+			gasBreakdowns[tx.ParentId] = newGasBreakdown(tx)
+		}
+	}
+
+	txsV2 := make([]*types.TransactionV2, 0, len(txs))
+	for _, tx := range txs {
+		txV2 := &types.TransactionV2{
+			Transaction:  *tx,
+			ExitCodeName: tx.Status,
+			EthHash:      ethHashes[tx.TxCid],
+			CallPath:     callPath(tx, byId),
+			GasBreakdown: gasBreakdowns[tx.Id],
+		}
+
+		if txV2.EthHash == "" {
+			txV2.EthHash = ethHashFromMetadata(tx)
+		}
+		txsV2 = append(txsV2, txV2)
+	}
+
+	return txsV2
+}
+
+// callPath walks up the parents of the tx. Parents that are not in the list end the path.
+func callPath(tx *types.Transaction, byId map[string]*types.Transaction) []string {
+	var path []string
+	visited := map[string]bool{tx.Id: true}
+	for parent, ok := byId[tx.ParentId]; ok && !visited[parent.Id]; parent, ok = byId[parent.ParentId] {
+		visited[parent.Id] = true
+		path = append([]string{parent.Id}, path...)
+	}
+	return path
+}
+
+func newGasBreakdown(feeTx *types.Transaction) *types.GasBreakdown {
+	rawMetadata, err := feeTx.GetMetadata()
+	if err != nil {
+		return nil
+	}
+
+	var metadata FeesMetadata
+	if err = json.Unmarshal([]byte(rawMetadata), &metadata); err != nil {
+		return nil
+	}
+
+	breakdown := &types.GasBreakdown{
+		BaseFeeBurn:        metadata.BurnFee.Amount,
+		OverEstimationBurn: metadata.OverEstimationBurnFee.Amount,
+		MinerTip:           metadata.MinerFee.Amount,
+		Source:             metadata.GasOutputsSource,
+	}
+	if metadata.RefundFee != nil {
+		breakdown.Refund = metadata.RefundFee.Amount
+	}
+	if metadata.MinerPenalty != nil {
+		breakdown.MinerPenalty = metadata.MinerPenalty.Amount
+	}
+	if feeTx.Amount != nil {
+		breakdown.TotalCost = feeTx.Amount.String()
+	}
+	return breakdown
+}
+
+func ethHashFromMetadata(tx *types.Transaction) string {
+	rawMetadata, err := tx.GetMetadata()
+	if err != nil {
+		return ""
+	}
+
+	var metadata map[string]interface{}
+	if err = json.Unmarshal([]byte(rawMetadata), &metadata); err != nil {
+		return ""
+	}
+	ethHash, _ := metadata[EthHashKey].(string)
+	return ethHash
+}
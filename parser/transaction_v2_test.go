@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestNewTransactionsV2(t *testing.T) {
+	feesMetadata, err := json.Marshal(FeesMetadata{
+		TxType:                MethodInvokeContract,
+		MinerFee:              MinerFee{MinerAddress: "f01000", Amount: "10"},
+		OverEstimationBurnFee: OverEstimationBurnFee{BurnAddress: BurnAddress, Amount: "20"},
+		BurnFee:               BurnFee{BurnAddress: BurnAddress, Amount: "30"},
+		RefundFee:             &RefundFee{RefundAddress: "f01001", Amount: "5"},
+		MinerPenalty:          &MinerPenalty{MinerAddress: "f01000", Amount: "2"},
+		GasOutputsSource:      GasOutputsSourceTrace,
+	})
+	require.NoError(t, err)
+
+	txs := []*types.Transaction{
+		{Id: "main", TxCid: "cid1", Status: "Ok", TxType: MethodInvokeContract, TxMetadata: "{}"},
+		{Id: "sub", ParentId: "main", Level: 1, TxCid: "cid1", Status: "ErrForbidden", TxType: MethodSend},
+		{Id: "subsub", ParentId: "sub", Level: 2, TxCid: "cid1", Status: StatusReverted, TxType: MethodSend},
+		{Id: "fee", ParentId: "main", TxCid: "cid1", Status: "Ok", TxType: TotalFeeOp, Amount: big.NewInt(60), TxMetadata: string(feesMetadata)},
+		{Id: "create", TxCid: "cid2", Status: "Ok", TxType: MethodCreateExternal, TxMetadata: `{"ethHash":"0x02"}`},
+	}
+
+	txsV2 := NewTransactionsV2(txs, []types.TxCidTranslation{{TxCid: "cid1", TxHash: "0x01"}})
+	require.Len(t, txsV2, len(txs))
+
+	require.Equal(t, "0x01", txsV2[0].EthHash)
+	require.Empty(t, txsV2[0].CallPath)
+	require.Equal(t, &types.GasBreakdown{BaseFeeBurn: "30", OverEstimationBurn: "20", MinerTip: "10", Refund: "5", TotalCost: "60",
+		MinerPenalty: "2", Source: GasOutputsSourceTrace}, txsV2[0].GasBreakdown)
+
+	require.Equal(t, "ErrForbidden", txsV2[1].ExitCodeName)
+	require.Equal(t, []string{"main"}, txsV2[1].CallPath)
+	require.Nil(t, txsV2[1].GasBreakdown)
+	require.Equal(t, []string{"main", "sub"}, txsV2[2].CallPath)
+	require.Equal(t, "0x02", txsV2[4].EthHash)
+
+	// legacy txs are kept as they were
+	legacy := types.ToLegacyTransactions(txsV2)
+	for i := range txs {
+		require.Equal(t, *txs[i], *legacy[i])
+	}
+}
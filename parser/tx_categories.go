@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"slices"
+
+	"github.com/filecoin-project/go-state-types/manifest"
+)
+
+// TxType is a tx type generated by the parser, see TxTypesByActor
+type TxType string
+
+// TxCategory groups the tx types by what they do, so downstream filters do not depend on tx type names
+type TxCategory string
+
+const (
+	TxCategoryTransfer   TxCategory = "transfer"
+	TxCategoryMinerOps   TxCategory = "miner-ops"
+	TxCategoryMarket     TxCategory = "market"
+	TxCategoryGovernance TxCategory = "governance"
+	TxCategoryFevm       TxCategory = "fevm"
+	TxCategoryFees       TxCategory = "fees"
+	TxCategorySystem     TxCategory = "system"
+)
+
+// TxCategories are all the categories, in the order they are documented
+var TxCategories = []TxCategory{
+	TxCategoryTransfer,
+	TxCategoryMinerOps,
+	TxCategoryMarket,
+	TxCategoryGovernance,
+	TxCategoryFevm,
+	TxCategoryFees,
+	TxCategorySystem,
+}
+
+// txCategoryOverrides are the tx types whose category does not depend on the actor generating them. They take
+// precedence over the category of the actor.
+var txCategoryOverrides = map[string]TxCategory{
+	MethodSend:                 TxCategoryTransfer,
+	MethodTransferExported:     TxCategoryTransfer,
+	MethodTransferFromExported: TxCategoryTransfer,
+	TotalFeeOp:                 TxCategoryFees,
+	GasRefundOp:                TxCategoryFees,
+	TxTypeGenesis:              TxCategorySystem,
+	UnknownStr:                 TxCategorySystem,
+	MethodConstructor:          TxCategorySystem,
+	MethodCronTick:             TxCategorySystem,
+}
+
+// txCategoriesByActor sets the category of the rest of tx types by the actor generating them. GetActorTxCategory
+// resolves the tx types generated by more than one actor (e.g. WithdrawBalance) by their actor; without actor,
+// GetTxCategory gives them the category of the first actor of the list.
+var txCategoriesByActor = []struct {
+	actor    string
+	category TxCategory
+}{
+	{manifest.MinerKey, TxCategoryMinerOps},
+	{manifest.PowerKey, TxCategoryMinerOps},
+	{manifest.MarketKey, TxCategoryMarket},
+	{manifest.MultisigKey, TxCategoryGovernance},
+	{manifest.VerifregKey, TxCategoryGovernance},
+	{manifest.DatacapKey, TxCategoryGovernance},
+	{manifest.EvmKey, TxCategoryFevm},
+	{manifest.EamKey, TxCategoryFevm},
+	{manifest.EthAccountKey, TxCategoryFevm},
+	{manifest.PlaceholderKey, TxCategoryFevm},
+	{manifest.PaychKey, TxCategoryTransfer},
+	{manifest.AccountKey, TxCategorySystem},
+	{manifest.CronKey, TxCategorySystem},
+	{manifest.InitKey, TxCategorySystem},
+	{manifest.RewardKey, TxCategorySystem},
+}
+
+var txCategories, txCategoriesOfActors = buildTxCategories()
+
+// buildTxCategories returns the category of each tx type and of each tx type of each actor
+func buildTxCategories() (map[string]TxCategory, map[string]map[string]TxCategory) {
+	categories := make(map[string]TxCategory)
+	for txType, category := range txCategoryOverrides {
+		categories[txType] = category
+	}
+
+	byActor := make(map[string]map[string]TxCategory)
+	for _, entry := range txCategoriesByActor {
+		byActor[entry.actor] = make(map[string]TxCategory)
+		for _, txType := range TxTypesByActor[entry.actor] {
+			category, ok := txCategoryOverrides[txType]
+			if !ok {
+				category = entry.category
+			}
+			byActor[entry.actor][txType] = category
+			if _, ok := categories[txType]; !ok {
+				categories[txType] = category
+			}
+		}
+	}
+	return categories, byActor
+}
+
+// GetAllTypedTxTypes returns all the tx types of the registry as TxType, sorted and without duplicates
+func GetAllTypedTxTypes() []TxType {
+	txTypes := GetAllTxTypes()
+	typed := make([]TxType, 0, len(txTypes))
+	for _, txType := range txTypes {
+		typed = append(typed, TxType(txType))
+	}
+	return typed
+}
+
+// GetTxCategory returns the category of the tx type. Tx types generated by more than one actor get the category
+// of the first actor of txCategoriesByActor, so use GetActorTxCategory when the actor is known.
+// Tx types that are not in the registry are flagged as system.
+func GetTxCategory(txType string) TxCategory {
+	if category, ok := txCategories[txType]; ok {
+		return category
+	}
+	return TxCategorySystem
+}
+
+// GetActorTxCategory returns the category of the tx type generated by the actor, e.g. the ActorType of the TxTo
+// address. The actor-independent tx types (Send, fees...) keep their category, the rest of tx types of the actor
+// get the category of the actor, and the tx types the actor does not generate fall back to GetTxCategory.
+func GetActorTxCategory(actorName, txType string) TxCategory {
+	if category, ok := txCategoriesOfActors[actorName][txType]; ok {
+		return category
+	}
+	return GetTxCategory(txType)
+}
+
+// GetTxTypesByCategory returns the tx types of the category, sorted
+func GetTxTypesByCategory(category TxCategory) []string {
+	var txTypes []string
+	for txType, txCategory := range txCategories {
+		if txCategory == category {
+			txTypes = append(txTypes, txType)
+		}
+	}
+	slices.Sort(txTypes)
+	return txTypes
+}
+
+func (t TxType) Category() TxCategory {
+	return GetTxCategory(string(t))
+}
+
+func IsTransfer(txType string) bool {
+	return GetTxCategory(txType) == TxCategoryTransfer
+}
+
+func IsMinerOp(txType string) bool {
+	return GetTxCategory(txType) == TxCategoryMinerOps
+}
+
+func IsMarket(txType string) bool {
+	return GetTxCategory(txType) == TxCategoryMarket
+}
+
+func IsGovernance(txType string) bool {
+	return GetTxCategory(txType) == TxCategoryGovernance
+}
+
+func IsFevm(txType string) bool {
+	return GetTxCategory(txType) == TxCategoryFevm
+}
+
+func IsFee(txType string) bool {
+	return GetTxCategory(txType) == TxCategoryFees
+}
+
+func IsSystem(txType string) bool {
+	return GetTxCategory(txType) == TxCategorySystem
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCategories_AllActorsGrouped(t *testing.T) {
+	for actor := range TxTypesByActor {
+		found := false
+		for _, entry := range txCategoriesByActor {
+			found = found || entry.actor == actor
+		}
+		require.True(t, found, "actor %s has no category", actor)
+	}
+
+	total := 0
+	for _, category := range TxCategories {
+		total += len(GetTxTypesByCategory(category))
+	}
+	require.Equal(t, len(GetAllTxTypes()), total)
+	require.Len(t, GetAllTypedTxTypes(), total)
+}
+
+func TestGetTxCategory(t *testing.T) {
+	tests := []struct {
+		txType string
+		want   TxCategory
+	}{
+		{txType: MethodSend, want: TxCategoryTransfer},
+		{txType: MethodTransferExported, want: TxCategoryTransfer},
+		{txType: MethodSettle, want: TxCategoryTransfer},
+		{txType: TotalFeeOp, want: TxCategoryFees},
+		{txType: GasRefundOp, want: TxCategoryFees},
+		{txType: MethodSubmitWindowedPoSt, want: TxCategoryMinerOps},
+		{txType: MethodCreateMiner, want: TxCategoryMinerOps},
+		{txType: MethodWithdrawBalance, want: TxCategoryMinerOps},
+		{txType: MethodPublishStorageDeals, want: TxCategoryMarket},
+		{txType: MethodPropose, want: TxCategoryGovernance},
+		{txType: MethodAddVerifiedClient, want: TxCategoryGovernance},
+		{txType: MethodInvokeContract, want: TxCategoryFevm},
+		{txType: MethodCreateExternal, want: TxCategoryFevm},
+		{txType: MethodConstructor, want: TxCategorySystem},
+		{txType: MethodAwardBlockReward, want: TxCategorySystem},
+		{txType: "NotATxType", want: TxCategorySystem},
+	}
+	for _, tt := range tests {
+		t.Run(tt.txType, func(t *testing.T) {
+			require.Equal(t, tt.want, GetTxCategory(tt.txType))
+			require.Equal(t, tt.want, TxType(tt.txType).Category())
+		})
+	}
+
+	require.True(t, IsTransfer(MethodSend))
+	require.False(t, IsTransfer(TotalFeeOp))
+	require.True(t, IsFee(TotalFeeOp))
+	require.True(t, IsFevm(MethodInvokeContract))
+	require.True(t, IsMinerOp(MethodPreCommitSector))
+	require.True(t, IsMarket(MethodAddBalance))
+	require.True(t, IsGovernance(MethodApprove))
+	require.True(t, IsSystem(MethodEpochTick))
+}
+
+func TestGetActorTxCategory(t *testing.T) {
+	tests := []struct {
+		actor  string
+		txType string
+		want   TxCategory
+	}{
+		{actor: manifest.MarketKey, txType: MethodWithdrawBalance, want: TxCategoryMarket},
+		{actor: manifest.MinerKey, txType: MethodWithdrawBalance, want: TxCategoryMinerOps},
+		{actor: manifest.MarketKey, txType: MethodWithdrawBalanceExported, want: TxCategoryMarket},
+		{actor: manifest.MinerKey, txType: MethodWithdrawBalanceExported, want: TxCategoryMinerOps},
+		{actor: manifest.MarketKey, txType: MethodAddBalance, want: TxCategoryMarket},
+		{actor: manifest.MinerKey, txType: MethodConstructor, want: TxCategorySystem},
+		{actor: manifest.MultisigKey, txType: MethodSend, want: TxCategoryTransfer},
+		{actor: manifest.EvmKey, txType: MethodInvokeContract, want: TxCategoryFevm},
+		// tx types the actor does not generate fall back to the category of the tx type
+		{actor: manifest.AccountKey, txType: MethodPublishStorageDeals, want: TxCategoryMarket},
+		{actor: "unknown", txType: MethodWithdrawBalance, want: TxCategoryMinerOps},
+		{actor: "unknown", txType: "NotATxType", want: TxCategorySystem},
+	}
+	for _, tt := range tests {
+		t.Run(tt.actor+"/"+tt.txType, func(t *testing.T) {
+			require.Equal(t, tt.want, GetActorTxCategory(tt.actor, tt.txType))
+		})
+	}
+}
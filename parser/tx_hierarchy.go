@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"github.com/google/uuid"
+	"github.com/zondax/fil-parser/types"
+)
+
+// IsTopLevelParent reports whether the parent id belongs to a top-level message, i.e. there is no parent
+func IsTopLevelParent(parentId string) bool {
+	return parentId == "" || parentId == uuid.Nil.String()
+}
+
+// LinkTxHierarchy sets IsInternal and RootId on the txs, so consumers do not need to walk the ParentId
+// chain to find the top-level message a tx belongs to. Parents missing from txs are taken as top-level.
+func LinkTxHierarchy(txs []*types.Transaction) {
+	parents := make(map[string]string, len(txs))
+	for _, tx := range txs {
+		parents[tx.Id] = tx.ParentId
+	}
+
+	for _, tx := range txs {
+		tx.IsInternal = tx.Level > 0
+		tx.RootId = ""
+		if IsTopLevelParent(tx.ParentId) {
+			continue
+		}
+
+		rootId := tx.ParentId
+		// the amount of steps is bounded so a malformed cycle can not hang the parser
+		for range len(txs) {
+			parentId, ok := parents[rootId]
+			if !ok || IsTopLevelParent(parentId) {
+				break
+			}
+			rootId = parentId
+		}
+		tx.RootId = rootId
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestLinkTxHierarchy(t *testing.T) {
+	msg := &types.Transaction{Id: "msg", ParentId: uuid.Nil.String()}
+	subCall := &types.Transaction{Id: "sub", ParentId: "msg", Level: 1}
+	subSubCall := &types.Transaction{Id: "subsub", ParentId: "sub", Level: 2}
+	fee := &types.Transaction{Id: "fee", ParentId: "msg", TxType: TotalFeeOp}
+	// the parent was not parsed in this batch, e.g. fees re-extracted with ParseFees
+	orphanFee := &types.Transaction{Id: "orphan", ParentId: "missing", TxType: TotalFeeOp}
+
+	LinkTxHierarchy([]*types.Transaction{msg, subCall, subSubCall, fee, orphanFee})
+
+	tests := []struct {
+		name       string
+		tx         *types.Transaction
+		isInternal bool
+		rootId     string
+	}{
+		{name: "top-level message", tx: msg, isInternal: false, rootId: ""},
+		{name: "sub-call", tx: subCall, isInternal: true, rootId: "msg"},
+		{name: "nested sub-call", tx: subSubCall, isInternal: true, rootId: "msg"},
+		{name: "fee", tx: fee, isInternal: false, rootId: "msg"},
+		{name: "fee of a missing message", tx: orphanFee, isInternal: false, rootId: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.isInternal, tt.tx.IsInternal)
+			require.Equal(t, tt.rootId, tt.tx.RootId)
+		})
+	}
+}
+
+func TestLinkTxHierarchy_Cycle(t *testing.T) {
+	a := &types.Transaction{Id: "a", ParentId: "b", Level: 1}
+	b := &types.Transaction{Id: "b", ParentId: "a", Level: 1}
+
+	LinkTxHierarchy([]*types.Transaction{a, b})
+	require.NotEmpty(t, a.RootId)
+	require.NotEmpty(t, b.RootId)
+}
@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"slices"
+
+	"github.com/filecoin-project/go-state-types/manifest"
+)
+
+// TxTypesVersion is the version of the tx types registry. Tx types are part of the parser output
+// and downstream consumers map them to their own enums, so existing entries must never be renamed
+// or removed. Adding new tx types requires bumping this version.
+const TxTypesVersion = "v3"
+
+// CommonTxTypes contains the tx types that can be generated for any actor
+var CommonTxTypes = []string{
+	MethodSend,
+	TotalFeeOp,
+	GasRefundOp,
+	TxTypeGenesis,
+	UnknownStr,
+}
+
+// TxTypesByActor is the canonical registry of the tx types generated by the parser, grouped by actor name
+var TxTypesByActor = map[string][]string{
+	manifest.AccountKey: {
+		MethodConstructor,
+		MethodPubkeyAddress,
+		MethodAuthenticateMessage,
+	},
+	manifest.CronKey: {
+		MethodConstructor,
+		MethodEpochTick,
+	},
+	manifest.DatacapKey: {
+		MethodConstructor,
+		MethodNameExported,
+		MethodTransferExported,
+		MethodTotalSupplyExported,
+		MethodMintExported,
+		MethodBurnExported,
+		MethodDecreaseAllowanceExported,
+		MethodIncreaseAllowanceExported,
+		MethodSymbolExported,
+		MethodDestroyExported,
+		MethodRevokeAllowanceExported,
+		MethodBurnFromExported,
+		MethodBalanceExported,
+		MethodTransferFromExported,
+		MethodGranularityExported,
+		MethodAllowanceExported,
+	},
+	manifest.EamKey: {
+		MethodConstructor,
+		MethodCreate,
+		MethodCreate2,
+		MethodCreateExternal,
+	},
+	manifest.EthAccountKey: {
+		MethodConstructor,
+		MethodResurrect,
+		MethodGetBytecode,
+		MethodGetBytecodeHash,
+		MethodGetStorageAt,
+		MethodInvokeContractDelegate,
+		MethodInvokeContract,
+	},
+	manifest.EvmKey: {
+		MethodConstructor,
+		MethodResurrect,
+		MethodGetBytecode,
+		MethodGetBytecodeHash,
+		MethodGetStorageAt,
+		MethodInvokeContractDelegate,
+		MethodInvokeContract,
+	},
+	manifest.InitKey: {
+		MethodConstructor,
+		MethodExec,
+		MethodExec4,
+	},
+	manifest.MultisigKey: {
+		MethodConstructor,
+		MethodPropose,
+		MethodApprove,
+		MethodCancel,
+		MethodAddSigner,
+		MethodRemoveSigner,
+		MethodSwapSigner,
+		MethodChangeNumApprovalsThreshold,
+		MethodLockBalance,
+		MethodRemoveSignerExported,
+		MethodApproveExported,
+		MethodProposeExported,
+		MethodLockBalanceExported,
+		MethodAddSignerExported,
+		MethodCancelExported,
+		MethodChangeNumApprovalsThresholdExported,
+		MethodMsigUniversalReceiverHook,
+		MethodSwapSignerExported,
+	},
+	manifest.PaychKey: {
+		MethodConstructor,
+		MethodUpdateChannelState,
+		MethodSettle,
+		MethodCollect,
+	},
+	manifest.PlaceholderKey: {
+		MethodConstructor,
+		MethodResurrect,
+		MethodGetBytecode,
+		MethodGetBytecodeHash,
+		MethodGetStorageAt,
+		MethodInvokeContractDelegate,
+		MethodInvokeContract,
+	},
+	manifest.RewardKey: {
+		MethodConstructor,
+		MethodAwardBlockReward,
+		MethodThisEpochReward,
+		MethodUpdateNetworkKPI,
+	},
+	manifest.MarketKey: {
+		MethodConstructor,
+		MethodAddBalance,
+		MethodWithdrawBalance,
+		MethodPublishStorageDeals,
+		MethodVerifyDealsForActivation,
+		MethodActivateDeals,
+		MethodOnMinerSectorsTerminate,
+		MethodComputeDataCommitment,
+		MethodCronTick,
+		MethodGetDealLabel,
+		MethodGetDealClient,
+		MethodGetDealTerm,
+		MethodGetDealClientCollateral,
+		MethodGetBalance,
+		MethodAddBalanceExported,
+		MethodGetDealProvider,
+		MethodGetDealDataCommitment,
+		MethodPublishStorageDealsExported,
+		MethodWithdrawBalanceExported,
+		MethodGetDealActivation,
+		MethodGetDealVerified,
+		MethodGetDealProviderCollateral,
+		MethodGetDealTotalPrice,
+	},
+	manifest.MinerKey: {
+		MethodConstructor,
+		MethodControlAddresses,
+		MethodChangeWorkerAddress,
+		MethodChangePeerID,
+		MethodSubmitWindowedPoSt,
+		MethodPreCommitSector,
+		MethodProveCommitSector,
+		MethodExtendSectorExpiration,
+		MethodTerminateSectors,
+		MethodDeclareFaults,
+		MethodDeclareFaultsRecovered,
+		MethodOnDeferredCronEvent,
+		TxTypeCronProvingDeadlinePenalty,
+		TxTypeCronEarlyTerminationPenalty,
+		MethodCheckSectorProven,
+		MethodApplyRewards,
+		MethodReportConsensusFault,
+		MethodWithdrawBalance,
+		MethodConfirmSectorProofsValid,
+		MethodChangeMultiaddrs,
+		MethodCompactPartitions,
+		MethodCompactSectorNumbers,
+		MethodConfirmChangeWorkerAddress,
+		MethodRepayDebt,
+		MethodChangeOwnerAddress,
+		MethodDisputeWindowedPoSt,
+		MethodPreCommitSectorBatch,
+		MethodProveCommitAggregate,
+		MethodProveReplicaUpdates,
+		MethodPreCommitSectorBatch2,
+		MethodProveReplicaUpdates2,
+		MethodChangeBeneficiary,
+		MethodGetBeneficiary,
+		MethodExtendSectorExpiration2,
+		MethodIsControllingAddressExported,
+		MethodChangeOwnerAddressExported,
+		MethodChangeMultiaddrsExported,
+		MethodChangePeerIDExported,
+		MethodGetMultiaddrs,
+		MethodChangeBeneficiaryExported,
+		MethodGetVestingFunds,
+		MethodWithdrawBalanceExported,
+		MethodConfirmChangeWorkerAddressExported,
+		MethodGetPeerID,
+		MethodGetOwner,
+		MethodChangeWorkerAddressExported,
+		MethodRepayDebtExported,
+		MethodGetSectorSize,
+		MethodGetAvailableBalance,
+	},
+	manifest.PowerKey: {
+		MethodConstructor,
+		MethodCreateMiner,
+		MethodUpdateClaimedPower,
+		MethodEnrollCronEvent,
+		MethodCronTick,
+		MethodUpdatePledgeTotal,
+		MethodOnConsensusFault,
+		MethodSubmitPoRepForBulkVerify,
+		MethodCurrentTotalPower,
+		MethodMinerConsensusCountExported,
+		MethodNetworkRawPowerExported,
+		MethodCreateMinerExported,
+		MethodMinerCountExported,
+		MethodMinerRawPowerExported,
+	},
+	manifest.VerifregKey: {
+		MethodConstructor,
+		MethodAddVerifier,
+		MethodRemoveVerifier,
+		MethodAddVerifiedClient,
+		MethodUseBytes,
+		MethodRestoreBytes,
+		MethodRemoveVerifiedClientDataCap,
+		MethodRemoveExpiredAllocations,
+		MethodClaimAllocations,
+		MethodGetClaims,
+		MethodExtendClaimTerms,
+		MethodRemoveExpiredClaims,
+		MethodExtendClaimTermsExported,
+		MethodGetClaimsExported,
+		MethodRemoveExpiredAllocationsExported,
+		MethodRemoveExpiredClaimsExported,
+		MethodMsigUniversalReceiverHook,
+		MethodAddVerifiedClientExported,
+	},
+}
+
+// GetTxTypesByActor returns all the possible tx types for the given actor name, including the common ones
+func GetTxTypesByActor(actorName string) ([]string, error) {
+	actorTxTypes, ok := TxTypesByActor[actorName]
+	if !ok {
+		return nil, ErrNotKnownActor
+	}
+
+	txTypes := slices.Clone(CommonTxTypes)
+	for _, txType := range actorTxTypes {
+		if !slices.Contains(txTypes, txType) {
+			txTypes = append(txTypes, txType)
+		}
+	}
+	return txTypes, nil
+}
+
+// GetAllTxTypes returns all the tx types of the registry, sorted and without duplicates
+func GetAllTxTypes() []string {
+	txTypes := slices.Clone(CommonTxTypes)
+	for _, actorTxTypes := range TxTypesByActor {
+		txTypes = append(txTypes, actorTxTypes...)
+	}
+
+	slices.Sort(txTypes)
+	return slices.Compact(txTypes)
+}
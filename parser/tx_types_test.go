@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const txTypesDataPath = "../data/tx_types"
+
+type txTypesSnapshot struct {
+	Common  []string            `json:"common"`
+	ByActor map[string][]string `json:"by_actor"`
+}
+
+func loadTxTypesSnapshot(t *testing.T, path string) txTypesSnapshot {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var snapshot txTypesSnapshot
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+	return snapshot
+}
+
+// TestTxTypesRegistry_CurrentVersion checks that the registry matches the snapshot of the current version.
+// If this test fails because tx types were added, bump TxTypesVersion and add a new snapshot.
+func TestTxTypesRegistry_CurrentVersion(t *testing.T) {
+	snapshot := loadTxTypesSnapshot(t, filepath.Join(txTypesDataPath, fmt.Sprintf("%s.json", TxTypesVersion)))
+
+	require.ElementsMatch(t, snapshot.Common, CommonTxTypes)
+	require.Len(t, TxTypesByActor, len(snapshot.ByActor))
+	for actor, txTypes := range snapshot.ByActor {
+		require.ElementsMatch(t, txTypes, TxTypesByActor[actor], "tx types for actor %s do not match", actor)
+	}
+}
+
+// TestTxTypesRegistry_Compatibility checks that no tx type from previous versions was renamed or removed
+func TestTxTypesRegistry_Compatibility(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(txTypesDataPath, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			snapshot := loadTxTypesSnapshot(t, file)
+			for _, txType := range snapshot.Common {
+				require.True(t, slices.Contains(CommonTxTypes, txType), "common tx type %s was removed", txType)
+			}
+			for actor, txTypes := range snapshot.ByActor {
+				current, err := GetTxTypesByActor(actor)
+				require.NoError(t, err)
+				for _, txType := range txTypes {
+					require.True(t, slices.Contains(current, txType), "tx type %s of actor %s was removed", txType, actor)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTxTypesByActor(t *testing.T) {
+	txTypes, err := GetTxTypesByActor("storageminer")
+	require.NoError(t, err)
+	require.Contains(t, txTypes, MethodSend)
+	require.Contains(t, txTypes, MethodPreCommitSector)
+
+	_, err = GetTxTypesByActor("unknownActor")
+	require.ErrorIs(t, err, ErrNotKnownActor)
+}
+
+func TestGetAllTxTypes(t *testing.T) {
+	txTypes := GetAllTxTypes()
+	require.True(t, slices.IsSorted(txTypes))
+	require.Len(t, slices.Compact(slices.Clone(txTypes)), len(txTypes))
+	require.Contains(t, txTypes, TotalFeeOp)
+}
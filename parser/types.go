@@ -7,8 +7,8 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/cbor"
 	"github.com/filecoin-project/go-state-types/exitcode"
-	"github.com/filecoin-project/lotus/api"
 	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types"
 )
 
 type ControlAddress struct {
@@ -17,6 +17,24 @@ type ControlAddress struct {
 	ControlAddrs []string `json:"controlAddrs"`
 }
 
+// MinerNetworkInfo is the peer id and multiaddrs announced by a miner, decoded to their text form.
+// Values that can not be decoded are kept base64 encoded.
+type MinerNetworkInfo struct {
+	PeerID     string   `json:"peerId,omitempty"`
+	Multiaddrs []string `json:"multiaddrs,omitempty"`
+}
+
+// SectorExtension is the version independent view of an extension of ExtendSectorExpiration and
+// ExtendSectorExpiration2. Sectors includes the sectors with verified claims.
+type SectorExtension struct {
+	Deadline      uint64   `json:"deadline"`
+	Partition     uint64   `json:"partition"`
+	Sectors       []uint64 `json:"sectors"`
+	NewExpiration int64    `json:"newExpiration"`
+	// ClaimsDropped are the ids of the verified claims dropped from the sectors
+	ClaimsDropped []uint64 `json:"claimsDropped,omitempty"`
+}
+
 type ExecParams struct {
 	CodeCid           string `json:"CodeCid"`
 	ConstructorParams string `json:"constructorParams"`
@@ -79,11 +97,31 @@ type BurnFee struct {
 	Amount      string
 }
 
+// RefundFee is the gas refunded to the sender, which is not part of the total fee
+type RefundFee struct {
+	RefundAddress string
+	Amount        string
+}
+
+// MinerPenalty is burned from the miner that included the message, so it is not part of the total fee
+type MinerPenalty struct {
+	MinerAddress string
+	Amount       string
+}
+
 type FeesMetadata struct {
 	TxType                string
 	MinerFee              MinerFee
 	OverEstimationBurnFee OverEstimationBurnFee
 	BurnFee               BurnFee
+	// RefundFee is only set with FeatureGasRefunds
+	RefundFee *RefundFee `json:",omitempty"`
+	// FeeMarket is only set with FeatureFeeMarket
+	FeeMarket *FeeMarketPlacement `json:",omitempty"`
+	// MinerPenalty is only set if the miner was penalized for including the message
+	MinerPenalty *MinerPenalty `json:",omitempty"`
+	// GasOutputsSource is where the fees come from, see the GasOutputsSource constants
+	GasOutputsSource string `json:",omitempty"`
 }
 
 type LotusMessage struct {
@@ -92,6 +130,8 @@ type LotusMessage struct {
 	Method abi.MethodNum
 	Cid    cid.Cid
 	Params []byte
+	// ParamsCodec is the IPLD codec of the params, CodecUnknown if the traces do not record it
+	ParamsCodec uint64 `json:"-"`
 }
 
 type RawLotusMessage LotusMessage
@@ -111,9 +151,11 @@ func (m *LotusMessage) MarshalJSON() ([]byte, error) {
 type LotusMessageReceipt struct {
 	ExitCode exitcode.ExitCode
 	Return   []byte
+	// ReturnCodec is the IPLD codec of the return value, CodecUnknown if the traces do not record it
+	ReturnCodec uint64 `json:"-"`
 }
 
 type ComputeOutputVersioned struct {
-	api.ComputeStateOutput
+	types.ComputeStateOutput
 	Version string
 }
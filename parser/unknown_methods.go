@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+const (
+	// UnknownMethodReasonName is used when the method number is not in the methods table of the actor
+	UnknownMethodReasonName = "method_name"
+	// UnknownMethodReasonMetadata is used when there is no decoder for the method params and return
+	UnknownMethodReasonMetadata = "metadata"
+)
+
+// UnknownMethod aggregates the calls to an (actor, method) pair that the parser could not decode
+type UnknownMethod struct {
+	ActorName string `json:"actor_name"`
+	ActorCid  string `json:"actor_cid"`
+	Method    uint64 `json:"method"`
+	Reason    string `json:"reason"`
+	Count     uint64 `json:"count"`
+	// SampleTxCid is the first transaction found calling the method, useful to build new decoders
+	SampleTxCid string `json:"sample_tx_cid"`
+	FirstHeight uint64 `json:"first_height"`
+	LastHeight  uint64 `json:"last_height"`
+}
+
+type unknownMethodKey struct {
+	actorCid string
+	method   uint64
+	reason   string
+}
+
+// UnknownMethodsTracker counts the unknown (actor, method) pairs found while parsing. It is safe for concurrent use.
+type UnknownMethodsTracker struct {
+	mu      sync.Mutex
+	entries map[unknownMethodKey]*UnknownMethod
+}
+
+func NewUnknownMethodsTracker() *UnknownMethodsTracker {
+	return &UnknownMethodsTracker{entries: make(map[unknownMethodKey]*UnknownMethod)}
+}
+
+// Record adds a call to the (actor, method) pair
+func (t *UnknownMethodsTracker) Record(actorName, actorCid string, method uint64, reason, txCid string, height uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := unknownMethodKey{actorCid: actorCid, method: method, reason: reason}
+	entry, ok := t.entries[key]
+	if !ok {
+		t.entries[key] = &UnknownMethod{
+			ActorName:   actorName,
+			ActorCid:    actorCid,
+			Method:      method,
+			Reason:      reason,
+			Count:       1,
+			SampleTxCid: txCid,
+			FirstHeight: height,
+			LastHeight:  height,
+		}
+		return
+	}
+
+	entry.Count++
+	entry.FirstHeight = min(entry.FirstHeight, height)
+	entry.LastHeight = max(entry.LastHeight, height)
+}
+
+// Snapshot returns a copy of the recorded pairs, the most called first
+func (t *UnknownMethodsTracker) Snapshot() []UnknownMethod {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make([]UnknownMethod, 0, len(t.entries))
+	for _, entry := range t.entries {
+		snapshot = append(snapshot, *entry)
+	}
+
+	slices.SortFunc(snapshot, func(a, b UnknownMethod) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.ActorName, b.ActorName),
+			cmp.Compare(a.Method, b.Method),
+			cmp.Compare(a.Reason, b.Reason),
+		)
+	})
+	return snapshot
+}
+
+// Reset removes every recorded pair
+func (t *UnknownMethodsTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = make(map[unknownMethodKey]*UnknownMethod)
+}
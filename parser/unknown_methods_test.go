@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownMethodsTracker(t *testing.T) {
+	tracker := NewUnknownMethodsTracker()
+
+	var wg sync.WaitGroup
+	for height := uint64(1); height <= 10; height++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Record("miner", "bafkminer", 42, UnknownMethodReasonMetadata, "bafytx", height)
+		}()
+	}
+	wg.Wait()
+	tracker.Record("evm", "bafkevm", 7, UnknownMethodReasonName, "bafyevmtx", 5)
+
+	snapshot := tracker.Snapshot()
+	require.Len(t, snapshot, 2)
+	require.Equal(t, UnknownMethod{
+		ActorName:   "miner",
+		ActorCid:    "bafkminer",
+		Method:      42,
+		Reason:      UnknownMethodReasonMetadata,
+		Count:       10,
+		SampleTxCid: "bafytx",
+		FirstHeight: 1,
+		LastHeight:  10,
+	}, snapshot[0])
+	require.Equal(t, "evm", snapshot[1].ActorName)
+	require.Equal(t, uint64(1), snapshot[1].Count)
+
+	tracker.Reset()
+	require.Empty(t, tracker.Snapshot())
+}
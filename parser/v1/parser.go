@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
-	"github.com/bytedance/sonic"
+	"github.com/filecoin-project/go-address"
+	filBig "github.com/filecoin-project/go-state-types/big"
 	filTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/google/uuid"
 	"github.com/ipfs/go-cid"
@@ -30,6 +32,8 @@ type Parser struct {
 	actorParser            *actors.ActorParser
 	addresses              *types.AddressInfoMap
 	txCidEquivalents       []types.TxCidTranslation
+	tokenTransfers         []*types.TokenTransfer
+	skippedTraces          []types.SkippedTrace
 	helper                 *helper.Helper
 	logger                 *zap.Logger
 	multisigEventGenerator multisigTools.EventGenerator
@@ -64,31 +68,101 @@ func (p *Parser) IsNodeVersionSupported(ver string) bool {
 }
 
 func (p *Parser) ParseTransactions(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
+	traces, err := p.DecodeTraces(txsData)
+	if err != nil {
+		return nil, err
+	}
+	result, pending, err := p.BuildTxTree(ctx, txsData, traces)
+	if err != nil {
+		return nil, err
+	}
+	if err = pending.Decode(ctx); err != nil {
+		return nil, err
+	}
+	pending.Render()
+
+	// Clear this cache when we finish processing a tipset.
+	// Bad addresses in this tipset might be valid in the next one
+	p.helper.GetActorsCache().ClearBadAddressCache()
+	return result, nil
+}
+
+// DecodeTraces decodes the traces of the tipset, to be passed to BuildTxTree
+func (p *Parser) DecodeTraces(txsData types.TxsData) (parser.DecodedTraces, error) {
 	// Unmarshal into vComputeState
 	computeState := &typesV1.ComputeStateOutputV1{}
-	err := sonic.UnmarshalString(string(txsData.Traces), &computeState)
+	err := tools.UnmarshalJSON(p.helper.GetConfig().JSONCodec, txsData.Traces, &computeState)
 	if err != nil {
 		p.logger.Sugar().Error(err)
 		return nil, errors.New("could not decode")
 	}
+	return computeState, nil
+}
+
+// BuildTxTree builds the txs of the traces decoded by DecodeTraces. The params of the txs are not decoded: they are
+// queued in the returned PendingMetadata instead. With a checkpointer, the params of every trace are decoded and
+// rendered as it is parsed, so the checkpoints hold complete txs.
+func (p *Parser) BuildTxTree(ctx context.Context, txsData types.TxsData, traces parser.DecodedTraces) (*types.TxsParsedResult, *parser.PendingMetadata, error) {
+	computeState, ok := traces.(*typesV1.ComputeStateOutputV1)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected decoded traces %T", traces)
+	}
 
 	appTools := tools.Tools{Logger: p.logger}
 	var transactions []*types.Transaction
 	p.addresses = types.NewAddressInfoMap()
 	p.txCidEquivalents = make([]types.TxCidTranslation, 0)
+	p.tokenTransfers = make([]*types.TokenTransfer, 0)
+	p.skippedTraces = make([]types.SkippedTrace, 0)
+	p.helper.StartAddressLookups()
+
+	resume, err := parser.NewTraceResume(txsData, Version, p.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if checkpoint := resume.Checkpoint(); checkpoint != nil {
+		transactions = checkpoint.Txs
+		p.addresses = types.NewAddressInfoMapFrom(checkpoint.Addresses)
+		p.txCidEquivalents = checkpoint.TxCids
+		p.tokenTransfers = checkpoint.TokenTransfers
+		p.skippedTraces = append(p.skippedTraces, checkpoint.SkippedTraces...)
+	}
 
 	tipsetKey := txsData.Tipset.Key()
 	tipsetCid := txsData.Tipset.GetCidString()
 
-	for _, trace := range computeState.Trace {
+	joinReceipts(computeState.Trace, txsData.Receipts)
+	resolveGasOutputs(computeState.Trace, txsData.Tipset)
+	premiums := p.gasPremiumDistribution(computeState.Trace)
+	pending := parser.NewPendingMetadata()
+	linkReceipts := p.helper.GetConfig().LinkReceipts
+	explicitMessages := uint64(0)
+	for i, trace := range computeState.Trace {
+		// the traces parsed so far are kept by the resume checkpoint, if any, when the context is done
+		if err = ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		// implicit messages have no receipt, so the receipt index is the amount of explicit messages applied before
+		receiptIndex, hasReceipt := explicitMessages, trace.Msg != nil && !parser.IsImplicitMessage(trace.Msg.From)
+		if hasReceipt {
+			explicitMessages++
+		}
+		if resume.Skip(i) {
+			continue
+		}
+		resume.Save(i, func() *types.TraceCheckpoint { return p.traceCheckpoint(transactions) })
+
+		skipStart := len(p.skippedTraces)
 		if !hasMessage(trace) {
+			p.skipTrace(trace.MsgCid, "", types.SkipReasonNoMessage, "")
+			parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
 			continue
 		}
 
 		// TODO find a way to not having this special case handled outside func parseTrace
 		if ok := hasExecutionTrace(trace); !ok {
 			// Create tx
-			txType, _ := p.helper.GetMethodName(&parser.LotusMessage{
+			txType, _ := p.helper.GetMethodNameWithContext(ctx, &parser.LotusMessage{
 				To:     trace.Msg.To,
 				From:   trace.Msg.From,
 				Method: trace.Msg.Method,
@@ -98,7 +172,7 @@ func (p *Parser) ParseTransactions(ctx context.Context, txsData types.TxsData) (
 			if err != nil {
 				p.logger.Sugar().Errorf("Error when trying to get block cid from message,txType '%s': %v", txType, err)
 			}
-			messageUuid := tools.BuildMessageId(tipsetCid, blockCid, trace.MsgCid.String(), trace.Msg.Cid().String(), uuid.Nil.String())
+			messageUuid := p.ids().BuildMessageId(tipsetCid, blockCid, trace.MsgCid.String(), trace.Msg.Cid().String(), uuid.Nil.String())
 
 			badTx := &types.Transaction{
 				TxBasicBlockData: types.TxBasicBlockData{
@@ -108,64 +182,98 @@ func (p *Parser) ParseTransactions(ctx context.Context, txsData types.TxsData) (
 					},
 					BlockCid: blockCid,
 				},
-				Id:          messageUuid,
-				ParentId:    uuid.Nil.String(),
-				TxCid:       trace.MsgCid.String(),
-				TxFrom:      trace.Msg.From.String(),
-				TxTo:        trace.Msg.To.String(),
-				TxType:      txType,
-				Amount:      trace.Msg.Value.Int,
-				GasUsed:     uint64(trace.MsgRct.GasUsed),
-				Status:      parser.GetExitCodeStatus(trace.MsgRct.ExitCode),
-				TxMetadata:  trace.Error,
-				TxTimestamp: parser.GetTimestamp(txsData.Tipset.MinTimestamp()),
+				Id:             messageUuid,
+				ParentId:       uuid.Nil.String(),
+				TxCid:          trace.MsgCid.String(),
+				TxFrom:         trace.Msg.From.String(),
+				TxTo:           trace.Msg.To.String(),
+				TxType:         txType,
+				Amount:         trace.Msg.Value.Int,
+				GasUsed:        uint64(trace.MsgRct.GasUsed),
+				Status:         parser.GetExitCodeStatus(trace.MsgRct.ExitCode),
+				TxMetadata:     trace.Error,
+				TxTimestamp:    parser.GetTimestamp(txsData.Tipset.MinTimestamp()),
+				ExecutionIndex: uint64(i),
 			}
 
+			if linkReceipts && hasReceipt {
+				parser.LinkReceipt(badTx, receiptIndex, txsData.ReceiptsRoot)
+			}
 			transactions = append(transactions, badTx)
 			continue
 		}
 
 		// Main transaction
-		transaction, err := p.parseTrace(trace.ExecutionTrace, trace.MsgCid, txsData.Tipset, uuid.Nil.String())
+		msgStart := len(transactions)
+		transaction, err := p.parseTrace(ctx, trace.ExecutionTrace, trace.MsgCid, txsData.Tipset, uuid.Nil.String(), false, pending)
 		if err != nil {
+			p.skipTrace(trace.MsgCid, "", types.SkipReasonParseError, err.Error())
+			parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
 			continue
 		}
-		transaction.GasUsed = trace.GasCost.GasUsed.Uint64()
+		transaction.GasUsed = parser.GetGasUsed(trace.GasCost.GasUsed)
+		if linkReceipts && hasReceipt {
+			parser.LinkReceipt(transaction, receiptIndex, txsData.ReceiptsRoot)
+		}
 		transactions = append(transactions, transaction)
 
 		// Only process sub-calls if the parent call was successfully executed
 		if trace.ExecutionTrace.MsgRct.ExitCode.IsSuccess() {
-			subTxs := p.parseSubTxs(trace.ExecutionTrace.Subcalls, trace.MsgCid, txsData.Tipset, txsData.EthLogs,
-				trace.Msg.Cid().String(), transaction.Id, 0)
+			subTxs := p.parseSubTxs(ctx, trace.ExecutionTrace.Subcalls, trace.MsgCid, txsData.Tipset, txsData.EthLogs,
+				trace.Msg.Cid().String(), transaction.Id, "", 0, false, pending)
 			if len(subTxs) > 0 {
 				transactions = append(transactions, subTxs...)
 			}
+		} else if len(trace.ExecutionTrace.Subcalls) > 0 {
+			p.skipTrace(trace.MsgCid, "", types.SkipReasonFailedParent, fmt.Sprintf("%d sub-calls of the failed message", len(trace.ExecutionTrace.Subcalls)))
 		}
 
 		// Fees
-		if trace.GasCost.TotalCost.Uint64() > 0 {
-			feeTx := p.feesTransactions(trace, txsData.Tipset, transaction.TxType, transaction.Id)
-			transactions = append(transactions, feeTx)
+		if parser.IsPositiveAmount(trace.GasCost.TotalCost) {
+			transactions = append(transactions, p.feeTxs(trace, txsData.Tipset, transaction.TxType, transaction.Id, premiums)...)
 		}
+		parser.SetExecutionIndex(transactions[msgStart:], i)
+		parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
 
 		// TxCid <-> TxHash
-		txHash, err := parser.TranslateTxCidToTxHash(p.helper.GetFilecoinNodeClient(), trace.MsgCid)
+		txHash, err := parser.TranslateTxCidToTxHashWithContext(ctx, p.helper.GetFilecoinNodeClient(), trace.MsgCid)
 		if err == nil && txHash != "" {
 			p.txCidEquivalents = append(p.txCidEquivalents, types.TxCidTranslation{TxCid: trace.MsgCid.String(), TxHash: txHash})
 		}
+
+		// the checkpoints hold complete txs
+		if resume.Enabled() {
+			if err = pending.Decode(ctx); err != nil {
+				return nil, nil, err
+			}
+			pending.Render()
+		}
 	}
 
+	resume.Done()
 	transactions = tools.SetNodeMetadata(transactions, txsData.Metadata, Version)
 
-	// Clear this cache when we finish processing a tipset.
-	// Bad addresses in this tipset might be valid in the next one
-	p.helper.GetActorsCache().ClearBadAddressCache()
-
 	return &types.TxsParsedResult{
-		Txs:       transactions,
-		Addresses: p.addresses,
-		TxCids:    p.txCidEquivalents,
-	}, nil
+		Txs:            transactions,
+		Addresses:      p.addresses,
+		TxCids:         p.txCidEquivalents,
+		TokenTransfers: p.tokenTransfers,
+		Report:         types.ParseReport{SkippedTraces: p.skippedTraces},
+	}, pending, nil
+}
+
+// ParseTransactionsStream parses the txs like ParseTransactions, passing them to fn once they are all parsed. The
+// traces of this version are decoded all at once, so unlike v2 the txs are not streamed while they are parsed.
+func (p *Parser) ParseTransactionsStream(ctx context.Context, txsData types.TxsData, fn func(txs []*types.Transaction) error) (*types.TxsParsedResult, error) {
+	result, err := p.ParseTransactions(ctx, txsData)
+	if err != nil {
+		return nil, err
+	}
+	if err = fn(result.Txs); err != nil {
+		return nil, err
+	}
+	result.Txs = nil
+	return result, nil
 }
 
 func (p *Parser) ParseMultisigEvents(ctx context.Context, multisigTxs []*types.Transaction, tipsetCid string, tipsetKey filTypes.TipSetKey) (*types.MultisigEvents, error) {
@@ -180,12 +288,24 @@ func (p *Parser) ParseEthLogs(_ context.Context, _ types.EventsData) (*types.Eve
 	return nil, errors.New("unimplimented")
 }
 
+// GetBaseFee returns the base fee of the tipset, or types.ErrBaseFeeOverflow if it does not fit in an uint64.
+//
+// Deprecated: use GetBaseFeeWithSource, which returns the whole base fee
 func (p *Parser) GetBaseFee(traces []byte, tipset *types.ExtendedTipSet) (uint64, error) {
+	baseFee, err := p.GetBaseFeeWithSource(traces, tipset)
+	if err != nil {
+		return baseFee.Value, err
+	}
+	return baseFee.Uint64()
+}
+
+// GetBaseFeeWithSource returns the base fee of the tipset and how it was derived
+func (p *Parser) GetBaseFeeWithSource(traces []byte, tipset *types.ExtendedTipSet) (types.BaseFee, error) {
 	// Unmarshal into vComputeState
 	computeState := &typesV1.ComputeStateOutputV1{}
-	if err := sonic.UnmarshalString(string(traces), &computeState); err != nil {
+	if err := tools.UnmarshalJSON(p.helper.GetConfig().JSONCodec, traces, &computeState); err != nil {
 		p.logger.Sugar().Error(err)
-		return 0, errors.New("could not decode")
+		return types.BaseFee{}, errors.New("could not decode")
 	}
 
 	baseFee := big.NewInt(0)
@@ -203,30 +323,92 @@ func (p *Parser) GetBaseFee(traces []byte, tipset *types.ExtendedTipSet) (uint64
 	}
 
 	if !found {
-		return parser.GetParentBaseFeeByHeight(tipset, p.logger)
+		parentBaseFee, err := parser.GetParentBaseFee(tipset, p.logger)
+		return types.NewBaseFee(parentBaseFee, types.BaseFeeSourceParentBaseFee), err
 	}
 
-	return baseFee.Uint64(), nil
+	return types.NewBaseFee(baseFee, types.BaseFeeSourceTraces), nil
 }
 
-func (p *Parser) parseSubTxs(subTxs []typesV1.ExecutionTraceV1, mainMsgCid cid.Cid, tipSet *types.ExtendedTipSet, ethLogs []types.EthLog, txHash string,
-	parentId string, level uint16) (txs []*types.Transaction) {
+// ParseFees re-extracts only the fee txs of the tipset from its traces. Params decoding, sub-calls and address
+// consolidation are skipped, so fees can be recomputed for large ranges without parsing the whole tipset again.
+func (p *Parser) ParseFees(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
+	computeState := &typesV1.ComputeStateOutputV1{}
+	if err := tools.UnmarshalJSON(p.helper.GetConfig().JSONCodec, txsData.Traces, &computeState); err != nil {
+		p.logger.Sugar().Error(err)
+		return nil, errors.New("could not decode")
+	}
+
+	appTools := tools.Tools{Logger: p.logger}
+	tipsetCid := txsData.Tipset.GetCidString()
+	var transactions []*types.Transaction
+	joinReceipts(computeState.Trace, txsData.Receipts)
+	resolveGasOutputs(computeState.Trace, txsData.Tipset)
+	premiums := p.gasPremiumDistribution(computeState.Trace)
+	for i, trace := range computeState.Trace {
+		// messages without execution trace never generate fee txs
+		if !hasMessage(trace) || !hasExecutionTrace(trace) || !parser.IsPositiveAmount(trace.GasCost.TotalCost) {
+			continue
+		}
+
+		txType, err := p.helper.GetMethodNameWithContext(ctx, &parser.LotusMessage{
+			To:     trace.ExecutionTrace.Msg.To,
+			From:   trace.ExecutionTrace.Msg.From,
+			Method: trace.ExecutionTrace.Msg.Method,
+		}, int64(txsData.Tipset.Height()), txsData.Tipset.Key())
+		if err != nil {
+			p.logger.Sugar().Errorf("Error when trying to get method name in tx cid'%s': %v", trace.MsgCid.String(), err)
+			txType = parser.UnknownStr
+		}
+
+		blockCid, err := appTools.GetBlockCidFromMsgCid(trace.MsgCid.String(), txType, nil, txsData.Tipset)
+		if err != nil {
+			p.logger.Sugar().Errorf("Error when trying to get block cid from message, txType '%s': %v", txType, err)
+		}
+		parentId := p.ids().BuildMessageId(tipsetCid, blockCid, trace.MsgCid.String(), trace.ExecutionTrace.Msg.Cid().String(), uuid.Nil.String())
+
+		feeTxs := p.feeTxs(trace, txsData.Tipset, txType, parentId, premiums)
+		parser.SetExecutionIndex(feeTxs, i)
+		transactions = append(transactions, feeTxs...)
+	}
+
+	transactions = tools.SetNodeMetadata(transactions, txsData.Metadata, Version)
+	return &types.TxsParsedResult{Txs: transactions}, nil
+}
+
+func (p *Parser) parseSubTxs(ctx context.Context, subTxs []typesV1.ExecutionTraceV1, mainMsgCid cid.Cid, tipSet *types.ExtendedTipSet, ethLogs []types.EthLog, txHash string,
+	parentId string, path string, level uint16, reverted bool, pending *parser.PendingMetadata) (txs []*types.Transaction) {
 	level++
-	for _, subTx := range subTxs {
-		subTransaction, err := p.parseTrace(subTx, mainMsgCid, tipSet, parentId)
+	for i, subTx := range subTxs {
+		subPath := parser.BuildInternalTxPath(path, i)
+		subTransaction, err := p.parseTrace(ctx, subTx, mainMsgCid, tipSet, parentId, reverted, pending)
 		if err != nil {
+			p.skipTrace(mainMsgCid, subPath, types.SkipReasonParseError, err.Error())
 			continue
 		}
 
+		subTransaction.InternalTxId = parser.BuildInternalTxId(mainMsgCid.String(), subPath)
+
+		// Sub-calls of a failed call are reverted, even if they succeeded (e.g. value sends)
+		subTransaction.Status = parser.GetTxStatus(subTx.MsgRct.ExitCode,
+			reverted && p.helper.GetConfig().IsFeatureEnabled(parser.FeatureRevertedStatus))
 		subTransaction.Level = level
 		txs = append(txs, subTransaction)
-		txs = append(txs, p.parseSubTxs(subTx.Subcalls, mainMsgCid, tipSet, ethLogs, txHash, subTransaction.Id, level)...)
+
+		subSubTxs := p.parseSubTxs(ctx, subTx.Subcalls, mainMsgCid, tipSet, ethLogs, txHash, subTransaction.Id, subPath, level,
+			reverted || subTx.MsgRct.ExitCode.IsError(), pending)
+		if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureMinerCronPenalties) {
+			parser.ClassifyMinerCronTxs(subTransaction, subTx.Msg.Params, subSubTxs)
+		}
+		txs = append(txs, subSubTxs...)
 	}
 	return
 }
 
-func (p *Parser) parseTrace(trace typesV1.ExecutionTraceV1, mainMsgCid cid.Cid, tipset *types.ExtendedTipSet, parentId string) (*types.Transaction, error) {
-	txType, err := p.helper.GetMethodName(&parser.LotusMessage{
+// parseTrace builds the tx of the trace, queuing the decoding of its params in pending
+func (p *Parser) parseTrace(ctx context.Context, trace typesV1.ExecutionTraceV1, mainMsgCid cid.Cid, tipset *types.ExtendedTipSet, parentId string,
+	reverted bool, pending *parser.PendingMetadata) (*types.Transaction, error) {
+	txType, err := p.helper.GetMethodNameWithContext(ctx, &parser.LotusMessage{
 		To:     trace.Msg.To,
 		From:   trace.Msg.From,
 		Method: trace.Msg.Method,
@@ -240,41 +422,59 @@ func (p *Parser) parseTrace(trace typesV1.ExecutionTraceV1, mainMsgCid cid.Cid,
 		p.logger.Sugar().Errorf("Could not get method name in transaction '%s'", trace.Msg.Cid().String())
 	}
 
-	metadata, addressInfo, mErr := p.actorParser.GetMetadata(txType, &parser.LotusMessage{
-		To:     trace.Msg.To,
-		From:   trace.Msg.From,
-		Method: trace.Msg.Method,
-		Cid:    trace.Msg.Cid(),
-		Params: trace.Msg.Params,
-	}, mainMsgCid, &parser.LotusMessageReceipt{
-		ExitCode: trace.MsgRct.ExitCode,
-		Return:   trace.MsgRct.Return,
-	}, int64(tipset.Height()), tipset.Key())
+	decode := func(ctx context.Context) map[string]interface{} {
+		metadata, addressInfo, mErr := p.actorParser.GetMetadata(ctx, txType, &parser.LotusMessage{
+			To:     trace.Msg.To,
+			From:   trace.Msg.From,
+			Method: trace.Msg.Method,
+			Cid:    trace.Msg.Cid(),
+			Params: trace.Msg.Params,
+		}, mainMsgCid, &parser.LotusMessageReceipt{
+			ExitCode: trace.MsgRct.ExitCode,
+			Return:   trace.MsgRct.Return,
+		}, int64(tipset.Height()), tipset.Key())
+
+		if mErr != nil {
+			p.logger.Sugar().Warnf("Could not get metadata for transaction in height %s of type '%s': %s", tipset.Height().String(), txType, mErr.Error())
+		}
+		if txType == parser.UnknownStr || errors.Is(mErr, parser.ErrUnknownMethod) {
+			reason := parser.UnknownMethodReasonMetadata
+			if txType == parser.UnknownStr {
+				reason = parser.UnknownMethodReasonName
+			}
+			p.helper.RecordUnknownMethodWithContext(ctx, &parser.LotusMessage{To: trace.Msg.To, Method: trace.Msg.Method}, reason, mainMsgCid.String(),
+				int64(tipset.Height()), tipset.Key())
+		}
+		if addressInfo != nil {
+			if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureCreationHeight) {
+				parser.SetCreationHeight(addressInfo, uint64(tipset.Height()))
+			}
+			parser.AppendToAddressesMap(p.addresses, addressInfo)
+		}
+		if trace.MsgRct.ExitCode.IsError() {
+			metadata["Error"] = trace.MsgRct.ExitCode.Error()
+		}
 
-	if mErr != nil {
-		p.logger.Sugar().Warnf("Could not get metadata for transaction in height %s of type '%s': %s", tipset.Height().String(), txType, mErr.Error())
+		p.appendAddressInfo(ctx, trace.Msg, tipset.Key())
+		return metadata
 	}
-	if addressInfo != nil {
-		parser.AppendToAddressesMap(p.addresses, addressInfo)
-	}
-	if trace.MsgRct.ExitCode.IsError() {
-		metadata["Error"] = trace.MsgRct.ExitCode.Error()
+
+	// the block of a reward is the one mined by the miner in its params, so they are decoded along with the tx
+	var metadata map[string]interface{}
+	if txType == parser.MethodAwardBlockReward {
+		metadata = decode(ctx)
 	}
 
 	tipsetCid := tipset.GetCidString()
-	jsonMetadata, _ := json.Marshal(metadata)
-
-	p.appendAddressInfo(trace.Msg, tipset.Key())
-
 	appTools := tools.Tools{Logger: p.logger}
 	blockCid, err := appTools.GetBlockCidFromMsgCid(mainMsgCid.String(), txType, metadata, tipset)
 	if err != nil {
 		p.logger.Sugar().Errorf("Error when trying to get block cid from message, txType '%s': %v", txType, err)
 	}
 
-	messageUuid := tools.BuildMessageId(tipsetCid, blockCid, mainMsgCid.String(), trace.Msg.Cid().String(), parentId)
+	messageUuid := p.ids().BuildMessageId(tipsetCid, blockCid, mainMsgCid.String(), trace.Msg.Cid().String(), parentId)
 
-	return &types.Transaction{
+	transaction := &types.Transaction{
 		TxBasicBlockData: types.TxBasicBlockData{
 			BasicBlockData: types.BasicBlockData{
 				Height:    uint64(tipset.Height()),
@@ -291,11 +491,77 @@ func (p *Parser) parseTrace(trace typesV1.ExecutionTraceV1, mainMsgCid cid.Cid,
 		Amount:      trace.Msg.Value.Int,
 		Status:      parser.GetExitCodeStatus(trace.MsgRct.ExitCode),
 		TxType:      txType,
-		TxMetadata:  string(jsonMetadata),
-	}, nil
+	}
+	if metadata != nil {
+		pending.AddDecoded(transaction, metadata)
+	} else {
+		pending.Add(transaction, decode)
+	}
+
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureFRC46TokenTransfers) {
+		if transfer := parser.NewTokenTransfer(&parser.LotusMessage{From: trace.Msg.From, To: trace.Msg.To, Method: trace.Msg.Method, Params: trace.Msg.Params},
+			&parser.LotusMessageReceipt{ExitCode: trace.MsgRct.ExitCode}, transaction, reverted); transfer != nil {
+			p.tokenTransfers = append(p.tokenTransfers, transfer)
+		}
+	}
+
+	return transaction, nil
+}
+
+// ids returns the builder of the tx ids, using the configured hash scheme
+func (p *Parser) ids() tools.IdBuilder {
+	return tools.NewIdBuilder(p.helper.GetConfig().IdHashScheme)
+}
+
+// traceCheckpoint snapshots the state built from the traces parsed so far
+func (p *Parser) traceCheckpoint(transactions []*types.Transaction) *types.TraceCheckpoint {
+	return &types.TraceCheckpoint{
+		Txs:            transactions,
+		Addresses:      p.addresses.Copy(),
+		TxCids:         p.txCidEquivalents,
+		TokenTransfers: p.tokenTransfers,
+		SkippedTraces:  p.skippedTraces,
+	}
+}
+
+// skipTrace records a trace that did not produce any tx. The execution index is set by the caller.
+func (p *Parser) skipTrace(msgCid cid.Cid, path, reason, detail string) {
+	skipped := types.SkippedTrace{Path: path, Reason: reason, Detail: detail}
+	if msgCid.Defined() {
+		skipped.TxCid = msgCid.String()
+	}
+	p.skippedTraces = append(p.skippedTraces, skipped)
 }
 
-func (p *Parser) feesTransactions(msg *typesV1.InvocResultV1, tipset *types.ExtendedTipSet, txType, parentTxId string) *types.Transaction {
+// gasPremiumDistribution collects the gas premiums of the messages that pay fees in the tipset. It is nil unless
+// FeatureFeeMarket is enabled.
+func (p *Parser) gasPremiumDistribution(traces []*typesV1.InvocResultV1) *parser.GasPremiumDistribution {
+	if !p.helper.GetConfig().IsFeatureEnabled(parser.FeatureFeeMarket) {
+		return nil
+	}
+
+	var premiums []filBig.Int
+	for _, trace := range traces {
+		if trace == nil || trace.Msg == nil || !parser.IsPositiveAmount(trace.GasCost.TotalCost) {
+			continue
+		}
+		premiums = append(premiums, trace.Msg.GasPremium)
+	}
+	return parser.NewGasPremiumDistribution(premiums)
+}
+
+// feeTxs returns the fee tx of the message and, if enabled, its gas refund tx
+func (p *Parser) feeTxs(trace *typesV1.InvocResultV1, tipset *types.ExtendedTipSet, txType, parentTxId string, premiums *parser.GasPremiumDistribution) []*types.Transaction {
+	feeTx := p.feesTransactions(trace, tipset, txType, parentTxId, premiums)
+	txs := []*types.Transaction{feeTx}
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureGasRefunds) && parser.IsPositiveAmount(trace.GasCost.Refund) {
+		txs = append(txs, parser.NewGasRefundTx(feeTx, p.ids().BuildId(feeTx.Id, parser.GasRefundOp),
+			trace.Msg.GasLimit, trace.Msg.GasFeeCap, trace.GasCost.Refund))
+	}
+	return txs
+}
+
+func (p *Parser) feesTransactions(msg *typesV1.InvocResultV1, tipset *types.ExtendedTipSet, txType, parentTxId string, premiums *parser.GasPremiumDistribution) *types.Transaction {
 	timestamp := parser.GetTimestamp(tipset.MinTimestamp())
 	appTools := tools.Tools{Logger: p.logger}
 	blockCid, err := appTools.GetBlockCidFromMsgCid(msg.MsgCid.String(), txType, nil, tipset)
@@ -322,10 +588,21 @@ func (p *Parser) feesTransactions(msg *typesV1.InvocResultV1, tipset *types.Exte
 			BurnAddress: parser.BurnAddress,
 			Amount:      msg.GasCost.BaseFeeBurn.String(),
 		},
+		GasOutputsSource: msg.GasOutputsSource,
+	}
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureGasRefunds) {
+		feesMetadata.RefundFee = &parser.RefundFee{RefundAddress: msg.Msg.From.String(), Amount: msg.GasCost.Refund.String()}
+	}
+	if premiums != nil {
+		placement := premiums.Placement(msg.Msg.GasPremium)
+		feesMetadata.FeeMarket = &placement
+	}
+	if parser.IsPositiveAmount(msg.GasCost.MinerPenalty) {
+		feesMetadata.MinerPenalty = &parser.MinerPenalty{MinerAddress: minerAddress, Amount: msg.GasCost.MinerPenalty.String()}
 	}
 
 	metadata, _ := json.Marshal(feesMetadata)
-	feeID := tools.BuildFeeId(tipset.GetCidString(), blockCid, msg.MsgCid.String())
+	feeID := p.ids().BuildFeeId(tipset.GetCidString(), blockCid, msg.MsgCid.String())
 
 	return &types.Transaction{
 		TxBasicBlockData: types.TxBasicBlockData{
@@ -347,6 +624,53 @@ func (p *Parser) feesTransactions(msg *typesV1.InvocResultV1, tipset *types.Exte
 	}
 }
 
+// joinReceipts completes the traces with the receipts of their messages delivered separately: the missing
+// receipts and gas used are set, and a missing or truncated return is replaced, see parser.JoinReceiptReturn
+func joinReceipts(traces []*typesV1.InvocResultV1, receipts map[cid.Cid]filTypes.MessageReceipt) {
+	if len(receipts) == 0 {
+		return
+	}
+	for _, trace := range traces {
+		if trace == nil {
+			continue
+		}
+		receipt, ok := receipts[trace.MsgCid]
+		if !ok {
+			continue
+		}
+		if trace.MsgRct == nil {
+			trace.MsgRct = &receipt
+		}
+		if trace.GasCost.GasUsed.Int == nil || trace.GasCost.GasUsed.IsZero() {
+			trace.GasCost.GasUsed = filBig.NewInt(receipt.GasUsed)
+		}
+		if trace.ExecutionTrace.Msg == nil {
+			continue
+		}
+		if trace.ExecutionTrace.MsgRct == nil {
+			executionReceipt := receipt
+			trace.ExecutionTrace.MsgRct = &executionReceipt
+			continue
+		}
+		trace.ExecutionTrace.MsgRct.Return, _ = parser.JoinReceiptReturn(trace.ExecutionTrace.MsgRct.Return,
+			trace.ExecutionTrace.MsgRct.ExitCode, receipt)
+	}
+}
+
+// resolveGasOutputs sets the source of the gas outputs of the traces, recomputing them with the parent base fee of
+// the tipset if a trace does not expose them, see parser.ResolveGasOutputs
+func resolveGasOutputs(traces []*typesV1.InvocResultV1, tipset *types.ExtendedTipSet) {
+	var parentBaseFee filBig.Int
+	if tipset != nil && len(tipset.Blocks()) > 0 {
+		parentBaseFee = tipset.Blocks()[0].ParentBaseFee
+	}
+	for _, trace := range traces {
+		if trace != nil {
+			trace.GasOutputsSource = parser.ResolveGasOutputs(&trace.GasCost, trace.Msg, trace.MsgRct, parentBaseFee)
+		}
+	}
+}
+
 func hasMessage(trace *typesV1.InvocResultV1) bool {
 	return trace.Msg != nil
 }
@@ -360,11 +684,14 @@ func hasExecutionTrace(trace *typesV1.InvocResultV1) bool {
 	return true
 }
 
-func (p *Parser) appendAddressInfo(msg *filTypes.Message, key filTypes.TipSetKey) {
+func (p *Parser) appendAddressInfo(ctx context.Context, msg *filTypes.Message, key filTypes.TipSetKey) {
 	if msg == nil {
 		return
 	}
-	fromAdd := p.helper.GetActorAddressInfo(msg.From, key)
-	toAdd := p.helper.GetActorAddressInfo(msg.To, key)
-	parser.AppendToAddressesMap(p.addresses, fromAdd, toAdd)
+	for _, add := range []address.Address{msg.From, msg.To} {
+		// the addresses left once the lookups budget runs out are not added, see AddressLookupTimeout
+		if info, ok := p.helper.LookupAddressInfo(ctx, add, key); ok {
+			parser.AppendToAddressesMap(p.addresses, info)
+		}
+	}
 }
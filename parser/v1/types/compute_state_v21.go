@@ -1,9 +1,9 @@
 package types
 
 import (
-	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/ipfs/go-cid"
+	parserTypes "github.com/zondax/fil-parser/types"
 	"time"
 )
 
@@ -15,13 +15,16 @@ type ComputeStateOutputV1 struct {
 // InvocResultV1 This is a copy of native lotus InvocResult type at version v1.22.
 // We need to copy it because we cannot have more than one
 type InvocResultV1 struct {
-	MsgCid         cid.Cid
-	Msg            *types.Message
-	MsgRct         *types.MessageReceipt
-	GasCost        api.MsgGasCost
-	Error          string
-	Duration       time.Duration
-	ExecutionTrace ExecutionTraceV1
+	MsgCid   cid.Cid
+	Msg      *types.Message
+	MsgRct   *types.MessageReceipt
+	GasCost  parserTypes.MsgGasCost
+	Error    string
+	Duration time.Duration
+	// GasOutputsSource is where the gas outputs of GasCost come from, see parser.ResolveGasOutputs. It is not
+	// part of the node traces.
+	GasOutputsSource string `json:"-"`
+	ExecutionTrace   ExecutionTraceV1
 }
 
 type ExecutionTraceV1 struct {
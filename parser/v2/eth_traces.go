@@ -0,0 +1,274 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/evm"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/google/uuid"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/tools"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	ethTraceTypeCall   = "call"
+	ethTraceTypeCreate = "create"
+
+	ethCallTypeDelegate = "delegatecall"
+	ethCallTypeStatic   = "staticcall"
+)
+
+// ethTraceErrors are the errors the node sets on the eth traces of the calls failed with these exit codes
+var ethTraceErrors = map[string]exitcode.ExitCode{
+	"out of gas":               exitcode.SysErrOutOfGas,
+	"Reverted":                 evm.ErrReverted,
+	"invalid instruction":      evm.ErrInvalidInstruction,
+	"undefined instruction":    evm.ErrUndefinedInstruction,
+	"stack underflow":          evm.ErrStackUnderflow,
+	"stack overflow":           evm.ErrStackOverflow,
+	"illegal memory access":    evm.ErrIllegalMemoryAccess,
+	"invalid jump destination": evm.ErrBadJumpdest,
+	"self destruct failed":     evm.ErrSelfdestructFailed,
+}
+
+// ethTraceErrorPrefixes prefix the exit code in the errors of the other failed calls, e.g. "vm error: SysErrForbidden(8)"
+var ethTraceErrorPrefixes = []string{"vm error: ", "actor error: "}
+
+// ParseEthTraces parses the eth traces of a tipset (see types.EthTracesData) into the same txs shape as
+// ParseTransactions. Eth traces only hold the EVM calls, with their input and output as metadata: there are no
+// fees, nor the native calls of the messages, and the InternalTxId path follows the eth trace address.
+func (p *Parser) ParseEthTraces(_ context.Context, ethTracesData types.EthTracesData) (*types.TxsParsedResult, error) {
+	var transactions []*types.Transaction
+	p.skippedTraces = make([]types.SkippedTrace, 0)
+
+	appTools := tools.Tools{Logger: p.logger}
+	tipset := ethTracesData.Tipset
+	tipsetCid := tipset.GetCidString()
+
+	var currentTx ethtypes.EthHash
+	var blockCid string
+	// ids of the traces of the current eth tx, by trace address path
+	ids := make(map[string]string)
+	// whether the traces of the current eth tx or one of their parents failed, by trace address path
+	failed := make(map[string]bool)
+	revertedStatus := p.helper.GetConfig().IsFeatureEnabled(parser.FeatureRevertedStatus)
+	for _, trace := range ethTracesData.Traces {
+		if trace.EthTrace == nil {
+			p.skippedTraces = append(p.skippedTraces, types.SkippedTrace{ExecutionIndex: uint64(trace.TransactionPosition),
+				TxCid: ethTraceTxCid(trace), Reason: types.SkipReasonNoMessage})
+			continue
+		}
+
+		txCid := ethTraceTxCid(trace)
+		if len(transactions) == 0 || trace.TransactionHash != currentTx {
+			currentTx = trace.TransactionHash
+			ids = make(map[string]string)
+			failed = make(map[string]bool)
+
+			var err error
+			if blockCid, err = appTools.GetBlockCidFromMsgCid(txCid, "", nil, tipset); err != nil {
+				p.logger.Sugar().Debugf("could not get block cid of eth tx %s: %s", trace.TransactionHash, err)
+			}
+		}
+
+		path, parentPath := ethTracePaths(trace.TraceAddress)
+		parentId := uuid.Nil.String()
+		if len(trace.TraceAddress) > 0 {
+			var ok bool
+			if parentId, ok = ids[parentPath]; !ok {
+				p.skippedTraces = append(p.skippedTraces, types.SkippedTrace{ExecutionIndex: uint64(trace.TransactionPosition),
+					TxCid: txCid, Path: path, Reason: types.SkipReasonParseError, Detail: "parent trace not found"})
+				continue
+			}
+		}
+
+		parentFailed := len(trace.TraceAddress) > 0 && failed[parentPath]
+		transaction, err := p.parseEthTrace(trace.EthTrace, txCid, path, parentFailed && revertedStatus)
+		if err != nil {
+			p.skippedTraces = append(p.skippedTraces, types.SkippedTrace{ExecutionIndex: uint64(trace.TransactionPosition),
+				TxCid: txCid, Path: path, Reason: types.SkipReasonParseError, Detail: err.Error()})
+			continue
+		}
+
+		transaction.Height = uint64(tipset.Height())
+		transaction.TipsetCid = tipsetCid
+		transaction.BlockCid = blockCid
+		transaction.TxTimestamp = parser.GetTimestamp(tipset.MinTimestamp())
+		transaction.ParentId = parentId
+		transaction.Id = p.ids().BuildMessageId(tipsetCid, blockCid, txCid, ethTraceIdPrefix+path, parentId)
+		transaction.ExecutionIndex = uint64(trace.TransactionPosition)
+		ids[path] = transaction.Id
+		failed[path] = parentFailed || trace.Error != ""
+
+		transactions = append(transactions, transaction)
+	}
+
+	transactions = tools.SetNodeMetadata(transactions, ethTracesData.Metadata, Version)
+
+	return &types.TxsParsedResult{
+		Txs:       transactions,
+		Addresses: types.NewAddressInfoMap(),
+		Report:    types.ParseReport{SkippedTraces: p.skippedTraces},
+	}, nil
+}
+
+// ethTraceIdPrefix tells apart the ids of the eth traces from the ids of the native traces of the same message
+const ethTraceIdPrefix = "eth:"
+
+func ethTraceTxCid(trace types.EthTraceBlock) string {
+	if trace.TransactionCid != "" {
+		return trace.TransactionCid
+	}
+	return trace.TransactionHash.String()
+}
+
+// ethTracePaths returns the path of the trace address and the path of its parent
+func ethTracePaths(traceAddress []int) (path string, parentPath string) {
+	for _, i := range traceAddress {
+		parentPath = path
+		path = parser.BuildInternalTxPath(path, i)
+	}
+	return path, parentPath
+}
+
+// parseEthTrace parses the eth trace into a tx. A successful trace is reverted if its parent is, see parser.GetTxStatus
+func (p *Parser) parseEthTrace(trace *ethtypes.EthTrace, txCid, path string, parentReverted bool) (*types.Transaction, error) {
+	var from, to ethtypes.EthAddress
+	var value ethtypes.EthBigInt
+	var input, output ethtypes.EthBytes
+	var gasUsed ethtypes.EthUint64
+	var txType string
+
+	switch trace.Type {
+	case ethTraceTypeCall:
+		var action ethtypes.EthCallTraceAction
+		var result ethtypes.EthCallTraceResult
+		if err := decodeEthTraceFields(trace, &action, &result); err != nil {
+			return nil, err
+		}
+		from, to, value, input = action.From, action.To, action.Value, action.Input
+		gasUsed, output = result.GasUsed, result.Output
+
+		switch action.CallType {
+		case ethCallTypeDelegate:
+			txType = parser.MethodInvokeContractDelegate
+		case ethCallTypeStatic:
+			txType = parser.MethodInvokeContractReadOnly
+		default:
+			txType = parser.MethodInvokeContract
+		}
+	case ethTraceTypeCreate:
+		var action ethtypes.EthCreateTraceAction
+		var result ethtypes.EthCreateTraceResult
+		if err := decodeEthTraceFields(trace, &action, &result); err != nil {
+			return nil, err
+		}
+		from, value, input = action.From, action.Value, action.Init
+		gasUsed, output = result.GasUsed, result.Code
+		if result.Address != nil {
+			to = *result.Address
+		}
+
+		// contracts created by eth accounts go through the EAM CreateExternal method, the rest through Create
+		txType = parser.MethodCreate
+		if path == "" {
+			txType = parser.MethodCreateExternal
+		}
+	default:
+		return nil, fmt.Errorf("unknown eth trace type %s", trace.Type)
+	}
+
+	metadata := map[string]interface{}{
+		parser.ParamsKey: input.String(),
+		parser.ReturnKey: output.String(),
+	}
+	if trace.Error != "" {
+		metadata["Error"] = trace.Error
+	}
+	status := parser.GetTxStatus(ethTraceExitCode(trace.Error), parentReverted)
+	jsonMetadata, _ := json.Marshal(metadata)
+
+	transaction := &types.Transaction{
+		Level:      uint16(len(trace.TraceAddress)),
+		TxCid:      txCid,
+		TxFrom:     ethToFilecoinAddress(from),
+		TxTo:       ethToFilecoinAddress(to),
+		Amount:     value.Int,
+		Status:     status,
+		TxType:     txType,
+		TxMetadata: string(jsonMetadata),
+	}
+	if path != "" {
+		transaction.InternalTxId = parser.BuildInternalTxId(txCid, path)
+	} else {
+		transaction.GasUsed = uint64(gasUsed)
+	}
+
+	return transaction, nil
+}
+
+// ethTraceExitCode returns the exit code of the call from the error of its eth trace, ErrUnspecified if the error
+// is unknown
+func ethTraceExitCode(traceError string) exitcode.ExitCode {
+	if traceError == "" {
+		return exitcode.Ok
+	}
+	if code, ok := ethTraceErrors[traceError]; ok {
+		return code
+	}
+
+	for _, prefix := range ethTraceErrorPrefixes {
+		code, ok := strings.CutPrefix(traceError, prefix)
+		if !ok {
+			continue
+		}
+		// named exit codes are formatted as name(code)
+		if open := strings.LastIndex(code, "("); open >= 0 && strings.HasSuffix(code, ")") {
+			code = code[open+1 : len(code)-1]
+		}
+		if value, err := strconv.ParseInt(code, 10, 64); err == nil {
+			return exitcode.ExitCode(value)
+		}
+	}
+	return exitcode.ErrUnspecified
+}
+
+// decodeEthTraceFields decodes the action and result of the trace, which are generic json values
+func decodeEthTraceFields(trace *ethtypes.EthTrace, action, result interface{}) error {
+	for _, field := range []struct {
+		value  interface{}
+		target interface{}
+	}{{trace.Action, action}, {trace.Result, result}} {
+		if field.value == nil {
+			continue
+		}
+		data, err := json.Marshal(field.value)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, field.target); err != nil {
+			return fmt.Errorf("could not decode %s trace: %w", trace.Type, err)
+		}
+	}
+	return nil
+}
+
+// ethToFilecoinAddress returns the filecoin address of the eth address: the ID address for masked ID
+// addresses and the f410 address for the rest
+func ethToFilecoinAddress(ethAddr ethtypes.EthAddress) string {
+	if ethAddr == (ethtypes.EthAddress{}) {
+		return ""
+	}
+	filAddr, err := ethAddr.ToFilecoinAddress()
+	if err != nil || filAddr == address.Undef {
+		return ethAddr.String()
+	}
+	return filAddr.String()
+}
@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/evm"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+const ethTraces = `[
+	{"type": "create", "action": {"from": "0xff00000000000000000000000000000000000064", "gas": "0x1", "value": "0x0", "init": "0x01"},
+	 "result": {"address": "0xff00000000000000000000000000000000000065", "gasUsed": "0x10", "code": "0x02"},
+	 "subtraces": 1, "traceAddress": [], "blockHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+	 "blockNumber": 10, "transactionHash": "0x00000000000000000000000000000000000000000000000000000000000000aa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "staticcall", "from": "0xff00000000000000000000000000000000000065", "to": "0xff00000000000000000000000000000000000066", "gas": "0x1", "value": "0x0", "input": "0x03"},
+	 "result": {"gasUsed": "0x5", "output": "0x04"}, "error": "Reverted",
+	 "subtraces": 1, "traceAddress": [0], "blockHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+	 "blockNumber": 10, "transactionHash": "0x00000000000000000000000000000000000000000000000000000000000000aa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "call", "from": "0xff00000000000000000000000000000000000066", "to": "0xff00000000000000000000000000000000000067", "gas": "0x1", "value": "0x1", "input": "0x"},
+	 "result": {"gasUsed": "0x5", "output": "0x"},
+	 "subtraces": 0, "traceAddress": [0, 0], "blockHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+	 "blockNumber": 10, "transactionHash": "0x00000000000000000000000000000000000000000000000000000000000000aa", "transactionPosition": 0},
+	{"type": "call", "action": {"callType": "call", "from": "0xff00000000000000000000000000000000000065", "to": "0xff00000000000000000000000000000000000066", "gas": "0x1", "value": "0x0", "input": "0x"},
+	 "result": {"gasUsed": "0x5", "output": "0x"},
+	 "subtraces": 0, "traceAddress": [3, 0], "blockHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+	 "blockNumber": 10, "transactionHash": "0x00000000000000000000000000000000000000000000000000000000000000aa", "transactionPosition": 0}
+]`
+
+func TestParseEthTraces(t *testing.T) {
+	var traces []types.EthTraceBlock
+	require.NoError(t, json.Unmarshal([]byte(ethTraces), &traces))
+	traces[0].TransactionCid = "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"
+
+	config := parser.FilecoinParserConfig{ExperimentalFeatures: []string{string(parser.FeatureRevertedStatus)}}
+	p := &Parser{logger: zap.NewNop(), helper: helper.NewHelper(nil, nil, nil, zap.NewNop(), config)}
+	result, err := p.ParseEthTraces(context.Background(), types.EthTracesData{Tipset: &types.ExtendedTipSet{}, Traces: traces})
+	require.NoError(t, err)
+	require.Len(t, result.Txs, 3)
+
+	create := result.Txs[0]
+	require.Equal(t, parser.MethodCreateExternal, create.TxType)
+	require.Equal(t, "f0100", create.TxFrom)
+	require.Equal(t, "f0101", create.TxTo)
+	require.Equal(t, uint64(16), create.GasUsed)
+	require.Equal(t, uuid.Nil.String(), create.ParentId)
+	// the cid of the message is preferred to the eth tx hash
+	require.Equal(t, traces[0].TransactionCid, create.TxCid)
+
+	call := result.Txs[1]
+	require.Equal(t, parser.MethodInvokeContractReadOnly, call.TxType)
+	require.Equal(t, create.Id, call.ParentId)
+	// same status as the native trace of the reverted call
+	require.Equal(t, parser.GetExitCodeStatus(evm.ErrReverted), call.Status)
+	require.Equal(t, uint16(1), call.Level)
+	require.NotEmpty(t, call.InternalTxId)
+
+	// the successful call is reverted by the failure of its parent
+	reverted := result.Txs[2]
+	require.Equal(t, call.Id, reverted.ParentId)
+	require.Equal(t, parser.StatusReverted, reverted.Status)
+
+	// the parent of the last trace is missing
+	require.Len(t, result.Report.SkippedTraces, 1)
+	require.Equal(t, types.SkipReasonParseError, result.Report.SkippedTraces[0].Reason)
+	require.Equal(t, traces[3].TransactionHash.String(), result.Report.SkippedTraces[0].TxCid)
+}
+
+func TestEthTraceExitCode(t *testing.T) {
+	tests := []struct {
+		traceError string
+		want       exitcode.ExitCode
+	}{
+		{traceError: "", want: exitcode.Ok},
+		{traceError: "Reverted", want: evm.ErrReverted},
+		{traceError: "out of gas", want: exitcode.SysErrOutOfGas},
+		{traceError: "vm error: SysErrForbidden(8)", want: exitcode.SysErrForbidden},
+		{traceError: "actor error: ErrInsufficientFunds(19)", want: exitcode.ErrInsufficientFunds},
+		{traceError: "actor error: 42", want: exitcode.ExitCode(42)},
+		{traceError: "unknown", want: exitcode.ErrUnspecified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.traceError, func(t *testing.T) {
+			require.Equal(t, tt.want, ethTraceExitCode(tt.traceError))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+	"github.com/zondax/fil-parser/tools"
+)
+
+// forestTraceMarkers are keys that only show up in traces generated by Forest nodes
+var forestTraceMarkers = [][]byte{
+	[]byte(`"msg_cid"`),
+	[]byte(`"msg_rct"`),
+	[]byte(`"execution_trace"`),
+	[]byte(`"gas_cost"`),
+}
+
+// isForestTrace checks if the raw trace uses the Forest json layout
+func isForestTrace(rawTraces []byte) bool {
+	for _, marker := range forestTraceMarkers {
+		if bytes.Contains(rawTraces, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeComputeState unmarshals the raw traces into a ComputeStateOutputV2. If the traces were generated by
+// a Forest node (either flagged by the caller or auto-detected), they are normalized to the Lotus layout first.
+func decodeComputeState(rawTraces []byte, forest bool, codec string) (*typesV2.ComputeStateOutputV2, error) {
+	computeState := &typesV2.ComputeStateOutputV2{}
+	if !forest && !isForestTrace(rawTraces) {
+		if err := tools.UnmarshalJSON(codec, rawTraces, &computeState); err != nil {
+			return nil, err
+		}
+		return computeState, nil
+	}
+
+	normalized, err := normalizeForestTraces(rawTraces)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tools.UnmarshalJSON(codec, normalized, &computeState); err != nil {
+		return nil, err
+	}
+
+	fillForestMissingFields(computeState)
+	return computeState, nil
+}
+
+// normalizeForestTraces renames the snake_case keys used by Forest to the CamelCase keys used by Lotus.
+// Numbers are kept as json.Number so no precision is lost while re-encoding.
+func normalizeForestTraces(rawTraces []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(rawTraces))
+	decoder.UseNumber()
+
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(normalizeForestKeys(data))
+}
+
+func normalizeForestKeys(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			normalized[snakeToCamel(k)] = normalizeForestKeys(v)
+		}
+		return normalized
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normalizeForestKeys(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func snakeToCamel(key string) string {
+	if !strings.Contains(key, "_") {
+		return key
+	}
+
+	parts := strings.Split(key, "_")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// fillForestMissingFields sets zero values on the fields that Forest may omit and that the parser
+// expects to be always present. The missing gas outputs are zero-filled once resolved, see resolveGasOutputs.
+func fillForestMissingFields(computeState *typesV2.ComputeStateOutputV2) {
+	for _, trace := range computeState.Trace {
+		fillForestTrace(trace)
+	}
+}
+
+func fillForestTrace(trace *typesV2.InvocResultV2) {
+	if trace == nil {
+		return
+	}
+
+	// the gas outputs are left unset, so they are recomputed if missing, see resolveGasOutputs
+	if trace.GasCost.GasUsed.Int == nil {
+		trace.GasCost.GasUsed = filBig.Zero()
+	}
+
+	if trace.Msg != nil && trace.Msg.Value.Int == nil {
+		trace.Msg.Value = filBig.Zero()
+	}
+
+	fillForestExecutionTrace(&trace.ExecutionTrace)
+}
+
+func fillForestExecutionTrace(trace *typesV2.ExecutionTraceV2) {
+	if trace.Msg.Value.Int == nil {
+		trace.Msg.Value = filBig.Zero()
+	}
+
+	for i := range trace.Subcalls {
+		fillForestExecutionTrace(&trace.Subcalls[i])
+	}
+}
@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"testing"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+)
+
+const forestTrace = `{
+	"root": {"/": "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"},
+	"trace": [{
+		"msg_cid": {"/": "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"},
+		"msg": {"Version": 0, "To": "f01", "From": "f02", "Nonce": 1, "Value": "10", "GasLimit": 1000, "GasFeeCap": "1", "GasPremium": "1", "Method": 0, "Params": null},
+		"msg_rct": {"exit_code": 0, "return": null, "gas_used": 100},
+		"gas_cost": {"gas_used": "100", "base_fee_burn": "200"},
+		"execution_trace": {
+			"msg": {"from": "f02", "to": "f01", "value": "10", "method": 0, "params": null},
+			"msg_rct": {"exit_code": 0, "return": null},
+			"subcalls": [{"msg": {"from": "f01", "to": "f03", "method": 0}}]
+		}
+	}]
+}`
+
+func TestSnakeToCamel(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "snake case", key: "msg_cid", want: "MsgCid"},
+		{name: "multiple parts", key: "over_estimation_burn", want: "OverEstimationBurn"},
+		{name: "camel case", key: "MsgCid", want: "MsgCid"},
+		{name: "cid link", key: "/", want: "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, snakeToCamel(tt.key))
+		})
+	}
+}
+
+func TestDecodeComputeState_Forest(t *testing.T) {
+	require.True(t, isForestTrace([]byte(forestTrace)))
+
+	computeState, err := decodeComputeState([]byte(forestTrace), false, parser.JSONCodecSonic)
+	require.NoError(t, err)
+	require.Len(t, computeState.Trace, 1)
+
+	trace := computeState.Trace[0]
+	require.NotNil(t, trace.Msg)
+	require.Equal(t, "f01", trace.Msg.To.String())
+	require.Equal(t, uint64(100), trace.GasCost.GasUsed.Uint64())
+	require.Equal(t, uint64(200), trace.GasCost.BaseFeeBurn.Uint64())
+	// missing gas outputs are left unset, so they can be recomputed
+	require.False(t, parser.HasGasOutputs(trace.GasCost))
+	require.Nil(t, trace.GasCost.TotalCost.Int)
+	require.Equal(t, "f03", trace.ExecutionTrace.Subcalls[0].Msg.To.String())
+	require.Equal(t, uint64(0), trace.ExecutionTrace.Subcalls[0].Msg.Value.Uint64())
+}
+
+func TestResolveGasOutputs_Forest(t *testing.T) {
+	// the gas outputs omitted by Forest are recomputed from the parent base fee
+	computeState, err := decodeComputeState([]byte(forestTrace), false, parser.JSONCodecSonic)
+	require.NoError(t, err)
+	trace := computeState.Trace[0]
+	resolveGasOutputs(trace, filBig.NewInt(1))
+	require.Equal(t, parser.GasOutputsSourceComputed, trace.GasOutputsSource)
+	require.True(t, parser.IsPositiveAmount(trace.GasCost.TotalCost))
+
+	// without the parent base fee they can not be recomputed, so they are set to zero
+	computeState, err = decodeComputeState([]byte(forestTrace), false, parser.JSONCodecSonic)
+	require.NoError(t, err)
+	trace = computeState.Trace[0]
+	resolveGasOutputs(trace, filBig.Int{})
+	require.Empty(t, trace.GasOutputsSource)
+	require.Equal(t, uint64(0), trace.GasCost.TotalCost.Uint64())
+	require.Equal(t, uint64(200), trace.GasCost.BaseFeeBurn.Uint64())
+}
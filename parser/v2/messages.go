@@ -0,0 +1,109 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/google/uuid"
+	"github.com/zondax/fil-parser/parser"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+	"github.com/zondax/fil-parser/tools"
+	"github.com/zondax/fil-parser/types"
+)
+
+// ParseMessages parses the messages of a tipset without their execution traces. Only top-level transactions
+// are returned: internal calls and fees can not be known without traces.
+func (p *Parser) ParseMessages(ctx context.Context, messagesData types.MessagesData) (*types.TxsParsedResult, error) {
+	if len(messagesData.Messages) != len(messagesData.Receipts) {
+		return nil, fmt.Errorf("got %d messages and %d receipts", len(messagesData.Messages), len(messagesData.Receipts))
+	}
+
+	var transactions []*types.Transaction
+	p.addresses = types.NewAddressInfoMap()
+	p.txCidEquivalents = make([]types.TxCidTranslation, 0)
+	p.tokenTransfers = make([]*types.TokenTransfer, 0)
+	p.skippedTraces = make([]types.SkippedTrace, 0)
+	p.helper.StartAddressLookups()
+
+	for i, message := range messagesData.Messages {
+		receipt := messagesData.Receipts[i]
+		skipStart := len(p.skippedTraces)
+		if message.Message == nil || receipt == nil {
+			p.skipTrace(message.Cid, "", types.SkipReasonNoMessage, "")
+			parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
+			continue
+		}
+
+		var signature *parser.SignatureInfo
+		if sig, ok := messagesData.Signatures[message.Cid]; ok {
+			info := parser.NewSignatureInfo(sig)
+			if info.IsUnknown() && p.helper.GetConfig().UnknownSignatures == parser.UnknownSignaturesDrop {
+				p.logger.Sugar().Warnf("dropping message %s with unknown signature type %d", message.Cid.String(), info.TypeCode)
+				p.skipTrace(message.Cid, "", types.SkipReasonUnknownSignature, fmt.Sprintf("signature type %d", info.TypeCode))
+				parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
+				continue
+			}
+			signature = &info
+		}
+
+		pending := parser.NewPendingMetadata()
+		transaction, err := p.parseTrace(ctx, messageToTrace(message.Message, receipt), message.Cid, messagesData.Tipset, uuid.Nil.String(), false, pending)
+		if err != nil {
+			p.skipTrace(message.Cid, "", types.SkipReasonParseError, err.Error())
+			parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
+			continue
+		}
+		if err = pending.Decode(ctx); err != nil {
+			return nil, err
+		}
+		pending.Render()
+		if signature != nil {
+			if err = parser.AddSignatureMetadata(transaction, *signature); err != nil {
+				p.logger.Sugar().Errorf("could not add signature to tx %s: %s", transaction.Id, err)
+			}
+		}
+		transaction.GasUsed = uint64(receipt.GasUsed)
+		// messages are applied in the order of the receipts
+		transaction.ExecutionIndex = uint64(i)
+		if p.helper.GetConfig().LinkReceipts {
+			parser.LinkReceipt(transaction, uint64(i), messagesData.ReceiptsRoot)
+		}
+		transactions = append(transactions, transaction)
+
+		// TxCid <-> TxHash
+		txHash, err := parser.TranslateTxCidToTxHashWithContext(ctx, p.helper.GetFilecoinNodeClient(), message.Cid)
+		if err == nil && txHash != "" {
+			p.txCidEquivalents = append(p.txCidEquivalents, types.TxCidTranslation{TxCid: message.Cid.String(), TxHash: txHash})
+		}
+	}
+
+	transactions = tools.SetNodeMetadata(transactions, messagesData.Metadata, Version)
+	p.helper.GetActorsCache().ClearBadAddressCache()
+
+	return &types.TxsParsedResult{
+		Txs:            transactions,
+		Addresses:      p.addresses,
+		TxCids:         p.txCidEquivalents,
+		TokenTransfers: p.tokenTransfers,
+		Report:         types.ParseReport{SkippedTraces: p.skippedTraces},
+	}, nil
+}
+
+// messageToTrace builds the execution trace of a message without sub-calls
+func messageToTrace(msg *filTypes.Message, receipt *filTypes.MessageReceipt) typesV2.ExecutionTraceV2 {
+	return typesV2.ExecutionTraceV2{
+		Msg: filTypes.MessageTrace{
+			From:     msg.From,
+			To:       msg.To,
+			Value:    msg.Value,
+			Method:   msg.Method,
+			Params:   msg.Params,
+			GasLimit: uint64(msg.GasLimit),
+		},
+		MsgRct: filTypes.ReturnTrace{
+			ExitCode: receipt.ExitCode,
+			Return:   receipt.Return,
+		},
+	}
+}
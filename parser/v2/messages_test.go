@@ -0,0 +1,39 @@
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestMessageToTrace(t *testing.T) {
+	from, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	to, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	msg := &filTypes.Message{From: from, To: to, Value: filTypes.NewInt(10), Method: 2, Params: []byte{1}, GasLimit: 100}
+	receipt := &filTypes.MessageReceipt{ExitCode: exitcode.ErrForbidden, Return: []byte{2}, GasUsed: 50}
+
+	trace := messageToTrace(msg, receipt)
+	require.Equal(t, from, trace.Msg.From)
+	require.Equal(t, to, trace.Msg.To)
+	require.Equal(t, uint64(10), trace.Msg.Value.Uint64())
+	require.Equal(t, uint64(100), trace.Msg.GasLimit)
+	require.Equal(t, exitcode.ErrForbidden, trace.MsgRct.ExitCode)
+	require.Equal(t, []byte{2}, trace.MsgRct.Return)
+	require.Empty(t, trace.Subcalls)
+}
+
+func TestParseMessages_ReceiptsMismatch(t *testing.T) {
+	p := &Parser{}
+	_, err := p.ParseMessages(context.Background(), types.MessagesData{
+		Messages: []types.Message{{}},
+	})
+	require.Error(t, err)
+}
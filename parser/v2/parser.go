@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"slices"
 	"strings"
@@ -19,7 +20,8 @@ import (
 	multisigTools "github.com/zondax/fil-parser/tools/multisig"
 	"github.com/zondax/fil-parser/types"
 
-	"github.com/bytedance/sonic"
+	"github.com/filecoin-project/go-address"
+	filBig "github.com/filecoin-project/go-state-types/big"
 	filTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/google/uuid"
 	"github.com/ipfs/go-cid"
@@ -34,6 +36,8 @@ type Parser struct {
 	actorParser            *actors.ActorParser
 	addresses              *types.AddressInfoMap
 	txCidEquivalents       []types.TxCidTranslation
+	tokenTransfers         []*types.TokenTransfer
+	skippedTraces          []types.SkippedTrace
 	helper                 *helper.Helper
 	logger                 *zap.Logger
 	multisigEventGenerator multisigTools.EventGenerator
@@ -67,79 +71,219 @@ func (p *Parser) IsNodeVersionSupported(ver string) bool {
 	return false
 }
 
-func (p *Parser) ParseTransactions(_ context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
-	// Unmarshal into vComputeState
-	computeState := &typesV2.ComputeStateOutputV2{}
-	err := sonic.UnmarshalString(string(txsData.Traces), &computeState)
+func (p *Parser) ParseTransactions(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
+	traces, err := p.DecodeTraces(txsData)
+	if err != nil {
+		return nil, err
+	}
+	result, pending, err := p.BuildTxTree(ctx, txsData, traces)
+	if err != nil {
+		return nil, err
+	}
+	if err = pending.Decode(ctx); err != nil {
+		return nil, err
+	}
+	pending.Render()
+
+	// Clear this cache when we finish processing a tipset.
+	// Bad addresses in this tipset might be valid in the next one
+	p.helper.GetActorsCache().ClearBadAddressCache()
+	return result, nil
+}
+
+// ParseTransactionsStream parses the txs like ParseTransactions, passing the txs of every trace to fn as soon as
+// the trace is parsed instead of collecting them, so the txs of the tipset are never held in memory at once. The
+// result holds everything but the txs. Resuming from a checkpoint is not supported, as it needs the parsed txs.
+func (p *Parser) ParseTransactionsStream(ctx context.Context, txsData types.TxsData, fn func(txs []*types.Transaction) error) (*types.TxsParsedResult, error) {
+	txsData.Checkpointer = nil
+	source, err := p.traceSource(txsData)
+	if err != nil {
+		p.logger.Sugar().Error(err)
+		return nil, errors.New("could not decode")
+	}
+	result, _, err := p.buildTxTree(ctx, txsData, source, fn)
+	if err != nil {
+		return nil, err
+	}
+	p.helper.GetActorsCache().ClearBadAddressCache()
+	return result, nil
+}
+
+// DecodeTraces decodes the traces of the tipset, to be passed to BuildTxTree. The traces bigger than the
+// LowMemoryTraceThreshold are decoded one at a time while the tx tree is built instead.
+func (p *Parser) DecodeTraces(txsData types.TxsData) (parser.DecodedTraces, error) {
+	source, err := p.traceSource(txsData)
 	if err != nil {
 		p.logger.Sugar().Error(err)
 		return nil, errors.New("could not decode")
 	}
+	return source, nil
+}
+
+// BuildTxTree builds the txs of the traces decoded by DecodeTraces. The params of the txs are not decoded: they are
+// queued in the returned PendingMetadata instead. With a checkpointer, the params of every trace are decoded and
+// rendered as it is parsed, so the checkpoints hold complete txs.
+func (p *Parser) BuildTxTree(ctx context.Context, txsData types.TxsData, traces parser.DecodedTraces) (*types.TxsParsedResult, *parser.PendingMetadata, error) {
+	source, ok := traces.(traceSource)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected decoded traces %T", traces)
+	}
+	return p.buildTxTree(ctx, txsData, source, nil)
+}
+
+// buildTxTree parses the traces of the tipset. If emit is set, the txs of every trace are decoded, rendered and
+// passed to it instead of being returned.
+func (p *Parser) buildTxTree(ctx context.Context, txsData types.TxsData, source traceSource,
+	emit func(txs []*types.Transaction) error) (*types.TxsParsedResult, *parser.PendingMetadata, error) {
+	traces := resolvedTraces{traces: source, parentBaseFee: p.parentBaseFee(txsData.Tipset), receipts: txsData.Receipts}
 
 	var transactions []*types.Transaction
 	p.addresses = types.NewAddressInfoMap()
 	p.txCidEquivalents = make([]types.TxCidTranslation, 0)
+	p.tokenTransfers = make([]*types.TokenTransfer, 0)
+	p.skippedTraces = make([]types.SkippedTrace, 0)
+	p.helper.StartAddressLookups()
 
+	resume, err := parser.NewTraceResume(txsData, Version, p.logger)
 	if err != nil {
-		return nil, parser.ErrBlockHash
+		return nil, nil, err
 	}
-	for _, trace := range computeState.Trace {
+	if checkpoint := resume.Checkpoint(); checkpoint != nil {
+		transactions = checkpoint.Txs
+		p.addresses = types.NewAddressInfoMapFrom(checkpoint.Addresses)
+		p.txCidEquivalents = checkpoint.TxCids
+		p.tokenTransfers = checkpoint.TokenTransfers
+		p.skippedTraces = append(p.skippedTraces, checkpoint.SkippedTraces...)
+	}
+
+	premiums, err := p.gasPremiumDistribution(traces)
+	if err != nil {
+		p.logger.Sugar().Error(err)
+		return nil, nil, errors.New("could not decode")
+	}
+	pending := parser.NewPendingMetadata()
+	// the params are decoded trace by trace if the txs leave the parser before the tree is complete
+	inline := emit != nil || resume.Enabled()
+	linkReceipts := p.helper.GetConfig().LinkReceipts
+	explicitMessages := uint64(0)
+	var emitErr error
+	err = traces.each(func(i int, trace *typesV2.InvocResultV2) error {
+		// the traces parsed so far are kept by the resume checkpoint, if any, when the context is done
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// implicit messages have no receipt, so the receipt index is the amount of explicit messages applied before
+		receiptIndex, hasReceipt := explicitMessages, trace.Msg != nil && !parser.IsImplicitMessage(trace.Msg.From)
+		if hasReceipt {
+			explicitMessages++
+		}
+		if resume.Skip(i) {
+			return nil
+		}
+		resume.Save(i, func() *types.TraceCheckpoint { return p.traceCheckpoint(transactions) })
+
+		skipStart := len(p.skippedTraces)
 		if trace.Msg == nil {
-			continue
+			p.skipTrace(trace.MsgCid, "", types.SkipReasonNoMessage, "")
+			parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
+			return nil
 		}
 
 		// Main transaction
-		transaction, err := p.parseTrace(trace.ExecutionTrace, trace.MsgCid, txsData.Tipset, uuid.Nil.String())
+		msgStart := len(transactions)
+		transaction, err := p.parseTrace(ctx, trace.ExecutionTrace, trace.MsgCid, txsData.Tipset, uuid.Nil.String(), false, pending)
 		if err != nil {
-			continue
+			p.skipTrace(trace.MsgCid, "", types.SkipReasonParseError, err.Error())
+			parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
+			return nil
 		}
 
 		// We only set the gas usage for the main transaction.
 		// If we need the gas usage of all sub-txs, we need to also parse GasCharges (today is very inefficient)
-		transaction.GasUsed = trace.GasCost.GasUsed.Uint64()
+		transaction.GasUsed = parser.GetGasUsed(trace.GasCost.GasUsed)
+		if linkReceipts && hasReceipt {
+			parser.LinkReceipt(transaction, receiptIndex, txsData.ReceiptsRoot)
+		}
 
 		transactions = append(transactions, transaction)
 
 		// Only process sub-calls if the parent call was successfully executed
 		if trace.ExecutionTrace.MsgRct.ExitCode.IsSuccess() {
-			subTxs := p.parseSubTxs(trace.ExecutionTrace.Subcalls, trace.MsgCid, txsData.Tipset, txsData.EthLogs,
-				trace.Msg.Cid().String(), transaction.Id, 0)
+			subTxs := p.parseSubTxs(ctx, trace.ExecutionTrace.Subcalls, trace.MsgCid, txsData.Tipset, txsData.EthLogs,
+				trace.Msg.Cid().String(), transaction.Id, "", 0, false, pending)
 			if len(subTxs) > 0 {
 				transactions = append(transactions, subTxs...)
 			}
+		} else if len(trace.ExecutionTrace.Subcalls) > 0 {
+			p.skipTrace(trace.MsgCid, "", types.SkipReasonFailedParent, fmt.Sprintf("%d sub-calls of the failed message", len(trace.ExecutionTrace.Subcalls)))
 		}
 
 		// Fees
-		if trace.GasCost.TotalCost.Uint64() > 0 {
-			feeTx := p.feesTransactions(trace, txsData.Tipset, transaction.TxType, transaction.Id)
-			transactions = append(transactions, feeTx)
+		if parser.IsPositiveAmount(trace.GasCost.TotalCost) {
+			transactions = append(transactions, p.feeTxs(trace, txsData.Tipset, transaction.TxType, transaction.Id, premiums)...)
 		}
+		parser.SetExecutionIndex(transactions[msgStart:], i)
+		parser.SetSkippedTracesExecutionIndex(p.skippedTraces[skipStart:], i)
 
 		// TxCid <-> TxHash
-		txHash, err := parser.TranslateTxCidToTxHash(p.helper.GetFilecoinNodeClient(), trace.MsgCid)
+		txHash, err := parser.TranslateTxCidToTxHashWithContext(ctx, p.helper.GetFilecoinNodeClient(), trace.MsgCid)
 		if err == nil && txHash != "" {
 			p.txCidEquivalents = append(p.txCidEquivalents, types.TxCidTranslation{TxCid: trace.MsgCid.String(), TxHash: txHash})
 		}
+
+		if inline {
+			if err := pending.Decode(ctx); err != nil {
+				return err
+			}
+			pending.Render()
+		}
+		if emit != nil {
+			batch := tools.SetNodeMetadata(transactions, txsData.Metadata, Version)
+			transactions = nil
+			if err := emit(batch); err != nil {
+				emitErr = err
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		p.logger.Sugar().Error(err)
+		if emitErr != nil {
+			return nil, nil, emitErr
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		return nil, nil, errors.New("could not decode")
 	}
 
+	resume.Done()
 	transactions = tools.SetNodeMetadata(transactions, txsData.Metadata, Version)
 
-	// Clear this cache when we finish processing a tipset.
-	// Bad addresses in this tipset might be valid in the next one
-	p.helper.GetActorsCache().ClearBadAddressCache()
-
 	return &types.TxsParsedResult{
-		Txs:       transactions,
-		Addresses: p.addresses,
-		TxCids:    p.txCidEquivalents,
-	}, nil
+		Txs:            transactions,
+		Addresses:      p.addresses,
+		TxCids:         p.txCidEquivalents,
+		TokenTransfers: p.tokenTransfers,
+		Report:         types.ParseReport{SkippedTraces: p.skippedTraces},
+	}, pending, nil
 }
 
-func (p *Parser) ParseNativeEvents(_ context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error) {
+func (p *Parser) ParseNativeEvents(ctx context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error) {
+	nativeLogs := eventsData.NativeLog
+	if len(eventsData.Receipts) > 0 {
+		receiptsLogs, err := eventTools.ActorEventsFromReceipts(ctx, eventsData.Tipset, eventsData.Receipts, p.helper)
+		if err != nil {
+			return nil, err
+		}
+		nativeLogs = append(slices.Clone(nativeLogs), receiptsLogs...)
+	}
+
 	var parsed []*types.Event
 	nativeEventsTotal, evmEventsTotal := 0, 0
-	for idx, nativeLog := range eventsData.NativeLog {
-		event, err := eventTools.ParseNativeLog(eventsData.Tipset, nativeLog, uint64(idx))
+	for idx, nativeLog := range nativeLogs {
+		event, err := eventTools.ParseNativeLogWithSchemas(eventsData.Tipset, nativeLog, uint64(idx), p.helper.GetEventSchemas(), p.ids())
 		if err != nil {
 			return nil, err
 		}
@@ -158,7 +302,7 @@ func (p *Parser) ParseNativeEvents(_ context.Context, eventsData types.EventsDat
 	return &types.EventsParsedResult{EVMEvents: evmEventsTotal, NativeEvents: nativeEventsTotal, ParsedEvents: parsed}, nil
 }
 
-func (p *Parser) ParseEthLogs(_ context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error) {
+func (p *Parser) ParseEthLogs(ctx context.Context, eventsData types.EventsData) (*types.EventsParsedResult, error) {
 	var parsed []*types.Event
 	// sort the events by the TransactionIndex ASC and the logIndex ASC
 	slices.SortFunc(eventsData.EthLogs, func(a, b types.EthLog) int {
@@ -169,7 +313,7 @@ func (p *Parser) ParseEthLogs(_ context.Context, eventsData types.EventsData) (*
 	})
 
 	for idx, ethLog := range eventsData.EthLogs {
-		event, err := eventTools.ParseEthLog(eventsData.Tipset, ethLog, p.helper, uint64(idx))
+		event, err := eventTools.ParseEthLogWithContext(ctx, eventsData.Tipset, ethLog, p.helper, uint64(idx))
 		if err != nil {
 			zap.S().Errorf("error retrieving selector_sig for hash: %s err: %s", event.SelectorID, err)
 		}
@@ -179,19 +323,31 @@ func (p *Parser) ParseEthLogs(_ context.Context, eventsData types.EventsData) (*
 
 	parsed = tools.SetNodeMetadata(parsed, eventsData.Metadata, Version)
 
-	return &types.EventsParsedResult{EVMEvents: len(parsed), ParsedEvents: parsed}, nil
+	return &types.EventsParsedResult{EVMEvents: len(parsed), ParsedEvents: parsed, LogsBloom: eventTools.ComputeLogsBloom(eventsData.EthLogs)}, nil
 }
 
 func (p *Parser) ParseMultisigEvents(ctx context.Context, multisigTxs []*types.Transaction, tipsetCid string, tipsetKey filTypes.TipSetKey) (*types.MultisigEvents, error) {
 	return p.multisigEventGenerator.GenerateMultisigEvents(ctx, multisigTxs, tipsetCid, tipsetKey)
 }
 
+// GetBaseFee returns the base fee of the tipset, or types.ErrBaseFeeOverflow if it does not fit in an uint64.
+//
+// Deprecated: use GetBaseFeeWithSource, which returns the whole base fee
 func (p *Parser) GetBaseFee(traces []byte, tipset *types.ExtendedTipSet) (uint64, error) {
+	baseFee, err := p.GetBaseFeeWithSource(traces, tipset)
+	if err != nil {
+		return baseFee.Value, err
+	}
+	return baseFee.Uint64()
+}
+
+// GetBaseFeeWithSource returns the base fee of the tipset and how it was derived
+func (p *Parser) GetBaseFeeWithSource(traces []byte, tipset *types.ExtendedTipSet) (types.BaseFee, error) {
 	// Unmarshal into vComputeState
-	computeState := &typesV2.ComputeStateOutputV2{}
-	if err := sonic.UnmarshalString(string(traces), &computeState); err != nil {
+	computeState, err := decodeComputeState(traces, false, p.helper.GetConfig().JSONCodec)
+	if err != nil {
 		p.logger.Sugar().Error(err)
-		return 0, errors.New("could not decode")
+		return types.BaseFee{}, errors.New("could not decode")
 	}
 
 	baseFee := big.NewInt(0)
@@ -199,7 +355,7 @@ func (p *Parser) GetBaseFee(traces []byte, tipset *types.ExtendedTipSet) (uint64
 	for _, trace := range computeState.Trace {
 		baseFeeBurn := trace.GasCost.BaseFeeBurn
 		gasUsed := trace.GasCost.GasUsed
-		if gasUsed.IsZero() {
+		if gasUsed.Int == nil || gasUsed.IsZero() || baseFeeBurn.Int == nil {
 			continue
 		}
 
@@ -209,30 +365,95 @@ func (p *Parser) GetBaseFee(traces []byte, tipset *types.ExtendedTipSet) (uint64
 	}
 
 	if !found {
-		return parser.GetParentBaseFeeByHeight(tipset, p.logger)
+		parentBaseFee, err := parser.GetParentBaseFee(tipset, p.logger)
+		return types.NewBaseFee(parentBaseFee, types.BaseFeeSourceParentBaseFee), err
 	}
 
-	return baseFee.Uint64(), nil
+	return types.NewBaseFee(baseFee, types.BaseFeeSourceTraces), nil
 }
 
-func (p *Parser) parseSubTxs(subTxs []typesV2.ExecutionTraceV2, mainMsgCid cid.Cid, tipSet *types.ExtendedTipSet, ethLogs []types.EthLog, txHash string,
-	parentId string, level uint16) (txs []*types.Transaction) {
+// ParseFees re-extracts only the fee txs of the tipset from its traces. Params decoding, sub-calls and address
+// consolidation are skipped, so fees can be recomputed for large ranges without parsing the whole tipset again.
+func (p *Parser) ParseFees(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error) {
+	computeState, err := decodeComputeState(txsData.Traces, txsData.Metadata.IsForest(), p.helper.GetConfig().JSONCodec)
+	if err != nil {
+		p.logger.Sugar().Error(err)
+		return nil, errors.New("could not decode")
+	}
+
+	appTools := tools.Tools{Logger: p.logger}
+	var transactions []*types.Transaction
+	parentBaseFee := p.parentBaseFee(txsData.Tipset)
+	premiums, _ := p.gasPremiumDistribution(resolvedTraces{traces: decodedTraces(computeState.Trace), parentBaseFee: parentBaseFee,
+		receipts: txsData.Receipts})
+	for i, trace := range computeState.Trace {
+		resolveGasOutputs(trace, parentBaseFee)
+		if trace.Msg == nil || !parser.IsPositiveAmount(trace.GasCost.TotalCost) {
+			continue
+		}
+
+		txType, err := p.helper.GetMethodNameWithContext(ctx, &parser.LotusMessage{
+			To:     trace.ExecutionTrace.Msg.To,
+			From:   trace.ExecutionTrace.Msg.From,
+			Method: trace.ExecutionTrace.Msg.Method,
+		}, int64(txsData.Tipset.Height()), txsData.Tipset.Key())
+		if err != nil {
+			p.logger.Sugar().Errorf("Error when trying to get method name in tx cid'%s': %v", trace.MsgCid.String(), err)
+			txType = parser.UnknownStr
+		}
+
+		blockCid, err := appTools.GetBlockCidFromMsgCid(trace.MsgCid.String(), txType, nil, txsData.Tipset)
+		if err != nil {
+			p.logger.Sugar().Errorf("Error when trying to get block cid from message, txType '%s': %v", txType, err)
+		}
+
+		parentId, err := p.messageId(trace.ExecutionTrace, trace.MsgCid, blockCid, txsData.Tipset, uuid.Nil.String())
+		if err != nil {
+			p.logger.Sugar().Errorf("Error when trying to build message id in tx cid'%s': %v", trace.MsgCid.String(), err)
+		}
+
+		feeTxs := p.feeTxs(trace, txsData.Tipset, txType, parentId, premiums)
+		parser.SetExecutionIndex(feeTxs, i)
+		transactions = append(transactions, feeTxs...)
+	}
+
+	transactions = tools.SetNodeMetadata(transactions, txsData.Metadata, Version)
+	return &types.TxsParsedResult{Txs: transactions}, nil
+}
+
+func (p *Parser) parseSubTxs(ctx context.Context, subTxs []typesV2.ExecutionTraceV2, mainMsgCid cid.Cid, tipSet *types.ExtendedTipSet, ethLogs []types.EthLog, txHash string,
+	parentId string, path string, level uint16, reverted bool, pending *parser.PendingMetadata) (txs []*types.Transaction) {
 	level++
-	for _, subTx := range subTxs {
-		subTransaction, err := p.parseTrace(subTx, mainMsgCid, tipSet, parentId)
+	for i, subTx := range subTxs {
+		subPath := parser.BuildInternalTxPath(path, i)
+		subTransaction, err := p.parseTrace(ctx, subTx, mainMsgCid, tipSet, parentId, reverted, pending)
 		if err != nil {
+			p.skipTrace(mainMsgCid, subPath, types.SkipReasonParseError, err.Error())
 			continue
 		}
 
+		subTransaction.InternalTxId = parser.BuildInternalTxId(mainMsgCid.String(), subPath)
+
+		// Sub-calls of a failed call are reverted, even if they succeeded (e.g. value sends)
+		subTransaction.Status = parser.GetTxStatus(subTx.MsgRct.ExitCode,
+			reverted && p.helper.GetConfig().IsFeatureEnabled(parser.FeatureRevertedStatus))
 		subTransaction.Level = level
 		txs = append(txs, subTransaction)
-		txs = append(txs, p.parseSubTxs(subTx.Subcalls, mainMsgCid, tipSet, ethLogs, txHash, subTransaction.Id, level)...)
+
+		subSubTxs := p.parseSubTxs(ctx, subTx.Subcalls, mainMsgCid, tipSet, ethLogs, txHash, subTransaction.Id, subPath, level,
+			reverted || subTx.MsgRct.ExitCode.IsError(), pending)
+		if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureMinerCronPenalties) {
+			parser.ClassifyMinerCronTxs(subTransaction, subTx.Msg.Params, subSubTxs)
+		}
+		txs = append(txs, subSubTxs...)
 	}
 	return
 }
 
-func (p *Parser) parseTrace(trace typesV2.ExecutionTraceV2, mainMsgCid cid.Cid, tipset *types.ExtendedTipSet, parentId string) (*types.Transaction, error) {
-	txType, err := p.helper.GetMethodName(&parser.LotusMessage{
+// parseTrace builds the tx of the trace, queuing the decoding of its params in pending
+func (p *Parser) parseTrace(ctx context.Context, trace typesV2.ExecutionTraceV2, mainMsgCid cid.Cid, tipset *types.ExtendedTipSet, parentId string,
+	reverted bool, pending *parser.PendingMetadata) (*types.Transaction, error) {
+	txType, err := p.helper.GetMethodNameWithContext(ctx, &parser.LotusMessage{
 		To:     trace.Msg.To,
 		From:   trace.Msg.From,
 		Method: trace.Msg.Method,
@@ -246,36 +467,56 @@ func (p *Parser) parseTrace(trace typesV2.ExecutionTraceV2, mainMsgCid cid.Cid,
 		p.logger.Sugar().Errorf("Could not get method name in transaction '%s'", mainMsgCid.String())
 	}
 
-	metadata, addressInfo, mErr := p.actorParser.GetMetadata(txType, &parser.LotusMessage{
-		To:     trace.Msg.To,
-		From:   trace.Msg.From,
-		Method: trace.Msg.Method,
-		Cid:    mainMsgCid,
-		Params: trace.Msg.Params,
-	}, mainMsgCid, &parser.LotusMessageReceipt{
-		ExitCode: trace.MsgRct.ExitCode,
-		Return:   trace.MsgRct.Return,
-	}, int64(tipset.Height()), tipset.Key())
+	decode := func(ctx context.Context) map[string]interface{} {
+		metadata, addressInfo, mErr := p.actorParser.GetMetadata(ctx, txType, &parser.LotusMessage{
+			To:          trace.Msg.To,
+			From:        trace.Msg.From,
+			Method:      trace.Msg.Method,
+			Cid:         mainMsgCid,
+			Params:      trace.Msg.Params,
+			ParamsCodec: trace.Msg.ParamsCodec,
+		}, mainMsgCid, &parser.LotusMessageReceipt{
+			ExitCode:    trace.MsgRct.ExitCode,
+			Return:      trace.MsgRct.Return,
+			ReturnCodec: trace.MsgRct.ReturnCodec,
+		}, int64(tipset.Height()), tipset.Key())
+
+		if mErr != nil {
+			p.logger.Sugar().Warnf("Could not get metadata for transaction in height %s of type '%s': %s", tipset.Height().String(), txType, mErr.Error())
+		}
+		if txType == parser.UnknownStr || errors.Is(mErr, parser.ErrUnknownMethod) {
+			reason := parser.UnknownMethodReasonMetadata
+			if txType == parser.UnknownStr {
+				reason = parser.UnknownMethodReasonName
+			}
+			p.helper.RecordUnknownMethodWithContext(ctx, &parser.LotusMessage{To: trace.Msg.To, Method: trace.Msg.Method}, reason, mainMsgCid.String(),
+				int64(tipset.Height()), tipset.Key())
+		}
+		if addressInfo != nil {
+			if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureCreationHeight) {
+				parser.SetCreationHeight(addressInfo, uint64(tipset.Height()))
+			}
+			parser.AppendToAddressesMap(p.addresses, addressInfo)
+		}
+		if trace.MsgRct.ExitCode.IsError() {
+			metadata["Error"] = trace.MsgRct.ExitCode.Error()
+		}
 
-	if mErr != nil {
-		p.logger.Sugar().Warnf("Could not get metadata for transaction in height %s of type '%s': %s", tipset.Height().String(), txType, mErr.Error())
-	}
-	if addressInfo != nil {
-		parser.AppendToAddressesMap(p.addresses, addressInfo)
+		p.appendAddressInfo(ctx, &parser.LotusMessage{
+			To:     trace.Msg.To,
+			From:   trace.Msg.From,
+			Method: trace.Msg.Method,
+			Cid:    mainMsgCid,
+			Params: trace.Msg.Params,
+		}, tipset.Key())
+		return metadata
 	}
-	if trace.MsgRct.ExitCode.IsError() {
-		metadata["Error"] = trace.MsgRct.ExitCode.Error()
-	}
-
-	jsonMetadata, _ := json.Marshal(metadata)
 
-	p.appendAddressInfo(&parser.LotusMessage{
-		To:     trace.Msg.To,
-		From:   trace.Msg.From,
-		Method: trace.Msg.Method,
-		Cid:    mainMsgCid,
-		Params: trace.Msg.Params,
-	}, tipset.Key())
+	// the block of a reward is the one mined by the miner in its params, so they are decoded along with the tx
+	var metadata map[string]interface{}
+	if txType == parser.MethodAwardBlockReward {
+		metadata = decode(ctx)
+	}
 
 	appTools := tools.Tools{Logger: p.logger}
 	blockCid, err := appTools.GetBlockCidFromMsgCid(mainMsgCid.String(), txType, metadata, tipset)
@@ -283,15 +524,14 @@ func (p *Parser) parseTrace(trace typesV2.ExecutionTraceV2, mainMsgCid cid.Cid,
 		p.logger.Sugar().Errorf("Error when trying to get block cid from message, txType '%s': %v", txType, err)
 	}
 
-	msgCid, err := tools.BuildCidFromMessageTrace(trace.Msg, mainMsgCid.String())
+	messageUuid, err := p.messageId(trace, mainMsgCid, blockCid, tipset, parentId)
 	if err != nil {
 		p.logger.Sugar().Errorf("Error when trying to build message cid in tx cid'%s': %v", mainMsgCid.String(), err)
 	}
 
 	tipsetCid := tipset.GetCidString()
-	messageUuid := tools.BuildMessageId(tipsetCid, blockCid, mainMsgCid.String(), msgCid, parentId)
 
-	return &types.Transaction{
+	transaction := &types.Transaction{
 		TxBasicBlockData: types.TxBasicBlockData{
 			BasicBlockData: types.BasicBlockData{
 				Height:    uint64(tipset.Height()),
@@ -308,11 +548,106 @@ func (p *Parser) parseTrace(trace typesV2.ExecutionTraceV2, mainMsgCid cid.Cid,
 		Amount:      trace.Msg.Value.Int,
 		Status:      parser.GetExitCodeStatus(trace.MsgRct.ExitCode),
 		TxType:      txType,
-		TxMetadata:  string(jsonMetadata),
-	}, nil
+	}
+	if metadata != nil {
+		pending.AddDecoded(transaction, metadata)
+	} else {
+		pending.Add(transaction, decode)
+	}
+
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureFRC46TokenTransfers) {
+		if transfer := parser.NewTokenTransfer(&parser.LotusMessage{From: trace.Msg.From, To: trace.Msg.To, Method: trace.Msg.Method, Params: trace.Msg.Params},
+			&parser.LotusMessageReceipt{ExitCode: trace.MsgRct.ExitCode}, transaction, reverted); transfer != nil {
+			p.tokenTransfers = append(p.tokenTransfers, transfer)
+		}
+	}
+
+	return transaction, nil
+}
+
+// messageId builds the id of the tx generated by the trace
+func (p *Parser) messageId(trace typesV2.ExecutionTraceV2, mainMsgCid cid.Cid, blockCid string, tipset *types.ExtendedTipSet, parentId string) (string, error) {
+	msgCid, err := tools.BuildCidFromMessageTrace(trace.Msg, mainMsgCid.String())
+	return p.ids().BuildMessageId(tipset.GetCidString(), blockCid, mainMsgCid.String(), msgCid, parentId), err
+}
+
+// ids returns the builder of the tx ids, using the configured hash scheme
+func (p *Parser) ids() tools.IdBuilder {
+	return tools.NewIdBuilder(p.helper.GetConfig().IdHashScheme)
 }
 
-func (p *Parser) feesTransactions(msg *typesV2.InvocResultV2, tipset *types.ExtendedTipSet, txType, parentTxId string) *types.Transaction {
+// traceCheckpoint snapshots the state built from the traces parsed so far
+func (p *Parser) traceCheckpoint(transactions []*types.Transaction) *types.TraceCheckpoint {
+	return &types.TraceCheckpoint{
+		Txs:            transactions,
+		Addresses:      p.addresses.Copy(),
+		TxCids:         p.txCidEquivalents,
+		TokenTransfers: p.tokenTransfers,
+		SkippedTraces:  p.skippedTraces,
+	}
+}
+
+// skipTrace records a trace that did not produce any tx. The execution index is set by the caller.
+func (p *Parser) skipTrace(msgCid cid.Cid, path, reason, detail string) {
+	skipped := types.SkippedTrace{Path: path, Reason: reason, Detail: detail}
+	if msgCid.Defined() {
+		skipped.TxCid = msgCid.String()
+	}
+	p.skippedTraces = append(p.skippedTraces, skipped)
+}
+
+// gasPremiumDistribution collects the gas premiums of the messages that pay fees in the tipset. It is nil unless
+// FeatureFeeMarket is enabled.
+func (p *Parser) gasPremiumDistribution(traces traceSource) (*parser.GasPremiumDistribution, error) {
+	if !p.helper.GetConfig().IsFeatureEnabled(parser.FeatureFeeMarket) {
+		return nil, nil
+	}
+
+	var premiums []filBig.Int
+	err := traces.each(func(_ int, trace *typesV2.InvocResultV2) error {
+		if trace != nil && trace.Msg != nil && parser.IsPositiveAmount(trace.GasCost.TotalCost) {
+			premiums = append(premiums, trace.Msg.GasPremium)
+		}
+		return nil
+	})
+	return parser.NewGasPremiumDistribution(premiums), err
+}
+
+// traceSource decodes the traces all at once, or streams them if they are bigger than the LowMemoryTraceThreshold
+func (p *Parser) traceSource(txsData types.TxsData) (traceSource, error) {
+	config := p.helper.GetConfig()
+	if len(txsData.Traces) > config.GetLowMemoryTraceThreshold() {
+		p.logger.Sugar().Infof("[parser] - traces of %d bytes exceed the low memory threshold, decoding them one at a time", len(txsData.Traces))
+		return newTraceStream(txsData.Traces, txsData.Metadata.IsForest(), config.JSONCodec), nil
+	}
+
+	computeState, err := decodeComputeState(txsData.Traces, txsData.Metadata.IsForest(), config.JSONCodec)
+	if err != nil {
+		return nil, err
+	}
+	return decodedTraces(computeState.Trace), nil
+}
+
+// parentBaseFee returns the parent base fee of the tipset the fees are recomputed with, nil if it is unknown
+func (p *Parser) parentBaseFee(tipset *types.ExtendedTipSet) filBig.Int {
+	if tipset == nil || len(tipset.Blocks()) == 0 {
+		return filBig.Int{}
+	}
+	return tipset.Blocks()[0].ParentBaseFee
+}
+
+// feeTxs returns the fee tx of the message and, if enabled, its gas refund tx
+func (p *Parser) feeTxs(trace *typesV2.InvocResultV2, tipset *types.ExtendedTipSet, txType, parentTxId string, premiums *parser.GasPremiumDistribution) []*types.Transaction {
+	feeTx := p.feesTransactions(trace, tipset, txType, parentTxId, premiums)
+	txs := []*types.Transaction{feeTx}
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureGasRefunds) && parser.IsPositiveAmount(trace.GasCost.Refund) {
+		txs = append(txs, parser.NewGasRefundTx(feeTx, p.ids().BuildId(feeTx.Id, parser.GasRefundOp),
+			trace.Msg.GasLimit, trace.Msg.GasFeeCap, trace.GasCost.Refund))
+	}
+	return txs
+}
+
+func (p *Parser) feesTransactions(msg *typesV2.InvocResultV2, tipset *types.ExtendedTipSet, txType, parentTxId string, premiums *parser.GasPremiumDistribution) *types.Transaction {
 	timestamp := parser.GetTimestamp(tipset.MinTimestamp())
 	appTools := tools.Tools{Logger: p.logger}
 	blockCid, err := appTools.GetBlockCidFromMsgCid(msg.MsgCid.String(), txType, nil, tipset)
@@ -339,10 +674,21 @@ func (p *Parser) feesTransactions(msg *typesV2.InvocResultV2, tipset *types.Exte
 			BurnAddress: parser.BurnAddress,
 			Amount:      msg.GasCost.BaseFeeBurn.String(),
 		},
+		GasOutputsSource: msg.GasOutputsSource,
+	}
+	if p.helper.GetConfig().IsFeatureEnabled(parser.FeatureGasRefunds) {
+		feesMetadata.RefundFee = &parser.RefundFee{RefundAddress: msg.Msg.From.String(), Amount: msg.GasCost.Refund.String()}
+	}
+	if premiums != nil {
+		placement := premiums.Placement(msg.Msg.GasPremium)
+		feesMetadata.FeeMarket = &placement
+	}
+	if parser.IsPositiveAmount(msg.GasCost.MinerPenalty) {
+		feesMetadata.MinerPenalty = &parser.MinerPenalty{MinerAddress: minerAddress, Amount: msg.GasCost.MinerPenalty.String()}
 	}
 
 	metadata, _ := json.Marshal(feesMetadata)
-	feeID := tools.BuildFeeId(tipset.GetCidString(), blockCid, msg.MsgCid.String())
+	feeID := p.ids().BuildFeeId(tipset.GetCidString(), blockCid, msg.MsgCid.String())
 
 	return &types.Transaction{
 		TxBasicBlockData: types.TxBasicBlockData{
@@ -364,11 +710,14 @@ func (p *Parser) feesTransactions(msg *typesV2.InvocResultV2, tipset *types.Exte
 	}
 }
 
-func (p *Parser) appendAddressInfo(msg *parser.LotusMessage, key filTypes.TipSetKey) {
+func (p *Parser) appendAddressInfo(ctx context.Context, msg *parser.LotusMessage, key filTypes.TipSetKey) {
 	if msg == nil {
 		return
 	}
-	fromAdd := p.helper.GetActorAddressInfo(msg.From, key)
-	toAdd := p.helper.GetActorAddressInfo(msg.To, key)
-	parser.AppendToAddressesMap(p.addresses, fromAdd, toAdd)
+	for _, add := range []address.Address{msg.From, msg.To} {
+		// the addresses left once the lookups budget runs out are not added, see AddressLookupTimeout
+		if info, ok := p.helper.LookupAddressInfo(ctx, add, key); ok {
+			parser.AppendToAddressesMap(p.addresses, info)
+		}
+	}
 }
@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+func TestGetBaseFee_Source(t *testing.T) {
+	p := &Parser{logger: zap.NewNop()}
+
+	// base fee burn / gas used of the first message using gas
+	baseFee, err := p.GetBaseFeeWithSource([]byte(forestTrace), nil)
+	require.NoError(t, err)
+	require.Equal(t, types.NewBaseFee(big.NewInt(2), types.BaseFeeSourceTraces), baseFee)
+	require.False(t, baseFee.IsFallback())
+
+	value, err := p.GetBaseFee([]byte(forestTrace), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), value)
+
+	// without messages using gas, the parent base fee of the tipset is used
+	baseFee, err = p.GetBaseFeeWithSource([]byte(`{"Trace": []}`), nil)
+	require.Error(t, err)
+	require.Equal(t, types.BaseFeeSourceParentBaseFee, baseFee.Source)
+	require.True(t, baseFee.IsFallback())
+}
+
+func TestParseFees_NoFees(t *testing.T) {
+	p := &Parser{logger: zap.NewNop(), helper: helper.NewHelper(nil, nil, nil, zap.NewNop(), parser.DefaultConfig())}
+
+	// the message of the trace pays no fees, so no fee tx is generated
+	result, err := p.ParseFees(context.Background(), types.TxsData{Traces: []byte(forestTrace)})
+	require.NoError(t, err)
+	require.Empty(t, result.Txs)
+
+	_, err = p.ParseFees(context.Background(), types.TxsData{Traces: []byte(`{`)})
+	require.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/parser"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+	"github.com/zondax/fil-parser/tools"
+)
+
+// traceSource iterates the traces of a tipset in execution order
+type traceSource interface {
+	each(fn func(i int, trace *typesV2.InvocResultV2) error) error
+}
+
+// decodedTraces are traces decoded all at once
+type decodedTraces []*typesV2.InvocResultV2
+
+func (d decodedTraces) each(fn func(i int, trace *typesV2.InvocResultV2) error) error {
+	for i, trace := range d {
+		if err := fn(i, trace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceStream decodes the traces one at a time while they are iterated, so only one of them is decoded at any
+// time. It is the low-memory strategy used for the traces bigger than the LowMemoryTraceThreshold. The layout is
+// scanned with encoding/json, and every trace is decoded with the configured JSONCodec.
+type traceStream struct {
+	raw    []byte
+	forest bool
+	codec  string
+}
+
+func newTraceStream(raw []byte, forest bool, codec string) traceStream {
+	return traceStream{raw: raw, forest: forest || isForestTrace(raw), codec: codec}
+}
+
+func (s traceStream) each(fn func(i int, trace *typesV2.InvocResultV2) error) error {
+	decoder := json.NewDecoder(bytes.NewReader(s.raw))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		// keys are matched case insensitively, same as encoding/json, which covers the forest layout too
+		if key, _ := token.(string); !strings.EqualFold(key, "Trace") {
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = s.eachTrace(decoder, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s traceStream) eachTrace(decoder *json.Decoder, fn func(i int, trace *typesV2.InvocResultV2) error) error {
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		// null traces
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected traces token %v", token)
+	}
+
+	for i := 0; decoder.More(); i++ {
+		trace, err := s.decodeTrace(decoder)
+		if err != nil {
+			return fmt.Errorf("could not decode trace %d: %w", i, err)
+		}
+		if err = fn(i, trace); err != nil {
+			return err
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+func (s traceStream) decodeTrace(decoder *json.Decoder) (*typesV2.InvocResultV2, error) {
+	var trace *typesV2.InvocResultV2
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if !s.forest {
+		err := tools.UnmarshalJSON(s.codec, raw, &trace)
+		return trace, err
+	}
+
+	normalized, err := normalizeForestTraces(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err = tools.UnmarshalJSON(s.codec, normalized, &trace); err != nil {
+		return nil, err
+	}
+	fillForestTrace(trace)
+	return trace, nil
+}
+
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("unexpected token %v, expected %s", token, want)
+	}
+	return nil
+}
+
+// resolvedTraces completes the traces with the receipts delivered separately, see joinReceipt, and resolves their
+// gas outputs, see resolveGasOutputs, while they are iterated
+type resolvedTraces struct {
+	traces        traceSource
+	parentBaseFee filBig.Int
+	receipts      map[cid.Cid]filTypes.MessageReceipt
+}
+
+func (t resolvedTraces) each(fn func(i int, trace *typesV2.InvocResultV2) error) error {
+	return t.traces.each(func(i int, trace *typesV2.InvocResultV2) error {
+		joinReceipt(trace, t.receipts)
+		resolveGasOutputs(trace, t.parentBaseFee)
+		return fn(i, trace)
+	})
+}
+
+// joinReceipt completes the trace with the receipt of its message, if it was delivered separately: the missing
+// receipt and gas used are set, and a missing or truncated return is replaced, see parser.JoinReceiptReturn
+func joinReceipt(trace *typesV2.InvocResultV2, receipts map[cid.Cid]filTypes.MessageReceipt) {
+	if trace == nil {
+		return
+	}
+	receipt, ok := receipts[trace.MsgCid]
+	if !ok {
+		return
+	}
+	if trace.MsgRct == nil {
+		trace.MsgRct = &receipt
+	}
+	if trace.GasCost.GasUsed.Int == nil || trace.GasCost.GasUsed.IsZero() {
+		trace.GasCost.GasUsed = filBig.NewInt(receipt.GasUsed)
+	}
+	msgRct := &trace.ExecutionTrace.MsgRct
+	msgRct.Return, _ = parser.JoinReceiptReturn(msgRct.Return, msgRct.ExitCode, receipt)
+}
+
+// resolveGasOutputs sets the source of the gas outputs of the trace, recomputing them if the trace does not expose
+// them. The outputs that can not be recomputed either are set to zero. Traces already resolved are left as they are.
+func resolveGasOutputs(trace *typesV2.InvocResultV2, parentBaseFee filBig.Int) {
+	if trace == nil || trace.GasOutputsSource != "" {
+		return
+	}
+	trace.GasOutputsSource = parser.ResolveGasOutputs(&trace.GasCost, trace.Msg, trace.MsgRct, parentBaseFee)
+	if trace.GasOutputsSource != "" {
+		return
+	}
+
+	gasCost := &trace.GasCost
+	for _, amount := range []*filBig.Int{&gasCost.GasUsed, &gasCost.BaseFeeBurn, &gasCost.OverEstimationBurn,
+		&gasCost.MinerPenalty, &gasCost.MinerTip, &gasCost.Refund, &gasCost.TotalCost} {
+		if amount.Int == nil {
+			*amount = filBig.Zero()
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+)
+
+const lotusTraces = `{
+	"Root": {"/": "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"},
+	"Trace": [
+		{"MsgCid": {"/": "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"}, "Msg": {"To": "f01", "From": "f02", "Value": "10", "GasFeeCap": "1", "GasPremium": "1"}},
+		{"MsgCid": {"/": "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"}, "Msg": {"To": "f03", "From": "f04", "Value": "20", "GasFeeCap": "1", "GasPremium": "2"}}
+	]
+}`
+
+func collectTraces(t *testing.T, source traceSource) []*typesV2.InvocResultV2 {
+	var traces []*typesV2.InvocResultV2
+	require.NoError(t, source.each(func(i int, trace *typesV2.InvocResultV2) error {
+		require.Equal(t, len(traces), i)
+		traces = append(traces, trace)
+		return nil
+	}))
+	return traces
+}
+
+func TestTraceStream(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "lotus traces", raw: lotusTraces},
+		{name: "forest traces", raw: forestTrace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			computeState, err := decodeComputeState([]byte(tt.raw), false, parser.JSONCodecSonic)
+			require.NoError(t, err)
+
+			// the stream decodes the same traces as the whole compute state, with every codec
+			for _, codec := range []string{parser.JSONCodecSonic, parser.JSONCodecStd} {
+				streamed := collectTraces(t, newTraceStream([]byte(tt.raw), false, codec))
+				require.Equal(t, collectTraces(t, decodedTraces(computeState.Trace)), streamed, codec)
+			}
+		})
+	}
+}
+
+func TestTraceStream_Errors(t *testing.T) {
+	// the iteration stops at the first error
+	stop := errors.New("stop")
+	calls := 0
+	err := newTraceStream([]byte(lotusTraces), false, parser.JSONCodecStd).each(func(int, *typesV2.InvocResultV2) error {
+		calls++
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, calls)
+
+	require.Empty(t, collectTraces(t, newTraceStream([]byte(`{"Root": null, "Trace": null}`), false, parser.JSONCodecStd)))
+	require.Error(t, newTraceStream([]byte(`[]`), false, parser.JSONCodecStd).each(func(int, *typesV2.InvocResultV2) error { return nil }))
+}
+
+func TestResolvedTraces_joinReceipt(t *testing.T) {
+	computeState, err := decodeComputeState([]byte(lotusTraces), false, parser.JSONCodecSonic)
+	require.NoError(t, err)
+	msgCid := computeState.Trace[0].MsgCid
+	computeState.Trace[0].ExecutionTrace.MsgRct.Return = []byte{0x82}
+	receipts := map[cid.Cid]filTypes.MessageReceipt{msgCid: {ExitCode: exitcode.Ok, Return: []byte{0x82, 0x01, 0x02}, GasUsed: 100}}
+
+	traces := collectTraces(t, resolvedTraces{traces: decodedTraces(computeState.Trace), receipts: receipts})
+	for _, trace := range traces {
+		require.NotNil(t, trace.MsgRct)
+		require.Equal(t, int64(100), trace.MsgRct.GasUsed)
+		require.Equal(t, filBig.NewInt(100), trace.GasCost.GasUsed)
+	}
+	// the truncated return is replaced, and the missing one is set
+	require.Equal(t, []byte{0x82, 0x01, 0x02}, traces[0].ExecutionTrace.MsgRct.Return)
+	require.Equal(t, []byte{0x82, 0x01, 0x02}, traces[1].ExecutionTrace.MsgRct.Return)
+}
@@ -3,9 +3,9 @@ package types
 import (
 	"time"
 
-	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/ipfs/go-cid"
+	parserTypes "github.com/zondax/fil-parser/types"
 )
 
 type ComputeStateOutputV2 struct {
@@ -16,13 +16,16 @@ type ComputeStateOutputV2 struct {
 // InvocResult This is a copy of native lotus InvocResult type. We need to copy it because
 // we need a modified ExecutionTrace field, and we can't do that in lotus codebase.
 type InvocResultV2 struct {
-	MsgCid         cid.Cid
-	Msg            *types.Message
-	MsgRct         *types.MessageReceipt
-	GasCost        api.MsgGasCost
-	Error          string
-	Duration       time.Duration
-	ExecutionTrace ExecutionTraceV2
+	MsgCid   cid.Cid
+	Msg      *types.Message
+	MsgRct   *types.MessageReceipt
+	GasCost  parserTypes.MsgGasCost
+	Error    string
+	Duration time.Duration
+	// GasOutputsSource is where the gas outputs of GasCost come from, see parser.ResolveGasOutputs. It is not
+	// part of the node traces.
+	GasOutputsSource string `json:"-"`
+	ExecutionTrace   ExecutionTraceV2
 }
 
 // ExecutionTrace This is a copy of native lotus ExecutionTrace type
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"context"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// VerifiedContract is the metadata of a verified smart contract, stored in the metadata of the txs calling
+// it under VerifiedContractKey
+type VerifiedContract struct {
+	Name       string `json:"name"`
+	Compiler   string `json:"compiler,omitempty"`
+	SourceHash string `json:"source_hash,omitempty"`
+}
+
+// ContractMetadataProvider returns the metadata of the verified contracts, e.g. from the database of a contract
+// verification service. It is called for every contract call, so implementations must be fast and safe for
+// concurrent use.
+type ContractMetadataProvider interface {
+	// VerifiedContract returns the metadata of the contract, if it is verified. The address may be in any
+	// format (short, robust, eth...).
+	VerifiedContract(ctx context.Context, address string) (*VerifiedContract, bool)
+}
+
+// StaticContractMetadataProvider returns the verified contracts from a fixed list
+type StaticContractMetadataProvider struct {
+	contracts map[string]VerifiedContract
+}
+
+// NewStaticContractMetadataProvider creates a provider from a map of addresses to their verified contracts
+func NewStaticContractMetadataProvider(contracts map[string]VerifiedContract) *StaticContractMetadataProvider {
+	return &StaticContractMetadataProvider{contracts: contracts}
+}
+
+func (s *StaticContractMetadataProvider) VerifiedContract(_ context.Context, address string) (*VerifiedContract, bool) {
+	contract, ok := s.contracts[address]
+	if !ok {
+		return nil, false
+	}
+	return &contract, true
+}
+
+// IsContractCall returns whether the tx type is a call to an evm smart contract
+func IsContractCall(txType string) bool {
+	switch txType {
+	case MethodInvokeContract, MethodInvokeContractReadOnly, MethodInvokeContractDelegate:
+		return true
+	}
+	return false
+}
+
+// AddVerifiedContractMetadata adds the metadata of the verified contract called by the tx to its metadata
+func AddVerifiedContractMetadata(tx *types.Transaction, contract VerifiedContract) error {
+	return mergeTxMetadata(tx, VerifiedContractKey, contract)
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestAddVerifiedContractMetadata(t *testing.T) {
+	contract := VerifiedContract{Name: "WFIL", Compiler: "solc 0.8.20", SourceHash: "0xabc"}
+	provider := NewStaticContractMetadataProvider(map[string]VerifiedContract{"f410fcontract": contract})
+
+	got, ok := provider.VerifiedContract(context.Background(), "f410fcontract")
+	require.True(t, ok)
+	require.Equal(t, contract, *got)
+	_, ok = provider.VerifiedContract(context.Background(), "f410fother")
+	require.False(t, ok)
+
+	tx := &types.Transaction{Id: "a", TxType: MethodInvokeContract, TxMetadata: `{"Params":"0x01"}`}
+	require.NoError(t, AddVerifiedContractMetadata(tx, contract))
+	require.JSONEq(t, `{"Params":"0x01","verifiedContract":{"name":"WFIL","compiler":"solc 0.8.20","source_hash":"0xabc"}}`, tx.TxMetadata)
+
+	tx = &types.Transaction{Id: "b", TxMetadata: "not json"}
+	require.Error(t, AddVerifiedContractMetadata(tx, contract))
+}
+
+func TestIsContractCall(t *testing.T) {
+	require.True(t, IsContractCall(MethodInvokeContract))
+	require.True(t, IsContractCall(MethodInvokeContractDelegate))
+	require.False(t, IsContractCall(MethodSend))
+}
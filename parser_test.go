@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -26,12 +27,18 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/filecoin-project/go-address"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	filBig "github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/manifest"
 	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors"
 	filTypes "github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	cidLink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/zondax/fil-parser/actors/cache/fake"
 	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/parser"
+	helper2 "github.com/zondax/fil-parser/parser/helper"
 	v1 "github.com/zondax/fil-parser/parser/v1"
 	v2 "github.com/zondax/fil-parser/parser/v2"
 	"github.com/zondax/fil-parser/tools"
@@ -281,10 +288,571 @@ func TestParser_ParseTransactions(t *testing.T) {
 			require.Equal(t, tt.results.totalTraces, len(parsedResult.Txs))
 			require.Equal(t, tt.results.totalAddress, parsedResult.Addresses.Len())
 			require.Equal(t, tt.results.totalTxCids, len(parsedResult.TxCids))
+			requireEthMetadata(t, parsedResult.Txs, ethlogs)
+			requireExecutionOrder(t, parsedResult.Txs)
 		})
 	}
 }
 
+// TestParser_ParseTransactions_FEVM parses FEVM-heavy heights, with many evm calls and eth logs, and checks the
+// decoded metadata of their evm and eam txs
+func TestParser_ParseTransactions_FEVM(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		url     string
+		height  string
+	}{
+		{
+			name:    "evm calls and eth logs (calib)",
+			version: v2.NodeVersionsSupported[0],
+			url:     calibNextNodeUrl,
+			height:  "1572087",
+		},
+		{
+			name:    "nested evm calls (calib)",
+			version: v2.NodeVersionsSupported[0],
+			url:     calibNextNodeUrl,
+			height:  "1576593",
+		},
+		{
+			name:    "evm calls with eth logs 1162295 (calib)",
+			version: v2.NodeVersionsSupported[0],
+			url:     calibNextNodeUrl,
+			height:  "1162295",
+		},
+		{
+			name:    "evm calls with eth logs 1419335 (calib)",
+			version: v2.NodeVersionsSupported[0],
+			url:     calibNextNodeUrl,
+			height:  "1419335",
+		},
+		{
+			name:    "evm calls next to multisig txs 1552242 (calib)",
+			version: v2.NodeVersionsSupported[0],
+			url:     calibNextNodeUrl,
+			height:  "1552242",
+		},
+		{
+			name:    "evm calls next to a multisig creation 1698055 (calib)",
+			version: v2.NodeVersionsSupported[0],
+			url:     calibNextNodeUrl,
+			height:  "1698055",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := getLib(t, tt.url)
+
+			tipset, err := readTipset(tt.height)
+			require.NoError(t, err)
+			ethlogs, err := readEthLogs(tt.height)
+			require.NoError(t, err)
+			require.NotEmpty(t, ethlogs)
+			traces, err := readGzFile(tracesFilename(tt.height))
+			require.NoError(t, err)
+
+			p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), zap.NewNop())
+			require.NoError(t, err)
+
+			parsedResult, err := p.ParseTransactions(context.Background(), types.TxsData{
+				EthLogs:  ethlogs,
+				Tipset:   tipset,
+				Traces:   traces,
+				Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: tt.version}},
+			})
+			require.NoError(t, err)
+
+			evmTxs := 0
+			for _, tx := range parsedResult.Txs {
+				if tx.TxType == parser.MethodInvokeContract && tx.Status == "Ok" {
+					evmTxs++
+				}
+			}
+			require.NotZero(t, evmTxs)
+			requireEthMetadata(t, parsedResult.Txs, ethlogs)
+			requireExecutionOrder(t, parsedResult.Txs)
+		})
+	}
+}
+
+// TestParser_ParseTransactionsStream_Fixtures checks that the streamed txs match the txs of ParseTransactions
+// TestParser_ParseFees_Fixtures checks that ParseFees returns the same fee txs as ParseTransactions
+func TestParser_ParseFees_Fixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		url     string
+		height  string
+	}{
+		{
+			name:    "traces from v1",
+			version: v1.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907480",
+		},
+		{
+			name:    "traces from v2",
+			version: v2.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907520",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := getLib(t, tt.url)
+
+			tipset, err := readTipset(tt.height)
+			require.NoError(t, err)
+			traces, err := readGzFile(tracesFilename(tt.height))
+			require.NoError(t, err)
+
+			p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), zap.NewNop())
+			require.NoError(t, err)
+
+			txsData := types.TxsData{
+				Tipset:   tipset,
+				Traces:   traces,
+				Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: tt.version}},
+			}
+			parsedResult, err := p.ParseTransactions(context.Background(), txsData)
+			require.NoError(t, err)
+			want := make(map[string]*types.Transaction)
+			for _, tx := range parsedResult.Txs {
+				if tx.TxType == parser.TotalFeeOp {
+					want[tx.Id] = tx
+				}
+			}
+			require.NotEmpty(t, want)
+
+			feesResult, err := p.ParseFees(context.Background(), txsData)
+			require.NoError(t, err)
+			require.Len(t, feesResult.Txs, len(want))
+			for _, got := range feesResult.Txs {
+				tx, ok := want[got.Id]
+				require.True(t, ok, "unexpected fee tx %s", got.Id)
+				require.Equal(t, tx.ParentId, got.ParentId, got.Id)
+				require.Equal(t, tx.TxCid, got.TxCid, got.Id)
+				require.Equal(t, tx.BlockCid, got.BlockCid, got.Id)
+				require.Equal(t, tx.TxFrom, got.TxFrom, got.Id)
+				require.Equal(t, tx.Amount, got.Amount, got.Id)
+				require.Equal(t, tx.Status, got.Status, got.Id)
+				require.JSONEq(t, tx.TxMetadata, got.TxMetadata, got.Id)
+			}
+		})
+	}
+}
+
+// TestParser_CheckInvariants_Fixtures checks that the fixtures parse without invariant violations
+func TestParser_CheckInvariants_Fixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		url     string
+		height  string
+	}{
+		{
+			name:    "traces from v1",
+			version: v1.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907480",
+		},
+		{
+			name:    "traces from v2",
+			version: v2.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907520",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := getLib(t, tt.url)
+
+			tipset, err := readTipset(tt.height)
+			require.NoError(t, err)
+			traces, err := readGzFile(tracesFilename(tt.height))
+			require.NoError(t, err)
+
+			config := parser.DefaultConfig()
+			config.CheckInvariants = true
+			p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), zap.NewNop(), WithConfig(config))
+			require.NoError(t, err)
+
+			parsedResult, err := p.ParseTransactions(context.Background(), types.TxsData{
+				Tipset:   tipset,
+				Traces:   traces,
+				Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: tt.version}},
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, parsedResult.Txs)
+			require.Empty(t, parsedResult.Report.InvariantViolations)
+		})
+	}
+}
+
+// TestParser_ParseTransactions_JSONCodecs checks that the fixtures parse to the same output with every json codec
+func TestParser_ParseTransactions_JSONCodecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		url     string
+		height  string
+	}{
+		{
+			name:    "traces from v1",
+			version: v1.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907480",
+		},
+		{
+			name:    "traces from v2",
+			version: v2.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907520",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := getLib(t, tt.url)
+
+			tipset, err := readTipset(tt.height)
+			require.NoError(t, err)
+			traces, err := readGzFile(tracesFilename(tt.height))
+			require.NoError(t, err)
+
+			parse := func(codec string) []byte {
+				config := parser.DefaultConfig()
+				config.JSONCodec = codec
+				p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), zap.NewNop(), WithConfig(config))
+				require.NoError(t, err)
+
+				parsedResult, err := p.ParseTransactions(context.Background(), types.TxsData{
+					Tipset:   tipset,
+					Traces:   traces,
+					Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: tt.version}},
+				})
+				require.NoError(t, err)
+				require.NotEmpty(t, parsedResult.Txs)
+
+				output, err := json.Marshal(parsedResult)
+				require.NoError(t, err)
+				return output
+			}
+
+			sonicOutput := parse(parser.JSONCodecSonic)
+			stdOutput := parse(parser.JSONCodecStd)
+			require.True(t, bytes.Equal(sonicOutput, stdOutput), "the output depends on the json codec")
+		})
+	}
+}
+
+// fakeStateTraces keeps the traces of the given messages of the height, so they only reach the actors of a fake state
+func fakeStateTraces(t *testing.T, height string, msgCids ...string) []byte {
+	raw, err := readGzFile(tracesFilename(height))
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var computeState map[string]any
+	require.NoError(t, decoder.Decode(&computeState))
+
+	var traces []any
+	for _, trace := range computeState["Trace"].([]any) {
+		msgCid := trace.(map[string]any)["MsgCid"].(map[string]any)["/"]
+		for _, keep := range msgCids {
+			if msgCid == keep {
+				traces = append(traces, trace)
+			}
+		}
+	}
+	require.Len(t, traces, len(msgCids))
+	computeState["Trace"] = traces
+
+	filtered, err := json.Marshal(computeState)
+	require.NoError(t, err)
+	return filtered
+}
+
+// TestParser_ParseTransactions_FakeActorsCache parses real traces end to end without node, resolving the actors
+// from the canned state of the fake actors cache
+func TestParser_ParseTransactions_FakeActorsCache(t *testing.T) {
+	const (
+		height    = "3573062"
+		msgCid    = "bafy2bzacea7ugpagsykgm4hybh72ndrm2cctlbhdmhmnoy4dv4uku324v2v3s"
+		miner     = "f01885111"
+		worker    = "f01885869"
+		workerKey = "f3wym2gctmzzthwqyrkwtjhbpfr36evehbmx322aggkswc2hmsywrkrc7d2ks3w65tg7zfbfwdy73cn2hfdiwq"
+	)
+	// the lib runs offline, so it resolves the actor names with the codes of the actors version of the height
+	codes, err := actors.GetActorCodeIDs(actorstypes.Version12)
+	require.NoError(t, err)
+	lib := rosettaFilecoinLib.NewRosettaConstructionFilecoin(nil)
+	lib.BuiltinActors.Metadata.ActorsNameCidMap = codes
+
+	state := fake.State{Actors: []fake.Actor{
+		{Short: miner, Code: codes[manifest.MinerKey].String()},
+		{Short: worker, Robust: workerKey, Code: codes[manifest.AccountKey].String()},
+	}}
+
+	tipset, err := readTipset(height)
+	require.NoError(t, err)
+	traces := fakeStateTraces(t, height, msgCid)
+
+	parse := func() *types.TxsParsedResult {
+		actorsCache, err := fake.NewActorsCache(state, zap.NewNop())
+		require.NoError(t, err)
+		p, err := NewFilecoinParserWithActorsCache(lib, actorsCache, nil, zap.NewNop())
+		require.NoError(t, err)
+
+		parsedResult, err := p.ParseTransactions(context.Background(), types.TxsData{
+			Tipset:   tipset,
+			Traces:   traces,
+			Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: v2.NodeVersionsSupported[0]}},
+		})
+		require.NoError(t, err)
+		return parsedResult
+	}
+
+	parsedResult := parse()
+	var post, fee *types.Transaction
+	for _, tx := range parsedResult.Txs {
+		switch tx.TxType {
+		case parser.MethodSubmitWindowedPoSt:
+			post = tx
+		case parser.TotalFeeOp:
+			fee = tx
+		}
+	}
+	require.NotNil(t, post)
+	require.Equal(t, msgCid, post.TxCid)
+	require.Equal(t, miner, post.TxTo)
+	require.Equal(t, "Ok", post.Status)
+	require.NotEmpty(t, post.TxMetadata)
+	require.NotNil(t, fee)
+	requireExecutionOrder(t, parsedResult.Txs)
+
+	minerInfo, ok := parsedResult.Addresses.Get(miner)
+	require.True(t, ok)
+	require.Equal(t, "storageminer", minerInfo.ActorType)
+
+	// the fake state only depends on its canned actors, so parsing again gives the same result
+	again := parse()
+	require.Len(t, again.Txs, len(parsedResult.Txs))
+	for i, tx := range parsedResult.Txs {
+		require.Equal(t, tx.Id, again.Txs[i].Id)
+		require.Equal(t, tx.TxType, again.Txs[i].TxType)
+		require.Equal(t, tx.TxMetadata, again.Txs[i].TxMetadata)
+	}
+}
+
+// truncateTraceReturn keeps the first bytes of the execution return of the message in the traces, as nodes truncating
+// large returns do. It returns the truncated traces and the full return.
+func truncateTraceReturn(t *testing.T, traces []byte, msgCid string, keep int) ([]byte, []byte) {
+	decoder := json.NewDecoder(bytes.NewReader(traces))
+	decoder.UseNumber()
+	var computeState map[string]any
+	require.NoError(t, decoder.Decode(&computeState))
+
+	var fullReturn []byte
+	for _, trace := range computeState["Trace"].([]any) {
+		if trace.(map[string]any)["MsgCid"].(map[string]any)["/"] != msgCid {
+			continue
+		}
+		msgRct := trace.(map[string]any)["ExecutionTrace"].(map[string]any)["MsgRct"].(map[string]any)
+		var err error
+		fullReturn, err = base64.StdEncoding.DecodeString(msgRct["Return"].(string))
+		require.NoError(t, err)
+		require.Greater(t, len(fullReturn), keep)
+		msgRct["Return"] = base64.StdEncoding.EncodeToString(fullReturn[:keep])
+	}
+	require.NotNil(t, fullReturn)
+
+	truncated, err := json.Marshal(computeState)
+	require.NoError(t, err)
+	return truncated, fullReturn
+}
+
+// TestParser_ParseTransactions_ReceiptReturn parses a message whose trace return is truncated, completing it with
+// the receipt of the message delivered separately
+func TestParser_ParseTransactions_ReceiptReturn(t *testing.T) {
+	const (
+		height    = "3573062"
+		msgCid    = "bafy2bzaceabuponyhzroa6v3dzqjay5fel7yv4iv7t2fm22l3nqfq5jxt6xfy"
+		client    = "f01477053"
+		clientKey = "f3uluczkvthb2spfmtg5mn7dsm2ybx4wzpkevbkpn2f6e23wtg2elf4s5365xejx4o66o66qy3btmrfwxbsyza"
+		provider  = "f073448"
+	)
+	codes, err := actors.GetActorCodeIDs(actorstypes.Version12)
+	require.NoError(t, err)
+	lib := rosettaFilecoinLib.NewRosettaConstructionFilecoin(nil)
+	lib.BuiltinActors.Metadata.ActorsNameCidMap = codes
+
+	state := fake.State{Actors: []fake.Actor{
+		{Short: client, Robust: clientKey, Code: codes[manifest.AccountKey].String()},
+		{Short: provider, Code: codes[manifest.MinerKey].String()},
+	}}
+
+	tipset, err := readTipset(height)
+	require.NoError(t, err)
+	traces := fakeStateTraces(t, height, msgCid)
+	truncatedTraces, fullReturn := truncateTraceReturn(t, traces, msgCid, 4)
+	receipts := map[cid.Cid]filTypes.MessageReceipt{
+		cid.MustParse(msgCid): {ExitCode: 0, Return: fullReturn, GasUsed: 253118736},
+	}
+
+	publishStorageDeals := func(traces []byte, receipts map[cid.Cid]filTypes.MessageReceipt) *types.Transaction {
+		actorsCache, err := fake.NewActorsCache(state, zap.NewNop())
+		require.NoError(t, err)
+		p, err := NewFilecoinParserWithActorsCache(lib, actorsCache, nil, zap.NewNop())
+		require.NoError(t, err)
+
+		parsedResult, err := p.ParseTransactions(context.Background(), types.TxsData{
+			Tipset:   tipset,
+			Traces:   traces,
+			Receipts: receipts,
+			Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: v2.NodeVersionsSupported[0]}},
+		})
+		require.NoError(t, err)
+		for _, tx := range parsedResult.Txs {
+			if tx.TxType == parser.MethodPublishStorageDeals && tx.TxCid == msgCid {
+				return tx
+			}
+		}
+		require.FailNow(t, "PublishStorageDeals tx not found")
+		return nil
+	}
+
+	full := publishStorageDeals(traces, nil)
+	require.Contains(t, full.TxMetadata, parser.ReturnKey)
+
+	// the truncated return can not be decoded on its own
+	truncated := publishStorageDeals(truncatedTraces, nil)
+	require.NotContains(t, truncated.TxMetadata, parser.ReturnKey)
+
+	// the receipt delivered separately completes it, giving the same tx as the full trace
+	joined := publishStorageDeals(truncatedTraces, receipts)
+	require.Equal(t, full.Id, joined.Id)
+	require.Equal(t, full.Status, joined.Status)
+	require.Equal(t, full.TxMetadata, joined.TxMetadata)
+}
+
+func TestParser_ParseTransactionsStream_Fixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		url     string
+		height  string
+	}{
+		{
+			name:    "traces from v1",
+			version: v1.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907480",
+		},
+		{
+			name:    "traces from v2",
+			version: v2.NodeVersionsSupported[0],
+			url:     nodeUrl,
+			height:  "2907520",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := getLib(t, tt.url)
+
+			tipset, err := readTipset(tt.height)
+			require.NoError(t, err)
+			traces, err := readGzFile(tracesFilename(tt.height))
+			require.NoError(t, err)
+
+			p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), zap.NewNop())
+			require.NoError(t, err)
+
+			txsData := types.TxsData{
+				Tipset:   tipset,
+				Traces:   traces,
+				Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: tt.version}},
+			}
+			parsedResult, err := p.ParseTransactions(context.Background(), txsData)
+			require.NoError(t, err)
+
+			var streamed []*types.Transaction
+			streamResult, err := p.ParseTransactionsStream(context.Background(), txsData, func(txs []*types.Transaction) error {
+				streamed = append(streamed, txs...)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, parsedResult.Addresses.Len(), streamResult.Addresses.Len())
+
+			require.Len(t, streamed, len(parsedResult.Txs))
+			want := make(map[string]*types.Transaction, len(parsedResult.Txs))
+			for _, tx := range parsedResult.Txs {
+				want[tx.Id] = tx
+			}
+			for _, got := range streamed {
+				tx, ok := want[got.Id]
+				require.True(t, ok, "unexpected tx %s", got.Id)
+				require.Equal(t, tx.ParentId, got.ParentId, got.Id)
+				require.Equal(t, tx.RootId, got.RootId, got.Id)
+				require.Equal(t, tx.IsInternal, got.IsInternal, got.Id)
+				require.Equal(t, tx.Level, got.Level, got.Id)
+				require.Equal(t, tx.TxType, got.TxType, got.Id)
+				require.Equal(t, tx.TxFrom, got.TxFrom, got.Id)
+				require.Equal(t, tx.TxTo, got.TxTo, got.Id)
+				require.Equal(t, tx.Amount, got.Amount, got.Id)
+				require.Equal(t, tx.Status, got.Status, got.Id)
+			}
+		})
+	}
+}
+
+// requireExecutionOrder checks that the txs are sorted by execution index, and that every tx of a message shares it
+func requireExecutionOrder(t *testing.T, txs []*types.Transaction) {
+	indexes := make(map[string]uint64, len(txs))
+	for i, tx := range txs {
+		if i > 0 {
+			require.GreaterOrEqual(t, tx.ExecutionIndex, txs[i-1].ExecutionIndex, tx.Id)
+		}
+		if index, ok := indexes[tx.TxCid]; ok {
+			require.Equal(t, index, tx.ExecutionIndex, tx.Id)
+		}
+		indexes[tx.TxCid] = tx.ExecutionIndex
+	}
+}
+
+// requireEthMetadata checks the decoded metadata of evm and eam txs, and that every eth log belongs to a parsed tx
+func requireEthMetadata(t *testing.T, txs []*types.Transaction, ethLogs []types.EthLog) {
+	txCids := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		txCids[tx.TxCid] = true
+		if tx.Status != "Ok" {
+			continue
+		}
+
+		switch tx.TxType {
+		case parser.MethodInvokeContract:
+			var metadata map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tx.TxMetadata), &metadata), tx.Id)
+			require.Regexp(t, "^0x[0-9a-f]*$", metadata[parser.ParamsKey], tx.Id)
+			require.Regexp(t, "^0x[0-9a-f]*$", metadata[parser.ReturnKey], tx.Id)
+		case parser.MethodCreate, parser.MethodCreate2, parser.MethodCreateExternal:
+			var metadata map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(tx.TxMetadata), &metadata), tx.Id)
+			require.NotNil(t, metadata[parser.ReturnKey], tx.Id)
+
+			msgCid, err := cid.Decode(tx.TxCid)
+			require.NoError(t, err)
+			ethHash, err := ethtypes.EthHashFromCid(msgCid)
+			require.NoError(t, err)
+			require.Equal(t, ethHash.String(), metadata[parser.EthHashKey], tx.Id)
+		}
+	}
+
+	for _, ethLog := range ethLogs {
+		require.True(t, txCids[ethLog.TransactionCid], "eth log of tx %s not attached to any tx", ethLog.TransactionCid)
+	}
+}
+
 func TestParser_GetBaseFee(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -330,6 +898,12 @@ func TestParser_GetBaseFee(t *testing.T) {
 			if tt.fallback {
 				require.Equal(t, baseFee, tipset.Blocks()[0].ParentBaseFee.Uint64())
 			}
+
+			withSource, err := p.GetBaseFeeWithSource(traces, types.BlockMetadata{}, tipset)
+			require.NoError(t, err)
+			require.Equal(t, baseFee, withSource.Value)
+			require.Equal(t, tt.baseFee, withSource.Amount)
+			require.Equal(t, tt.fallback, withSource.IsFallback())
 		})
 	}
 }
@@ -365,7 +939,10 @@ func TestParser_InDepthCompare(t *testing.T) {
 			logger, err := zap.NewDevelopment()
 			require.NoError(t, err)
 
-			p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), logger)
+			// both parsers must parse the same traces, whatever their layout
+			config := parser.DefaultConfig()
+			config.TraceVersionMismatch = parser.TraceVersionMismatchIgnore
+			p, err := NewFilecoinParser(lib, getCacheDataSource(t, tt.url), logger, WithConfig(config))
 			require.NoError(t, err)
 
 			txsData := types.TxsData{
@@ -1264,7 +1841,11 @@ func TestParser_ParseNativeEvents_EVM(t *testing.T) {
 			assert.EqualValues(t, tt.emitter.String(), events.ParsedEvents[0].Emitter)
 			if len(tt.entries) > 0 { // only check the selector_id if there are entries in the test case
 				assert.EqualValues(t, "0x013dbb9442ca9667baccc6230fcd5c1c4b2d4d2870f4bd20681d4d47cfd15184", events.ParsedEvents[0].SelectorID)
+				assert.EqualValues(t, events.ParsedEvents[0].SelectorID, events.ParsedEvents[0].Topic0)
 			}
+			// the indexes of the log are only known for the events parsed from eth logs
+			assert.Nil(t, events.ParsedEvents[0].EthLogIndex)
+			assert.Nil(t, events.ParsedEvents[0].TransactionIndex)
 		})
 	}
 
@@ -1765,11 +2346,21 @@ func TestParseGenesis(t *testing.T) {
 	lib := getLib(t, nodeUrl)
 	p, err := NewFilecoinParser(lib, getCacheDataSource(t, nodeUrl), logger)
 	assert.NoError(t, err)
-	actualTxs, _ := p.ParseGenesis(genesisBalances, genesisTipset)
+	actualTxs, addresses := p.ParseGenesis(genesisBalances, genesisTipset)
 
 	assert.Equal(t, len(actualTxs), 21)
 	assert.Equal(t, actualTxs[0].BlockCid, "bafy2bzacecnamqgqmifpluoeldx7zzglxcljo6oja4vrmtj7432rphldpdmm2")
 	assert.Equal(t, actualTxs[0].TipsetCid, "bafy2bzacea3l7hchfijz5fvswab36fxepf6oagecp5hrstmol7zpm2l4tedf6")
+
+	// every genesis actor is keyed by its genesis balance address, with its actor type and short address
+	assert.GreaterOrEqual(t, addresses.Len(), len(actualTxs))
+	addresses.Range(func(key string, info *types.AddressInfo) bool {
+		assert.NotEmpty(t, info.ActorType, "actor type of %s", key)
+		short, err := address.NewFromString(info.Short)
+		assert.NoError(t, err, "short address of %s", key)
+		assert.Equal(t, address.ID, short.Protocol(), "short address of %s", key)
+		return true
+	})
 }
 
 func TestParseGenesisMultisig(t *testing.T) {
@@ -1850,3 +2441,196 @@ func createTopic(t *testing.T, hash string) []byte {
 
 	return topicBytes
 }
+
+func TestFilecoinParser_ReportSkippedTraces(t *testing.T) {
+	var hookHeight uint64
+	var hookSkipped []types.SkippedTrace
+	p := &FilecoinParser{logger: zap.NewNop(), skippedTraceHook: func(height uint64, skipped []types.SkippedTrace) {
+		hookHeight = height
+		hookSkipped = skipped
+	}}
+
+	parsedResult := &types.TxsParsedResult{Report: types.ParseReport{
+		SkippedTraces: []types.SkippedTrace{{ExecutionIndex: 1, TxCid: "dup", Reason: types.SkipReasonDuplicated}},
+	}}
+	parsed := []types.SkippedTrace{{ExecutionIndex: 0, TxCid: "failed", Reason: types.SkipReasonFailedParent}}
+	p.reportSkippedTraces(parsedResult, parsed, nil)
+
+	expected := []types.SkippedTrace{parsed[0], {ExecutionIndex: 1, TxCid: "dup", Reason: types.SkipReasonDuplicated}}
+	require.Equal(t, expected, parsedResult.Report.SkippedTraces)
+	require.Equal(t, expected, hookSkipped)
+	require.Zero(t, hookHeight)
+
+	// the hook is not called for heights without skipped traces
+	hookSkipped = nil
+	p.reportSkippedTraces(&types.TxsParsedResult{}, nil, nil)
+	require.Nil(t, hookSkipped)
+}
+
+func TestFilecoinParser_FilterDuplicated(t *testing.T) {
+	p := &FilecoinParser{}
+	fee := &types.Transaction{Id: "fee", TxType: parser.TotalFeeOp}
+	send := &types.Transaction{Id: "send", TxType: parser.MethodSend, Level: 1}
+	txs := []*types.Transaction{
+		fee,
+		send,
+		{Id: "fee", TxType: parser.TotalFeeOp},
+		{Id: "fee", TxType: parser.TotalFeeOp},
+		{Id: "send", TxType: parser.MethodSend, Level: 1},
+		{Id: "other", TxType: parser.MethodSend},
+	}
+
+	filtered, report := p.filterDuplicated(txs)
+	require.Len(t, filtered, 3)
+	require.Equal(t, 3, report.DuplicatedTxs)
+	require.Equal(t, 2, report.DuplicatedFees)
+	require.Len(t, report.SkippedTraces, 3)
+	for _, skipped := range report.SkippedTraces {
+		require.Equal(t, types.SkipReasonDuplicated, skipped.Reason)
+	}
+	require.Equal(t, []string{types.DiagnosticDuplicatedFee}, fee.Diagnostics)
+	require.Equal(t, []string{types.DiagnosticDuplicatedTx}, send.Diagnostics)
+	require.Empty(t, filtered[2].Diagnostics)
+}
+
+func TestFilecoinParser_TracesParserVersion(t *testing.T) {
+	v1Traces := []byte(`{"Trace": [{"ExecutionTrace": {"Msg": {"To": "f01", "From": "f02", "Nonce": 1}, "MsgRct": {"ExitCode": 0}}}]}`)
+	v2Traces := []byte(`{"Trace": [{"ExecutionTrace": {"Msg": {"To": "f01", "From": "f02", "ParamsCodec": 0}, "MsgRct": {"ExitCode": 0}}}]}`)
+	v1Metadata := types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: "v1.22"}}
+	v2Metadata := types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: "v1.23"}}
+
+	tests := []struct {
+		name     string
+		handling string
+		metadata types.BlockMetadata
+		traces   []byte
+		want     string
+		wantErr  error
+	}{
+		{name: "matching v1", handling: parser.TraceVersionMismatchError, metadata: v1Metadata, traces: v1Traces, want: v1.Version},
+		{name: "matching v2", handling: parser.TraceVersionMismatchError, metadata: v2Metadata, traces: v2Traces, want: v2.Version},
+		{name: "auto-correct", handling: parser.TraceVersionMismatchAutoCorrect, metadata: v1Metadata, traces: v2Traces, want: v2.Version},
+		{name: "auto-correct by default", metadata: v2Metadata, traces: v1Traces, want: v1.Version},
+		{name: "error", handling: parser.TraceVersionMismatchError, metadata: v2Metadata, traces: v1Traces, wantErr: parser.ErrVersionMismatch},
+		{name: "ignore", handling: parser.TraceVersionMismatchIgnore, metadata: v1Metadata, traces: v2Traces, want: v1.Version},
+		{name: "unknown version", metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: "v0.1"}}, traces: v2Traces, wantErr: errUnknownVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &FilecoinParser{
+				parserV1: &v1.Parser{},
+				parserV2: &v2.Parser{},
+				Helper:   helper2.NewHelper(nil, nil, nil, nil, parser.FilecoinParserConfig{TraceVersionMismatch: tt.handling}),
+				logger:   zap.NewNop(),
+			}
+
+			got, err := p.tracesParserVersion(tt.metadata, tt.traces)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fixedStreamParser emits a fixed batch of txs per trace
+type fixedStreamParser struct {
+	Parser
+	batches [][]*types.Transaction
+}
+
+func (s fixedStreamParser) ParseTransactionsStream(_ context.Context, _ types.TxsData, fn func(txs []*types.Transaction) error) (*types.TxsParsedResult, error) {
+	for _, batch := range s.batches {
+		if err := fn(batch); err != nil {
+			return nil, err
+		}
+	}
+	return &types.TxsParsedResult{Addresses: types.NewAddressInfoMap()}, nil
+}
+
+func TestFilecoinParser_ParseTransactionsStream(t *testing.T) {
+	v2Traces := []byte(`{"Trace": [{"ExecutionTrace": {"Msg": {"To": "f01", "From": "f02", "ParamsCodec": 0}, "MsgRct": {"ExitCode": 0}}}]}`)
+	txsData := types.TxsData{Traces: v2Traces, Metadata: types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: "v1.23"}}}
+	newParser := func() *FilecoinParser {
+		return &FilecoinParser{
+			parserV1: &v1.Parser{},
+			// "a" is duplicated in its own trace and in the last one
+			parserV2: fixedStreamParser{batches: [][]*types.Transaction{
+				{{Id: "a"}, {Id: "b", ParentId: "a", Level: 1}, {Id: "a"}},
+				{{Id: "c"}, {Id: "d", ParentId: "c", Level: 1}, {Id: "e", ParentId: "d", Level: 2}},
+				{{Id: "a"}},
+			}},
+			Helper: helper2.NewHelper(nil, nil, nil, nil, parser.FilecoinParserConfig{StampBuildInfo: true,
+				ExperimentalFeatures: []string{string(parser.FeatureTxHierarchy)}}),
+			logger: zap.NewNop(),
+		}
+	}
+
+	var batches [][]string
+	roots := make(map[string]string)
+	result, err := newParser().ParseTransactionsStream(context.Background(), txsData, func(txs []*types.Transaction) error {
+		var ids []string
+		for _, tx := range txs {
+			require.Equal(t, parser.GetBuildInfo().String(), tx.ParserBuild)
+			require.Equal(t, tx.Level > 0, tx.IsInternal)
+			ids = append(ids, tx.Id)
+			roots[tx.Id] = tx.RootId
+		}
+		batches = append(batches, ids)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d", "e"}}, batches)
+	// the hierarchy is linked per trace
+	require.Equal(t, map[string]string{"a": "", "b": "a", "c": "", "d": "c", "e": "c"}, roots)
+	require.Nil(t, result.Txs)
+	require.Equal(t, parser.GetBuildInfo(), result.Report.Build)
+	require.Equal(t, 2, result.Report.DuplicatedTxs)
+	require.Len(t, result.Report.SkippedTraces, 2)
+	require.Equal(t, types.SkipReasonDuplicated, result.Report.SkippedTraces[1].Reason)
+
+	// parsing stops at the first error of the consumer
+	errStop := fmt.Errorf("stop")
+	batches = nil
+	_, err = newParser().ParseTransactionsStream(context.Background(), txsData, func(txs []*types.Transaction) error {
+		batches = append(batches, []string{txs[0].Id})
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, [][]string{{"a"}}, batches)
+
+	// the hierarchy is only linked with the tx_hierarchy feature
+	withoutHierarchy := newParser()
+	withoutHierarchy.Helper = helper2.NewHelper(nil, nil, nil, nil, parser.FilecoinParserConfig{})
+	_, err = withoutHierarchy.ParseTransactionsStream(context.Background(), txsData, func(txs []*types.Transaction) error {
+		for _, tx := range txs {
+			require.False(t, tx.IsInternal)
+			require.Empty(t, tx.RootId)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// TestParsers_OptionalInterfaces checks the optional interfaces implemented by each parser version
+func TestParsers_OptionalInterfaces(t *testing.T) {
+	var v1Parser Parser = &v1.Parser{}
+	var v2Parser Parser = &v2.Parser{}
+
+	_, ok := v1Parser.(MessagesParser)
+	assert.False(t, ok)
+	_, ok = v2Parser.(MessagesParser)
+	assert.True(t, ok)
+
+	_, ok = v1Parser.(FeesParser)
+	assert.True(t, ok)
+	_, ok = v2Parser.(FeesParser)
+	assert.True(t, ok)
+
+	_, ok = v1Parser.(EthTracesParser)
+	assert.False(t, ok)
+	_, ok = v2Parser.(EthTracesParser)
+	assert.True(t, ok)
+}
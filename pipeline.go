@@ -0,0 +1,386 @@
+package fil_parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zondax/fil-parser/parser"
+	v1 "github.com/zondax/fil-parser/parser/v1"
+	v2 "github.com/zondax/fil-parser/parser/v2"
+	"github.com/zondax/fil-parser/types"
+)
+
+// Steps of the default pipeline of ParseTransactions, in order
+const (
+	// StepDecodeTraces decodes the traces with the parser of their version
+	StepDecodeTraces = "decode_traces"
+	// StepBuildTxTree builds the txs from the decoded traces. It sets the result of the pipeline, so it must run
+	// before the rest of the steps.
+	StepBuildTxTree = "build_tx_tree"
+	// StepDecodeParams decodes the params and return values of the txs. The address lookups it makes are bounded by
+	// AddressLookupTimeout.
+	StepDecodeParams = "decode_params"
+	// StepRenderMetadata encodes the decoded params into the TxMetadata of the txs, so it must run before the steps
+	// reading the metadata
+	StepRenderMetadata          = "render_metadata"
+	StepFilterDuplicated        = "filter_duplicated"
+	StepLinkTxHierarchy         = "link_tx_hierarchy"
+	StepReconcileEthLogs        = "reconcile_eth_logs"
+	StepReconcileEthReceipts    = "reconcile_eth_receipts"
+	StepInputHashes             = "input_hashes"
+	StepDetectAnomalies         = "detect_anomalies"
+	StepCheckInvariants         = "check_invariants"
+	StepConsolidateAddresses    = "consolidate_addresses"
+	StepInvalidateDeletedActors = "invalidate_deleted_actors"
+	StepEscrowChanges           = "escrow_changes"
+	StepAccountPromotions       = "account_promotions"
+	StepBlockInclusions         = "block_inclusions"
+	StepTagAddresses            = "tag_addresses"
+	StepVerifiedContracts       = "verified_contracts"
+	// StepFormatOutput compresses the metadata and formats the amounts as set in the config
+	StepFormatOutput = "format_output"
+	StepBuildInfo    = "build_info"
+)
+
+var (
+	ErrUnknownStep   = errors.New("unknown pipeline step")
+	ErrDuplicateStep = errors.New("duplicate pipeline step")
+	ErrNoParseResult = errors.New("no parse result, the traces were not decoded")
+	// ErrNoDecodedTraces is returned by StepBuildTxTree when StepDecodeTraces did not run before it
+	ErrNoDecodedTraces = errors.New("the traces were not decoded")
+	// ErrStageTimeout is returned when a step runs out of its time budget, see DecodeTimeout and AddressValidationTimeout
+	ErrStageTimeout = errors.New("pipeline step timed out")
+)
+
+// PipelineState is the state shared by the steps of a pipeline
+type PipelineState struct {
+	TxsData types.TxsData
+	// ParserVersion is the version of the parser matching the traces
+	ParserVersion string
+	// Result is set by StepBuildTxTree and refined by the following steps
+	Result *types.TxsParsedResult
+
+	// set by StepDecodeTraces
+	traces           parser.DecodedTraces
+	unknownAddresses *parser.UnknownAddresses
+	// the DecodeTimeout budget is shared by StepDecodeTraces, StepBuildTxTree and StepDecodeParams
+	decodeDeadline time.Time
+	// set by StepBuildTxTree and emptied by StepRenderMetadata
+	pending *parser.PendingMetadata
+	// inputHashes are set by ParseTransactions when it already hashed the inputs for the results cache key, so
+	// StepInputHashes does not hash them again
+	inputHashes *types.InputHashes
+}
+
+// InputHashes returns the hashes of the inputs of the state, hashing them on the first call
+func (s *PipelineState) InputHashes() types.InputHashes {
+	if s.inputHashes == nil {
+		hashes := types.HashTxsData(s.TxsData)
+		s.inputHashes = &hashes
+	}
+	return *s.inputHashes
+}
+
+// stagedParser is implemented by the parsers whose ParseTransactions can be run step by step
+type stagedParser interface {
+	DecodeTraces(txsData types.TxsData) (parser.DecodedTraces, error)
+	BuildTxTree(ctx context.Context, txsData types.TxsData, traces parser.DecodedTraces) (*types.TxsParsedResult, *parser.PendingMetadata, error)
+}
+
+// StepFunc runs a step of the pipeline over its state
+type StepFunc func(ctx context.Context, state *PipelineState) error
+
+// Step is a named stage of a Pipeline
+type Step struct {
+	Name string
+	Run  StepFunc
+}
+
+// Pipeline is the ordered list of steps run by ParseTransactionsWithPipeline. Start from DefaultPipeline to
+// reorder, skip or replace the stages of ParseTransactions without forking the parser.
+type Pipeline struct {
+	steps []Step
+}
+
+// NewPipeline creates a pipeline running the steps in the given order
+func NewPipeline(steps ...Step) (*Pipeline, error) {
+	names := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		if names[step.Name] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateStep, step.Name)
+		}
+		names[step.Name] = true
+	}
+	return &Pipeline{steps: append([]Step(nil), steps...)}, nil
+}
+
+// Steps returns a copy of the steps of the pipeline, in order
+func (p *Pipeline) Steps() []Step {
+	return append([]Step(nil), p.steps...)
+}
+
+// Skip removes the step from the pipeline
+func (p *Pipeline) Skip(name string) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	p.steps = append(p.steps[:i], p.steps[i+1:]...)
+	return nil
+}
+
+// Replace runs fn instead of the step, keeping its position
+func (p *Pipeline) Replace(name string, fn StepFunc) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	p.steps[i].Run = fn
+	return nil
+}
+
+// InsertBefore adds the step right before the given one
+func (p *Pipeline) InsertBefore(name string, step Step) error {
+	return p.insert(name, step, 0)
+}
+
+// InsertAfter adds the step right after the given one
+func (p *Pipeline) InsertAfter(name string, step Step) error {
+	return p.insert(name, step, 1)
+}
+
+func (p *Pipeline) insert(name string, step Step, offset int) error {
+	if _, err := p.index(step.Name); err == nil {
+		return fmt.Errorf("%w: %s", ErrDuplicateStep, step.Name)
+	}
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	i += offset
+	p.steps = append(p.steps[:i], append([]Step{step}, p.steps[i:]...)...)
+	return nil
+}
+
+func (p *Pipeline) index(name string) (int, error) {
+	for i, step := range p.steps {
+		if step.Name == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownStep, name)
+}
+
+// DefaultPipeline returns the steps run by ParseTransactions. Every call returns a new pipeline.
+func (p *FilecoinParser) DefaultPipeline() *Pipeline {
+	return &Pipeline{steps: []Step{
+		{Name: StepDecodeTraces, Run: p.decodeTracesStep},
+		{Name: StepBuildTxTree, Run: p.buildTxTreeStep},
+		{Name: StepDecodeParams, Run: p.decodeParamsStep},
+		{Name: StepRenderMetadata, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.renderMetadata(state)
+		})},
+		{Name: StepFilterDuplicated, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			skippedTraces, timedOutSteps := state.Result.Report.SkippedTraces, state.Result.Report.TimedOutSteps
+			state.Result.Txs, state.Result.Report = p.filterDuplicated(state.Result.Txs)
+			state.Result.Report.TimedOutSteps = timedOutSteps
+			p.reportSkippedTraces(state.Result, skippedTraces, state.TxsData.Tipset)
+		})},
+		{Name: StepLinkTxHierarchy, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.linkTxHierarchy(state.Result.Txs)
+		})},
+		{Name: StepReconcileEthLogs, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.reconcileEthLogs(state.Result, state.TxsData.EthLogs, state.TxsData.Tipset)
+		})},
+		{Name: StepReconcileEthReceipts, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.reconcileEthReceipts(state.Result, state.TxsData.EthReceipts, state.TxsData.Tipset)
+		})},
+		{Name: StepInputHashes, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.setInputHashes(state.Result, state.InputHashes())
+		})},
+		{Name: StepDetectAnomalies, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.detectAnomalies(state.Result, state.TxsData.Tipset)
+		})},
+		{Name: StepCheckInvariants, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.checkInvariants(state.Result, state.TxsData.Tipset)
+		})},
+		{Name: StepConsolidateAddresses, Run: func(ctx context.Context, state *PipelineState) error {
+			if state.Result == nil {
+				return ErrNoParseResult
+			}
+			return p.validateAddresses(ctx, state.Result, state.TxsData.Tipset)
+		}},
+		{Name: StepInvalidateDeletedActors, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.invalidateDeletedActors(state.Result.Txs)
+		})},
+		{Name: StepEscrowChanges, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.setEscrowChanges(state.Result)
+		})},
+		{Name: StepAccountPromotions, Run: resultStep(func(ctx context.Context, state *PipelineState) {
+			p.setAccountPromotions(ctx, state.Result, state.TxsData.Tipset)
+		})},
+		{Name: StepBlockInclusions, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.setBlockInclusions(state.Result, state.TxsData.Tipset)
+		})},
+		{Name: StepTagAddresses, Run: resultStep(func(ctx context.Context, state *PipelineState) {
+			p.tagAddresses(ctx, state.Result)
+		})},
+		{Name: StepVerifiedContracts, Run: resultStep(func(ctx context.Context, state *PipelineState) {
+			p.setVerifiedContracts(ctx, state.Result)
+		})},
+		{Name: StepFormatOutput, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.compressMetadata(state.Result.Txs)
+			parser.FormatAmounts(state.Result.Txs, p.Helper.GetConfig().AmountFormat)
+		})},
+		{Name: StepBuildInfo, Run: resultStep(func(_ context.Context, state *PipelineState) {
+			p.setBuildInfo(state.Result)
+		})},
+	}}
+}
+
+// ParseTransactionsWithPipeline parses the transactions like ParseTransactions, running the steps of the given
+// pipeline instead of the default ones
+func (p *FilecoinParser) ParseTransactionsWithPipeline(ctx context.Context, pipeline *Pipeline, txsData types.TxsData) (*types.TxsParsedResult, error) {
+	return p.runPipeline(ctx, pipeline, &PipelineState{TxsData: txsData})
+}
+
+// runPipeline runs the steps of the pipeline over the state, whose TxsData must be set
+func (p *FilecoinParser) runPipeline(ctx context.Context, pipeline *Pipeline, state *PipelineState) (*types.TxsParsedResult, error) {
+	txsData := state.TxsData
+	parserVersion, err := p.tracesParserVersion(txsData.Metadata, txsData.Traces)
+	if err != nil {
+		return nil, err
+	}
+	p.setHeadEpoch(txsData.Tipset)
+
+	ctx, span := p.startSpan(ctx, parser.SpanParseTransactions, txsData.Tipset, parserVersion)
+	defer span.End()
+
+	state.ParserVersion = parserVersion
+	for _, step := range pipeline.steps {
+		if err = step.Run(ctx, state); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	if state.Result == nil {
+		return nil, ErrNoParseResult
+	}
+	return state.Result, nil
+}
+
+// decodeTracesStep decodes the traces with the parser of their version, within the DecodeTimeout budget
+func (p *FilecoinParser) decodeTracesStep(ctx context.Context, state *PipelineState) error {
+	p.logger.Sugar().Debugf("trace files node version: [%s] - parser to use: [%s]", state.TxsData.Metadata.NodeMajorMinorVersion, state.ParserVersion)
+
+	impl, err := p.stagedParser(state.ParserVersion)
+	if err != nil {
+		return err
+	}
+	if timeout := p.Helper.GetConfig().DecodeTimeout; timeout > 0 {
+		state.decodeDeadline = time.Now().Add(timeout)
+	}
+
+	// addresses of protocols newer than the parser are replaced, so the traces can still be decoded
+	txsData := state.TxsData
+	txsData.Traces, state.unknownAddresses = parser.SanitizeUnknownAddresses(txsData.Traces)
+	state.traces, err = impl.DecodeTraces(txsData)
+	return err
+}
+
+// buildTxTreeStep builds the txs from the decoded traces, queuing the decoding of their params
+func (p *FilecoinParser) buildTxTreeStep(ctx context.Context, state *PipelineState) error {
+	if state.traces == nil {
+		return ErrNoDecodedTraces
+	}
+	impl, err := p.stagedParser(state.ParserVersion)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := p.decodeContext(ctx, state)
+	defer cancel()
+	state.Result, state.pending, err = impl.BuildTxTree(ctx, state.TxsData, state.traces)
+	return p.decodeTimeoutError(ctx, StepBuildTxTree, err)
+}
+
+// decodeParamsStep decodes the params and return values of the txs built by StepBuildTxTree
+func (p *FilecoinParser) decodeParamsStep(ctx context.Context, state *PipelineState) error {
+	if state.Result == nil {
+		return ErrNoParseResult
+	}
+
+	ctx, cancel := p.decodeContext(ctx, state)
+	defer cancel()
+	if err := state.pending.Decode(ctx); err != nil {
+		return p.decodeTimeoutError(ctx, StepDecodeParams, err)
+	}
+
+	// Clear this cache when we finish processing a tipset.
+	// Bad addresses in this tipset might be valid in the next one
+	if actorsCache := p.Helper.GetActorsCache(); actorsCache != nil {
+		actorsCache.ClearBadAddressCache()
+	}
+	return p.checkAddressLookups(state.Result, StepDecodeParams)
+}
+
+// renderMetadata encodes the decoded params into the TxMetadata of the txs and marks the txs with addresses of
+// unknown protocols, which needs the rendered metadata
+func (p *FilecoinParser) renderMetadata(state *PipelineState) {
+	state.pending.Render()
+	if state.unknownAddresses.Len() == 0 {
+		return
+	}
+
+	p.logger.Sugar().Warnf("[parser] - %d addresses of unknown protocols found in the traces", state.unknownAddresses.Len())
+	if err := state.unknownAddresses.Restore(state.Result.Txs, state.Result.Addresses); err != nil {
+		p.logger.Sugar().Errorf("[parser] - could not mark the txs with unknown addresses: %v", err)
+	}
+}
+
+// stagedParser returns the parser of the version
+func (p *FilecoinParser) stagedParser(parserVersion string) (stagedParser, error) {
+	var impl Parser
+	switch parserVersion {
+	case v1.Version:
+		impl = p.parserV1
+	case v2.Version:
+		impl = p.parserV2
+	}
+	staged, ok := impl.(stagedParser)
+	if !ok {
+		p.logger.Sugar().Errorf("[parser] implementation not supported: %s", parserVersion)
+		return nil, errUnknownImpl
+	}
+	return staged, nil
+}
+
+// decodeContext bounds the context with what is left of the DecodeTimeout budget
+func (p *FilecoinParser) decodeContext(ctx context.Context, state *PipelineState) (context.Context, context.CancelFunc) {
+	if state.decodeDeadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, state.decodeDeadline)
+}
+
+// decodeTimeoutError wraps the error of a step with ErrStageTimeout when the DecodeTimeout budget ran out
+func (p *FilecoinParser) decodeTimeoutError(ctx context.Context, step string, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s exceeded %s: %w", ErrStageTimeout, step, p.Helper.GetConfig().DecodeTimeout, err)
+	}
+	return err
+}
+
+// resultStep wraps a step that refines the parse result, failing if the traces were not decoded yet
+func resultStep(fn func(ctx context.Context, state *PipelineState)) StepFunc {
+	return func(ctx context.Context, state *PipelineState) error {
+		if state.Result == nil {
+			return ErrNoParseResult
+		}
+		fn(ctx, state)
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+package fil_parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	helper2 "github.com/zondax/fil-parser/parser/helper"
+	v1 "github.com/zondax/fil-parser/parser/v1"
+	v2 "github.com/zondax/fil-parser/parser/v2"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+func stepNames(pipeline *Pipeline) []string {
+	var names []string
+	for _, step := range pipeline.Steps() {
+		names = append(names, step.Name)
+	}
+	return names
+}
+
+func TestPipeline(t *testing.T) {
+	noop := func(context.Context, *PipelineState) error { return nil }
+	pipeline, err := NewPipeline(Step{Name: "a", Run: noop}, Step{Name: "b", Run: noop})
+	require.NoError(t, err)
+
+	require.NoError(t, pipeline.InsertBefore("a", Step{Name: "first", Run: noop}))
+	require.NoError(t, pipeline.InsertAfter("b", Step{Name: "last", Run: noop}))
+	require.NoError(t, pipeline.Skip("a"))
+	require.Equal(t, []string{"first", "b", "last"}, stepNames(pipeline))
+
+	require.ErrorIs(t, pipeline.Skip("a"), ErrUnknownStep)
+	require.ErrorIs(t, pipeline.Replace("a", noop), ErrUnknownStep)
+	require.ErrorIs(t, pipeline.InsertAfter("b", Step{Name: "last", Run: noop}), ErrDuplicateStep)
+
+	_, err = NewPipeline(Step{Name: "a", Run: noop}, Step{Name: "a", Run: noop})
+	require.ErrorIs(t, err, ErrDuplicateStep)
+}
+
+func TestFilecoinParser_ParseTransactionsWithPipeline(t *testing.T) {
+	logger := zap.NewNop()
+	helper := helper2.NewHelper(nil, nil, nil, logger, parser.DefaultConfig())
+	p := &FilecoinParser{parserV1: v1.NewParser(helper, logger), parserV2: v2.NewParser(helper, logger), Helper: helper, logger: logger}
+
+	pipeline := p.DefaultPipeline()
+	require.Equal(t, []string{StepDecodeTraces, StepBuildTxTree, StepDecodeParams, StepRenderMetadata}, stepNames(pipeline)[:4])
+
+	// building the tx tree needs the decoded traces
+	_, err := p.ParseTransactionsWithPipeline(context.Background(), p.DefaultPipeline(), types.TxsData{})
+	require.Error(t, err)
+	require.NoError(t, pipeline.Skip(StepDecodeTraces))
+	_, err = p.ParseTransactionsWithPipeline(context.Background(), pipeline, types.TxsData{})
+	require.ErrorIs(t, err, ErrNoDecodedTraces)
+
+	// the tx tree is replaced by a fixed result, and the metadata is left as rendered
+	require.NoError(t, pipeline.Replace(StepBuildTxTree, func(_ context.Context, state *PipelineState) error {
+		state.Result = &types.TxsParsedResult{Txs: []*types.Transaction{
+			{Id: "a", TxMetadata: `{"Params":"x"}`},
+			{Id: "a"},
+		}}
+		return nil
+	}))
+	require.NoError(t, pipeline.Skip(StepFormatOutput))
+
+	result, err := p.ParseTransactionsWithPipeline(context.Background(), pipeline, types.TxsData{})
+	require.NoError(t, err)
+	require.Len(t, result.Txs, 1, "duplicated txs are filtered")
+	require.Equal(t, `{"Params":"x"}`, result.Txs[0].TxMetadata)
+	require.Equal(t, parser.GetBuildInfo(), result.Report.Build)
+
+	// steps refining the result fail if the tx tree is not built first
+	require.NoError(t, pipeline.Skip(StepBuildTxTree))
+	_, err = p.ParseTransactionsWithPipeline(context.Background(), pipeline, types.TxsData{})
+	require.ErrorIs(t, err, ErrNoParseResult)
+}
+
+func TestFilecoinParser_validateAddresses_timeout(t *testing.T) {
+	logger := zap.NewNop()
+	config := parser.DefaultConfig()
+	config.ValidateAddresses = true
+	config.AddressValidationTimeout = time.Second
+	newResult := func() *types.TxsParsedResult {
+		addresses := types.NewAddressInfoMap()
+		addresses.Set("f01235", &types.AddressInfo{Short: "f01235", Robust: "f01236"})
+		return &types.TxsParsedResult{Addresses: addresses}
+	}
+	// the budget is already exhausted when the step starts
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	p := &FilecoinParser{Helper: helper2.NewHelper(nil, nil, nil, logger, config), logger: logger}
+	require.ErrorIs(t, p.validateAddresses(ctx, newResult(), nil), ErrStageTimeout)
+
+	// in best effort mode the entries not validated yet are kept
+	config.StageTimeoutHandling = parser.StageTimeoutBestEffort
+	p = &FilecoinParser{Helper: helper2.NewHelper(nil, nil, nil, logger, config), logger: logger}
+	result := newResult()
+	require.NoError(t, p.validateAddresses(ctx, result, nil))
+	require.Equal(t, []string{StepConsolidateAddresses}, result.Report.TimedOutSteps)
+	_, ok := result.Addresses.Get("f01235")
+	require.True(t, ok)
+
+	// without a budget the inconsistent entry is reported and kept
+	result = newResult()
+	require.NoError(t, p.validateAddresses(context.Background(), result, nil))
+	require.Empty(t, result.Report.TimedOutSteps)
+	require.Len(t, result.Report.AddressViolations, 1)
+	_, ok = result.Addresses.Get("f01235")
+	require.True(t, ok)
+}
+
+func TestFilecoinParser_checkAddressLookups(t *testing.T) {
+	logger := zap.NewNop()
+	config := parser.DefaultConfig()
+	config.AddressLookupTimeout = time.Nanosecond
+	helper := helper2.NewHelper(nil, nil, nil, logger, config)
+	p := &FilecoinParser{Helper: helper, logger: logger}
+
+	result := &types.TxsParsedResult{}
+	require.NoError(t, p.checkAddressLookups(result, StepDecodeParams), "the budget is started per height")
+
+	helper.StartAddressLookups()
+	time.Sleep(time.Millisecond)
+	require.ErrorIs(t, p.checkAddressLookups(result, StepDecodeParams), ErrStageTimeout)
+
+	// in best effort mode the step is reported instead
+	config.StageTimeoutHandling = parser.StageTimeoutBestEffort
+	helper = helper2.NewHelper(nil, nil, nil, logger, config)
+	p = &FilecoinParser{Helper: helper, logger: logger}
+	helper.StartAddressLookups()
+	time.Sleep(time.Millisecond)
+	require.NoError(t, p.checkAddressLookups(result, StepDecodeParams))
+	require.Equal(t, []string{StepDecodeParams}, result.Report.TimedOutSteps)
+}
@@ -0,0 +1,103 @@
+package fil_parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zondax/fil-parser/actors/cache"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	logger2 "github.com/zondax/fil-parser/logger"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTenantNotFound      = errors.New("tenant not found")
+	ErrTenantAlreadyExists = errors.New("tenant already exists")
+)
+
+// ParserPool holds one FilecoinParser per tenant. All the parsers share the same ActorsCache,
+// while each one keeps its own config and parsing state.
+type ParserPool struct {
+	lib         *helper.FilecoinLib
+	actorsCache *cache.ActorsCache
+	node        types.FullNode
+	logger      *zap.Logger
+
+	mu      sync.RWMutex
+	parsers map[string]*FilecoinParser
+}
+
+func NewParserPool(lib *helper.FilecoinLib, cacheSource common.DataSource, logger *zap.Logger) (*ParserPool, error) {
+	logger = logger2.GetSafeLogger(logger)
+	actorsCache, err := cache.SetupActorsCache(cacheSource, logger)
+	if err != nil {
+		logger.Sugar().Errorf("could not setup actors cache: %v", err)
+		return nil, err
+	}
+
+	return &ParserPool{
+		lib:         lib,
+		actorsCache: actorsCache,
+		node:        cacheSource.Node,
+		logger:      logger,
+		parsers:     make(map[string]*FilecoinParser),
+	}, nil
+}
+
+// AddTenant creates a new parser for the tenant using the shared actors cache
+func (pp *ParserPool) AddTenant(tenantID string, opts ...Option) (*FilecoinParser, error) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	if _, ok := pp.parsers[tenantID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrTenantAlreadyExists, tenantID)
+	}
+
+	p, err := newFilecoinParser(pp.lib, pp.actorsCache, pp.node, pp.logger.With(zap.String("tenant", tenantID)), newOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+	pp.parsers[tenantID] = p
+	return p, nil
+}
+
+// GetParser returns the parser of the tenant
+func (pp *ParserPool) GetParser(tenantID string) (*FilecoinParser, error) {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+
+	p, ok := pp.parsers[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTenantNotFound, tenantID)
+	}
+	return p, nil
+}
+
+func (pp *ParserPool) RemoveTenant(tenantID string) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	delete(pp.parsers, tenantID)
+}
+
+// Tenants returns the sorted list of registered tenants
+func (pp *ParserPool) Tenants() []string {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+
+	tenants := make([]string, 0, len(pp.parsers))
+	for tenantID := range pp.parsers {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// GetActorsCache returns the actors cache shared by all the tenants
+func (pp *ParserPool) GetActorsCache() *cache.ActorsCache {
+	return pp.actorsCache
+}
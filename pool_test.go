@@ -0,0 +1,40 @@
+package fil_parser
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/lotus/api/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/parser"
+)
+
+func TestParserPool(t *testing.T) {
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	pool, err := NewParserPool(nil, common.DataSource{Node: node}, nil)
+	require.NoError(t, err)
+
+	tenantA, err := pool.AddTenant("a", WithConfig(parser.FilecoinParserConfig{EnrichSectorInfo: true}))
+	require.NoError(t, err)
+	tenantB, err := pool.AddTenant("b")
+	require.NoError(t, err)
+
+	_, err = pool.AddTenant("a")
+	require.ErrorIs(t, err, ErrTenantAlreadyExists)
+
+	// config is isolated per tenant, the actors cache is shared
+	require.True(t, tenantA.Helper.GetConfig().EnrichSectorInfo)
+	require.False(t, tenantB.Helper.GetConfig().EnrichSectorInfo)
+	require.Same(t, tenantA.Helper.GetActorsCache(), tenantB.Helper.GetActorsCache())
+	require.Same(t, pool.GetActorsCache(), tenantA.Helper.GetActorsCache())
+
+	got, err := pool.GetParser("a")
+	require.NoError(t, err)
+	require.Same(t, tenantA, got)
+	require.Equal(t, []string{"a", "b"}, pool.Tenants())
+
+	pool.RemoveTenant("a")
+	_, err = pool.GetParser("a")
+	require.ErrorIs(t, err, ErrTenantNotFound)
+}
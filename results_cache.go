@@ -0,0 +1,71 @@
+package fil_parser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+)
+
+// ResultsCache stores the results of ParseTransactions keyed by the hash of their inputs, so pipelines that
+// re-deliver the same height get the stored output instead of parsing it again. Cached results are shared
+// between the callers, so they must not be modified. Implementations must be safe for concurrent use.
+type ResultsCache interface {
+	Get(ctx context.Context, key string) (*types.TxsParsedResult, bool)
+	Set(ctx context.Context, key string, result *types.TxsParsedResult)
+}
+
+// InMemoryResultsCache is a ResultsCache keeping the most recently used results in memory
+type InMemoryResultsCache struct {
+	results *lru.Cache[string, *types.TxsParsedResult]
+}
+
+// NewInMemoryResultsCache creates a cache holding up to size results
+func NewInMemoryResultsCache(size int) (*InMemoryResultsCache, error) {
+	results, err := lru.New[string, *types.TxsParsedResult](size)
+	if err != nil {
+		return nil, err
+	}
+	return &InMemoryResultsCache{results: results}, nil
+}
+
+func (c *InMemoryResultsCache) Get(_ context.Context, key string) (*types.TxsParsedResult, bool) {
+	return c.results.Get(key)
+}
+
+func (c *InMemoryResultsCache) Set(_ context.Context, key string, result *types.TxsParsedResult) {
+	c.results.Add(key, result)
+}
+
+// resultsCacheKey hashes everything the output of ParseTransactions depends on: the hashes of the inputs of the
+// tipset, the node metadata, the parser version and build, and the config
+func (p *FilecoinParser) resultsCacheKey(hashes types.InputHashes, txsData types.TxsData, parserVersion string) (string, error) {
+	config, err := json.Marshal(p.Helper.GetConfig())
+	if err != nil {
+		return "", fmt.Errorf("could not encode config: %w", err)
+	}
+	metadata, err := json.Marshal(txsData.Metadata)
+	if err != nil {
+		return "", fmt.Errorf("could not encode metadata: %w", err)
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{
+		[]byte(hashes.Combined()),
+		metadata,
+		[]byte(txsData.ReceiptsRoot.String()),
+		[]byte(parserVersion),
+		[]byte(parser.GetBuildInfo().Version),
+		config,
+	} {
+		// length prefixed, so the parts can not be shifted into each other
+		_, _ = fmt.Fprintf(hash, "%d:", len(part))
+		_, _ = hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,72 @@
+package fil_parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache"
+	"github.com/zondax/fil-parser/parser"
+	v2 "github.com/zondax/fil-parser/parser/v2"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestInMemoryResultsCache(t *testing.T) {
+	resultsCache, err := NewInMemoryResultsCache(1)
+	require.NoError(t, err)
+
+	first, second := &types.TxsParsedResult{}, &types.TxsParsedResult{}
+	resultsCache.Set(context.Background(), "first", first)
+	got, ok := resultsCache.Get(context.Background(), "first")
+	require.True(t, ok)
+	require.Same(t, first, got)
+
+	// the least recently used result is evicted
+	resultsCache.Set(context.Background(), "second", second)
+	_, ok = resultsCache.Get(context.Background(), "first")
+	require.False(t, ok)
+
+	_, err = NewInMemoryResultsCache(0)
+	require.Error(t, err)
+}
+
+func TestFilecoinParser_resultsCacheKey(t *testing.T) {
+	p, err := NewFilecoinParserWithActorsCache(nil, cache.NewOfflineActorsCache(nil, nil), nil, nil)
+	require.NoError(t, err)
+	txsData := types.TxsData{Traces: []byte(`{"Trace": []}`)}
+	hashes := types.HashTxsData(txsData)
+
+	key, err := p.resultsCacheKey(hashes, txsData, v2.Version)
+	require.NoError(t, err)
+	same, err := p.resultsCacheKey(hashes, txsData, v2.Version)
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	// any change of the inputs, parser version or config changes the key
+	otherTxsData := types.TxsData{Traces: []byte(`{"Trace": null}`)}
+	other, err := p.resultsCacheKey(types.HashTxsData(otherTxsData), otherTxsData, v2.Version)
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	other, err = p.resultsCacheKey(hashes, txsData, "v1")
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	config := parser.DefaultConfig()
+	config.LinkReceipts = true
+	withConfig, err := NewFilecoinParserWithActorsCache(nil, cache.NewOfflineActorsCache(nil, nil), nil, nil, WithConfig(config))
+	require.NoError(t, err)
+	other, err = withConfig.resultsCacheKey(hashes, txsData, v2.Version)
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
+
+func TestPipelineState_InputHashes(t *testing.T) {
+	state := &PipelineState{TxsData: types.TxsData{Traces: []byte(`{"Trace": []}`)}}
+	hashes := state.InputHashes()
+	require.Equal(t, types.HashTxsData(state.TxsData), hashes)
+
+	// the inputs are only hashed once
+	state.TxsData.Traces = []byte(`{"Trace": null}`)
+	require.Equal(t, hashes, state.InputHashes())
+}
@@ -0,0 +1,32 @@
+package fil_parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestFilecoinParser_tagAddresses(t *testing.T) {
+	p := &FilecoinParser{tagger: parser.NewStaticAddressTagger(map[string][]string{
+		"f1exchange": {parser.AddressTagExchange},
+		"f099":       {parser.AddressTagBurn},
+	})}
+
+	addresses := types.NewAddressInfoMap()
+	addresses.Set("f01000", &types.AddressInfo{Short: "f01000", Robust: "f1exchange"})
+
+	txs := []*types.Transaction{
+		{TxFrom: "f01000", TxTo: "f099"},
+		{TxFrom: "f01001", TxTo: "f01002"},
+	}
+	p.tagAddresses(context.Background(), &types.TxsParsedResult{Txs: txs, Addresses: addresses})
+
+	// the short address is tagged through its robust address
+	require.Equal(t, []string{parser.AddressTagExchange}, txs[0].TxFromTags)
+	require.Equal(t, []string{parser.AddressTagBurn}, txs[0].TxToTags)
+	require.Empty(t, txs[1].TxFromTags)
+	require.Empty(t, txs[1].TxToTags)
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"cmp"
+	"math/big"
+	"slices"
+
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+)
+
+// AddressStats are the aggregated txs of an address over a range
+type AddressStats struct {
+	Address string   `json:"address"`
+	TxCount uint64   `json:"tx_count"`
+	GasUsed uint64   `json:"gas_used"`
+	Value   *big.Int `json:"value"`
+}
+
+// Ranking are the top addresses by each metric
+type Ranking struct {
+	ByTxCount []AddressStats `json:"by_tx_count"`
+	ByGasUsed []AddressStats `json:"by_gas_used"`
+	ByValue   []AddressStats `json:"by_value"`
+}
+
+// HeavyHittersReport are the top addresses of a range of heights
+type HeavyHittersReport struct {
+	FromHeight uint64 `json:"from_height"`
+	ToHeight   uint64 `json:"to_height"`
+	// Senders are ranked by the txs they sent
+	Senders Ranking `json:"senders"`
+	// Receivers are ranked by the txs they received
+	Receivers Ranking `json:"receivers"`
+	// Contracts are ranked by the fevm txs they received
+	Contracts Ranking `json:"contracts"`
+}
+
+// HeavyHitters aggregates the parsed txs of a range, one height at a time, to rank its top addresses.
+// Fee txs are not counted. The value is only added for successful txs, as failed txs move no funds;
+// it is read from the Amount of the txs, so the attoFIL amount format must be kept.
+type HeavyHitters struct {
+	senders    map[string]*AddressStats
+	receivers  map[string]*AddressStats
+	contracts  map[string]*AddressStats
+	fromHeight uint64
+	toHeight   uint64
+	empty      bool
+}
+
+func NewHeavyHitters() *HeavyHitters {
+	return &HeavyHitters{
+		senders:   make(map[string]*AddressStats),
+		receivers: make(map[string]*AddressStats),
+		contracts: make(map[string]*AddressStats),
+		empty:     true,
+	}
+}
+
+// Add aggregates the txs of a parsed height
+func (h *HeavyHitters) Add(txs []*types.Transaction) {
+	okStatus := parser.GetExitCodeStatus(0)
+	for _, tx := range txs {
+		if tx == nil || parser.IsFee(tx.TxType) {
+			continue
+		}
+		h.trackHeight(tx.Height)
+
+		value := big.NewInt(0)
+		if tx.Status == okStatus && tx.Amount != nil {
+			value = tx.Amount
+		}
+
+		addStats(h.senders, tx.TxFrom, tx.GasUsed, value)
+		addStats(h.receivers, tx.TxTo, tx.GasUsed, value)
+		if parser.IsFevm(tx.TxType) {
+			addStats(h.contracts, tx.TxTo, tx.GasUsed, value)
+		}
+	}
+}
+
+func (h *HeavyHitters) trackHeight(height uint64) {
+	if h.empty {
+		h.fromHeight, h.toHeight, h.empty = height, height, false
+		return
+	}
+	h.fromHeight = min(h.fromHeight, height)
+	h.toHeight = max(h.toHeight, height)
+}
+
+func addStats(stats map[string]*AddressStats, address string, gasUsed uint64, value *big.Int) {
+	if address == "" {
+		return
+	}
+
+	entry, ok := stats[address]
+	if !ok {
+		entry = &AddressStats{Address: address, Value: big.NewInt(0)}
+		stats[address] = entry
+	}
+	entry.TxCount++
+	entry.GasUsed += gasUsed
+	entry.Value.Add(entry.Value, value)
+}
+
+// Top returns the n top addresses of the aggregated range by each metric, or all of them if n is negative.
+// Ties are sorted by address, so the report is stable for the same input.
+func (h *HeavyHitters) Top(n int) HeavyHittersReport {
+	return HeavyHittersReport{
+		FromHeight: h.fromHeight,
+		ToHeight:   h.toHeight,
+		Senders:    rank(h.senders, n),
+		Receivers:  rank(h.receivers, n),
+		Contracts:  rank(h.contracts, n),
+	}
+}
+
+func rank(stats map[string]*AddressStats, n int) Ranking {
+	all := make([]AddressStats, 0, len(stats))
+	for _, entry := range stats {
+		all = append(all, AddressStats{Address: entry.Address, TxCount: entry.TxCount, GasUsed: entry.GasUsed, Value: new(big.Int).Set(entry.Value)})
+	}
+
+	return Ranking{
+		ByTxCount: top(all, n, func(a, b AddressStats) int { return cmp.Compare(a.TxCount, b.TxCount) }),
+		ByGasUsed: top(all, n, func(a, b AddressStats) int { return cmp.Compare(a.GasUsed, b.GasUsed) }),
+		ByValue:   top(all, n, func(a, b AddressStats) int { return a.Value.Cmp(b.Value) }),
+	}
+}
+
+// top sorts a copy of the stats by the metric, descending, and keeps the first n
+func top(all []AddressStats, n int, compare func(a, b AddressStats) int) []AddressStats {
+	sorted := slices.Clone(all)
+	slices.SortFunc(sorted, func(a, b AddressStats) int {
+		return cmp.Or(compare(b, a), cmp.Compare(a.Address, b.Address))
+	})
+	if n >= 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+)
+
+func newTx(height uint64, from, to, txType, status string, gasUsed uint64, amount int64) *types.Transaction {
+	return &types.Transaction{
+		TxBasicBlockData: types.TxBasicBlockData{BasicBlockData: types.BasicBlockData{Height: height}},
+		TxFrom:           from,
+		TxTo:             to,
+		TxType:           txType,
+		Status:           status,
+		GasUsed:          gasUsed,
+		Amount:           big.NewInt(amount),
+	}
+}
+
+func TestHeavyHitters(t *testing.T) {
+	h := NewHeavyHitters()
+	h.Add([]*types.Transaction{
+		newTx(10, "f01", "f02", parser.MethodSend, "Ok", 100, 50),
+		newTx(10, "f01", "f410f", parser.MethodInvokeContract, "Ok", 500, 0),
+		newTx(10, "f01", "f02", parser.TotalFeeOp, "Ok", 0, 1000),
+	})
+	h.Add([]*types.Transaction{
+		newTx(12, "f03", "f02", parser.MethodSend, "ErrInsufficientFunds", 10, 500),
+		newTx(12, "f03", "f04", parser.MethodSend, "Ok", 10, 70),
+	})
+
+	report := h.Top(1)
+	require.Equal(t, uint64(10), report.FromHeight)
+	require.Equal(t, uint64(12), report.ToHeight)
+
+	require.Equal(t, "f01", report.Senders.ByTxCount[0].Address)
+	require.Equal(t, uint64(2), report.Senders.ByTxCount[0].TxCount)
+	require.Equal(t, "f01", report.Senders.ByGasUsed[0].Address)
+	require.Equal(t, uint64(600), report.Senders.ByGasUsed[0].GasUsed)
+	// failed txs move no value
+	require.Equal(t, "f03", report.Senders.ByValue[0].Address)
+	require.Equal(t, int64(70), report.Senders.ByValue[0].Value.Int64())
+
+	require.Equal(t, "f02", report.Receivers.ByTxCount[0].Address)
+	require.Equal(t, uint64(2), report.Receivers.ByTxCount[0].TxCount)
+
+	require.Len(t, report.Contracts.ByTxCount, 1)
+	require.Equal(t, "f410f", report.Contracts.ByTxCount[0].Address)
+}
+
+func TestHeavyHitters_Ties(t *testing.T) {
+	h := NewHeavyHitters()
+	h.Add([]*types.Transaction{
+		newTx(1, "f02", "f09", parser.MethodSend, "Ok", 1, 1),
+		newTx(1, "f01", "f09", parser.MethodSend, "Ok", 1, 1),
+	})
+
+	report := h.Top(-1)
+	require.Len(t, report.Senders.ByTxCount, 2)
+	require.Equal(t, "f01", report.Senders.ByTxCount[0].Address)
+	require.Equal(t, "f02", report.Senders.ByTxCount[1].Address)
+}
@@ -0,0 +1,262 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/klauspost/compress/s2"
+	"github.com/zondax/fil-parser/types"
+)
+
+// File types of a height, named as tracedl names them: <type>_<height>.json[.gz|.s2]
+const (
+	TypeTraces    = "traces"
+	TypeTipset    = "tipset"
+	TypeEthLog    = "ethlog"
+	TypeNativeLog = "nativelog"
+	// TypeMetadata is the BlockMetadata of the node the height was downloaded from
+	TypeMetadata = "metadata"
+)
+
+var (
+	ErrHeightNotFound  = errors.New("height not found in bundle")
+	ErrInvalidFileName = errors.New("invalid bundle file name")
+)
+
+// HeightFiles are the raw (decompressed) files of a height
+type HeightFiles struct {
+	Height     uint64
+	Traces     []byte
+	Tipset     []byte
+	EthLogs    []byte
+	NativeLogs []byte
+	// Metadata is optional, see BlockMetadata
+	Metadata []byte
+}
+
+// BlockMetadata returns the metadata of the height. The node version picks the parser of the traces, so the
+// heights downloaded from different node versions carry their own metadata file; the heights without it get the
+// given default metadata.
+func (h *HeightFiles) BlockMetadata(defaultMetadata types.BlockMetadata) (types.BlockMetadata, error) {
+	if len(h.Metadata) == 0 {
+		return defaultMetadata, nil
+	}
+	var metadata types.BlockMetadata
+	if err := sonic.Unmarshal(h.Metadata, &metadata); err != nil {
+		return types.BlockMetadata{}, fmt.Errorf("could not decode metadata of height %d: %w", h.Height, err)
+	}
+	return metadata, nil
+}
+
+// TxsData decodes the files into the input of ParseTransactions. The metadata is the default of the heights
+// without metadata file, see BlockMetadata.
+func (h *HeightFiles) TxsData(defaultMetadata types.BlockMetadata) (types.TxsData, error) {
+	metadata, err := h.BlockMetadata(defaultMetadata)
+	if err != nil {
+		return types.TxsData{}, err
+	}
+	txsData := types.TxsData{Traces: h.Traces, Metadata: metadata}
+	if err := sonic.Unmarshal(h.Tipset, &txsData.Tipset); err != nil {
+		return types.TxsData{}, fmt.Errorf("could not decode tipset of height %d: %w", h.Height, err)
+	}
+	if len(h.EthLogs) > 0 {
+		if err := sonic.Unmarshal(h.EthLogs, &txsData.EthLogs); err != nil {
+			return types.TxsData{}, fmt.Errorf("could not decode eth logs of height %d: %w", h.Height, err)
+		}
+	}
+	return txsData, nil
+}
+
+// EventsData decodes the files into the input of ParseNativeEvents and ParseEthLogs. The metadata is the default
+// of the heights without metadata file, see BlockMetadata.
+func (h *HeightFiles) EventsData(defaultMetadata types.BlockMetadata) (types.EventsData, error) {
+	txsData, err := h.TxsData(defaultMetadata)
+	if err != nil {
+		return types.EventsData{}, err
+	}
+
+	eventsData := types.EventsData{Tipset: txsData.Tipset, EthLogs: txsData.EthLogs, Metadata: txsData.Metadata}
+	if len(h.NativeLogs) > 0 {
+		var nativeLogs []*filTypes.ActorEvent
+		if err = sonic.Unmarshal(h.NativeLogs, &nativeLogs); err != nil {
+			return types.EventsData{}, fmt.Errorf("could not decode native logs of height %d: %w", h.Height, err)
+		}
+		eventsData.NativeLog = nativeLogs
+	}
+	return eventsData, nil
+}
+
+// Bundle holds the files of several heights, read from a single archive
+type Bundle struct {
+	heights map[uint64]*HeightFiles
+}
+
+// Heights returns the heights of the bundle sorted ASC
+func (b *Bundle) Heights() []uint64 {
+	heights := make([]uint64, 0, len(b.heights))
+	for height := range b.heights {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+// Get returns the files of the height
+func (b *Bundle) Get(height uint64) (*HeightFiles, error) {
+	files, ok := b.heights[height]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrHeightNotFound, height)
+	}
+	return files, nil
+}
+
+// ReadTar reads a tar archive, optionally gzip compressed, with the files of several heights. The files can be
+// compressed individually too (.gz or .s2), and directories inside the archive are ignored.
+func ReadTar(r io.Reader) (*Bundle, error) {
+	buffered := bufio.NewReader(r)
+	var reader io.Reader = buffered
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	bundle := &Bundle{heights: make(map[uint64]*HeightFiles)}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fileType, height, compression, err := parseFileName(path.Base(header.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := readFile(tarReader, compression)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", header.Name, err)
+		}
+
+		files, ok := bundle.heights[height]
+		if !ok {
+			files = &HeightFiles{Height: height}
+			bundle.heights[height] = files
+		}
+		switch fileType {
+		case TypeTraces:
+			files.Traces = data
+		case TypeTipset:
+			files.Tipset = data
+		case TypeEthLog:
+			files.EthLogs = data
+		case TypeNativeLog:
+			files.NativeLogs = data
+		case TypeMetadata:
+			files.Metadata = data
+		}
+	}
+
+	return bundle, nil
+}
+
+// WriteTar writes the files of the heights as a gzip compressed tar archive, readable by ReadTar
+func WriteTar(w io.Writer, heights []*HeightFiles) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, files := range heights {
+		for _, file := range []struct {
+			fileType string
+			data     []byte
+		}{{TypeTraces, files.Traces}, {TypeTipset, files.Tipset}, {TypeEthLog, files.EthLogs}, {TypeNativeLog, files.NativeLogs},
+			{TypeMetadata, files.Metadata}} {
+			fileType, data := file.fileType, file.data
+			if data == nil {
+				continue
+			}
+
+			header := &tar.Header{
+				Name:     fmt.Sprintf("%s_%d.json", fileType, files.Height),
+				Mode:     0o644,
+				Size:     int64(len(data)),
+				Typeflag: tar.TypeReg,
+			}
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := tarWriter.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// parseFileName parses the names generated by tracedl, e.g. traces_3897964.json.gz
+func parseFileName(name string) (string, uint64, string, error) {
+	base, compression, _ := strings.Cut(name, ".json")
+	compression = strings.TrimPrefix(compression, ".")
+
+	fileType, rawHeight, ok := strings.Cut(base, "_")
+	if !ok {
+		return "", 0, "", fmt.Errorf("%w: %s", ErrInvalidFileName, name)
+	}
+	switch fileType {
+	case TypeTraces, TypeTipset, TypeEthLog, TypeNativeLog, TypeMetadata:
+	default:
+		return "", 0, "", fmt.Errorf("%w: unknown file type %s", ErrInvalidFileName, name)
+	}
+
+	height, err := strconv.ParseUint(rawHeight, 10, 64)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("%w: %s", ErrInvalidFileName, name)
+	}
+	return fileType, height, compression, nil
+}
+
+func readFile(r io.Reader, compression string) ([]byte, error) {
+	switch compression {
+	case "":
+		return io.ReadAll(r)
+	case "gz":
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		return io.ReadAll(gzipReader)
+	case "s2":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(s2.NewReader(bytes.NewReader(data)))
+	}
+	return nil, fmt.Errorf("unknown compression %s", compression)
+}
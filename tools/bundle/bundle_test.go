@@ -0,0 +1,138 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestWriteReadTar(t *testing.T) {
+	heights := []*HeightFiles{
+		{Height: 10, Traces: []byte(`{"trace":[]}`), Tipset: []byte(`{}`), EthLogs: []byte(`[]`)},
+		{Height: 11, Traces: []byte(`{"trace":[1]}`), Tipset: []byte(`{}`), NativeLogs: []byte(`[]`),
+			Metadata: []byte(`{"node_major_minor_version":"v1.23"}`)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTar(&buf, heights))
+
+	bundle, err := ReadTar(&buf)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10, 11}, bundle.Heights())
+
+	for _, want := range heights {
+		got, err := bundle.Get(want.Height)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err = bundle.Get(12)
+	require.ErrorIs(t, err, ErrHeightNotFound)
+}
+
+func TestReadTar_CompressedEntries(t *testing.T) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, err := gzipWriter.Write([]byte(`{"trace":[]}`))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "bundle/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "bundle/traces_5.json.gz", Typeflag: tar.TypeReg,
+		Mode: 0o644, Size: int64(compressed.Len())}))
+	_, err = tarWriter.Write(compressed.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+
+	bundle, err := ReadTar(&buf)
+	require.NoError(t, err)
+	files, err := bundle.Get(5)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"trace":[]}`), files.Traces)
+}
+
+func TestHeightFiles_BlockMetadata(t *testing.T) {
+	defaultMetadata := types.BlockMetadata{NodeInfo: types.NodeInfo{NodeMajorMinorVersion: "v1.23"}}
+
+	metadata, err := (&HeightFiles{Height: 1}).BlockMetadata(defaultMetadata)
+	require.NoError(t, err)
+	require.Equal(t, defaultMetadata, metadata)
+
+	// heights downloaded from another node version are parsed with their own metadata
+	files := &HeightFiles{Height: 2, Metadata: []byte(`{"node_full_version":"1.31.0+mainnet","node_major_minor_version":"v1.31"}`)}
+	metadata, err = files.BlockMetadata(defaultMetadata)
+	require.NoError(t, err)
+	require.Equal(t, "v1.31", metadata.NodeMajorMinorVersion)
+	require.Equal(t, "1.31.0+mainnet", metadata.NodeFullVersion)
+
+	_, err = (&HeightFiles{Height: 3, Metadata: []byte(`{`)}).BlockMetadata(defaultMetadata)
+	require.Error(t, err)
+}
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileName        string
+		wantType        string
+		wantHeight      uint64
+		wantCompression string
+		wantErr         bool
+	}{
+		{name: "plain", fileName: "traces_100.json", wantType: TypeTraces, wantHeight: 100},
+		{name: "gzip", fileName: "tipset_7.json.gz", wantType: TypeTipset, wantHeight: 7, wantCompression: "gz"},
+		{name: "s2", fileName: "nativelog_7.json.s2", wantType: TypeNativeLog, wantHeight: 7, wantCompression: "s2"},
+		{name: "metadata", fileName: "metadata_7.json.gz", wantType: TypeMetadata, wantHeight: 7, wantCompression: "gz"},
+		{name: "unknown type", fileName: "blocks_7.json", wantErr: true},
+		{name: "invalid height", fileName: "ethlog_x.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileType, height, compression, err := parseFileName(tt.fileName)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidFileName)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantType, fileType)
+			require.Equal(t, tt.wantHeight, height)
+			require.Equal(t, tt.wantCompression, compression)
+		})
+	}
+}
+
+func TestLoader_Load(t *testing.T) {
+	opened := map[uint64]int{}
+	open := func(_ context.Context, firstHeight uint64) (io.ReadCloser, error) {
+		opened[firstHeight]++
+		var heights []*HeightFiles
+		for h := firstHeight; h < firstHeight+10; h++ {
+			heights = append(heights, &HeightFiles{Height: h, Tipset: []byte(`{}`)})
+		}
+		var buf bytes.Buffer
+		if err := WriteTar(&buf, heights); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(&buf), nil
+	}
+
+	loader, err := NewLoader(open, 10)
+	require.NoError(t, err)
+
+	for _, height := range []uint64{20, 25, 29, 30} {
+		files, err := loader.Load(context.Background(), height)
+		require.NoError(t, err)
+		require.Equal(t, height, files.Height)
+	}
+	require.Equal(t, map[uint64]int{20: 1, 30: 1}, opened)
+
+	_, err = NewLoader(open, 0)
+	require.Error(t, err)
+}
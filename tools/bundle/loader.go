@@ -0,0 +1,84 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/zondax/fil-parser/tools/jobs"
+	"github.com/zondax/fil-parser/types"
+)
+
+// OpenFunc opens the bundle that starts at the given height, e.g. with a GET to an object store
+type OpenFunc func(ctx context.Context, firstHeight uint64) (io.ReadCloser, error)
+
+// TxsParser parses the transactions of a height
+type TxsParser interface {
+	ParseTransactions(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error)
+}
+
+// Loader reads the files of the heights from bundles of Size consecutive heights, so a range job does a single
+// read per bundle instead of a read per file of every height. The last bundle read is kept in memory.
+type Loader struct {
+	open OpenFunc
+	size uint64
+
+	mu          sync.Mutex
+	firstHeight uint64
+	current     *Bundle
+}
+
+// NewLoader creates a loader for bundles of size heights, starting at the heights multiple of size
+func NewLoader(open OpenFunc, size uint64) (*Loader, error) {
+	if size == 0 {
+		return nil, fmt.Errorf("bundle size must be positive")
+	}
+	return &Loader{open: open, size: size}, nil
+}
+
+// FirstHeight returns the first height of the bundle holding the height
+func (l *Loader) FirstHeight(height uint64) uint64 {
+	return height - height%l.size
+}
+
+// Load returns the files of the height, opening its bundle if it is not the one in memory
+func (l *Loader) Load(ctx context.Context, height uint64) (*HeightFiles, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	firstHeight := l.FirstHeight(height)
+	if l.current == nil || l.firstHeight != firstHeight {
+		reader, err := l.open(ctx, firstHeight)
+		if err != nil {
+			return nil, fmt.Errorf("could not open bundle of height %d: %w", firstHeight, err)
+		}
+		defer reader.Close()
+
+		bundle, err := ReadTar(reader)
+		if err != nil {
+			return nil, fmt.Errorf("could not read bundle of height %d: %w", firstHeight, err)
+		}
+		l.current, l.firstHeight = bundle, firstHeight
+	}
+
+	return l.current.Get(height)
+}
+
+// ParseFunc parses the heights read from the bundles, to be used with jobs.NewSinkHeightFunc. Each height is
+// parsed with its own metadata file, or with defaultMetadata if the bundle has none for it, see
+// HeightFiles.BlockMetadata.
+func (l *Loader) ParseFunc(parser TxsParser, defaultMetadata types.BlockMetadata) jobs.ParseFunc {
+	return func(ctx context.Context, height uint64) (*types.TxsParsedResult, error) {
+		files, err := l.Load(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+
+		txsData, err := files.TxsData(defaultMetadata)
+		if err != nil {
+			return nil, err
+		}
+		return parser.ParseTransactions(ctx, txsData)
+	}
+}
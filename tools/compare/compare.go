@@ -0,0 +1,194 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// DefaultIgnoredFields are expected to change between parser versions and are not reported
+var DefaultIgnoredFields = []string{"parser_version", "parser_build", "node_major_minor_version", "node_full_version", "metadata_compressed"}
+
+// metadataPrefix is used for the fields of the tx metadata, which are compared one by one
+const metadataPrefix = "tx_metadata."
+
+// TxsParser is implemented by FilecoinParser, and by any parser build wrapped to be compared
+type TxsParser interface {
+	ParseTransactions(ctx context.Context, txsData types.TxsData) (*types.TxsParsedResult, error)
+}
+
+// TxsDataLoader loads the stored traces, tipset and metadata of a height
+type TxsDataLoader func(ctx context.Context, height uint64) (types.TxsData, error)
+
+type FieldDiff struct {
+	Field     string `json:"field"`
+	Baseline  string `json:"baseline"`
+	Candidate string `json:"candidate"`
+}
+
+type TxDiff struct {
+	Id     string      `json:"id"`
+	TxCid  string      `json:"tx_cid"`
+	TxType string      `json:"tx_type"`
+	Fields []FieldDiff `json:"fields"`
+}
+
+// HeightReport are the differences found in a height. Transactions are matched by their id.
+type HeightReport struct {
+	Height  uint64   `json:"height"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []TxDiff `json:"changed,omitempty"`
+	// Error is set when one of the parsers failed
+	Error string `json:"error,omitempty"`
+}
+
+func (h HeightReport) HasDifferences() bool {
+	return len(h.Added) > 0 || len(h.Removed) > 0 || len(h.Changed) > 0 || h.Error != ""
+}
+
+type Report struct {
+	Heights []HeightReport `json:"heights"`
+}
+
+// HasDifferences is false when the upgrade is safe for the compared heights
+func (r *Report) HasDifferences() bool {
+	return slices.ContainsFunc(r.Heights, HeightReport.HasDifferences)
+}
+
+type Options struct {
+	// IgnoredFields are the json names of the fields (or tx_metadata.<key>) that are not compared.
+	// DefaultIgnoredFields are used if empty.
+	IgnoredFields []string
+}
+
+func (o Options) ignored() []string {
+	if len(o.IgnoredFields) == 0 {
+		return DefaultIgnoredFields
+	}
+	return o.IgnoredFields
+}
+
+// Replay parses every height with both parsers and compares their transactions. A height that fails on
+// either parser is reported with its error and does not stop the replay.
+func Replay(ctx context.Context, heights []uint64, load TxsDataLoader, baseline, candidate TxsParser, opts Options) (*Report, error) {
+	report := &Report{}
+	for _, height := range heights {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		txsData, err := load(ctx, height)
+		if err != nil {
+			return report, fmt.Errorf("could not load height %d: %w", height, err)
+		}
+
+		baselineResult, baselineErr := baseline.ParseTransactions(ctx, txsData)
+		candidateResult, candidateErr := candidate.ParseTransactions(ctx, txsData)
+		if baselineErr != nil || candidateErr != nil {
+			report.Heights = append(report.Heights, HeightReport{
+				Height: height,
+				Error:  fmt.Sprintf("baseline: %v, candidate: %v", baselineErr, candidateErr),
+			})
+			continue
+		}
+
+		report.Heights = append(report.Heights, CompareTransactions(height, baselineResult.Txs, candidateResult.Txs, opts))
+	}
+	return report, nil
+}
+
+// CompareTransactions compares the transactions of a height parsed by two parser versions
+func CompareTransactions(height uint64, baseline, candidate []*types.Transaction, opts Options) HeightReport {
+	report := HeightReport{Height: height}
+	ignored := opts.ignored()
+
+	candidateById := make(map[string]*types.Transaction, len(candidate))
+	for _, tx := range candidate {
+		candidateById[tx.Id] = tx
+	}
+
+	baselineIds := make(map[string]bool, len(baseline))
+	for _, tx := range baseline {
+		baselineIds[tx.Id] = true
+		candidateTx, ok := candidateById[tx.Id]
+		if !ok {
+			report.Removed = append(report.Removed, tx.Id)
+			continue
+		}
+
+		if fields := diffFields(tx, candidateTx, ignored); len(fields) > 0 {
+			report.Changed = append(report.Changed, TxDiff{Id: tx.Id, TxCid: tx.TxCid, TxType: tx.TxType, Fields: fields})
+		}
+	}
+
+	for _, tx := range candidate {
+		if !baselineIds[tx.Id] {
+			report.Added = append(report.Added, tx.Id)
+		}
+	}
+
+	return report
+}
+
+func diffFields(baseline, candidate *types.Transaction, ignored []string) []FieldDiff {
+	baselineFields := txFields(baseline)
+	candidateFields := txFields(candidate)
+
+	keys := make([]string, 0, len(baselineFields))
+	for key := range baselineFields {
+		keys = append(keys, key)
+	}
+	for key := range candidateFields {
+		if _, ok := baselineFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var diffs []FieldDiff
+	for _, key := range keys {
+		if slices.Contains(ignored, key) {
+			continue
+		}
+		baselineValue, candidateValue := baselineFields[key], candidateFields[key]
+		if !bytes.Equal(baselineValue, candidateValue) {
+			diffs = append(diffs, FieldDiff{Field: key, Baseline: string(baselineValue), Candidate: string(candidateValue)})
+		}
+	}
+	return diffs
+}
+
+// txFields returns the json value of every field of the tx. The metadata is decoded and split into its
+// keys, so a change in a single param is reported on its own.
+func txFields(tx *types.Transaction) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(raw, &fields)
+
+	metadataField := strings.TrimSuffix(metadataPrefix, ".")
+	metadata, err := tx.GetMetadata()
+	if err != nil || metadata == "" {
+		return fields
+	}
+
+	var metadataFields map[string]json.RawMessage
+	if err = json.Unmarshal([]byte(metadata), &metadataFields); err != nil {
+		fields[metadataField] = json.RawMessage(fmt.Sprintf("%q", metadata))
+		return fields
+	}
+
+	delete(fields, metadataField)
+	for key, value := range metadataFields {
+		fields[metadataPrefix+key] = value
+	}
+	return fields
+}
@@ -0,0 +1,99 @@
+package compare
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/tools/sink"
+	"github.com/zondax/fil-parser/types"
+)
+
+func tx(id, txType, metadata, parserVersion string) *types.Transaction {
+	return &types.Transaction{
+		TxBasicBlockData: types.TxBasicBlockData{BasicBlockData: types.BasicBlockData{Height: 10}},
+		Id:               id,
+		TxCid:            "bafy" + id,
+		TxType:           txType,
+		TxMetadata:       metadata,
+		ParserVersion:    parserVersion,
+	}
+}
+
+type staticParser struct {
+	txs []*types.Transaction
+	err error
+}
+
+func (p staticParser) ParseTransactions(_ context.Context, _ types.TxsData) (*types.TxsParsedResult, error) {
+	return &types.TxsParsedResult{Txs: p.txs}, p.err
+}
+
+func TestCompareTransactions(t *testing.T) {
+	baseline := []*types.Transaction{
+		tx("1", "Send", `{"Params":"a"}`, "v1"),
+		tx("2", "Exec", `{"Params":"b","Return":"c"}`, "v1"),
+		tx("3", "Send", "", "v1"),
+	}
+	candidate := []*types.Transaction{
+		tx("1", "Send", `{"Params":"a"}`, "v2"),
+		tx("2", "Exec", `{"Params":"b","Return":"d"}`, "v2"),
+		tx("4", "Send", "", "v2"),
+	}
+
+	report := CompareTransactions(10, baseline, candidate, Options{})
+	require.True(t, report.HasDifferences())
+	require.Equal(t, []string{"3"}, report.Removed)
+	require.Equal(t, []string{"4"}, report.Added)
+	require.Equal(t, []TxDiff{{
+		Id:     "2",
+		TxCid:  "bafy2",
+		TxType: "Exec",
+		Fields: []FieldDiff{{Field: "tx_metadata.Return", Baseline: `"c"`, Candidate: `"d"`}},
+	}}, report.Changed)
+
+	// compressed metadata is compared decoded
+	compressed := tx("2", "Exec", `{"Params":"b","Return":"c"}`, "v2")
+	compressed.CompressMetadata()
+	report = CompareTransactions(10, baseline[1:2], []*types.Transaction{compressed}, Options{})
+	require.False(t, report.HasDifferences())
+}
+
+func TestReplay(t *testing.T) {
+	load := func(_ context.Context, _ uint64) (types.TxsData, error) {
+		return types.TxsData{}, nil
+	}
+	txs := []*types.Transaction{tx("1", "Send", "", "v1")}
+
+	report, err := Replay(context.Background(), []uint64{1, 2}, load, staticParser{txs: txs}, staticParser{txs: txs}, Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Heights, 2)
+	require.False(t, report.HasDifferences())
+
+	report, err = Replay(context.Background(), []uint64{1}, load, staticParser{txs: txs}, staticParser{err: errors.New("boom")}, Options{})
+	require.NoError(t, err)
+	require.True(t, report.HasDifferences())
+	require.Contains(t, report.Heights[0].Error, "boom")
+}
+
+func TestCompareJSONL(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, txs []*types.Transaction) string {
+		path := filepath.Join(dir, name)
+		jsonlSink, err := sink.NewJSONLSink(path, filepath.Join(dir, name+".addresses"))
+		require.NoError(t, err)
+		require.NoError(t, jsonlSink.WriteTransactions(context.Background(), txs))
+		require.NoError(t, jsonlSink.Close())
+		return path
+	}
+
+	baselinePath := write("baseline.jsonl", []*types.Transaction{tx("1", "Send", "", "v1")})
+	candidatePath := write("candidate.jsonl", []*types.Transaction{tx("1", "Exec", "", "v2")})
+
+	report, err := CompareJSONL(baselinePath, candidatePath, Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Heights, 1)
+	require.Equal(t, []FieldDiff{{Field: "tx_type", Baseline: `"Send"`, Candidate: `"Exec"`}}, report.Heights[0].Changed[0].Fields)
+}
@@ -0,0 +1,49 @@
+package compare
+
+import (
+	"slices"
+
+	"github.com/zondax/fil-parser/tools/sink"
+	"github.com/zondax/fil-parser/types"
+)
+
+// CompareJSONL compares the output of two parser builds, stored by JSONLSink. This allows comparing builds
+// that can not run in the same program, by replaying the same heights with each of them first.
+func CompareJSONL(baselinePath, candidatePath string, opts Options) (*Report, error) {
+	baseline, err := sink.ReadTransactionsJSONL(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := sink.ReadTransactionsJSONL(candidatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	baselineByHeight := groupByHeight(baseline)
+	candidateByHeight := groupByHeight(candidate)
+
+	heights := make([]uint64, 0, len(baselineByHeight))
+	for height := range baselineByHeight {
+		heights = append(heights, height)
+	}
+	for height := range candidateByHeight {
+		if _, ok := baselineByHeight[height]; !ok {
+			heights = append(heights, height)
+		}
+	}
+	slices.Sort(heights)
+
+	report := &Report{}
+	for _, height := range heights {
+		report.Heights = append(report.Heights, CompareTransactions(height, baselineByHeight[height], candidateByHeight[height], opts))
+	}
+	return report, nil
+}
+
+func groupByHeight(txs []*types.Transaction) map[uint64][]*types.Transaction {
+	grouped := make(map[uint64][]*types.Transaction)
+	for _, tx := range txs {
+		grouped[tx.Height] = append(grouped[tx.Height], tx)
+	}
+	return grouped
+}
@@ -0,0 +1,122 @@
+// Package debug renders the execution traces of a height as a tree, with the actor and method names
+// resolved, to help debugging heights that are not parsed as expected.
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/parser"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+	"github.com/zondax/fil-parser/types"
+)
+
+// Resolver resolves the actor and method names of the trace calls. It is implemented by helper.Helper.
+type Resolver interface {
+	GetActorNameFromAddress(address address.Address, height int64, key filTypes.TipSetKey) (string, error)
+	GetMethodName(msg *parser.LotusMessage, height int64, key filTypes.TipSetKey) (string, error)
+}
+
+// TraceNode is a call of the execution trace
+type TraceNode struct {
+	// MsgCid is only set on the root call of each message
+	MsgCid       string       `json:"msg_cid,omitempty"`
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	ActorName    string       `json:"actor_name"`
+	Method       uint64       `json:"method"`
+	MethodName   string       `json:"method_name"`
+	Value        string       `json:"value"`
+	ExitCode     int64        `json:"exit_code"`
+	ExitCodeName string       `json:"exit_code_name"`
+	Subcalls     []*TraceNode `json:"subcalls,omitempty"`
+}
+
+// BuildTraceTree builds a tree for each message of the traces
+func BuildTraceTree(computeState *typesV2.ComputeStateOutputV2, tipset *types.ExtendedTipSet, resolver Resolver) []*TraceNode {
+	nodes := make([]*TraceNode, 0, len(computeState.Trace))
+	for _, trace := range computeState.Trace {
+		if trace == nil {
+			continue
+		}
+		node := buildNode(trace.ExecutionTrace, tipset, resolver)
+		node.MsgCid = trace.MsgCid.String()
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func buildNode(trace typesV2.ExecutionTraceV2, tipset *types.ExtendedTipSet, resolver Resolver) *TraceNode {
+	height, key := int64(tipset.Height()), tipset.Key()
+
+	actorName, err := resolver.GetActorNameFromAddress(trace.Msg.To, height, key)
+	if err != nil {
+		actorName = parser.UnknownStr
+	}
+	methodName, err := resolver.GetMethodName(&parser.LotusMessage{
+		To:     trace.Msg.To,
+		From:   trace.Msg.From,
+		Method: trace.Msg.Method,
+	}, height, key)
+	if err != nil || methodName == "" {
+		methodName = parser.UnknownStr
+	}
+
+	value := "0"
+	if trace.Msg.Value.Int != nil {
+		value = trace.Msg.Value.String()
+	}
+
+	node := &TraceNode{
+		From:         trace.Msg.From.String(),
+		To:           trace.Msg.To.String(),
+		ActorName:    actorName,
+		Method:       uint64(trace.Msg.Method),
+		MethodName:   methodName,
+		Value:        value,
+		ExitCode:     int64(trace.MsgRct.ExitCode),
+		ExitCodeName: trace.MsgRct.ExitCode.String(),
+	}
+	for _, subcall := range trace.Subcalls {
+		node.Subcalls = append(node.Subcalls, buildNode(subcall, tipset, resolver))
+	}
+	return node
+}
+
+// RenderText writes the trees as indented text, one call per line
+func RenderText(w io.Writer, nodes []*TraceNode) error {
+	for _, node := range nodes {
+		if _, err := fmt.Fprintf(w, "msg %s\n", node.MsgCid); err != nil {
+			return err
+		}
+		if err := renderTextNode(w, node, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderTextNode(w io.Writer, node *TraceNode, depth int) error {
+	_, err := fmt.Fprintf(w, "%s%s -> %s (%s) %s [%d] value=%s exit=%s\n", strings.Repeat("  ", depth), node.From, node.To,
+		node.ActorName, node.MethodName, node.Method, node.Value, node.ExitCodeName)
+	if err != nil {
+		return err
+	}
+	for _, subcall := range node.Subcalls {
+		if err = renderTextNode(w, subcall, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderJSON writes the trees as indented json
+func RenderJSON(w io.Writer, nodes []*TraceNode) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(nodes)
+}
@@ -0,0 +1,71 @@
+package debug
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+	"github.com/zondax/fil-parser/types"
+)
+
+type staticResolver struct{}
+
+func (staticResolver) GetActorNameFromAddress(addr address.Address, _ int64, _ filTypes.TipSetKey) (string, error) {
+	return "actor-" + addr.String(), nil
+}
+
+func (staticResolver) GetMethodName(msg *parser.LotusMessage, _ int64, _ filTypes.TipSetKey) (string, error) {
+	if msg.Method == 0 {
+		return parser.MethodSend, nil
+	}
+	return parser.UnknownStr, nil
+}
+
+func TestBuildTraceTree(t *testing.T) {
+	from, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	to, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	msgCid := cid.MustParse("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+
+	computeState := &typesV2.ComputeStateOutputV2{Trace: []*typesV2.InvocResultV2{{
+		MsgCid: msgCid,
+		ExecutionTrace: typesV2.ExecutionTraceV2{
+			Msg: filTypes.MessageTrace{From: from, To: to, Method: 2, Value: filTypes.NewInt(5)},
+			Subcalls: []typesV2.ExecutionTraceV2{{
+				Msg:    filTypes.MessageTrace{From: to, To: from},
+				MsgRct: filTypes.ReturnTrace{ExitCode: exitcode.ErrForbidden},
+			}},
+		},
+	}}}
+
+	tipset := &types.ExtendedTipSet{}
+	nodes := BuildTraceTree(computeState, tipset, staticResolver{})
+	require.Len(t, nodes, 1)
+	require.Equal(t, msgCid.String(), nodes[0].MsgCid)
+	require.Equal(t, "actor-f01001", nodes[0].ActorName)
+	require.Equal(t, parser.UnknownStr, nodes[0].MethodName)
+	require.Equal(t, "5", nodes[0].Value)
+	require.Len(t, nodes[0].Subcalls, 1)
+	require.Equal(t, parser.MethodSend, nodes[0].Subcalls[0].MethodName)
+	require.Equal(t, int64(exitcode.ErrForbidden), nodes[0].Subcalls[0].ExitCode)
+
+	text := new(bytes.Buffer)
+	require.NoError(t, RenderText(text, nodes))
+	require.Equal(t, "msg "+msgCid.String()+"\n"+
+		"  f01000 -> f01001 (actor-f01001) unknown [2] value=5 exit=Ok(0)\n"+
+		"    f01001 -> f01000 (actor-f01000) Send [0] value=0 exit=ErrForbidden(18)\n", text.String())
+
+	raw := new(bytes.Buffer)
+	require.NoError(t, RenderJSON(raw, nodes))
+	var decoded []*TraceNode
+	require.NoError(t, json.Unmarshal(raw.Bytes(), &decoded))
+	require.Equal(t, nodes, decoded)
+}
@@ -0,0 +1,65 @@
+package event_tools
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/zondax/fil-parser/types"
+)
+
+// ComputeLogsBloom builds the logs bloom of every receipt and of the whole tipset from its eth logs.
+// Receipt blooms are computed like the Lotus eth_getTransactionReceipt, adding the address and the topics
+// of every log. Lotus returns a full bloom for blocks, so the tipset bloom is built as the union of the
+// receipt blooms instead, which is what bloom based log queries expect.
+func ComputeLogsBloom(ethLogs []types.EthLog) *types.LogsBloom {
+	logsBloom := &types.LogsBloom{Tipset: ethtypes.NewEmptyEthBloom()}
+
+	receipts := make(map[ethtypes.EthHash]*types.ReceiptLogsBloom)
+	for _, ethLog := range ethLogs {
+		receipt, ok := receipts[ethLog.TransactionHash]
+		if !ok {
+			receipt = &types.ReceiptLogsBloom{
+				TransactionHash:  ethLog.TransactionHash,
+				TransactionCid:   ethLog.TransactionCid,
+				TransactionIndex: uint64(ethLog.TransactionIndex),
+				Bloom:            ethtypes.NewEmptyEthBloom(),
+			}
+			receipts[ethLog.TransactionHash] = receipt
+		}
+
+		for _, topic := range ethLog.Topics {
+			ethtypes.EthBloomSet(receipt.Bloom, topic[:])
+			ethtypes.EthBloomSet(logsBloom.Tipset, topic[:])
+		}
+		ethtypes.EthBloomSet(receipt.Bloom, ethLog.Address[:])
+		ethtypes.EthBloomSet(logsBloom.Tipset, ethLog.Address[:])
+	}
+
+	logsBloom.Receipts = make([]types.ReceiptLogsBloom, 0, len(receipts))
+	for _, receipt := range receipts {
+		logsBloom.Receipts = append(logsBloom.Receipts, *receipt)
+	}
+	slices.SortFunc(logsBloom.Receipts, func(a, b types.ReceiptLogsBloom) int {
+		return cmp.Compare(a.TransactionIndex, b.TransactionIndex)
+	})
+
+	return logsBloom
+}
+
+// BloomMayContain checks if the data (an address or a topic) may be part of the bloom.
+// False positives are possible, false negatives are not.
+func BloomMayContain(bloom ethtypes.EthBytes, data []byte) bool {
+	if len(bloom) != ethtypes.EthBloomSize/8 {
+		return false
+	}
+
+	probe := ethtypes.EthBytes(ethtypes.NewEmptyEthBloom())
+	ethtypes.EthBloomSet(probe, data)
+	for i := range probe {
+		if bloom[i]&probe[i] != probe[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package event_tools
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestComputeLogsBloom(t *testing.T) {
+	addr1 := ethtypes.EthAddress{0x01}
+	addr2 := ethtypes.EthAddress{0x02}
+	topic1 := ethtypes.EthHash{0xaa}
+	topic2 := ethtypes.EthHash{0xbb}
+	tx1 := ethtypes.EthHash{0x11}
+	tx2 := ethtypes.EthHash{0x22}
+
+	ethLogs := []types.EthLog{
+		{EthLog: ethtypes.EthLog{Address: addr2, Topics: []ethtypes.EthHash{topic2}, TransactionHash: tx2, TransactionIndex: 1}, TransactionCid: "cid2"},
+		{EthLog: ethtypes.EthLog{Address: addr1, Topics: []ethtypes.EthHash{topic1}, TransactionHash: tx1, TransactionIndex: 0}, TransactionCid: "cid1"},
+		{EthLog: ethtypes.EthLog{Address: addr1, TransactionHash: tx1, TransactionIndex: 0, LogIndex: 1}, TransactionCid: "cid1"},
+	}
+
+	logsBloom := ComputeLogsBloom(ethLogs)
+	require.Len(t, logsBloom.Receipts, 2)
+
+	receipt := logsBloom.Receipts[0]
+	require.Equal(t, tx1, receipt.TransactionHash)
+	require.Equal(t, "cid1", receipt.TransactionCid)
+
+	expected := ethtypes.EthBytes(ethtypes.NewEmptyEthBloom())
+	ethtypes.EthBloomSet(expected, topic1[:])
+	ethtypes.EthBloomSet(expected, addr1[:])
+	require.Equal(t, expected, receipt.Bloom)
+	require.False(t, BloomMayContain(receipt.Bloom, topic2[:]))
+
+	for _, data := range [][]byte{addr1[:], addr2[:], topic1[:], topic2[:]} {
+		require.True(t, BloomMayContain(logsBloom.Tipset, data))
+	}
+}
+
+func TestComputeLogsBloom_Empty(t *testing.T) {
+	logsBloom := ComputeLogsBloom(nil)
+	require.Empty(t, logsBloom.Receipts)
+	require.Equal(t, ethtypes.EthBytes(ethtypes.NewEmptyEthBloom()), logsBloom.Tipset)
+}
@@ -0,0 +1,84 @@
+package event_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types"
+)
+
+// EthLogsNode is the subset of the node api needed to fetch the eth logs of a tipset
+type EthLogsNode interface {
+	EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error)
+	EthGetMessageCidByTransactionHash(ctx context.Context, txHash *ethtypes.EthHash) (*cid.Cid, error)
+}
+
+// FetchEthLogs returns the eth logs emitted by the messages of the tipset, ready to be parsed by ParseEthLogs.
+// The logs are filtered by the eth block hash of the tipset instead of a block range, so null rounds and
+// the inclusive range bounds of eth_getLogs can not add or drop logs of the neighbour tipsets.
+func FetchEthLogs(ctx context.Context, node EthLogsNode, tipset *types.ExtendedTipSet) ([]types.EthLog, error) {
+	tipsetCid, err := tipset.Key().Cid()
+	if err != nil {
+		return nil, fmt.Errorf("error getting cid of tipset %d: %w", tipset.Height(), err)
+	}
+	blockHash, err := ethtypes.EthHashFromCid(tipsetCid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting eth block hash of tipset %d: %w", tipset.Height(), err)
+	}
+
+	result, err := node.EthGetLogs(ctx, &ethtypes.EthFilterSpec{BlockHash: &blockHash})
+	if err != nil {
+		return nil, fmt.Errorf("error getting eth logs of tipset %d: %w", tipset.Height(), err)
+	}
+	if result == nil {
+		return []types.EthLog{}, nil
+	}
+
+	txCids := make(map[ethtypes.EthHash]string)
+	ethLogs := make([]types.EthLog, 0, len(result.Results))
+	for _, raw := range result.Results {
+		ethLog, err := decodeEthLog(raw)
+		if err != nil {
+			return nil, err
+		}
+		if ethLog.BlockHash != blockHash {
+			return nil, fmt.Errorf("got eth log of block %s for tipset %d with hash %s", ethLog.BlockHash, tipset.Height(), blockHash)
+		}
+
+		txCid, ok := txCids[ethLog.TransactionHash]
+		if !ok {
+			msgCid, err := node.EthGetMessageCidByTransactionHash(ctx, &ethLog.TransactionHash)
+			if err != nil {
+				return nil, fmt.Errorf("error getting message cid of eth tx %s: %w", ethLog.TransactionHash, err)
+			}
+			if msgCid != nil {
+				txCid = msgCid.String()
+			}
+			txCids[ethLog.TransactionHash] = txCid
+		}
+
+		ethLogs = append(ethLogs, types.EthLog{EthLog: ethLog, TransactionCid: txCid})
+	}
+
+	return ethLogs, nil
+}
+
+// decodeEthLog converts a result of eth_getLogs, which the rpc client decodes as a generic json value
+func decodeEthLog(raw interface{}) (ethtypes.EthLog, error) {
+	if ethLog, ok := raw.(ethtypes.EthLog); ok {
+		return ethLog, nil
+	}
+
+	var ethLog ethtypes.EthLog
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return ethLog, fmt.Errorf("error encoding eth log: %w", err)
+	}
+	if err = json.Unmarshal(data, &ethLog); err != nil {
+		return ethLog, fmt.Errorf("error decoding eth log: %w", err)
+	}
+	return ethLog, nil
+}
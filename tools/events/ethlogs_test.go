@@ -0,0 +1,64 @@
+package event_tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/filecoin-project/lotus/api/mocks"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/golang/mock/gomock"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestFetchEthLogs(t *testing.T) {
+	data, err := os.ReadFile("../../data/genesis/mainnet_genesis_tipset.json")
+	require.NoError(t, err)
+	tipset := &types.ExtendedTipSet{}
+	require.NoError(t, json.Unmarshal(data, &tipset.TipSet))
+
+	tipsetCid, err := tipset.Key().Cid()
+	require.NoError(t, err)
+	blockHash, err := ethtypes.EthHashFromCid(tipsetCid)
+	require.NoError(t, err)
+
+	msgCid, err := cid.Decode("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+	require.NoError(t, err)
+	txHash := ethtypes.EthHash{0x01}
+
+	// the rpc client decodes the results as generic json values
+	var results []interface{}
+	rawLogs, err := json.Marshal([]ethtypes.EthLog{
+		{Address: ethtypes.EthAddress{0xaa}, TransactionHash: txHash, BlockHash: blockHash, LogIndex: 0},
+		{Address: ethtypes.EthAddress{0xbb}, TransactionHash: txHash, BlockHash: blockHash, LogIndex: 1},
+	})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rawLogs, &results))
+
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	node.EXPECT().EthGetLogs(gomock.Any(), &ethtypes.EthFilterSpec{BlockHash: &blockHash}).Return(&ethtypes.EthFilterResult{Results: results}, nil)
+	// the message cid is only requested once per tx
+	node.EXPECT().EthGetMessageCidByTransactionHash(gomock.Any(), &txHash).Return(&msgCid, nil).Times(1)
+
+	ethLogs, err := FetchEthLogs(context.Background(), node, tipset)
+	require.NoError(t, err)
+	require.Len(t, ethLogs, 2)
+	require.Equal(t, ethtypes.EthAddress{0xbb}, ethLogs[1].Address)
+	for _, ethLog := range ethLogs {
+		require.Equal(t, msgCid.String(), ethLog.TransactionCid)
+	}
+}
+
+func TestFetchEthLogs_WrongBlock(t *testing.T) {
+	tipset := &types.ExtendedTipSet{TipSet: filTypes.TipSet{}}
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	node.EXPECT().EthGetLogs(gomock.Any(), gomock.Any()).
+		Return(&ethtypes.EthFilterResult{Results: []interface{}{ethtypes.EthLog{BlockHash: ethtypes.EthHash{0x02}}}}, nil)
+
+	_, err := FetchEthLogs(context.Background(), node, tipset)
+	require.ErrorContains(t, err, "got eth log of block")
+}
@@ -32,6 +32,14 @@ const (
 )
 
 func ParseNativeLog(tipset *types.ExtendedTipSet, actorEvent *filTypes.ActorEvent, logIndex uint64) (*types.Event, error) {
+	return ParseNativeLogWithSchemas(tipset, actorEvent, logIndex, nil, tools.IdBuilder{})
+}
+
+// ParseNativeLogWithSchemas parses the native event like ParseNativeLog, building its id with ids. Native events of
+// user actors with a schema in the registry are decoded into the named fields of the schema instead of the raw
+// entries. Events whose entries can not be decoded with their schema are logged and keep the raw entries.
+func ParseNativeLogWithSchemas(tipset *types.ExtendedTipSet, actorEvent *filTypes.ActorEvent, logIndex uint64,
+	schemas *parser.EventSchemaRegistry, ids tools.IdBuilder) (*types.Event, error) {
 	event := &types.Event{}
 	event.TxCid = actorEvent.MsgCid.String()
 	event.Height = uint64(tipset.Height())
@@ -44,6 +52,17 @@ func ParseNativeLog(tipset *types.ExtendedTipSet, actorEvent *filTypes.ActorEven
 		return nil, err
 	}
 	var metaData string
+	eventType := nativeEventType(actorEvent.Entries)
+	schema, hasSchema := schemas.Lookup(event.Emitter, eventType)
+	var schemaFields map[string]any
+	if hasSchema && addr.Protocol() != address.Delegated {
+		schemaFields, err = decodeSchemaEntries(schema, actorEvent.Entries)
+		if err != nil {
+			// the entries do not follow the schema, keep the raw entries instead of dropping the event
+			zap.S().Errorf("error decoding native event %s of %s with schema, falling back to the raw entries: %s", eventType, event.Emitter, err)
+			hasSchema = false
+		}
+	}
 	if addr.Protocol() == address.Delegated {
 		// this is an evm compatible address
 		event.Type = types.EventTypeEVM
@@ -87,6 +106,21 @@ func ParseNativeLog(tipset *types.ExtendedTipSet, actorEvent *filTypes.ActorEven
 		}
 		metaData = string(metaDataBytes)
 
+		if ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(addr); err == nil {
+			event.EthLogFields.Address = ethAddr.String()
+		} else {
+			zap.S().Errorf("error converting the emitter %s of a native evm event to an eth address: %s", event.Emitter, err)
+		}
+		setEthLogTopics(&event.EthLogFields, topics)
+
+	} else if hasSchema {
+		event.Type = types.EventTypeNative
+		metaDataBytes, err := json.Marshal(schemaFields)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling event fields to JSON: %w", err)
+		}
+		metaData = string(metaDataBytes)
+		event.SelectorID = eventType
 	} else {
 		event.Type = types.EventTypeNative
 		parsedEntries, err := parseNativeEventEntry(event.Type, actorEvent.Entries)
@@ -94,8 +128,9 @@ func ParseNativeLog(tipset *types.ExtendedTipSet, actorEvent *filTypes.ActorEven
 			return nil, fmt.Errorf("error parsing native event entries: %w", err)
 		}
 
+		// built-in actors set the event type as the first entry, user actors are free to emit any entries
 		var eventType datamodel.Node
-		if parsedEntries[0] != nil {
+		if parsedEntries[0] != nil && parsedEntries[0][parsedEntryKey] == NativeTypeEventEntryKey {
 			var ok bool
 			eventType, ok = parsedEntries[0][parsedEntryValue].(datamodel.Node)
 			if !ok {
@@ -120,15 +155,24 @@ func ParseNativeLog(tipset *types.ExtendedTipSet, actorEvent *filTypes.ActorEven
 
 	event.Metadata = metaData
 	event.SelectorSig = genFVMSelectorSig(actorEvent)
+	if hasSchema && event.Type == types.EventTypeNative && schema.Name != "" {
+		event.SelectorSig = schema.Name
+	}
 	event.LogIndex = logIndex
-	event.ID = tools.BuildId(event.TipsetCid, event.TxCid, fmt.Sprint(event.LogIndex), event.Type)
+	event.ID = ids.BuildId(event.TipsetCid, event.TxCid, fmt.Sprint(event.LogIndex), event.Type)
 	return event, nil
 }
 
 func ParseEthLog(tipset *types.ExtendedTipSet, ethLog types.EthLog, helper *helper.Helper, logIndex uint64) (*types.Event, error) {
+	return ParseEthLogWithContext(context.Background(), tipset, ethLog, helper, logIndex)
+}
+
+// ParseEthLogWithContext is ParseEthLog, cancelling the lookup of the selector signature with the context
+func ParseEthLogWithContext(ctx context.Context, tipset *types.ExtendedTipSet, ethLog types.EthLog, helper *helper.Helper,
+	logIndex uint64) (*types.Event, error) {
 	event := &types.Event{}
 	event.TxCid = ethLog.TransactionCid
-	event.Emitter = ethLog.Address.String()
+	event.Emitter = helper.GetConfig().FormatEthAddress(ethLog.Address)
 
 	// we set a custom logIndex to avoid duplicates.
 	// ethLog.LogIndex is only unique within the same ethLog.TransactionIndex.
@@ -140,7 +184,7 @@ func ParseEthLog(tipset *types.ExtendedTipSet, ethLog types.EthLog, helper *help
 
 	if event.SelectorID != "" {
 		var err error
-		event.SelectorSig, err = helper.GetEVMSelectorSig(context.Background(), event.SelectorID)
+		event.SelectorSig, err = helper.GetEVMSelectorSig(ctx, event.SelectorID)
 		if err != nil {
 			zap.S().Errorf("error retrieving selector_sig for hash: %s err: %s", event.SelectorID, err)
 		}
@@ -156,12 +200,42 @@ func ParseEthLog(tipset *types.ExtendedTipSet, ethLog types.EthLog, helper *help
 	event.Metadata = string(metaDataBytes)
 	event.Reverted = ethLog.Removed
 	event.Type = types.EventTypeEVM
-	event.ID = tools.BuildId(event.TipsetCid, event.TxCid, fmt.Sprint(event.LogIndex), event.Type)
+	event.EthLogFields = ethLogFields(ethLog)
+	event.ID = tools.NewIdBuilder(helper.GetConfig().IdHashScheme).BuildId(event.TipsetCid, event.TxCid, fmt.Sprint(event.LogIndex), event.Type)
 
 	return event, nil
 
 }
 
+// ethLogFields returns the typed fields of the eth log. Logs have up to 4 topics, any other topic is only
+// part of the metadata.
+func ethLogFields(ethLog types.EthLog) types.EthLogFields {
+	ethLogIndex, transactionIndex := uint64(ethLog.LogIndex), uint64(ethLog.TransactionIndex)
+	fields := types.EthLogFields{
+		EthLogIndex:      &ethLogIndex,
+		TransactionIndex: &transactionIndex,
+		TransactionHash:  ethLog.TransactionHash.String(),
+		Address:          ethLog.Address.String(),
+	}
+	topics := make([]string, 0, len(ethLog.Topics))
+	for _, topic := range ethLog.Topics {
+		topics = append(topics, topic.String())
+	}
+	setEthLogTopics(&fields, topics)
+	return fields
+}
+
+// setEthLogTopics sets the typed topics of the fields
+func setEthLogTopics(fields *types.EthLogFields, topics []string) {
+	typedTopics := []*string{&fields.Topic0, &fields.Topic1, &fields.Topic2, &fields.Topic3}
+	for i, topic := range topics {
+		if i >= len(typedTopics) {
+			break
+		}
+		*typedTopics[i] = topic
+	}
+}
+
 func genFVMSelectorSig(event *filTypes.ActorEvent) string {
 	// TODO: generate signature
 	return ""
@@ -224,6 +298,10 @@ func parseNativeEventEntry(eventType string, entries []filTypes.EventEntry) (map
 				}
 				parsedEntry["value"] = selectorHash.String()
 			case types.EventTypeNative:
+				if entry.Codec == cid.Raw {
+					// user actors may emit raw values, which are kept as they are
+					break
+				}
 				var (
 					err         error
 					parsedValue datamodel.Node
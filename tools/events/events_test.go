@@ -0,0 +1,65 @@
+package event_tools
+
+import (
+	"testing"
+
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestEthLogFields(t *testing.T) {
+	topics := []ethtypes.EthHash{{0x01}, {0x02}, {0x03}, {0x04}, {0x05}}
+	ethLog := types.EthLog{EthLog: ethtypes.EthLog{
+		Address:          ethtypes.EthAddress{0xaa},
+		TransactionHash:  ethtypes.EthHash{0xff},
+		TransactionIndex: 3,
+		LogIndex:         7,
+		Topics:           topics,
+	}}
+
+	ethLogIndex, transactionIndex := uint64(7), uint64(3)
+	require.Equal(t, types.EthLogFields{
+		EthLogIndex:      &ethLogIndex,
+		TransactionIndex: &transactionIndex,
+		TransactionHash:  ethtypes.EthHash{0xff}.String(),
+		Address:          "0xaa00000000000000000000000000000000000000",
+		Topic0:           topics[0].String(),
+		Topic1:           topics[1].String(),
+		Topic2:           topics[2].String(),
+		Topic3:           topics[3].String(),
+	}, ethLogFields(ethLog))
+
+	// missing topics are left empty
+	ethLog.Topics = topics[:1]
+	fields := ethLogFields(ethLog)
+	require.Equal(t, topics[0].String(), fields.Topic0)
+	require.Empty(t, fields.Topic1)
+}
+
+func TestParseNativeLog_EthLogFields(t *testing.T) {
+	ethAddr := ethtypes.EthAddress{0xaa}
+	emitter, err := ethAddr.ToFilecoinAddress()
+	require.NoError(t, err)
+	topics := []ethtypes.EthHash{{0x01}, {0x02}}
+
+	actorEvent := &filTypes.ActorEvent{
+		Emitter: emitter,
+		Entries: []filTypes.EventEntry{
+			{Flags: 0x03, Key: "t1", Codec: cid.Raw, Value: topics[0][:]},
+			{Flags: 0x03, Key: "t2", Codec: cid.Raw, Value: topics[1][:]},
+			{Flags: 0x03, Key: EVMDataEventEntryKey, Codec: cid.Raw, Value: []byte{0x01}},
+		},
+	}
+
+	event, err := ParseNativeLog(&types.ExtendedTipSet{TipSet: filTypes.TipSet{}}, actorEvent, 0)
+	require.NoError(t, err)
+	require.Equal(t, types.EventTypeEVM, event.Type)
+	require.Equal(t, types.EthLogFields{
+		Address: ethAddr.String(),
+		Topic0:  topics[0].String(),
+		Topic1:  topics[1].String(),
+	}, event.EthLogFields)
+}
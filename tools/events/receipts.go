@@ -0,0 +1,61 @@
+package event_tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+)
+
+// ActorEventsFromReceipts builds the actor events emitted by each message receipt, so they can be parsed
+// by ParseNativeLog. Events are fetched from the node when only the events AMT root is provided.
+func ActorEventsFromReceipts(ctx context.Context, tipset *types.ExtendedTipSet, receipts []types.ReceiptEvents, helper *helper.Helper) ([]*filTypes.ActorEvent, error) {
+	var actorEvents []*filTypes.ActorEvent
+	for _, receipt := range receipts {
+		events := receipt.Events
+		if len(events) == 0 && receipt.EventsRoot != nil {
+			var err error
+			events, err = helper.GetFilecoinNodeClient().ChainGetEvents(ctx, *receipt.EventsRoot)
+			if err != nil {
+				return nil, fmt.Errorf("error getting events for root %s: %w", receipt.EventsRoot.String(), err)
+			}
+		}
+
+		for _, event := range events {
+			actorEvents = append(actorEvents, &filTypes.ActorEvent{
+				Entries:   event.Entries,
+				Emitter:   resolveEmitter(ctx, uint64(event.Emitter), helper),
+				Reverted:  false,
+				Height:    tipset.Height(),
+				TipSetKey: tipset.Key(),
+				MsgCid:    receipt.MsgCid,
+			})
+		}
+	}
+
+	return actorEvents, nil
+}
+
+// resolveEmitter returns the delegated address of the emitter if it has one, so evm events are
+// detected as such. Otherwise, the id address is returned.
+func resolveEmitter(ctx context.Context, actorID uint64, helper *helper.Helper) address.Address {
+	emitter, err := address.NewIDAddress(actorID)
+	if err != nil {
+		return emitter
+	}
+
+	robust, err := helper.GetActorsCache().GetRobustAddressWithContext(ctx, emitter)
+	if err != nil {
+		return emitter
+	}
+
+	robustAddr, err := address.NewFromString(robust)
+	if err != nil || robustAddr.Protocol() != address.Delegated {
+		return emitter
+	}
+
+	return robustAddr
+}
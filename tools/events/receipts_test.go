@@ -0,0 +1,59 @@
+package event_tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api/mocks"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/golang/mock/gomock"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/actors/cache"
+	"github.com/zondax/fil-parser/actors/cache/impl/common"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestActorEventsFromReceipts(t *testing.T) {
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	actorsCache, err := cache.SetupActorsCache(common.DataSource{Node: node}, nil)
+	require.NoError(t, err)
+	h := helper.NewHelper(nil, actorsCache, node, nil, parser.FilecoinParserConfig{})
+
+	msgCid, err := cid.Decode("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+	require.NoError(t, err)
+	eventsRoot, err := cid.Decode("bafy2bzaceaqvazcnzwg5xpbcvfwkjheygqstdrjfn3dtzwmhkb6ph4tmnbaf6")
+	require.NoError(t, err)
+
+	userActor, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	entries := []filTypes.EventEntry{
+		{Flags: 0x03, Key: "amount", Codec: cid.Raw, Value: []byte("100")},
+	}
+
+	node.EXPECT().ChainGetEvents(gomock.Any(), eventsRoot).Return([]filTypes.Event{{Emitter: 1001, Entries: entries}}, nil)
+	node.EXPECT().StateAccountKey(gomock.Any(), userActor, gomock.Any()).Return(userActor, nil).AnyTimes()
+
+	tipset := &types.ExtendedTipSet{TipSet: filTypes.TipSet{}}
+	actorEvents, err := ActorEventsFromReceipts(context.Background(), tipset, []types.ReceiptEvents{
+		{MsgCid: msgCid, EventsRoot: &eventsRoot},
+		// events already fetched are not requested again
+		{MsgCid: msgCid, Events: []filTypes.Event{{Emitter: 1001, Entries: entries}}},
+	}, h)
+	require.NoError(t, err)
+	require.Len(t, actorEvents, 2)
+
+	for idx, actorEvent := range actorEvents {
+		require.Equal(t, userActor, actorEvent.Emitter)
+		require.Equal(t, msgCid, actorEvent.MsgCid)
+
+		event, err := ParseNativeLog(tipset, actorEvent, uint64(idx))
+		require.NoError(t, err)
+		require.Equal(t, types.EventTypeNative, event.Type)
+		require.Equal(t, msgCid.String(), event.TxCid)
+		require.Empty(t, event.SelectorID)
+	}
+}
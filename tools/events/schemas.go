@@ -0,0 +1,151 @@
+package event_tools
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/zondax/fil-parser/parser"
+)
+
+// nativeEventType returns the $type entry of the event, or an empty string if the actor did not set it
+func nativeEventType(entries []filTypes.EventEntry) string {
+	for _, entry := range entries {
+		if entry.Key != NativeTypeEventEntryKey {
+			continue
+		}
+		if entry.Codec == cid.Raw {
+			return string(entry.Value)
+		}
+		node, err := decode(entry)
+		if err != nil {
+			return ""
+		}
+		eventType, _ := node.AsString()
+		return eventType
+	}
+	return ""
+}
+
+// decodeSchemaEntries decodes the entries into the named fields of the schema. Entries that are not in the
+// schema are kept under their key, hex encoded.
+func decodeSchemaEntries(schema *parser.EventSchema, entries []filTypes.EventEntry) (map[string]any, error) {
+	fields := make(map[string]any, len(entries))
+	for _, entry := range entries {
+		field, ok := schema.Field(entry.Key)
+		if !ok {
+			if _, exists := fields[entry.Key]; !exists {
+				fields[entry.Key] = hex.EncodeToString(entry.Value)
+			}
+			continue
+		}
+
+		value, err := decodeSchemaField(field.Codec, entry)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding field %s of event %s: %w", field.Name, schema.Name, err)
+		}
+		fields[field.Name] = value
+	}
+	return fields, nil
+}
+
+func decodeSchemaField(codec parser.EventFieldCodec, entry filTypes.EventEntry) (any, error) {
+	switch codec {
+	case parser.EventFieldRaw:
+		return hex.EncodeToString(entry.Value), nil
+	case parser.EventFieldCBOR:
+		return decode(entry)
+	}
+
+	// user actors can emit the values either as raw bytes or cbor encoded
+	if entry.Codec != cid.Raw {
+		node, err := decode(entry)
+		if err != nil {
+			return nil, err
+		}
+		return decodeSchemaNode(codec, node)
+	}
+
+	value := entry.Value
+	switch codec {
+	case parser.EventFieldString:
+		return string(value), nil
+	case parser.EventFieldUint, parser.EventFieldInt:
+		if len(value) > 8 {
+			return nil, fmt.Errorf("integer of %d bytes does not fit in 64 bits", len(value))
+		}
+		padded := make([]byte, 8)
+		copy(padded[8-len(value):], value)
+		if codec == parser.EventFieldInt {
+			return int64(binary.BigEndian.Uint64(padded)), nil
+		}
+		return binary.BigEndian.Uint64(padded), nil
+	case parser.EventFieldBigInt:
+		return decodeBigIntBytes(value)
+	case parser.EventFieldAddress:
+		return decodeAddressBytes(value)
+	case parser.EventFieldCid:
+		c, err := cid.Cast(value)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	case parser.EventFieldBoolean:
+		return len(value) == 1 && value[0] == 1, nil
+	}
+	return nil, fmt.Errorf("unknown codec %s", codec)
+}
+
+func decodeSchemaNode(codec parser.EventFieldCodec, node datamodel.Node) (any, error) {
+	switch codec {
+	case parser.EventFieldString:
+		return node.AsString()
+	case parser.EventFieldUint, parser.EventFieldInt:
+		value, err := node.AsInt()
+		if err != nil {
+			return nil, err
+		}
+		if codec == parser.EventFieldUint {
+			return uint64(value), nil
+		}
+		return value, nil
+	case parser.EventFieldBigInt:
+		value, err := node.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		return decodeBigIntBytes(value)
+	case parser.EventFieldAddress:
+		value, err := node.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		return decodeAddressBytes(value)
+	case parser.EventFieldCid:
+		return parseCid(node)
+	case parser.EventFieldBoolean:
+		return node.AsBool()
+	}
+	return nil, fmt.Errorf("unknown codec %s", codec)
+}
+
+func decodeBigIntBytes(value []byte) (string, error) {
+	bigInt, err := big.FromBytes(value)
+	if err != nil {
+		return "", err
+	}
+	return bigInt.String(), nil
+}
+
+func decodeAddressBytes(value []byte) (string, error) {
+	addr, err := address.NewFromBytes(value)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
@@ -0,0 +1,87 @@
+package event_tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/tools"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestParseNativeLogWithSchemas(t *testing.T) {
+	emitter, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	recipient, err := address.NewIDAddress(1002)
+	require.NoError(t, err)
+	msgCid, err := cid.Decode("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+	require.NoError(t, err)
+
+	schemas := parser.NewEventSchemaRegistry()
+	require.NoError(t, schemas.Register(parser.EventSchema{
+		Emitter: emitter.String(),
+		Type:    "deposit",
+		Name:    "Deposit(address,uint64,string)",
+		Fields: []parser.EventField{
+			{Key: "t", Name: "to", Codec: parser.EventFieldAddress},
+			{Key: "a", Name: "amount", Codec: parser.EventFieldUint},
+			{Key: "m", Name: "memo", Codec: parser.EventFieldString},
+		},
+	}))
+
+	actorEvent := &filTypes.ActorEvent{
+		Emitter: emitter,
+		MsgCid:  msgCid,
+		Entries: []filTypes.EventEntry{
+			// "deposit" cbor encoded
+			{Flags: 0x03, Key: NativeTypeEventEntryKey, Codec: 0x51, Value: append([]byte{0x67}, []byte("deposit")...)},
+			{Flags: 0x03, Key: "t", Codec: cid.Raw, Value: recipient.Bytes()},
+			{Flags: 0x03, Key: "a", Codec: cid.Raw, Value: []byte{0x01, 0x00}},
+			{Flags: 0x03, Key: "m", Codec: cid.Raw, Value: []byte("hello")},
+			{Flags: 0x03, Key: "x", Codec: cid.Raw, Value: []byte{0xff}},
+		},
+	}
+
+	tipset := &types.ExtendedTipSet{TipSet: filTypes.TipSet{}}
+	event, err := ParseNativeLogWithSchemas(tipset, actorEvent, 0, schemas, tools.IdBuilder{})
+	require.NoError(t, err)
+	require.Equal(t, types.EventTypeNative, event.Type)
+	require.Equal(t, "deposit", event.SelectorID)
+	require.Equal(t, "Deposit(address,uint64,string)", event.SelectorSig)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal([]byte(event.Metadata), &fields))
+	require.Equal(t, recipient.String(), fields["to"])
+	require.Equal(t, float64(256), fields["amount"])
+	require.Equal(t, "hello", fields["memo"])
+	// entries that are not in the schema are kept hex encoded
+	require.Equal(t, "ff", fields["x"])
+
+	// entries not following the schema fall back to the raw entries
+	actorEvent.Entries[2] = filTypes.EventEntry{Flags: 0x03, Key: "a", Codec: cid.Raw, Value: make([]byte, 9)}
+	event, err = ParseNativeLogWithSchemas(tipset, actorEvent, 0, schemas, tools.IdBuilder{})
+	require.NoError(t, err)
+	require.Equal(t, types.EventTypeNative, event.Type)
+	require.Equal(t, "deposit", event.SelectorID)
+	require.NotEqual(t, "Deposit(address,uint64,string)", event.SelectorSig)
+	require.NotContains(t, event.Metadata, "memo")
+
+	// events of other emitters are not decoded with the schema
+	other, err := address.NewIDAddress(1003)
+	require.NoError(t, err)
+	actorEvent.Emitter = other
+	event, err = ParseNativeLogWithSchemas(tipset, actorEvent, 0, schemas, tools.IdBuilder{})
+	require.NoError(t, err)
+	require.Empty(t, event.SelectorSig)
+	require.NotContains(t, event.Metadata, "memo")
+
+	// the id is built with the hash scheme of the builder
+	blake2bEvent, err := ParseNativeLogWithSchemas(tipset, actorEvent, 0, schemas, tools.NewIdBuilder(parser.IdHashSchemeBlake2b))
+	require.NoError(t, err)
+	require.Equal(t, tools.NewIdBuilder(parser.IdHashSchemeBlake2b).BuildId(event.TipsetCid, event.TxCid, "0", event.Type), blake2bEvent.ID)
+	require.NotEqual(t, event.ID, blake2bEvent.ID)
+}
@@ -0,0 +1,130 @@
+package fees
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+)
+
+// The gas limit can exceed the gas used by 10% without being burned
+const (
+	gasOveruseNum   = 11
+	gasOveruseDenom = 10
+)
+
+// GasOutputs is how the gas of a message is paid, the same as computed by the lotus vm. Everything but the
+// MinerPenalty is paid by the sender; the penalty is paid by the miner that included the message.
+type GasOutputs struct {
+	// BaseFeeBurn is the base fee paid for the gas used, which is burned
+	BaseFeeBurn abi.TokenAmount
+	// OverEstimationBurn is the base fee paid for the gas limit over estimated by the sender, which is burned
+	OverEstimationBurn abi.TokenAmount
+	// MinerPenalty is burned from the miner when the fee cap of the message is lower than the base fee
+	MinerPenalty abi.TokenAmount
+	// MinerTip is the gas premium paid to the miner for the gas limit
+	MinerTip abi.TokenAmount
+	// Refund is the amount returned to the sender out of the gas limit times the fee cap
+	Refund abi.TokenAmount
+
+	// GasRefund is the gas over estimated by the sender that is not burned
+	GasRefund int64
+	// GasBurned is the gas over estimated by the sender that is burned
+	GasBurned int64
+}
+
+// ZeroGasOutputs returns the outputs of a message that pays no gas
+func ZeroGasOutputs() GasOutputs {
+	return GasOutputs{
+		BaseFeeBurn:        big.Zero(),
+		OverEstimationBurn: big.Zero(),
+		MinerPenalty:       big.Zero(),
+		MinerTip:           big.Zero(),
+		Refund:             big.Zero(),
+	}
+}
+
+// Burn is the amount burned from the sender: the base fee burn and the over estimation burn
+func (o GasOutputs) Burn() abi.TokenAmount {
+	return big.Add(o.BaseFeeBurn, o.OverEstimationBurn)
+}
+
+// TotalCost is the amount paid by the sender, the same as the TotalCost of the lotus gas cost api
+func (o GasOutputs) TotalCost() abi.TokenAmount {
+	return big.Add(o.Burn(), o.MinerTip)
+}
+
+// EffectiveGasPrice is the price paid by the sender for each unit of gas used, as reported by the eth receipts
+func (o GasOutputs) EffectiveGasPrice(gasUsed int64) abi.TokenAmount {
+	if gasUsed <= 0 {
+		return big.Zero()
+	}
+	return big.Div(o.TotalCost(), big.NewInt(gasUsed))
+}
+
+// ComputeGasOverestimationBurn splits the gas limit not used by a message into the gas refunded and the gas
+// burned, in that order. Up to 10% of over estimation is refunded, the rest is burned proportionally.
+func ComputeGasOverestimationBurn(gasUsed, gasLimit int64) (int64, int64) {
+	if gasUsed == 0 {
+		return 0, gasLimit
+	}
+
+	// over = min(gasLimit - 1.1*gasUsed, gasUsed)
+	// gasToBurn = (gasLimit - gasUsed) * over / gasUsed
+	over := gasLimit - (gasOveruseNum*gasUsed)/gasOveruseDenom
+	if over < 0 {
+		return gasLimit - gasUsed, 0
+	}
+	if over > gasUsed {
+		over = gasUsed
+	}
+
+	// big ints, the product overflows int64 for huge gas limits
+	gasToBurn := big.Mul(big.NewInt(gasLimit-gasUsed), big.NewInt(over))
+	gasToBurn = big.Div(gasToBurn, big.NewInt(gasUsed))
+
+	return gasLimit - gasUsed - gasToBurn.Int64(), gasToBurn.Int64()
+}
+
+// ComputeGasOutputs computes how the gas of a message is paid given the base fee of its tipset. The base fee
+// burn is skipped if chargeNetworkFee is false, e.g. for the messages the network does not charge.
+func ComputeGasOutputs(gasUsed, gasLimit int64, baseFee, feeCap, gasPremium abi.TokenAmount, chargeNetworkFee bool) GasOutputs {
+	gasUsedBig := big.NewInt(gasUsed)
+	out := ZeroGasOutputs()
+
+	baseFeeToPay := baseFee
+	if baseFee.GreaterThan(feeCap) {
+		baseFeeToPay = feeCap
+		out.MinerPenalty = big.Mul(big.Sub(baseFee, feeCap), gasUsedBig)
+	}
+	if chargeNetworkFee {
+		out.BaseFeeBurn = big.Mul(baseFeeToPay, gasUsedBig)
+	}
+
+	minerTip := gasPremium
+	if big.Add(baseFeeToPay, minerTip).GreaterThan(feeCap) {
+		minerTip = big.Sub(feeCap, baseFeeToPay)
+	}
+	out.MinerTip = big.Mul(minerTip, big.NewInt(gasLimit))
+
+	out.GasRefund, out.GasBurned = ComputeGasOverestimationBurn(gasUsed, gasLimit)
+	if out.GasBurned != 0 {
+		gasBurnedBig := big.NewInt(out.GasBurned)
+		out.OverEstimationBurn = big.Mul(baseFeeToPay, gasBurnedBig)
+		out.MinerPenalty = big.Add(out.MinerPenalty, big.Mul(big.Sub(baseFee, baseFeeToPay), gasBurnedBig))
+	}
+
+	requiredFunds := big.Mul(big.NewInt(gasLimit), feeCap)
+	out.Refund = big.Sub(big.Sub(big.Sub(requiredFunds, out.BaseFeeBurn), out.MinerTip), out.OverEstimationBurn)
+	return out
+}
+
+// ComputeFees computes how the gas of a message is paid from the message, its receipt and the parent base fee
+// of the tipset that included it
+func ComputeFees(msg *filTypes.Message, receipt *filTypes.MessageReceipt, baseFee abi.TokenAmount) GasOutputs {
+	return ComputeGasOutputs(receipt.GasUsed, msg.GasLimit, baseFee, msg.GasFeeCap, msg.GasPremium, true)
+}
+
+// ComputeBurn returns the amount burned from the sender of a message, see GasOutputs.Burn
+func ComputeBurn(msg *filTypes.Message, receipt *filTypes.MessageReceipt, baseFee abi.TokenAmount) abi.TokenAmount {
+	return ComputeFees(msg, receipt, baseFee).Burn()
+}
@@ -0,0 +1,59 @@
+package fees
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeGasOutputs(t *testing.T) {
+	tests := []struct {
+		name                             string
+		gasUsed, gasLimit                int64
+		baseFee, feeCap, gasPremium      int64
+		burn, overEstimation, penalty    int64
+		tip, refund, gasRefund, gasBurnt int64
+	}{
+		{
+			name: "over estimated gas limit", gasUsed: 100, gasLimit: 200, baseFee: 10, feeCap: 20, gasPremium: 5,
+			burn: 1000, overEstimation: 900, tip: 1000, refund: 1100, gasRefund: 10, gasBurnt: 90,
+		},
+		{
+			name: "fee cap below the base fee", gasUsed: 100, gasLimit: 100, baseFee: 10, feeCap: 8, gasPremium: 5,
+			burn: 800, penalty: 200,
+		},
+		{
+			name: "no gas used", gasUsed: 0, gasLimit: 100, baseFee: 10, feeCap: 20, gasPremium: 5,
+			overEstimation: 1000, tip: 500, refund: 500, gasBurnt: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeGasOutputs(tt.gasUsed, tt.gasLimit, big.NewInt(tt.baseFee), big.NewInt(tt.feeCap), big.NewInt(tt.gasPremium), true)
+			require.Equal(t, big.NewInt(tt.burn), got.BaseFeeBurn)
+			require.Equal(t, big.NewInt(tt.overEstimation), got.OverEstimationBurn)
+			require.Equal(t, big.NewInt(tt.penalty), got.MinerPenalty)
+			require.Equal(t, big.NewInt(tt.tip), got.MinerTip)
+			require.Equal(t, big.NewInt(tt.refund), got.Refund)
+			require.Equal(t, tt.gasRefund, got.GasRefund)
+			require.Equal(t, tt.gasBurnt, got.GasBurned)
+			// the sender pays the gas limit times the fee cap, minus the refund
+			require.Equal(t, big.Sub(big.NewInt(tt.gasLimit*tt.feeCap), got.Refund), got.TotalCost())
+		})
+	}
+}
+
+func TestComputeBurn(t *testing.T) {
+	msg := &filTypes.Message{GasLimit: 200, GasFeeCap: big.NewInt(20), GasPremium: big.NewInt(5)}
+	receipt := &filTypes.MessageReceipt{GasUsed: 100}
+
+	require.Equal(t, big.NewInt(1900), ComputeBurn(msg, receipt, big.NewInt(10)))
+	fees := ComputeFees(msg, receipt, big.NewInt(10))
+	require.Equal(t, big.NewInt(29), fees.EffectiveGasPrice(receipt.GasUsed))
+	require.Equal(t, big.Zero(), fees.EffectiveGasPrice(0))
+
+	// the network fee is not charged
+	require.Equal(t, big.Zero(), ComputeGasOutputs(100, 200, big.NewInt(10), big.NewInt(20), big.NewInt(5), false).BaseFeeBurn)
+}
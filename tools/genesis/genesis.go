@@ -0,0 +1,51 @@
+package genesis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/zondax/fil-parser/types"
+)
+
+// Node is the subset of the node api needed to read the genesis
+type Node interface {
+	ChainGetGenesis(ctx context.Context) (*filTypes.TipSet, error)
+	StateListActors(ctx context.Context, tsk filTypes.TipSetKey) ([]address.Address, error)
+	StateGetActor(ctx context.Context, actor address.Address, tsk filTypes.TipSetKey) (*filTypes.Actor, error)
+}
+
+// FetchGenesisData builds the input of ParseGenesis from the node: the genesis tipset and the balance of
+// every actor listed at epoch 0. Balances are sorted by address, so the output is stable between nodes.
+func FetchGenesisData(ctx context.Context, node Node) (*types.GenesisBalances, *types.ExtendedTipSet, error) {
+	tipset, err := node.ChainGetGenesis(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get genesis tipset: %w", err)
+	}
+
+	actors, err := node.StateListActors(ctx, tipset.Key())
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list genesis actors: %w", err)
+	}
+
+	balances := &types.GenesisBalances{}
+	balances.Actors.All = make([]types.GenesisBalance, 0, len(actors))
+	for _, addr := range actors {
+		actor, err := node.StateGetActor(ctx, addr, tipset.Key())
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get genesis actor %s: %w", addr.String(), err)
+		}
+
+		balance := types.GenesisBalance{Key: addr.String()}
+		balance.Value.Balance = actor.Balance.String()
+		balances.Actors.All = append(balances.Actors.All, balance)
+	}
+
+	sort.Slice(balances.Actors.All, func(i, j int) bool {
+		return balances.Actors.All[i].Key < balances.Actors.All[j].Key
+	})
+
+	return balances, &types.ExtendedTipSet{TipSet: *tipset, BlockMessages: make(types.BlockMessages)}, nil
+}
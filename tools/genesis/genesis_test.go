@@ -0,0 +1,43 @@
+package genesis
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lotus/api/mocks"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchGenesisData(t *testing.T) {
+	data, err := os.ReadFile("../../data/genesis/mainnet_genesis_tipset.json")
+	require.NoError(t, err)
+	var tipset filTypes.TipSet
+	require.NoError(t, json.Unmarshal(data, &tipset))
+
+	first, err := address.NewFromString("f0110")
+	require.NoError(t, err)
+	second, err := address.NewFromString("f01002")
+	require.NoError(t, err)
+
+	node := mocks.NewMockFullNode(gomock.NewController(t))
+	node.EXPECT().ChainGetGenesis(gomock.Any()).Return(&tipset, nil)
+	node.EXPECT().StateListActors(gomock.Any(), tipset.Key()).Return([]address.Address{second, first}, nil)
+	node.EXPECT().StateGetActor(gomock.Any(), first, tipset.Key()).Return(&filTypes.Actor{Balance: big.NewInt(10)}, nil)
+	node.EXPECT().StateGetActor(gomock.Any(), second, tipset.Key()).Return(&filTypes.Actor{Balance: big.Zero()}, nil)
+
+	balances, genesisTipset, err := FetchGenesisData(context.Background(), node)
+	require.NoError(t, err)
+	require.Equal(t, tipset.Key(), genesisTipset.Key())
+
+	require.Len(t, balances.Actors.All, 2)
+	require.Equal(t, "f01002", balances.Actors.All[0].Key)
+	require.Equal(t, "0", balances.Actors.All[0].Value.Balance)
+	require.Equal(t, "f0110", balances.Actors.All[1].Key)
+	require.Equal(t, "10", balances.Actors.All[1].Value.Balance)
+}
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint stores the progress of a range job
+type Checkpoint struct {
+	// From is the first height of the range
+	From uint64 `json:"from"`
+	// To is the last height of the range (inclusive)
+	To uint64 `json:"to"`
+	// LastCompletedHeight is the last height that was successfully processed
+	LastCompletedHeight uint64 `json:"last_completed_height"`
+	// Priority is set on the checkpoints of a PriorityJob, whose progress is the Pending ranges instead of
+	// LastCompletedHeight
+	Priority bool `json:"priority,omitempty"`
+	// Pending are the ranges of heights a PriorityJob has pending to process, sorted ASC
+	Pending []HeightRange `json:"pending,omitempty"`
+}
+
+// HeightRange is a range of heights (inclusive)
+type HeightRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// Checkpointer loads and persists the progress of a range job
+type Checkpointer interface {
+	// Load returns the stored checkpoint, or nil if there is none
+	Load() (*Checkpoint, error)
+	Save(checkpoint *Checkpoint) error
+}
+
+// FileCheckpointer stores the checkpoint as a json file on disk
+type FileCheckpointer struct {
+	path string
+}
+
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{path: path}
+}
+
+func (f *FileCheckpointer) Load() (*Checkpoint, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read checkpoint file %s: %w", f.path, err)
+	}
+
+	var checkpoint Checkpoint
+	if err = json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("could not decode checkpoint file %s: %w", f.path, err)
+	}
+
+	return &checkpoint, nil
+}
+
+// Save writes the checkpoint to a temporary file and renames it, so a crash while writing
+// never leaves a corrupted checkpoint behind.
+func (f *FileCheckpointer) Save(checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary checkpoint file: %w", err)
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("could not write checkpoint file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// DefaultDedupRetention is the amount of heights behind the last delivered one whose tipsets are remembered
+const DefaultDedupRetention = 2880
+
+// DeliveredTipset is a tipset whose data was already delivered
+type DeliveredTipset struct {
+	Height    uint64 `json:"height"`
+	TipsetCid string `json:"tipset_cid"`
+}
+
+// DeliveredStore keeps track of the tipsets already delivered, so a chain follower does not deliver them
+// again after a restart. Tipsets are identified by their cid, so the tipsets of a reorg are not taken as
+// duplicates of the orphaned ones at the same height.
+type DeliveredStore interface {
+	IsDelivered(tipsetCid string) (bool, error)
+	MarkDelivered(tipset DeliveredTipset) error
+}
+
+// TipsetDedup skips the tipsets already delivered, e.g. the ones seen again when a chain follower restarts
+// a few heights behind the head
+type TipsetDedup struct {
+	store DeliveredStore
+}
+
+func NewTipsetDedup(store DeliveredStore) *TipsetDedup {
+	return &TipsetDedup{store: store}
+}
+
+// Deliver calls fn unless the tipset was already delivered, and marks the tipset as delivered once fn
+// succeeds. It returns whether the tipset was skipped as a duplicate.
+func (d *TipsetDedup) Deliver(tipset DeliveredTipset, fn func() error) (bool, error) {
+	delivered, err := d.store.IsDelivered(tipset.TipsetCid)
+	if err != nil {
+		return false, fmt.Errorf("could not check if tipset %s was delivered: %w", tipset.TipsetCid, err)
+	}
+	if delivered {
+		return true, nil
+	}
+
+	if err = fn(); err != nil {
+		return false, err
+	}
+
+	if err = d.store.MarkDelivered(tipset); err != nil {
+		return false, fmt.Errorf("could not mark tipset %s as delivered: %w", tipset.TipsetCid, err)
+	}
+	return false, nil
+}
+
+// FileDeliveredStore stores the delivered tipsets as a json file on disk. Only the tipsets within the
+// retention of the last delivered height are kept, as the follower never goes further back.
+type FileDeliveredStore struct {
+	path      string
+	retention uint64
+	mu        sync.Mutex
+	tipsets   map[string]DeliveredTipset
+}
+
+// NewFileDeliveredStore loads the delivered tipsets of the file, if any. A zero retention uses DefaultDedupRetention.
+func NewFileDeliveredStore(path string, retention uint64) (*FileDeliveredStore, error) {
+	if retention == 0 {
+		retention = DefaultDedupRetention
+	}
+	s := &FileDeliveredStore{path: path, retention: retention, tipsets: make(map[string]DeliveredTipset)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("could not read delivered tipsets file %s: %w", path, err)
+	}
+
+	var tipsets []DeliveredTipset
+	if err = json.Unmarshal(data, &tipsets); err != nil {
+		return nil, fmt.Errorf("could not decode delivered tipsets file %s: %w", path, err)
+	}
+	for _, tipset := range tipsets {
+		s.tipsets[tipset.TipsetCid] = tipset
+	}
+
+	return s, nil
+}
+
+func (s *FileDeliveredStore) IsDelivered(tipsetCid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.tipsets[tipsetCid]
+	return ok, nil
+}
+
+// MarkDelivered stores the tipset, forgetting the ones that fall out of the retention
+func (s *FileDeliveredStore) MarkDelivered(tipset DeliveredTipset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tipsets[tipset.TipsetCid] = tipset
+	s.prune()
+	return s.save()
+}
+
+// prune forgets the tipsets older than the retention of the last delivered height
+func (s *FileDeliveredStore) prune() {
+	var last uint64
+	for _, tipset := range s.tipsets {
+		if tipset.Height > last {
+			last = tipset.Height
+		}
+	}
+	if last < s.retention {
+		return
+	}
+	for tipsetCid, tipset := range s.tipsets {
+		if tipset.Height < last-s.retention {
+			delete(s.tipsets, tipsetCid)
+		}
+	}
+}
+
+// save writes the tipsets to a temporary file and renames it, same as FileCheckpointer.Save
+func (s *FileDeliveredStore) save() error {
+	tipsets := make([]DeliveredTipset, 0, len(s.tipsets))
+	for _, tipset := range s.tipsets {
+		tipsets = append(tipsets, tipset)
+	}
+	sort.Slice(tipsets, func(i, j int) bool {
+		if tipsets[i].Height != tipsets[j].Height {
+			return tipsets[i].Height < tipsets[j].Height
+		}
+		return tipsets[i].TipsetCid < tipsets[j].TipsetCid
+	})
+
+	data, err := json.Marshal(tipsets)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTipsetDedup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "delivered.json")
+	store, err := NewFileDeliveredStore(path, 10)
+	require.NoError(t, err)
+	dedup := NewTipsetDedup(store)
+
+	var delivered []string
+	deliver := func(tipset DeliveredTipset) (bool, error) {
+		return dedup.Deliver(tipset, func() error {
+			delivered = append(delivered, tipset.TipsetCid)
+			return nil
+		})
+	}
+
+	skipped, err := deliver(DeliveredTipset{Height: 100, TipsetCid: "bafy100"})
+	require.NoError(t, err)
+	require.False(t, skipped)
+
+	// a failed delivery is not marked
+	_, err = dedup.Deliver(DeliveredTipset{Height: 101, TipsetCid: "bafy101"}, func() error { return errors.New("boom") })
+	require.Error(t, err)
+
+	// the delivered tipsets survive a restart
+	store, err = NewFileDeliveredStore(path, 10)
+	require.NoError(t, err)
+	dedup = NewTipsetDedup(store)
+
+	skipped, err = deliver(DeliveredTipset{Height: 100, TipsetCid: "bafy100"})
+	require.NoError(t, err)
+	require.True(t, skipped)
+	skipped, err = deliver(DeliveredTipset{Height: 101, TipsetCid: "bafy101"})
+	require.NoError(t, err)
+	require.False(t, skipped)
+	// a reorged tipset at the same height is delivered
+	skipped, err = deliver(DeliveredTipset{Height: 101, TipsetCid: "bafy101-reorg"})
+	require.NoError(t, err)
+	require.False(t, skipped)
+	require.Equal(t, []string{"bafy100", "bafy101", "bafy101-reorg"}, delivered)
+
+	// tipsets out of the retention are forgotten
+	require.NoError(t, store.MarkDelivered(DeliveredTipset{Height: 111, TipsetCid: "bafy111"}))
+	ok, err := store.IsDelivered("bafy100")
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = store.IsDelivered("bafy101")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
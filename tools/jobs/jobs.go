@@ -0,0 +1,246 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	logger2 "github.com/zondax/fil-parser/logger"
+	"github.com/zondax/fil-parser/tools/sink"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultRetryDelay = 5 * time.Second
+)
+
+var (
+	ErrInvalidRange      = errors.New("invalid height range")
+	ErrNilHeightFunc     = errors.New("height func is nil")
+	ErrMaxRetriesReached = errors.New("max retries reached")
+)
+
+// HeightFunc processes a single height (download, parse, store...)
+type HeightFunc func(ctx context.Context, height uint64) error
+
+type Config struct {
+	// From is the first height to process
+	From uint64
+	// To is the last height to process (inclusive)
+	To uint64
+	// MaxRetries is the amount of attempts per height before the job fails, the first one included, i.e. a height
+	// is retried MaxRetries-1 times. Zero means DefaultMaxRetries
+	MaxRetries int
+	// RetryDelay is the time to wait between attempts
+	RetryDelay time.Duration
+	// Quarantine is optional. When set, heights that exhaust their retries are quarantined and the job
+	// moves on to the next height instead of failing. Quarantined heights are skipped on later runs.
+	Quarantine Quarantine
+	// RetryQuarantined processes the quarantined heights again instead of skipping them
+	RetryQuarantined bool
+	// ParserVersion is stored along the quarantined heights. Heights quarantined by a different
+	// parser version are always retried, as the new version may be able to process them.
+	ParserVersion string
+}
+
+// RangeJob processes a range of heights sequentially, storing a checkpoint after every
+// completed height so the job can be resumed after a restart.
+type RangeJob struct {
+	config       Config
+	fn           HeightFunc
+	checkpointer Checkpointer
+	logger       *zap.Logger
+}
+
+func NewRangeJob(config Config, fn HeightFunc, checkpointer Checkpointer, logger *zap.Logger) (*RangeJob, error) {
+	if config.From > config.To {
+		return nil, fmt.Errorf("%w: from %d is greater than to %d", ErrInvalidRange, config.From, config.To)
+	}
+	if fn == nil {
+		return nil, ErrNilHeightFunc
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultRetryDelay
+	}
+
+	return &RangeJob{
+		config:       config,
+		fn:           fn,
+		checkpointer: checkpointer,
+		logger:       logger2.GetSafeLogger(logger),
+	}, nil
+}
+
+// Run processes every pending height of the range. It returns as soon as a height exhausts its retries
+// (unless a quarantine is configured) or the context is cancelled; calling Run again resumes from the
+// last completed height.
+func (j *RangeJob) Run(ctx context.Context) error {
+	start, completed, err := j.startHeight()
+	if err != nil || completed {
+		return err
+	}
+
+	for height := start; height <= j.config.To; height++ {
+		if err = j.processQuarantinedHeight(ctx, height); err != nil {
+			return err
+		}
+
+		if j.checkpointer != nil {
+			err = j.checkpointer.Save(&Checkpoint{
+				From:                j.config.From,
+				To:                  j.config.To,
+				LastCompletedHeight: height,
+			})
+			if err != nil {
+				return fmt.Errorf("could not save checkpoint for height %d: %w", height, err)
+			}
+		}
+
+		// avoid overflow when the range ends at the max height
+		if height == j.config.To {
+			break
+		}
+	}
+
+	return nil
+}
+
+// RunQuarantined processes again the quarantined heights of the range, even if they are behind the checkpoint.
+// Heights processed successfully are removed from the quarantine; the rest stay quarantined.
+func (j *RangeJob) RunQuarantined(ctx context.Context) error {
+	if j.config.Quarantine == nil {
+		return nil
+	}
+
+	entries, err := j.config.Quarantine.List()
+	if err != nil {
+		return fmt.Errorf("could not list quarantined heights: %w", err)
+	}
+
+	retry := j.config
+	retry.RetryQuarantined = true
+	quarantined := &RangeJob{config: retry, fn: j.fn, logger: j.logger}
+	for _, entry := range entries {
+		if entry.Height < j.config.From || entry.Height > j.config.To {
+			continue
+		}
+		if err = quarantined.processQuarantinedHeight(ctx, entry.Height); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startHeight returns the first height pending to be processed and whether the whole range was already completed
+func (j *RangeJob) startHeight() (uint64, bool, error) {
+	if j.checkpointer == nil {
+		return j.config.From, false, nil
+	}
+
+	checkpoint, err := j.checkpointer.Load()
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Only resume from checkpoints of a range starting at the same height, so no height is skipped
+	if checkpoint == nil || checkpoint.From != j.config.From || checkpoint.LastCompletedHeight < j.config.From ||
+		checkpoint.LastCompletedHeight > j.config.To {
+		return j.config.From, false, nil
+	}
+
+	if checkpoint.LastCompletedHeight == j.config.To {
+		j.logger.Sugar().Infof("[jobs] - range [%d, %d] already completed", j.config.From, j.config.To)
+		return 0, true, nil
+	}
+
+	j.logger.Sugar().Infof("[jobs] - resuming range [%d, %d] from height %d", j.config.From, j.config.To, checkpoint.LastCompletedHeight+1)
+	return checkpoint.LastCompletedHeight + 1, false, nil
+}
+
+// processQuarantinedHeight processes the height, consulting the quarantine if there is one
+func (j *RangeJob) processQuarantinedHeight(ctx context.Context, height uint64) error {
+	if j.config.Quarantine == nil {
+		return j.processHeight(ctx, height)
+	}
+
+	entry, err := j.config.Quarantine.Get(height)
+	if err != nil {
+		return fmt.Errorf("could not get quarantined height %d: %w", height, err)
+	}
+
+	if entry != nil && !j.config.RetryQuarantined && entry.ParserVersion == j.config.ParserVersion {
+		j.logger.Sugar().Warnf("[jobs] - skipping quarantined height %d: %s", height, entry.Error)
+		return nil
+	}
+
+	err = j.processHeight(ctx, height)
+	switch {
+	case err == nil:
+		if entry == nil {
+			return nil
+		}
+		return j.config.Quarantine.Remove(height)
+	case ctx.Err() != nil:
+		return err
+	}
+
+	j.logger.Sugar().Errorf("[jobs] - quarantining height %d: %s", height, err)
+	qErr := j.config.Quarantine.Add(QuarantinedHeight{
+		Height:        height,
+		Error:         err.Error(),
+		ParserVersion: j.config.ParserVersion,
+		FailedAt:      time.Now(),
+	})
+	if qErr != nil {
+		return fmt.Errorf("could not quarantine height %d: %w", height, qErr)
+	}
+	return nil
+}
+
+func (j *RangeJob) processHeight(ctx context.Context, height uint64) error {
+	var err error
+	for attempt := 1; attempt <= j.config.MaxRetries; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err = j.fn(ctx, height); err == nil {
+			return nil
+		}
+
+		j.logger.Sugar().Errorf("[jobs] - error processing height %d (attempt %d/%d): %s", height, attempt, j.config.MaxRetries, err)
+		if attempt == j.config.MaxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(j.config.RetryDelay):
+		}
+	}
+
+	return fmt.Errorf("%w for height %d: %w", ErrMaxRetriesReached, height, err)
+}
+
+// ParseFunc parses a single height
+type ParseFunc func(ctx context.Context, height uint64) (*types.TxsParsedResult, error)
+
+// NewSinkHeightFunc streams the result of every parsed height into the sink. The sink is flushed
+// before the height is reported as completed, so checkpoints never get ahead of the persisted output.
+func NewSinkHeightFunc(parse ParseFunc, txSink sink.TxSink) HeightFunc {
+	return func(ctx context.Context, height uint64) error {
+		result, err := parse(ctx, height)
+		if err != nil {
+			return err
+		}
+		return sink.WriteParsedResult(ctx, txSink, result)
+	}
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/tools/sink"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestRangeJob_Run(t *testing.T) {
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	var processed []uint64
+	failAt := uint64(13)
+	fn := func(_ context.Context, height uint64) error {
+		if height == failAt {
+			return errors.New("node unavailable")
+		}
+		processed = append(processed, height)
+		return nil
+	}
+
+	job, err := NewRangeJob(Config{From: 10, To: 15, MaxRetries: 2, RetryDelay: time.Millisecond}, fn, checkpointer, nil)
+	require.NoError(t, err)
+
+	err = job.Run(context.Background())
+	require.ErrorIs(t, err, ErrMaxRetriesReached)
+	require.Equal(t, []uint64{10, 11, 12}, processed)
+
+	checkpoint, err := checkpointer.Load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), checkpoint.LastCompletedHeight)
+
+	// resume
+	failAt = 0
+	processed = nil
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, []uint64{13, 14, 15}, processed)
+
+	// already completed
+	processed = nil
+	require.NoError(t, job.Run(context.Background()))
+	require.Empty(t, processed)
+}
+
+func TestRangeJob_Retries(t *testing.T) {
+	attempts := 0
+	fn := func(_ context.Context, _ uint64) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary error")
+		}
+		return nil
+	}
+
+	job, err := NewRangeJob(Config{From: 1, To: 1, MaxRetries: 3, RetryDelay: time.Millisecond}, fn, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, 3, attempts)
+}
+
+func TestNewRangeJob_InvalidRange(t *testing.T) {
+	_, err := NewRangeJob(Config{From: 10, To: 1}, func(_ context.Context, _ uint64) error { return nil }, nil, nil)
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestRangeJob_Sink(t *testing.T) {
+	txSink := sink.NewChannelSink(3)
+	parse := func(_ context.Context, height uint64) (*types.TxsParsedResult, error) {
+		return &types.TxsParsedResult{Txs: []*types.Transaction{{TxBasicBlockData: types.TxBasicBlockData{BasicBlockData: types.BasicBlockData{Height: height}}}}}, nil
+	}
+
+	job, err := NewRangeJob(Config{From: 1, To: 3}, NewSinkHeightFunc(parse, txSink), nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, job.Run(context.Background()))
+	txSink.Close()
+
+	var heights []uint64
+	for txs := range txSink.Transactions() {
+		heights = append(heights, txs[0].Height)
+	}
+	require.Equal(t, []uint64{1, 2, 3}, heights)
+}
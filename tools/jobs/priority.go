@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// PriorityJob processes the most recent pending height first, so the data near the head is available quickly
+// while the older ranges are backfilled when there are no recent heights left. New ranges (e.g. new heads)
+// can be added while the job is running and they are picked up before the backfill continues.
+//
+// As heights are not processed in order, the checkpoint stores the pending ranges instead of the last completed
+// height. It is saved after every processed height, so the job resumes with the same pending heights after a
+// restart, the ranges added with Add included. Without checkpointer the pending ranges are only kept in memory.
+// Retries and quarantine work as in RangeJob.
+type PriorityJob struct {
+	job          *RangeJob
+	checkpointer Checkpointer
+	mu           sync.Mutex
+	pending      []HeightRange // sorted ASC and without overlaps
+}
+
+// NewPriorityJob creates the job with the [From, To] range of the config pending, or with the pending ranges of
+// the checkpoint if it was saved by a PriorityJob of the same range. The checkpointer is optional.
+func NewPriorityJob(config Config, fn HeightFunc, checkpointer Checkpointer, logger *zap.Logger) (*PriorityJob, error) {
+	job, err := NewRangeJob(config, fn, nil, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PriorityJob{job: job, checkpointer: checkpointer}
+	if checkpointer != nil {
+		checkpoint, err := checkpointer.Load()
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil && checkpoint.Priority && checkpoint.From == config.From && checkpoint.To == config.To {
+			job.logger.Sugar().Infof("[jobs] - resuming range [%d, %d] with %d pending ranges", config.From, config.To, len(checkpoint.Pending))
+			for _, r := range checkpoint.Pending {
+				p.addRange(r.From, r.To)
+			}
+			return p, nil
+		}
+	}
+
+	p.addRange(config.From, config.To)
+	return p, nil
+}
+
+// Add adds the range to the pending heights. Heights already pending are not duplicated.
+func (p *PriorityJob) Add(from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("%w: from %d is greater than to %d", ErrInvalidRange, from, to)
+	}
+	p.addRange(from, to)
+	return nil
+}
+
+// Pending returns the amount of heights pending to be processed
+func (p *PriorityJob) Pending() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var total uint64
+	for _, r := range p.pending {
+		total += r.To - r.From + 1
+	}
+	return total
+}
+
+// Run processes the pending heights, the most recent first, until there are none left. It returns as soon as
+// a height exhausts its retries (unless a quarantine is configured) or the context is cancelled; the failed
+// height stays pending so calling Run again retries it.
+func (p *PriorityJob) Run(ctx context.Context) error {
+	for {
+		height, ok := p.next()
+		if !ok {
+			return nil
+		}
+
+		if err := p.job.processQuarantinedHeight(ctx, height); err != nil {
+			p.addRange(height, height)
+			return err
+		}
+
+		if err := p.save(height); err != nil {
+			return fmt.Errorf("could not save checkpoint for height %d: %w", height, err)
+		}
+	}
+}
+
+// save stores the pending ranges, if there is a checkpointer
+func (p *PriorityJob) save(lastCompletedHeight uint64) error {
+	if p.checkpointer == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	pending := slices.Clone(p.pending)
+	p.mu.Unlock()
+
+	return p.checkpointer.Save(&Checkpoint{
+		From:                p.job.config.From,
+		To:                  p.job.config.To,
+		LastCompletedHeight: lastCompletedHeight,
+		Priority:            true,
+		Pending:             pending,
+	})
+}
+
+// next pops the most recent pending height
+func (p *PriorityJob) next() (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.pending) == 0 {
+		return 0, false
+	}
+
+	last := &p.pending[len(p.pending)-1]
+	height := last.To
+	if last.From == last.To {
+		p.pending = p.pending[:len(p.pending)-1]
+	} else {
+		last.To--
+	}
+	return height, true
+}
+
+func (p *PriorityJob) addRange(from, to uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = append(p.pending, HeightRange{From: from, To: to})
+	sort.Slice(p.pending, func(i, j int) bool {
+		return p.pending[i].From < p.pending[j].From
+	})
+
+	// merge the overlapping and adjacent ranges
+	merged := p.pending[:1]
+	for _, r := range p.pending[1:] {
+		last := &merged[len(merged)-1]
+		if r.From <= last.To || r.From-last.To == 1 {
+			if r.To > last.To {
+				last.To = r.To
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	p.pending = merged
+}
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityJob_RecentFirst(t *testing.T) {
+	var processed []uint64
+	var job *PriorityJob
+	fn := func(_ context.Context, height uint64) error {
+		processed = append(processed, height)
+		// a new head shows up while backfilling
+		if height == 8 {
+			require.NoError(t, job.Add(12, 13))
+		}
+		return nil
+	}
+
+	job, err := NewPriorityJob(Config{From: 5, To: 10, MaxRetries: 1, RetryDelay: time.Millisecond}, fn, nil, nil)
+	require.NoError(t, err)
+	// overlapping ranges are not processed twice
+	require.NoError(t, job.Add(1, 6))
+	require.Equal(t, uint64(10), job.Pending())
+
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, []uint64{10, 9, 8, 13, 12, 7, 6, 5, 4, 3, 2, 1}, processed)
+	require.Zero(t, job.Pending())
+}
+
+func TestPriorityJob_FailedHeightStaysPending(t *testing.T) {
+	failAt := uint64(2)
+	var processed []uint64
+	fn := func(_ context.Context, height uint64) error {
+		if height == failAt {
+			return errors.New("node unavailable")
+		}
+		processed = append(processed, height)
+		return nil
+	}
+
+	job, err := NewPriorityJob(Config{From: 1, To: 3, MaxRetries: 1, RetryDelay: time.Millisecond}, fn, nil, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, job.Run(context.Background()), ErrMaxRetriesReached)
+	require.Equal(t, []uint64{3}, processed)
+	require.Equal(t, uint64(2), job.Pending())
+
+	failAt = 0
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, []uint64{3, 2, 1}, processed)
+
+	require.ErrorIs(t, job.Add(5, 4), ErrInvalidRange)
+}
+
+func TestPriorityJob_Checkpoint(t *testing.T) {
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))
+	config := Config{From: 1, To: 5, MaxRetries: 1, RetryDelay: time.Millisecond}
+	failAt := uint64(3)
+	var processed []uint64
+	fn := func(_ context.Context, height uint64) error {
+		if height == failAt {
+			return errors.New("node unavailable")
+		}
+		processed = append(processed, height)
+		return nil
+	}
+
+	job, err := NewPriorityJob(config, fn, checkpointer, nil)
+	require.NoError(t, err)
+	require.NoError(t, job.Add(8, 9))
+	require.ErrorIs(t, job.Run(context.Background()), ErrMaxRetriesReached)
+	require.Equal(t, []uint64{9, 8, 5, 4}, processed)
+
+	// a new job of the same range resumes with the pending heights, the failed one included
+	failAt = 0
+	resumed, err := NewPriorityJob(config, fn, checkpointer, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), resumed.Pending())
+	require.NoError(t, resumed.Run(context.Background()))
+	require.Equal(t, []uint64{9, 8, 5, 4, 3, 2, 1}, processed)
+
+	completed, err := NewPriorityJob(config, fn, checkpointer, nil)
+	require.NoError(t, err)
+	require.Zero(t, completed.Pending())
+
+	// the checkpoints of other ranges are ignored
+	other, err := NewPriorityJob(Config{From: 1, To: 6}, fn, checkpointer, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), other.Pending())
+}
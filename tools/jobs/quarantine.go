@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QuarantinedHeight is a height that failed after exhausting its retries
+type QuarantinedHeight struct {
+	Height uint64 `json:"height"`
+	// Error is the last error returned while processing the height
+	Error string `json:"error"`
+	// ParserVersion is the version of the parser that failed to process the height
+	ParserVersion string    `json:"parser_version"`
+	FailedAt      time.Time `json:"failed_at"`
+	// Failures is the amount of runs in which the height failed
+	Failures int `json:"failures"`
+}
+
+// Quarantine keeps track of the heights that failed, so range jobs can skip them and retry them later
+type Quarantine interface {
+	// Get returns the quarantined height, or nil if the height is not quarantined
+	Get(height uint64) (*QuarantinedHeight, error)
+	Add(entry QuarantinedHeight) error
+	Remove(height uint64) error
+	// List returns the quarantined heights sorted ASC
+	List() ([]QuarantinedHeight, error)
+}
+
+// FileQuarantine stores the quarantined heights as a json file on disk
+type FileQuarantine struct {
+	path    string
+	mu      sync.Mutex
+	entries map[uint64]QuarantinedHeight
+}
+
+func NewFileQuarantine(path string) (*FileQuarantine, error) {
+	q := &FileQuarantine{path: path, entries: make(map[uint64]QuarantinedHeight)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return q, nil
+		}
+		return nil, fmt.Errorf("could not read quarantine file %s: %w", path, err)
+	}
+
+	var entries []QuarantinedHeight
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("could not decode quarantine file %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		q.entries[entry.Height] = entry
+	}
+
+	return q, nil
+}
+
+func (q *FileQuarantine) Get(height uint64) (*QuarantinedHeight, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[height]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Add stores the entry, increasing the failures if the height was already quarantined
+func (q *FileQuarantine) Add(entry QuarantinedHeight) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if entry.Failures <= 0 {
+		entry.Failures = 1
+	}
+	if previous, ok := q.entries[entry.Height]; ok {
+		entry.Failures += previous.Failures
+	}
+	q.entries[entry.Height] = entry
+	return q.save()
+}
+
+func (q *FileQuarantine) Remove(height uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.entries[height]; !ok {
+		return nil
+	}
+	delete(q.entries, height)
+	return q.save()
+}
+
+func (q *FileQuarantine) List() ([]QuarantinedHeight, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.sortedEntries(), nil
+}
+
+func (q *FileQuarantine) sortedEntries() []QuarantinedHeight {
+	entries := make([]QuarantinedHeight, 0, len(q.entries))
+	for _, entry := range q.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Height < entries[j].Height
+	})
+	return entries
+}
+
+// save writes the entries to a temporary file and renames it, same as FileCheckpointer.Save
+func (q *FileQuarantine) save() error {
+	data, err := json.Marshal(q.sortedEntries())
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary quarantine file: %w", err)
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("could not write quarantine file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), q.path)
+}
@@ -0,0 +1,103 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileQuarantine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+	quarantine, err := NewFileQuarantine(path)
+	require.NoError(t, err)
+
+	entry, err := quarantine.Get(10)
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	require.NoError(t, quarantine.Add(QuarantinedHeight{Height: 20, Error: "boom", ParserVersion: "v2"}))
+	require.NoError(t, quarantine.Add(QuarantinedHeight{Height: 10, Error: "boom", ParserVersion: "v2"}))
+	require.NoError(t, quarantine.Add(QuarantinedHeight{Height: 10, Error: "boom again", ParserVersion: "v2"}))
+
+	// entries are persisted
+	quarantine, err = NewFileQuarantine(path)
+	require.NoError(t, err)
+	entries, err := quarantine.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(10), entries[0].Height)
+	require.Equal(t, "boom again", entries[0].Error)
+	require.Equal(t, 2, entries[0].Failures)
+
+	require.NoError(t, quarantine.Remove(10))
+	entry, err = quarantine.Get(10)
+	require.NoError(t, err)
+	require.Nil(t, entry)
+}
+
+func TestRangeJob_Quarantine(t *testing.T) {
+	quarantine, err := NewFileQuarantine(filepath.Join(t.TempDir(), "quarantine.json"))
+	require.NoError(t, err)
+
+	var processed []uint64
+	failAt := uint64(2)
+	fn := func(_ context.Context, height uint64) error {
+		if height == failAt {
+			return errors.New("pathological height")
+		}
+		processed = append(processed, height)
+		return nil
+	}
+
+	config := Config{From: 1, To: 3, MaxRetries: 1, RetryDelay: time.Millisecond, Quarantine: quarantine, ParserVersion: "v2"}
+	job, err := NewRangeJob(config, fn, nil, nil)
+	require.NoError(t, err)
+
+	// the failed height does not stall the job
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, []uint64{1, 3}, processed)
+	entry, err := quarantine.Get(2)
+	require.NoError(t, err)
+	require.Equal(t, "v2", entry.ParserVersion)
+
+	// quarantined heights are skipped
+	failAt = 0
+	processed = nil
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, []uint64{1, 3}, processed)
+
+	// a new parser version retries them
+	config.ParserVersion = "v3"
+	job, err = NewRangeJob(config, fn, nil, nil)
+	require.NoError(t, err)
+	processed = nil
+	require.NoError(t, job.Run(context.Background()))
+	require.Equal(t, []uint64{1, 2, 3}, processed)
+
+	entry, err = quarantine.Get(2)
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	// quarantined heights behind the checkpoint are retried by RunQuarantined
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))
+	config.Quarantine, config.ParserVersion = quarantine, "v3"
+	failAt = 2
+	job, err = NewRangeJob(config, fn, checkpointer, nil)
+	require.NoError(t, err)
+	require.NoError(t, job.Run(context.Background()))
+
+	failAt = 0
+	processed = nil
+	require.NoError(t, job.Run(context.Background()))
+	require.Empty(t, processed)
+	require.NoError(t, job.RunQuarantined(context.Background()))
+	require.Equal(t, []uint64{2}, processed)
+
+	entries, err := quarantine.List()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// FileTraceCheckpointer stores the checkpoints of the tipsets being parsed as json files in a directory, with a
+// directory per parser version and tipset holding a file per checkpoint. See types.TxsData.Checkpointer.
+type FileTraceCheckpointer struct {
+	dir string
+}
+
+func NewFileTraceCheckpointer(dir string) (*FileTraceCheckpointer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create checkpoints dir %s: %w", dir, err)
+	}
+	return &FileTraceCheckpointer{dir: dir}, nil
+}
+
+func (f *FileTraceCheckpointer) Load(key types.TraceCheckpointKey) ([]*types.TraceCheckpoint, error) {
+	dir := f.path(key)
+	// the entries are sorted by name, which is the order the checkpoints were saved in
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not read checkpoints dir %s: %w", dir, err)
+	}
+
+	var checkpoints []*types.TraceCheckpoint
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read checkpoint file %s: %w", path, err)
+		}
+
+		var checkpoint types.TraceCheckpoint
+		if err = json.Unmarshal(data, &checkpoint); err != nil {
+			return nil, fmt.Errorf("could not decode checkpoint file %s: %w", path, err)
+		}
+		checkpoints = append(checkpoints, &checkpoint)
+	}
+
+	return checkpoints, nil
+}
+
+func (f *FileTraceCheckpointer) Save(checkpoint *types.TraceCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	dir := f.path(checkpoint.Key())
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("could not create checkpoints dir %s: %w", dir, err)
+	}
+	return writeFileAtomic(filepath.Join(dir, fmt.Sprintf("%010d.json", checkpoint.NextTrace)), data)
+}
+
+func (f *FileTraceCheckpointer) Delete(key types.TraceCheckpointKey) error {
+	return os.RemoveAll(f.path(key))
+}
+
+func (f *FileTraceCheckpointer) path(key types.TraceCheckpointKey) string {
+	return filepath.Join(f.dir, key.ParserVersion, key.TipsetCid)
+}
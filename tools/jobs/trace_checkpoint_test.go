@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestFileTraceCheckpointer(t *testing.T) {
+	checkpointer, err := NewFileTraceCheckpointer(filepath.Join(t.TempDir(), "traces"))
+	require.NoError(t, err)
+
+	key := types.TraceCheckpointKey{ParserVersion: "v2", TipsetCid: "bafytipset"}
+	checkpoints, err := checkpointer.Load(key)
+	require.NoError(t, err)
+	require.Empty(t, checkpoints)
+
+	want := &types.TraceCheckpoint{
+		ParserVersion: "v2",
+		TipsetCid:     "bafytipset",
+		NextTrace:     500,
+		Txs:           []*types.Transaction{{Id: "tx-1", Amount: big.NewInt(10)}},
+		Addresses:     map[string]*types.AddressInfo{"f01000": {Short: "f01000", Robust: "f1robust"}},
+		TxCids:        []types.TxCidTranslation{{TxCid: "bafytx", TxHash: "0x01"}},
+	}
+	require.NoError(t, checkpointer.Save(want))
+	next := &types.TraceCheckpoint{ParserVersion: "v2", TipsetCid: "bafytipset", NextTrace: 1000, Txs: []*types.Transaction{{Id: "tx-2"}}}
+	require.NoError(t, checkpointer.Save(next))
+
+	// the checkpoints of other parser versions are kept apart
+	other, err := checkpointer.Load(types.TraceCheckpointKey{ParserVersion: "v1", TipsetCid: "bafytipset"})
+	require.NoError(t, err)
+	require.Empty(t, other)
+
+	checkpoints, err = checkpointer.Load(key)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	checkpoint := checkpoints[0]
+	require.Equal(t, next.NextTrace, checkpoints[1].NextTrace)
+	require.Equal(t, next.Txs[0].Id, checkpoints[1].Txs[0].Id)
+	require.Equal(t, want.NextTrace, checkpoint.NextTrace)
+	require.Equal(t, want.Txs[0].Id, checkpoint.Txs[0].Id)
+	require.Equal(t, want.Txs[0].Amount, checkpoint.Txs[0].Amount)
+	require.Equal(t, want.Addresses, checkpoint.Addresses)
+	require.Equal(t, want.TxCids, checkpoint.TxCids)
+
+	require.NoError(t, checkpointer.Delete(key))
+	require.NoError(t, checkpointer.Delete(key))
+	checkpoints, err = checkpointer.Load(key)
+	require.NoError(t, err)
+	require.Empty(t, checkpoints)
+}
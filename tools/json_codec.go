@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"encoding/json"
+
+	"github.com/bytedance/sonic"
+	"github.com/zondax/fil-parser/parser"
+)
+
+// sonicStd is sonic with the encoding/json behaviour (strings copied, sorted map keys...), so the output is
+// byte-identical whichever codec, or architecture, the traces are decoded with. Strings are not validated, as
+// sonic would reject the invalid utf8 that encoding/json replaces with U+FFFD.
+var sonicStd = sonic.Config{
+	EscapeHTML:       true,
+	SortMapKeys:      true,
+	CompactMarshaler: true,
+	CopyString:       true,
+}.Froze()
+
+// UnmarshalJSON decodes the data with the given codec (see the parser.JSONCodec constants), sonic if it is empty
+func UnmarshalJSON(codec string, data []byte, v interface{}) error {
+	if codec == parser.JSONCodecStd {
+		return json.Unmarshal(data, v)
+	}
+	return sonicStd.Unmarshal(data, v)
+}
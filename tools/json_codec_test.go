@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	typesV1 "github.com/zondax/fil-parser/parser/v1/types"
+	typesV2 "github.com/zondax/fil-parser/parser/v2/types"
+)
+
+func readGzFixture(t *testing.T, path string) []byte {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+// decodeTraces decodes the traces with the codec and encodes them back, so the decoded values can be compared
+func decodeTraces(t *testing.T, codec string, rawTraces []byte) []byte {
+	var computeState interface{} = &typesV2.ComputeStateOutputV2{}
+	if parser.DetectTraceFormat(rawTraces) == parser.TraceFormatV1 {
+		computeState = &typesV1.ComputeStateOutputV1{}
+	}
+	require.NoError(t, UnmarshalJSON(codec, rawTraces, computeState))
+
+	encoded, err := json.Marshal(computeState)
+	require.NoError(t, err)
+	return encoded
+}
+
+// TestUnmarshalJSON_Codecs checks that the traces fixtures decode to the same values with every codec, as
+// outputs parsed by heterogeneous fleets are cross validated
+func TestUnmarshalJSON_Codecs(t *testing.T) {
+	files, err := filepath.Glob("../data/heights/traces_*.json.gz")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			rawTraces := readGzFixture(t, file)
+			sonicTraces := decodeTraces(t, parser.JSONCodecSonic, rawTraces)
+			stdTraces := decodeTraces(t, parser.JSONCodecStd, rawTraces)
+			require.True(t, bytes.Equal(sonicTraces, stdTraces))
+		})
+	}
+}
+
+func TestUnmarshalJSON_InvalidUTF8(t *testing.T) {
+	// invalid utf8 is replaced the same way by both codecs
+	data := []byte("{\"Error\":\"bad \xff byte\"}")
+	var sonicValue, stdValue struct{ Error string }
+	require.NoError(t, UnmarshalJSON(parser.JSONCodecSonic, data, &sonicValue))
+	require.NoError(t, UnmarshalJSON(parser.JSONCodecStd, data, &stdValue))
+	require.Equal(t, stdValue, sonicValue)
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/manifest"
-	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/blockstore"
 	"github.com/filecoin-project/lotus/chain/actors/adt"
 	"github.com/filecoin-project/lotus/chain/actors/builtin/multisig"
@@ -64,6 +63,11 @@ func NewEventGenerator(helper *helper.Helper, logger *zap.Logger) EventGenerator
 	}
 }
 
+// ids returns the builder of the ids of the events, with the hash scheme of the parser
+func (eg *eventGenerator) ids() tools.IdBuilder {
+	return tools.NewIdBuilder(eg.helper.GetConfig().IdHashScheme)
+}
+
 func (eg *eventGenerator) GenerateMultisigEvents(ctx context.Context, transactions []*types.Transaction, tipsetCid string, tipsetKey filTypes.TipSetKey) (*types.MultisigEvents, error) {
 	events := &types.MultisigEvents{
 		Proposals:    []*types.MultisigProposal{},
@@ -76,7 +80,12 @@ func (eg *eventGenerator) GenerateMultisigEvents(ctx context.Context, transactio
 			continue
 		}
 
-		metadata, err := tools.ParseTxMetadata(tx.TxMetadata)
+		rawMetadata, err := tx.GetMetadata()
+		if err != nil {
+			return nil, err
+		}
+
+		metadata, err := tools.ParseTxMetadata(rawMetadata)
 		if err != nil {
 			return nil, err
 		}
@@ -95,7 +104,7 @@ func (eg *eventGenerator) GenerateMultisigEvents(ctx context.Context, transactio
 				continue
 			}
 
-			actorName, err := eg.helper.GetActorNameFromAddress(addrTo, int64(tx.Height), tipsetKey)
+			actorName, err := eg.helper.GetActorNameFromAddressWithContext(ctx, addrTo, int64(tx.Height), tipsetKey)
 			if err != nil {
 				eg.logger.Sugar().Errorf("could not get actor name from address. Err: %s", err)
 				continue
@@ -131,7 +140,7 @@ func (eg *eventGenerator) createProposal(ctx context.Context, tx *types.Transact
 		}
 	}
 
-	proposal.ID = tools.BuildId(tipsetCid, tx.TxCid, proposal.Signer, proposal.MultisigAddress, fmt.Sprint(proposal.ProposalID), fmt.Sprint(tx.Height), tx.TxType)
+	proposal.ID = eg.ids().BuildId(tipsetCid, tx.TxCid, proposal.Signer, proposal.MultisigAddress, fmt.Sprint(proposal.ProposalID), fmt.Sprint(tx.Height), tx.TxType)
 	return proposal
 }
 
@@ -197,10 +206,15 @@ func (eg *eventGenerator) processNestedParams(ctx context.Context, params map[st
 }
 
 func (eg *eventGenerator) createMultisigInfo(ctx context.Context, tx *types.Transaction, tipsetCid string) (*types.MultisigInfo, error) {
-	value, err := actors.ParseMultisigMetadata(tx.TxType, tx.TxMetadata)
+	rawMetadata, err := tx.GetMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := actors.ParseMultisigMetadata(tx.TxType, rawMetadata)
 	if err != nil {
 		eg.logger.Sugar().Error(ctx, fmt.Sprintf("Multisig error parsing metadata: %s", err.Error()))
-		value = tx.TxMetadata // if there is an error then we need to store the raw metadata
+		value = rawMetadata // if there is an error then we need to store the raw metadata
 	}
 
 	b, err := json.Marshal(value)
@@ -210,7 +224,7 @@ func (eg *eventGenerator) createMultisigInfo(ctx context.Context, tx *types.Tran
 	}
 
 	return &types.MultisigInfo{
-		ID:              tools.BuildId(tipsetCid, tx.TxTo, fmt.Sprint(tx.Height), tx.TxCid, tx.TxType),
+		ID:              eg.ids().BuildId(tipsetCid, tx.TxTo, fmt.Sprint(tx.Height), tx.TxCid, tx.TxType),
 		MultisigAddress: tx.TxTo,
 		Height:          tx.Height,
 		TxCid:           tx.TxCid,
@@ -250,10 +264,10 @@ Ref: https://github.com/filecoin-project/lotus/blob/2714a84248095f877f52ce20e737
 		"StartEpoch":           147120
 	}
 */
-func GenerateGenesisMultisigData(ctx context.Context, api api.FullNode, addr address.Address, genesisTipset *types.ExtendedTipSet) (map[string]any, error) {
-	store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(api)))
+func GenerateGenesisMultisigData(ctx context.Context, node types.FullNode, addr address.Address, genesisTipset *types.ExtendedTipSet) (map[string]any, error) {
+	store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(node)))
 
-	act, err := api.StateGetActor(ctx, addr, genesisTipset.Key())
+	act, err := node.StateGetActor(ctx, addr, genesisTipset.Key())
 	if err != nil {
 		return nil, fmt.Errorf("api.StateGetActor(): %s", err)
 	}
@@ -270,7 +284,7 @@ func GenerateGenesisMultisigData(ctx context.Context, api api.FullNode, addr add
 
 	var signerActors []string
 	for _, s := range signers {
-		signerActor, err := api.StateAccountKey(ctx, s, filTypes.EmptyTSK)
+		signerActor, err := node.StateAccountKey(ctx, s, filTypes.EmptyTSK)
 		if err != nil {
 			return nil, fmt.Errorf("api.StateAccountKey(): %s", err)
 		}
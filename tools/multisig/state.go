@@ -0,0 +1,109 @@
+package multisig
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/multisig"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+)
+
+// GetMultisigState reads the signers, threshold and pending transactions of the multisig at the tipset.
+// Signers and approvers are returned as robust addresses when they can be resolved.
+func GetMultisigState(ctx context.Context, helper *helper.Helper, addr address.Address, tipset *types.ExtendedTipSet) (*types.MultisigState, error) {
+	node := helper.GetFilecoinNodeClient()
+	store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(node)))
+
+	act, err := node.StateGetActor(ctx, addr, tipset.Key())
+	if err != nil {
+		return nil, fmt.Errorf("api.StateGetActor(): %s", err)
+	}
+
+	mstate, err := multisig.Load(store, act)
+	if err != nil {
+		return nil, fmt.Errorf("multisig.Load(): %s", err)
+	}
+
+	signers, err := mstate.Signers()
+	if err != nil {
+		return nil, fmt.Errorf("mstate.Signers(): %s", err)
+	}
+	threshold, err := mstate.Threshold()
+	if err != nil {
+		return nil, fmt.Errorf("mstate.Threshold(): %s", err)
+	}
+	initialBalance, err := mstate.InitialBalance()
+	if err != nil {
+		return nil, fmt.Errorf("mstate.InitialBalance(): %s", err)
+	}
+	lockedBalance, err := mstate.LockedBalance(tipset.Height())
+	if err != nil {
+		return nil, fmt.Errorf("mstate.LockedBalance(): %s", err)
+	}
+	startEpoch, err := mstate.StartEpoch()
+	if err != nil {
+		return nil, fmt.Errorf("mstate.StartEpoch(): %s", err)
+	}
+	unlockDuration, err := mstate.UnlockDuration()
+	if err != nil {
+		return nil, fmt.Errorf("mstate.UnlockDuration(): %s", err)
+	}
+
+	state := &types.MultisigState{
+		MultisigAddress:     addr.String(),
+		Height:              uint64(tipset.Height()),
+		Signers:             robustAddresses(ctx, helper, signers),
+		Threshold:           threshold,
+		InitialBalance:      initialBalance.String(),
+		LockedBalance:       lockedBalance.String(),
+		StartEpoch:          int64(startEpoch),
+		UnlockDuration:      int64(unlockDuration),
+		PendingTransactions: []*types.MultisigPendingTxn{},
+	}
+
+	err = mstate.ForEachPendingTxn(func(id int64, txn multisig.Transaction) error {
+		methodName, err := helper.GetMethodNameWithContext(ctx, &parser.LotusMessage{To: txn.To, Method: txn.Method}, int64(tipset.Height()), tipset.Key())
+		if err != nil {
+			methodName = parser.UnknownStr
+		}
+
+		state.PendingTransactions = append(state.PendingTransactions, &types.MultisigPendingTxn{
+			ID:         id,
+			To:         txn.To.String(),
+			Value:      txn.Value.String(),
+			Method:     uint64(txn.Method),
+			MethodName: methodName,
+			Params:     base64.StdEncoding.EncodeToString(txn.Params),
+			Approved:   robustAddresses(ctx, helper, txn.Approved),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mstate.ForEachPendingTxn(): %s", err)
+	}
+
+	slices.SortFunc(state.PendingTransactions, func(a, b *types.MultisigPendingTxn) int {
+		return int(a.ID - b.ID)
+	})
+	return state, nil
+}
+
+func robustAddresses(ctx context.Context, helper *helper.Helper, addrs []address.Address) []string {
+	result := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		robust, err := helper.GetActorsCache().GetRobustAddressWithContext(ctx, addr)
+		if err != nil || robust == "" {
+			robust = addr.String()
+		}
+		result = append(result, robust)
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package receipts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types"
+)
+
+// Node is the subset of the node api needed to find the receipts root of a tipset
+type Node interface {
+	ChainGetTipSetAfterHeight(ctx context.Context, height abi.ChainEpoch, tsk filTypes.TipSetKey) (*filTypes.TipSet, error)
+}
+
+// FetchReceiptsRoot returns the root of the receipts AMT of the messages of the tipset, ready to be set on
+// TxsData.ReceiptsRoot. Receipts are committed by the next tipset (skipping null rounds), which must have the
+// tipset as parent and agree on the root in all its blocks.
+func FetchReceiptsRoot(ctx context.Context, node Node, tipset *types.ExtendedTipSet) (cid.Cid, error) {
+	next, err := node.ChainGetTipSetAfterHeight(ctx, tipset.Height()+1, filTypes.EmptyTSK)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("could not get the tipset after height %d: %w", tipset.Height(), err)
+	}
+	if next == nil || len(next.Blocks()) == 0 {
+		return cid.Undef, fmt.Errorf("tipset after height %d not found", tipset.Height())
+	}
+	if next.Parents() != tipset.Key() {
+		return cid.Undef, fmt.Errorf("tipset %d is not the parent of tipset %d", tipset.Height(), next.Height())
+	}
+
+	root := next.Blocks()[0].ParentMessageReceipts
+	for _, block := range next.Blocks()[1:] {
+		if block.ParentMessageReceipts != root {
+			return cid.Undef, fmt.Errorf("blocks of tipset %d disagree on the receipts root", next.Height())
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,71 @@
+package receipts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	parentsCid  = "bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e"
+	receiptsCid = "bafy2bzaceaa43et73tgxsoh2xizd4mxhbrcfig4kqp25zfa5scdgkzppllyuu"
+)
+
+type testNode struct {
+	next *filTypes.TipSet
+}
+
+func (n *testNode) ChainGetTipSetAfterHeight(_ context.Context, _ abi.ChainEpoch, _ filTypes.TipSetKey) (*filTypes.TipSet, error) {
+	return n.next, nil
+}
+
+func testTipset(t *testing.T, height abi.ChainEpoch, parents []cid.Cid, receipts ...cid.Cid) *filTypes.TipSet {
+	blocks := make([]*filTypes.BlockHeader, 0, len(receipts))
+	for i, root := range receipts {
+		miner, err := address.NewIDAddress(uint64(1000 + i))
+		require.NoError(t, err)
+		c := cid.MustParse(parentsCid)
+		blocks = append(blocks, &filTypes.BlockHeader{
+			Miner:                 miner,
+			Ticket:                &filTypes.Ticket{VRFProof: []byte{byte(i)}},
+			ElectionProof:         &filTypes.ElectionProof{WinCount: 1, VRFProof: []byte{byte(i)}},
+			Parents:               parents,
+			ParentWeight:          filTypes.NewInt(10),
+			Height:                height,
+			ParentStateRoot:       c,
+			ParentMessageReceipts: root,
+			Messages:              c,
+			BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+			BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+			Timestamp:             1000,
+			ParentBaseFee:         filTypes.NewInt(100),
+		})
+	}
+	tipset, err := filTypes.NewTipSet(blocks)
+	require.NoError(t, err)
+	return tipset
+}
+
+func TestFetchReceiptsRoot(t *testing.T) {
+	root := cid.MustParse(receiptsCid)
+	parent := testTipset(t, 100, []cid.Cid{cid.MustParse(parentsCid)}, root)
+	tipset := &types.ExtendedTipSet{TipSet: *parent}
+
+	// the next tipset is after a null round
+	got, err := FetchReceiptsRoot(context.Background(), &testNode{next: testTipset(t, 102, parent.Cids(), root, root)}, tipset)
+	require.NoError(t, err)
+	require.Equal(t, root, got)
+
+	_, err = FetchReceiptsRoot(context.Background(), &testNode{next: testTipset(t, 102, parent.Cids(), root, cid.MustParse(parentsCid))}, tipset)
+	require.ErrorContains(t, err, "disagree")
+
+	_, err = FetchReceiptsRoot(context.Background(), &testNode{next: testTipset(t, 101, []cid.Cid{root}, root)}, tipset)
+	require.ErrorContains(t, err, "not the parent")
+}
@@ -0,0 +1,79 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// ResultBatch is a sub-batch of the output of a tipset, along with its cursor
+type ResultBatch struct {
+	Cursor types.ParseCursor
+	Txs    []*types.Transaction
+	// Addresses are only set on the final sub-batch
+	Addresses *types.AddressInfoMap
+}
+
+// SplitParsedResult splits the txs of a parsed tipset into sub-batches of up to batchSize txs, in order. The split
+// only depends on the result, so the same tipset always has the same sub-batches. A tipset without txs has a
+// single empty sub-batch, so its delivery is recorded too. A batchSize of zero puts all the txs in one sub-batch.
+func SplitParsedResult(height uint64, tipsetCid string, result *types.TxsParsedResult, batchSize int) []ResultBatch {
+	var txs []*types.Transaction
+	var addresses *types.AddressInfoMap
+	if result != nil {
+		txs, addresses = result.Txs, result.Addresses
+	}
+	if batchSize <= 0 || batchSize > len(txs) {
+		batchSize = len(txs)
+	}
+
+	var batches []ResultBatch
+	for start := 0; start < len(txs) || len(batches) == 0; start += batchSize {
+		end := min(start+batchSize, len(txs))
+		batches = append(batches, ResultBatch{
+			Cursor: types.ParseCursor{Height: height, TipsetCid: tipsetCid, Batch: len(batches)},
+			Txs:    txs[start:end],
+		})
+		if batchSize == 0 {
+			break
+		}
+	}
+
+	last := &batches[len(batches)-1]
+	last.Cursor.Final = true
+	last.Addresses = addresses
+	return batches
+}
+
+// CommitFunc stores the cursor of a sub-batch once it is written to the sink. Sinks with transactions should
+// store it in the same transaction as the data, to get exactly-once delivery.
+type CommitFunc func(ctx context.Context, cursor types.ParseCursor) error
+
+// DeliverBatches writes and flushes the sub-batches not delivered yet, skipping the ones delivered up to the resume
+// cursor (nil if there is none), and commits the cursor of each one. It returns the amount of delivered sub-batches.
+func DeliverBatches(ctx context.Context, sink TxSink, batches []ResultBatch, resume *types.ParseCursor, commit CommitFunc) (int, error) {
+	delivered := 0
+	for _, batch := range batches {
+		if resume != nil && resume.Delivered(batch.Cursor) {
+			continue
+		}
+
+		if err := sink.WriteTransactions(ctx, batch.Txs); err != nil {
+			return delivered, fmt.Errorf("could not write transactions of %s: %w", batch.Cursor, err)
+		}
+		if batch.Addresses != nil {
+			if err := sink.WriteAddresses(ctx, batch.Addresses); err != nil {
+				return delivered, fmt.Errorf("could not write addresses of %s: %w", batch.Cursor, err)
+			}
+		}
+		if err := sink.Flush(ctx); err != nil {
+			return delivered, fmt.Errorf("could not flush %s: %w", batch.Cursor, err)
+		}
+		if err := commit(ctx, batch.Cursor); err != nil {
+			return delivered, fmt.Errorf("could not commit %s: %w", batch.Cursor, err)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
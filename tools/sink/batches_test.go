@@ -0,0 +1,60 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestSplitParsedResult(t *testing.T) {
+	result := parsedResult()
+	result.Txs = append(result.Txs, &types.Transaction{TxCid: "bafy3"})
+
+	batches := SplitParsedResult(10, "bafy10", result, 2)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0].Txs, 2)
+	require.Nil(t, batches[0].Addresses)
+	require.False(t, batches[0].Cursor.Final)
+	require.Len(t, batches[1].Txs, 1)
+	require.Equal(t, types.ParseCursor{Height: 10, TipsetCid: "bafy10", Batch: 1, Final: true}, batches[1].Cursor)
+	require.Equal(t, result.Addresses, batches[1].Addresses)
+
+	require.Len(t, SplitParsedResult(10, "bafy10", result, 0), 1)
+
+	// tipsets without txs are delivered too
+	empty := SplitParsedResult(11, "bafy11", nil, 2)
+	require.Len(t, empty, 1)
+	require.True(t, empty[0].Cursor.Final)
+}
+
+func TestDeliverBatches(t *testing.T) {
+	result := parsedResult()
+	result.Txs = append(result.Txs, &types.Transaction{TxCid: "bafy3"})
+	batches := SplitParsedResult(10, "bafy10", result, 1)
+
+	sink := NewChannelSink(10)
+	var committed []types.ParseCursor
+	failAt := 1
+	commit := func(_ context.Context, cursor types.ParseCursor) error {
+		if cursor.Batch == failAt {
+			return errors.New("boom")
+		}
+		committed = append(committed, cursor)
+		return nil
+	}
+
+	delivered, err := DeliverBatches(context.Background(), sink, batches, nil, commit)
+	require.Error(t, err)
+	require.Equal(t, 1, delivered)
+
+	// resume from the last committed cursor, the failed sub-batch is delivered again
+	failAt = -1
+	delivered, err = DeliverBatches(context.Background(), sink, batches, &committed[len(committed)-1], commit)
+	require.NoError(t, err)
+	require.Equal(t, 2, delivered)
+	require.Len(t, committed, 3)
+	require.True(t, committed[2].Final)
+}
@@ -0,0 +1,59 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// ChannelSink sends the written transactions and addresses to channels, for consumers that process the parser
+// output in the same program. Writes block until the values are received or the context is done.
+type ChannelSink struct {
+	txs       chan []*types.Transaction
+	addresses chan *types.AddressInfoMap
+}
+
+// NewChannelSink creates the sink channels with the given buffer size
+func NewChannelSink(buffer int) *ChannelSink {
+	return &ChannelSink{
+		txs:       make(chan []*types.Transaction, buffer),
+		addresses: make(chan *types.AddressInfoMap, buffer),
+	}
+}
+
+func (s *ChannelSink) Transactions() <-chan []*types.Transaction {
+	return s.txs
+}
+
+func (s *ChannelSink) Addresses() <-chan *types.AddressInfoMap {
+	return s.addresses
+}
+
+func (s *ChannelSink) WriteTransactions(ctx context.Context, txs []*types.Transaction) error {
+	select {
+	case s.txs <- txs:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ChannelSink) WriteAddresses(ctx context.Context, addresses *types.AddressInfoMap) error {
+	select {
+	case s.addresses <- addresses:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush is a no-op, values are sent as soon as they are written
+func (s *ChannelSink) Flush(_ context.Context) error {
+	return nil
+}
+
+// Close closes the channels. No writes must happen after it.
+func (s *ChannelSink) Close() {
+	close(s.txs)
+	close(s.addresses)
+}
@@ -0,0 +1,107 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// JSONLSink appends the transactions and addresses as json lines to two files
+type JSONLSink struct {
+	txsFile       *os.File
+	addressesFile *os.File
+	txs           *bufio.Writer
+	addresses     *bufio.Writer
+}
+
+// NewJSONLSink opens (or creates) the files the sink appends to. Close must be called to release them.
+func NewJSONLSink(txsPath, addressesPath string) (*JSONLSink, error) {
+	txsFile, err := os.OpenFile(txsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open transactions file %s: %w", txsPath, err)
+	}
+
+	addressesFile, err := os.OpenFile(addressesPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		_ = txsFile.Close()
+		return nil, fmt.Errorf("could not open addresses file %s: %w", addressesPath, err)
+	}
+
+	return &JSONLSink{
+		txsFile:       txsFile,
+		addressesFile: addressesFile,
+		txs:           bufio.NewWriter(txsFile),
+		addresses:     bufio.NewWriter(addressesFile),
+	}, nil
+}
+
+func (s *JSONLSink) WriteTransactions(_ context.Context, txs []*types.Transaction) error {
+	encoder := json.NewEncoder(s.txs)
+	for _, tx := range txs {
+		if err := encoder.Encode(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAddresses writes the addresses sorted by their key, so the output is deterministic
+func (s *JSONLSink) WriteAddresses(_ context.Context, addresses *types.AddressInfoMap) error {
+	infos := addresses.Copy()
+	keys := make([]string, 0, len(infos))
+	for key := range infos {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	encoder := json.NewEncoder(s.addresses)
+	for _, key := range keys {
+		if err := encoder.Encode(infos[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered lines and syncs both files to disk
+func (s *JSONLSink) Flush(_ context.Context) error {
+	if err := s.txs.Flush(); err != nil {
+		return err
+	}
+	if err := s.addresses.Flush(); err != nil {
+		return err
+	}
+	return errors.Join(s.txsFile.Sync(), s.addressesFile.Sync())
+}
+
+// Close flushes the sink and closes the files
+func (s *JSONLSink) Close() error {
+	err := s.Flush(context.Background())
+	return errors.Join(err, s.txsFile.Close(), s.addressesFile.Close())
+}
+
+// ReadTransactionsJSONL reads the transactions written by a JSONLSink
+func ReadTransactionsJSONL(path string) ([]*types.Transaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open transactions file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var txs []*types.Transaction
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		var tx types.Transaction
+		if err = decoder.Decode(&tx); err != nil {
+			return nil, fmt.Errorf("could not decode transactions file %s: %w", path, err)
+		}
+		txs = append(txs, &tx)
+	}
+	return txs, nil
+}
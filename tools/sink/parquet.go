@@ -0,0 +1,213 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+const (
+	parquetTransactionsPrefix = "transactions"
+	parquetAddressesPrefix    = "addresses"
+)
+
+// ParquetSink writes the transactions and addresses as parquet files to a directory. Parquet files can not be
+// appended to, so every Flush writes the rows buffered since the previous one as a new part file, e.g.
+// transactions-000002.parquet. Amounts are stored as decimal strings, so no precision is lost.
+type ParquetSink struct {
+	dir       string
+	txs       []*types.Transaction
+	addresses []*types.AddressInfo
+}
+
+// NewParquetSink creates the directory the sink writes the part files to, if it does not exist
+func NewParquetSink(dir string) (*ParquetSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create parquet directory %s: %w", dir, err)
+	}
+	return &ParquetSink{dir: dir}, nil
+}
+
+func (s *ParquetSink) WriteTransactions(_ context.Context, txs []*types.Transaction) error {
+	s.txs = append(s.txs, txs...)
+	return nil
+}
+
+// WriteAddresses buffers the addresses sorted by their key, so the output is deterministic
+func (s *ParquetSink) WriteAddresses(_ context.Context, addresses *types.AddressInfoMap) error {
+	infos := addresses.Copy()
+	keys := make([]string, 0, len(infos))
+	for key := range infos {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		s.addresses = append(s.addresses, infos[key])
+	}
+	return nil
+}
+
+// Flush writes the buffered transactions and addresses to new part files. Nothing is written for empty buffers.
+func (s *ParquetSink) Flush(_ context.Context) error {
+	if len(s.txs) > 0 {
+		if err := writeParquetPart(s.dir, parquetTransactionsPrefix, transactionColumns, s.txs); err != nil {
+			return err
+		}
+		s.txs = nil
+	}
+	if len(s.addresses) > 0 {
+		if err := writeParquetPart(s.dir, parquetAddressesPrefix, addressColumns, s.addresses); err != nil {
+			return err
+		}
+		s.addresses = nil
+	}
+	return nil
+}
+
+// writeParquetPart writes the rows to the next free part file of the prefix in the directory
+func writeParquetPart[T any](dir, prefix string, columns []parquetColumn[T], rows []T) error {
+	existing, err := filepath.Glob(filepath.Join(dir, prefix+"-*.parquet"))
+	if err != nil {
+		return err
+	}
+
+	var file *os.File
+	for part := len(existing); ; part++ {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%06d.parquet", prefix, part))
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if !errors.Is(err, os.ErrExist) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not create %s parquet file: %w", prefix, err)
+	}
+
+	if err = writeParquet(file, columns, rows); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("could not write %s parquet file: %w", prefix, err)
+	}
+	return errors.Join(file.Sync(), file.Close())
+}
+
+func stringColumn[T any](name string, value func(row T) string) parquetColumn[T] {
+	return parquetColumn[T]{
+		columnSchema: columnSchema{name: name, typ: parquetByteArray, converted: convertedUTF8},
+		value:        func(row T) any { return value(row) },
+	}
+}
+
+func uint64Column[T any](name string, value func(row T) uint64) parquetColumn[T] {
+	return parquetColumn[T]{
+		columnSchema: columnSchema{name: name, typ: parquetInt64, converted: convertedUint64},
+		value:        func(row T) any { return int64(value(row)) },
+	}
+}
+
+func boolColumn[T any](name string, value func(row T) bool) parquetColumn[T] {
+	return parquetColumn[T]{
+		columnSchema: columnSchema{name: name, typ: parquetBoolean, converted: noConvertedType},
+		value:        func(row T) any { return value(row) },
+	}
+}
+
+// jsonColumn stores a list as a json string, null if it is empty
+func jsonColumn[T any](name string, value func(row T) []string) parquetColumn[T] {
+	return parquetColumn[T]{
+		columnSchema: columnSchema{name: name, typ: parquetByteArray, converted: convertedJSON, optional: true},
+		value: func(row T) any {
+			list := value(row)
+			if len(list) == 0 {
+				return nil
+			}
+			encoded, _ := json.Marshal(list)
+			return encoded
+		},
+	}
+}
+
+var transactionColumns = []parquetColumn[*types.Transaction]{
+	uint64Column("height", func(tx *types.Transaction) uint64 { return tx.Height }),
+	stringColumn("tipset_cid", func(tx *types.Transaction) string { return tx.TipsetCid }),
+	stringColumn("block_cid", func(tx *types.Transaction) string { return tx.BlockCid }),
+	stringColumn("id", func(tx *types.Transaction) string { return tx.Id }),
+	stringColumn("parent_id", func(tx *types.Transaction) string { return tx.ParentId }),
+	{
+		columnSchema: columnSchema{name: "level", typ: parquetInt32, converted: convertedUint16},
+		value:        func(tx *types.Transaction) any { return int32(tx.Level) },
+	},
+	boolColumn("is_internal", func(tx *types.Transaction) bool { return tx.IsInternal }),
+	stringColumn("root_id", func(tx *types.Transaction) string { return tx.RootId }),
+	{
+		columnSchema: columnSchema{name: "tx_timestamp", typ: parquetInt64, converted: convertedTimestampMillis},
+		value:        func(tx *types.Transaction) any { return tx.TxTimestamp.UnixMilli() },
+	},
+	stringColumn("tx_cid", func(tx *types.Transaction) string { return tx.TxCid }),
+	stringColumn("internal_tx_id", func(tx *types.Transaction) string { return tx.InternalTxId }),
+	stringColumn("tx_from", func(tx *types.Transaction) string { return tx.TxFrom }),
+	stringColumn("tx_to", func(tx *types.Transaction) string { return tx.TxTo }),
+	jsonColumn("tx_from_tags", func(tx *types.Transaction) []string { return tx.TxFromTags }),
+	jsonColumn("tx_to_tags", func(tx *types.Transaction) []string { return tx.TxToTags }),
+	{
+		columnSchema: columnSchema{name: "amount", typ: parquetByteArray, converted: convertedUTF8, optional: true},
+		value: func(tx *types.Transaction) any {
+			if tx.Amount == nil {
+				return nil
+			}
+			return tx.Amount.String()
+		},
+	},
+	stringColumn("amount_fil", func(tx *types.Transaction) string { return tx.AmountFil }),
+	uint64Column("execution_index", func(tx *types.Transaction) uint64 { return tx.ExecutionIndex }),
+	{
+		columnSchema: columnSchema{name: "receipt_index", typ: parquetInt64, converted: convertedUint64, optional: true},
+		value: func(tx *types.Transaction) any {
+			if tx.ReceiptIndex == nil {
+				return nil
+			}
+			return int64(*tx.ReceiptIndex)
+		},
+	},
+	stringColumn("receipts_root", func(tx *types.Transaction) string { return tx.ReceiptsRoot }),
+	uint64Column("gas_used", func(tx *types.Transaction) uint64 { return tx.GasUsed }),
+	stringColumn("status", func(tx *types.Transaction) string { return tx.Status }),
+	stringColumn("tx_type", func(tx *types.Transaction) string { return tx.TxType }),
+	{
+		// compressed metadata is not valid UTF8, so the column is stored as raw bytes
+		columnSchema: columnSchema{name: "tx_metadata", typ: parquetByteArray, converted: noConvertedType},
+		value:        func(tx *types.Transaction) any { return []byte(tx.TxMetadata) },
+	},
+	boolColumn("metadata_compressed", func(tx *types.Transaction) bool { return tx.MetadataCompressed }),
+	jsonColumn("diagnostics", func(tx *types.Transaction) []string { return tx.Diagnostics }),
+	stringColumn("input_hash", func(tx *types.Transaction) string { return tx.InputHash }),
+	stringColumn("parser_version", func(tx *types.Transaction) string { return tx.ParserVersion }),
+	stringColumn("parser_build", func(tx *types.Transaction) string { return tx.ParserBuild }),
+	{
+		columnSchema: columnSchema{name: "effective_gas_price", typ: parquetByteArray, converted: convertedUTF8, optional: true},
+		value: func(tx *types.Transaction) any {
+			if tx.EffectiveGasPrice == nil {
+				return nil
+			}
+			return tx.EffectiveGasPrice.String()
+		},
+	},
+	stringColumn("node_full_version", func(tx *types.Transaction) string { return tx.NodeFullVersion }),
+	stringColumn("node_major_minor_version", func(tx *types.Transaction) string { return tx.NodeMajorMinorVersion }),
+}
+
+var addressColumns = []parquetColumn[*types.AddressInfo]{
+	stringColumn("short", func(info *types.AddressInfo) string { return info.Short }),
+	stringColumn("robust", func(info *types.AddressInfo) string { return info.Robust }),
+	stringColumn("eth_address", func(info *types.AddressInfo) string { return info.EthAddress }),
+	stringColumn("actor_cid", func(info *types.AddressInfo) string { return info.ActorCid }),
+	stringColumn("actor_type", func(info *types.AddressInfo) string { return info.ActorType }),
+	stringColumn("creation_tx_cid", func(info *types.AddressInfo) string { return info.CreationTxCid }),
+	uint64Column("creation_height", func(info *types.AddressInfo) uint64 { return info.CreationHeight }),
+}
@@ -0,0 +1,344 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Minimal Parquet file encoding: a single row group with one uncompressed, PLAIN encoded data page per column.
+// The file metadata and page headers are thrift structs serialized with the compact protocol, see
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+
+var parquetMagic = []byte("PAR1")
+
+// parquet physical types
+const (
+	parquetBoolean   int32 = 0
+	parquetInt32     int32 = 1
+	parquetInt64     int32 = 2
+	parquetByteArray int32 = 6
+)
+
+// parquet converted types, noConvertedType if the column has none
+const (
+	noConvertedType          int32 = -1
+	convertedUTF8            int32 = 0
+	convertedTimestampMillis int32 = 9
+	convertedUint16          int32 = 12
+	convertedUint64          int32 = 14
+	convertedJSON            int32 = 19
+)
+
+const (
+	repetitionRequired int32 = 0
+	repetitionOptional int32 = 1
+
+	encodingPlain int32 = 0
+	encodingRLE   int32 = 3
+
+	pageTypeData       int32 = 0
+	codecUncompressed  int32 = 0
+	parquetFileVersion int32 = 1
+	parquetCreatedBy         = "github.com/zondax/fil-parser"
+)
+
+// columnSchema is the schema of a parquet column
+type columnSchema struct {
+	name      string
+	typ       int32
+	converted int32
+	optional  bool
+}
+
+// parquetColumn is a column of the rows of type T. value returns the value of the row for the column: a string,
+// []byte, int64, int32 or bool depending on the physical type, or nil for a null value of an optional column.
+type parquetColumn[T any] struct {
+	columnSchema
+	value func(row T) any
+}
+
+// writeParquet writes the rows as a parquet file with the given columns
+func writeParquet[T any](w io.Writer, columns []parquetColumn[T], rows []T) error {
+	var file bytes.Buffer
+	file.Write(parquetMagic)
+
+	chunks := make([]columnChunk, 0, len(columns))
+	for _, column := range columns {
+		page, err := encodeColumnPage(column, rows)
+		if err != nil {
+			return fmt.Errorf("could not encode column %s: %w", column.name, err)
+		}
+
+		header := compactWriter{}
+		header.writeDataPageHeader(len(rows), len(page))
+		offset := int64(file.Len())
+		file.Write(header.bytes())
+		file.Write(page)
+
+		chunks = append(chunks, columnChunk{
+			columnSchema: column.columnSchema,
+			numValues:    int64(len(rows)),
+			size:         int64(header.len() + len(page)),
+			pageOffset:   offset,
+		})
+	}
+
+	footer := compactWriter{}
+	footer.writeFileMetaData(chunks, int64(len(rows)))
+	file.Write(footer.bytes())
+	_ = binary.Write(&file, binary.LittleEndian, uint32(footer.len()))
+	file.Write(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// encodeColumnPage encodes the values of the column as the body of a data page: the definition levels of optional
+// columns, followed by the non-null values
+func encodeColumnPage[T any](column parquetColumn[T], rows []T) ([]byte, error) {
+	var page, values bytes.Buffer
+	definitionLevels := make([]bool, 0, len(rows))
+	var booleans []bool
+	for _, row := range rows {
+		value := column.value(row)
+		if value == nil {
+			if !column.optional {
+				return nil, fmt.Errorf("null value in required column")
+			}
+			definitionLevels = append(definitionLevels, false)
+			continue
+		}
+		definitionLevels = append(definitionLevels, true)
+
+		switch v := value.(type) {
+		case bool:
+			booleans = append(booleans, v)
+		case int32:
+			_ = binary.Write(&values, binary.LittleEndian, v)
+		case int64:
+			_ = binary.Write(&values, binary.LittleEndian, v)
+		case string:
+			_ = binary.Write(&values, binary.LittleEndian, uint32(len(v)))
+			values.WriteString(v)
+		case []byte:
+			_ = binary.Write(&values, binary.LittleEndian, uint32(len(v)))
+			values.Write(v)
+		default:
+			return nil, fmt.Errorf("unsupported value type %T", value)
+		}
+	}
+	values.Write(packBooleans(booleans))
+
+	if column.optional {
+		levels := encodeDefinitionLevels(definitionLevels)
+		_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+		page.Write(levels)
+	}
+	page.Write(values.Bytes())
+	return page.Bytes(), nil
+}
+
+// encodeDefinitionLevels encodes the definition levels (1 if the value is set, 0 if null) of an optional column with
+// the RLE hybrid encoding, as one RLE run per sequence of equal levels
+func encodeDefinitionLevels(levels []bool) []byte {
+	var buf bytes.Buffer
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		buf.Write(binary.AppendUvarint(nil, uint64(end-start)<<1))
+		if levels[start] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		start = end
+	}
+	return buf.Bytes()
+}
+
+// packBooleans encodes booleans with the PLAIN encoding: one bit per value, least significant bit first
+func packBooleans(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, value := range values {
+		if value {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// columnChunk is the location of the data page of a column in the file
+type columnChunk struct {
+	columnSchema
+	numValues  int64
+	size       int64
+	pageOffset int64
+}
+
+// thrift compact protocol types
+const (
+	compactI32    byte = 5
+	compactI64    byte = 6
+	compactBinary byte = 8
+	compactList   byte = 9
+	compactStruct byte = 12
+)
+
+// compactWriter serializes thrift structs with the compact protocol
+type compactWriter struct {
+	buf bytes.Buffer
+	// lastField is the id of the last field written in each nested struct, field ids are delta encoded
+	lastField []int16
+}
+
+func (w *compactWriter) bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func (w *compactWriter) len() int {
+	return w.buf.Len()
+}
+
+func (w *compactWriter) beginStruct() {
+	w.lastField = append(w.lastField, 0)
+}
+
+func (w *compactWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+func (w *compactWriter) fieldHeader(id int16, typ byte) {
+	last := &w.lastField[len(w.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.writeVarint(int64(id))
+	}
+	*last = id
+}
+
+func (w *compactWriter) listHeader(size int, elemType byte) {
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemType)
+	w.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+// writeVarint writes a zigzag encoded varint, as used by the compact protocol for all the integers
+func (w *compactWriter) writeVarint(v int64) {
+	w.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (w *compactWriter) writeBinary(v string) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(v))))
+	w.buf.WriteString(v)
+}
+
+func (w *compactWriter) i32Field(id int16, v int32) {
+	w.fieldHeader(id, compactI32)
+	w.writeVarint(int64(v))
+}
+
+func (w *compactWriter) i64Field(id int16, v int64) {
+	w.fieldHeader(id, compactI64)
+	w.writeVarint(v)
+}
+
+func (w *compactWriter) binaryField(id int16, v string) {
+	w.fieldHeader(id, compactBinary)
+	w.writeBinary(v)
+}
+
+// writeDataPageHeader writes the PageHeader of an uncompressed PLAIN encoded data page
+func (w *compactWriter) writeDataPageHeader(numValues, size int) {
+	w.beginStruct()
+	w.i32Field(1, pageTypeData)
+	w.i32Field(2, int32(size))
+	w.i32Field(3, int32(size))
+	w.fieldHeader(5, compactStruct)
+	w.beginStruct()
+	w.i32Field(1, int32(numValues))
+	w.i32Field(2, encodingPlain)
+	w.i32Field(3, encodingRLE)
+	w.i32Field(4, encodingRLE)
+	w.endStruct()
+	w.endStruct()
+}
+
+// writeFileMetaData writes the FileMetaData of a file with a single row group
+func (w *compactWriter) writeFileMetaData(chunks []columnChunk, numRows int64) {
+	w.beginStruct()
+	w.i32Field(1, parquetFileVersion)
+
+	w.fieldHeader(2, compactList)
+	w.listHeader(len(chunks)+1, compactStruct)
+	w.beginStruct()
+	w.binaryField(4, "schema")
+	w.i32Field(5, int32(len(chunks)))
+	w.endStruct()
+	for _, column := range chunks {
+		repetition := repetitionRequired
+		if column.optional {
+			repetition = repetitionOptional
+		}
+		w.beginStruct()
+		w.i32Field(1, column.typ)
+		w.i32Field(3, repetition)
+		w.binaryField(4, column.name)
+		if column.converted != noConvertedType {
+			w.i32Field(6, column.converted)
+		}
+		w.endStruct()
+	}
+
+	w.i64Field(3, numRows)
+
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+	w.fieldHeader(4, compactList)
+	w.listHeader(1, compactStruct)
+	w.beginStruct()
+	w.fieldHeader(1, compactList)
+	w.listHeader(len(chunks), compactStruct)
+	for _, chunk := range chunks {
+		w.writeColumnChunk(chunk)
+	}
+	w.i64Field(2, totalSize)
+	w.i64Field(3, numRows)
+	w.endStruct()
+
+	w.binaryField(6, parquetCreatedBy)
+	w.endStruct()
+}
+
+func (w *compactWriter) writeColumnChunk(chunk columnChunk) {
+	w.beginStruct()
+	w.i64Field(2, chunk.pageOffset)
+	w.fieldHeader(3, compactStruct)
+	w.beginStruct()
+	w.i32Field(1, chunk.typ)
+	w.fieldHeader(2, compactList)
+	w.listHeader(2, compactI32)
+	w.writeVarint(int64(encodingPlain))
+	w.writeVarint(int64(encodingRLE))
+	w.fieldHeader(3, compactList)
+	w.listHeader(1, compactBinary)
+	w.writeBinary(chunk.name)
+	w.i32Field(4, codecUncompressed)
+	w.i64Field(5, chunk.numValues)
+	w.i64Field(6, chunk.size)
+	w.i64Field(7, chunk.size)
+	w.i64Field(9, chunk.pageOffset)
+	w.endStruct()
+	w.endStruct()
+}
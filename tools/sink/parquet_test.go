@@ -0,0 +1,227 @@
+package sink
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+// compactReader decodes thrift compact structs into maps of field id to value, enough to check the parquet metadata
+type compactReader struct {
+	t    *testing.T
+	data []byte
+	pos  int
+}
+
+func (r *compactReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *compactReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	require.Positive(r.t, n)
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) varint() int64 {
+	v, n := binary.Varint(r.data[r.pos:])
+	require.Positive(r.t, n)
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) value(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 5, 6:
+		return r.varint()
+	case 8:
+		size := int(r.uvarint())
+		r.pos += size
+		return string(r.data[r.pos-size : r.pos])
+	case 9:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, 0, size)
+		for range size {
+			list = append(list, r.value(header&0x0f))
+		}
+		return list
+	case 12:
+		return r.structValue()
+	}
+	r.t.Fatalf("unexpected compact type %d", typ)
+	return nil
+}
+
+func (r *compactReader) structValue() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+// readParquetColumn reads the footer of the file and the byte array values of the column, with nil for nulls
+func readParquetColumn(t *testing.T, path, name string) (int64, []any) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	// the reader does not share any constant with the writer, so it checks the format rather than the writer
+	require.Equal(t, []byte("PAR1"), data[:4])
+	require.Equal(t, []byte("PAR1"), data[len(data)-4:])
+
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &compactReader{t: t, data: data[:len(data)-8], pos: len(data) - 8 - footerSize}
+	metadata := footer.structValue()
+	numRows := metadata[3].(int64)
+
+	schema := metadata[2].([]any)
+	columns := metadata[4].([]any)[0].(map[int16]any)[1].([]any)
+	for i, element := range schema[1:] {
+		if element.(map[int16]any)[4] != name {
+			continue
+		}
+		// OPTIONAL repetition
+		optional := element.(map[int16]any)[3] == int64(1)
+		chunk := columns[i].(map[int16]any)[3].(map[int16]any)
+		require.Equal(t, []any{name}, chunk[3])
+
+		page := &compactReader{t: t, data: data, pos: int(chunk[9].(int64))}
+		header := page.structValue()
+		body := data[page.pos : page.pos+int(header[3].(int64))]
+
+		defined := make([]bool, 0, numRows)
+		if optional {
+			levels := &compactReader{t: t, data: body[4 : 4+binary.LittleEndian.Uint32(body)]}
+			for levels.pos < len(levels.data) {
+				run := int(levels.uvarint() >> 1)
+				level := levels.byte() == 1
+				for range run {
+					defined = append(defined, level)
+				}
+			}
+			body = body[4+len(levels.data):]
+		}
+
+		var values []any
+		for row := int64(0); row < numRows; row++ {
+			if optional && !defined[row] {
+				values = append(values, nil)
+				continue
+			}
+			size := binary.LittleEndian.Uint32(body)
+			values = append(values, string(body[4:4+size]))
+			body = body[4+size:]
+		}
+		return numRows, values
+	}
+
+	t.Fatalf("column %s not found", name)
+	return 0, nil
+}
+
+func TestParquetSink(t *testing.T) {
+	dir := t.TempDir()
+	txSink, err := NewParquetSink(dir)
+	require.NoError(t, err)
+
+	result := parsedResult()
+	result.Txs[1].Amount = big.NewInt(10)
+	require.NoError(t, WriteParsedResult(context.Background(), txSink, result))
+
+	numRows, values := readParquetColumn(t, filepath.Join(dir, "transactions-000000.parquet"), "tx_cid")
+	require.Equal(t, int64(2), numRows)
+	require.Equal(t, []any{"bafy1", "bafy2"}, values)
+
+	_, values = readParquetColumn(t, filepath.Join(dir, "transactions-000000.parquet"), "amount")
+	require.Equal(t, []any{nil, "10"}, values)
+
+	_, values = readParquetColumn(t, filepath.Join(dir, "addresses-000000.parquet"), "short")
+	require.Equal(t, []any{"f01000", "f01001"}, values)
+
+	// every flush writes a new part, empty buffers are not written
+	require.NoError(t, txSink.WriteTransactions(context.Background(), []*types.Transaction{{TxCid: "bafy3"}}))
+	require.NoError(t, txSink.Flush(context.Background()))
+	require.NoError(t, txSink.Flush(context.Background()))
+
+	_, values = readParquetColumn(t, filepath.Join(dir, "transactions-000001.parquet"), "tx_cid")
+	require.Equal(t, []any{"bafy3"}, values)
+	parts, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+}
+
+// pyarrowReadScript prints the rows of the parquet file as json, read with the reference implementation
+const pyarrowReadScript = `
+import json, sys
+import pyarrow.parquet as pq
+print(json.dumps(pq.read_table(sys.argv[1]).to_pylist(), default=str))
+`
+
+// readParquetWithPyarrow reads the rows of the file with pyarrow. The test is skipped if pyarrow is not installed.
+func readParquetWithPyarrow(t *testing.T, path string) []map[string]any {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not installed")
+	}
+	if err = exec.Command(python, "-c", "import pyarrow.parquet").Run(); err != nil {
+		t.Skip("pyarrow is not installed")
+	}
+
+	out, err := exec.Command(python, "-c", pyarrowReadScript, path).Output()
+	require.NoError(t, err)
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(out, &rows))
+	return rows
+}
+
+// TestParquetSink_Pyarrow validates the files with an independent parquet implementation
+func TestParquetSink_Pyarrow(t *testing.T) {
+	dir := t.TempDir()
+	txSink, err := NewParquetSink(dir)
+	require.NoError(t, err)
+
+	result := parsedResult()
+	result.Txs[1].Amount = big.NewInt(10)
+	result.Txs[1].Level = 2
+	result.Txs[1].IsInternal = true
+	require.NoError(t, WriteParsedResult(context.Background(), txSink, result))
+
+	rows := readParquetWithPyarrow(t, filepath.Join(dir, "transactions-000000.parquet"))
+	require.Len(t, rows, 2)
+	require.Equal(t, "bafy1", rows[0]["tx_cid"])
+	require.Nil(t, rows[0]["amount"])
+	require.Equal(t, "bafy2", rows[1]["tx_cid"])
+	require.Equal(t, "10", rows[1]["amount"])
+	require.Equal(t, float64(2), rows[1]["level"])
+	require.Equal(t, true, rows[1]["is_internal"])
+
+	rows = readParquetWithPyarrow(t, filepath.Join(dir, "addresses-000000.parquet"))
+	require.Len(t, rows, 2)
+	require.Equal(t, "f01000", rows[0]["short"])
+	require.Equal(t, "f01001", rows[1]["short"])
+}
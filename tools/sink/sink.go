@@ -0,0 +1,34 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zondax/fil-parser/types"
+)
+
+// TxSink persists the output of the parser. Writes may be buffered until Flush is called.
+type TxSink interface {
+	WriteTransactions(ctx context.Context, txs []*types.Transaction) error
+	WriteAddresses(ctx context.Context, addresses *types.AddressInfoMap) error
+	Flush(ctx context.Context) error
+}
+
+// WriteParsedResult writes the transactions and addresses of a parsed tipset and flushes the sink,
+// so the result is persisted once it returns
+func WriteParsedResult(ctx context.Context, sink TxSink, result *types.TxsParsedResult) error {
+	if result == nil {
+		return sink.Flush(ctx)
+	}
+
+	if err := sink.WriteTransactions(ctx, result.Txs); err != nil {
+		return fmt.Errorf("could not write transactions: %w", err)
+	}
+	if result.Addresses != nil {
+		if err := sink.WriteAddresses(ctx, result.Addresses); err != nil {
+			return fmt.Errorf("could not write addresses: %w", err)
+		}
+	}
+
+	return sink.Flush(ctx)
+}
@@ -0,0 +1,77 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func parsedResult() *types.TxsParsedResult {
+	addresses := types.NewAddressInfoMap()
+	addresses.Set("f01001", &types.AddressInfo{Short: "f01001"})
+	addresses.Set("f01000", &types.AddressInfo{Short: "f01000"})
+	return &types.TxsParsedResult{
+		Txs:       []*types.Transaction{{TxCid: "bafy1"}, {TxCid: "bafy2"}},
+		Addresses: addresses,
+	}
+}
+
+func readLines[T any](t *testing.T, path string) []T {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var values []T
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var value T
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &value))
+		values = append(values, value)
+	}
+	require.NoError(t, scanner.Err())
+	return values
+}
+
+func TestJSONLSink(t *testing.T) {
+	dir := t.TempDir()
+	txsPath, addressesPath := filepath.Join(dir, "txs.jsonl"), filepath.Join(dir, "addresses.jsonl")
+
+	sink, err := NewJSONLSink(txsPath, addressesPath)
+	require.NoError(t, err)
+	require.NoError(t, WriteParsedResult(context.Background(), sink, parsedResult()))
+	require.NoError(t, sink.Close())
+
+	// appends on reopen
+	sink, err = NewJSONLSink(txsPath, addressesPath)
+	require.NoError(t, err)
+	require.NoError(t, sink.WriteTransactions(context.Background(), []*types.Transaction{{TxCid: "bafy3"}}))
+	require.NoError(t, sink.Close())
+
+	txs := readLines[types.Transaction](t, txsPath)
+	require.Len(t, txs, 3)
+	require.Equal(t, "bafy3", txs[2].TxCid)
+
+	addresses := readLines[types.AddressInfo](t, addressesPath)
+	require.Equal(t, []types.AddressInfo{{Short: "f01000"}, {Short: "f01001"}}, addresses)
+}
+
+func TestChannelSink(t *testing.T) {
+	sink := NewChannelSink(1)
+	result := parsedResult()
+	require.NoError(t, WriteParsedResult(context.Background(), sink, result))
+	require.Equal(t, result.Txs, <-sink.Transactions())
+	require.Equal(t, result.Addresses, <-sink.Addresses())
+
+	// blocked writes return when the context is done
+	require.NoError(t, sink.WriteTransactions(context.Background(), nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, sink.WriteTransactions(ctx, nil), context.Canceled)
+	sink.Close()
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"hash"
 
 	"github.com/zondax/fil-parser/parser"
 	"github.com/zondax/fil-parser/types"
@@ -15,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	blocks "github.com/ipfs/go-block-format"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/blake2b"
 )
 
 const UnknownParserVersion = "unknown"
@@ -24,24 +26,59 @@ type Tools struct {
 }
 
 func BuildId(input ...string) string {
+	return IdBuilder{}.BuildId(input...)
+}
+
+func BuildMessageId(tipsetCid, blockCid, mainMsgCid, messageCid, parentId string) string {
+	return IdBuilder{}.BuildMessageId(tipsetCid, blockCid, mainMsgCid, messageCid, parentId)
+}
+
+func BuildFeeId(tipsetCid, blockCid, mainMsgCid string) string {
+	return IdBuilder{}.BuildFeeId(tipsetCid, blockCid, mainMsgCid)
+}
+
+// IdBuilder builds the deterministic tx ids with the hash of a scheme (see the parser.IdHashScheme constants).
+// An id is the UUID v5 (nil namespace) of the hash of its concatenated inputs, so any indexer can generate
+// the same ids from the same traces. The zero value uses sha256.
+type IdBuilder struct {
+	newHash func() hash.Hash
+}
+
+func NewIdBuilder(scheme string) IdBuilder {
+	if scheme == parser.IdHashSchemeBlake2b {
+		return IdBuilder{newHash: newBlake2b256}
+	}
+	return IdBuilder{}
+}
+
+func newBlake2b256() hash.Hash {
+	// blake2b only fails for keys longer than 64 bytes
+	h, _ := blake2b.New256(nil)
+	return h
+}
+
+func (b IdBuilder) BuildId(input ...string) string {
 	h := sha256.New()
+	if b.newHash != nil {
+		h = b.newHash()
+	}
+
 	a := make([]byte, 0)
 	for _, v := range input {
 		a = append(a, []byte(v)...)
 	}
 
 	h.Write(a)
-	hash := h.Sum(nil)
-	id := uuid.NewSHA1(uuid.Nil, hash)
+	id := uuid.NewSHA1(uuid.Nil, h.Sum(nil))
 	return id.String()
 }
 
-func BuildMessageId(tipsetCid, blockCid, mainMsgCid, messageCid, parentId string) string {
-	return BuildId(tipsetCid, blockCid, mainMsgCid, messageCid, parentId)
+func (b IdBuilder) BuildMessageId(tipsetCid, blockCid, mainMsgCid, messageCid, parentId string) string {
+	return b.BuildId(tipsetCid, blockCid, mainMsgCid, messageCid, parentId)
 }
 
-func BuildFeeId(tipsetCid, blockCid, mainMsgCid string) string {
-	return BuildId(tipsetCid, blockCid, mainMsgCid, "fee")
+func (b IdBuilder) BuildFeeId(tipsetCid, blockCid, mainMsgCid string) string {
+	return b.BuildId(tipsetCid, blockCid, mainMsgCid, "fee")
 }
 
 func BuildTipsetId(tipsetCid string) string {
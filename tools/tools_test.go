@@ -1,11 +1,15 @@
 package tools
 
 import (
+	"crypto/sha256"
 	"os"
 	"testing"
 
 	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"golang.org/x/crypto/blake2b"
 )
 
 func TestBuildTipSetKeyHash(t *testing.T) {
@@ -93,3 +97,28 @@ func TestBuildCidFromMessageTrace(t *testing.T) {
 		})
 	}
 }*/
+
+func TestIdBuilder(t *testing.T) {
+	input := []string{"tipset", "block", "msg", "fee"}
+	sha := sha256.Sum256([]byte("tipsetblockmsgfee"))
+	blake := blake2b.Sum256([]byte("tipsetblockmsgfee"))
+
+	tests := []struct {
+		name   string
+		scheme string
+		want   string
+	}{
+		{name: "default", scheme: "", want: uuid.NewSHA1(uuid.Nil, sha[:]).String()},
+		{name: "sha256", scheme: parser.IdHashSchemeSha256, want: uuid.NewSHA1(uuid.Nil, sha[:]).String()},
+		{name: "blake2b", scheme: parser.IdHashSchemeBlake2b, want: uuid.NewSHA1(uuid.Nil, blake[:]).String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewIdBuilder(tt.scheme).BuildId(input...))
+			require.Equal(t, tt.want, NewIdBuilder(tt.scheme).BuildFeeId("tipset", "block", "msg"))
+		})
+	}
+
+	// the package functions keep using sha256
+	require.Equal(t, uuid.NewSHA1(uuid.Nil, sha[:]).String(), BuildFeeId("tipset", "block", "msg"))
+}
@@ -0,0 +1,145 @@
+package verifreg
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/verifreg"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/zondax/fil-parser/parser/helper"
+	"github.com/zondax/fil-parser/types"
+)
+
+// GetAllocations reads the pending allocations made by the client at the tipset, traversing the
+// allocations HAMT of the verified registry actor
+func GetAllocations(ctx context.Context, helper *helper.Helper, client address.Address, tipset *types.ExtendedTipSet) (*types.VerifregAllocations, error) {
+	clientID, err := idAddress(ctx, helper, client)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(ctx, helper, tipset)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := state.GetAllocations(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("vstate.GetAllocations(): %s", err)
+	}
+
+	result := &types.VerifregAllocations{
+		Client:      clientID.String(),
+		Height:      uint64(tipset.Height()),
+		Allocations: make([]*types.VerifregAllocation, 0, len(allocations)),
+	}
+	for id, allocation := range allocations {
+		result.Allocations = append(result.Allocations, newAllocation(id, allocation))
+	}
+	slices.SortFunc(result.Allocations, func(a, b *types.VerifregAllocation) int {
+		return cmp.Compare(a.AllocationID, b.AllocationID)
+	})
+	return result, nil
+}
+
+// GetClaims reads the claims of the provider at the tipset, traversing the claims HAMT of the verified
+// registry actor
+func GetClaims(ctx context.Context, helper *helper.Helper, provider address.Address, tipset *types.ExtendedTipSet) (*types.VerifregClaims, error) {
+	providerID, err := idAddress(ctx, helper, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(ctx, helper, tipset)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := state.GetClaims(providerID)
+	if err != nil {
+		return nil, fmt.Errorf("vstate.GetClaims(): %s", err)
+	}
+
+	result := &types.VerifregClaims{
+		Provider: providerID.String(),
+		Height:   uint64(tipset.Height()),
+		Claims:   make([]*types.VerifregClaim, 0, len(claims)),
+	}
+	for id, claim := range claims {
+		result.Claims = append(result.Claims, newClaim(id, claim))
+	}
+	slices.SortFunc(result.Claims, func(a, b *types.VerifregClaim) int {
+		return cmp.Compare(a.ClaimID, b.ClaimID)
+	})
+	return result, nil
+}
+
+func loadState(ctx context.Context, helper *helper.Helper, tipset *types.ExtendedTipSet) (verifreg.State, error) {
+	node := helper.GetFilecoinNodeClient()
+	store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(node)))
+
+	act, err := node.StateGetActor(ctx, verifreg.Address, tipset.Key())
+	if err != nil {
+		return nil, fmt.Errorf("api.StateGetActor(): %s", err)
+	}
+
+	state, err := verifreg.Load(store, act)
+	if err != nil {
+		return nil, fmt.Errorf("verifreg.Load(): %s", err)
+	}
+	return state, nil
+}
+
+// idAddress returns the ID address of the actor, as the allocations and claims are keyed by actor id
+func idAddress(ctx context.Context, helper *helper.Helper, addr address.Address) (address.Address, error) {
+	if addr.Protocol() == address.ID {
+		return addr, nil
+	}
+
+	short, err := helper.GetActorsCache().GetShortAddressWithContext(ctx, addr)
+	if err != nil {
+		return address.Undef, fmt.Errorf("could not get short address for %s: %w", addr.String(), err)
+	}
+	return address.NewFromString(short)
+}
+
+func newAllocation(id verifreg.AllocationId, allocation verifreg.Allocation) *types.VerifregAllocation {
+	return &types.VerifregAllocation{
+		AllocationID: uint64(id),
+		Client:       actorIDAddress(allocation.Client),
+		Provider:     actorIDAddress(allocation.Provider),
+		Data:         allocation.Data.String(),
+		Size:         uint64(allocation.Size),
+		TermMin:      int64(allocation.TermMin),
+		TermMax:      int64(allocation.TermMax),
+		Expiration:   int64(allocation.Expiration),
+	}
+}
+
+func newClaim(id verifreg.ClaimId, claim verifreg.Claim) *types.VerifregClaim {
+	return &types.VerifregClaim{
+		ClaimID:   uint64(id),
+		Provider:  actorIDAddress(claim.Provider),
+		Client:    actorIDAddress(claim.Client),
+		Data:      claim.Data.String(),
+		Size:      uint64(claim.Size),
+		TermMin:   int64(claim.TermMin),
+		TermMax:   int64(claim.TermMax),
+		TermStart: int64(claim.TermStart),
+		Sector:    uint64(claim.Sector),
+	}
+}
+
+func actorIDAddress(id abi.ActorID) string {
+	addr, err := address.NewIDAddress(uint64(id))
+	if err != nil {
+		return ""
+	}
+	return addr.String()
+}
@@ -0,0 +1,40 @@
+package verifreg
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/lotus/chain/actors/builtin/verifreg"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/types"
+)
+
+func TestNewAllocationAndClaim(t *testing.T) {
+	data, err := cid.Parse("baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	require.NoError(t, err)
+
+	allocation := newAllocation(7, verifreg.Allocation{Client: 1000, Provider: 2000, Data: data, Size: 2048, TermMin: 10, TermMax: 20, Expiration: 30})
+	require.Equal(t, &types.VerifregAllocation{
+		AllocationID: 7,
+		Client:       "f01000",
+		Provider:     "f02000",
+		Data:         data.String(),
+		Size:         2048,
+		TermMin:      10,
+		TermMax:      20,
+		Expiration:   30,
+	}, allocation)
+
+	claim := newClaim(8, verifreg.Claim{Provider: 2000, Client: 1000, Data: data, Size: 2048, TermMin: 10, TermMax: 20, TermStart: 15, Sector: 3})
+	require.Equal(t, &types.VerifregClaim{
+		ClaimID:   8,
+		Provider:  "f02000",
+		Client:    "f01000",
+		Data:      data.String(),
+		Size:      2048,
+		TermMin:   10,
+		TermMax:   20,
+		TermStart: 15,
+		Sector:    3,
+	}, claim)
+}
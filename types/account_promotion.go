@@ -0,0 +1,21 @@
+package types
+
+// AccountPromotion is a placeholder actor promoted to an EthAccount by the first message it sent. Placeholders
+// are created by transfers to f410 addresses nobody controls yet, and become accounts once their owner signs
+// a message.
+type AccountPromotion struct {
+	// Height is the height of the tipset
+	Height uint64 `json:"height"`
+	// TipsetCid is the cid of the tipset
+	TipsetCid string `json:"tipset_cid"`
+	// Address is the f410 address of the promoted actor
+	Address string `json:"address"`
+	// ActorId is the id address of the promoted actor
+	ActorId string `json:"actor_id,omitempty"`
+	// EthAddress is the eth address of the promoted actor
+	EthAddress string `json:"eth_address,omitempty"`
+	// ActorCode is the code cid of the EthAccount actor the placeholder was promoted to
+	ActorCode string `json:"actor_code,omitempty"`
+	// TxCid is the cid of the message that triggered the promotion
+	TxCid string `json:"tx_cid"`
+}
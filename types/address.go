@@ -1,6 +1,23 @@
 package types
 
-import "sync"
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// AddressInfoMapSerializerName is the name of the gorm serializer of AddressInfoMap fields, to be used as
+// `gorm:"serializer:address_info_map"`
+const AddressInfoMapSerializerName = "address_info_map"
+
+func init() {
+	schema.RegisterSerializer(AddressInfoMapSerializerName, AddressInfoMapSerializer{})
+}
 
 type AddressInfo struct {
 	// Short is the address in 'short' format
@@ -15,6 +32,9 @@ type AddressInfo struct {
 	ActorType string `json:"actor_type"`
 	// CreationTxCid is the tx cid were this actor was created (if applicable)
 	CreationTxCid string `json:"creation_tx_cid" gorm:"index:idx_addresses_creation_tx_cid"`
+	// CreationHeight is the height of the tx were this actor was created. It is only set along CreationTxCid, if the
+	// creation_height feature is enabled, and omitted otherwise
+	CreationHeight uint64 `json:"creation_height,omitempty"`
 }
 
 type AddressInfoMap struct {
@@ -41,6 +61,12 @@ func (a *AddressInfoMap) Get(key string) (*AddressInfo, bool) {
 	return val, ok
 }
 
+func (a *AddressInfoMap) Delete(key string) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.m, key)
+}
+
 func (a *AddressInfoMap) Len() int {
 	a.Lock()
 	defer a.Unlock()
@@ -69,3 +95,71 @@ func (a *AddressInfoMap) Copy() map[string]*AddressInfo {
 
 	return result
 }
+
+// MarshalJSON encodes the addresses as a json object keyed by address
+func (a *AddressInfoMap) MarshalJSON() ([]byte, error) {
+	a.Lock()
+	defer a.Unlock()
+	if a.m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(a.m)
+}
+
+// UnmarshalJSON replaces the addresses with the ones of the json object
+func (a *AddressInfoMap) UnmarshalJSON(data []byte) error {
+	m := make(map[string]*AddressInfo)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	a.m = m
+	return nil
+}
+
+// Value implements driver.Valuer, storing the addresses as a json object
+func (a *AddressInfoMap) Value() (driver.Value, error) {
+	return a.MarshalJSON()
+}
+
+// Scan implements sql.Scanner for the json objects stored by Value. A NULL value leaves no addresses.
+func (a *AddressInfoMap) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		a.Lock()
+		defer a.Unlock()
+		a.m = make(map[string]*AddressInfo)
+		return nil
+	case []byte:
+		return a.UnmarshalJSON(value)
+	case string:
+		return a.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("cannot scan %T into AddressInfoMap", src)
+	}
+}
+
+// AddressInfoMapSerializer is the gorm serializer of *AddressInfoMap fields, see AddressInfoMapSerializerName
+type AddressInfoMapSerializer struct{}
+
+func (AddressInfoMapSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	addresses := NewAddressInfoMap()
+	if err := addresses.Scan(dbValue); err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(addresses))
+	return nil
+}
+
+func (AddressInfoMapSerializer) Value(_ context.Context, _ *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	addresses, ok := fieldValue.(*AddressInfoMap)
+	if !ok {
+		return nil, fmt.Errorf("cannot serialize %T as AddressInfoMap", fieldValue)
+	}
+	if addresses == nil {
+		return nil, nil
+	}
+	return addresses.Value()
+}
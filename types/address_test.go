@@ -1,7 +1,11 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm/schema"
+	"reflect"
 	"sync"
 	"testing"
 )
@@ -83,3 +87,56 @@ func (suite *AddressInfoMapSuite) TestCopy() {
 	suite.Equal(address1, copiedMap["key1"])
 	suite.Equal(address2, copiedMap["key2"])
 }
+
+func (suite *AddressInfoMapSuite) TestJSON() {
+	address := &AddressInfo{Short: "f01", Robust: "f1abc", CreationTxCid: "bafy", CreationHeight: 10}
+	suite.aim.Set("f01", address)
+	// the creation height is omitted when unknown
+	suite.aim.Set("f02", &AddressInfo{Short: "f02"})
+
+	data, err := json.Marshal(suite.aim)
+	suite.NoError(err)
+	suite.JSONEq(`{"f01":{"short":"f01","robust":"f1abc","eth_address":"","actor_cid":"","actor_type":"","creation_tx_cid":"bafy","creation_height":10},`+
+		`"f02":{"short":"f02","robust":"","eth_address":"","actor_cid":"","actor_type":"","creation_tx_cid":""}}`, string(data))
+
+	decoded := NewAddressInfoMap()
+	suite.NoError(json.Unmarshal(data, decoded))
+	suite.Equal(suite.aim.Copy(), decoded.Copy())
+}
+
+func (suite *AddressInfoMapSuite) TestValueAndScan() {
+	suite.aim.Set("f01", &AddressInfo{Short: "f01"})
+
+	value, err := suite.aim.Value()
+	suite.NoError(err)
+
+	scanned := NewAddressInfoMap()
+	suite.NoError(scanned.Scan(value))
+	suite.Equal(suite.aim.Copy(), scanned.Copy())
+	suite.NoError(scanned.Scan(string(value.([]byte))))
+	suite.Equal(suite.aim.Copy(), scanned.Copy())
+
+	suite.NoError(scanned.Scan(nil))
+	suite.Equal(0, scanned.Len())
+	suite.Error(scanned.Scan(1))
+}
+
+func (suite *AddressInfoMapSuite) TestSerializer() {
+	type row struct {
+		Addresses *AddressInfoMap `gorm:"serializer:address_info_map"`
+	}
+	s, err := schema.Parse(&row{}, &sync.Map{}, schema.NamingStrategy{})
+	suite.Require().NoError(err)
+	field := s.LookUpField("Addresses")
+	suite.Require().NotNil(field)
+
+	suite.aim.Set("f01", &AddressInfo{Short: "f01"})
+	ctx := context.Background()
+	dbValue, err := AddressInfoMapSerializer{}.Value(ctx, field, reflect.ValueOf(&row{Addresses: suite.aim}), suite.aim)
+	suite.NoError(err)
+
+	var scanned row
+	suite.NoError(AddressInfoMapSerializer{}.Scan(ctx, field, reflect.ValueOf(&scanned), dbValue))
+	suite.Require().NotNil(scanned.Addresses)
+	suite.Equal(suite.aim.Copy(), scanned.Addresses.Copy())
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// ErrBaseFeeOverflow is returned when a base fee does not fit in an uint64
+var ErrBaseFeeOverflow = errors.New("base fee overflows uint64")
+
+// BaseFeeSource is how a base fee was derived
+type BaseFeeSource string
+
+const (
+	// BaseFeeSourceTraces is derived from the base fee burn and the gas used by the first message of the traces
+	BaseFeeSourceTraces BaseFeeSource = "traces"
+	// BaseFeeSourceParentBaseFee is the fallback used when no message of the traces used gas: the
+	// ParentBaseFee of the first block of the tipset, as reported by the node
+	BaseFeeSourceParentBaseFee BaseFeeSource = "parent_base_fee"
+)
+
+// BaseFee is the base fee of a tipset along with how it was derived
+type BaseFee struct {
+	// Deprecated: Value is capped at math.MaxUint64, use Amount or Uint64 instead
+	Value uint64 `json:"value"`
+	// Amount is the base fee in attoFil
+	Amount *big.Int      `json:"amount"`
+	Source BaseFeeSource `json:"source"`
+}
+
+// NewBaseFee returns the base fee of the given amount, which may be nil if it is unknown
+func NewBaseFee(amount *big.Int, source BaseFeeSource) BaseFee {
+	baseFee := BaseFee{Amount: amount, Source: source}
+	if amount != nil {
+		baseFee.Value = math.MaxUint64
+		if amount.IsUint64() {
+			baseFee.Value = amount.Uint64()
+		}
+	}
+	return baseFee
+}
+
+// Uint64 returns the base fee as an uint64, or ErrBaseFeeOverflow if it does not fit
+func (b BaseFee) Uint64() (uint64, error) {
+	if b.Amount == nil {
+		return b.Value, nil
+	}
+	if !b.Amount.IsUint64() {
+		return 0, ErrBaseFeeOverflow
+	}
+	return b.Amount.Uint64(), nil
+}
+
+// String returns the base fee in attoFil
+func (b BaseFee) String() string {
+	if b.Amount == nil {
+		return new(big.Int).SetUint64(b.Value).String()
+	}
+	return b.Amount.String()
+}
+
+// IsFallback is true when the base fee was not derived from the traces
+func (b BaseFee) IsFallback() bool {
+	return b.Source != BaseFeeSourceTraces
+}
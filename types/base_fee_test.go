@@ -0,0 +1,31 @@
+package types
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseFee(t *testing.T) {
+	baseFee := NewBaseFee(big.NewInt(100), BaseFeeSourceTraces)
+	require.Equal(t, uint64(100), baseFee.Value)
+	value, err := baseFee.Uint64()
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), value)
+	require.Equal(t, "100", baseFee.String())
+
+	// 2^64 + 1 does not fit in an uint64
+	huge := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	baseFee = NewBaseFee(huge, BaseFeeSourceParentBaseFee)
+	require.Equal(t, uint64(math.MaxUint64), baseFee.Value)
+	_, err = baseFee.Uint64()
+	require.ErrorIs(t, err, ErrBaseFeeOverflow)
+	require.Equal(t, "18446744073709551617", baseFee.String())
+
+	// unknown amount
+	baseFee = NewBaseFee(nil, BaseFeeSourceParentBaseFee)
+	require.Nil(t, baseFee.Amount)
+	require.Equal(t, uint64(0), baseFee.Value)
+}
@@ -0,0 +1,23 @@
+package types
+
+// BlockInclusion is a message included in one of the blocks of a tipset. A message can be included in more than
+// one block of the same tipset but it is applied only once, so its txs only have the first of them (the canonical
+// block). There is one BlockInclusion for every block that included the message.
+type BlockInclusion struct {
+	// Height is the height of the tipset
+	Height uint64 `json:"height"`
+	// TipsetCid is the cid of the tipset
+	TipsetCid string `json:"tipset_cid"`
+	// BlockCid is the cid of the block that included the message
+	BlockCid string `json:"block_cid"`
+	// BlockMiner is the miner that mined the block
+	BlockMiner string `json:"block_miner"`
+	// TxCid is the cid of the message
+	TxCid string `json:"tx_cid"`
+	// TxId is the id of the tx of the message
+	TxId string `json:"tx_id"`
+	// Canonical is true for the block the tx of the message is attributed to
+	Canonical bool `json:"canonical"`
+	// Inclusions is the amount of blocks of the tipset that included the message
+	Inclusions int `json:"inclusions"`
+}
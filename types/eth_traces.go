@@ -0,0 +1,19 @@
+package types
+
+import "github.com/filecoin-project/lotus/chain/types/ethtypes"
+
+// EthTracesData are the eth traces of a tipset in the layout of the trace_block eth api, e.g. FEVM data
+// archived from a node. They are an alternative source for EVM-only parsing, see ParseEthTraces.
+type EthTracesData struct {
+	Tipset *ExtendedTipSet
+	// Traces must be in the order returned by trace_block: by transaction, each call before its sub-calls
+	Traces   []EthTraceBlock
+	Metadata BlockMetadata
+}
+
+// EthTraceBlock is a trace returned by trace_block. TransactionCid is optional: it is the cid of the message of
+// the eth tx, used as TxCid of the parsed txs. If it is not set, the eth transaction hash is used instead.
+type EthTraceBlock struct {
+	ethtypes.EthTraceBlock
+	TransactionCid string `json:"transactionCid"`
+}
@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
 
 const (
 	EventTypeEVM    = "evm"
@@ -21,11 +25,44 @@ type Event struct {
 	EventTimestamp time.Time `json:"event_timestamp"`
 	// ParserVersion is the parser version used to parse this event
 	ParserVersion string `json:"parser_version"`
+	EthLogFields
 	NodeInfo
 }
 
+// EthLogFields are the fields of an eth log exposed as typed values, besides the metadata json, so they can be
+// stored as columns. They are only set for evm events. The indexes and the transaction hash are only known for the
+// events parsed from eth logs, so they are nil (or empty) for the evm events parsed from native events.
+type EthLogFields struct {
+	// EthLogIndex is the index of the log as returned by the node. LogIndex is renumbered within the tipset instead.
+	EthLogIndex      *uint64 `json:"eth_log_index,omitempty"`
+	TransactionIndex *uint64 `json:"transaction_index,omitempty"`
+	TransactionHash  string  `json:"transaction_hash,omitempty"`
+	// Address is the lowercase hex address of the emitter, as it shows up in the eth logs
+	Address string `json:"address,omitempty"`
+	Topic0  string `json:"topic0,omitempty"`
+	Topic1  string `json:"topic1,omitempty"`
+	Topic2  string `json:"topic2,omitempty"`
+	Topic3  string `json:"topic3,omitempty"`
+}
+
 func (evt *Event) SetNodeMetadata(nodeMajorMinorVersion, nodeFullVersion, parserVer string) {
 	evt.NodeMajorMinorVersion = nodeMajorMinorVersion
 	evt.NodeFullVersion = nodeFullVersion
 	evt.ParserVersion = parserVer
 }
+
+// LogsBloom holds the logs bloom filters of a tipset, computed from its eth logs
+type LogsBloom struct {
+	// Tipset is the union of the blooms of every receipt of the tipset
+	Tipset ethtypes.EthBytes `json:"tipset"`
+	// Receipts holds the bloom of each transaction with logs, sorted by transaction index
+	Receipts []ReceiptLogsBloom `json:"receipts"`
+}
+
+// ReceiptLogsBloom is the logs bloom of a single transaction receipt
+type ReceiptLogsBloom struct {
+	TransactionHash  ethtypes.EthHash  `json:"transaction_hash"`
+	TransactionCid   string            `json:"transaction_cid"`
+	TransactionIndex uint64            `json:"transaction_index"`
+	Bloom            ethtypes.EthBytes `json:"bloom"`
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// InputHashes are the SHA-256 of the inputs a tipset was parsed from, hex encoded. Empty inputs have an
+// empty hash. They tie archived outputs back to the exact inputs when auditing discrepancies.
+type InputHashes struct {
+	Traces   string `json:"traces,omitempty"`
+	Tipset   string `json:"tipset,omitempty"`
+	EthLogs  string `json:"eth_logs,omitempty"`
+	Messages string `json:"messages,omitempty"`
+	// EthReceipts is only set if eth receipts were provided, so the combined hash of older inputs does not change
+	EthReceipts string `json:"eth_receipts,omitempty"`
+	// Receipts is only set if receipts were provided separately from the traces, see TxsData.Receipts
+	Receipts string `json:"receipts,omitempty"`
+}
+
+// HashTxsData hashes the raw traces as they were received. The tipset, the eth logs and the receipts are hashed
+// in their json encoding, the same one used to store them.
+func HashTxsData(txsData TxsData) InputHashes {
+	hashes := InputHashes{Traces: hashBytes(txsData.Traces)}
+	if txsData.Tipset != nil {
+		hashes.Tipset = hashJSON(txsData.Tipset)
+	}
+	if len(txsData.EthLogs) > 0 {
+		hashes.EthLogs = hashJSON(txsData.EthLogs)
+	}
+	if len(txsData.EthReceipts) > 0 {
+		hashes.EthReceipts = hashJSON(txsData.EthReceipts)
+	}
+	if len(txsData.Receipts) > 0 {
+		hashes.Receipts = hashJSON(txsData.Receipts)
+	}
+	return hashes
+}
+
+// HashMessagesData hashes the tipset, and the messages and receipts together in their json encoding
+func HashMessagesData(messagesData MessagesData) InputHashes {
+	var hashes InputHashes
+	if messagesData.Tipset != nil {
+		hashes.Tipset = hashJSON(messagesData.Tipset)
+	}
+	if len(messagesData.Messages) > 0 {
+		hashes.Messages = hashJSON([]interface{}{messagesData.Messages, messagesData.Receipts})
+	}
+	return hashes
+}
+
+// Combined returns a single hash of all the inputs, used as the per tx provenance
+func (h InputHashes) Combined() string {
+	return hashBytes([]byte(h.Traces + h.Tipset + h.EthLogs + h.Messages + h.EthReceipts + h.Receipts))
+}
+
+func hashJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return hashBytes(data)
+}
+
+func hashBytes(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashTxsData(t *testing.T) {
+	traces := []byte(`[{"MsgCid":"bafy"}]`)
+	sum := sha256.Sum256(traces)
+
+	hashes := HashTxsData(TxsData{Traces: traces})
+	require.Equal(t, hex.EncodeToString(sum[:]), hashes.Traces)
+	require.Empty(t, hashes.Tipset)
+	require.Empty(t, hashes.EthLogs)
+
+	// the same inputs always have the same hashes
+	require.Equal(t, hashes, HashTxsData(TxsData{Traces: traces}))
+	require.Equal(t, hashes.Combined(), HashTxsData(TxsData{Traces: traces}).Combined())
+
+	other := HashTxsData(TxsData{Traces: []byte(`[]`), EthLogs: []EthLog{{}}})
+	require.NotEqual(t, hashes.Traces, other.Traces)
+	require.NotEmpty(t, other.EthLogs)
+	require.NotEqual(t, hashes.Combined(), other.Combined())
+}
+
+func TestHashMessagesData(t *testing.T) {
+	require.Equal(t, InputHashes{}, HashMessagesData(MessagesData{}))
+}
@@ -0,0 +1,59 @@
+// Package light holds a representation of tipsets that only depends on the standard library, so modules
+// consuming the parser output do not need to align their Lotus version with the one used by the parser.
+// Use types.ExtendedTipSet.ToLight and types.ExtendedTipSetFromLight to convert from and to the Lotus types.
+package light
+
+import "fmt"
+
+type BlockHeader struct {
+	Cid                   string   `json:"cid"`
+	Miner                 string   `json:"miner"`
+	Height                int64    `json:"height"`
+	Timestamp             uint64   `json:"timestamp"`
+	Parents               []string `json:"parents"`
+	ParentWeight          string   `json:"parent_weight"`
+	ParentStateRoot       string   `json:"parent_state_root"`
+	ParentMessageReceipts string   `json:"parent_message_receipts"`
+	Messages              string   `json:"messages"`
+	ParentBaseFee         string   `json:"parent_base_fee"`
+	// Raw is the CBOR encoded block header, needed to convert the tipset back to the Lotus types
+	Raw []byte `json:"raw,omitempty"`
+}
+
+// MessageBlock is a block including a message
+type MessageBlock struct {
+	Cid        string `json:"cid"`
+	BlockMiner string `json:"block_miner"`
+}
+
+type TipSet struct {
+	Cid          string        `json:"cid"`
+	ParentCid    string        `json:"parent_cid"`
+	Height       int64         `json:"height"`
+	MinTimestamp uint64        `json:"min_timestamp"`
+	Cids         []string      `json:"cids"`
+	Parents      []string      `json:"parents"`
+	Blocks       []BlockHeader `json:"blocks"`
+	// BlockMessages are the blocks including each message, by message cid
+	BlockMessages map[string][]MessageBlock `json:"block_messages"`
+}
+
+func (t *TipSet) GetBlockMiner(blockCid string) (string, error) {
+	for _, block := range t.Blocks {
+		if block.Cid == blockCid {
+			return block.Miner, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find miner that mined block '%s'", blockCid)
+}
+
+func (t *TipSet) GetBlockMinedByMiner(minerAddress string) (string, error) {
+	for _, block := range t.Blocks {
+		if block.Miner == minerAddress {
+			return block.Cid, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find block mined by miner '%s'", minerAddress)
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	lotusChainTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/zondax/fil-parser/types/light"
+)
+
+var errMissingRawHeader = errors.New("missing raw block header")
+
+// ToLight converts the tipset to its Lotus independent representation
+func (e *ExtendedTipSet) ToLight() (*light.TipSet, error) {
+	t := &light.TipSet{
+		Cid:           e.GetCidString(),
+		ParentCid:     e.GetParentCidString(),
+		Height:        int64(e.Height()),
+		MinTimestamp:  e.MinTimestamp(),
+		Cids:          cidsToStrings(e.Cids()),
+		Parents:       cidsToStrings(e.Parents().Cids()),
+		BlockMessages: make(map[string][]light.MessageBlock, len(e.BlockMessages)),
+	}
+
+	for _, header := range e.Blocks() {
+		raw := new(bytes.Buffer)
+		if err := header.MarshalCBOR(raw); err != nil {
+			return nil, fmt.Errorf("could not encode block header %s: %w", header.Cid(), err)
+		}
+
+		t.Blocks = append(t.Blocks, light.BlockHeader{
+			Cid:                   header.Cid().String(),
+			Miner:                 header.Miner.String(),
+			Height:                int64(header.Height),
+			Timestamp:             header.Timestamp,
+			Parents:               cidsToStrings(header.Parents),
+			ParentWeight:          header.ParentWeight.String(),
+			ParentStateRoot:       header.ParentStateRoot.String(),
+			ParentMessageReceipts: header.ParentMessageReceipts.String(),
+			Messages:              header.Messages.String(),
+			ParentBaseFee:         header.ParentBaseFee.String(),
+			Raw:                   raw.Bytes(),
+		})
+	}
+
+	for msgCid, blocks := range e.BlockMessages {
+		for _, block := range blocks {
+			t.BlockMessages[msgCid] = append(t.BlockMessages[msgCid], light.MessageBlock{Cid: block.Cid, BlockMiner: block.BlockMiner})
+		}
+	}
+
+	return t, nil
+}
+
+// ExtendedTipSetFromLight converts the Lotus independent tipset back to the Lotus types. The raw block
+// headers are required, as the Lotus tipset is built from the full headers.
+func ExtendedTipSetFromLight(t *light.TipSet) (*ExtendedTipSet, error) {
+	headers := make([]*lotusChainTypes.BlockHeader, 0, len(t.Blocks))
+	for _, block := range t.Blocks {
+		if len(block.Raw) == 0 {
+			return nil, fmt.Errorf("%w: %s", errMissingRawHeader, block.Cid)
+		}
+
+		header, err := lotusChainTypes.DecodeBlock(block.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode block header %s: %w", block.Cid, err)
+		}
+		headers = append(headers, header)
+	}
+
+	tipset, err := lotusChainTypes.NewTipSet(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	blockMessages := make(BlockMessages, len(t.BlockMessages))
+	for msgCid, blocks := range t.BlockMessages {
+		for _, block := range blocks {
+			blockMessages[msgCid] = append(blockMessages[msgCid], LightBlockHeader{Cid: block.Cid, BlockMiner: block.BlockMiner})
+		}
+	}
+
+	return &ExtendedTipSet{TipSet: *tipset, BlockMessages: blockMessages}, nil
+}
+
+func cidsToStrings(cids []cid.Cid) []string {
+	result := make([]string, 0, len(cids))
+	for _, c := range cids {
+		result = append(result, c.String())
+	}
+	return result
+}
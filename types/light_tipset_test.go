@@ -0,0 +1,68 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+	lotusChainTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlockHeader(t *testing.T, miner uint64, ticket byte) *lotusChainTypes.BlockHeader {
+	minerAddr, err := address.NewIDAddress(miner)
+	require.NoError(t, err)
+	c := cid.MustParse("bafy2bzacecdjkk2tzogitpcybu3eszr4uptrjogstqmyt6u4q2p4kfdrwhy3e")
+
+	return &lotusChainTypes.BlockHeader{
+		Miner:                 minerAddr,
+		Ticket:                &lotusChainTypes.Ticket{VRFProof: []byte{ticket}},
+		ElectionProof:         &lotusChainTypes.ElectionProof{WinCount: 1, VRFProof: []byte{ticket}},
+		Parents:               []cid.Cid{c},
+		ParentWeight:          lotusChainTypes.NewInt(10),
+		Height:                abi.ChainEpoch(100),
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+		BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+		Timestamp:             1000,
+		ParentBaseFee:         lotusChainTypes.NewInt(100),
+	}
+}
+
+func TestExtendedTipSet_Light(t *testing.T) {
+	blockA := testBlockHeader(t, 1000, 1)
+	blockB := testBlockHeader(t, 1001, 2)
+	tipset, err := lotusChainTypes.NewTipSet([]*lotusChainTypes.BlockHeader{blockA, blockB})
+	require.NoError(t, err)
+
+	extended := &ExtendedTipSet{
+		TipSet: *tipset,
+		BlockMessages: BlockMessages{
+			"bafymsg": {{Cid: blockA.Cid().String(), BlockMiner: blockA.Miner.String()}},
+		},
+	}
+
+	lightTipset, err := extended.ToLight()
+	require.NoError(t, err)
+	require.Equal(t, extended.GetCidString(), lightTipset.Cid)
+	require.Equal(t, extended.GetParentCidString(), lightTipset.ParentCid)
+	require.Equal(t, int64(tipset.Height()), lightTipset.Height)
+	require.Len(t, lightTipset.Blocks, 2)
+
+	miner, err := lightTipset.GetBlockMiner(blockB.Cid().String())
+	require.NoError(t, err)
+	require.Equal(t, blockB.Miner.String(), miner)
+
+	got, err := ExtendedTipSetFromLight(lightTipset)
+	require.NoError(t, err)
+	require.Equal(t, extended.Key(), got.Key())
+	require.Equal(t, extended.BlockMessages, got.BlockMessages)
+
+	lightTipset.Blocks[0].Raw = nil
+	_, err = ExtendedTipSetFromLight(lightTipset)
+	require.ErrorIs(t, err, errMissingRawHeader)
+}
@@ -0,0 +1,19 @@
+//go:build !nonode
+
+package types
+
+import "github.com/filecoin-project/lotus/api"
+
+// The types of the lotus node api used by the parser. Building with the nonode tag replaces them with copies
+// that do not import the lotus api, see lotus_api_nonode.go.
+type (
+	// FullNode is the lotus node client
+	FullNode           = api.FullNode
+	APIVersion         = api.APIVersion
+	APIVersionNumber   = api.Version
+	MsgGasCost         = api.MsgGasCost
+	InvocResult        = api.InvocResult
+	ComputeStateOutput = api.ComputeStateOutput
+	Message            = api.Message
+	EthTxReceipt       = api.EthTxReceipt
+)
@@ -0,0 +1,104 @@
+//go:build nonode
+
+package types
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+)
+
+// Copies of the types of the lotus node api used by the parser, so it can be built without importing the lotus
+// api. They match the lotus ones field by field, see lotus_api.go.
+
+// FullNode is the part of the lotus node client used by the parser. The lookups needing other methods (e.g. the
+// miner info enrichment) are not available in nonode builds.
+type FullNode interface {
+	Version(ctx context.Context) (APIVersion, error)
+	StateNetworkVersion(ctx context.Context, tsk filTypes.TipSetKey) (network.Version, error)
+	StateActorCodeCIDs(ctx context.Context, version network.Version) (map[string]cid.Cid, error)
+	EthChainId(ctx context.Context) (ethtypes.EthUint64, error)
+	EthTraceBlock(ctx context.Context, blkNum string) ([]*ethtypes.EthTraceBlock, error)
+	EthGetBlockReceipts(ctx context.Context, blkParam ethtypes.EthBlockNumberOrHash) ([]*EthTxReceipt, error)
+	GetActorEventsRaw(ctx context.Context, filter *filTypes.ActorEventFilter) ([]*filTypes.ActorEvent, error)
+	ChainReadObj(ctx context.Context, obj cid.Cid) ([]byte, error)
+	ChainHasObj(ctx context.Context, obj cid.Cid) (bool, error)
+	ChainPutObj(ctx context.Context, obj blocks.Block) error
+	ChainGetEvents(ctx context.Context, root cid.Cid) ([]filTypes.Event, error)
+	StateGetActor(ctx context.Context, actor address.Address, tsk filTypes.TipSetKey) (*filTypes.Actor, error)
+	StateLookupID(ctx context.Context, addr address.Address, tsk filTypes.TipSetKey) (address.Address, error)
+	StateAccountKey(ctx context.Context, addr address.Address, tsk filTypes.TipSetKey) (address.Address, error)
+	StateSectorGetInfo(ctx context.Context, maddr address.Address, n abi.SectorNumber, tsk filTypes.TipSetKey) (*miner.SectorOnChainInfo, error)
+	EthGetTransactionHashByCid(ctx context.Context, msgCid cid.Cid) (*ethtypes.EthHash, error)
+}
+
+// APIVersionNumber is the binary encoded semver version of the node api
+type APIVersionNumber uint32
+
+func (v APIVersionNumber) String() string {
+	return fmt.Sprintf("%d.%d.%d", (v>>16)&0xff, (v>>8)&0xff, v&0xff)
+}
+
+type APIVersion struct {
+	Version    string
+	APIVersion APIVersionNumber
+	BlockDelay uint64
+}
+
+type MsgGasCost struct {
+	Message            cid.Cid
+	GasUsed            abi.TokenAmount
+	BaseFeeBurn        abi.TokenAmount
+	OverEstimationBurn abi.TokenAmount
+	MinerPenalty       abi.TokenAmount
+	MinerTip           abi.TokenAmount
+	Refund             abi.TokenAmount
+	TotalCost          abi.TokenAmount
+}
+
+type InvocResult struct {
+	MsgCid         cid.Cid
+	Msg            *filTypes.Message
+	MsgRct         *filTypes.MessageReceipt
+	GasCost        MsgGasCost
+	ExecutionTrace filTypes.ExecutionTrace
+	Error          string
+	Duration       time.Duration
+}
+
+type ComputeStateOutput struct {
+	Root  cid.Cid
+	Trace []*InvocResult
+}
+
+type Message struct {
+	Cid     cid.Cid
+	Message *filTypes.Message
+}
+
+type EthTxReceipt struct {
+	TransactionHash   ethtypes.EthHash     `json:"transactionHash"`
+	TransactionIndex  ethtypes.EthUint64   `json:"transactionIndex"`
+	BlockHash         ethtypes.EthHash     `json:"blockHash"`
+	BlockNumber       ethtypes.EthUint64   `json:"blockNumber"`
+	From              ethtypes.EthAddress  `json:"from"`
+	To                *ethtypes.EthAddress `json:"to"`
+	StateRoot         ethtypes.EthHash     `json:"root"`
+	Status            ethtypes.EthUint64   `json:"status"`
+	ContractAddress   *ethtypes.EthAddress `json:"contractAddress"`
+	CumulativeGasUsed ethtypes.EthUint64   `json:"cumulativeGasUsed"`
+	GasUsed           ethtypes.EthUint64   `json:"gasUsed"`
+	EffectiveGasPrice ethtypes.EthBigInt   `json:"effectiveGasPrice"`
+	LogsBloom         ethtypes.EthBytes    `json:"logsBloom"`
+	Logs              []ethtypes.EthLog    `json:"logs"`
+	Type              ethtypes.EthUint64   `json:"type"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"math/big"
+)
+
+const (
+	// EscrowChangeDeposit is a deposit into the market escrow of an account (AddBalance)
+	EscrowChangeDeposit = "deposit"
+	// EscrowChangeWithdrawal is a withdrawal from the market escrow of an account (WithdrawBalance)
+	EscrowChangeWithdrawal = "withdrawal"
+)
+
+// EscrowChange is a change of the balance a client or provider holds in the escrow of the storage market
+type EscrowChange struct {
+	// TxId is the id of the AddBalance or WithdrawBalance tx
+	TxId string `json:"tx_id"`
+	// TxCid is the cid of the main message
+	TxCid string `json:"tx_cid"`
+	// Height is the height of the tipset
+	Height uint64 `json:"height"`
+	// Kind is either EscrowChangeDeposit or EscrowChangeWithdrawal
+	Kind string `json:"kind"`
+	// Account is the client or provider whose escrow changed
+	Account string `json:"account"`
+	// Sender is the address that called the market
+	Sender string `json:"sender"`
+	// Recipient is the address the withdrawn funds were sent to. Empty for deposits, and for withdrawals
+	// whose internal transfer is not known (e.g. when parsing messages without traces)
+	Recipient string `json:"recipient,omitempty"`
+	// Amount is the amount deposited or withdrawn, in attoFil
+	Amount *big.Int `json:"amount" gorm:"type:numeric"`
+	// Requested is the amount the withdrawal asked for. The market only sends the available balance, so
+	// it can be greater than Amount.
+	Requested *big.Int `json:"requested,omitempty" gorm:"type:numeric"`
+}
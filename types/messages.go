@@ -0,0 +1,22 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-state-types/crypto"
+	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+)
+
+// MessagesData holds the messages of a tipset with their receipts, used to parse transactions when
+// execution traces are not available. Messages and receipts are the ones returned by ChainGetParentMessages
+// and ChainGetParentReceipts for any block of the next tipset, so they must be in the same order.
+type MessagesData struct {
+	Tipset   *ExtendedTipSet
+	Messages []Message
+	Receipts []*filTypes.MessageReceipt
+	// Signatures is optional. It holds the signatures of the signed messages (e.g. from ChainGetBlockMessages),
+	// keyed by the cid of the message in Messages. Signatures are added to the metadata of the txs.
+	Signatures map[cid.Cid]crypto.Signature
+	// ReceiptsRoot is optional. It is the ParentMessageReceipts of the next tipset, set on the txs if LinkReceipts is enabled
+	ReceiptsRoot cid.Cid
+	Metadata     BlockMetadata
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// CompressMetadata replaces the metadata by its zstd compressed version, base64 encoded so it can still be
+// stored as text. MetadataCompressed flags the tx, so GetMetadata knows the metadata must be decompressed.
+func (tx *Transaction) CompressMetadata() {
+	if tx.MetadataCompressed {
+		return
+	}
+	compressed := zstdEncoder.EncodeAll([]byte(tx.TxMetadata), nil)
+	tx.TxMetadata = base64.StdEncoding.EncodeToString(compressed)
+	tx.MetadataCompressed = true
+}
+
+// GetMetadata returns the metadata of the tx, decompressing it if needed
+func (tx *Transaction) GetMetadata() (string, error) {
+	if !tx.MetadataCompressed {
+		return tx.TxMetadata, nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(tx.TxMetadata)
+	if err != nil {
+		return "", fmt.Errorf("could not decode compressed metadata of tx %s: %w", tx.Id, err)
+	}
+
+	metadata, err := zstdDecoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not decompress metadata of tx %s: %w", tx.Id, err)
+	}
+	return string(metadata), nil
+}
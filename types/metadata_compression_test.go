@@ -0,0 +1,43 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_CompressMetadata(t *testing.T) {
+	metadata := `{"Params":{"Deals":[` + strings.Repeat(`{"PieceSize":34359738368,"Client":"f01234"},`, 1000) + `{}]}}`
+	tx := &Transaction{Id: "tx", TxMetadata: metadata}
+
+	// uncompressed txs keep the output they had before the flag existed
+	raw, err := json.Marshal(tx)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "metadata_compressed")
+
+	got, err := tx.GetMetadata()
+	require.NoError(t, err)
+	require.Equal(t, metadata, got)
+
+	tx.CompressMetadata()
+	require.True(t, tx.MetadataCompressed)
+	require.Less(t, len(tx.TxMetadata), len(metadata))
+	raw, err = json.Marshal(tx)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `"metadata_compressed":true`)
+
+	// compressing twice is a no-op
+	compressed := tx.TxMetadata
+	tx.CompressMetadata()
+	require.Equal(t, compressed, tx.TxMetadata)
+
+	got, err = tx.GetMetadata()
+	require.NoError(t, err)
+	require.Equal(t, metadata, got)
+
+	tx.TxMetadata = "not base64!"
+	_, err = tx.GetMetadata()
+	require.Error(t, err)
+}
@@ -26,3 +26,27 @@ type MultisigEvents struct {
 	Proposals    []*MultisigProposal
 	MultisigInfo []*MultisigInfo
 }
+
+// MultisigState is a snapshot of the on-chain state of a multisig at a tipset
+type MultisigState struct {
+	MultisigAddress     string                `json:"multisig_address"`
+	Height              uint64                `json:"height"`
+	Signers             []string              `json:"signers"`
+	Threshold           uint64                `json:"threshold"`
+	InitialBalance      string                `json:"initial_balance"`
+	LockedBalance       string                `json:"locked_balance"`
+	StartEpoch          int64                 `json:"start_epoch"`
+	UnlockDuration      int64                 `json:"unlock_duration"`
+	PendingTransactions []*MultisigPendingTxn `json:"pending_transactions"`
+}
+
+// MultisigPendingTxn is a proposal waiting for approvals
+type MultisigPendingTxn struct {
+	ID         int64    `json:"id"`
+	To         string   `json:"to"`
+	Value      string   `json:"value"`
+	Method     uint64   `json:"method"`
+	MethodName string   `json:"method_name"`
+	Params     string   `json:"params"`
+	Approved   []string `json:"approved"`
+}
@@ -1,5 +1,10 @@
 package types
 
+import "strings"
+
+// ForestNodeIdentifier is the token present in the full version string reported by Forest nodes
+const ForestNodeIdentifier = "forest"
+
 type NodeInfo struct {
 	// NodeFullVersion contains the node version from which this metadata was extracted
 	NodeFullVersion string `json:"node_full_version,omitempty"`
@@ -7,6 +12,11 @@ type NodeInfo struct {
 	NodeMajorMinorVersion string `json:"node_major_minor_version,omitempty"`
 }
 
+// IsForest returns true if the metadata was extracted from a Forest node
+func (n NodeInfo) IsForest() bool {
+	return strings.Contains(strings.ToLower(n.NodeFullVersion), ForestNodeIdentifier)
+}
+
 type HasNodeInfo interface {
 	SetNodeMetadata(nodeMajorMinorVersion, nodeFullVersion, parserVer string)
 }
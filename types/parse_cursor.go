@@ -0,0 +1,53 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseCursor identifies a sub-batch of the output of a tipset. Consumers store it along the data written to
+// their sinks, in the same transaction, and resume after it to deliver every sub-batch exactly once.
+type ParseCursor struct {
+	Height    uint64 `json:"height"`
+	TipsetCid string `json:"tipset_cid"`
+	// Batch is the index of the sub-batch in the output of the tipset
+	Batch int `json:"batch"`
+	// Final is true for the last sub-batch of the tipset, once it is delivered the tipset is complete
+	Final bool `json:"final"`
+}
+
+// String encodes the cursor as height:tipsetCid:batch, with a trailing :final for the last sub-batch
+func (c ParseCursor) String() string {
+	s := fmt.Sprintf("%d:%s:%d", c.Height, c.TipsetCid, c.Batch)
+	if c.Final {
+		s += ":final"
+	}
+	return s
+}
+
+// ParseCursorFromString decodes a cursor encoded with ParseCursor.String
+func ParseCursorFromString(s string) (ParseCursor, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 || len(parts) > 4 || (len(parts) == 4 && parts[3] != "final") {
+		return ParseCursor{}, fmt.Errorf("invalid parse cursor %s", s)
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return ParseCursor{}, fmt.Errorf("invalid height of parse cursor %s: %w", s, err)
+	}
+	batch, err := strconv.Atoi(parts[2])
+	if err != nil || batch < 0 {
+		return ParseCursor{}, fmt.Errorf("invalid batch of parse cursor %s", s)
+	}
+	return ParseCursor{Height: height, TipsetCid: parts[1], Batch: batch, Final: len(parts) == 4}, nil
+}
+
+// Delivered returns whether the sub-batch at the other cursor was already delivered when the consumer is at this
+// cursor. Sub-batches of other tipsets at the same height (e.g. after a reorg) are never taken as delivered.
+func (c ParseCursor) Delivered(other ParseCursor) bool {
+	if other.Height != c.Height {
+		return other.Height < c.Height
+	}
+	return other.TipsetCid == c.TipsetCid && other.Batch <= c.Batch
+}
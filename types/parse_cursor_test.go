@@ -0,0 +1,27 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCursor(t *testing.T) {
+	cursor := ParseCursor{Height: 10, TipsetCid: "bafy10", Batch: 2, Final: true}
+	require.Equal(t, "10:bafy10:2:final", cursor.String())
+
+	decoded, err := ParseCursorFromString(cursor.String())
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+
+	for _, invalid := range []string{"", "10:bafy10", "x:bafy10:1", "10:bafy10:-1", "10:bafy10:1:done"} {
+		_, err = ParseCursorFromString(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	require.True(t, cursor.Delivered(ParseCursor{Height: 9, TipsetCid: "bafy9", Batch: 5}))
+	require.True(t, cursor.Delivered(ParseCursor{Height: 10, TipsetCid: "bafy10", Batch: 1}))
+	require.False(t, cursor.Delivered(ParseCursor{Height: 10, TipsetCid: "bafy10", Batch: 3}))
+	require.False(t, cursor.Delivered(ParseCursor{Height: 10, TipsetCid: "bafy10-reorg", Batch: 0}))
+	require.False(t, cursor.Delivered(ParseCursor{Height: 11, TipsetCid: "bafy11", Batch: 0}))
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	filTypes "github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
 )
 
 type TxsData struct {
@@ -9,23 +10,222 @@ type TxsData struct {
 	Tipset   *ExtendedTipSet
 	EthLogs  []EthLog
 	Metadata BlockMetadata
+	// Checkpointer is optional. When set, the progress of the parse is stored every CheckpointInterval
+	// traces, and a tipset that was partially parsed by a crashed process is resumed from its checkpoint.
+	Checkpointer TraceCheckpointer
+	// CheckpointInterval is the amount of traces between checkpoints. Zero means DefaultTraceCheckpointInterval
+	CheckpointInterval int
+	// ReceiptsRoot is optional. It is the ParentMessageReceipts of the next tipset, set on the txs if LinkReceipts is enabled
+	ReceiptsRoot cid.Cid
+	// EthReceipts is optional. It is the output of eth_getBlockReceipts for the tipset, used to set the effective
+	// gas price of the messages and cross-check the receipts with the parsed txs, see EthReceiptsReconciliation
+	EthReceipts []EthReceipt
+	// Receipts is optional. It holds the receipts of the messages delivered separately from the traces, keyed by
+	// the cid of the message. They complete the receipts of the traces, e.g. when the return of a trace is truncated.
+	Receipts map[cid.Cid]filTypes.MessageReceipt
 }
 
 type TxsParsedResult struct {
 	Txs       []*Transaction
 	Addresses *AddressInfoMap
 	TxCids    []TxCidTranslation
+	// TokenTransfers are the FRC-46 transfers found in the txs, see TokenTransfer
+	TokenTransfers []*TokenTransfer
+	// EscrowChanges are the deposits and withdrawals of the storage market escrow, see EscrowChange
+	EscrowChanges []*EscrowChange
+	// AccountPromotions are the placeholder actors promoted to EthAccounts by the txs, see AccountPromotion
+	AccountPromotions []*AccountPromotion
+	// BlockInclusions are the blocks that included each message, see BlockInclusion
+	BlockInclusions []*BlockInclusion
+	Report          ParseReport
+}
+
+// ParseReport holds the corner cases found while parsing a tipset, so they can be audited downstream
+type ParseReport struct {
+	// DuplicatedTxs is the amount of txs dropped because another tx had the same id
+	DuplicatedTxs int `json:"duplicated_txs"`
+	// DuplicatedFees is the amount of dropped txs that were duplicated fees at level 0 (e.g. height 845259)
+	DuplicatedFees int `json:"duplicated_fees"`
+	// InputHashes are the hashes of the inputs of the tipset, see InputHashes
+	InputHashes InputHashes `json:"input_hashes"`
+	// Anomalies are the suspicious txs found by the anomalies analyzer, if it is enabled
+	Anomalies []Anomaly `json:"anomalies,omitempty"`
+	// InvariantViolations are the chain economics invariants that do not hold for the tipset, if the check is enabled
+	InvariantViolations []InvariantViolation `json:"invariant_violations,omitempty"`
+	// AddressViolations are the inconsistent address info entries found by the addresses validation, if it is enabled
+	AddressViolations []AddressViolation `json:"address_violations,omitempty"`
+	// Build is the fil-parser build that produced the output
+	Build BuildInfo `json:"build"`
+	// SkippedTraces are the traces and messages that did not produce any tx, see SkippedTrace
+	SkippedTraces []SkippedTrace `json:"skipped_traces,omitempty"`
+	// EthLogs is the reconciliation of the eth logs of the tipset with the parsed messages, if any eth log was provided
+	EthLogs *EthLogsReconciliation `json:"eth_logs,omitempty"`
+	// EthReceipts is the reconciliation of the eth receipts of the tipset with the parsed messages, if any eth
+	// receipt was provided
+	EthReceipts *EthReceiptsReconciliation `json:"eth_receipts,omitempty"`
+	// TimedOutSteps are the pipeline steps that ran out of their time budget and were cut short, when the stage
+	// timeouts are handled in best effort mode
+	TimedOutSteps []string `json:"timed_out_steps,omitempty"`
+}
+
+const (
+	// EthLogMismatchMessageNotFound flags an eth log whose message is not part of the parsed txs
+	EthLogMismatchMessageNotFound = "message_not_found"
+	// EthLogMismatchFailedMessage flags an eth log emitted by a failed message, whose events should have been reverted
+	EthLogMismatchFailedMessage = "failed_message"
+	// EthLogMismatchTxHash flags an eth log whose tx hash does not translate to its tx cid
+	EthLogMismatchTxHash = "tx_hash_mismatch"
+	// EthLogMismatchTransactionIndex flags an eth log whose transaction index is not the execution index of its message
+	EthLogMismatchTransactionIndex = "transaction_index_mismatch"
+)
+
+// EthLogsReconciliation matches the eth logs of a tipset with the parsed messages. Mismatches have historically
+// pointed to node bugs, so they are reported instead of silently ignored.
+type EthLogsReconciliation struct {
+	// Logs is the amount of eth logs provided
+	Logs int `json:"logs"`
+	// Matched is the amount of eth logs matching a successful parsed message
+	Matched int `json:"matched"`
+	// Messages is the amount of parsed messages with eth logs
+	Messages int `json:"messages"`
+	// Unmatched are the eth logs that could not be reconciled, see the EthLogMismatch constants
+	Unmatched []EthLogMismatch `json:"unmatched,omitempty"`
+	// Duplicates are the eth logs found more than once, with the same tx hash and log index
+	Duplicates []EthLogMismatch `json:"duplicates,omitempty"`
+}
+
+// EthLogMismatch is an eth log that could not be reconciled with the parsed messages
+type EthLogMismatch struct {
+	TransactionHash string `json:"transaction_hash"`
+	TransactionCid  string `json:"transaction_cid,omitempty"`
+	LogIndex        uint64 `json:"log_index"`
+	Reason          string `json:"reason,omitempty"`
+	Detail          string `json:"detail,omitempty"`
+}
+
+const (
+	// EthReceiptMismatchMessageNotFound flags an eth receipt whose message is not part of the parsed txs
+	EthReceiptMismatchMessageNotFound = "message_not_found"
+	// EthReceiptMismatchStatus flags an eth receipt whose status does not match the status of its message
+	EthReceiptMismatchStatus = "status_mismatch"
+	// EthReceiptMismatchGasUsed flags an eth receipt whose gas used is not the gas used by its message
+	EthReceiptMismatchGasUsed = "gas_used_mismatch"
+	// EthReceiptMismatchEffectiveGasPrice flags an eth receipt whose effective gas price is not the one derived
+	// from the gas costs of the traces
+	EthReceiptMismatchEffectiveGasPrice = "effective_gas_price_mismatch"
+)
+
+// EthReceiptsReconciliation matches the eth receipts of a tipset with the parsed messages. The receipts are
+// computed by the node independently of the traces, so mismatches point to bugs in either of them.
+type EthReceiptsReconciliation struct {
+	// Receipts is the amount of eth receipts provided
+	Receipts int `json:"receipts"`
+	// Matched is the amount of eth receipts matching their parsed message
+	Matched int `json:"matched"`
+	// Mismatches are the eth receipts that could not be reconciled, see the EthReceiptMismatch constants.
+	// A receipt may be reported more than once, with different reasons.
+	Mismatches []EthReceiptMismatch `json:"mismatches,omitempty"`
+	// Duplicates are the eth receipts found more than once, with the same tx hash
+	Duplicates []EthReceiptMismatch `json:"duplicates,omitempty"`
+}
+
+// EthReceiptMismatch is an eth receipt that could not be reconciled with the parsed messages. TxId is the id of
+// the parsed message, if it was found.
+type EthReceiptMismatch struct {
+	TransactionHash string `json:"transaction_hash"`
+	TransactionCid  string `json:"transaction_cid,omitempty"`
+	TxId            string `json:"tx_id,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	Detail          string `json:"detail,omitempty"`
+}
+
+const (
+	// SkipReasonNoMessage flags a trace without message
+	SkipReasonNoMessage = "no_message"
+	// SkipReasonParseError flags a trace that could not be parsed
+	SkipReasonParseError = "parse_error"
+	// SkipReasonFailedParent flags the sub-calls of a failed message, they are not parsed as nothing they did was applied
+	SkipReasonFailedParent = "failed_parent"
+	// SkipReasonDuplicated flags a tx dropped because another tx had the same id
+	SkipReasonDuplicated = "duplicated_tx"
+	// SkipReasonUnknownSignature flags a message dropped because of its signature type, see UnknownSignaturesDrop
+	SkipReasonUnknownSignature = "unknown_signature"
+)
+
+// SkippedTrace is a trace, or message, intentionally left out of the parsed txs, so auditors can confirm
+// nothing meaningful was dropped at a given height
+type SkippedTrace struct {
+	// ExecutionIndex is the position of the message of the trace in the tipset
+	ExecutionIndex uint64 `json:"execution_index"`
+	TxCid          string `json:"tx_cid,omitempty"`
+	// Path is the path of sub-call indexes of internal traces (see Transaction.InternalTxId), empty for messages
+	Path   string `json:"path,omitempty"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// BuildInfo identifies a fil-parser build, so the heights parsed by a buggy version can be found and reprocessed
+type BuildInfo struct {
+	// Version is the semantic version of the module, or a pseudo-version for untagged builds
+	Version string `json:"version"`
+	// Revision is the git revision of the build, if known
+	Revision string `json:"revision,omitempty"`
+}
+
+// String renders the build as version+revision, or only the version if the revision is not known
+func (b BuildInfo) String() string {
+	if b.Revision == "" {
+		return b.Version
+	}
+	return b.Version + "+" + b.Revision
+}
+
+// Anomaly is a suspicious tx, or tipset if TxId is empty, flagged by a heuristic of the anomalies analyzer
+type Anomaly struct {
+	Kind   string `json:"kind"`
+	TxId   string `json:"tx_id,omitempty"`
+	TxCid  string `json:"tx_cid,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// InvariantViolation is a chain economics invariant that does not hold for a tipset, with the offending txs
+type InvariantViolation struct {
+	Kind     string   `json:"kind"`
+	TxIds    []string `json:"tx_ids,omitempty"`
+	Expected string   `json:"expected"`
+	Actual   string   `json:"actual"`
+	Detail   string   `json:"detail"`
+}
+
+// AddressViolation is an address info entry breaking a rule of the addresses validation
+type AddressViolation struct {
+	Kind   string `json:"kind"`
+	Short  string `json:"short"`
+	Robust string `json:"robust,omitempty"`
+	Detail string `json:"detail"`
 }
 
 type EventsData struct {
 	Tipset    *ExtendedTipSet
 	NativeLog []*filTypes.ActorEvent
 	EthLogs   []EthLog
-	Metadata  BlockMetadata
+	// Receipts holds the events of each message receipt, decoded as native events after NativeLog
+	Receipts []ReceiptEvents
+	Metadata BlockMetadata
+}
+
+// ReceiptEvents are the events emitted by a message. If Events is empty, they are fetched
+// from the node using the EventsRoot of the message receipt.
+type ReceiptEvents struct {
+	MsgCid     cid.Cid
+	EventsRoot *cid.Cid
+	Events     []filTypes.Event
 }
 
 type EventsParsedResult struct {
 	EVMEvents    int
 	NativeEvents int
 	ParsedEvents []*Event
+	// LogsBloom holds the logs bloom filters of the tipset. Only set by ParseEthLogs.
+	LogsBloom *LogsBloom
 }
@@ -0,0 +1,24 @@
+package types
+
+// MinerInfo are the addresses controlling a miner at a given tipset
+type MinerInfo struct {
+	// Owner is the address that controls the miner and receives its withdrawals
+	Owner string `json:"owner"`
+	// Worker is the address that signs the blocks and most of the messages of the miner
+	Worker string `json:"worker"`
+	// ControlAddresses are the extra addresses allowed to send the PoSt messages of the miner
+	ControlAddresses []string `json:"control_addresses"`
+	// Beneficiary is the address that receives the funds withdrawn from the miner
+	Beneficiary string `json:"beneficiary,omitempty"`
+}
+
+type SectorInfo struct {
+	// SectorNumber is the sector number inside the miner
+	SectorNumber uint64 `json:"sector_number"`
+	// Activation is the epoch during which the sector proof was accepted
+	Activation int64 `json:"activation"`
+	// Expiration is the epoch during which the sector expires
+	Expiration int64 `json:"expiration"`
+	// DealIDs are the deals stored in the sector
+	DealIDs []uint64 `json:"deal_ids"`
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"math/big"
+)
+
+// TokenTransfer is a FRC-46 token transfer, normalized from the receiver hook the token actor calls on the
+// recipient. It is the same regardless of the actor implementing the token (datacap, evm wrappers...).
+type TokenTransfer struct {
+	// TxId is the id of the receiver hook tx the transfer was detected in
+	TxId string `json:"tx_id"`
+	// TxCid is the cid of the main message
+	TxCid string `json:"tx_cid"`
+	// Height is the height of the tipset
+	Height uint64 `json:"height"`
+	// TokenActor is the actor implementing the token
+	TokenActor string `json:"token_actor"`
+	// From is the address the tokens were debited from
+	From string `json:"from"`
+	// To is the address the tokens were credited to
+	To string `json:"to"`
+	// Operator is the address that initiated the transfer (it differs from From on TransferFrom)
+	Operator string `json:"operator"`
+	// Amount is the amount of tokens transferred, in atto units
+	Amount *big.Int `json:"amount" gorm:"type:numeric"`
+}
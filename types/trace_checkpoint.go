@@ -0,0 +1,53 @@
+package types
+
+// DefaultTraceCheckpointInterval is the amount of traces parsed between two checkpoints of the same tipset
+const DefaultTraceCheckpointInterval = 500
+
+// TraceCheckpointKey identifies the checkpoints of a tipset. Checkpoints are only resumed by the parser version
+// that saved them, as other versions may build different txs from the same traces.
+type TraceCheckpointKey struct {
+	ParserVersion string
+	TipsetCid     string
+}
+
+// TraceCheckpoint is the progress of the parse of a single tipset. Checkpoints are incremental: each one holds
+// what was parsed from the traces between the previous checkpoint of the tipset and NextTrace, so a crashed
+// process can resume the tipset from the last one by merging them all.
+type TraceCheckpoint struct {
+	// ParserVersion is the version of the parser that saved the checkpoint
+	ParserVersion string `json:"parser_version"`
+	// TipsetCid is the tipset being parsed
+	TipsetCid string `json:"tipset_cid"`
+	// NextTrace is the index of the first trace pending to be parsed
+	NextTrace int `json:"next_trace"`
+	// Addresses are the addresses found or updated since the previous checkpoint
+	Addresses      map[string]*AddressInfo `json:"addresses"`
+	Txs            []*Transaction          `json:"txs"`
+	TxCids         []TxCidTranslation      `json:"tx_cids"`
+	TokenTransfers []*TokenTransfer        `json:"token_transfers"`
+	SkippedTraces  []SkippedTrace          `json:"skipped_traces,omitempty"`
+}
+
+// Key returns the key of the tipset of the checkpoint
+func (c *TraceCheckpoint) Key() TraceCheckpointKey {
+	return TraceCheckpointKey{ParserVersion: c.ParserVersion, TipsetCid: c.TipsetCid}
+}
+
+// TraceCheckpointer loads and persists the progress of the parse of a tipset
+type TraceCheckpointer interface {
+	// Load returns the stored checkpoints of the tipset, in the order they were saved, or none
+	Load(key TraceCheckpointKey) ([]*TraceCheckpoint, error)
+	// Save appends the checkpoint to the ones stored for its tipset
+	Save(checkpoint *TraceCheckpoint) error
+	// Delete removes the checkpoints of the tipset once it is fully parsed
+	Delete(key TraceCheckpointKey) error
+}
+
+// NewAddressInfoMapFrom builds an AddressInfoMap holding the given entries
+func NewAddressInfoMapFrom(entries map[string]*AddressInfo) *AddressInfoMap {
+	addresses := NewAddressInfoMap()
+	for k, v := range entries {
+		addresses.Set(k, v)
+	}
+	return addresses
+}
@@ -10,6 +10,15 @@ import (
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 )
 
+const (
+	// DiagnosticDuplicatedTx flags a tx that was found more than once while parsing, only one copy is kept
+	DiagnosticDuplicatedTx = "duplicated_tx"
+	// DiagnosticDuplicatedFee flags a fee tx at level 0 that was found more than once while parsing
+	DiagnosticDuplicatedFee = "duplicated_fee"
+	// DiagnosticUnknownSignature flags a message signed with a signature type not supported by the parser
+	DiagnosticUnknownSignature = "unknown_signature"
+)
+
 // Transaction parses transaction heights into the desired format for reports
 type Transaction struct {
 	TxBasicBlockData `gorm:"embedded"`
@@ -19,16 +28,41 @@ type Transaction struct {
 	ParentId string `json:"parent_id"`
 	// Level is the nested level of the transaction
 	Level uint16 `json:"level"`
+	// IsInternal is true for the sub-calls of a message (level > 0). Only set if the tx_hierarchy feature is enabled
+	IsInternal bool `json:"is_internal,omitempty"`
+	// RootId is the id of the top-level message the tx belongs to, for sub-calls, fees and gas refunds.
+	// It is empty for top-level messages, so they can be selected without looking at the tx type. Only set if the
+	// tx_hierarchy feature is enabled.
+	RootId string `json:"root_id,omitempty" gorm:"index:idx_transactions_root_id"`
 	// TxTimestamp is the timestamp of the transaction
 	TxTimestamp time.Time `json:"tx_timestamp"`
-	// TxCid is the transaction hash
+	// TxCid is the transaction hash. Internal txs have no cid of their own, so they share the cid of the
+	// on-chain message that triggered them; use InternalTxId to tell them apart.
 	TxCid string `json:"tx_cid" gorm:"index:idx_transactions_tx_hash"`
+	// InternalTxId is the synthetic identifier of internal txs (level > 0): the TxCid followed by the path of
+	// sub-call indexes in the execution trace, e.g. bafy2...:0.2. It is empty for on-chain messages and fees.
+	InternalTxId string `json:"internal_tx_id,omitempty" gorm:"index:idx_transactions_internal_tx_id"`
 	// TxFrom is the sender address
 	TxFrom string `json:"tx_from" gorm:"index:idx_transactions_tx_from"`
 	// TxTo is the receiver address
 	TxTo string `json:"tx_to" gorm:"index:idx_transactions_tx_to"`
+	// TxFromTags are the labels of the sender address (exchange, bridge...), if an address tagger is configured
+	TxFromTags []string `json:"tx_from_tags,omitempty" gorm:"serializer:json"`
+	// TxToTags are the labels of the receiver address, if an address tagger is configured
+	TxToTags []string `json:"tx_to_tags,omitempty" gorm:"serializer:json"`
 	// Amount is the amount of the tx in attoFil
 	Amount *big.Int `json:"amount" gorm:"type:numeric"`
+	// AmountFil is the amount of the tx as a FIL decimal string, set depending on the configured amount format
+	AmountFil string `json:"amount_fil,omitempty"`
+	// ExecutionIndex is the position of the message in the tipset, in the order the VM applied the messages
+	// of all its blocks. It is shared by the message and all its sub-txs and fees.
+	ExecutionIndex uint64 `json:"execution_index"`
+	// ReceiptIndex is the index of the receipt of the message in the receipts of the tipset. Only set on messages
+	// if LinkReceipts is enabled; implicit messages (rewards, cron) have no receipt.
+	ReceiptIndex *uint64 `json:"receipt_index,omitempty"`
+	// ReceiptsRoot is the root of the receipts AMT of the tipset, i.e. the ParentMessageReceipts of the next tipset.
+	// Only set on messages if LinkReceipts is enabled and the root was provided.
+	ReceiptsRoot string `json:"receipts_root,omitempty"`
 	// GasUsed is the total gas used amount in attoFil
 	GasUsed uint64 `json:"gas_used"`
 	// Status
@@ -37,8 +71,20 @@ type Transaction struct {
 	TxType string `json:"tx_type" gorm:"index:idx_tx_type"`
 	// TxMetadata is the message metadata
 	TxMetadata string `json:"tx_metadata"`
+	// MetadataCompressed is true when TxMetadata is zstd compressed, see GetMetadata. Omitted when false, so the
+	// output of parsers without CompressMetadata is unchanged
+	MetadataCompressed bool `json:"metadata_compressed,omitempty"`
+	// Diagnostics flags the corner cases found while parsing this tx, see the Diagnostic constants
+	Diagnostics []string `json:"diagnostics,omitempty" gorm:"serializer:json"`
+	// InputHash is the combined hash of the inputs this tx was parsed from, if TxInputProvenance is enabled
+	InputHash string `json:"input_hash,omitempty"`
 	// ParserVersion is the parser version used to parse this tx
 	ParserVersion string `json:"parser_version"`
+	// ParserBuild is the fil-parser build that parsed this tx, see BuildInfo. Only set if StampBuildInfo is enabled
+	ParserBuild string `json:"parser_build,omitempty"`
+	// EffectiveGasPrice is the effective gas price of the message in attoFil, as reported by the node. Only set on
+	// messages if the eth receipts of the tipset were provided, see EthReceipt
+	EffectiveGasPrice *big.Int `json:"effective_gas_price,omitempty" gorm:"type:numeric"`
 	NodeInfo
 }
 
@@ -46,6 +92,7 @@ func (t Transaction) Equal(b Transaction) bool {
 	b.ParentId = t.ParentId
 	b.Id = t.Id
 	b.ParserVersion = t.ParserVersion
+	b.ParserBuild = t.ParserBuild
 	b.NodeMajorMinorVersion = t.NodeMajorMinorVersion
 	b.NodeFullVersion = t.NodeFullVersion
 	return reflect.DeepEqual(t, b)
@@ -62,6 +109,13 @@ type EthLog struct {
 	TransactionCid string `json:"transactionCid"`
 }
 
+// EthReceipt is a receipt returned by eth_getBlockReceipts. TransactionCid is optional: it is the cid of the
+// message of the eth tx. If it is not set, the message is found by translating the eth transaction hash.
+type EthReceipt struct {
+	EthTxReceipt
+	TransactionCid string `json:"transactionCid"`
+}
+
 func (t EthLog) GetId() (string, error) {
 	h := sha256.New()
 	rawData, err := json.Marshal(t)
@@ -76,11 +130,14 @@ func (t EthLog) GetId() (string, error) {
 
 type GenesisBalances struct {
 	Actors struct {
-		All []struct {
-			Key   string `json:"Key"`
-			Value struct {
-				Balance string `json:"Balance"`
-			}
-		}
+		All []GenesisBalance
+	}
+}
+
+// GenesisBalance is the balance of an actor at genesis
+type GenesisBalance struct {
+	Key   string `json:"Key"`
+	Value struct {
+		Balance string `json:"Balance"`
 	}
 }
@@ -0,0 +1,44 @@
+package types
+
+// GasBreakdown splits the fee paid by a message. All the amounts are in attoFil.
+type GasBreakdown struct {
+	BaseFeeBurn        string `json:"base_fee_burn"`
+	OverEstimationBurn string `json:"over_estimation_burn"`
+	MinerTip           string `json:"miner_tip"`
+	TotalCost          string `json:"total_cost"`
+	// Refund is the gas refunded to the sender, only set with the gas_refunds experimental feature
+	Refund string `json:"refund,omitempty"`
+	// MinerPenalty is burned from the miner that included the message, empty if it was not penalized
+	MinerPenalty string `json:"miner_penalty,omitempty"`
+	// Source is where the fees come from: the gas outputs of the trace or recomputed from the message
+	Source string `json:"source,omitempty"`
+}
+
+// TransactionV2 extends Transaction with the fields that otherwise have to be extracted from the metadata
+// or from other txs. It converts from and to the legacy Transaction, so both can be used side by side.
+type TransactionV2 struct {
+	Transaction `gorm:"embedded"`
+	// ExitCodeName is the name of the exit code of the tx, e.g. Ok or ErrInsufficientFunds
+	ExitCodeName string `json:"exit_code_name"`
+	// EthHash is the ethereum hash of the message, if it has one
+	EthHash string `json:"eth_hash"`
+	// CallPath contains the ids of the parent txs, from the main tx to this one
+	CallPath []string `json:"call_path" gorm:"serializer:json"`
+	// GasBreakdown is only set on main txs that paid fees
+	GasBreakdown *GasBreakdown `json:"gas_breakdown" gorm:"serializer:json"`
+}
+
+// ToLegacy returns the legacy Transaction
+func (t *TransactionV2) ToLegacy() *Transaction {
+	tx := t.Transaction
+	return &tx
+}
+
+// ToLegacyTransactions converts a list of TransactionV2 to legacy transactions
+func ToLegacyTransactions(txs []*TransactionV2) []*Transaction {
+	legacy := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		legacy = append(legacy, tx.ToLegacy())
+	}
+	return legacy
+}
@@ -0,0 +1,40 @@
+package types
+
+// VerifregAllocation is a DataCap allocation made by a client to a provider, pending to be claimed
+type VerifregAllocation struct {
+	AllocationID uint64 `json:"allocation_id"`
+	Client       string `json:"client"`
+	Provider     string `json:"provider"`
+	Data         string `json:"data"`
+	Size         uint64 `json:"size"`
+	TermMin      int64  `json:"term_min"`
+	TermMax      int64  `json:"term_max"`
+	Expiration   int64  `json:"expiration"`
+}
+
+// VerifregClaim is an allocation claimed by a provider, committed to one of its sectors
+type VerifregClaim struct {
+	ClaimID   uint64 `json:"claim_id"`
+	Provider  string `json:"provider"`
+	Client    string `json:"client"`
+	Data      string `json:"data"`
+	Size      uint64 `json:"size"`
+	TermMin   int64  `json:"term_min"`
+	TermMax   int64  `json:"term_max"`
+	TermStart int64  `json:"term_start"`
+	Sector    uint64 `json:"sector"`
+}
+
+// VerifregAllocations are the allocations of a client at a tipset, sorted by id
+type VerifregAllocations struct {
+	Client      string                `json:"client"`
+	Height      uint64                `json:"height"`
+	Allocations []*VerifregAllocation `json:"allocations"`
+}
+
+// VerifregClaims are the claims of a provider at a tipset, sorted by id
+type VerifregClaims struct {
+	Provider string           `json:"provider"`
+	Height   uint64           `json:"height"`
+	Claims   []*VerifregClaim `json:"claims"`
+}
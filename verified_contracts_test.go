@@ -0,0 +1,33 @@
+package fil_parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zondax/fil-parser/parser"
+	"github.com/zondax/fil-parser/types"
+	"go.uber.org/zap"
+)
+
+func TestFilecoinParser_setVerifiedContracts(t *testing.T) {
+	contract := parser.VerifiedContract{Name: "WFIL"}
+	p := &FilecoinParser{logger: zap.NewNop(), contracts: parser.NewStaticContractMetadataProvider(map[string]parser.VerifiedContract{
+		"0x60e1773636cf5e4a227d9ac24f20feca034ee25a": contract,
+	})}
+
+	addresses := types.NewAddressInfoMap()
+	addresses.Set("f01000", &types.AddressInfo{Short: "f01000", EthAddress: "0x60e1773636cf5e4a227d9ac24f20feca034ee25a"})
+
+	txs := []*types.Transaction{
+		{Id: "call", TxTo: "f01000", TxType: parser.MethodInvokeContract},
+		{Id: "send", TxTo: "f01000", TxType: parser.MethodSend},
+		{Id: "unverified", TxTo: "f01001", TxType: parser.MethodInvokeContract},
+	}
+	p.setVerifiedContracts(context.Background(), &types.TxsParsedResult{Txs: txs, Addresses: addresses})
+
+	// the short address is found through its eth address
+	require.JSONEq(t, `{"verifiedContract":{"name":"WFIL"}}`, txs[0].TxMetadata)
+	require.Empty(t, txs[1].TxMetadata)
+	require.Empty(t, txs[2].TxMetadata)
+}